@@ -24,6 +24,99 @@ type ObservabilityAddonSpec struct {
 	// +kubebuilder:validation:Minimum=15
 	// +kubebuilder:validation:Maximum=3600
 	Interval int32 `json:"interval,omitempty"`
+
+	// CollectNodeMetrics indicates the node level metrics (node-exporter) are collected
+	// from the managed clusters.
+	// +optional
+	// +kubebuilder:default:=true
+	CollectNodeMetrics bool `json:"collectNodeMetrics"`
+
+	// CollectContainerMetrics indicates the container level metrics (cAdvisor) are collected
+	// from the managed clusters.
+	// +optional
+	// +kubebuilder:default:=true
+	CollectContainerMetrics bool `json:"collectContainerMetrics"`
+
+	// CollectEtcdMetrics indicates the etcd metrics are collected from the managed clusters.
+	// +optional
+	// +kubebuilder:default:=true
+	CollectEtcdMetrics bool `json:"collectEtcdMetrics"`
+
+	// CollectApiserverMetrics indicates the kube-apiserver metrics are collected
+	// from the managed clusters.
+	// +optional
+	// +kubebuilder:default:=true
+	CollectApiserverMetrics bool `json:"collectApiserverMetrics"`
+
+	// CollectCostMetrics indicates the metrics which are required by the cost attribution
+	// of OpenCost or Koku are collected from the managed clusters.
+	// +optional
+	CollectCostMetrics bool `json:"collectCostMetrics,omitempty"`
+
+	// ServiceMonitorSelector selects the ServiceMonitor objects on the managed cluster
+	// whose targets are merged into the scrape config of the metrics collector.
+	// Nothing is selected when it is not set.
+	// +optional
+	ServiceMonitorSelector *metav1.LabelSelector `json:"serviceMonitorSelector,omitempty"`
+
+	// PodMonitorSelector selects the PodMonitor objects on the managed cluster
+	// whose targets are merged into the scrape config of the metrics collector.
+	// Nothing is selected when it is not set.
+	// +optional
+	PodMonitorSelector *metav1.LabelSelector `json:"podMonitorSelector,omitempty"`
+
+	// CollectorSharding splits the metrics collection on the managed cluster across
+	// multiple metrics collector shards. The sharding set in the ObservabilityAddon
+	// of a managed cluster overrides the global one.
+	// +optional
+	CollectorSharding *CollectorShardingSpec `json:"collectorSharding,omitempty"`
+
+	// CollectionMode is how the metrics are collected from the managed clusters: in push
+	// mode the metrics collector remote writes to the hub, in pull mode the observability
+	// addon exposes a secured /federate endpoint which is scraped from the hub. Pull mode
+	// is for the managed clusters which cannot open connections to the hub.
+	// +optional
+	// +kubebuilder:default:=push
+	// +kubebuilder:validation:Enum=push;pull
+	CollectionMode string `json:"collectionMode,omitempty"`
+
+	// NamespaceFilter selects the namespaces whose workload metrics are scraped and
+	// forwarded from the managed cluster. The filter set in the ObservabilityAddon
+	// of a managed cluster overrides the global one.
+	// +optional
+	NamespaceFilter *NamespaceFilterSpec `json:"namespaceFilter,omitempty"`
+}
+
+// NamespaceFilterSpec is the allow and deny list of the namespaces of the metrics,
+// the series without the namespace label are always forwarded
+type NamespaceFilterSpec struct {
+	// Include is the list of the regular expressions of the namespaces whose metrics
+	// are forwarded, all the namespaces are included if it is empty.
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Exclude is the list of the regular expressions of the namespaces whose metrics
+	// are dropped, it takes precedence over the include list.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// CollectorShardingSpec is the spec of metrics collector sharding
+type CollectorShardingSpec struct {
+	// Number of the metrics collector shards. Each shard only collects the series
+	// whose hash of the sharding key modulo the number of shards equals its index.
+	// +optional
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32
+	Shards int32 `json:"shards,omitempty"`
+
+	// Key is hashed to assign the series to the shards: target spreads the scrape
+	// targets and metric spreads the metric names across the shards.
+	// +optional
+	// +kubebuilder:default:=target
+	// +kubebuilder:validation:Enum=target;metric
+	Key string `json:"key,omitempty"`
 }
 
 type PreConfiguredStorage struct {
@@ -20,7 +20,9 @@ limitations under the License.
 
 package shared
 
-import ()
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Condition) DeepCopyInto(out *Condition) {
@@ -38,9 +40,69 @@ func (in *Condition) DeepCopy() *Condition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CollectorShardingSpec) DeepCopyInto(out *CollectorShardingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollectorShardingSpec.
+func (in *CollectorShardingSpec) DeepCopy() *CollectorShardingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CollectorShardingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceFilterSpec) DeepCopyInto(out *NamespaceFilterSpec) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceFilterSpec.
+func (in *NamespaceFilterSpec) DeepCopy() *NamespaceFilterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceFilterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObservabilityAddonSpec) DeepCopyInto(out *ObservabilityAddonSpec) {
 	*out = *in
+	if in.ServiceMonitorSelector != nil {
+		in, out := &in.ServiceMonitorSelector, &out.ServiceMonitorSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodMonitorSelector != nil {
+		in, out := &in.PodMonitorSelector, &out.PodMonitorSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CollectorSharding != nil {
+		in, out := &in.CollectorSharding, &out.CollectorSharding
+		*out = new(CollectorShardingSpec)
+		**out = **in
+	}
+	if in.NamespaceFilter != nil {
+		in, out := &in.NamespaceFilter, &out.NamespaceFilter
+		*out = new(NamespaceFilterSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObservabilityAddonSpec.
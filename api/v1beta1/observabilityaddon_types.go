@@ -27,6 +27,9 @@ type ObservabilityAddonStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	Conditions []StatusCondition `json:"conditions"`
+	// The version of the observability addon which runs on the managed cluster
+	// +optional
+	Version string `json:"version,omitempty"`
 }
 
 // +kubebuilder:object:root=true
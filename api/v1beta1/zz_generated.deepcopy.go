@@ -110,7 +110,7 @@ func (in *MultiClusterObservabilitySpec) DeepCopyInto(out *MultiClusterObservabi
 	if in.ObservabilityAddonSpec != nil {
 		in, out := &in.ObservabilityAddonSpec, &out.ObservabilityAddonSpec
 		*out = new(shared.ObservabilityAddonSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -151,7 +151,7 @@ func (in *ObservabilityAddon) DeepCopyInto(out *ObservabilityAddon) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
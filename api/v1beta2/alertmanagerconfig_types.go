@@ -0,0 +1,144 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package v1beta2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	observabilityshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+)
+
+// AlertmanagerConfigSpec defines the desired state of AlertmanagerConfig
+type AlertmanagerConfigSpec struct {
+	// The route of the alerts of the tenant. The alerts are always matched with the tenant
+	// label of the namespace, in addition to the matchers of the route.
+	// +required
+	Route AlertmanagerRoute `json:"route"`
+	// The receivers of the alerts of the tenant.
+	// +required
+	Receivers []AlertmanagerReceiver `json:"receivers"`
+}
+
+// AlertmanagerRoute routes the matched alerts to a receiver.
+type AlertmanagerRoute struct {
+	// The name of the receiver, which must be one of the receivers in the same AlertmanagerConfig.
+	// +required
+	Receiver string `json:"receiver"`
+	// The labels by which the alerts are grouped.
+	// +optional
+	GroupBy []string `json:"groupBy,omitempty"`
+	// How long to wait before sending the notification of a new group, e.g. 30s.
+	// +optional
+	GroupWait string `json:"groupWait,omitempty"`
+	// How long to wait before sending the notification of the new alerts in a group, e.g. 5m.
+	// +optional
+	GroupInterval string `json:"groupInterval,omitempty"`
+	// How long to wait before sending the notification again, e.g. 12h.
+	// +optional
+	RepeatInterval string `json:"repeatInterval,omitempty"`
+	// The matchers of the alert labels. The matchers on the tenant label are ignored.
+	// +optional
+	Matchers []AlertmanagerMatcher `json:"matchers,omitempty"`
+}
+
+// AlertmanagerMatcher matches the value of an alert label.
+type AlertmanagerMatcher struct {
+	// The name of the label.
+	// +required
+	Name string `json:"name"`
+	// The value of the label.
+	// +required
+	Value string `json:"value"`
+	// Match the value as a regular expression.
+	// +optional
+	Regex bool `json:"regex,omitempty"`
+}
+
+// AlertmanagerReceiver is a named list of the notification integrations.
+type AlertmanagerReceiver struct {
+	// The name of the receiver.
+	// +required
+	Name string `json:"name"`
+	// The webhooks which are notified with the alerts.
+	// +optional
+	WebhookConfigs []AlertmanagerWebhookConfig `json:"webhookConfigs,omitempty"`
+	// The slack channels which are notified with the alerts.
+	// +optional
+	SlackConfigs []AlertmanagerSlackConfig `json:"slackConfigs,omitempty"`
+	// The email addresses which are notified with the alerts, with the global smtp settings
+	// of the alertmanager-config secret.
+	// +optional
+	EmailConfigs []AlertmanagerEmailConfig `json:"emailConfigs,omitempty"`
+}
+
+// AlertmanagerWebhookConfig sends the alerts to a webhook.
+type AlertmanagerWebhookConfig struct {
+	// The URL of the webhook. Either url or urlSecret is required.
+	// +optional
+	URL string `json:"url,omitempty"`
+	// The key of the secret in the same namespace which contains the URL of the webhook.
+	// +optional
+	URLSecret *corev1.SecretKeySelector `json:"urlSecret,omitempty"`
+	// Whether to notify about the resolved alerts.
+	// +optional
+	SendResolved *bool `json:"sendResolved,omitempty"`
+}
+
+// AlertmanagerSlackConfig sends the alerts to a slack channel.
+type AlertmanagerSlackConfig struct {
+	// The key of the secret in the same namespace which contains the incoming webhook URL of slack.
+	// +required
+	APIURLSecret corev1.SecretKeySelector `json:"apiURLSecret"`
+	// The channel or the user to send the alerts to.
+	// +optional
+	Channel string `json:"channel,omitempty"`
+	// Whether to notify about the resolved alerts.
+	// +optional
+	SendResolved *bool `json:"sendResolved,omitempty"`
+}
+
+// AlertmanagerEmailConfig sends the alerts to an email address.
+type AlertmanagerEmailConfig struct {
+	// The email address to send the alerts to.
+	// +required
+	To string `json:"to"`
+	// Whether to notify about the resolved alerts.
+	// +optional
+	SendResolved *bool `json:"sendResolved,omitempty"`
+}
+
+// AlertmanagerConfigStatus defines the observed state of AlertmanagerConfig
+type AlertmanagerConfigStatus struct {
+	// Represents whether the config is merged into the alertmanager configuration
+	// +optional
+	Conditions []observabilityshared.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// AlertmanagerConfig declares the receivers of the alerts of a tenant. It is created in one of
+// the namespaces of the tenant and merged into the configuration of the alertmanager on the hub.
+// +kubebuilder:resource:path=alertmanagerconfigs,scope=Namespaced
+// +kubebuilder:printcolumn:name="Receiver",type=string,JSONPath=`.spec.route.receiver`
+type AlertmanagerConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AlertmanagerConfigSpec   `json:"spec,omitempty"`
+	Status AlertmanagerConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// AlertmanagerConfigList contains a list of AlertmanagerConfig
+type AlertmanagerConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AlertmanagerConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AlertmanagerConfig{}, &AlertmanagerConfigList{})
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	observabilityshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+)
+
+// FleetSLOSpec defines the desired state of FleetSLO
+type FleetSLOSpec struct {
+	// The service level indicator, which is the ratio of the error events to the total events.
+	// +required
+	SLI ServiceLevelIndicator `json:"sli"`
+	// The objective of the service level in percentage, e.g. 99.9.
+	// +required
+	// +kubebuilder:validation:Pattern=`^(100(\.0+)?|[0-9]{1,2}(\.[0-9]+)?)$`
+	Objective string `json:"objective"`
+	// The time window which the objective is measured over, e.g. 28d or 30d.
+	// +optional
+	// +kubebuilder:default:="30d"
+	// +kubebuilder:validation:Pattern=`^[0-9]+(d|h)$`
+	Window string `json:"window,omitempty"`
+	// Disable the multi-window error budget burn rate alerts of the service level.
+	// +optional
+	DisableAlerts bool `json:"disableAlerts,omitempty"`
+}
+
+// ServiceLevelIndicator is the ratio of the error events to the total events. Both queries
+// are summed by the cluster label so that the service level is evaluated per cluster, and
+// the {{window}} placeholder in them is replaced with the range of each evaluation window.
+type ServiceLevelIndicator struct {
+	// The query of the error events, e.g. rate(http_requests_total{code=~"5.."}[{{window}}]).
+	// +required
+	ErrorQuery string `json:"errorQuery"`
+	// The query of the total events, e.g. rate(http_requests_total[{{window}}]).
+	// +required
+	TotalQuery string `json:"totalQuery"`
+}
+
+// FleetSLOStatus defines the observed state of FleetSLO
+type FleetSLOStatus struct {
+	// Represents whether the rules of the service level are generated
+	// +optional
+	Conditions []observabilityshared.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// FleetSLO declares a service level objective which is evaluated for every managed cluster.
+// It is translated into the recording and alerting rules of the thanos ruler on the hub.
+// +kubebuilder:resource:path=fleetslos,scope=Cluster,shortName=fslo
+// +kubebuilder:printcolumn:name="Objective",type=string,JSONPath=`.spec.objective`
+// +kubebuilder:printcolumn:name="Window",type=string,JSONPath=`.spec.window`
+type FleetSLO struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FleetSLOSpec   `json:"spec,omitempty"`
+	Status FleetSLOStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// FleetSLOList contains a list of FleetSLO
+type FleetSLOList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FleetSLO `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FleetSLO{}, &FleetSLOList{})
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	observabilityshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+)
+
+// MetricsImportPhase is the phase of the import
+type MetricsImportPhase string
+
+const (
+	// MetricsImportPending means the import job is not started yet
+	MetricsImportPending MetricsImportPhase = "Pending"
+	// MetricsImportRunning means the import job is running
+	MetricsImportRunning MetricsImportPhase = "Running"
+	// MetricsImportSucceeded means the blocks are imported into the object storage
+	MetricsImportSucceeded MetricsImportPhase = "Succeeded"
+	// MetricsImportFailed means the import job failed
+	MetricsImportFailed MetricsImportPhase = "Failed"
+)
+
+// MetricsImportSpec defines the desired state of MetricsImport
+type MetricsImportSpec struct {
+	// The name of the managed cluster which the imported metrics belong to. It is added to the
+	// blocks of Prometheus as the cluster external label so that the metrics can be queried with
+	// the metrics of the cluster which are collected by the observability addon.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	ClusterName string `json:"clusterName"`
+	// The ID of the managed cluster, which is added to the blocks of Prometheus as the clusterID
+	// external label. The name of the cluster is used by default.
+	// +optional
+	ClusterID string `json:"clusterID,omitempty"`
+	// The additional external labels which are added to the blocks of Prometheus. The blocks of
+	// Thanos keep their external labels.
+	// +optional
+	ExternalLabels map[string]string `json:"externalLabels,omitempty"`
+	// The source of the historical metrics, exactly one of the sources must be set.
+	// +required
+	Source MetricsImportSource `json:"source"`
+	// The image of thanos which runs the import. The image of thanos of the observability
+	// components is used by default.
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// MetricsImportSource is the source of the historical metrics
+type MetricsImportSource struct {
+	// The TSDB blocks of an existing Prometheus.
+	// +optional
+	PrometheusTSDB *PrometheusTSDBSource `json:"prometheusTSDB,omitempty"`
+	// The bucket of an existing Thanos installation.
+	// +optional
+	ThanosBucket *ThanosBucketSource `json:"thanosBucket,omitempty"`
+}
+
+// PrometheusTSDBSource is the data directory of an existing Prometheus in a persistent volume
+type PrometheusTSDBSource struct {
+	// The name of the persistent volume claim in the namespace of the observability components,
+	// which contains the data directory of Prometheus.
+	// +required
+	ClaimName string `json:"claimName"`
+	// The path of the data directory of Prometheus in the volume, the root of the volume by default.
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+}
+
+// ThanosBucketSource is the bucket of an existing Thanos installation
+type ThanosBucketSource struct {
+	// The secret in the namespace of the observability components with the configuration of
+	// the bucket, in the same format as the object storage of the observability components.
+	// +required
+	Config observabilityshared.PreConfiguredStorage `json:"config"`
+	// The matchers of the external labels of the blocks which are imported, e.g.
+	// cluster="cluster1". The blocks with the cluster external label of the cluster are
+	// imported by default.
+	// +optional
+	Matchers []string `json:"matchers,omitempty"`
+}
+
+// MetricsImportStatus defines the observed state of MetricsImport
+type MetricsImportStatus struct {
+	// The phase of the import.
+	// +optional
+	Phase MetricsImportPhase `json:"phase,omitempty"`
+	// The name of the job which imports the metrics.
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+	// The time when the import job started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// The time when the import job finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Represents the progress of the import
+	// +optional
+	Conditions []observabilityshared.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// MetricsImport imports the historical metrics of a managed cluster from an existing Prometheus
+// or Thanos installation into the object storage of the observability components.
+// +kubebuilder:resource:path=metricsimports,scope=Cluster,shortName=mimp
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.clusterName`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+type MetricsImport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MetricsImportSpec   `json:"spec,omitempty"`
+	Status MetricsImportStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// MetricsImportList contains a list of MetricsImport
+type MetricsImportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MetricsImport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MetricsImport{}, &MetricsImportList{})
+}
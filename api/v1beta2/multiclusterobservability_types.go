@@ -46,6 +46,862 @@ type MultiClusterObservabilitySpec struct {
 	// clusters which have observability add-on enabled.
 	// +required
 	ObservabilityAddonSpec *observabilityshared.ObservabilityAddonSpec `json:"observabilityAddonSpec,omitempty"`
+	// The list of ManagedCluster label keys. For each managed cluster, the values
+	// of these labels (e.g. env, region or owner) are injected as external labels
+	// into all the series forwarded from that cluster.
+	// +optional
+	InjectedClusterLabels []string `json:"injectedClusterLabels,omitempty"`
+	// The external labels, e.g. the name of the hub or the business unit, which are applied to all
+	// the series stored on the hub, so that the aggregations across the hubs and the downstream
+	// exports can tell which hub the series come from. The labels of the managed clusters take
+	// precedence, and the labels which the observability sets itself, e.g. cluster and clusterID,
+	// are reserved.
+	// +optional
+	ExternalLabels map[string]string `json:"externalLabels,omitempty"`
+	// The list of tenants which share the hub. The series forwarded from the clusters
+	// in the cluster sets of a tenant are labelled with the tenant name, so that the
+	// dashboards and the alert routes can be filtered by the tenant. The label does not
+	// restrict the queries on the hub, the queries are only isolated by the tenant in a
+	// Cortex or Mimir external metrics store.
+	// +optional
+	ClusterSetTenants []ClusterSetTenant `json:"clusterSetTenants,omitempty"`
+	// The URL of the HTTP webhook which is notified with a JSON payload when a managed
+	// cluster is onboarded to observability, goes degraded, or is removed. The same
+	// notifications are always recorded as kubernetes events.
+	// +optional
+	NotificationWebhookURL string `json:"notificationWebhookURL,omitempty"`
+	// The regional gateways between the managed clusters and the hub. The managed clusters
+	// in a region remote write to the gateway cluster of that region, which forwards the
+	// series to the hub, so that the hub only takes one connection per region.
+	// +optional
+	RegionalGateway *RegionalGatewaySpec `json:"regionalGateway,omitempty"`
+	// Enable or disable the OTLP receiver on the hub. It is exposed by the otlp-receiver
+	// route and accepts the metrics pushed with the OpenTelemetry protocol from the
+	// clients whose certificates are signed by the observability client CA.
+	// The default value is false.
+	// +optional
+	EnableOTLPReceiver bool `json:"enableOTLPReceiver,omitempty"`
+	// Enable or disable the recording rules on the hub which aggregate the cost metrics
+	// of the managed clusters by cluster and namespace. The cost metrics are collected
+	// with the collectCostMetrics of the observabilityAddonSpec.
+	// The default value is false.
+	// +optional
+	EnableCostAggregation bool `json:"enableCostAggregation,omitempty"`
+	// Enable or disable the report of the collected metrics which are not used by the
+	// dashboards, the rules of the thanos ruler or the ad hoc queries in the logs of the
+	// thanos query frontend. The default value is false.
+	// +optional
+	EnableMetricsUsageAnalytics bool `json:"enableMetricsUsageAnalytics,omitempty"`
+	// Enable or disable the attribution of the remote writes which the hub rejects, e.g. with 409,
+	// 429 or 5xx, to the managed clusters. The clusters with most rejected remote writes are reported
+	// in the status and in the observability-ingestion-errors ConfigMap. The default value is false.
+	// +optional
+	EnableIngestionErrorAttribution bool `json:"enableIngestionErrorAttribution,omitempty"`
+	// Enable or disable the self test of the alerting pipeline. A watchdog alert which always
+	// fires is pushed to every managed cluster, and the operator verifies that it reaches the
+	// alertmanager on the hub and is delivered to the receiver for each cluster.
+	// The default value is false.
+	// +optional
+	EnableAlertingSelfTest bool `json:"enableAlertingSelfTest,omitempty"`
+	// The spec of the logs collection from the managed clusters. The logs are
+	// forwarded to the Loki on the hub or an external Loki, and are labelled with
+	// the same external labels as the metrics.
+	// +optional
+	LogsCollection *LogsCollectionSpec `json:"logsCollection,omitempty"`
+	// The spec of the traces collection from the managed clusters. The traces are
+	// shipped with the OpenTelemetry protocol to the OTLP receiver on the hub, which
+	// exports them to the tracing backend. The OTLP receiver is enabled with it.
+	// +optional
+	Tracing *TracingSpec `json:"tracing,omitempty"`
+	// The spec of the kubernetes events collection from the managed clusters. The events
+	// are forwarded into the Loki of the logs collection, which must be enabled as well.
+	// +optional
+	EventsCollection *EventsCollectionSpec `json:"eventsCollection,omitempty"`
+	// The spec of the detection of the high-cardinality metrics of the managed clusters.
+	// The series of each metric are counted per cluster on the hub, and the metrics whose
+	// series are over the threshold or growing too fast are alerted.
+	// +optional
+	CardinalityGuard *CardinalityGuardSpec `json:"cardinalityGuard,omitempty"`
+	// The receivers of the alerts of the managed clusters. The operator expands them into the
+	// receivers and the routes of the alertmanager configuration, and the rest of the
+	// alertmanager-config secret is still managed by the admin.
+	// +optional
+	AlertReceivers []AlertReceiver `json:"alertReceivers,omitempty"`
+	// The customizations of grafana on the hub, they are reconciled into the grafana
+	// deployment so that they survive the upgrades of the operator.
+	// +optional
+	Grafana *GrafanaSpec `json:"grafana,omitempty"`
+	// Pin the default dashboards, alert rules and metrics allowlist to the bundle which is
+	// exported by the given version of the operator, e.g. to keep the defaults of the previous
+	// version after an upgrade. The defaults of the running operator are deployed if it is empty.
+	// +optional
+	PinnedDefaultsVersion string `json:"pinnedDefaultsVersion,omitempty"`
+	// The external metrics store, e.g. Observatorium, Thanos, Cortex or Mimir, which receives the
+	// metrics of the managed clusters and serves the queries of grafana. The storage stack on the
+	// hub is not deployed when it is set.
+	// +optional
+	ExternalMetricsStore *ExternalMetricsStoreSpec `json:"externalMetricsStore,omitempty"`
+	// The secrets which are synced from an external secret manager, e.g. Vault, by the External
+	// Secrets Operator instead of being created by hand, e.g. the pull secret, the object storage
+	// secret or the secrets of the custom CAs. The operator waits for them to be synced.
+	// +optional
+	ExternalSecrets *ExternalSecretsSpec `json:"externalSecrets,omitempty"`
+	// Hold the upgrade of the observability components on the hub after an upgrade of the operator
+	// until enough managed clusters run the observability addon of the new version.
+	// +optional
+	HubUpgradeGate *HubUpgradeGateSpec `json:"hubUpgradeGate,omitempty"`
+	// Deploy the observability components on the hub in the highly available topology, which
+	// spreads them across the availability zones of the nodes and replicates every series in
+	// thanos receive. The topology is validated against the nodes of the hub.
+	// +optional
+	HighAvailability *HighAvailabilitySpec `json:"highAvailability,omitempty"`
+	// The priority classes of the observability components on the hub and the observability addon
+	// on the managed clusters, so that they are not the first pods evicted under the node pressure.
+	// +optional
+	PriorityClasses *PriorityClassesSpec `json:"priorityClasses,omitempty"`
+	// Scale thanos receive, query and query frontend automatically between the bounds based on
+	// their load, so that the hub absorbs the growth of the fleet without resizing them manually.
+	// +optional
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
+	// The user-managed Placement or PlacementRule in the namespace of the operands which selects the
+	// managed clusters to enable the observability on. The operator consumes its decisions instead of
+	// creating the default PlacementRule, so that the placement can be managed by GitOps.
+	// +optional
+	PlacementRef *PlacementRefSpec `json:"placementRef,omitempty"`
+	// The validation and the rollback of the image manifest of the release, which overrides the
+	// images of the observability components on the hub and the managed clusters.
+	// +optional
+	ImageManifest *ImageManifestSpec `json:"imageManifest,omitempty"`
+	// The identity of the managed clusters which is retained across the renames and the reimports,
+	// so that the history of a cluster remains queryable under its new name.
+	// +optional
+	ClusterIdentity *ClusterIdentitySpec `json:"clusterIdentity,omitempty"`
+	// The configuration of the certificates of the observability API on the hub.
+	// +optional
+	TLSConfig *TLSConfigSpec `json:"tlsConfig,omitempty"`
+	// The authentication of the remote writes and the queries at the observability API gateway
+	// in addition to the mTLS with the client certificates signed by the observability client CA.
+	// +optional
+	GatewayAuth *GatewayAuthSpec `json:"gatewayAuth,omitempty"`
+	// The windows of the planned maintenance of the managed clusters. The alerts of the clusters
+	// in an active window are silenced, and their metrics collection is optionally paused. They
+	// are resumed automatically when the window ends.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	// The anonymous usage and health reports of the observability, which summarize the size of the
+	// fleet, the versions of the components and the rates of the errors without any metric data.
+	// They are disabled by default.
+	// +optional
+	Telemetry *TelemetrySpec `json:"telemetry,omitempty"`
+	// The query endpoints of the observability of the other hubs, which are added as the grafana
+	// datasources so that a global grafana shows the fleets of several hubs side by side.
+	// +optional
+	FederatedHubs []FederatedHubSpec `json:"federatedHubs,omitempty"`
+	// The probes of the API servers of the managed clusters from the hub, which measure their
+	// reachability and latency as the metrics of the fleet. They are disabled by default.
+	// +optional
+	ClusterAPIProbe *ClusterAPIProbeSpec `json:"clusterAPIProbe,omitempty"`
+	// The one-shot backfill of the recent history of the local Prometheus of the freshly onboarded
+	// managed clusters into the hub, so that their dashboards are not blank for the first hours.
+	// It is disabled by default.
+	// +optional
+	Backfill *BackfillSpec `json:"backfill,omitempty"`
+	// The authenticated PromQL HTTP API endpoint of the fleet exposed with its own route and
+	// certificate, so that the external systems query the metrics without going through grafana.
+	// It is disabled by default.
+	// +optional
+	QueryEndpoint *QueryEndpointSpec `json:"queryEndpoint,omitempty"`
+}
+
+// GrafanaSpec is the spec of the customizations of grafana.
+type GrafanaSpec struct {
+	// The plugins which are installed into grafana when it starts, only the plugins in the
+	// allowlist of the operator can be installed.
+	// +optional
+	Plugins []GrafanaPlugin `json:"plugins,omitempty"`
+	// The key of the secret in the namespace of the operands which contains the password
+	// of the admin user of grafana.
+	// +optional
+	AdminPasswordSecret *corev1.SecretKeySelector `json:"adminPasswordSecret,omitempty"`
+	// Generate a drill-down dashboard for each managed cluster in the grafana folder with the
+	// name of the cluster. The dashboards are removed when the clusters are removed.
+	// +optional
+	ClusterDashboards bool `json:"clusterDashboards,omitempty"`
+	// The persistence of the data of grafana, e.g. the dashboards and the users which are
+	// created in the UI. Grafana runs with one replica when the persistence is enabled.
+	// +optional
+	Persistence *GrafanaPersistenceSpec `json:"persistence,omitempty"`
+	// The SMTP server which grafana sends the emails with, e.g. the invitations and the
+	// reports.
+	// +optional
+	SMTP *GrafanaSMTPSpec `json:"smtp,omitempty"`
+	// Sync the dashboards from a git repository into grafana, so that the dashboards can be
+	// managed as code without wrapping them in the configmaps.
+	// +optional
+	DashboardSync *DashboardSyncSpec `json:"dashboardSync,omitempty"`
+}
+
+// ExternalMetricsStoreSpec is the spec of the external metrics store.
+type ExternalMetricsStoreSpec struct {
+	// The type of the external metrics store. Cortex and Mimir require the X-Scope-OrgID
+	// header in the remote writes and the queries.
+	// +optional
+	// +kubebuilder:default:=Thanos
+	// +kubebuilder:validation:Enum=Thanos;Observatorium;Cortex;Mimir
+	Type string `json:"type,omitempty"`
+	// The remote write URL of the external metrics store, e.g. https://mimir.example.com/api/v1/push.
+	// +required
+	RemoteWriteURL string `json:"remoteWriteURL"`
+	// The URL of the Prometheus compatible query API of the external metrics store,
+	// e.g. https://mimir.example.com/prometheus.
+	// +required
+	QueryURL string `json:"queryURL"`
+	// The name of the secret in the namespace of the operands which contains the credentials of
+	// the external metrics store, the token key for the bearer token, or the username and password
+	// keys for the basic auth. The optional ca.crt key contains the CA of the server certificate.
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	// The tenant ID of Cortex or Mimir for the clusters which do not belong to the cluster sets
+	// of the tenants in clusterSetTenants, the clusters of a tenant use the name of the tenant.
+	// +optional
+	// +kubebuilder:default:="anonymous"
+	DefaultTenantID string `json:"defaultTenantID,omitempty"`
+}
+
+// ExternalSecretsSpec is the spec of the secrets which are synced by the External Secrets Operator.
+type ExternalSecretsSpec struct {
+	// The secret store of the External Secrets Operator which holds the secrets.
+	// +required
+	SecretStoreRef ExternalSecretStoreRef `json:"secretStoreRef"`
+	// The interval which the secrets are refreshed from the secret store in.
+	// +optional
+	// +kubebuilder:default:="1h"
+	RefreshInterval string `json:"refreshInterval,omitempty"`
+	// The secrets which are synced from the secret store.
+	// +optional
+	Secrets []ExternalSecret `json:"secrets,omitempty"`
+}
+
+// ExternalSecretStoreRef is the reference of the secret store of the External Secrets Operator.
+type ExternalSecretStoreRef struct {
+	// The name of the secret store.
+	// +required
+	Name string `json:"name"`
+	// The kind of the secret store, the SecretStore must be in the namespace of the secret.
+	// +optional
+	// +kubebuilder:default:=ClusterSecretStore
+	// +kubebuilder:validation:Enum=SecretStore;ClusterSecretStore
+	Kind string `json:"kind,omitempty"`
+}
+
+// ExternalSecret is the secret which is synced from the secret store.
+type ExternalSecret struct {
+	// The name of the secret, e.g. the imagePullSecret or the name of the metricObjectStorage secret.
+	// +required
+	Name string `json:"name"`
+	// The namespace of the secret, the namespace of the observability components by default.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// The key of the secret in the secret store, all the properties of the key are synced as the
+	// keys of the secret, e.g. secret/data/observability/thanos-object-storage of Vault.
+	// +required
+	RemoteKey string `json:"remoteKey"`
+	// The type of the secret, e.g. kubernetes.io/dockerconfigjson for the pull secret.
+	// +optional
+	Type corev1.SecretType `json:"type,omitempty"`
+}
+
+// HighAvailabilitySpec is the spec of the highly available topology of the observability components on the hub.
+type HighAvailabilitySpec struct {
+	// The minimum number of the availability zones which the nodes of the observability components span.
+	// +optional
+	// +kubebuilder:default:=3
+	// +kubebuilder:validation:Minimum=2
+	MinZones int32 `json:"minZones,omitempty"`
+	// The label of the nodes with their availability zone.
+	// +optional
+	// +kubebuilder:default:="topology.kubernetes.io/zone"
+	ZoneLabel string `json:"zoneLabel,omitempty"`
+	// The number of the copies of every series in thanos receive. The replicas of thanos receive
+	// are increased to it if they are fewer.
+	// +optional
+	// +kubebuilder:default:=3
+	// +kubebuilder:validation:Minimum=1
+	ReplicationFactor int32 `json:"replicationFactor,omitempty"`
+}
+
+// PriorityClassesSpec is the spec of the priority classes of the observability components.
+type PriorityClassesSpec struct {
+	// The priority class of the deployments and statefulsets of the observability components on the hub.
+	// +optional
+	Hub *PriorityClassSpec `json:"hub,omitempty"`
+	// The priority class of the observability addon on the managed clusters.
+	// +optional
+	Addon *PriorityClassSpec `json:"addon,omitempty"`
+}
+
+// PriorityClassSpec is the spec of a priority class of the observability components.
+type PriorityClassSpec struct {
+	// The name of an existing priority class. The operator creates and manages the priority class
+	// with the value when it is not set.
+	// +optional
+	Name string `json:"name,omitempty"`
+	// The value of the priority class which the operator creates.
+	// +optional
+	// +kubebuilder:default:=1000000
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000000000
+	Value int32 `json:"value,omitempty"`
+}
+
+// AutoscalingSpec is the spec of the automatic scaling of the thanos components.
+type AutoscalingSpec struct {
+	// The autoscaler of the components. HPA scales them on the CPU utilization of the pods. KEDA scales
+	// thanos receive on the samples ingested per second and thanos query and query frontend on the
+	// concurrent queries, which are queried from the Prometheus server, it requires KEDA to be installed.
+	// +optional
+	// +kubebuilder:default:=HPA
+	// +kubebuilder:validation:Enum=HPA;KEDA
+	Type string `json:"type,omitempty"`
+	// The address of the Prometheus server which KEDA queries the metrics of the components from.
+	// +optional
+	// +kubebuilder:default:="https://thanos-querier.openshift-monitoring.svc:9091"
+	PrometheusURL string `json:"prometheusURL,omitempty"`
+	// The name of the TriggerAuthentication in the namespace of the operands which KEDA authenticates
+	// to the Prometheus server with.
+	// +optional
+	TriggerAuthentication string `json:"triggerAuthentication,omitempty"`
+	// The automatic scaling of thanos receive. The minimum replicas are raised to the replication
+	// factor of the highly available topology.
+	// +optional
+	Receive *ComponentAutoscalingSpec `json:"receive,omitempty"`
+	// The automatic scaling of thanos query.
+	// +optional
+	Query *ComponentAutoscalingSpec `json:"query,omitempty"`
+	// The automatic scaling of thanos query frontend.
+	// +optional
+	QueryFrontend *ComponentAutoscalingSpec `json:"queryFrontend,omitempty"`
+}
+
+// ComponentAutoscalingSpec is the spec of the automatic scaling of a thanos component.
+type ComponentAutoscalingSpec struct {
+	// The minimum number of the replicas.
+	// +optional
+	// +kubebuilder:default:=2
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+	// The maximum number of the replicas, it must not be less than the minimum.
+	// +optional
+	// +kubebuilder:default:=10
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+	// The target of the metric per replica, the average CPU utilization percentage with HPA, or the
+	// samples ingested per second of thanos receive and the concurrent queries of thanos query and
+	// query frontend with KEDA. The default target of the component is used if it is not set.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Target int32 `json:"target,omitempty"`
+}
+
+// PlacementRefSpec is the reference of the placement which selects the managed clusters.
+type PlacementRefSpec struct {
+	// The kind of the placement, PlacementRule or Placement.
+	// +optional
+	// +kubebuilder:default:=PlacementRule
+	// +kubebuilder:validation:Enum=PlacementRule;Placement
+	Kind string `json:"kind,omitempty"`
+	// The name of the placement in the namespace of the operands.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// ImageManifestSpec is the spec of the validation and the rollback of the image manifest.
+type ImageManifestSpec struct {
+	// Reject the image manifest if any image is not pinned by its digest, e.g. image@sha256:<digest>.
+	// +optional
+	RequireDigest bool `json:"requireDigest,omitempty"`
+	// Do not roll back to the previously applied image set if the observability addons become
+	// degraded after a new image set is applied.
+	// +optional
+	DisableRollback bool `json:"disableRollback,omitempty"`
+}
+
+// ClusterIdentitySpec is the spec of the identity of the managed clusters across renames and reimports.
+type ClusterIdentitySpec struct {
+	// How the clusterID label which is injected into the series forwarded from the cluster is derived:
+	// ClusterClaim from the value of a ClusterClaim of the managed cluster, ClusterName from the name
+	// of the ManagedCluster, or ClusterLabel from the value of a label of the ManagedCluster, e.g. the
+	// ID of the cluster in a CMDB.
+	// +optional
+	// +kubebuilder:default:=ClusterClaim
+	// +kubebuilder:validation:Enum=ClusterClaim;ClusterName;ClusterLabel
+	Source string `json:"source,omitempty"`
+	// The name of the ClusterClaim of the managed cluster whose value is injected as the clusterID
+	// label with the ClusterClaim source. The id.openshift.io claim, then the id.k8s.io claim is used
+	// by default.
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+	// The key of the label of the ManagedCluster whose value is injected as the clusterID label with
+	// the ClusterLabel source.
+	// +optional
+	LabelKey string `json:"labelKey,omitempty"`
+}
+
+// TLSConfigSpec is the spec of the certificates of the observability API on the hub.
+type TLSConfigSpec struct {
+	// The number of the managed clusters per minute which the renewed server CA is pushed to, so
+	// that the collectors of the fleet are not restarted at the same time. The other clusters keep
+	// the previous CA until their turn. The renewed CA is pushed to all the clusters at once by default.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CARolloutRate int32 `json:"caRolloutRate,omitempty"`
+	// The additional DNS names and IP addresses which the server certificate of the observability API
+	// is issued for, e.g. the hosts of a front-door load balancer or of a split-horizon DNS. The
+	// certificate is reissued when the list changes.
+	// +optional
+	AdditionalSANs []string `json:"additionalSANs,omitempty"`
+	// Sign the leaf certificates with an intermediate CA instead of the root CA.
+	// +optional
+	IntermediateCA *IntermediateCASpec `json:"intermediateCA,omitempty"`
+	// Change it to rotate the client CA with a new private key, e.g. after the key is compromised. The
+	// gateway trusts the previous client CA as well until the client certificates of all the managed
+	// clusters are reissued by the new client CA.
+	// +optional
+	ClientCARotationID string `json:"clientCARotationID,omitempty"`
+}
+
+// IntermediateCASpec is the spec of the intermediate CA which signs the leaf certificates.
+type IntermediateCASpec struct {
+	// Enable or disable the intermediate CA. The intermediate CAs are generated from the server and
+	// the client root CAs, and the root CAs are only used to sign the intermediate CAs.
+	Enabled bool `json:"enabled"`
+	// The name of the secret in the namespace of the operator with the intermediate CA which signs
+	// the server certificates instead of the generated one, e.g. issued by the enterprise PKI. The
+	// secret has the certificate in tls.crt, the PKCS1 private key in tls.key and the chain of the
+	// issuers up to the root in ca.crt, which is pushed to the managed clusters to be trusted.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// GatewayAuthSpec is the spec of the authentication at the observability API gateway.
+type GatewayAuthSpec struct {
+	// Authenticate the remote writes and the queries of the tenant with the bearer tokens issued by
+	// the OIDC provider, for the workloads which are not allowed to hold long-lived client certificates.
+	// +optional
+	OIDC *GatewayOIDCSpec `json:"oidc,omitempty"`
+	// Authenticate the remote writes of the selected managed clusters with the short-lived tokens of
+	// a service account on the hub instead of the client certificates, which are not issued to them.
+	// It cannot be enabled together with the OIDC.
+	// +optional
+	ServiceAccountToken *GatewayServiceAccountTokenSpec `json:"serviceAccountToken,omitempty"`
+	// The number of the managed clusters per minute which are switched between the client certificates
+	// and the service account tokens. The clusters are only switched to the tokens once the gateway
+	// accepts them, and back to the client certificates once their certificates are issued. All the
+	// clusters are switched at once by default.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MigrationRate int32 `json:"migrationRate,omitempty"`
+}
+
+// GatewayServiceAccountTokenSpec is the spec of the authentication with the bound service account tokens.
+type GatewayServiceAccountTokenSpec struct {
+	// Enable or disable the authentication with the service account tokens.
+	Enabled bool `json:"enabled"`
+	// The managed clusters which authenticate with the service account tokens, all the managed clusters
+	// if it is not set. The selected clusters must run the observability addon of this release.
+	// +optional
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+	// The audience of the tokens, the default is observability-api.
+	// +optional
+	Audience string `json:"audience,omitempty"`
+	// The validity of the tokens which the managed clusters request, the default is 3600.
+	// +kubebuilder:validation:Minimum=600
+	// +optional
+	ExpirationSeconds int64 `json:"expirationSeconds,omitempty"`
+	// The URL of the service account issuer of the hub, the default is https://kubernetes.default.svc.
+	// +optional
+	IssuerURL string `json:"issuerURL,omitempty"`
+}
+
+// GatewayOIDCSpec is the spec of the OIDC provider which issues the tokens for the tenant.
+type GatewayOIDCSpec struct {
+	// The URL of the OIDC issuer, e.g. https://keycloak.example.com/auth/realms/observability.
+	IssuerURL string `json:"issuerURL"`
+	// The key of the ConfigMap in the namespace of the operands which contains the CA of the issuer.
+	// The system trust store is used if it is not set.
+	// +optional
+	IssuerCA *corev1.ConfigMapKeySelector `json:"issuerCA,omitempty"`
+	// The ID of the client of the observability API gateway registered in the OIDC provider.
+	ClientID string `json:"clientID"`
+	// The key of the secret in the namespace of the operands which contains the secret of the client.
+	// +optional
+	ClientSecret *corev1.SecretKeySelector `json:"clientSecret,omitempty"`
+	// The claim of the token which is taken as the user name, the default is sub.
+	// +optional
+	UsernameClaim string `json:"usernameClaim,omitempty"`
+	// The users of the OIDC provider which are allowed to remote write to the tenant.
+	// +optional
+	WriteUsers []string `json:"writeUsers,omitempty"`
+	// The users of the OIDC provider which are allowed to query the tenant.
+	// +optional
+	ReadUsers []string `json:"readUsers,omitempty"`
+}
+
+// HubUpgradeGateSpec is the spec of the gate of the upgrade of the observability components on the hub.
+type HubUpgradeGateSpec struct {
+	// The minimum percentage of the managed clusters which run the observability addon of the
+	// version of the operator before the deployments and statefulsets on the hub are upgraded.
+	// +optional
+	// +kubebuilder:default:=100
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MinUpdatedAddonsPercentage int32 `json:"minUpdatedAddonsPercentage,omitempty"`
+}
+
+// DashboardSyncSpec is the spec of the sync of the dashboards from a git repository.
+type DashboardSyncSpec struct {
+	// The URL of the git repository, e.g. https://github.com/example/dashboards.git.
+	// +required
+	Repository string `json:"repository"`
+	// The branch of the git repository.
+	// +optional
+	// +kubebuilder:default:="main"
+	Ref string `json:"ref,omitempty"`
+	// The directory of the dashboards in the repository. The dashboard JSON files in each
+	// sub directory are loaded into the grafana folder with the name of the sub directory.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// How often the repository is pulled, e.g. 30s or 5m.
+	// +optional
+	// +kubebuilder:default:="5m"
+	// +kubebuilder:validation:Pattern=`^[0-9]+(s|m|h)$`
+	Interval string `json:"interval,omitempty"`
+	// The name of the secret in the namespace of the operands which contains the username
+	// and the password or the token of the repository, in the username and password keys.
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+}
+
+// GrafanaPlugin is a plugin of grafana from the allowlist of the operator.
+type GrafanaPlugin struct {
+	// The ID of the plugin.
+	// +required
+	// +kubebuilder:validation:Enum=grafana-piechart-panel;grafana-worldmap-panel;grafana-clock-panel;grafana-polystat-panel;natel-discrete-panel;vonage-status-panel;yesoreyeram-boomtable-panel
+	Name string `json:"name"`
+	// The version of the plugin, the latest version is installed if it is empty.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+\.[0-9]+\.[0-9]+$`
+	Version string `json:"version,omitempty"`
+}
+
+// GrafanaPersistenceSpec is the spec of the persistence of grafana.
+type GrafanaPersistenceSpec struct {
+	// Store the data of grafana in a persistent volume claim instead of an emptyDir.
+	// +optional
+	Enabled bool `json:"enabled"`
+	// The size of the persistent volume claim, it is created with the storage class of
+	// the storage config.
+	// +optional
+	// +kubebuilder:default:="1Gi"
+	StorageSize string `json:"storageSize,omitempty"`
+}
+
+// GrafanaSMTPSpec is the spec of the SMTP server of grafana.
+type GrafanaSMTPSpec struct {
+	// The host and the port of the SMTP server, e.g. smtp.example.com:587.
+	// +required
+	Host string `json:"host"`
+	// The address which the emails are sent from.
+	// +required
+	FromAddress string `json:"fromAddress"`
+	// The user to authenticate with the SMTP server.
+	// +optional
+	User string `json:"user,omitempty"`
+	// The key of the secret in the namespace of the operands which contains the password
+	// of the user.
+	// +optional
+	PasswordSecret *corev1.SecretKeySelector `json:"passwordSecret,omitempty"`
+	// Skip the verification of the certificate of the SMTP server.
+	// +optional
+	SkipVerify bool `json:"skipVerify,omitempty"`
+}
+
+// AlertReceiver sends the alerts with the severities from the clusters to one integration.
+type AlertReceiver struct {
+	// The name of the receiver, it must be unique in the alert receivers.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Route the alerts with one of the severities, e.g. critical. All the alerts are
+	// routed if it is empty.
+	// +optional
+	Severities []string `json:"severities,omitempty"`
+	// Route the alerts from one of the managed clusters. The alerts from all the clusters
+	// are routed if it is empty.
+	// +optional
+	Clusters []string `json:"clusters,omitempty"`
+	// Whether to notify about the resolved alerts.
+	// +optional
+	SendResolved *bool `json:"sendResolved,omitempty"`
+	// Send the alerts to PagerDuty. Only one of pagerDuty, slack and msTeams can be set.
+	// +optional
+	PagerDuty *PagerDutyReceiver `json:"pagerDuty,omitempty"`
+	// Send the alerts to a slack channel. Only one of pagerDuty, slack and msTeams can be set.
+	// +optional
+	Slack *SlackReceiver `json:"slack,omitempty"`
+	// Send the alerts to a Microsoft Teams channel. Only one of pagerDuty, slack and msTeams can be set.
+	// +optional
+	MSTeams *MSTeamsReceiver `json:"msTeams,omitempty"`
+}
+
+// PagerDutyReceiver sends the alerts to a PagerDuty service with the Events API v2.
+type PagerDutyReceiver struct {
+	// The key of the secret in the namespace of the operands which contains the integration
+	// key of the PagerDuty service.
+	// +required
+	RoutingKeySecret corev1.SecretKeySelector `json:"routingKeySecret"`
+}
+
+// SlackReceiver sends the alerts to a slack channel.
+type SlackReceiver struct {
+	// The key of the secret in the namespace of the operands which contains the incoming
+	// webhook URL of slack.
+	// +required
+	APIURLSecret corev1.SecretKeySelector `json:"apiURLSecret"`
+	// The channel or the user to send the alerts to.
+	// +optional
+	Channel string `json:"channel,omitempty"`
+}
+
+// MSTeamsReceiver sends the alerts to a Microsoft Teams channel. The alertmanager cannot post
+// the messages of Teams, so the alerts are sent to a bridge such as prometheus-msteams which
+// converts them to the message cards of the incoming webhook of the channel.
+type MSTeamsReceiver struct {
+	// The key of the secret in the namespace of the operands which contains the URL of the
+	// bridge for the channel.
+	// +required
+	WebhookURLSecret corev1.SecretKeySelector `json:"webhookURLSecret"`
+}
+
+// CardinalityGuardSpec is the spec of the detection and throttling of the high-cardinality metrics.
+type CardinalityGuardSpec struct {
+	// Enable or disable the detection of the high-cardinality metrics.
+	// +optional
+	Enabled bool `json:"enabled"`
+	// The number of the series of a metric in a cluster over which the metric is alerted.
+	// +optional
+	// +kubebuilder:default:=10000
+	// +kubebuilder:validation:Minimum=1
+	SeriesThreshold int32 `json:"seriesThreshold,omitempty"`
+	// Stop forwarding the metric from the cluster temporarily when its series are over the
+	// threshold and more than doubled in the last hour.
+	// +optional
+	AutoThrottle bool `json:"autoThrottle,omitempty"`
+	// The duration which the metric is throttled for, e.g. 30m or 2h.
+	// +optional
+	// +kubebuilder:default:="1h"
+	// +kubebuilder:validation:Pattern=`^[0-9]+(m|h)$`
+	ThrottleDuration string `json:"throttleDuration,omitempty"`
+}
+
+// LogsCollectionSpec is the spec of the logs collection.
+type LogsCollectionSpec struct {
+	// Enable or disable the log forwarder on the managed clusters.
+	// +optional
+	Enabled bool `json:"enabled"`
+	// The URL of the external Loki which receives the logs, e.g. https://loki.example.com.
+	// The Loki on the hub is deployed when it is not set.
+	// +optional
+	ExternalLokiURL string `json:"externalLokiURL,omitempty"`
+	// The amount of storage applied to the Loki stateful set on the hub.
+	// +optional
+	// +kubebuilder:default:="10Gi"
+	StorageSize string `json:"storageSize,omitempty"`
+}
+
+// EventsCollectionSpec is the spec of the kubernetes events collection.
+type EventsCollectionSpec struct {
+	// Enable or disable the events forwarder on the managed clusters.
+	// +optional
+	Enabled bool `json:"enabled"`
+	// The lowest type of the events which are forwarded, Normal forwards all the events
+	// and Warning only forwards the warning events.
+	// +optional
+	// +kubebuilder:default:=Warning
+	// +kubebuilder:validation:Enum=Normal;Warning
+	Severity string `json:"severity,omitempty"`
+	// Only the events in these namespaces are forwarded. The events in all the
+	// namespaces are forwarded when it is not set.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// The events in these namespaces are not forwarded.
+	// +optional
+	ExcludedNamespaces []string `json:"excludedNamespaces,omitempty"`
+}
+
+// TracingSpec is the spec of the traces collection.
+type TracingSpec struct {
+	// Enable or disable the traces forwarder on the managed clusters.
+	// +optional
+	Enabled bool `json:"enabled"`
+	// The type of the tracing backend, tempo receives the traces with the OpenTelemetry
+	// protocol and jaeger receives them with the jaeger gRPC protocol.
+	// +optional
+	// +kubebuilder:default:=tempo
+	// +kubebuilder:validation:Enum=tempo;jaeger
+	Backend string `json:"backend,omitempty"`
+	// The gRPC endpoint of the tracing backend which the traces are exported to,
+	// e.g. tempo-distributor.tracing.svc:4317 or jaeger-collector.tracing.svc:14250.
+	// +required
+	Endpoint string `json:"endpoint"`
+	// The URL of the tracing backend which grafana queries the traces from,
+	// e.g. http://tempo-query-frontend.tracing.svc:3100 or http://jaeger-query.tracing.svc:16686.
+	// +required
+	QueryURL string `json:"queryURL"`
+	// The name of the secret in the open-cluster-management-observability namespace which contains
+	// the CA bundle of the tracing backend in the ca.crt key. The service CA of the hub is used by default.
+	// +optional
+	CASecretName string `json:"caSecretName,omitempty"`
+	// Export the traces to the tracing backend in plaintext. The traces are exported with TLS by default.
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// TelemetrySpec is the spec of the anonymous usage and health reports of the observability.
+type TelemetrySpec struct {
+	// Enable or disable the reports.
+	Enabled bool `json:"enabled"`
+	// The URL which the reports are posted to as JSON.
+	// +required
+	Endpoint string `json:"endpoint"`
+	// How often the reports are posted, e.g. 12h or 24h.
+	// +optional
+	// +kubebuilder:default:="24h"
+	// +kubebuilder:validation:Pattern=`^[0-9]+(m|h)$`
+	Interval string `json:"interval,omitempty"`
+}
+
+// FederatedHubSpec is the query endpoint of the observability of another hub.
+type FederatedHubSpec struct {
+	// The name of the hub, which the datasource is named after as Observatorium-hub-<name>.
+	// +required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// The URL of the Prometheus compatible query API of the hub, e.g. the route of its rbac-query-proxy.
+	// +required
+	QueryURL string `json:"queryURL"`
+	// The name of the secret in the open-cluster-management-observability namespace which contains
+	// the bearer token in the token key and the CA of the server certificate in the ca.crt key of the hub.
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+}
+
+// ClusterAPIProbeSpec is the spec of the probes of the API servers of the managed clusters.
+type ClusterAPIProbeSpec struct {
+	// Enable or disable the probes.
+	Enabled bool `json:"enabled"`
+	// How often each managed cluster is probed, e.g. 30s or 1m.
+	// +optional
+	// +kubebuilder:default:="1m"
+	// +kubebuilder:validation:Pattern=`^[0-9]+(s|m|h)$`
+	Interval string `json:"interval,omitempty"`
+	// The timeout of each probe, the API server which does not respond in time is unreachable.
+	// +optional
+	// +kubebuilder:default:="5s"
+	// +kubebuilder:validation:Pattern=`^[0-9]+(s|m)$`
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// BackfillSpec is the spec of the backfill of the history of the freshly onboarded managed clusters.
+type BackfillSpec struct {
+	// Enable or disable the backfill. Only the managed clusters which are onboarded while it is
+	// enabled are backfilled, each of them once.
+	Enabled bool `json:"enabled"`
+	// How much of the recent history of the local Prometheus is backfilled, e.g. 6h.
+	// +optional
+	// +kubebuilder:default:="6h"
+	// +kubebuilder:validation:Pattern=`^[0-9]+(m|h)$`
+	Lookback string `json:"lookback,omitempty"`
+	// The address of the local Prometheus on the managed clusters which the history is read from.
+	// +optional
+	// +kubebuilder:default:="https://thanos-querier.openshift-monitoring.svc:9091"
+	PrometheusURL string `json:"prometheusURL,omitempty"`
+	// The secret in the namespace of the operands with the configuration of the object storage of
+	// the hub which the backfilled blocks are uploaded to, in the same format as the object storage
+	// of the observability components. It is copied to the managed clusters, so the credentials
+	// should be limited to write the bucket.
+	// +required
+	UploadStorage *observabilityshared.PreConfiguredStorage `json:"uploadStorage,omitempty"`
+}
+
+// QueryEndpointSpec is the spec of the query endpoint of the fleet for the external systems.
+type QueryEndpointSpec struct {
+	// Enable or disable the query endpoint.
+	Enabled bool `json:"enabled"`
+	// The host of the route of the query endpoint, it is generated by the ingress controller
+	// if it is not set.
+	// +optional
+	Host string `json:"host,omitempty"`
+}
+
+// ClusterSetTenant maps a list of ManagedClusterSets to a tenant.
+type ClusterSetTenant struct {
+	// The name of the tenant, it is used as the value of the tenant label.
+	// +required
+	Name string `json:"name"`
+	// The names of the ManagedClusterSets which belong to the tenant.
+	// +required
+	ClusterSets []string `json:"clusterSets"`
+	// The namespaces on the hub where the tenant manages the AlertmanagerConfigs. The alerts
+	// routed by those AlertmanagerConfigs are scoped to the tenant.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// MaintenanceWindow is a one-off or recurring window of the planned maintenance of the managed clusters.
+type MaintenanceWindow struct {
+	// The name of the window, it must be unique in the maintenance windows.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// The managed clusters in the window.
+	// +optional
+	Clusters []string `json:"clusters,omitempty"`
+	// The ManagedClusterSets whose managed clusters are in the window.
+	// +optional
+	ClusterSets []string `json:"clusterSets,omitempty"`
+	// The start of the recurring window in the cron format in UTC, e.g. "0 2 * * 6" for 02:00 on
+	// every Saturday. Either schedule and duration, or start and end are required.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+	// The duration of the recurring window, e.g. 2h, at most 7 days.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// The start of the one-off window.
+	// +optional
+	Start *metav1.Time `json:"start,omitempty"`
+	// The end of the one-off window.
+	// +optional
+	End *metav1.Time `json:"end,omitempty"`
+	// Pause the metrics collection of the clusters during the window, so that the maintenance
+	// does not skew the SLO data.
+	// +optional
+	PauseCollection bool `json:"pauseCollection,omitempty"`
+}
+
+// RegionalGatewaySpec selects the regions and their gateway clusters by the ManagedCluster labels.
+type RegionalGatewaySpec struct {
+	// The key of the ManagedCluster label whose value is the region of the cluster.
+	// +required
+	RegionLabel string `json:"regionLabel"`
+	// The key of the ManagedCluster label which designates the cluster as the gateway
+	// of its region when the value is "true". The URL of the gateway, which is exposed
+	// on the gateway cluster, is set in the observability.open-cluster-management.io/gateway-url
+	// annotation of the ManagedCluster.
+	// +optional
+	// +kubebuilder:default:="observability.open-cluster-management.io/gateway"
+	GatewayLabel string `json:"gatewayLabel,omitempty"`
 }
 
 // RetentionConfig is the spec of retention configurations.
@@ -120,6 +976,63 @@ type StorageConfig struct {
 	// +optional
 	// +kubebuilder:default:="10Gi"
 	StoreStorageSize string `json:"storeStorageSize,omitempty"`
+	// The short-lived cloud credentials of the thanos components for the object storage, the
+	// static keys in metricObjectStorage are not required when it is set.
+	// +optional
+	WorkloadIdentity *WorkloadIdentitySpec `json:"workloadIdentity,omitempty"`
+	// The server-side encryption of the blocks which the thanos components write to the object storage.
+	// +optional
+	Encryption *ObjStorageEncryptionSpec `json:"encryption,omitempty"`
+	// Verify the blocks in the object storage periodically to report the corrupted and overlapping blocks.
+	// +optional
+	BucketVerify *BucketVerifySpec `json:"bucketVerify,omitempty"`
+}
+
+// BucketVerifySpec is the spec of the periodic verification of the blocks in the object storage.
+type BucketVerifySpec struct {
+	// The schedule in the cron format of the verification.
+	// +optional
+	// +kubebuilder:default:="0 */6 * * *"
+	Schedule string `json:"schedule,omitempty"`
+	// The issues which are verified, see the --issues flag of thanos tools bucket verify.
+	// +optional
+	// +kubebuilder:default:={overlapped_blocks,index_known_issues}
+	Issues []string `json:"issues,omitempty"`
+}
+
+// ObjStorageEncryptionSpec is the spec of the server-side encryption of the object storage.
+// Only S3 encrypts the writes with the key of the request, the customer-managed keys of GCS and
+// Azure are set as the default keys of the bucket and the storage account.
+type ObjStorageEncryptionSpec struct {
+	// The type of the server-side encryption of S3, SSE-S3 for the keys managed by S3, or SSE-KMS
+	// for the customer-managed key in AWS KMS.
+	// +required
+	// +kubebuilder:validation:Enum=SSE-S3;SSE-KMS
+	Type string `json:"type"`
+	// The ID of the customer-managed key in AWS KMS, required by SSE-KMS.
+	// +optional
+	KMSKeyID string `json:"kmsKeyID,omitempty"`
+	// The encryption context of SSE-KMS.
+	// +optional
+	KMSEncryptionContext map[string]string `json:"kmsEncryptionContext,omitempty"`
+}
+
+// WorkloadIdentitySpec is the spec of the workload identity of the object storage.
+type WorkloadIdentitySpec struct {
+	// The cloud provider of the workload identity, AWS for the IAM roles for service accounts on
+	// EKS, GCP for the workload identity on GKE, or Azure for the workload identity on AKS.
+	// +required
+	// +kubebuilder:validation:Enum=AWS;GCP;Azure
+	Provider string `json:"provider"`
+	// The ARN of the IAM role which the thanos components assume on AWS.
+	// +optional
+	RoleARN string `json:"roleARN,omitempty"`
+	// The email of the google service account which the thanos components impersonate on GCP.
+	// +optional
+	GCPServiceAccount string `json:"gcpServiceAccount,omitempty"`
+	// The client ID of the managed identity which the thanos components use on Azure.
+	// +optional
+	ClientID string `json:"clientID,omitempty"`
 }
 
 // MultiClusterObservabilityStatus defines the observed state of MultiClusterObservability
@@ -130,6 +1043,28 @@ type MultiClusterObservabilityStatus struct {
 	// Represents the status of each deployment
 	// +optional
 	Conditions []observabilityshared.Condition `json:"conditions,omitempty"`
+	// The rollout status of each observability component on the hub, so that the progress of the
+	// install and the upgrade can be followed from the MultiClusterObservability.
+	// +optional
+	Components []ComponentStatus `json:"components,omitempty"`
+}
+
+// ComponentStatus is the rollout status of an observability component on the hub.
+type ComponentStatus struct {
+	// The name of the deployment or the statefulset of the component.
+	Name string `json:"name"`
+	// The kind of the component, Deployment or StatefulSet.
+	Kind string `json:"kind"`
+	// The number of the replicas which the component desires.
+	DesiredReplicas int32 `json:"desiredReplicas"`
+	// The number of the replicas which are ready.
+	ReadyReplicas int32 `json:"readyReplicas"`
+	// The number of the replicas which run the latest pod template of the component.
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+	// The last error of the rollout of the component, e.g. the component is not created yet or its
+	// pods fail to pull the image or crash.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,101 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	observabilityshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+)
+
+// MetricName is the name of a metric
+// +kubebuilder:validation:Pattern=`^[a-zA-Z_:][a-zA-Z0-9_:]*$`
+type MetricName string
+
+// ObservabilityMetricsAllowlistSpec defines the metrics which are collected from the managed clusters
+// in addition to the default metrics allowlist
+type ObservabilityMetricsAllowlistSpec struct {
+	// The names of the metrics to collect.
+	// +optional
+	Names []MetricName `json:"names,omitempty"`
+	// The series selectors of the metrics to collect, e.g. __name__="etcd_disk_wal_fsync_duration_seconds_bucket",job="etcd".
+	// +optional
+	Matches []string `json:"matches,omitempty"`
+	// The metrics which are renamed when they are collected.
+	// +optional
+	Renames []MetricRename `json:"renames,omitempty"`
+	// The recording rules which are evaluated by the metrics collector on the managed clusters,
+	// the recorded metrics are collected.
+	// +optional
+	RecordingRules []MetricsRecordingRule `json:"recordingRules,omitempty"`
+	// The intervals in which the metrics are collected instead of the interval of the observability addon.
+	// +optional
+	CollectIntervals []MetricsCollectInterval `json:"collectIntervals,omitempty"`
+}
+
+// MetricRename renames a metric when it is collected
+type MetricRename struct {
+	// The name of the metric on the managed cluster.
+	// +required
+	From MetricName `json:"from"`
+	// The name of the metric on the hub.
+	// +required
+	To MetricName `json:"to"`
+}
+
+// MetricsRecordingRule is a recording rule which is evaluated by the metrics collector
+type MetricsRecordingRule struct {
+	// The name of the recorded metric.
+	// +required
+	Record MetricName `json:"record"`
+	// The expression which is evaluated on the managed cluster.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Expr string `json:"expr"`
+}
+
+// MetricsCollectInterval is the interval in which the metrics are collected
+type MetricsCollectInterval struct {
+	// The names of the metrics.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	Names []MetricName `json:"names"`
+	// The interval in which the metrics are collected, e.g. 30s or 5m.
+	// +required
+	// +kubebuilder:validation:Pattern=`^[0-9]+(s|m|h)$`
+	Interval string `json:"interval"`
+}
+
+// ObservabilityMetricsAllowlistStatus defines the observed state of ObservabilityMetricsAllowlist
+type ObservabilityMetricsAllowlistStatus struct {
+	// Represents whether the metrics are merged into the allowlist of the managed clusters
+	// +optional
+	Conditions []observabilityshared.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// ObservabilityMetricsAllowlist declares the metrics which are collected from the managed clusters in addition
+// to the default metrics allowlist. All the allowlists are merged, so that each team can own its allowlist.
+// +kubebuilder:resource:path=observabilitymetricsallowlists,scope=Cluster,shortName=oma
+type ObservabilityMetricsAllowlist struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ObservabilityMetricsAllowlistSpec   `json:"spec,omitempty"`
+	Status ObservabilityMetricsAllowlistStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// ObservabilityMetricsAllowlistList contains a list of ObservabilityMetricsAllowlist
+type ObservabilityMetricsAllowlistList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ObservabilityMetricsAllowlist `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ObservabilityMetricsAllowlist{}, &ObservabilityMetricsAllowlistList{})
+}
@@ -23,9 +23,1177 @@ package v1beta2
 import (
 	"github.com/open-cluster-management/multicluster-observability-operator/api/shared"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertReceiver) DeepCopyInto(out *AlertReceiver) {
+	*out = *in
+	if in.Severities != nil {
+		in, out := &in.Severities, &out.Severities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SendResolved != nil {
+		in, out := &in.SendResolved, &out.SendResolved
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PagerDuty != nil {
+		in, out := &in.PagerDuty, &out.PagerDuty
+		*out = new(PagerDutyReceiver)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Slack != nil {
+		in, out := &in.Slack, &out.Slack
+		*out = new(SlackReceiver)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MSTeams != nil {
+		in, out := &in.MSTeams, &out.MSTeams
+		*out = new(MSTeamsReceiver)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertReceiver.
+func (in *AlertReceiver) DeepCopy() *AlertReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerConfig) DeepCopyInto(out *AlertmanagerConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerConfig.
+func (in *AlertmanagerConfig) DeepCopy() *AlertmanagerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AlertmanagerConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerConfigList) DeepCopyInto(out *AlertmanagerConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AlertmanagerConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerConfigList.
+func (in *AlertmanagerConfigList) DeepCopy() *AlertmanagerConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AlertmanagerConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerConfigSpec) DeepCopyInto(out *AlertmanagerConfigSpec) {
+	*out = *in
+	in.Route.DeepCopyInto(&out.Route)
+	if in.Receivers != nil {
+		in, out := &in.Receivers, &out.Receivers
+		*out = make([]AlertmanagerReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerConfigSpec.
+func (in *AlertmanagerConfigSpec) DeepCopy() *AlertmanagerConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerConfigStatus) DeepCopyInto(out *AlertmanagerConfigStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]shared.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerConfigStatus.
+func (in *AlertmanagerConfigStatus) DeepCopy() *AlertmanagerConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerEmailConfig) DeepCopyInto(out *AlertmanagerEmailConfig) {
+	*out = *in
+	if in.SendResolved != nil {
+		in, out := &in.SendResolved, &out.SendResolved
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerEmailConfig.
+func (in *AlertmanagerEmailConfig) DeepCopy() *AlertmanagerEmailConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerEmailConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerMatcher) DeepCopyInto(out *AlertmanagerMatcher) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerMatcher.
+func (in *AlertmanagerMatcher) DeepCopy() *AlertmanagerMatcher {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerMatcher)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerReceiver) DeepCopyInto(out *AlertmanagerReceiver) {
+	*out = *in
+	if in.WebhookConfigs != nil {
+		in, out := &in.WebhookConfigs, &out.WebhookConfigs
+		*out = make([]AlertmanagerWebhookConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SlackConfigs != nil {
+		in, out := &in.SlackConfigs, &out.SlackConfigs
+		*out = make([]AlertmanagerSlackConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EmailConfigs != nil {
+		in, out := &in.EmailConfigs, &out.EmailConfigs
+		*out = make([]AlertmanagerEmailConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerReceiver.
+func (in *AlertmanagerReceiver) DeepCopy() *AlertmanagerReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerRoute) DeepCopyInto(out *AlertmanagerRoute) {
+	*out = *in
+	if in.GroupBy != nil {
+		in, out := &in.GroupBy, &out.GroupBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Matchers != nil {
+		in, out := &in.Matchers, &out.Matchers
+		*out = make([]AlertmanagerMatcher, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerRoute.
+func (in *AlertmanagerRoute) DeepCopy() *AlertmanagerRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerSlackConfig) DeepCopyInto(out *AlertmanagerSlackConfig) {
+	*out = *in
+	in.APIURLSecret.DeepCopyInto(&out.APIURLSecret)
+	if in.SendResolved != nil {
+		in, out := &in.SendResolved, &out.SendResolved
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerSlackConfig.
+func (in *AlertmanagerSlackConfig) DeepCopy() *AlertmanagerSlackConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerSlackConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertmanagerWebhookConfig) DeepCopyInto(out *AlertmanagerWebhookConfig) {
+	*out = *in
+	if in.URLSecret != nil {
+		in, out := &in.URLSecret, &out.URLSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SendResolved != nil {
+		in, out := &in.SendResolved, &out.SendResolved
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertmanagerWebhookConfig.
+func (in *AlertmanagerWebhookConfig) DeepCopy() *AlertmanagerWebhookConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertmanagerWebhookConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalingSpec) DeepCopyInto(out *AutoscalingSpec) {
+	*out = *in
+	if in.Receive != nil {
+		in, out := &in.Receive, &out.Receive
+		*out = new(ComponentAutoscalingSpec)
+		**out = **in
+	}
+	if in.Query != nil {
+		in, out := &in.Query, &out.Query
+		*out = new(ComponentAutoscalingSpec)
+		**out = **in
+	}
+	if in.QueryFrontend != nil {
+		in, out := &in.QueryFrontend, &out.QueryFrontend
+		*out = new(ComponentAutoscalingSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalingSpec.
+func (in *AutoscalingSpec) DeepCopy() *AutoscalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackfillSpec) DeepCopyInto(out *BackfillSpec) {
+	*out = *in
+	if in.UploadStorage != nil {
+		in, out := &in.UploadStorage, &out.UploadStorage
+		*out = new(shared.PreConfiguredStorage)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackfillSpec.
+func (in *BackfillSpec) DeepCopy() *BackfillSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackfillSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketVerifySpec) DeepCopyInto(out *BucketVerifySpec) {
+	*out = *in
+	if in.Issues != nil {
+		in, out := &in.Issues, &out.Issues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketVerifySpec.
+func (in *BucketVerifySpec) DeepCopy() *BucketVerifySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketVerifySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CardinalityGuardSpec) DeepCopyInto(out *CardinalityGuardSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CardinalityGuardSpec.
+func (in *CardinalityGuardSpec) DeepCopy() *CardinalityGuardSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CardinalityGuardSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterAPIProbeSpec) DeepCopyInto(out *ClusterAPIProbeSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterAPIProbeSpec.
+func (in *ClusterAPIProbeSpec) DeepCopy() *ClusterAPIProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterAPIProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterIdentitySpec) DeepCopyInto(out *ClusterIdentitySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterIdentitySpec.
+func (in *ClusterIdentitySpec) DeepCopy() *ClusterIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSetTenant) DeepCopyInto(out *ClusterSetTenant) {
+	*out = *in
+	if in.ClusterSets != nil {
+		in, out := &in.ClusterSets, &out.ClusterSets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSetTenant.
+func (in *ClusterSetTenant) DeepCopy() *ClusterSetTenant {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSetTenant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentAutoscalingSpec) DeepCopyInto(out *ComponentAutoscalingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentAutoscalingSpec.
+func (in *ComponentAutoscalingSpec) DeepCopy() *ComponentAutoscalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentAutoscalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentStatus) DeepCopyInto(out *ComponentStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentStatus.
+func (in *ComponentStatus) DeepCopy() *ComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DashboardSyncSpec) DeepCopyInto(out *DashboardSyncSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DashboardSyncSpec.
+func (in *DashboardSyncSpec) DeepCopy() *DashboardSyncSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DashboardSyncSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventsCollectionSpec) DeepCopyInto(out *EventsCollectionSpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedNamespaces != nil {
+		in, out := &in.ExcludedNamespaces, &out.ExcludedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventsCollectionSpec.
+func (in *EventsCollectionSpec) DeepCopy() *EventsCollectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EventsCollectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalMetricsStoreSpec) DeepCopyInto(out *ExternalMetricsStoreSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalMetricsStoreSpec.
+func (in *ExternalMetricsStoreSpec) DeepCopy() *ExternalMetricsStoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalMetricsStoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalSecret) DeepCopyInto(out *ExternalSecret) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecret.
+func (in *ExternalSecret) DeepCopy() *ExternalSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalSecretStoreRef) DeepCopyInto(out *ExternalSecretStoreRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretStoreRef.
+func (in *ExternalSecretStoreRef) DeepCopy() *ExternalSecretStoreRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSecretStoreRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalSecretsSpec) DeepCopyInto(out *ExternalSecretsSpec) {
+	*out = *in
+	out.SecretStoreRef = in.SecretStoreRef
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]ExternalSecret, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretsSpec.
+func (in *ExternalSecretsSpec) DeepCopy() *ExternalSecretsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSecretsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederatedHubSpec) DeepCopyInto(out *FederatedHubSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederatedHubSpec.
+func (in *FederatedHubSpec) DeepCopy() *FederatedHubSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FederatedHubSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FleetSLO) DeepCopyInto(out *FleetSLO) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FleetSLO.
+func (in *FleetSLO) DeepCopy() *FleetSLO {
+	if in == nil {
+		return nil
+	}
+	out := new(FleetSLO)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FleetSLO) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FleetSLOList) DeepCopyInto(out *FleetSLOList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FleetSLO, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FleetSLOList.
+func (in *FleetSLOList) DeepCopy() *FleetSLOList {
+	if in == nil {
+		return nil
+	}
+	out := new(FleetSLOList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FleetSLOList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FleetSLOSpec) DeepCopyInto(out *FleetSLOSpec) {
+	*out = *in
+	out.SLI = in.SLI
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FleetSLOSpec.
+func (in *FleetSLOSpec) DeepCopy() *FleetSLOSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FleetSLOSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FleetSLOStatus) DeepCopyInto(out *FleetSLOStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]shared.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FleetSLOStatus.
+func (in *FleetSLOStatus) DeepCopy() *FleetSLOStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FleetSLOStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayAuthSpec) DeepCopyInto(out *GatewayAuthSpec) {
+	*out = *in
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(GatewayOIDCSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAccountToken != nil {
+		in, out := &in.ServiceAccountToken, &out.ServiceAccountToken
+		*out = new(GatewayServiceAccountTokenSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayAuthSpec.
+func (in *GatewayAuthSpec) DeepCopy() *GatewayAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayOIDCSpec) DeepCopyInto(out *GatewayOIDCSpec) {
+	*out = *in
+	if in.IssuerCA != nil {
+		in, out := &in.IssuerCA, &out.IssuerCA
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientSecret != nil {
+		in, out := &in.ClientSecret, &out.ClientSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WriteUsers != nil {
+		in, out := &in.WriteUsers, &out.WriteUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadUsers != nil {
+		in, out := &in.ReadUsers, &out.ReadUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayOIDCSpec.
+func (in *GatewayOIDCSpec) DeepCopy() *GatewayOIDCSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayOIDCSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayServiceAccountTokenSpec) DeepCopyInto(out *GatewayServiceAccountTokenSpec) {
+	*out = *in
+	if in.ClusterSelector != nil {
+		in, out := &in.ClusterSelector, &out.ClusterSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayServiceAccountTokenSpec.
+func (in *GatewayServiceAccountTokenSpec) DeepCopy() *GatewayServiceAccountTokenSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayServiceAccountTokenSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaPersistenceSpec) DeepCopyInto(out *GrafanaPersistenceSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaPersistenceSpec.
+func (in *GrafanaPersistenceSpec) DeepCopy() *GrafanaPersistenceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaPersistenceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaPlugin) DeepCopyInto(out *GrafanaPlugin) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaPlugin.
+func (in *GrafanaPlugin) DeepCopy() *GrafanaPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaSMTPSpec) DeepCopyInto(out *GrafanaSMTPSpec) {
+	*out = *in
+	if in.PasswordSecret != nil {
+		in, out := &in.PasswordSecret, &out.PasswordSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaSMTPSpec.
+func (in *GrafanaSMTPSpec) DeepCopy() *GrafanaSMTPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaSMTPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaSpec) DeepCopyInto(out *GrafanaSpec) {
+	*out = *in
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]GrafanaPlugin, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdminPasswordSecret != nil {
+		in, out := &in.AdminPasswordSecret, &out.AdminPasswordSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Persistence != nil {
+		in, out := &in.Persistence, &out.Persistence
+		*out = new(GrafanaPersistenceSpec)
+		**out = **in
+	}
+	if in.SMTP != nil {
+		in, out := &in.SMTP, &out.SMTP
+		*out = new(GrafanaSMTPSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DashboardSync != nil {
+		in, out := &in.DashboardSync, &out.DashboardSync
+		*out = new(DashboardSyncSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaSpec.
+func (in *GrafanaSpec) DeepCopy() *GrafanaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HighAvailabilitySpec) DeepCopyInto(out *HighAvailabilitySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HighAvailabilitySpec.
+func (in *HighAvailabilitySpec) DeepCopy() *HighAvailabilitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HighAvailabilitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HubUpgradeGateSpec) DeepCopyInto(out *HubUpgradeGateSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HubUpgradeGateSpec.
+func (in *HubUpgradeGateSpec) DeepCopy() *HubUpgradeGateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HubUpgradeGateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageManifestSpec) DeepCopyInto(out *ImageManifestSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageManifestSpec.
+func (in *ImageManifestSpec) DeepCopy() *ImageManifestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageManifestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntermediateCASpec) DeepCopyInto(out *IntermediateCASpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntermediateCASpec.
+func (in *IntermediateCASpec) DeepCopy() *IntermediateCASpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IntermediateCASpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogsCollectionSpec) DeepCopyInto(out *LogsCollectionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogsCollectionSpec.
+func (in *LogsCollectionSpec) DeepCopy() *LogsCollectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogsCollectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MSTeamsReceiver) DeepCopyInto(out *MSTeamsReceiver) {
+	*out = *in
+	in.WebhookURLSecret.DeepCopyInto(&out.WebhookURLSecret)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MSTeamsReceiver.
+func (in *MSTeamsReceiver) DeepCopy() *MSTeamsReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(MSTeamsReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterSets != nil {
+		in, out := &in.ClusterSets, &out.ClusterSets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Start != nil {
+		in, out := &in.Start, &out.Start
+		*out = new(metav1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.End != nil {
+		in, out := &in.End, &out.End
+		*out = new(metav1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricRename) DeepCopyInto(out *MetricRename) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricRename.
+func (in *MetricRename) DeepCopy() *MetricRename {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricRename)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsCollectInterval) DeepCopyInto(out *MetricsCollectInterval) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]MetricName, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsCollectInterval.
+func (in *MetricsCollectInterval) DeepCopy() *MetricsCollectInterval {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsCollectInterval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsImport) DeepCopyInto(out *MetricsImport) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsImport.
+func (in *MetricsImport) DeepCopy() *MetricsImport {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MetricsImport) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsImportList) DeepCopyInto(out *MetricsImportList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MetricsImport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsImportList.
+func (in *MetricsImportList) DeepCopy() *MetricsImportList {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsImportList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MetricsImportList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsImportSource) DeepCopyInto(out *MetricsImportSource) {
+	*out = *in
+	if in.PrometheusTSDB != nil {
+		in, out := &in.PrometheusTSDB, &out.PrometheusTSDB
+		*out = new(PrometheusTSDBSource)
+		**out = **in
+	}
+	if in.ThanosBucket != nil {
+		in, out := &in.ThanosBucket, &out.ThanosBucket
+		*out = new(ThanosBucketSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsImportSource.
+func (in *MetricsImportSource) DeepCopy() *MetricsImportSource {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsImportSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsImportSpec) DeepCopyInto(out *MetricsImportSpec) {
+	*out = *in
+	if in.ExternalLabels != nil {
+		in, out := &in.ExternalLabels, &out.ExternalLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Source.DeepCopyInto(&out.Source)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsImportSpec.
+func (in *MetricsImportSpec) DeepCopy() *MetricsImportSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsImportSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsImportStatus) DeepCopyInto(out *MetricsImportStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]shared.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsImportStatus.
+func (in *MetricsImportStatus) DeepCopy() *MetricsImportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsImportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsRecordingRule) DeepCopyInto(out *MetricsRecordingRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsRecordingRule.
+func (in *MetricsRecordingRule) DeepCopy() *MetricsRecordingRule {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsRecordingRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MultiClusterObservability) DeepCopyInto(out *MultiClusterObservability) {
 	*out = *in
@@ -35,18 +1203,313 @@ func (in *MultiClusterObservability) DeepCopyInto(out *MultiClusterObservability
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterObservability.
-func (in *MultiClusterObservability) DeepCopy() *MultiClusterObservability {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterObservability.
+func (in *MultiClusterObservability) DeepCopy() *MultiClusterObservability {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterObservability)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MultiClusterObservability) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterObservabilityList) DeepCopyInto(out *MultiClusterObservabilityList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MultiClusterObservability, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterObservabilityList.
+func (in *MultiClusterObservabilityList) DeepCopy() *MultiClusterObservabilityList {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterObservabilityList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MultiClusterObservabilityList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterObservabilitySpec) DeepCopyInto(out *MultiClusterObservabilitySpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RetentionConfig != nil {
+		in, out := &in.RetentionConfig, &out.RetentionConfig
+		*out = new(RetentionConfig)
+		**out = **in
+	}
+	if in.StorageConfig != nil {
+		in, out := &in.StorageConfig, &out.StorageConfig
+		*out = new(StorageConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ObservabilityAddonSpec != nil {
+		in, out := &in.ObservabilityAddonSpec, &out.ObservabilityAddonSpec
+		*out = new(shared.ObservabilityAddonSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InjectedClusterLabels != nil {
+		in, out := &in.InjectedClusterLabels, &out.InjectedClusterLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExternalLabels != nil {
+		in, out := &in.ExternalLabels, &out.ExternalLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ClusterSetTenants != nil {
+		in, out := &in.ClusterSetTenants, &out.ClusterSetTenants
+		*out = make([]ClusterSetTenant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RegionalGateway != nil {
+		in, out := &in.RegionalGateway, &out.RegionalGateway
+		*out = new(RegionalGatewaySpec)
+		**out = **in
+	}
+	if in.LogsCollection != nil {
+		in, out := &in.LogsCollection, &out.LogsCollection
+		*out = new(LogsCollectionSpec)
+		**out = **in
+	}
+	if in.Tracing != nil {
+		in, out := &in.Tracing, &out.Tracing
+		*out = new(TracingSpec)
+		**out = **in
+	}
+	if in.EventsCollection != nil {
+		in, out := &in.EventsCollection, &out.EventsCollection
+		*out = new(EventsCollectionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CardinalityGuard != nil {
+		in, out := &in.CardinalityGuard, &out.CardinalityGuard
+		*out = new(CardinalityGuardSpec)
+		**out = **in
+	}
+	if in.AlertReceivers != nil {
+		in, out := &in.AlertReceivers, &out.AlertReceivers
+		*out = make([]AlertReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Grafana != nil {
+		in, out := &in.Grafana, &out.Grafana
+		*out = new(GrafanaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalMetricsStore != nil {
+		in, out := &in.ExternalMetricsStore, &out.ExternalMetricsStore
+		*out = new(ExternalMetricsStoreSpec)
+		**out = **in
+	}
+	if in.ExternalSecrets != nil {
+		in, out := &in.ExternalSecrets, &out.ExternalSecrets
+		*out = new(ExternalSecretsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HubUpgradeGate != nil {
+		in, out := &in.HubUpgradeGate, &out.HubUpgradeGate
+		*out = new(HubUpgradeGateSpec)
+		**out = **in
+	}
+	if in.HighAvailability != nil {
+		in, out := &in.HighAvailability, &out.HighAvailability
+		*out = new(HighAvailabilitySpec)
+		**out = **in
+	}
+	if in.PriorityClasses != nil {
+		in, out := &in.PriorityClasses, &out.PriorityClasses
+		*out = new(PriorityClassesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(AutoscalingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PlacementRef != nil {
+		in, out := &in.PlacementRef, &out.PlacementRef
+		*out = new(PlacementRefSpec)
+		**out = **in
+	}
+	if in.ImageManifest != nil {
+		in, out := &in.ImageManifest, &out.ImageManifest
+		*out = new(ImageManifestSpec)
+		**out = **in
+	}
+	if in.ClusterIdentity != nil {
+		in, out := &in.ClusterIdentity, &out.ClusterIdentity
+		*out = new(ClusterIdentitySpec)
+		**out = **in
+	}
+	if in.TLSConfig != nil {
+		in, out := &in.TLSConfig, &out.TLSConfig
+		*out = new(TLSConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GatewayAuth != nil {
+		in, out := &in.GatewayAuth, &out.GatewayAuth
+		*out = new(GatewayAuthSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Telemetry != nil {
+		in, out := &in.Telemetry, &out.Telemetry
+		*out = new(TelemetrySpec)
+		**out = **in
+	}
+	if in.FederatedHubs != nil {
+		in, out := &in.FederatedHubs, &out.FederatedHubs
+		*out = make([]FederatedHubSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClusterAPIProbe != nil {
+		in, out := &in.ClusterAPIProbe, &out.ClusterAPIProbe
+		*out = new(ClusterAPIProbeSpec)
+		**out = **in
+	}
+	if in.Backfill != nil {
+		in, out := &in.Backfill, &out.Backfill
+		*out = new(BackfillSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QueryEndpoint != nil {
+		in, out := &in.QueryEndpoint, &out.QueryEndpoint
+		*out = new(QueryEndpointSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterObservabilitySpec.
+func (in *MultiClusterObservabilitySpec) DeepCopy() *MultiClusterObservabilitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterObservabilitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultiClusterObservabilityStatus) DeepCopyInto(out *MultiClusterObservabilityStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]shared.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]ComponentStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterObservabilityStatus.
+func (in *MultiClusterObservabilityStatus) DeepCopy() *MultiClusterObservabilityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MultiClusterObservabilityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjStorageEncryptionSpec) DeepCopyInto(out *ObjStorageEncryptionSpec) {
+	*out = *in
+	if in.KMSEncryptionContext != nil {
+		in, out := &in.KMSEncryptionContext, &out.KMSEncryptionContext
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjStorageEncryptionSpec.
+func (in *ObjStorageEncryptionSpec) DeepCopy() *ObjStorageEncryptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjStorageEncryptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObservabilityMetricsAllowlist) DeepCopyInto(out *ObservabilityMetricsAllowlist) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObservabilityMetricsAllowlist.
+func (in *ObservabilityMetricsAllowlist) DeepCopy() *ObservabilityMetricsAllowlist {
 	if in == nil {
 		return nil
 	}
-	out := new(MultiClusterObservability)
+	out := new(ObservabilityMetricsAllowlist)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MultiClusterObservability) DeepCopyObject() runtime.Object {
+func (in *ObservabilityMetricsAllowlist) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -54,31 +1517,31 @@ func (in *MultiClusterObservability) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MultiClusterObservabilityList) DeepCopyInto(out *MultiClusterObservabilityList) {
+func (in *ObservabilityMetricsAllowlistList) DeepCopyInto(out *ObservabilityMetricsAllowlistList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]MultiClusterObservability, len(*in))
+		*out = make([]ObservabilityMetricsAllowlist, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterObservabilityList.
-func (in *MultiClusterObservabilityList) DeepCopy() *MultiClusterObservabilityList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObservabilityMetricsAllowlistList.
+func (in *ObservabilityMetricsAllowlistList) DeepCopy() *ObservabilityMetricsAllowlistList {
 	if in == nil {
 		return nil
 	}
-	out := new(MultiClusterObservabilityList)
+	out := new(ObservabilityMetricsAllowlistList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MultiClusterObservabilityList) DeepCopyObject() runtime.Object {
+func (in *ObservabilityMetricsAllowlistList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -86,51 +1549,49 @@ func (in *MultiClusterObservabilityList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MultiClusterObservabilitySpec) DeepCopyInto(out *MultiClusterObservabilitySpec) {
+func (in *ObservabilityMetricsAllowlistSpec) DeepCopyInto(out *ObservabilityMetricsAllowlistSpec) {
 	*out = *in
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]MetricName, len(*in))
+		copy(*out, *in)
 	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Matches != nil {
+		in, out := &in.Matches, &out.Matches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.RetentionConfig != nil {
-		in, out := &in.RetentionConfig, &out.RetentionConfig
-		*out = new(RetentionConfig)
-		**out = **in
+	if in.Renames != nil {
+		in, out := &in.Renames, &out.Renames
+		*out = make([]MetricRename, len(*in))
+		copy(*out, *in)
 	}
-	if in.StorageConfig != nil {
-		in, out := &in.StorageConfig, &out.StorageConfig
-		*out = new(StorageConfig)
-		(*in).DeepCopyInto(*out)
+	if in.RecordingRules != nil {
+		in, out := &in.RecordingRules, &out.RecordingRules
+		*out = make([]MetricsRecordingRule, len(*in))
+		copy(*out, *in)
 	}
-	if in.ObservabilityAddonSpec != nil {
-		in, out := &in.ObservabilityAddonSpec, &out.ObservabilityAddonSpec
-		*out = new(shared.ObservabilityAddonSpec)
-		**out = **in
+	if in.CollectIntervals != nil {
+		in, out := &in.CollectIntervals, &out.CollectIntervals
+		*out = make([]MetricsCollectInterval, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterObservabilitySpec.
-func (in *MultiClusterObservabilitySpec) DeepCopy() *MultiClusterObservabilitySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObservabilityMetricsAllowlistSpec.
+func (in *ObservabilityMetricsAllowlistSpec) DeepCopy() *ObservabilityMetricsAllowlistSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(MultiClusterObservabilitySpec)
+	out := new(ObservabilityMetricsAllowlistSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MultiClusterObservabilityStatus) DeepCopyInto(out *MultiClusterObservabilityStatus) {
+func (in *ObservabilityMetricsAllowlistStatus) DeepCopyInto(out *ObservabilityMetricsAllowlistStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -141,12 +1602,128 @@ func (in *MultiClusterObservabilityStatus) DeepCopyInto(out *MultiClusterObserva
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiClusterObservabilityStatus.
-func (in *MultiClusterObservabilityStatus) DeepCopy() *MultiClusterObservabilityStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObservabilityMetricsAllowlistStatus.
+func (in *ObservabilityMetricsAllowlistStatus) DeepCopy() *ObservabilityMetricsAllowlistStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(MultiClusterObservabilityStatus)
+	out := new(ObservabilityMetricsAllowlistStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PagerDutyReceiver) DeepCopyInto(out *PagerDutyReceiver) {
+	*out = *in
+	in.RoutingKeySecret.DeepCopyInto(&out.RoutingKeySecret)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PagerDutyReceiver.
+func (in *PagerDutyReceiver) DeepCopy() *PagerDutyReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(PagerDutyReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementRefSpec) DeepCopyInto(out *PlacementRefSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementRefSpec.
+func (in *PlacementRefSpec) DeepCopy() *PlacementRefSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementRefSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PriorityClassSpec) DeepCopyInto(out *PriorityClassSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PriorityClassSpec.
+func (in *PriorityClassSpec) DeepCopy() *PriorityClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PriorityClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PriorityClassesSpec) DeepCopyInto(out *PriorityClassesSpec) {
+	*out = *in
+	if in.Hub != nil {
+		in, out := &in.Hub, &out.Hub
+		*out = new(PriorityClassSpec)
+		**out = **in
+	}
+	if in.Addon != nil {
+		in, out := &in.Addon, &out.Addon
+		*out = new(PriorityClassSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PriorityClassesSpec.
+func (in *PriorityClassesSpec) DeepCopy() *PriorityClassesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PriorityClassesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusTSDBSource) DeepCopyInto(out *PrometheusTSDBSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusTSDBSource.
+func (in *PrometheusTSDBSource) DeepCopy() *PrometheusTSDBSource {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusTSDBSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryEndpointSpec) DeepCopyInto(out *QueryEndpointSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryEndpointSpec.
+func (in *QueryEndpointSpec) DeepCopy() *QueryEndpointSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryEndpointSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegionalGatewaySpec) DeepCopyInto(out *RegionalGatewaySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionalGatewaySpec.
+func (in *RegionalGatewaySpec) DeepCopy() *RegionalGatewaySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RegionalGatewaySpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -166,6 +1743,37 @@ func (in *RetentionConfig) DeepCopy() *RetentionConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceLevelIndicator) DeepCopyInto(out *ServiceLevelIndicator) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceLevelIndicator.
+func (in *ServiceLevelIndicator) DeepCopy() *ServiceLevelIndicator {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceLevelIndicator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackReceiver) DeepCopyInto(out *SlackReceiver) {
+	*out = *in
+	in.APIURLSecret.DeepCopyInto(&out.APIURLSecret)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackReceiver.
+func (in *SlackReceiver) DeepCopy() *SlackReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageConfig) DeepCopyInto(out *StorageConfig) {
 	*out = *in
@@ -174,6 +1782,21 @@ func (in *StorageConfig) DeepCopyInto(out *StorageConfig) {
 		*out = new(shared.PreConfiguredStorage)
 		**out = **in
 	}
+	if in.WorkloadIdentity != nil {
+		in, out := &in.WorkloadIdentity, &out.WorkloadIdentity
+		*out = new(WorkloadIdentitySpec)
+		**out = **in
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(ObjStorageEncryptionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BucketVerify != nil {
+		in, out := &in.BucketVerify, &out.BucketVerify
+		*out = new(BucketVerifySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageConfig.
@@ -185,3 +1808,94 @@ func (in *StorageConfig) DeepCopy() *StorageConfig {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSConfigSpec) DeepCopyInto(out *TLSConfigSpec) {
+	*out = *in
+	if in.AdditionalSANs != nil {
+		in, out := &in.AdditionalSANs, &out.AdditionalSANs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IntermediateCA != nil {
+		in, out := &in.IntermediateCA, &out.IntermediateCA
+		*out = new(IntermediateCASpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfigSpec.
+func (in *TLSConfigSpec) DeepCopy() *TLSConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TelemetrySpec) DeepCopyInto(out *TelemetrySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelemetrySpec.
+func (in *TelemetrySpec) DeepCopy() *TelemetrySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TelemetrySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosBucketSource) DeepCopyInto(out *ThanosBucketSource) {
+	*out = *in
+	out.Config = in.Config
+	if in.Matchers != nil {
+		in, out := &in.Matchers, &out.Matchers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosBucketSource.
+func (in *ThanosBucketSource) DeepCopy() *ThanosBucketSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ThanosBucketSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TracingSpec) DeepCopyInto(out *TracingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TracingSpec.
+func (in *TracingSpec) DeepCopy() *TracingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TracingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadIdentitySpec) DeepCopyInto(out *WorkloadIdentitySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadIdentitySpec.
+func (in *WorkloadIdentitySpec) DeepCopy() *WorkloadIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	// the prefix of the receivers which are expanded from the alert receivers of the
+	// MultiClusterObservability CR, the receivers are named mco/<name>
+	alertReceiverPrefix            = "mco/"
+	alertReceiversConditionType    = "AlertReceiversReady"
+	pagerDutyDescriptionTemplate   = `{{ .CommonLabels.alertname }} on {{ .CommonLabels.cluster }}`
+	pagerDutySeverityTemplate      = `{{ if .CommonLabels.severity }}{{ .CommonLabels.severity }}{{ else }}error{{ end }}`
+	slackTitleTemplate             = `[{{ .Status | toUpper }}] {{ .CommonLabels.alertname }} on {{ .CommonLabels.cluster }}`
+	slackTextTemplate              = `{{ range .Alerts }}{{ .Annotations.summary }} {{ .Annotations.description }}` + "\n" + `{{ end }}`
+	alertReceiverSeverityLabelName = "severity"
+)
+
+// newAlertReceiverConfigs returns the receivers and the routes which are expanded from the alert
+// receivers of the MultiClusterObservability CR. The invalid alert receivers are skipped, and the
+// reasons are returned so that they are shown in the status.
+func newAlertReceiverConfigs(c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) ([]interface{}, []interface{}, []string) {
+	receivers := []interface{}{}
+	routes := []interface{}{}
+	invalid := []string{}
+	names := map[string]bool{}
+	for _, r := range mco.Spec.AlertReceivers {
+		if names[r.Name] {
+			invalid = append(invalid, fmt.Sprintf("the name of the receiver %q is duplicated", r.Name))
+			continue
+		}
+		names[r.Name] = true
+		receiver, err := newAlertReceiver(c, r)
+		if err != nil {
+			invalid = append(invalid, err.Error())
+			continue
+		}
+		receivers = append(receivers, receiver)
+		routes = append(routes, newAlertReceiverRoute(r))
+	}
+	return receivers, routes, invalid
+}
+
+// newAlertReceiver expands the alert receiver into the receiver of the alertmanager configuration,
+// the credentials are read from the secrets in the namespace of the operands
+func newAlertReceiver(c client.Client, r mcov1beta2.AlertReceiver) (map[string]interface{}, error) {
+	integrations := 0
+	for _, set := range []bool{r.PagerDuty != nil, r.Slack != nil, r.MSTeams != nil} {
+		if set {
+			integrations++
+		}
+	}
+	if integrations != 1 {
+		return nil, fmt.Errorf("exactly one of pagerDuty, slack and msTeams is required in the receiver %s", r.Name)
+	}
+
+	namespace := mcoconfig.GetDefaultNamespace()
+	receiver := map[string]interface{}{"name": alertReceiverPrefix + r.Name}
+	switch {
+	case r.PagerDuty != nil:
+		key, err := getSecretValue(c, namespace, &r.PagerDuty.RoutingKeySecret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid receiver %s: %v", r.Name, err)
+		}
+		receiver["pagerduty_configs"] = []interface{}{
+			withSendResolved(map[string]interface{}{
+				"routing_key": key,
+				"description": pagerDutyDescriptionTemplate,
+				"severity":    pagerDutySeverityTemplate,
+			}, r.SendResolved),
+		}
+	case r.Slack != nil:
+		url, err := getSecretValue(c, namespace, &r.Slack.APIURLSecret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid receiver %s: %v", r.Name, err)
+		}
+		config := map[string]interface{}{
+			"api_url": url,
+			"title":   slackTitleTemplate,
+			"text":    slackTextTemplate,
+		}
+		if r.Slack.Channel != "" {
+			config["channel"] = r.Slack.Channel
+		}
+		receiver["slack_configs"] = []interface{}{withSendResolved(config, r.SendResolved)}
+	case r.MSTeams != nil:
+		url, err := getSecretValue(c, namespace, &r.MSTeams.WebhookURLSecret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid receiver %s: %v", r.Name, err)
+		}
+		receiver["webhook_configs"] = []interface{}{
+			withSendResolved(map[string]interface{}{"url": url}, r.SendResolved),
+		}
+	}
+	return receiver, nil
+}
+
+// newAlertReceiverRoute returns the route of the alert receiver, which matches the severities and
+// the clusters of the alerts
+func newAlertReceiverRoute(r mcov1beta2.AlertReceiver) map[string]interface{} {
+	route := map[string]interface{}{
+		"receiver": alertReceiverPrefix + r.Name,
+		"group_by": []interface{}{"alertname", mcoconfig.GetClusterNameLabelKey()},
+		"continue": true,
+	}
+	matchRE := map[string]interface{}{}
+	if len(r.Severities) > 0 {
+		matchRE[alertReceiverSeverityLabelName] = newAlternation(r.Severities)
+	}
+	if len(r.Clusters) > 0 {
+		matchRE[mcoconfig.GetClusterNameLabelKey()] = newAlternation(r.Clusters)
+	}
+	if len(matchRE) > 0 {
+		route["match_re"] = matchRE
+	}
+	return route
+}
+
+// newAlternation returns the regular expression which matches any of the values exactly
+func newAlternation(values []string) string {
+	quoted := []string{}
+	for _, value := range values {
+		quoted = append(quoted, regexp.QuoteMeta(value))
+	}
+	return strings.Join(quoted, "|")
+}
+
+// updateAlertReceiversStatus shows whether all the alert receivers are expanded into the
+// alertmanager configuration in the status condition
+func updateAlertReceiversStatus(conditions *[]mcoshared.Condition, c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) {
+	if len(mco.Spec.AlertReceivers) == 0 {
+		removeStatusCondition(conditions, alertReceiversConditionType)
+		return
+	}
+	_, _, invalid := newAlertReceiverConfigs(c, mco)
+	condition := mcoshared.Condition{
+		Type:    alertReceiversConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ReceiversConfigured",
+		Message: "All the alert receivers are configured in the alertmanager",
+	}
+	if len(invalid) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "InvalidReceivers"
+		condition.Message = strings.Join(invalid, "; ")
+	}
+	setStatusCondition(conditions, condition)
+}
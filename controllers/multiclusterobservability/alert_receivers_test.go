@@ -0,0 +1,189 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestNewAlertReceiverConfigs(t *testing.T) {
+	namespace := mcoconfig.GetDefaultNamespace()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "alert-receivers", Namespace: namespace},
+		Data: map[string][]byte{
+			"pagerduty": []byte("pd-key"),
+			"slack":     []byte("https://hooks.slack.com/services/a"),
+		},
+	}
+	selector := func(key string) corev1.SecretKeySelector {
+		return corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "alert-receivers"},
+			Key:                  key,
+		}
+	}
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			AlertReceivers: []mcov1beta2.AlertReceiver{
+				{
+					Name:       "oncall",
+					Severities: []string{"critical"},
+					Clusters:   []string{"prod-1", "prod.2"},
+					PagerDuty:  &mcov1beta2.PagerDutyReceiver{RoutingKeySecret: selector("pagerduty")},
+				},
+				{
+					Name:  "ops",
+					Slack: &mcov1beta2.SlackReceiver{APIURLSecret: selector("slack"), Channel: "#ops"},
+				},
+				{
+					Name:    "teams",
+					MSTeams: &mcov1beta2.MSTeamsReceiver{WebhookURLSecret: selector("teams")},
+				},
+				{
+					Name:  "ops",
+					Slack: &mcov1beta2.SlackReceiver{APIURLSecret: selector("slack")},
+				},
+				{
+					Name:      "both",
+					PagerDuty: &mcov1beta2.PagerDutyReceiver{RoutingKeySecret: selector("pagerduty")},
+					Slack:     &mcov1beta2.SlackReceiver{APIURLSecret: selector("slack")},
+				},
+			},
+		},
+	}
+	c := fake.NewFakeClient(secret)
+
+	receivers, routes, invalid := newAlertReceiverConfigs(c, mco)
+	if len(receivers) != 2 || len(routes) != 2 {
+		t.Fatalf("Only the valid receivers should be expanded: %v", receivers)
+	}
+	if len(invalid) != 3 {
+		t.Fatalf("The missing secret key, the duplicated name and the multiple integrations should be invalid: %v",
+			invalid)
+	}
+	pagerduty := receivers[0].(map[string]interface{})
+	if pagerduty["name"] != "mco/oncall" ||
+		pagerduty["pagerduty_configs"].([]interface{})[0].(map[string]interface{})["routing_key"] != "pd-key" {
+		t.Fatalf("Wrong pagerduty receiver: %v", pagerduty)
+	}
+	expectedRoute := map[string]interface{}{
+		"receiver": "mco/oncall",
+		"group_by": []interface{}{"alertname", "cluster"},
+		"continue": true,
+		"match_re": map[string]interface{}{
+			"severity": "critical",
+			"cluster":  `prod-1|prod\.2`,
+		},
+	}
+	if !reflect.DeepEqual(routes[0], expectedRoute) {
+		t.Fatalf("Wrong route of the pagerduty receiver: %v", routes[0])
+	}
+	if _, ok := routes[1].(map[string]interface{})["match_re"]; ok {
+		t.Fatalf("The route without severities and clusters should match all the alerts: %v", routes[1])
+	}
+
+	conditions := []mcoshared.Condition{}
+	updateAlertReceiversStatus(&conditions, c, mco)
+	condition := findStatusCondition(conditions, alertReceiversConditionType)
+	if condition == nil || condition.Status != metav1.ConditionFalse || !strings.Contains(condition.Message, "teams") {
+		t.Fatalf("The invalid receivers should be shown in the status: %v", conditions)
+	}
+	mco.Spec.AlertReceivers = mco.Spec.AlertReceivers[:2]
+	updateAlertReceiversStatus(&conditions, c, mco)
+	condition = findStatusCondition(conditions, alertReceiversConditionType)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("The alert receivers should be ready: %v", conditions)
+	}
+}
+
+func TestGenerateAlertmanagerConfigWithAlertReceivers(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	namespace := mcoconfig.GetDefaultNamespace()
+	amSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.AlertmanagerConfigName, Namespace: namespace},
+		Data: map[string][]byte{AlertmanagerConfigKey: []byte(`
+receivers:
+- name: "null"
+route:
+  receiver: "null"
+`)},
+	}
+	slackSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "slack", Namespace: namespace},
+		Data:       map[string][]byte{"url": []byte("https://hooks.slack.com/services/a")},
+	}
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			AlertReceivers: []mcov1beta2.AlertReceiver{
+				{
+					Name: "ops",
+					Slack: &mcov1beta2.SlackReceiver{
+						APIURLSecret: corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "slack"},
+							Key:                  "url",
+						},
+					},
+				},
+			},
+		},
+	}
+	c := fake.NewFakeClient(amSecret, slackSecret)
+
+	_, err := GenerateAlertmanagerConfig(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the alertmanager configuration: (%v)", err)
+	}
+	found := &corev1.Secret{}
+	key := types.NamespacedName{Name: mcoconfig.AlertmanagerConfigName, Namespace: namespace}
+	err = c.Get(context.TODO(), key, found)
+	if err != nil {
+		t.Fatalf("Failed to get the alertmanager configuration: (%v)", err)
+	}
+	merged := map[string]interface{}{}
+	err = yaml.Unmarshal(found.Data[AlertmanagerConfigKey], &merged)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the merged configuration: (%v)", err)
+	}
+	routes := merged["route"].(map[string]interface{})["routes"].([]interface{})
+	if len(merged["receivers"].([]interface{})) != 2 || len(routes) != 1 ||
+		routes[0].(map[string]interface{})["receiver"] != "mco/ops" {
+		t.Fatalf("The alert receiver is not merged: %s", string(found.Data[AlertmanagerConfigKey]))
+	}
+
+	// the expanded receiver and route are removed with the alert receiver
+	mco.Spec.AlertReceivers = nil
+	_, err = GenerateAlertmanagerConfig(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the alertmanager configuration: (%v)", err)
+	}
+	err = c.Get(context.TODO(), key, found)
+	if err != nil {
+		t.Fatalf("Failed to get the alertmanager configuration: (%v)", err)
+	}
+	merged = map[string]interface{}{}
+	err = yaml.Unmarshal(found.Data[AlertmanagerConfigKey], &merged)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the merged configuration: (%v)", err)
+	}
+	if len(merged["receivers"].([]interface{})) != 1 || merged["route"].(map[string]interface{})["routes"] != nil {
+		t.Fatalf("The alert receiver should be removed: %s", string(found.Data[AlertmanagerConfigKey]))
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	alertingSelfTestInterval = 5 * time.Minute
+	// the alerting pipeline of a cluster is unhealthy if its watchdog alert is not delivered in the timeout
+	alertingSelfTestTimeout = 15 * time.Minute
+	// the receiver of the watchdog alerts in the alertmanager configuration
+	selfTestReceiverName          = "observability-alerting-self-test"
+	alertingPipelineConditionType = "AlertingPipelineHealthy"
+	// the annotation of the ObservabilityAddon of a cluster with the last time which its watchdog
+	// alert is delivered, it is shared by the replicas of the operator
+	watchdogDeliveredAnnotation = "observability.open-cluster-management.io/watchdog-delivered"
+	obsAddonName                = "observability-addon"
+)
+
+var (
+	alertingPipelineHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "acm_observability_alerting_pipeline_healthy",
+		Help: "Whether the watchdog alert of the managed cluster is delivered to the receiver on the hub in time.",
+	}, []string{"cluster"})
+
+	// watchdogs records the watchdog alerts which are delivered to the operator
+	watchdogs = &watchdogReceiver{started: time.Now()}
+)
+
+func init() {
+	metrics.Registry.MustRegister(alertingPipelineHealthy)
+}
+
+// watchdogReceiver is the webhook receiver of the watchdog alerts, it records the last time which the
+// watchdog alert of each cluster is delivered in the annotation of the ObservabilityAddon of the cluster.
+// The alertmanager posts to any replica of the operator, so the time is not kept in the memory. Only the
+// requests with the bearer token of the alertmanager are accepted.
+type watchdogReceiver struct {
+	client  client.Client
+	started time.Time
+}
+
+// webhookMessage is the payload of the webhook receiver of the alertmanager
+type webhookMessage struct {
+	Alerts []struct {
+		Status string            `json:"status"`
+		Labels map[string]string `json:"labels"`
+	} `json:"alerts"`
+}
+
+func (r *watchdogReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !r.isAuthorized(req) {
+		http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+		return
+	}
+	msg := &webhookMessage{}
+	err := json.NewDecoder(req.Body).Decode(msg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`,
+		watchdogDeliveredAnnotation, time.Now().UTC().Format(time.RFC3339)))
+	for _, alert := range msg.Alerts {
+		cluster := alert.Labels[mcoconfig.GetClusterNameLabelKey()]
+		if alert.Status != "firing" || alert.Labels["alertname"] != mcoconfig.ClusterWatchdogAlertName ||
+			cluster == "" {
+			continue
+		}
+		addon := &mcov1beta1.ObservabilityAddon{
+			ObjectMeta: metav1.ObjectMeta{Name: obsAddonName, Namespace: cluster},
+		}
+		err = r.client.Patch(context.TODO(), addon, client.RawPatch(types.MergePatchType, patch))
+		if err != nil && !errors.IsNotFound(err) {
+			// the alertmanager retries the failed notification
+			log.Error(err, "Failed to record the delivered watchdog alert", "cluster", cluster)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// isAuthorized returns whether the request carries the bearer token of the alertmanager
+func (r *watchdogReceiver) isAuthorized(req *http.Request) bool {
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	token := strings.TrimPrefix(auth, "Bearer ")
+	secret := &corev1.Secret{}
+	err := r.client.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.AlertingSelfTestTokenSecretName,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, secret)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get the token of the alerting self test")
+		}
+		return false
+	}
+	expected := secret.Data[mcoconfig.AlertingSelfTestTokenKey]
+	return len(expected) != 0 && subtle.ConstantTimeCompare([]byte(token), expected) == 1
+}
+
+// getSelfTestToken returns the bearer token which the alertmanager sends to the receiver of the
+// watchdog alerts, the token is generated at the first time
+func getSelfTestToken(c client.Client) (string, error) {
+	found := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.AlertingSelfTestTokenSecretName,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, found)
+	if err == nil {
+		return string(found.Data[mcoconfig.AlertingSelfTestTokenKey]), nil
+	}
+	if !errors.IsNotFound(err) {
+		log.Error(err, "Failed to check the token secret of the alerting self test")
+		return "", err
+	}
+	token := make([]byte, 32)
+	_, err = rand.Read(token)
+	if err != nil {
+		return "", err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mcoconfig.AlertingSelfTestTokenSecretName,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+		},
+		Data: map[string][]byte{
+			mcoconfig.AlertingSelfTestTokenKey: []byte(hex.EncodeToString(token)),
+		},
+	}
+	log.Info("Creating the token secret of the alerting self test")
+	err = c.Create(context.TODO(), secret)
+	if err != nil {
+		log.Error(err, "Failed to create the token secret of the alerting self test")
+		return "", err
+	}
+	return string(secret.Data[mcoconfig.AlertingSelfTestTokenKey]), nil
+}
+
+// isHealthy returns whether the watchdog alert of the cluster is delivered in the timeout, and
+// false for the second value if it is not known yet since the addon is created or the operator
+// started recently
+func (r *watchdogReceiver) isHealthy(addon *mcov1beta1.ObservabilityAddon, now time.Time) (bool, bool) {
+	delivered, err := time.Parse(time.RFC3339, addon.Annotations[watchdogDeliveredAnnotation])
+	if err != nil {
+		since := r.started
+		if addon.CreationTimestamp.Time.After(since) {
+			since = addon.CreationTimestamp.Time
+		}
+		return false, now.Sub(since) >= alertingSelfTestTimeout
+	}
+	return now.Sub(delivered) < alertingSelfTestTimeout, true
+}
+
+// GenerateWatchdogRules creates the rules configmap of the thanos ruler which fires the watchdog
+// alert on the hub for each cluster whose watchdog alert is forwarded, or removes it when the
+// alerting self test is disabled
+func GenerateWatchdogRules(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	if !mco.Spec.EnableAlertingSelfTest {
+		return nil, deleteResources(c, []client.Object{
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      mcoconfig.AlertRuleWatchdogConfigMapName,
+					Namespace: mcoconfig.GetDefaultNamespace(),
+				},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      mcoconfig.AlertingSelfTestTokenSecretName,
+					Namespace: mcoconfig.GetDefaultNamespace(),
+				},
+			},
+		})
+	}
+	return generateRulesConfigMap(c, scheme, mco, mcoconfig.AlertRuleWatchdogConfigMapName,
+		mcoconfig.AlertRuleWatchdogFileKey, RuleGroups{Groups: []RuleGroup{newWatchdogRuleGroup()}})
+}
+
+func newWatchdogRuleGroup() RuleGroup {
+	return RuleGroup{
+		Name: "observability-watchdog",
+		Rules: []Rule{
+			{
+				Alert: mcoconfig.ClusterWatchdogAlertName,
+				Expr: fmt.Sprintf(`max by (%s) (ALERTS{alertname="%s",alertstate="firing"})`,
+					mcoconfig.GetClusterNameLabelKey(), mcoconfig.WatchdogAlertName),
+				Labels: map[string]string{"severity": "none"},
+				Annotations: map[string]string{
+					"summary": "The watchdog alert of the alerting self test, it always fires for each cluster.",
+					"description": "The watchdog alert of cluster {{ $labels.cluster }} is forwarded to the hub. " +
+						"It can be routed to a dead man's switch which notifies when it stops.",
+				},
+			},
+		},
+	}
+}
+
+// newSelfTestConfig returns the receiver and the route of the watchdog alerts in the alertmanager
+// configuration. The route continues so that the watchdog alerts can be routed to the external
+// receivers as well.
+func newSelfTestConfig(token string) (map[string]interface{}, map[string]interface{}) {
+	receiver := map[string]interface{}{
+		"name": selfTestReceiverName,
+		"webhook_configs": []interface{}{
+			map[string]interface{}{
+				"url":           mcoconfig.GetAlertingSelfTestURL(),
+				"send_resolved": false,
+				// the serving certificate of the webhook service is signed by the service CA
+				"http_config": map[string]interface{}{
+					"bearer_token": token,
+					"tls_config": map[string]interface{}{
+						"ca_file": mcoconfig.ServiceCAFile,
+					},
+				},
+			},
+		},
+	}
+	route := map[string]interface{}{
+		"receiver":        selfTestReceiverName,
+		"match":           map[string]interface{}{"alertname": mcoconfig.ClusterWatchdogAlertName},
+		"group_by":        []interface{}{mcoconfig.GetClusterNameLabelKey()},
+		"group_wait":      "0s",
+		"group_interval":  "1m",
+		"repeat_interval": "5m",
+		"continue":        true,
+	}
+	return receiver, route
+}
+
+// updateAlertingPipelineStatus updates the health of the alerting pipeline of each managed cluster in
+// the metric, and the clusters whose watchdog alert is not delivered in time in the status condition
+func updateAlertingPipelineStatus(conditions *[]mcoshared.Condition, c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) {
+	if !mco.Spec.EnableAlertingSelfTest {
+		alertingPipelineHealthy.Reset()
+		removeStatusCondition(conditions, alertingPipelineConditionType)
+		return
+	}
+	addonList := &mcov1beta1.ObservabilityAddonList{}
+	err := c.List(context.TODO(), addonList)
+	if err != nil {
+		log.Error(err, "Failed to list the ObservabilityAddons")
+		return
+	}
+
+	now := time.Now()
+	unhealthy := []string{}
+	alertingPipelineHealthy.Reset()
+	for _, addon := range addonList.Items {
+		// the addons in the cluster namespaces on the hub
+		cluster := addon.Namespace
+		if cluster == mcoconfig.GetDefaultNamespace() {
+			continue
+		}
+		healthy, known := watchdogs.isHealthy(&addon, now)
+		if !known {
+			continue
+		}
+		if healthy {
+			alertingPipelineHealthy.WithLabelValues(cluster).Set(1)
+		} else {
+			alertingPipelineHealthy.WithLabelValues(cluster).Set(0)
+			unhealthy = append(unhealthy, cluster)
+		}
+	}
+
+	condition := mcoshared.Condition{
+		Type:    alertingPipelineConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "WatchdogDelivered",
+		Message: "The watchdog alerts of all the managed clusters are delivered",
+	}
+	if len(unhealthy) > 0 {
+		sort.Strings(unhealthy)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "WatchdogNotDelivered"
+		condition.Message = fmt.Sprintf("The watchdog alerts are not delivered in %s for the clusters: %s",
+			alertingSelfTestTimeout, strings.Join(unhealthy, ", "))
+	}
+	setStatusCondition(conditions, condition)
+}
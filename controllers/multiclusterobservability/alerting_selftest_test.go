@@ -0,0 +1,219 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestWatchdogReceiver(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta1.SchemeBuilder.AddToScheme(s)
+
+	addons := []*mcov1beta1.ObservabilityAddon{}
+	for _, ns := range []string{"cluster1", "cluster2", "cluster3"} {
+		addons = append(addons, &mcov1beta1.ObservabilityAddon{
+			ObjectMeta: metav1.ObjectMeta{Name: "observability-addon", Namespace: ns},
+		})
+	}
+	c := fake.NewFakeClient(addons[0], addons[1], addons[2])
+	receiver := &watchdogReceiver{client: c, started: time.Now()}
+	newRequest := func(body string, token string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, mcoconfig.AlertingSelfTestPath, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req
+	}
+	now := time.Now()
+	if _, known := receiver.isHealthy(addons[0], now); known {
+		t.Fatalf("The health of the cluster should not be known before the timeout")
+	}
+
+	body := `{"alerts":[
+{"status":"firing","labels":{"alertname":"ObservabilityClusterWatchdog","cluster":"cluster1"}},
+{"status":"resolved","labels":{"alertname":"ObservabilityClusterWatchdog","cluster":"cluster2"}},
+{"status":"firing","labels":{"alertname":"Other","cluster":"cluster3"}},
+{"status":"firing","labels":{"alertname":"ObservabilityClusterWatchdog","cluster":"detached"}}]}`
+	// the requests are rejected until the token is generated
+	rec := httptest.NewRecorder()
+	receiver.ServeHTTP(rec, newRequest(body, "any"))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("The request should be rejected without the token secret: %d", rec.Code)
+	}
+	token, err := getSelfTestToken(c)
+	if err != nil || token == "" {
+		t.Fatalf("Failed to generate the token: (%v)", err)
+	}
+	if again, _ := getSelfTestToken(c); again != token {
+		t.Fatalf("The token should be kept once it is generated")
+	}
+	for _, invalid := range []string{"", "invalid"} {
+		rec = httptest.NewRecorder()
+		receiver.ServeHTTP(rec, newRequest(body, invalid))
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("The request with the token %q should be rejected: %d", invalid, rec.Code)
+		}
+	}
+	addon := &mcov1beta1.ObservabilityAddon{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: "observability-addon", Namespace: "cluster1"}, addon)
+	if err != nil || addon.Annotations[watchdogDeliveredAnnotation] != "" {
+		t.Fatalf("The rejected request should not record the watchdog alert: (%v)", err)
+	}
+
+	rec = httptest.NewRecorder()
+	receiver.ServeHTTP(rec, newRequest(body, token))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Failed to receive the watchdog alerts: %d", rec.Code)
+	}
+
+	// the delivered time is read from the addon, so that another replica of the operator reports it
+	getAddon := func(cluster string) *mcov1beta1.ObservabilityAddon {
+		addon := &mcov1beta1.ObservabilityAddon{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: "observability-addon", Namespace: cluster}, addon)
+		if err != nil {
+			t.Fatalf("Failed to get the addon of %s: (%v)", cluster, err)
+		}
+		return addon
+	}
+	other := &watchdogReceiver{started: now}
+	if healthy, known := other.isHealthy(getAddon("cluster1"), now); !healthy || !known {
+		t.Fatalf("The alerting pipeline of cluster1 should be healthy")
+	}
+	later := now.Add(alertingSelfTestTimeout + time.Minute)
+	if healthy, known := other.isHealthy(getAddon("cluster1"), later); healthy || !known {
+		t.Fatalf("The alerting pipeline of cluster1 should be unhealthy after the timeout")
+	}
+	for _, cluster := range []string{"cluster2", "cluster3"} {
+		if healthy, known := other.isHealthy(getAddon(cluster), later); healthy || !known {
+			t.Fatalf("The alerting pipeline of %s should be unhealthy", cluster)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	receiver.ServeHTTP(rec, newRequest("{", token))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("The invalid payload should be rejected: %d", rec.Code)
+	}
+}
+
+func TestGenerateWatchdogRules(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       mcov1beta2.MultiClusterObservabilitySpec{EnableAlertingSelfTest: true},
+	}
+	c := fake.NewFakeClient()
+
+	_, err := GenerateWatchdogRules(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the watchdog rules: (%v)", err)
+	}
+	cm := &corev1.ConfigMap{}
+	key := types.NamespacedName{
+		Name:      mcoconfig.AlertRuleWatchdogConfigMapName,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}
+	err = c.Get(context.TODO(), key, cm)
+	if err != nil {
+		t.Fatalf("Failed to get the watchdog rules configmap: (%v)", err)
+	}
+	if !strings.Contains(cm.Data[mcoconfig.AlertRuleWatchdogFileKey], mcoconfig.ClusterWatchdogAlertName) {
+		t.Fatalf("The watchdog alert is not in the rules: %s", cm.Data[mcoconfig.AlertRuleWatchdogFileKey])
+	}
+
+	if _, err = getSelfTestToken(c); err != nil {
+		t.Fatalf("Failed to generate the token: (%v)", err)
+	}
+
+	mco.Spec.EnableAlertingSelfTest = false
+	_, err = GenerateWatchdogRules(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to remove the watchdog rules: (%v)", err)
+	}
+	err = c.Get(context.TODO(), key, cm)
+	if err == nil {
+		t.Fatalf("The watchdog rules configmap should be removed")
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.AlertingSelfTestTokenSecretName,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, &corev1.Secret{})
+	if err == nil {
+		t.Fatalf("The token secret should be removed")
+	}
+}
+
+func TestUpdateAlertingPipelineStatus(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta1.SchemeBuilder.AddToScheme(s)
+
+	delivered := map[string]string{watchdogDeliveredAnnotation: time.Now().UTC().Format(time.RFC3339)}
+	addons := []*mcov1beta1.ObservabilityAddon{}
+	for _, ns := range []string{"cluster1", "cluster2", mcoconfig.GetDefaultNamespace()} {
+		addons = append(addons, &mcov1beta1.ObservabilityAddon{
+			ObjectMeta: metav1.ObjectMeta{Name: "observability-addon", Namespace: ns},
+		})
+	}
+	addons[0].Annotations = delivered
+	c := fake.NewFakeClient(addons[0], addons[1], addons[2])
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       mcov1beta2.MultiClusterObservabilitySpec{EnableAlertingSelfTest: true},
+	}
+
+	origin := watchdogs
+	defer func() { watchdogs = origin }()
+	watchdogs = &watchdogReceiver{client: c, started: time.Now().Add(-2 * alertingSelfTestTimeout)}
+
+	conditions := []mcoshared.Condition{}
+	updateAlertingPipelineStatus(&conditions, c, mco)
+	condition := findStatusCondition(conditions, alertingPipelineConditionType)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != "WatchdogNotDelivered" {
+		t.Fatalf("The alerting pipeline of cluster2 should be unhealthy: %v", conditions)
+	}
+	if !strings.Contains(condition.Message, "cluster2") || strings.Contains(condition.Message, "cluster1") {
+		t.Fatalf("Wrong clusters in the condition message: %s", condition.Message)
+	}
+
+	addon := &mcov1beta1.ObservabilityAddon{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: "observability-addon", Namespace: "cluster2"}, addon)
+	if err != nil {
+		t.Fatalf("Failed to get the addon of cluster2: (%v)", err)
+	}
+	addon.Annotations = delivered
+	if err := c.Update(context.TODO(), addon); err != nil {
+		t.Fatalf("Failed to update the addon of cluster2: (%v)", err)
+	}
+	updateAlertingPipelineStatus(&conditions, c, mco)
+	condition = findStatusCondition(conditions, alertingPipelineConditionType)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("The alerting pipeline should be healthy: %v", conditions)
+	}
+
+	mco.Spec.EnableAlertingSelfTest = false
+	updateAlertingPipelineStatus(&conditions, c, mco)
+	if findStatusCondition(conditions, alertingPipelineConditionType) != nil {
+		t.Fatalf("The condition should be removed when the self test is disabled: %v", conditions)
+	}
+}
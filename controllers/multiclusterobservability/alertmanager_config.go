@@ -0,0 +1,417 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	// AlertmanagerConfigKey is the key of the alertmanager configuration in the alertmanager-config secret
+	AlertmanagerConfigKey = "alertmanager.yaml"
+	// the prefix of the receivers which are merged from the AlertmanagerConfigs, the receivers
+	// are named tenant/<namespace>/<name>/<receiver> so that they never conflict
+	tenantReceiverPrefix = "tenant/"
+)
+
+// GenerateAlertmanagerConfig merges the receivers and the routes of all the AlertmanagerConfigs, the
+// alert receivers of the MultiClusterObservability CR, the receiver of the alerting self test if
+// it is enabled, and the routes of the active maintenance windows, into the alertmanager-config secret. The routes of the AlertmanagerConfigs are scoped
+// to the tenant of the namespace, and the receivers and the routes which were merged before are
+// replaced, so that the rest of the configuration is still managed by the admin.
+func GenerateAlertmanagerConfig(c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	amcList := &mcov1beta2.AlertmanagerConfigList{}
+	err := c.List(context.TODO(), amcList)
+	if err != nil && !meta.IsNoMatchError(err) {
+		log.Error(err, "Failed to list the AlertmanagerConfigs")
+		return &ctrl.Result{}, err
+	}
+
+	secret := &corev1.Secret{}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.AlertmanagerConfigName,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return &ctrl.Result{}, err
+	}
+	amConfig := map[string]interface{}{}
+	err = yaml.Unmarshal(secret.Data[AlertmanagerConfigKey], &amConfig)
+	if err != nil {
+		// leave the broken configuration to the admin
+		log.Error(err, "Failed to unmarshal the alertmanager configuration, skip merging the AlertmanagerConfigs")
+		return nil, nil
+	}
+	// the configuration without the merged AlertmanagerConfigs is not updated, so that
+	// the secret is not reformatted if there is nothing to merge
+	original, err := yaml.Marshal(amConfig)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	receivers, routes := removeManagedConfigs(amConfig)
+
+	sort.Slice(amcList.Items, func(i, j int) bool {
+		if amcList.Items[i].Namespace != amcList.Items[j].Namespace {
+			return amcList.Items[i].Namespace < amcList.Items[j].Namespace
+		}
+		return amcList.Items[i].Name < amcList.Items[j].Name
+	})
+	// the routes of the maintenance windows are evaluated first, so that the alerts of the
+	// clusters in maintenance are not routed to any other receiver
+	maintenanceReceivers, tenantRoutes, invalidWindows, err := newMaintenanceConfigs(c, mco, time.Now())
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	if len(invalidWindows) > 0 {
+		log.Info("Invalid maintenance windows, skip them", "errors", strings.Join(invalidWindows, "; "))
+	}
+	receivers = append(receivers, maintenanceReceivers...)
+	if mco.Spec.EnableAlertingSelfTest {
+		token, err := getSelfTestToken(c)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+		receiver, route := newSelfTestConfig(token)
+		receivers = append(receivers, receiver)
+		tenantRoutes = append(tenantRoutes, route)
+	}
+	mcoReceivers, mcoRoutes, invalid := newAlertReceiverConfigs(c, mco)
+	if len(invalid) > 0 {
+		log.Info("Invalid alert receivers, skip them", "errors", strings.Join(invalid, "; "))
+	}
+	receivers = append(receivers, mcoReceivers...)
+	tenantRoutes = append(tenantRoutes, mcoRoutes...)
+	for idx := range amcList.Items {
+		amc := &amcList.Items[idx]
+		condition := mcoshared.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionTrue,
+			Reason:  "ConfigMerged",
+			Message: "The config is merged into the alertmanager configuration",
+		}
+		tenant := getNamespaceTenant(amc.Namespace, mco.Spec.ClusterSetTenants)
+		if tenant == "" {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "NoTenant"
+			condition.Message = fmt.Sprintf("The namespace %s does not belong to any tenant", amc.Namespace)
+		} else {
+			amcReceivers, route, err := newTenantConfig(c, amc, tenant)
+			if err != nil {
+				log.Info("Invalid AlertmanagerConfig, skip it", "namespace", amc.Namespace, "name", amc.Name,
+					"error", err.Error())
+				condition.Status = metav1.ConditionFalse
+				condition.Reason = "InvalidSpec"
+				condition.Message = err.Error()
+			} else {
+				receivers = append(receivers, amcReceivers...)
+				tenantRoutes = append(tenantRoutes, route)
+			}
+		}
+		err = updateAlertmanagerConfigStatus(c, amc, condition)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+
+	if len(receivers) > 0 {
+		amConfig["receivers"] = receivers
+	}
+	// the tenant routes are evaluated before the routes of the admin, and continue
+	// so that the admin still receives the alerts of the tenants and the watchdog alerts
+	routes = append(tenantRoutes, routes...)
+	if rootRoute, ok := amConfig["route"].(map[string]interface{}); ok {
+		if len(routes) > 0 {
+			rootRoute["routes"] = routes
+		} else {
+			delete(rootRoute, "routes")
+		}
+	} else if len(tenantRoutes) > 0 {
+		log.Info("No root route in the alertmanager configuration, skip merging the AlertmanagerConfigs")
+		return nil, nil
+	}
+
+	data, err := yaml.Marshal(amConfig)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	if reflect.DeepEqual(data, original) {
+		return nil, nil
+	}
+	log.Info("Updating the alertmanager configuration with the managed routes", "routes", len(tenantRoutes))
+	secret.Data[AlertmanagerConfigKey] = data
+	err = c.Update(context.TODO(), secret)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	return nil, nil
+}
+
+// removeManagedConfigs returns the receivers and the child routes of the root route
+// which were not merged by the operator
+func removeManagedConfigs(amConfig map[string]interface{}) ([]interface{}, []interface{}) {
+	receivers := []interface{}{}
+	if list, ok := amConfig["receivers"].([]interface{}); ok {
+		for _, receiver := range list {
+			if !isManagedReceiver(receiver) {
+				receivers = append(receivers, receiver)
+			}
+		}
+	}
+	routes := []interface{}{}
+	if rootRoute, ok := amConfig["route"].(map[string]interface{}); ok {
+		if list, ok := rootRoute["routes"].([]interface{}); ok {
+			for _, route := range list {
+				if !isManagedReceiver(route) {
+					routes = append(routes, route)
+				}
+			}
+		}
+	}
+	return receivers, routes
+}
+
+// isManagedReceiver returns true if the receiver or the route is merged from an AlertmanagerConfig or
+// the alert receivers, or it is the receiver of the alerting self test or a maintenance window
+func isManagedReceiver(v interface{}) bool {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, key := range []string{"name", "receiver"} {
+		if name, ok := m[key].(string); ok &&
+			(strings.HasPrefix(name, tenantReceiverPrefix) || strings.HasPrefix(name, alertReceiverPrefix) ||
+				strings.HasPrefix(name, maintenanceReceiverPrefix) || name == selfTestReceiverName) {
+			return true
+		}
+	}
+	return false
+}
+
+// getNamespaceTenant returns the name of the tenant which manages the AlertmanagerConfigs in the namespace
+func getNamespaceTenant(namespace string, tenants []mcov1beta2.ClusterSetTenant) string {
+	for _, tenant := range tenants {
+		for _, ns := range tenant.Namespaces {
+			if ns == namespace {
+				return tenant.Name
+			}
+		}
+	}
+	return ""
+}
+
+// newTenantConfig returns the receivers and the route of the AlertmanagerConfig in the alertmanager configuration
+func newTenantConfig(c client.Client, amc *mcov1beta2.AlertmanagerConfig,
+	tenant string) ([]interface{}, map[string]interface{}, error) {
+	prefix := fmt.Sprintf("%s%s/%s/", tenantReceiverPrefix, amc.Namespace, amc.Name)
+	receivers := []interface{}{}
+	names := map[string]bool{}
+	for _, r := range amc.Spec.Receivers {
+		if r.Name == "" || names[r.Name] {
+			return nil, nil, fmt.Errorf("the name of the receiver %q is empty or duplicated", r.Name)
+		}
+		names[r.Name] = true
+		receiver, err := newTenantReceiver(c, amc.Namespace, r)
+		if err != nil {
+			return nil, nil, err
+		}
+		receiver["name"] = prefix + r.Name
+		receivers = append(receivers, receiver)
+	}
+
+	spec := amc.Spec.Route
+	if !names[spec.Receiver] {
+		return nil, nil, fmt.Errorf("the receiver %q of the route is not found", spec.Receiver)
+	}
+	match := map[string]interface{}{}
+	matchRE := map[string]interface{}{}
+	for _, matcher := range spec.Matchers {
+		if matcher.Name == mcoconfig.TenantLabelName {
+			continue
+		}
+		if matcher.Regex {
+			matchRE[matcher.Name] = matcher.Value
+		} else {
+			match[matcher.Name] = matcher.Value
+		}
+	}
+	match[mcoconfig.TenantLabelName] = tenant
+	route := map[string]interface{}{
+		"receiver": prefix + spec.Receiver,
+		"match":    match,
+		"continue": true,
+	}
+	if len(matchRE) > 0 {
+		route["match_re"] = matchRE
+	}
+	if len(spec.GroupBy) > 0 {
+		groupBy := []interface{}{}
+		for _, label := range spec.GroupBy {
+			groupBy = append(groupBy, label)
+		}
+		route["group_by"] = groupBy
+	}
+	for key, value := range map[string]string{
+		"group_wait":      spec.GroupWait,
+		"group_interval":  spec.GroupInterval,
+		"repeat_interval": spec.RepeatInterval,
+	} {
+		if value != "" {
+			route[key] = value
+		}
+	}
+	return receivers, route, nil
+}
+
+func newTenantReceiver(c client.Client, namespace string,
+	r mcov1beta2.AlertmanagerReceiver) (map[string]interface{}, error) {
+	receiver := map[string]interface{}{}
+	if len(r.WebhookConfigs) > 0 {
+		configs := []interface{}{}
+		for _, webhook := range r.WebhookConfigs {
+			url := webhook.URL
+			if webhook.URLSecret != nil {
+				value, err := getSecretValue(c, namespace, webhook.URLSecret)
+				if err != nil {
+					return nil, err
+				}
+				url = value
+			}
+			if url == "" {
+				return nil, fmt.Errorf("either url or urlSecret is required in the webhook of %s", r.Name)
+			}
+			configs = append(configs, withSendResolved(map[string]interface{}{"url": url}, webhook.SendResolved))
+		}
+		receiver["webhook_configs"] = configs
+	}
+	if len(r.SlackConfigs) > 0 {
+		configs := []interface{}{}
+		for _, slack := range r.SlackConfigs {
+			url, err := getSecretValue(c, namespace, &slack.APIURLSecret)
+			if err != nil {
+				return nil, err
+			}
+			config := map[string]interface{}{"api_url": url}
+			if slack.Channel != "" {
+				config["channel"] = slack.Channel
+			}
+			configs = append(configs, withSendResolved(config, slack.SendResolved))
+		}
+		receiver["slack_configs"] = configs
+	}
+	if len(r.EmailConfigs) > 0 {
+		configs := []interface{}{}
+		for _, email := range r.EmailConfigs {
+			if email.To == "" {
+				return nil, fmt.Errorf("the email address is required in the email of %s", r.Name)
+			}
+			configs = append(configs, withSendResolved(map[string]interface{}{"to": email.To}, email.SendResolved))
+		}
+		receiver["email_configs"] = configs
+	}
+	return receiver, nil
+}
+
+func withSendResolved(config map[string]interface{}, sendResolved *bool) map[string]interface{} {
+	if sendResolved != nil {
+		config["send_resolved"] = *sendResolved
+	}
+	return config
+}
+
+// getSecretValue returns the value of the key of the secret in the namespace
+func getSecretValue(c client.Client, namespace string, selector *corev1.SecretKeySelector) (string, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: selector.Name, Namespace: namespace}, secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the secret %s: %v", selector.Name, err)
+	}
+	value, ok := secret.Data[selector.Key]
+	if !ok || len(value) == 0 {
+		return "", fmt.Errorf("the key %s is not found in the secret %s", selector.Key, selector.Name)
+	}
+	return string(value), nil
+}
+
+func updateAlertmanagerConfigStatus(c client.Client, amc *mcov1beta2.AlertmanagerConfig,
+	condition mcoshared.Condition) error {
+	conditions := append([]mcoshared.Condition{}, amc.Status.Conditions...)
+	setStatusCondition(&conditions, condition)
+	if reflect.DeepEqual(conditions, amc.Status.Conditions) {
+		return nil
+	}
+	amc.Status.Conditions = conditions
+	err := c.Status().Update(context.TODO(), amc)
+	if err != nil && !errors.IsConflict(err) {
+		log.Error(err, "Failed to update the status of AlertmanagerConfig", "namespace", amc.Namespace,
+			"name", amc.Name)
+		return err
+	}
+	return nil
+}
+
+// ValidateAlertmanagerConfig validates the alertmanager configuration which the managed routes and
+// receivers are merged into: the root route is required and the routes must refer to the receivers
+func ValidateAlertmanagerConfig(data []byte) error {
+	amConfig := map[string]interface{}{}
+	err := yaml.Unmarshal(data, &amConfig)
+	if err != nil {
+		return err
+	}
+	receivers := map[string]bool{}
+	if list, ok := amConfig["receivers"].([]interface{}); ok {
+		for _, receiver := range list {
+			if m, ok := receiver.(map[string]interface{}); ok {
+				if name, ok := m["name"].(string); ok {
+					receivers[name] = true
+				}
+			}
+		}
+	}
+	rootRoute, ok := amConfig["route"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("no root route in the alertmanager configuration")
+	}
+	var walk func(route map[string]interface{}) error
+	walk = func(route map[string]interface{}) error {
+		if name, ok := route["receiver"].(string); ok && !receivers[name] {
+			return fmt.Errorf("the receiver %s of the route is not defined", name)
+		}
+		if list, ok := route["routes"].([]interface{}); ok {
+			for _, child := range list {
+				if m, ok := child.(map[string]interface{}); ok {
+					if err := walk(m); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+	if _, ok := rootRoute["receiver"].(string); !ok {
+		return fmt.Errorf("the receiver of the root route is required")
+	}
+	return walk(rootRoute)
+}
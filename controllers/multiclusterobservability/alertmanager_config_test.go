@@ -0,0 +1,169 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestGenerateAlertmanagerConfig(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	namespace := mcoconfig.GetDefaultNamespace()
+	amSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.AlertmanagerConfigName, Namespace: namespace},
+		Data: map[string][]byte{AlertmanagerConfigKey: []byte(`
+receivers:
+- name: "null"
+- name: tenant/team-a/old/slack
+route:
+  receiver: "null"
+  routes:
+  - receiver: tenant/team-a/old/slack
+    match:
+      tenant: team-a
+  - receiver: "null"
+    match:
+      alertname: Watchdog
+`)},
+	}
+	slackSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "slack", Namespace: "team-a"},
+		Data:       map[string][]byte{"url": []byte("https://hooks.slack.com/services/a")},
+	}
+	sendResolved := false
+	amc := &mcov1beta2.AlertmanagerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "team-a"},
+		Spec: mcov1beta2.AlertmanagerConfigSpec{
+			Route: mcov1beta2.AlertmanagerRoute{
+				Receiver: "slack",
+				GroupBy:  []string{"alertname"},
+				Matchers: []mcov1beta2.AlertmanagerMatcher{
+					{Name: "severity", Value: "critical|warning", Regex: true},
+					{Name: mcoconfig.TenantLabelName, Value: "team-b"},
+				},
+			},
+			Receivers: []mcov1beta2.AlertmanagerReceiver{
+				{
+					Name: "slack",
+					SlackConfigs: []mcov1beta2.AlertmanagerSlackConfig{
+						{
+							APIURLSecret: corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "slack"},
+								Key:                  "url",
+							},
+							Channel:      "#team-a",
+							SendResolved: &sendResolved,
+						},
+					},
+				},
+			},
+		},
+	}
+	noTenant := &mcov1beta2.AlertmanagerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-c", Namespace: "team-c"},
+		Spec: mcov1beta2.AlertmanagerConfigSpec{
+			Route:     mcov1beta2.AlertmanagerRoute{Receiver: "webhook"},
+			Receivers: []mcov1beta2.AlertmanagerReceiver{{Name: "webhook"}},
+		},
+	}
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			ClusterSetTenants: []mcov1beta2.ClusterSetTenant{
+				{Name: "team-a", ClusterSets: []string{"team-a"}, Namespaces: []string{"team-a"}},
+			},
+		},
+	}
+	c := fake.NewFakeClient(amSecret, slackSecret, amc, noTenant)
+
+	_, err := GenerateAlertmanagerConfig(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the alertmanager configuration: (%v)", err)
+	}
+	found := &corev1.Secret{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: mcoconfig.AlertmanagerConfigName, Namespace: namespace}, found)
+	if err != nil {
+		t.Fatalf("Failed to get the alertmanager configuration: (%v)", err)
+	}
+	expected := map[string]interface{}{}
+	err = yaml.Unmarshal([]byte(`
+receivers:
+- name: "null"
+- name: tenant/team-a/team-a/slack
+  slack_configs:
+  - api_url: https://hooks.slack.com/services/a
+    channel: "#team-a"
+    send_resolved: false
+route:
+  receiver: "null"
+  routes:
+  - receiver: tenant/team-a/team-a/slack
+    continue: true
+    group_by:
+    - alertname
+    match:
+      tenant: team-a
+    match_re:
+      severity: critical|warning
+  - receiver: "null"
+    match:
+      alertname: Watchdog
+`), &expected)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the expected configuration: (%v)", err)
+	}
+	merged := map[string]interface{}{}
+	err = yaml.Unmarshal(found.Data[AlertmanagerConfigKey], &merged)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the merged configuration: (%v)", err)
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("Wrong merged alertmanager configuration: %s", string(found.Data[AlertmanagerConfigKey]))
+	}
+
+	updated := &mcov1beta2.AlertmanagerConfig{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: "team-c", Namespace: "team-c"}, updated)
+	if err != nil {
+		t.Fatalf("Failed to get the AlertmanagerConfig: (%v)", err)
+	}
+	if len(updated.Status.Conditions) != 1 || updated.Status.Conditions[0].Reason != "NoTenant" {
+		t.Fatalf("The AlertmanagerConfig without tenant should not be merged: %v", updated.Status.Conditions)
+	}
+
+	// the merged receivers and routes are removed with the AlertmanagerConfigs
+	err = c.Delete(context.TODO(), amc)
+	if err != nil {
+		t.Fatalf("Failed to delete the AlertmanagerConfig: (%v)", err)
+	}
+	_, err = GenerateAlertmanagerConfig(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the alertmanager configuration: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: mcoconfig.AlertmanagerConfigName, Namespace: namespace}, found)
+	if err != nil {
+		t.Fatalf("Failed to get the alertmanager configuration: (%v)", err)
+	}
+	merged = map[string]interface{}{}
+	err = yaml.Unmarshal(found.Data[AlertmanagerConfigKey], &merged)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the merged configuration: (%v)", err)
+	}
+	if len(merged["receivers"].([]interface{})) != 1 || len(merged["route"].(map[string]interface{})["routes"].([]interface{})) != 1 {
+		t.Fatalf("The merged receivers and routes should be removed: %s", string(found.Data[AlertmanagerConfigKey]))
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/certificates"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+const (
+	authMigrationConditionType = "AuthMigration"
+	// the progress of the migration is reported in the same interval
+	authMigrationStatusInterval = time.Minute
+)
+
+// getAuthMigrationProgress returns the number of the managed clusters which are selected for the service
+// account tokens, the number of them which are switched to the tokens, and the number of the managed
+// clusters which are switching back to the client certificates
+func getAuthMigrationProgress(c client.Client, mco *mcov1beta2.MultiClusterObservability) (int, int, int, error) {
+	selected := map[string]bool{}
+	if mcoconfig.IsTokenAuthEnabled(mco) {
+		clusters := &clusterv1.ManagedClusterList{}
+		if err := c.List(context.TODO(), clusters); err != nil {
+			return 0, 0, 0, err
+		}
+		for _, cluster := range clusters.Items {
+			isToken, err := mcoconfig.IsTokenAuthCluster(mco, cluster.GetLabels())
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			selected[cluster.Name] = isToken
+		}
+	}
+	addons := &addonv1alpha1.ManagedClusterAddOnList{}
+	if err := c.List(context.TODO(), addons); err != nil {
+		return 0, 0, 0, err
+	}
+	total, switched, reverting := 0, 0, 0
+	for _, cluster := range selected {
+		if cluster {
+			total++
+		}
+	}
+	for _, addon := range addons.Items {
+		if addon.Name != util.ManagedClusterAddonName {
+			continue
+		}
+		switch addon.Annotations[mcoconfig.AuthModeAnnotation] {
+		case mcoconfig.TokenAuthMode:
+			if selected[addon.Namespace] {
+				switched++
+			} else {
+				reverting++
+			}
+		case mcoconfig.RevertingAuthMode:
+			reverting++
+		}
+	}
+	return total, switched, reverting, nil
+}
+
+// updateAuthMigrationStatus reports the progress of the managed clusters switching between the client
+// certificates and the service account tokens, and of the client certificates reissued by the rotated
+// client CA
+func updateAuthMigrationStatus(conditions *[]mcoshared.Condition, c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) {
+	selected, switched, reverting, err := getAuthMigrationProgress(c, mco)
+	if err != nil {
+		log.Error(err, "Failed to get the progress of the authentication migration")
+		return
+	}
+	rotating, pending, err := certificates.GetClientCARotationProgress(c)
+	if err != nil {
+		log.Error(err, "Failed to get the progress of the client CA rotation")
+		return
+	}
+	if selected == 0 && reverting == 0 && !rotating {
+		removeStatusCondition(conditions, authMigrationConditionType)
+		return
+	}
+
+	progress := []string{}
+	if selected > 0 {
+		progress = append(progress, fmt.Sprintf("%d of %d managed clusters are switched to the service account tokens",
+			switched, selected))
+	}
+	if reverting > 0 {
+		progress = append(progress, fmt.Sprintf("%d managed clusters are switching back to the client certificates",
+			reverting))
+	}
+	if rotating {
+		progress = append(progress, fmt.Sprintf("%d managed clusters are waiting for the client certificates "+
+			"reissued by the rotated client CA", len(pending)))
+	}
+	if switched == selected && reverting == 0 && !rotating {
+		setStatusCondition(conditions, mcoshared.Condition{
+			Type:    authMigrationConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  "MigrationComplete",
+			Message: strings.Join(progress, ", "),
+		})
+		return
+	}
+	setStatusCondition(conditions, mcoshared.Condition{
+		Type:   authMigrationConditionType,
+		Status: metav1.ConditionFalse,
+		Reason: "MigrationInProgress",
+		Message: strings.Join(progress, ", ") +
+			". The observability API accepts both the client certificates and the tokens in the meantime",
+	})
+}
+
+// isAuthMigrating returns true if the managed clusters are still switching their authentication
+func isAuthMigrating(mco *mcov1beta2.MultiClusterObservability) bool {
+	condition := findStatusCondition(mco.Status.Conditions, authMigrationConditionType)
+	return condition != nil && condition.Status == metav1.ConditionFalse
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+func TestUpdateAuthMigrationStatus(t *testing.T) {
+	s := scheme.Scheme
+	addonv1alpha1.AddToScheme(s)
+	clusterv1.AddToScheme(s)
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			GatewayAuth: &mcov1beta2.GatewayAuthSpec{
+				ServiceAccountToken: &mcov1beta2.GatewayServiceAccountTokenSpec{Enabled: true},
+			},
+		},
+	}
+	newAddon := func(cluster string, mode string) *addonv1alpha1.ManagedClusterAddOn {
+		return &addonv1alpha1.ManagedClusterAddOn{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        util.ManagedClusterAddonName,
+				Namespace:   cluster,
+				Annotations: map[string]string{mcoconfig.AuthModeAnnotation: mode},
+			},
+		}
+	}
+	c := fake.NewFakeClientWithScheme(s,
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster2"}},
+		newAddon("cluster1", mcoconfig.TokenAuthMode),
+		newAddon("cluster2", ""),
+	)
+
+	conditions := []mcoshared.Condition{}
+	updateAuthMigrationStatus(&conditions, c, mco)
+	condition := findStatusCondition(conditions, authMigrationConditionType)
+	if condition == nil || condition.Reason != "MigrationInProgress" {
+		t.Fatalf("the migration should be in progress: %v", condition)
+	}
+	mco.Status.Conditions = conditions
+	if !isAuthMigrating(mco) {
+		t.Errorf("the status should be reported until the migration completes")
+	}
+
+	// the other cluster keeps the client certificate
+	mco.Spec.GatewayAuth.ServiceAccountToken.ClusterSelector = &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "name", Operator: metav1.LabelSelectorOpDoesNotExist},
+		},
+	}
+	c = fake.NewFakeClientWithScheme(s,
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster2", Labels: map[string]string{"name": "cluster2"}}},
+		newAddon("cluster1", mcoconfig.TokenAuthMode),
+		newAddon("cluster2", ""),
+	)
+	updateAuthMigrationStatus(&conditions, c, mco)
+	condition = findStatusCondition(conditions, authMigrationConditionType)
+	if condition == nil || condition.Reason != "MigrationComplete" {
+		t.Errorf("the migration should be completed: %v", condition)
+	}
+
+	mco.Spec.GatewayAuth = nil
+	c = fake.NewFakeClientWithScheme(s)
+	updateAuthMigrationStatus(&conditions, c, mco)
+	if findStatusCondition(conditions, authMigrationConditionType) != nil {
+		t.Errorf("the condition should be removed without the migration")
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	crdClientSet "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+const (
+	// receiveScaleDownWindow holds the scale down of thanos receive, every scale of the hashring
+	// moves the series of the tenants between the replicas
+	receiveScaleDownWindow int32 = 3600
+	queryScaleDownWindow   int32 = 300
+)
+
+// GenerateAutoscalers creates the HorizontalPodAutoscalers or the KEDA ScaledObjects of the thanos
+// components which are scaled automatically, and deletes the others. The replicas which the
+// autoscalers set are recorded into the Observatorium CR by the watches of the workloads, so that
+// the observatorium operator does not revert them.
+func GenerateAutoscalers(c client.Client, crdClient crdClientSet.Interface, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	kedaExists, err := util.CheckCRDExist(crdClient, mcoconfig.ScaledObjectCrdName)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	if mcoconfig.IsKEDAAutoscaling(mco) && !kedaExists {
+		return &ctrl.Result{}, fmt.Errorf("the CRD %s is not found, install KEDA to scale the "+
+			"components with KEDA", mcoconfig.ScaledObjectCrdName)
+	}
+
+	for _, component := range mcoconfig.AutoscaledComponents {
+		name := mco.GetName() + "-" + component
+		autoscaled := mcoconfig.GetComponentAutoscaling(mco, component) != nil &&
+			!mcoconfig.IsExternalMetricsStoreEnabled(mco)
+		useKEDA := autoscaled && mcoconfig.IsKEDAAutoscaling(mco)
+
+		// delete the autoscaler of the other type first, two autoscalers must not scale the same workload
+		deleted := []client.Object{}
+		if !autoscaled || useKEDA {
+			deleted = append(deleted, &autoscalingv2beta2.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: mcoconfig.GetDefaultNamespace(),
+			}})
+		}
+		if kedaExists && !useKEDA {
+			scaledObject := &unstructured.Unstructured{}
+			scaledObject.SetAPIVersion(mcoconfig.ScaledObjectAPIVersion)
+			scaledObject.SetKind(mcoconfig.ScaledObjectKind)
+			scaledObject.SetName(name)
+			scaledObject.SetNamespace(mcoconfig.GetDefaultNamespace())
+			deleted = append(deleted, scaledObject)
+		}
+		if err := deleteResources(c, deleted); err != nil {
+			return &ctrl.Result{}, err
+		}
+		if !autoscaled {
+			continue
+		}
+
+		if useKEDA {
+			scaledObject := newScaledObject(mco, component)
+			if err := controllerutil.SetControllerReference(mco, scaledObject, scheme); err != nil {
+				return &ctrl.Result{}, err
+			}
+			if err := createOrUpdateScaledObject(c, scaledObject); err != nil {
+				return &ctrl.Result{}, err
+			}
+			continue
+		}
+		hpa := newHPA(mco, component)
+		if err := controllerutil.SetControllerReference(mco, hpa, scheme); err != nil {
+			return &ctrl.Result{}, err
+		}
+		if err := createOrUpdateHPA(c, hpa); err != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+	return nil, nil
+}
+
+// getScaleTargetRef returns the workload of the component which the observatorium operator deploys
+func getScaleTargetRef(mco *mcov1beta2.MultiClusterObservability,
+	component string) autoscalingv2beta2.CrossVersionObjectReference {
+	kind := "Deployment"
+	if component == mcoconfig.ThanosReceive {
+		kind = "StatefulSet"
+	}
+	return autoscalingv2beta2.CrossVersionObjectReference{
+		APIVersion: "apps/v1",
+		Kind:       kind,
+		Name:       mco.GetName() + "-" + component,
+	}
+}
+
+// getScaleDownBehavior scales down one replica at a time after the load stays low for the window
+func getScaleDownBehavior(component string) *autoscalingv2beta2.HPAScalingRules {
+	window := queryScaleDownWindow
+	if component == mcoconfig.ThanosReceive {
+		window = receiveScaleDownWindow
+	}
+	return &autoscalingv2beta2.HPAScalingRules{
+		StabilizationWindowSeconds: &window,
+		Policies: []autoscalingv2beta2.HPAScalingPolicy{
+			{Type: autoscalingv2beta2.PodsScalingPolicy, Value: 1, PeriodSeconds: window},
+		},
+	}
+}
+
+func newHPA(mco *mcov1beta2.MultiClusterObservability,
+	component string) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	minReplicas, maxReplicas := mcoconfig.GetAutoscalingBounds(mco, component)
+	target := mcoconfig.GetAutoscalingTarget(mco, component)
+	return &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mco.GetName() + "-" + component,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+		},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: getScaleTargetRef(mco, component),
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    maxReplicas,
+			Metrics: []autoscalingv2beta2.MetricSpec{
+				{
+					Type: autoscalingv2beta2.ResourceMetricSourceType,
+					Resource: &autoscalingv2beta2.ResourceMetricSource{
+						Name: corev1.ResourceCPU,
+						Target: autoscalingv2beta2.MetricTarget{
+							Type:               autoscalingv2beta2.UtilizationMetricType,
+							AverageUtilization: &target,
+						},
+					},
+				},
+			},
+			Behavior: &autoscalingv2beta2.HorizontalPodAutoscalerBehavior{
+				ScaleDown: getScaleDownBehavior(component),
+			},
+		},
+	}
+}
+
+func createOrUpdateHPA(c client.Client, hpa *autoscalingv2beta2.HorizontalPodAutoscaler) error {
+	found := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: hpa.Name, Namespace: hpa.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating the HorizontalPodAutoscaler", "name", hpa.Name)
+		err = c.Create(context.TODO(), hpa)
+	} else if err == nil && !equality.Semantic.DeepDerivative(hpa.Spec, found.Spec) {
+		log.Info("Updating the HorizontalPodAutoscaler", "name", hpa.Name)
+		found.Spec = hpa.Spec
+		err = c.Update(context.TODO(), found)
+	}
+	if err != nil {
+		log.Error(err, "Failed to create or update the HorizontalPodAutoscaler", "name", hpa.Name)
+	}
+	return err
+}
+
+// getAutoscalingQuery returns the query of the load of the component per second, the samples
+// ingested by thanos receive and the concurrent queries of thanos query and query frontend
+func getAutoscalingQuery(mco *mcov1beta2.MultiClusterObservability, component string) string {
+	selector := fmt.Sprintf(`namespace="%s",job="%s"`, mcoconfig.GetDefaultNamespace(),
+		mco.GetName()+"-"+component)
+	switch component {
+	case mcoconfig.ThanosReceive:
+		return fmt.Sprintf("sum(rate(prometheus_tsdb_head_samples_appended_total{%s}[2m]))", selector)
+	case mcoconfig.ThanosQuery:
+		return fmt.Sprintf("sum(thanos_query_concurrent_gate_queries_in_flight{%s})", selector)
+	}
+	return fmt.Sprintf("sum(http_inflight_requests{%s})", selector)
+}
+
+func newScaledObject(mco *mcov1beta2.MultiClusterObservability, component string) *unstructured.Unstructured {
+	minReplicas, maxReplicas := mcoconfig.GetAutoscalingBounds(mco, component)
+	targetRef := getScaleTargetRef(mco, component)
+	behavior, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(
+		&autoscalingv2beta2.HorizontalPodAutoscalerBehavior{ScaleDown: getScaleDownBehavior(component)})
+	trigger := map[string]interface{}{
+		"type": "prometheus",
+		"metadata": map[string]interface{}{
+			"serverAddress": mcoconfig.GetAutoscalingPrometheusURL(mco),
+			"query":         getAutoscalingQuery(mco, component),
+			"threshold":     strconv.Itoa(int(mcoconfig.GetAutoscalingTarget(mco, component))),
+		},
+	}
+	if mco.Spec.Autoscaling.TriggerAuthentication != "" {
+		trigger["authenticationRef"] = map[string]interface{}{
+			"name": mco.Spec.Autoscaling.TriggerAuthentication,
+		}
+	}
+
+	scaledObject := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"scaleTargetRef": map[string]interface{}{
+					"apiVersion": targetRef.APIVersion,
+					"kind":       targetRef.Kind,
+					"name":       targetRef.Name,
+				},
+				"minReplicaCount": int64(minReplicas),
+				"maxReplicaCount": int64(maxReplicas),
+				"advanced": map[string]interface{}{
+					"horizontalPodAutoscalerConfig": map[string]interface{}{
+						"behavior": behavior,
+					},
+				},
+				"triggers": []interface{}{trigger},
+			},
+		},
+	}
+	scaledObject.SetAPIVersion(mcoconfig.ScaledObjectAPIVersion)
+	scaledObject.SetKind(mcoconfig.ScaledObjectKind)
+	scaledObject.SetName(targetRef.Name)
+	scaledObject.SetNamespace(mcoconfig.GetDefaultNamespace())
+	return scaledObject
+}
+
+func createOrUpdateScaledObject(c client.Client, scaledObject *unstructured.Unstructured) error {
+	found := &unstructured.Unstructured{}
+	found.SetAPIVersion(scaledObject.GetAPIVersion())
+	found.SetKind(scaledObject.GetKind())
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      scaledObject.GetName(),
+		Namespace: scaledObject.GetNamespace(),
+	}, found)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get the ScaledObject", "name", scaledObject.GetName())
+			return err
+		}
+		log.Info("Creating the ScaledObject", "name", scaledObject.GetName())
+		err = c.Create(context.TODO(), scaledObject)
+		if err != nil {
+			log.Error(err, "Failed to create the ScaledObject", "name", scaledObject.GetName())
+		}
+		return err
+	}
+
+	if reflect.DeepEqual(found.Object["spec"], scaledObject.Object["spec"]) {
+		return nil
+	}
+	log.Info("Updating the ScaledObject", "name", scaledObject.GetName())
+	found.Object["spec"] = scaledObject.Object["spec"]
+	err = c.Update(context.TODO(), found)
+	if err != nil {
+		log.Error(err, "Failed to update the ScaledObject", "name", scaledObject.GetName())
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	fakecrdclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestGenerateAutoscalers(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	gv := schema.GroupVersion{Group: "keda.sh", Version: "v1alpha1"}
+	s.AddKnownTypeWithName(gv.WithKind(mcoconfig.ScaledObjectKind), &unstructured.Unstructured{})
+
+	namespace := mcoconfig.GetDefaultNamespace()
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			HighAvailability: &mcov1beta2.HighAvailabilitySpec{},
+			Autoscaling: &mcov1beta2.AutoscalingSpec{
+				Receive: &mcov1beta2.ComponentAutoscalingSpec{MinReplicas: 2, MaxReplicas: 1},
+				Query:   &mcov1beta2.ComponentAutoscalingSpec{},
+			},
+		},
+	}
+	c := fake.NewFakeClient(mco)
+	crdClient := fakecrdclient.NewSimpleClientset()
+
+	_, err := GenerateAutoscalers(c, crdClient, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the autoscalers: (%v)", err)
+	}
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: "observability-thanos-receive-default",
+		Namespace: namespace}, hpa)
+	if err != nil {
+		t.Fatalf("Failed to get the HorizontalPodAutoscaler of thanos receive: (%v)", err)
+	}
+	if hpa.Spec.ScaleTargetRef.Kind != "StatefulSet" || *hpa.Spec.MinReplicas != 3 || hpa.Spec.MaxReplicas != 3 {
+		t.Errorf("the minimum replicas should be raised to the replication factor: %v", hpa.Spec)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: "observability-thanos-query",
+		Namespace: namespace}, hpa)
+	if err != nil || *hpa.Spec.MinReplicas != mcoconfig.DefaultAutoscalingMinReplicas ||
+		*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization != mcoconfig.DefaultAutoscalingCPUTarget {
+		t.Errorf("thanos query should be scaled with the defaults: %v (%v)", hpa.Spec, err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: "observability-thanos-query-frontend",
+		Namespace: namespace}, hpa)
+	if !errors.IsNotFound(err) {
+		t.Errorf("thanos query frontend should not be scaled: (%v)", err)
+	}
+
+	// switch to KEDA
+	mco.Spec.Autoscaling.Type = mcoconfig.AutoscalingTypeKEDA
+	_, err = GenerateAutoscalers(c, crdClient, s, mco)
+	if err == nil {
+		t.Fatalf("the ScaledObjects should not be generated without KEDA")
+	}
+	crdClient = fakecrdclient.NewSimpleClientset(&apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.ScaledObjectCrdName},
+	})
+	_, err = GenerateAutoscalers(c, crdClient, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the autoscalers: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: "observability-thanos-query",
+		Namespace: namespace}, hpa)
+	if !errors.IsNotFound(err) {
+		t.Errorf("the HorizontalPodAutoscaler should be replaced by the ScaledObject: (%v)", err)
+	}
+	scaledObject := &unstructured.Unstructured{}
+	scaledObject.SetAPIVersion(mcoconfig.ScaledObjectAPIVersion)
+	scaledObject.SetKind(mcoconfig.ScaledObjectKind)
+	err = c.Get(context.TODO(), types.NamespacedName{Name: "observability-thanos-receive-default",
+		Namespace: namespace}, scaledObject)
+	if err != nil {
+		t.Fatalf("Failed to get the ScaledObject of thanos receive: (%v)", err)
+	}
+	triggers, _, _ := unstructured.NestedSlice(scaledObject.Object, "spec", "triggers")
+	metadata := triggers[0].(map[string]interface{})["metadata"].(map[string]interface{})
+	if !strings.Contains(metadata["query"].(string), "prometheus_tsdb_head_samples_appended_total") ||
+		metadata["threshold"] != "100000" ||
+		metadata["serverAddress"] != mcoconfig.DefaultAutoscalingPrometheusURL {
+		t.Errorf("thanos receive should be scaled on the ingestion rate: %v", metadata)
+	}
+
+	mco.Spec.Autoscaling = nil
+	_, err = GenerateAutoscalers(c, crdClient, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the autoscalers: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: "observability-thanos-receive-default",
+		Namespace: namespace}, scaledObject)
+	if !errors.IsNotFound(err) {
+		t.Errorf("the ScaledObject should be deleted once the autoscaling is disabled: (%v)", err)
+	}
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	bucketVerifyCronJobName    = "observability-bucket-verify"
+	bucketHealthyConditionType = "BucketHealthy"
+	// the log of the verification is too long for the termination message, only the warnings
+	// and the errors are kept
+	bucketVerifyScript = "thanos tools bucket verify %s > /tmp/verify.log 2>&1; rc=$?; " +
+		"grep -E 'level=(warn|error)' /tmp/verify.log | tail -c 4000 > /dev/termination-log; exit $rc"
+)
+
+var (
+	bucketVerifyIssues = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "acm_observability_bucket_verify_issues",
+		Help: "The number of the issues of the blocks in the object storage which the last verification found.",
+	}, []string{"issue"})
+	bucketVerifyLastRun = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "acm_observability_bucket_verify_last_run_timestamp_seconds",
+		Help: "The time when the last verification of the blocks in the object storage finished.",
+	})
+
+	defaultBucketVerifyIssues = []string{"overlapped_blocks", "index_known_issues"}
+	bucketVerifyIssueRegexp   = regexp.MustCompile(`\b(?:verifier|issue)="?([^"\s]+)"?`)
+
+	// lastBucketVerify caches the result of the last finished verification job
+	lastBucketVerify = &bucketVerifyResult{}
+)
+
+func init() {
+	metrics.Registry.MustRegister(bucketVerifyIssues, bucketVerifyLastRun)
+}
+
+type bucketVerifyResult struct {
+	job      string
+	finished time.Time
+	failed   bool
+	issues   map[string]int
+}
+
+func isBucketVerifyEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return !mcoconfig.IsExternalMetricsStoreEnabled(mco) &&
+		mco.Spec.StorageConfig != nil && mco.Spec.StorageConfig.BucketVerify != nil
+}
+
+// GenerateBucketVerifyCronJob creates the cronjob which verifies the blocks in the object storage
+// periodically with thanos tools bucket verify
+func GenerateBucketVerifyCronJob(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	if !isBucketVerifyEnabled(mco) {
+		err := deleteResources(c, []client.Object{
+			&batchv1beta1.CronJob{ObjectMeta: metav1.ObjectMeta{
+				Name:      bucketVerifyCronJobName,
+				Namespace: mcoconfig.GetDefaultNamespace(),
+			}},
+		})
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+		return nil, nil
+	}
+
+	cronJob := newBucketVerifyCronJob(mco)
+	if err := controllerutil.SetControllerReference(mco, cronJob, scheme); err != nil {
+		return &ctrl.Result{}, err
+	}
+	found := &batchv1beta1.CronJob{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: cronJob.Name, Namespace: cronJob.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating the bucket verify cronjob")
+		err = c.Create(context.TODO(), cronJob)
+	} else if err == nil && !equality.Semantic.DeepDerivative(cronJob.Spec, found.Spec) {
+		log.Info("Updating the bucket verify cronjob")
+		found.Spec = cronJob.Spec
+		err = c.Update(context.TODO(), found)
+	}
+	if err != nil {
+		log.Error(err, "Failed to create or update the bucket verify cronjob")
+		return &ctrl.Result{}, err
+	}
+	return nil, nil
+}
+
+func newBucketVerifyCronJob(mco *mcov1beta2.MultiClusterObservability) *batchv1beta1.CronJob {
+	verify := mco.Spec.StorageConfig.BucketVerify
+	schedule := verify.Schedule
+	if schedule == "" {
+		schedule = "0 */6 * * *"
+	}
+	issues := verify.Issues
+	if len(issues) == 0 {
+		issues = defaultBucketVerifyIssues
+	}
+	objStorageConfig := newThanosObjStorageConfig(mco)
+	args := []string{
+		"--objstore.config-file=" + objStorageMountPath + "/" + objStorageConfig.Key,
+		"--log.format=logfmt",
+	}
+	for _, issue := range issues {
+		args = append(args, "--issues="+issue)
+	}
+	labels := map[string]string{
+		"app.kubernetes.io/instance": mco.GetName(),
+		"app.kubernetes.io/name":     bucketVerifyCronJobName,
+	}
+	backoffLimit := int32(0)
+	historyLimit := int32(1)
+
+	return &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bucketVerifyCronJobName,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+			Labels:    labels,
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:                   schedule,
+			ConcurrencyPolicy:          batchv1beta1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &historyLimit,
+			FailedJobsHistoryLimit:     &historyLimit,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &backoffLimit,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec: corev1.PodSpec{
+							// the service account of the compactor with the workload identity
+							ServiceAccountName: mco.GetName() + "-" + mcoconfig.ThanosCompact,
+							RestartPolicy:      corev1.RestartPolicyNever,
+							NodeSelector:       mco.Spec.NodeSelector,
+							Tolerations:        mco.Spec.Tolerations,
+							ImagePullSecrets: []corev1.LocalObjectReference{
+								{Name: mco.Spec.ImagePullSecret},
+							},
+							Containers: []corev1.Container{
+								{
+									Name:            "thanos-bucket-verify",
+									Image:           getThanosImage(mco),
+									ImagePullPolicy: mco.Spec.ImagePullPolicy,
+									Command: []string{"/bin/sh", "-c",
+										fmt.Sprintf(bucketVerifyScript, strings.Join(args, " "))},
+									VolumeMounts: []corev1.VolumeMount{
+										{Name: "objstore", MountPath: objStorageMountPath, ReadOnly: true},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "objstore",
+									VolumeSource: corev1.VolumeSource{
+										Secret: &corev1.SecretVolumeSource{SecretName: objStorageConfig.Name},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// parseBucketVerifyIssues counts the issues in the warnings and the errors of the verification
+func parseBucketVerifyIssues(output string) map[string]int {
+	issues := map[string]int{}
+	for _, line := range strings.Split(output, "\n") {
+		isError := strings.Contains(line, "level=error")
+		if !isError && !strings.Contains(line, "level=warn") {
+			continue
+		}
+		if matches := bucketVerifyIssueRegexp.FindStringSubmatch(line); matches != nil {
+			issues[matches[1]]++
+		} else if strings.Contains(line, "overlap") {
+			issues["overlapped_blocks"]++
+		} else if isError {
+			issues["error"]++
+		}
+	}
+	return issues
+}
+
+// getLastBucketVerifyResult returns the result of the last finished verification job
+func getLastBucketVerifyResult(c client.Client) (*bucketVerifyResult, error) {
+	jobList := &batchv1.JobList{}
+	err := c.List(context.TODO(), jobList, client.InNamespace(mcoconfig.GetDefaultNamespace()),
+		client.MatchingLabels{"app.kubernetes.io/name": bucketVerifyCronJobName})
+	if err != nil {
+		return nil, err
+	}
+	var last *batchv1.Job
+	var lastFinished time.Time
+	for i := range jobList.Items {
+		for _, condition := range jobList.Items[i].Status.Conditions {
+			if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) &&
+				condition.Status == corev1.ConditionTrue && condition.LastTransitionTime.Time.After(lastFinished) {
+				last = &jobList.Items[i]
+				lastFinished = condition.LastTransitionTime.Time
+			}
+		}
+	}
+	if last == nil {
+		return nil, nil
+	}
+	if last.Name == lastBucketVerify.job {
+		return lastBucketVerify, nil
+	}
+
+	_, jobResult := isJobFinished(last)
+	result := &bucketVerifyResult{
+		job:      last.Name,
+		finished: lastFinished,
+		failed:   jobResult == batchv1.JobFailed,
+		issues:   map[string]int{},
+	}
+	podList := &corev1.PodList{}
+	err = c.List(context.TODO(), podList, client.InNamespace(last.Namespace),
+		client.MatchingLabels{"job-name": last.Name})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range podList.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil {
+				result.issues = parseBucketVerifyIssues(status.State.Terminated.Message)
+			}
+		}
+	}
+	lastBucketVerify = result
+	return result, nil
+}
+
+// updateBucketHealthStatus reports the issues of the blocks which the last verification found
+func updateBucketHealthStatus(conditions *[]mcoshared.Condition, c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) {
+	if !isBucketVerifyEnabled(mco) {
+		bucketVerifyIssues.Reset()
+		removeStatusCondition(conditions, bucketHealthyConditionType)
+		return
+	}
+	result, err := getLastBucketVerifyResult(c)
+	if err != nil {
+		log.Error(err, "Failed to get the result of the bucket verification")
+		return
+	}
+	if result == nil {
+		// not verified yet
+		return
+	}
+
+	bucketVerifyIssues.Reset()
+	bucketVerifyLastRun.Set(float64(result.finished.Unix()))
+	issues := []string{}
+	for issue, count := range result.issues {
+		bucketVerifyIssues.WithLabelValues(issue).Set(float64(count))
+		issues = append(issues, fmt.Sprintf("%s: %d", issue, count))
+	}
+	finished := result.finished.UTC().Format(time.RFC3339)
+	condition := mcoshared.Condition{
+		Type:    bucketHealthyConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "BlocksHealthy",
+		Message: fmt.Sprintf("The verification of the object storage at %s found no issues", finished),
+	}
+	if len(issues) > 0 {
+		sort.Strings(issues)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "BlocksCorrupted"
+		condition.Message = fmt.Sprintf("The verification of the object storage at %s found the issues of the blocks: %s, "+
+			"check the logs of the job %s", finished, strings.Join(issues, ", "), result.job)
+	} else if result.failed {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "VerifyFailed"
+		condition.Message = fmt.Sprintf("The verification job %s failed at %s, check the logs of the job",
+			result.job, finished)
+	}
+	setStatusCondition(conditions, condition)
+}
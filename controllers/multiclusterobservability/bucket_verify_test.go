@@ -0,0 +1,120 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestParseBucketVerifyIssues(t *testing.T) {
+	output := `level=warn ts=2021-05-01T00:00:00Z caller=overlapped_blocks.go:43 verifier=overlapped_blocks msg="found overlapped blocks" group=0@{} overlap="[mint: 1, maxt: 2]"
+level=warn ts=2021-05-01T00:00:00Z caller=index_issue.go:58 verifier=index_known_issues msg="detected issue" id=01F4 err="out of order label"
+level=warn ts=2021-05-01T00:00:00Z caller=index_issue.go:58 verifier=index_known_issues msg="detected issue" id=01F5 err="out of order label"
+level=error ts=2021-05-01T00:00:00Z caller=main.go:130 err="get bucket: access denied"`
+	issues := parseBucketVerifyIssues(output)
+	if issues["overlapped_blocks"] != 1 || issues["index_known_issues"] != 2 || issues["error"] != 1 {
+		t.Errorf("the issues are not expected: %v", issues)
+	}
+}
+
+func TestBucketVerify(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	namespace := mcoconfig.GetDefaultNamespace()
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			StorageConfig: &mcov1beta2.StorageConfig{
+				MetricObjectStorage: &mcoshared.PreConfiguredStorage{
+					Name: "thanos-object-storage",
+					Key:  "thanos.yaml",
+				},
+				BucketVerify: &mcov1beta2.BucketVerifySpec{Schedule: "0 0 * * *"},
+			},
+		},
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bucketVerifyCronJobName + "-1620000000",
+			Namespace: namespace,
+			Labels:    map[string]string{"app.kubernetes.io/name": bucketVerifyCronJobName},
+		},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()},
+			},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.Name + "-abcde",
+			Namespace: namespace,
+			Labels:    map[string]string{"job-name": job.Name},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name: "thanos-bucket-verify",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							Message: `level=warn verifier=overlapped_blocks msg="found overlapped blocks"`,
+						},
+					},
+				},
+			},
+		},
+	}
+	c := fake.NewFakeClient(job, pod)
+
+	_, err := GenerateBucketVerifyCronJob(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the bucket verify cronjob: (%v)", err)
+	}
+	cronJob := &batchv1beta1.CronJob{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: bucketVerifyCronJobName, Namespace: namespace}, cronJob)
+	if err != nil {
+		t.Fatalf("Failed to get the bucket verify cronjob: (%v)", err)
+	}
+	command := cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Command[2]
+	if cronJob.Spec.Schedule != "0 0 * * *" || !strings.Contains(command, "--issues=index_known_issues") {
+		t.Errorf("the cronjob is not expected: %s %s", cronJob.Spec.Schedule, command)
+	}
+
+	conditions := []mcoshared.Condition{}
+	updateBucketHealthStatus(&conditions, c, mco)
+	condition := findStatusCondition(conditions, bucketHealthyConditionType)
+	if condition == nil || condition.Reason != "BlocksCorrupted" ||
+		!strings.Contains(condition.Message, "overlapped_blocks: 1") {
+		t.Errorf("the overlapped blocks should be reported: %v", conditions)
+	}
+
+	mco.Spec.StorageConfig.BucketVerify = nil
+	_, err = GenerateBucketVerifyCronJob(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to delete the bucket verify cronjob: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: bucketVerifyCronJobName, Namespace: namespace}, cronJob)
+	if err == nil {
+		t.Errorf("the bucket verify cronjob should be deleted")
+	}
+	updateBucketHealthStatus(&conditions, c, mco)
+	if findStatusCondition(conditions, bucketHealthyConditionType) != nil {
+		t.Errorf("the condition should be removed once the verification is disabled")
+	}
+}
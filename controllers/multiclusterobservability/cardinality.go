@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// GenerateCardinalityRules creates the rules configmap of the thanos ruler which counts the series
+// per cluster and metric and alerts on the high-cardinality metrics, or removes it when it is disabled
+func GenerateCardinalityRules(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	if !mcoconfig.IsCardinalityGuardEnabled(mco) {
+		return nil, deleteResources(c, []client.Object{
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      mcoconfig.AlertRuleCardinalityConfigMapName,
+					Namespace: mcoconfig.GetDefaultNamespace(),
+				},
+			},
+		})
+	}
+
+	return generateRulesConfigMap(c, scheme, mco, mcoconfig.AlertRuleCardinalityConfigMapName,
+		mcoconfig.AlertRuleCardinalityFileKey, RuleGroups{Groups: []RuleGroup{newCardinalityRuleGroup(mco)}})
+}
+
+// newCardinalityRuleGroup returns the recording rule of the series per cluster and metric, the name of
+// the metric is kept in the metric label, and the alerts of the metrics which are over the threshold
+// or whose series are exploding
+func newCardinalityRuleGroup(mco *mcov1beta2.MultiClusterObservability) RuleGroup {
+	cluster := mcoconfig.GetClusterNameLabelKey()
+	threshold := mcoconfig.GetCardinalitySeriesThreshold(mco)
+	return RuleGroup{
+		Name: "cardinality-guard",
+		Rules: []Rule{
+			{
+				Record: mcoconfig.CardinalitySeriesRecord,
+				Expr: fmt.Sprintf(`count by (%s, metric) (label_replace({__name__=~".+"}, "metric", "$1", "__name__", "(.+)"))`,
+					cluster),
+			},
+			{
+				Alert: "HighCardinalityMetric",
+				Expr:  fmt.Sprintf("%s > %d", mcoconfig.CardinalitySeriesRecord, threshold),
+				For:   "15m",
+				Labels: map[string]string{
+					"severity": "warning",
+				},
+				Annotations: map[string]string{
+					"summary": "The metric has too many series.",
+					"description": fmt.Sprintf("The metric {{ $labels.metric }} in cluster {{ $labels.cluster }} "+
+						"has {{ $value }} series, which is over the threshold %d.", threshold),
+				},
+			},
+			{
+				Alert: "MetricCardinalityExplosion",
+				Expr:  mcoconfig.GetCardinalityExplosionExpr(mco),
+				For:   "5m",
+				Labels: map[string]string{
+					"severity": "critical",
+				},
+				Annotations: map[string]string{
+					"summary": "The series of the metric are exploding.",
+					"description": "The series of the metric {{ $labels.metric }} in cluster {{ $labels.cluster }} " +
+						"are more than doubled in the last hour to {{ $value }}.",
+				},
+			},
+		},
+	}
+}
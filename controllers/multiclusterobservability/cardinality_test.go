@@ -0,0 +1,37 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"strings"
+	"testing"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestNewCardinalityRuleGroup(t *testing.T) {
+	mco := &mcov1beta2.MultiClusterObservability{
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			CardinalityGuard: &mcov1beta2.CardinalityGuardSpec{
+				Enabled:         true,
+				SeriesThreshold: 5000,
+			},
+		},
+	}
+	group := newCardinalityRuleGroup(mco)
+	if len(group.Rules) != 3 || group.Rules[0].Record != mcoconfig.CardinalitySeriesRecord {
+		t.Fatalf("Wrong cardinality rules: %v", group.Rules)
+	}
+	for _, rule := range group.Rules[1:] {
+		if !strings.Contains(rule.Expr, "> 5000") {
+			t.Errorf("The alert %s does not use the series threshold: %s", rule.Alert, rule.Expr)
+		}
+	}
+
+	mco.Spec.CardinalityGuard.SeriesThreshold = 0
+	if mcoconfig.GetCardinalitySeriesThreshold(mco) != 10000 {
+		t.Errorf("Wrong default series threshold: %d", mcoconfig.GetCardinalitySeriesThreshold(mco))
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+// componentStatusRefreshInterval is the interval to refresh the status of the components while they roll out
+const componentStatusRefreshInterval = 10 * time.Second
+
+// the waiting reasons of the containers which are expected while the pods start
+var startingReasons = []string{"ContainerCreating", "PodInitializing"}
+
+// getComponentStatuses returns the rollout status of the deployments and the statefulsets of the
+// observability components on the hub, the last error is looked up in the pods of the components
+// which are not rolled out
+func getComponentStatuses(c client.Client, mco *mcov1beta2.MultiClusterObservability) []mcov1beta2.ComponentStatus {
+	statuses := []mcov1beta2.ComponentStatus{}
+	for _, name := range getHubDeploymentNames(mco) {
+		status := mcov1beta2.ComponentStatus{Name: name, Kind: "Deployment"}
+		found := &appsv1.Deployment{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: config.GetDefaultNamespace()}, found)
+		if err != nil {
+			status.LastError = getComponentGetError(status.Kind, err)
+			statuses = append(statuses, status)
+			continue
+		}
+		status.DesiredReplicas = getDesiredReplicas(found.Spec.Replicas)
+		status.ReadyReplicas = found.Status.ReadyReplicas
+		status.UpdatedReplicas = found.Status.UpdatedReplicas
+		for _, condition := range found.Status.Conditions {
+			if condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == corev1.ConditionTrue ||
+				condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse {
+				status.LastError = condition.Message
+			}
+		}
+		if status.LastError == "" && !isComponentRolledOut(status) {
+			status.LastError = getPodsError(c, found.Spec.Selector)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, name := range getHubStatefulSetNames(mco) {
+		status := mcov1beta2.ComponentStatus{Name: name, Kind: "StatefulSet"}
+		found := &appsv1.StatefulSet{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: config.GetDefaultNamespace()}, found)
+		if err != nil {
+			status.LastError = getComponentGetError(status.Kind, err)
+			statuses = append(statuses, status)
+			continue
+		}
+		status.DesiredReplicas = getDesiredReplicas(found.Spec.Replicas)
+		status.ReadyReplicas = found.Status.ReadyReplicas
+		status.UpdatedReplicas = found.Status.UpdatedReplicas
+		if !isComponentRolledOut(status) {
+			status.LastError = getPodsError(c, found.Spec.Selector)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// isComponentRolledOut returns true if all the desired replicas of the component run the latest
+// pod template and are ready
+func isComponentRolledOut(status mcov1beta2.ComponentStatus) bool {
+	return status.LastError == "" && status.ReadyReplicas >= status.DesiredReplicas &&
+		status.UpdatedReplicas >= status.DesiredReplicas
+}
+
+func getDesiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+func getComponentGetError(kind string, err error) string {
+	if errors.IsNotFound(err) {
+		return fmt.Sprintf("The %s is not created yet", kind)
+	}
+	return fmt.Sprintf("Failed to get the %s: %v", kind, err)
+}
+
+// getPodsError returns the first error of the pods of the component, e.g. the pods which are not
+// scheduled, or whose containers fail to pull the image or crash
+func getPodsError(c client.Client, selector *metav1.LabelSelector) string {
+	if selector == nil || len(selector.MatchLabels) == 0 {
+		return ""
+	}
+	podList := &corev1.PodList{}
+	err := c.List(context.TODO(), podList, client.InNamespace(config.GetDefaultNamespace()),
+		client.MatchingLabels(selector.MatchLabels))
+	if err != nil {
+		log.Error(err, "Failed to list the pods of the component")
+		return ""
+	}
+	pods := podList.Items
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	for _, pod := range pods {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+				return fmt.Sprintf("Pod %s is not scheduled: %s", pod.Name, condition.Message)
+			}
+		}
+		containerStatuses := []corev1.ContainerStatus{}
+		containerStatuses = append(containerStatuses, pod.Status.InitContainerStatuses...)
+		containerStatuses = append(containerStatuses, pod.Status.ContainerStatuses...)
+		for _, status := range containerStatuses {
+			waiting := status.State.Waiting
+			if waiting == nil || waiting.Reason == "" || util.Contains(startingReasons, waiting.Reason) {
+				continue
+			}
+			message := fmt.Sprintf("Container %s of pod %s is waiting: %s", status.Name, pod.Name, waiting.Reason)
+			if waiting.Message != "" {
+				message += ": " + waiting.Message
+			}
+			return message
+		}
+	}
+	return ""
+}
+
+// updateComponentStatuses refreshes the rollout status of the components, it returns true if any
+// component is still rolling out
+func updateComponentStatuses(status *mcov1beta2.MultiClusterObservabilityStatus, c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) bool {
+	status.Components = getComponentStatuses(c, mco)
+	for _, component := range status.Components {
+		if !isComponentRolledOut(component) {
+			return true
+		}
+	}
+	return false
+}
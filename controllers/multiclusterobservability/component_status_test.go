@@ -0,0 +1,93 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestComponentStatuses(t *testing.T) {
+	config.SetMonitoringCRName("observability")
+	namespace := config.GetDefaultNamespace()
+	replicas := int32(2)
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "grafana"}}
+
+	objs := []runtime.Object{
+		// grafana is rolling out, one of its new pods fails to pull the image
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "observability-grafana", Namespace: namespace},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas, Selector: selector},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 2, UpdatedReplicas: 1},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "observability-grafana-1", Namespace: namespace,
+				Labels: selector.MatchLabels},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name: "grafana",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason: "ImagePullBackOff", Message: "Back-off pulling image"}},
+				}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "observability-grafana-0", Namespace: namespace,
+				Labels: selector.MatchLabels},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "grafana",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+				}},
+			},
+		},
+		// rbac-query-proxy is rolled out
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "observability-rbac-query-proxy", Namespace: namespace},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 2, UpdatedReplicas: 2},
+		},
+	}
+	c := fake.NewFakeClient(objs...)
+	// only grafana, rbac-query-proxy and alertmanager are deployed with the external metrics store
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			ExternalMetricsStore: &mcov1beta2.ExternalMetricsStoreSpec{
+				RemoteWriteURL: "https://mimir.example.com/api/v1/push",
+			},
+		},
+	}
+
+	status := &mcov1beta2.MultiClusterObservabilityStatus{}
+	if !updateComponentStatuses(status, c, mco) {
+		t.Errorf("The components should be reported as rolling out")
+	}
+	statuses := map[string]mcov1beta2.ComponentStatus{}
+	for _, component := range status.Components {
+		statuses[component.Name] = component
+	}
+	grafana := statuses["observability-grafana"]
+	if grafana.DesiredReplicas != 2 || grafana.UpdatedReplicas != 1 ||
+		!strings.Contains(grafana.LastError, "observability-grafana-1 is waiting: ImagePullBackOff") {
+		t.Errorf("Wrong status of grafana: %v", grafana)
+	}
+	proxy := statuses["observability-rbac-query-proxy"]
+	if proxy.LastError != "" || !isComponentRolledOut(proxy) {
+		t.Errorf("Wrong status of rbac-query-proxy: %v", proxy)
+	}
+	alertmanager := statuses["observability-alertmanager"]
+	if alertmanager.Kind != "StatefulSet" || alertmanager.LastError != "The StatefulSet is not created yet" {
+		t.Errorf("Wrong status of alertmanager: %v", alertmanager)
+	}
+}
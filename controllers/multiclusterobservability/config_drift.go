@@ -0,0 +1,38 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/deploying"
+)
+
+const configDriftConditionType = "ConfigDrift"
+
+// updateConfigDriftStatus reports the resources owned by the operator whose external modifications
+// are reverted recently, which usually means that another automation manages them as well
+func updateConfigDriftStatus(conditions *[]mcoshared.Condition) {
+	drifted := deploying.GetRecentDrifts(time.Now().Add(-deploying.DriftRetention))
+	if len(drifted) == 0 {
+		removeStatusCondition(conditions, configDriftConditionType)
+		return
+	}
+	resources := make([]string, len(drifted))
+	for i, r := range drifted {
+		resources[i] = r.String()
+	}
+	setStatusCondition(conditions, mcoshared.Condition{
+		Type:   configDriftConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: deploying.ReasonConfigDrift,
+		Message: fmt.Sprintf("The external modifications of %d resources owned by the operator are reverted in the last %s: %s",
+			len(resources), deploying.DriftRetention, strings.Join(resources, ", ")),
+	})
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/deploying"
+)
+
+func TestConfigDriftStatus(t *testing.T) {
+	conditions := []mcoshared.Condition{}
+	deploying.RecordDrift(&corev1.ObjectReference{Kind: "ConfigMap", Namespace: "open-cluster-management-observability",
+		Name: "thanos-ruler-default-rules"})
+	updateConfigDriftStatus(&conditions)
+	condition := findStatusCondition(conditions, configDriftConditionType)
+	if condition == nil || !strings.Contains(condition.Message,
+		"ConfigMap/open-cluster-management-observability/thanos-ruler-default-rules") {
+		t.Errorf("The reverted external modification should be reported: %v", condition)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// GenerateCostRules creates the rules configmap of the thanos ruler which aggregates the cost
+// metrics of the managed clusters by cluster and namespace, or removes it when it is disabled
+func GenerateCostRules(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	if !mco.Spec.EnableCostAggregation {
+		return nil, deleteResources(c, []client.Object{
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      mcoconfig.AlertRuleCostConfigMapName,
+					Namespace: mcoconfig.GetDefaultNamespace(),
+				},
+			},
+		})
+	}
+	return generateRulesConfigMap(c, scheme, mco, mcoconfig.AlertRuleCostConfigMapName,
+		mcoconfig.AlertRuleCostFileKey, RuleGroups{Groups: []RuleGroup{newCostRuleGroup()}})
+}
+
+// newCostRuleGroup returns the recording rules of the hourly cost per cluster and per namespace,
+// the allocation of the containers is priced by the hourly cost of the node which runs them
+func newCostRuleGroup() RuleGroup {
+	cluster := mcoconfig.GetClusterNameLabelKey()
+	return RuleGroup{
+		Name: "fleet-cost",
+		Rules: []Rule{
+			{
+				Record: "cluster:node_total_hourly_cost:sum",
+				Expr:   fmt.Sprintf("sum by (%s) (node_total_hourly_cost)", cluster),
+			},
+			{
+				Record: "namespace:container_cpu_allocation:sum",
+				Expr:   fmt.Sprintf("sum by (%s, namespace) (container_cpu_allocation)", cluster),
+			},
+			{
+				Record: "namespace:container_memory_allocation_bytes:sum",
+				Expr:   fmt.Sprintf("sum by (%s, namespace) (container_memory_allocation_bytes)", cluster),
+			},
+			{
+				Record: "namespace:cpu_hourly_cost:sum",
+				Expr: fmt.Sprintf("sum by (%s, namespace) (container_cpu_allocation "+
+					"* on (%s, node) group_left() node_cpu_hourly_cost)", cluster, cluster),
+			},
+			{
+				Record: "namespace:memory_hourly_cost:sum",
+				Expr: fmt.Sprintf("sum by (%s, namespace) (container_memory_allocation_bytes / 1024 / 1024 / 1024 "+
+					"* on (%s, node) group_left() node_ram_hourly_cost)", cluster, cluster),
+			},
+			{
+				Record: "namespace:total_hourly_cost:sum",
+				Expr:   "namespace:cpu_hourly_cost:sum + namespace:memory_hourly_cost:sum",
+			},
+			{
+				Record: "cluster:total_hourly_cost:sum",
+				Expr:   fmt.Sprintf("sum by (%s) (namespace:total_hourly_cost:sum)", cluster),
+			},
+		},
+	}
+}
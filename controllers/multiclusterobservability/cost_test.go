@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestGenerateCostRules(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			EnableCostAggregation: true,
+			RetentionConfig:       &mcov1beta2.RetentionConfig{},
+			StorageConfig: &mcov1beta2.StorageConfig{
+				StorageClass:            "gp2",
+				AlertmanagerStorageSize: "1Gi",
+				CompactStorageSize:      "1Gi",
+				RuleStorageSize:         "1Gi",
+				ReceiveStorageSize:      "1Gi",
+				StoreStorageSize:        "1Gi",
+			},
+		},
+	}
+	c := fake.NewFakeClient(mco)
+	key := types.NamespacedName{
+		Name:      mcoconfig.AlertRuleCostConfigMapName,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}
+
+	_, err := GenerateCostRules(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the cost rules: (%v)", err)
+	}
+	cm := &corev1.ConfigMap{}
+	err = c.Get(context.TODO(), key, cm)
+	if err != nil {
+		t.Fatalf("Failed to get the cost rules configmap: (%v)", err)
+	}
+	ruleGroups := &RuleGroups{}
+	err = yaml.Unmarshal([]byte(cm.Data[mcoconfig.AlertRuleCostFileKey]), ruleGroups)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the cost rules: (%v)", err)
+	}
+	if len(ruleGroups.Groups) != 1 || len(ruleGroups.Groups[0].Rules) == 0 {
+		t.Fatalf("Wrong cost rule groups: %v", ruleGroups.Groups)
+	}
+
+	rulesConfig := newRuleSpec(mco, "gp2").RulesConfig
+	if rulesConfig[len(rulesConfig)-1].Name != mcoconfig.AlertRuleCostConfigMapName {
+		t.Fatalf("The cost rules are not loaded by the thanos ruler: %v", rulesConfig)
+	}
+
+	mco.Spec.EnableCostAggregation = false
+	_, err = GenerateCostRules(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to remove the cost rules: (%v)", err)
+	}
+	err = c.Get(context.TODO(), key, cm)
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The cost rules configmap should be removed: (%v)", err)
+	}
+}
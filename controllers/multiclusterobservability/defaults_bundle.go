@@ -0,0 +1,179 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	grafanaDashboardPrefix      = "grafana-dashboard-"
+	defaultsPinnedConditionType = "DefaultsPinned"
+)
+
+// isDefaultsResource returns true if the rendered resource is one of the default dashboards,
+// the default alert rules or the default metrics allowlist
+func isDefaultsResource(res *unstructured.Unstructured) bool {
+	if res.GetKind() != "ConfigMap" {
+		return false
+	}
+	name := res.GetName()
+	return (strings.HasPrefix(name, grafanaDashboardPrefix) && name != mcoconfig.GrafanaDashboardSyncName) ||
+		name == mcoconfig.AlertRuleDefaultConfigMapName || name == mcoconfig.AllowlistConfigMapName
+}
+
+// getDefaultsBundleName returns the name of the configmap of the bundle of the version
+func getDefaultsBundleName(version string) string {
+	return mcoconfig.DefaultsBundleNamePrefix + strings.ToLower(version)
+}
+
+// ExportDefaultsBundle exports the default dashboards, alert rules and metrics allowlist which are
+// rendered by the running operator into the configmap of the bundle of its version, so that the
+// users can diff them against their customizations and pin them across the upgrades. The data
+// of each resource is stored with the key <configmap>.<key>.
+func ExportDefaultsBundle(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability, resources []*unstructured.Unstructured) error {
+	version := mcoconfig.GetComponentVersion()
+	data := map[string]string{}
+	for _, res := range resources {
+		if !isDefaultsResource(res) {
+			continue
+		}
+		resData, _, err := unstructured.NestedStringMap(res.Object, "data")
+		if err != nil {
+			return err
+		}
+		for key, value := range resData {
+			data[res.GetName()+"."+key] = value
+		}
+	}
+
+	bundle := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        getDefaultsBundleName(version),
+			Namespace:   mcoconfig.GetDefaultNamespace(),
+			Labels:      map[string]string{mcoconfig.DefaultsBundleLabelKey: "true"},
+			Annotations: map[string]string{mcoconfig.DefaultsBundleVersionAnnotation: version},
+		},
+		Data: data,
+	}
+	if err := controllerutil.SetControllerReference(mco, bundle, scheme); err != nil {
+		return err
+	}
+
+	found := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: bundle.Name, Namespace: bundle.Namespace}, found)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("Exporting the bundle of the defaults", "version", version)
+			return c.Create(context.TODO(), bundle)
+		}
+		return err
+	}
+	// the defaults of the same version only change in the development builds
+	if !reflect.DeepEqual(found.Data, bundle.Data) {
+		log.Info("Updating the bundle of the defaults", "version", version)
+		found.Data = bundle.Data
+		return c.Update(context.TODO(), found)
+	}
+	return nil
+}
+
+// ApplyPinnedDefaults replaces the data of the rendered default dashboards, alert rules and metrics
+// allowlist with the data in the bundle of the pinned version. The defaults which are not in the
+// pinned bundle, e.g. the dashboards added in the later versions, are still deployed.
+func ApplyPinnedDefaults(c client.Client, mco *mcov1beta2.MultiClusterObservability,
+	resources []*unstructured.Unstructured) error {
+	version := mco.Spec.PinnedDefaultsVersion
+	if version == "" || version == mcoconfig.GetComponentVersion() {
+		return nil
+	}
+	bundle := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      getDefaultsBundleName(version),
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, bundle)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// the defaults of the running operator are deployed, the status shows the missing bundle
+			log.Info("The bundle of the pinned defaults is not found", "version", version)
+			return nil
+		}
+		return err
+	}
+
+	pinned := map[string]map[string]string{}
+	for key, value := range bundle.Data {
+		parts := strings.SplitN(key, ".", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if pinned[parts[0]] == nil {
+			pinned[parts[0]] = map[string]string{}
+		}
+		pinned[parts[0]][parts[1]] = value
+	}
+	for _, res := range resources {
+		if !isDefaultsResource(res) {
+			continue
+		}
+		data, ok := pinned[res.GetName()]
+		if !ok {
+			continue
+		}
+		err = unstructured.SetNestedStringMap(res.Object, data, "data")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateDefaultsPinnedStatus shows whether the bundle of the pinned defaults is found in the
+// status condition
+func updateDefaultsPinnedStatus(conditions *[]mcoshared.Condition, c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) {
+	version := mco.Spec.PinnedDefaultsVersion
+	if version == "" {
+		removeStatusCondition(conditions, defaultsPinnedConditionType)
+		return
+	}
+	condition := mcoshared.Condition{
+		Type:    defaultsPinnedConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "BundleFound",
+		Message: fmt.Sprintf("The defaults are pinned to the bundle of version %s", version),
+	}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      getDefaultsBundleName(version),
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, &corev1.ConfigMap{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get the bundle of the pinned defaults", "version", version)
+			return
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "BundleNotFound"
+		condition.Message = fmt.Sprintf("The bundle of version %s is not found, the defaults of version %s are deployed",
+			version, mcoconfig.GetComponentVersion())
+	}
+	setStatusCondition(conditions, condition)
+}
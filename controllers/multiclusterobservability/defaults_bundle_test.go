@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func newTestConfigMap(name string, data map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": mcoconfig.GetDefaultNamespace(),
+		},
+		"data": data,
+	}}
+}
+
+func TestDefaultsBundle(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+	}
+	c := fake.NewFakeClient()
+
+	os.Setenv(mcoconfig.ComponentVersion, "2.3.0")
+	defer os.Unsetenv(mcoconfig.ComponentVersion)
+	resources := []*unstructured.Unstructured{
+		newTestConfigMap("grafana-dashboard-acm-clusters-overview",
+			map[string]interface{}{"acm-clusters-overview.json": `{"version": 1}`}),
+		newTestConfigMap(mcoconfig.AllowlistConfigMapName,
+			map[string]interface{}{"metrics_list.yaml": "names:\n- up\n"}),
+		newTestConfigMap(mcoconfig.GrafanaDashboardSyncName,
+			map[string]interface{}{"dashboard-sync.yaml": "apiVersion: 1\n"}),
+	}
+	err := ExportDefaultsBundle(c, s, mco, resources)
+	if err != nil {
+		t.Fatalf("Failed to export the bundle of the defaults: (%v)", err)
+	}
+	bundle := &corev1.ConfigMap{}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.DefaultsBundleNamePrefix + "2.3.0",
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, bundle)
+	if err != nil {
+		t.Fatalf("Failed to get the bundle of the defaults: (%v)", err)
+	}
+	if len(bundle.Data) != 2 ||
+		bundle.Data["grafana-dashboard-acm-clusters-overview.acm-clusters-overview.json"] != `{"version": 1}` {
+		t.Fatalf("Wrong data in the bundle of the defaults: %v", bundle.Data)
+	}
+
+	// the operator is upgraded with the defaults pinned to the previous version
+	os.Setenv(mcoconfig.ComponentVersion, "2.4.0")
+	mco.Spec.PinnedDefaultsVersion = "2.3.0"
+	resources = []*unstructured.Unstructured{
+		newTestConfigMap("grafana-dashboard-acm-clusters-overview",
+			map[string]interface{}{"acm-clusters-overview.json": `{"version": 2}`}),
+		newTestConfigMap("grafana-dashboard-acm-new",
+			map[string]interface{}{"acm-new.json": `{"version": 2}`}),
+	}
+	err = ApplyPinnedDefaults(c, mco, resources)
+	if err != nil {
+		t.Fatalf("Failed to apply the pinned defaults: (%v)", err)
+	}
+	data, _, _ := unstructured.NestedStringMap(resources[0].Object, "data")
+	if data["acm-clusters-overview.json"] != `{"version": 1}` {
+		t.Fatalf("The dashboard should be pinned to the previous version: %v", data)
+	}
+	data, _, _ = unstructured.NestedStringMap(resources[1].Object, "data")
+	if data["acm-new.json"] != `{"version": 2}` {
+		t.Fatalf("The dashboard which is not in the pinned bundle should not be changed: %v", data)
+	}
+
+	conditions := []mcoshared.Condition{}
+	updateDefaultsPinnedStatus(&conditions, c, mco)
+	condition := findStatusCondition(conditions, defaultsPinnedConditionType)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("The bundle of the pinned defaults should be found: %v", conditions)
+	}
+	mco.Spec.PinnedDefaultsVersion = "2.2.0"
+	updateDefaultsPinnedStatus(&conditions, c, mco)
+	condition = findStatusCondition(conditions, defaultsPinnedConditionType)
+	if condition == nil || condition.Reason != "BundleNotFound" {
+		t.Fatalf("The missing bundle of the pinned defaults should be shown: %v", conditions)
+	}
+}
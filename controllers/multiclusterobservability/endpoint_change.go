@@ -0,0 +1,296 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/certificates"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	endpointChangeConditionType = "EndpointChange"
+	// previousEndpointLabel is the label of the routes which keep serving the previous hosts of the
+	// observatorium api until the managed clusters move to the new host
+	previousEndpointLabel = "observability.open-cluster-management.io/previous-endpoint"
+	// endpointDrainPeriod is the minimum time the previous hosts are served after the change, so
+	// that the collectors on the managed clusters reload the new hub info
+	endpointDrainPeriod = 30 * time.Minute
+	// workOwnerLabelKey and workOwnerLabelValue select the manifestworks of the managed clusters
+	workOwnerLabelKey   = "owner"
+	workOwnerLabelValue = "multicluster-observability-operator"
+)
+
+// HandleObsAPIEndpointChange moves the managed clusters to the new host of the observatorium api
+// route without a gap in the remote writes. The server certificate is reissued for both the new and
+// the previous hosts first and a route keeps serving each previous host, then the manifestworks of
+// the managed clusters are updated with the new endpoint which the certificate covers. The previous
+// hosts are removed once every manifestwork is applied with the new endpoint and the drain period passed.
+func HandleObsAPIEndpointChange(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	if mcoconfig.IsExternalMetricsStoreEnabled(mco) {
+		return nil, nil
+	}
+	host, err := mcoconfig.GetObsAPIUrl(c, mcoconfig.GetDefaultNamespace())
+	if err != nil || host == "" {
+		// the router has not admitted the route yet
+		return nil, nil
+	}
+	secret := &corev1.Secret{}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.ServerCerts,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		log.Error(err, "Failed to get the server certificate secret")
+		return &ctrl.Result{}, err
+	}
+	endpoint := secret.Annotations[mcoconfig.ObsAPIEndpointAnnotation]
+	previous := getPreviousEndpoints(secret)
+
+	if endpoint == "" {
+		// the certificate was issued before the endpoint was recorded
+		err = certificates.ReissueServerCerts(c, mco, []string{host}, map[string]string{
+			mcoconfig.ObsAPIEndpointAnnotation: host,
+		})
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+		return nil, nil
+	}
+
+	if host != endpoint {
+		log.Info("The host of the observatorium api route changed", "previous", endpoint, "new", host)
+		previous = appendEndpoint(previous, endpoint)
+		previous = removeEndpoint(previous, host)
+		err = certificates.ReissueServerCerts(c, mco, append([]string{host}, previous...), map[string]string{
+			mcoconfig.ObsAPIEndpointAnnotation:          host,
+			mcoconfig.ObsAPIPreviousEndpointsAnnotation: strings.Join(previous, ","),
+			mcoconfig.ObsAPIEndpointChangedAnnotation:   time.Now().UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+		err = syncPreviousEndpointRoutes(c, scheme, mco, previous)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+		return &ctrl.Result{RequeueAfter: endpointDrainPeriod}, nil
+	}
+
+	if len(previous) == 0 {
+		return nil, nil
+	}
+	err = syncPreviousEndpointRoutes(c, scheme, mco, previous)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	updated, total, err := getEndpointChangeProgress(c, host)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	changedAt, err := time.Parse(time.RFC3339, secret.Annotations[mcoconfig.ObsAPIEndpointChangedAnnotation])
+	if err != nil {
+		changedAt = time.Now()
+	}
+	if remaining := time.Until(changedAt.Add(endpointDrainPeriod)); remaining > 0 || updated < total {
+		if remaining < time.Minute {
+			remaining = time.Minute
+		}
+		log.Info("Serving the previous endpoints until the managed clusters move to the new endpoint",
+			"previous", previous, "updated", updated, "total", total)
+		return &ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	log.Info("Every managed cluster moved to the new endpoint, removing the previous endpoints", "previous", previous)
+	err = certificates.ReissueServerCerts(c, mco, []string{host}, map[string]string{
+		mcoconfig.ObsAPIPreviousEndpointsAnnotation: "",
+		mcoconfig.ObsAPIEndpointChangedAnnotation:   "",
+	})
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	err = c.DeleteAllOf(context.TODO(), &routev1.Route{}, client.InNamespace(mcoconfig.GetDefaultNamespace()),
+		client.HasLabels{previousEndpointLabel})
+	if err != nil {
+		log.Error(err, "Failed to delete the routes of the previous endpoints")
+		return &ctrl.Result{}, err
+	}
+	return nil, nil
+}
+
+func getPreviousEndpoints(secret *corev1.Secret) []string {
+	previous := secret.Annotations[mcoconfig.ObsAPIPreviousEndpointsAnnotation]
+	if previous == "" {
+		return nil
+	}
+	return strings.Split(previous, ",")
+}
+
+func appendEndpoint(endpoints []string, endpoint string) []string {
+	for _, e := range endpoints {
+		if e == endpoint {
+			return endpoints
+		}
+	}
+	return append(endpoints, endpoint)
+}
+
+func removeEndpoint(endpoints []string, endpoint string) []string {
+	result := []string{}
+	for _, e := range endpoints {
+		if e != endpoint {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// syncPreviousEndpointRoutes creates a passthrough route for each previous host, the server
+// certificate covers the previous hosts too so the managed clusters which still write to them
+// are not interrupted
+func syncPreviousEndpointRoutes(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability, previous []string) error {
+	routeNames := map[string]bool{}
+	for idx, host := range previous {
+		route := &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-previous-%d", obsAPIGateway, idx),
+				Namespace: mcoconfig.GetDefaultNamespace(),
+				Labels:    map[string]string{previousEndpointLabel: "true"},
+			},
+			Spec: routev1.RouteSpec{
+				Host: host,
+				Port: &routev1.RoutePort{
+					TargetPort: intstr.FromString("public"),
+				},
+				To: routev1.RouteTargetReference{
+					Kind: "Service",
+					Name: mco.Name + "-observatorium-api",
+				},
+				TLS: &routev1.TLSConfig{
+					Termination:                   routev1.TLSTerminationPassthrough,
+					InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyNone,
+				},
+			},
+		}
+		routeNames[route.Name] = true
+		if err := controllerutil.SetControllerReference(mco, route, scheme); err != nil {
+			return err
+		}
+		found := &routev1.Route{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, found)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if errors.IsNotFound(err) {
+			log.Info("Creating the route of the previous endpoint", "name", route.Name, "host", host)
+			err = c.Create(context.TODO(), route)
+		} else if found.Spec.Host != host {
+			found.Spec = route.Spec
+			err = c.Update(context.TODO(), found)
+		}
+		if err != nil {
+			log.Error(err, "Failed to sync the route of the previous endpoint", "name", route.Name)
+			return err
+		}
+	}
+
+	// the route host changed back to a previous host
+	routes := &routev1.RouteList{}
+	err := c.List(context.TODO(), routes, client.InNamespace(mcoconfig.GetDefaultNamespace()),
+		client.HasLabels{previousEndpointLabel})
+	if err != nil {
+		return err
+	}
+	for idx := range routes.Items {
+		if routeNames[routes.Items[idx].Name] {
+			continue
+		}
+		log.Info("Deleting the route of the previous endpoint", "name", routes.Items[idx].Name)
+		err = c.Delete(context.TODO(), &routes.Items[idx])
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// getEndpointChangeProgress returns the number of the manifestworks which are applied with the
+// endpoint, and the number of all the manifestworks of the managed clusters
+func getEndpointChangeProgress(c client.Client, endpoint string) (int, int, error) {
+	works := &workv1.ManifestWorkList{}
+	err := c.List(context.TODO(), works, client.MatchingLabels{workOwnerLabelKey: workOwnerLabelValue})
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return 0, 0, nil
+		}
+		log.Error(err, "Failed to list the manifestworks")
+		return 0, 0, err
+	}
+	updated := 0
+	for _, work := range works.Items {
+		if work.Annotations[mcoconfig.ObsAPIEndpointAnnotation] == endpoint &&
+			meta.IsStatusConditionTrue(work.Status.Conditions, workv1.WorkApplied) {
+			updated++
+		}
+	}
+	return updated, len(works.Items), nil
+}
+
+// updateEndpointChangeStatus reports the progress of the managed clusters moving to the new
+// endpoint of the observatorium api
+func updateEndpointChangeStatus(conditions *[]mcoshared.Condition, c client.Client) {
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.ServerCerts,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			removeStatusCondition(conditions, endpointChangeConditionType)
+		}
+		return
+	}
+	previous := getPreviousEndpoints(secret)
+	if len(previous) == 0 {
+		removeStatusCondition(conditions, endpointChangeConditionType)
+		return
+	}
+	endpoint := secret.Annotations[mcoconfig.ObsAPIEndpointAnnotation]
+	updated, total, err := getEndpointChangeProgress(c, endpoint)
+	if err != nil {
+		return
+	}
+	setStatusCondition(conditions, mcoshared.Condition{
+		Type:   endpointChangeConditionType,
+		Status: metav1.ConditionFalse,
+		Reason: "EndpointChanging",
+		Message: fmt.Sprintf("The endpoint is changing to %s, %d of %d managed clusters are updated, "+
+			"the previous endpoints %s are served until every managed cluster is updated",
+			endpoint, updated, total, strings.Join(previous, ", ")),
+	})
+}
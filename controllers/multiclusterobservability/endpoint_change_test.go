@@ -0,0 +1,175 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/certificates"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func getServerCertsSecret(t *testing.T, c client.Client) (*corev1.Secret, []string) {
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.ServerCerts,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, secret)
+	if err != nil {
+		t.Fatalf("Failed to get the server certificate secret: (%v)", err)
+	}
+	hosts, err := certificates.GetServerCertHosts(secret)
+	if err != nil {
+		t.Fatalf("Failed to parse the server certificate: (%v)", err)
+	}
+	return secret, hosts
+}
+
+func TestHandleObsAPIEndpointChange(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	routev1.AddToScheme(s)
+	workv1.AddToScheme(s)
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+	}
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      obsAPIGateway,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+		},
+		Spec: routev1.RouteSpec{Host: "observatorium-api.apps.hub.example.com"},
+	}
+	work := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster1-observability",
+			Namespace: "cluster1",
+			Labels:    map[string]string{workOwnerLabelKey: workOwnerLabelValue},
+			Annotations: map[string]string{
+				mcoconfig.ObsAPIEndpointAnnotation: "observatorium-api.apps.hub.example.com",
+			},
+		},
+	}
+	c := fake.NewFakeClient(mco, route, work)
+	err := certificates.CreateObservabilityCerts(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to create the certificates: (%v)", err)
+	}
+
+	_, err = HandleObsAPIEndpointChange(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to handle the endpoint change: (%v)", err)
+	}
+	secret, _ := getServerCertsSecret(t, c)
+	if secret.Annotations[mcoconfig.ObsAPIEndpointAnnotation] != route.Spec.Host {
+		t.Errorf("the endpoint of the certificate should be recorded: %v", secret.Annotations)
+	}
+
+	// add the custom domain
+	route.Spec.Host = "metrics.example.com"
+	err = c.Update(context.TODO(), route)
+	if err != nil {
+		t.Fatalf("Failed to update the route: (%v)", err)
+	}
+	_, err = HandleObsAPIEndpointChange(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to handle the endpoint change: (%v)", err)
+	}
+	secret, hosts := getServerCertsSecret(t, c)
+	for _, host := range []string{"metrics.example.com", "observatorium-api.apps.hub.example.com"} {
+		found := false
+		for _, h := range hosts {
+			found = found || h == host
+		}
+		if !found {
+			t.Errorf("the server certificate should cover %s: %v", host, hosts)
+		}
+	}
+	if secret.Annotations[mcoconfig.ObsAPIEndpointAnnotation] != "metrics.example.com" ||
+		secret.Annotations[mcoconfig.ObsAPIPreviousEndpointsAnnotation] != "observatorium-api.apps.hub.example.com" {
+		t.Errorf("the endpoints should be recorded: %v", secret.Annotations)
+	}
+	endpoint, err := mcoconfig.GetObsAPIEndpoint(c, mcoconfig.GetDefaultNamespace())
+	if err != nil || endpoint != "metrics.example.com" {
+		t.Errorf("the managed clusters should move to the new endpoint: %s (%v)", endpoint, err)
+	}
+	previousRoute := &routev1.Route{}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      obsAPIGateway + "-previous-0",
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, previousRoute)
+	if err != nil || previousRoute.Spec.Host != "observatorium-api.apps.hub.example.com" {
+		t.Errorf("the previous endpoint should be served: %v (%v)", previousRoute.Spec, err)
+	}
+	conditions := []mcoshared.Condition{}
+	updateEndpointChangeStatus(&conditions, c)
+	if condition := findStatusCondition(conditions, endpointChangeConditionType); condition == nil ||
+		condition.Reason != "EndpointChanging" {
+		t.Errorf("the endpoint change should be reported: %v", conditions)
+	}
+
+	// the previous endpoint is kept until the manifestwork is applied with the new endpoint
+	secret.Annotations[mcoconfig.ObsAPIEndpointChangedAnnotation] =
+		time.Now().Add(-endpointDrainPeriod).UTC().Format(time.RFC3339)
+	err = c.Update(context.TODO(), secret)
+	if err != nil {
+		t.Fatalf("Failed to update the server certificate secret: (%v)", err)
+	}
+	result, err := HandleObsAPIEndpointChange(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to handle the endpoint change: (%v)", err)
+	}
+	if result == nil || result.RequeueAfter == 0 {
+		t.Errorf("the change should be requeued until the manifestwork is updated")
+	}
+
+	work.Annotations[mcoconfig.ObsAPIEndpointAnnotation] = "metrics.example.com"
+	work.Status.Conditions = []metav1.Condition{
+		{Type: workv1.WorkApplied, Status: metav1.ConditionTrue, Reason: "AppliedManifestWorkComplete"},
+	}
+	err = c.Update(context.TODO(), work)
+	if err != nil {
+		t.Fatalf("Failed to update the manifestwork: (%v)", err)
+	}
+	_, err = HandleObsAPIEndpointChange(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to handle the endpoint change: (%v)", err)
+	}
+	secret, hosts = getServerCertsSecret(t, c)
+	for _, host := range hosts {
+		if host == "observatorium-api.apps.hub.example.com" {
+			t.Errorf("the previous endpoint should be removed from the server certificate: %v", hosts)
+		}
+	}
+	if _, found := secret.Annotations[mcoconfig.ObsAPIPreviousEndpointsAnnotation]; found {
+		t.Errorf("the previous endpoints should be removed: %v", secret.Annotations)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      obsAPIGateway + "-previous-0",
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, previousRoute)
+	if !errors.IsNotFound(err) {
+		t.Errorf("the route of the previous endpoint should be deleted: (%v)", err)
+	}
+	updateEndpointChangeStatus(&conditions, c)
+	if findStatusCondition(conditions, endpointChangeConditionType) != nil {
+		t.Errorf("the condition should be removed once the change finished")
+	}
+}
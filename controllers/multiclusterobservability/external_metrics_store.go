@@ -0,0 +1,258 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	obsv1alpha1 "github.com/open-cluster-management/observatorium-operator/api/v1alpha1"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	externalMetricsStoreTokenKey    = "token"
+	externalMetricsStoreUsernameKey = "username"
+	externalMetricsStorePasswordKey = "password"
+	externalMetricsStoreCAKey       = "ca.crt"
+
+	externalMetricsStoreConditionType = "ExternalMetricsStoreReady"
+)
+
+// externalMetricsStoreProbe caches the result of the last probe of the external metrics store, the
+// ready endpoints are only probed again when the spec or the credentials change
+var externalMetricsStoreProbe = struct {
+	key       string
+	condition *mcoshared.Condition
+}{}
+
+// probeExternalMetricsStore checks that the external metrics store serves the query API and accepts
+// the remote writes with the credentials and the headers, it is a var so that the tests can stub it
+var probeExternalMetricsStore = func(mco *mcov1beta2.MultiClusterObservability,
+	secret *corev1.Secret) error {
+	httpClient, err := newExternalMetricsStoreClient(secret)
+	if err != nil {
+		return err
+	}
+	store := mco.Spec.ExternalMetricsStore
+	queryURL := strings.TrimSuffix(store.QueryURL, "/") + "/api/v1/query?query=vector(1)"
+	resp, err := doExternalMetricsStoreRequest(httpClient, http.MethodGet, queryURL, mco, secret)
+	if err != nil {
+		return fmt.Errorf("failed to query the external metrics store: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("the query API of the external metrics store returns %s", resp.Status)
+	}
+	// an empty remote write is rejected as a bad request by the stores which serve the remote write
+	// API, only a missing API or the rejected credentials make the collectors fail
+	resp, err = doExternalMetricsStoreRequest(httpClient, http.MethodPost, store.RemoteWriteURL, mco, secret)
+	if err != nil {
+		return fmt.Errorf("failed to remote write to the external metrics store: %v", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("the remote write API of the external metrics store returns %s", resp.Status)
+	}
+	return nil
+}
+
+// newExternalMetricsStoreClient returns the http client which trusts the CA of the external
+// metrics store if the credentials contain it
+func newExternalMetricsStoreClient(secret *corev1.Secret) (*http.Client, error) {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	if secret == nil || len(secret.Data[externalMetricsStoreCAKey]) == 0 {
+		return httpClient, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(secret.Data[externalMetricsStoreCAKey]) {
+		return nil, fmt.Errorf("failed to parse the CA of the external metrics store")
+	}
+	httpClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}
+	return httpClient, nil
+}
+
+func doExternalMetricsStoreRequest(httpClient *http.Client, method string, url string,
+	mco *mcov1beta2.MultiClusterObservability, secret *corev1.Secret) (*http.Response, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(nil))
+	if err != nil {
+		return nil, err
+	}
+	if secret != nil {
+		if token := string(secret.Data[externalMetricsStoreTokenKey]); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		} else if username := string(secret.Data[externalMetricsStoreUsernameKey]); username != "" {
+			req.SetBasicAuth(username, string(secret.Data[externalMetricsStorePasswordKey]))
+		}
+	}
+	if mcoconfig.IsScopeOrgIDRequired(mco) {
+		req.Header.Set(mcoconfig.ScopeOrgIDHeader, mcoconfig.GetExternalMetricsStoreTenantID(mco, ""))
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// updateExternalMetricsStoreStatus shows whether the external metrics store is reachable with the
+// credentials and serves the query API and the remote write API
+func updateExternalMetricsStoreStatus(conditions *[]mcoshared.Condition, c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) {
+	if !mcoconfig.IsExternalMetricsStoreEnabled(mco) {
+		removeStatusCondition(conditions, externalMetricsStoreConditionType)
+		return
+	}
+	secret, err := mcoconfig.GetExternalMetricsStoreCredentials(c, mco)
+	if err != nil {
+		setStatusCondition(conditions, mcoshared.Condition{
+			Type:    externalMetricsStoreConditionType,
+			Status:  metav1.ConditionFalse,
+			Reason:  "CredentialsNotFound",
+			Message: fmt.Sprintf("Failed to get the credentials of the external metrics store: %v", err),
+		})
+		return
+	}
+	key := fmt.Sprintf("%+v", *mco.Spec.ExternalMetricsStore)
+	if secret != nil {
+		key += "/" + secret.ResourceVersion
+	}
+	if externalMetricsStoreProbe.key != key || externalMetricsStoreProbe.condition == nil ||
+		externalMetricsStoreProbe.condition.Status != metav1.ConditionTrue {
+		condition := &mcoshared.Condition{
+			Type:    externalMetricsStoreConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  "EndpointsReady",
+			Message: "The external metrics store serves the query API and the remote write API",
+		}
+		err = probeExternalMetricsStore(mco, secret)
+		if err != nil {
+			log.Error(err, "Failed to probe the external metrics store")
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "EndpointsNotReady"
+			condition.Message = err.Error()
+		}
+		externalMetricsStoreProbe.key = key
+		externalMetricsStoreProbe.condition = condition
+	}
+	setStatusCondition(conditions, *externalMetricsStoreProbe.condition)
+}
+
+// deleteStorageStack removes the observatorium CR and the route of the observatorium api when the
+// metrics are stored in the external metrics store, the observatorium operator removes the thanos
+// components with the CR
+func deleteStorageStack(c client.Client, mco *mcov1beta2.MultiClusterObservability) error {
+	namespace := mcoconfig.GetDefaultNamespace()
+	return deleteResources(c, []client.Object{
+		&obsv1alpha1.Observatorium{
+			ObjectMeta: metav1.ObjectMeta{Name: mco.Name, Namespace: namespace},
+		},
+		&routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Name: obsAPIGateway, Namespace: namespace},
+		},
+	})
+}
+
+// newExternalMetricsStoreDatasource returns the default grafana datasource which queries the
+// external metrics store with its credentials. It keeps the name of the datasource of the hub so
+// that the dashboards which refer to the datasource by name keep working.
+func newExternalMetricsStoreDatasource(c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) (*GrafanaDatasource, error) {
+	datasource := &GrafanaDatasource{
+		Name:           "Observatorium",
+		Type:           "prometheus",
+		Access:         "proxy",
+		IsDefault:      true,
+		URL:            mco.Spec.ExternalMetricsStore.QueryURL,
+		JSONData:       &JsonData{},
+		SecureJSONData: &SecureJsonData{},
+	}
+	secret, err := mcoconfig.GetExternalMetricsStoreCredentials(c, mco)
+	if err != nil {
+		log.Error(err, "Failed to get the credentials of the external metrics store")
+		return nil, err
+	}
+	setDatasourceCredentials(datasource, secret)
+	return datasource, nil
+}
+
+// setDatasourceCredentials sets the bearer token or the basic auth, and the CA of the server
+// certificate in the secret on the datasource
+func setDatasourceCredentials(datasource *GrafanaDatasource, secret *corev1.Secret) {
+	if secret == nil {
+		return
+	}
+	if token := string(secret.Data[externalMetricsStoreTokenKey]); token != "" {
+		datasource.JSONData.HTTPHeaderName1 = "Authorization"
+		datasource.SecureJSONData.HTTPHeaderValue1 = "Bearer " + token
+	} else if username := string(secret.Data[externalMetricsStoreUsernameKey]); username != "" {
+		datasource.BasicAuth = true
+		datasource.BasicAuthUser = username
+		datasource.BasicAuthPassword = string(secret.Data[externalMetricsStorePasswordKey])
+	}
+	if ca := string(secret.Data[externalMetricsStoreCAKey]); ca != "" {
+		datasource.JSONData.TLSAuthCA = true
+		datasource.SecureJSONData.TLSCACert = ca
+	}
+}
+
+// newExternalMetricsStoreDatasources returns the datasources of the external metrics store. Cortex
+// and Mimir scope the queries with the X-Scope-OrgID header, so each tenant gets its own datasource
+// with the tenant ID, and the default datasource queries all the tenants with the tenant federation.
+func newExternalMetricsStoreDatasources(c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) ([]*GrafanaDatasource, error) {
+	datasource, err := newExternalMetricsStoreDatasource(c, mco)
+	if err != nil {
+		return nil, err
+	}
+	if !mcoconfig.IsScopeOrgIDRequired(mco) {
+		return []*GrafanaDatasource{datasource}, nil
+	}
+
+	tenantIDs := []string{mcoconfig.GetExternalMetricsStoreTenantID(mco, "")}
+	for _, tenant := range mco.Spec.ClusterSetTenants {
+		tenantIDs = append(tenantIDs, mcoconfig.GetExternalMetricsStoreTenantID(mco, tenant.Name))
+	}
+	datasources := []*GrafanaDatasource{
+		withHTTPHeader(datasource, mcoconfig.ScopeOrgIDHeader, strings.Join(tenantIDs, "|")),
+	}
+	for _, tenant := range mco.Spec.ClusterSetTenants {
+		tenantDatasource := withHTTPHeader(datasource, mcoconfig.ScopeOrgIDHeader,
+			mcoconfig.GetExternalMetricsStoreTenantID(mco, tenant.Name))
+		tenantDatasource.Name = datasource.Name + "-" + tenant.Name
+		tenantDatasource.IsDefault = false
+		datasources = append(datasources, tenantDatasource)
+	}
+	return datasources, nil
+}
+
+// withHTTPHeader returns a copy of the datasource which sends the header in addition to the
+// header of the bearer token, grafana reads the numbered headers until the first missing one
+func withHTTPHeader(datasource *GrafanaDatasource, name string, value string) *GrafanaDatasource {
+	ds := *datasource
+	jsonData := *datasource.JSONData
+	secureJSONData := *datasource.SecureJSONData
+	if jsonData.HTTPHeaderName1 == "" {
+		jsonData.HTTPHeaderName1 = name
+		secureJSONData.HTTPHeaderValue1 = value
+	} else {
+		jsonData.HTTPHeaderName2 = name
+		secureJSONData.HTTPHeaderValue2 = value
+	}
+	ds.JSONData = &jsonData
+	ds.SecureJSONData = &secureJSONData
+	return &ds
+}
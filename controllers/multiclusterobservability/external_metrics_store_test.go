@@ -0,0 +1,191 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	obsv1alpha1 "github.com/open-cluster-management/observatorium-operator/api/v1alpha1"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestExternalMetricsStore(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	obsv1alpha1.AddToScheme(s)
+	routev1.AddToScheme(s)
+
+	namespace := mcoconfig.GetDefaultNamespace()
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			ExternalMetricsStore: &mcov1beta2.ExternalMetricsStoreSpec{
+				RemoteWriteURL:    "https://mimir.example.com/api/v1/push",
+				QueryURL:          "https://mimir.example.com/prometheus",
+				CredentialsSecret: "mimir",
+			},
+		},
+	}
+	observatorium := &obsv1alpha1.Observatorium{
+		ObjectMeta: metav1.ObjectMeta{Name: mco.Name, Namespace: namespace},
+	}
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mimir", Namespace: namespace},
+		Data: map[string][]byte{
+			"username": []byte("mco"),
+			"password": []byte("secret"),
+			"ca.crt":   []byte("test-ca"),
+		},
+	}
+	c := fake.NewFakeClient(observatorium, credentials)
+
+	// the observatorium on the hub is removed
+	_, err := GenerateObservatoriumCR(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the observatorium CR: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: mco.Name, Namespace: namespace},
+		&obsv1alpha1.Observatorium{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The observatorium CR should be deleted with the external metrics store: (%v)", err)
+	}
+
+	datasource, err := newExternalMetricsStoreDatasource(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to create the datasource of the external metrics store: (%v)", err)
+	}
+	if datasource.URL != mco.Spec.ExternalMetricsStore.QueryURL || !datasource.IsDefault ||
+		!datasource.BasicAuth || datasource.BasicAuthUser != "mco" || datasource.BasicAuthPassword != "secret" ||
+		!datasource.JSONData.TLSAuthCA || datasource.SecureJSONData.TLSCACert != "test-ca" {
+		t.Fatalf("Wrong datasource of the external metrics store: %v", datasource)
+	}
+
+	credentials.Data = map[string][]byte{"token": []byte("test-token")}
+	err = c.Update(context.TODO(), credentials)
+	if err != nil {
+		t.Fatalf("Failed to update the credentials: (%v)", err)
+	}
+	datasource, err = newExternalMetricsStoreDatasource(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to create the datasource of the external metrics store: (%v)", err)
+	}
+	if datasource.BasicAuth || datasource.JSONData.HTTPHeaderName1 != "Authorization" ||
+		datasource.SecureJSONData.HTTPHeaderValue1 != "Bearer test-token" {
+		t.Fatalf("The datasource should use the bearer token: %v", datasource)
+	}
+}
+
+func TestExternalMetricsStoreDatasourcesWithMimir(t *testing.T) {
+	namespace := mcoconfig.GetDefaultNamespace()
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			ClusterSetTenants: []mcov1beta2.ClusterSetTenant{
+				{Name: "team-a", ClusterSets: []string{"team-a"}},
+			},
+			ExternalMetricsStore: &mcov1beta2.ExternalMetricsStoreSpec{
+				Type:              mcoconfig.ExternalMetricsStoreMimir,
+				RemoteWriteURL:    "https://mimir.example.com/api/v1/push",
+				QueryURL:          "https://mimir.example.com/prometheus",
+				CredentialsSecret: "mimir",
+			},
+		},
+	}
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mimir", Namespace: namespace},
+		Data:       map[string][]byte{"token": []byte("test-token")},
+	}
+	c := fake.NewFakeClient(credentials)
+
+	datasources, err := newExternalMetricsStoreDatasources(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to create the datasources of the external metrics store: (%v)", err)
+	}
+	if len(datasources) != 2 {
+		t.Fatalf("There should be the default datasource and the datasource of the tenant: %v", datasources)
+	}
+	ds := datasources[0]
+	if !ds.IsDefault || ds.JSONData.HTTPHeaderName1 != "Authorization" ||
+		ds.JSONData.HTTPHeaderName2 != mcoconfig.ScopeOrgIDHeader ||
+		ds.SecureJSONData.HTTPHeaderValue2 != "anonymous|team-a" {
+		t.Fatalf("The default datasource should query all the tenants: %v %v", ds.JSONData, ds.SecureJSONData)
+	}
+	ds = datasources[1]
+	if ds.IsDefault || ds.Name != "Observatorium-team-a" || ds.SecureJSONData.HTTPHeaderValue2 != "team-a" ||
+		ds.SecureJSONData.HTTPHeaderValue1 != "Bearer test-token" {
+		t.Fatalf("Wrong datasource of the tenant: %v %v", ds, ds.SecureJSONData)
+	}
+}
+
+func TestUpdateExternalMetricsStoreStatus(t *testing.T) {
+	orgIDs := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgIDs = append(orgIDs, r.Header.Get(mcoconfig.ScopeOrgIDHeader))
+		switch r.URL.Path {
+		case "/prometheus/api/v1/query":
+			w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1,"1"]}}`))
+		case "/api/v1/push":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	externalMetricsStoreProbe.key = ""
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			ExternalMetricsStore: &mcov1beta2.ExternalMetricsStoreSpec{
+				Type:            mcoconfig.ExternalMetricsStoreCortex,
+				RemoteWriteURL:  server.URL + "/api/v1/push",
+				QueryURL:        server.URL + "/prometheus",
+				DefaultTenantID: "fleet",
+			},
+		},
+	}
+	c := fake.NewFakeClient()
+	conditions := []mcoshared.Condition{}
+	updateExternalMetricsStoreStatus(&conditions, c, mco)
+	condition := findStatusCondition(conditions, externalMetricsStoreConditionType)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("The external metrics store should be ready: %v", condition)
+	}
+	if len(orgIDs) != 2 || orgIDs[0] != "fleet" || orgIDs[1] != "fleet" {
+		t.Fatalf("The probes should send the default tenant ID: %v", orgIDs)
+	}
+
+	// the ready endpoints are not probed again
+	updateExternalMetricsStoreStatus(&conditions, c, mco)
+	if len(orgIDs) != 2 {
+		t.Fatalf("The ready endpoints should not be probed again: %v", orgIDs)
+	}
+
+	mco.Spec.ExternalMetricsStore.RemoteWriteURL = server.URL + "/api/prom/push"
+	updateExternalMetricsStoreStatus(&conditions, c, mco)
+	condition = findStatusCondition(conditions, externalMetricsStoreConditionType)
+	if condition == nil || condition.Status != metav1.ConditionFalse {
+		t.Fatalf("The missing remote write API should be reported: %v", condition)
+	}
+
+	mco.Spec.ExternalMetricsStore = nil
+	updateExternalMetricsStoreStatus(&conditions, c, mco)
+	if findStatusCondition(conditions, externalMetricsStoreConditionType) != nil {
+		t.Fatalf("The condition should be removed without the external metrics store")
+	}
+}
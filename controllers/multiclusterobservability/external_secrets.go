@@ -0,0 +1,181 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	crdClientSet "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+// externalSecretSyncInterval is the interval to check the secrets which are not synced yet
+const externalSecretSyncInterval = 10 * time.Second
+
+// GenerateExternalSecrets creates the ExternalSecrets of the observability secrets which are
+// synced from the external secret manager and waits for the External Secrets Operator to create
+// the secrets, the ExternalSecrets which are removed from the spec are deleted
+func GenerateExternalSecrets(c client.Client, crdClient crdClientSet.Interface, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	crdExists, err := util.CheckCRDExist(crdClient, mcoconfig.ExternalSecretCrdName)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	if !crdExists {
+		if mcoconfig.IsExternalSecretsEnabled(mco) {
+			return &ctrl.Result{}, fmt.Errorf("the CRD %s is not found, install the External Secrets "+
+				"Operator to sync the secrets from the external secret manager", mcoconfig.ExternalSecretCrdName)
+		}
+		return nil, nil
+	}
+
+	expected := map[string]bool{}
+	if mcoconfig.IsExternalSecretsEnabled(mco) {
+		for _, secret := range mco.Spec.ExternalSecrets.Secrets {
+			es := newExternalSecret(mco, secret)
+			if err = controllerutil.SetControllerReference(mco, es, scheme); err != nil {
+				return &ctrl.Result{}, err
+			}
+			if err = createOrUpdateExternalSecret(c, es); err != nil {
+				return &ctrl.Result{}, err
+			}
+			expected[es.GetNamespace()+"/"+es.GetName()] = true
+		}
+	}
+
+	// delete the ExternalSecrets which are removed from the spec, the synced secrets are
+	// deleted with them by the External Secrets Operator
+	esList := &unstructured.UnstructuredList{}
+	esList.SetAPIVersion(mcoconfig.ExternalSecretAPIVersion)
+	esList.SetKind(mcoconfig.ExternalSecretListKind)
+	err = c.List(context.TODO(), esList, client.MatchingLabels{mcoconfig.ExternalSecretOwnerLabel: mco.GetName()})
+	if err != nil {
+		log.Error(err, "Failed to list the ExternalSecrets")
+		return &ctrl.Result{}, err
+	}
+	for index, es := range esList.Items {
+		if expected[es.GetNamespace()+"/"+es.GetName()] {
+			continue
+		}
+		log.Info("Deleting the ExternalSecret", "namespace", es.GetNamespace(), "name", es.GetName())
+		err = c.Delete(context.TODO(), &esList.Items[index])
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete the ExternalSecret", "name", es.GetName())
+			return &ctrl.Result{}, err
+		}
+	}
+
+	if !mcoconfig.IsExternalSecretsEnabled(mco) {
+		return nil, nil
+	}
+	// wait for the secrets, the other resources are not deployed without them
+	for _, secret := range mco.Spec.ExternalSecrets.Secrets {
+		namespace := mcoconfig.GetExternalSecretNamespace(secret)
+		err = c.Get(context.TODO(), types.NamespacedName{Name: secret.Name, Namespace: namespace}, &corev1.Secret{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				log.Info("Waiting for the secret to be synced from the external secret manager",
+					"namespace", namespace, "name", secret.Name)
+				return &ctrl.Result{RequeueAfter: externalSecretSyncInterval}, nil
+			}
+			log.Error(err, "Failed to get the synced secret", "name", secret.Name)
+			return &ctrl.Result{}, err
+		}
+	}
+	return nil, nil
+}
+
+// newExternalSecret returns the ExternalSecret which extracts all the properties of the remote key
+// as the keys of the secret
+func newExternalSecret(mco *mcov1beta2.MultiClusterObservability,
+	secret mcov1beta2.ExternalSecret) *unstructured.Unstructured {
+	spec := mco.Spec.ExternalSecrets
+	storeKind := spec.SecretStoreRef.Kind
+	if storeKind == "" {
+		storeKind = "ClusterSecretStore"
+	}
+	refreshInterval := spec.RefreshInterval
+	if refreshInterval == "" {
+		refreshInterval = "1h"
+	}
+	target := map[string]interface{}{
+		"name":           secret.Name,
+		"creationPolicy": "Owner",
+	}
+	if secret.Type != "" {
+		target["template"] = map[string]interface{}{
+			"type": string(secret.Type),
+		}
+	}
+
+	es := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"refreshInterval": refreshInterval,
+				"secretStoreRef": map[string]interface{}{
+					"name": spec.SecretStoreRef.Name,
+					"kind": storeKind,
+				},
+				"target": target,
+				"dataFrom": []interface{}{
+					map[string]interface{}{
+						"extract": map[string]interface{}{
+							"key": secret.RemoteKey,
+						},
+					},
+				},
+			},
+		},
+	}
+	es.SetAPIVersion(mcoconfig.ExternalSecretAPIVersion)
+	es.SetKind(mcoconfig.ExternalSecretKind)
+	es.SetName(secret.Name)
+	es.SetNamespace(mcoconfig.GetExternalSecretNamespace(secret))
+	es.SetLabels(map[string]string{mcoconfig.ExternalSecretOwnerLabel: mco.GetName()})
+	return es
+}
+
+func createOrUpdateExternalSecret(c client.Client, es *unstructured.Unstructured) error {
+	found := &unstructured.Unstructured{}
+	found.SetAPIVersion(es.GetAPIVersion())
+	found.SetKind(es.GetKind())
+	err := c.Get(context.TODO(), types.NamespacedName{Name: es.GetName(), Namespace: es.GetNamespace()}, found)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get the ExternalSecret", "name", es.GetName())
+			return err
+		}
+		log.Info("Creating the ExternalSecret", "namespace", es.GetNamespace(), "name", es.GetName())
+		err = c.Create(context.TODO(), es)
+		if err != nil {
+			log.Error(err, "Failed to create the ExternalSecret", "name", es.GetName())
+		}
+		return err
+	}
+
+	if reflect.DeepEqual(found.Object["spec"], es.Object["spec"]) {
+		return nil
+	}
+	log.Info("Updating the ExternalSecret", "namespace", es.GetNamespace(), "name", es.GetName())
+	found.Object["spec"] = es.Object["spec"]
+	err = c.Update(context.TODO(), found)
+	if err != nil {
+		log.Error(err, "Failed to update the ExternalSecret", "name", es.GetName())
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	fakecrdclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestGenerateExternalSecrets(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	gv := schema.GroupVersion{Group: mcoconfig.ExternalSecretGroup, Version: "v1beta1"}
+	s.AddKnownTypeWithName(gv.WithKind(mcoconfig.ExternalSecretKind), &unstructured.Unstructured{})
+	s.AddKnownTypeWithName(gv.WithKind(mcoconfig.ExternalSecretListKind), &unstructured.UnstructuredList{})
+
+	namespace := mcoconfig.GetDefaultNamespace()
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			ExternalSecrets: &mcov1beta2.ExternalSecretsSpec{
+				SecretStoreRef: mcov1beta2.ExternalSecretStoreRef{Name: "vault-backend"},
+				Secrets: []mcov1beta2.ExternalSecret{
+					{
+						Name:      "pull-secret",
+						RemoteKey: "secret/data/observability/pull-secret",
+						Type:      corev1.SecretTypeDockerConfigJson,
+					},
+				},
+			},
+		},
+	}
+	c := fake.NewFakeClient()
+
+	_, err := GenerateExternalSecrets(c, fakecrdclient.NewSimpleClientset(), s, mco)
+	if err == nil {
+		t.Fatalf("the ExternalSecrets should not be generated without the External Secrets Operator")
+	}
+
+	crdClient := fakecrdclient.NewSimpleClientset(&apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.ExternalSecretCrdName},
+	})
+	result, err := GenerateExternalSecrets(c, crdClient, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the ExternalSecrets: (%v)", err)
+	}
+	if result == nil || result.RequeueAfter != externalSecretSyncInterval {
+		t.Fatalf("the reconcile should wait for the secret to be synced: %v", result)
+	}
+	es := &unstructured.Unstructured{}
+	es.SetAPIVersion(mcoconfig.ExternalSecretAPIVersion)
+	es.SetKind(mcoconfig.ExternalSecretKind)
+	err = c.Get(context.TODO(), types.NamespacedName{Name: "pull-secret", Namespace: namespace}, es)
+	if err != nil {
+		t.Fatalf("Failed to get the ExternalSecret: (%v)", err)
+	}
+	storeKind, _, _ := unstructured.NestedString(es.Object, "spec", "secretStoreRef", "kind")
+	secretType, _, _ := unstructured.NestedString(es.Object, "spec", "target", "template", "type")
+	if storeKind != "ClusterSecretStore" || secretType != string(corev1.SecretTypeDockerConfigJson) {
+		t.Errorf("the ExternalSecret is not expected: %v", es.Object["spec"])
+	}
+
+	err = c.Create(context.TODO(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: namespace},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create the synced secret: (%v)", err)
+	}
+	result, err = GenerateExternalSecrets(c, crdClient, s, mco)
+	if err != nil || result != nil {
+		t.Fatalf("the reconcile should continue once the secret is synced: %v, %v", result, err)
+	}
+
+	mco.Spec.ExternalSecrets = nil
+	_, err = GenerateExternalSecrets(c, crdClient, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to delete the ExternalSecrets: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: "pull-secret", Namespace: namespace}, es)
+	if err == nil {
+		t.Errorf("the ExternalSecret should be deleted once it is removed from the spec")
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// federatedHubSecretNames are the secrets of the credentials of the other hubs, the datasources are
+// generated again once they are rotated
+var federatedHubSecretNames = map[string]bool{}
+
+// newFederatedHubDatasources returns a grafana datasource for the query endpoint of each of the other
+// hubs, so that the dashboards can show the fleets of several hubs side by side. The datasources of
+// the hubs whose credentials are not found are skipped so that they do not block the other datasources.
+func newFederatedHubDatasources(c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) ([]*GrafanaDatasource, error) {
+	if err := mcoconfig.ValidateFederatedHubs(mco); err != nil {
+		log.Error(err, "Invalid federated hubs, skip their datasources")
+		return nil, nil
+	}
+	secretNames := map[string]bool{}
+	datasources := []*GrafanaDatasource{}
+	for _, hub := range mco.Spec.FederatedHubs {
+		if hub.CredentialsSecret != "" {
+			secretNames[hub.CredentialsSecret] = true
+		}
+		datasource := &GrafanaDatasource{
+			Name:           mcoconfig.FederatedHubDatasourcePrefix + hub.Name,
+			Type:           "prometheus",
+			Access:         "proxy",
+			URL:            hub.QueryURL,
+			JSONData:       &JsonData{},
+			SecureJSONData: &SecureJsonData{},
+		}
+		secret, err := mcoconfig.GetFederatedHubCredentials(c, hub)
+		if errors.IsNotFound(err) {
+			log.Info("The credentials of the federated hub are not found, skip its datasource",
+				"hub", hub.Name, "secret", hub.CredentialsSecret)
+			continue
+		}
+		if err != nil {
+			log.Error(err, "Failed to get the credentials of the federated hub", "hub", hub.Name)
+			return nil, err
+		}
+		setDatasourceCredentials(datasource, secret)
+		datasources = append(datasources, datasource)
+	}
+	federatedHubSecretNames = secretNames
+	return datasources, nil
+}
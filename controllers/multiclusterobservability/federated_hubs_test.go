@@ -0,0 +1,61 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestNewFederatedHubDatasources(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-east-credentials", Namespace: config.GetDefaultNamespace()},
+		Data:       map[string][]byte{"token": []byte("east-token"), "ca.crt": []byte("east-ca")},
+	}
+	c := fake.NewFakeClient(secret)
+	mco := &mcov1beta2.MultiClusterObservability{
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			FederatedHubs: []mcov1beta2.FederatedHubSpec{
+				{Name: "east", QueryURL: "https://rbac-query-proxy.apps.east.example.com",
+					CredentialsSecret: "hub-east-credentials"},
+				{Name: "west", QueryURL: "https://rbac-query-proxy.apps.west.example.com",
+					CredentialsSecret: "hub-west-credentials"},
+				{Name: "south", QueryURL: "http://query.south.example.com"},
+			},
+		},
+	}
+
+	datasources, err := newFederatedHubDatasources(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the datasources of the federated hubs: (%v)", err)
+	}
+	// the datasource of the hub whose credentials are not found is skipped
+	if len(datasources) != 2 {
+		t.Fatalf("Wrong number of datasources: %v", len(datasources))
+	}
+	ds := datasources[0]
+	if ds.Name != "Observatorium-hub-east" || ds.IsDefault || ds.URL != "https://rbac-query-proxy.apps.east.example.com" ||
+		ds.JSONData.HTTPHeaderName1 != "Authorization" || ds.SecureJSONData.HTTPHeaderValue1 != "Bearer east-token" ||
+		!ds.JSONData.TLSAuthCA || ds.SecureJSONData.TLSCACert != "east-ca" {
+		t.Errorf("Wrong datasource of the federated hub: %v", ds)
+	}
+	if datasources[1].Name != "Observatorium-hub-south" || datasources[1].JSONData.HTTPHeaderName1 != "" {
+		t.Errorf("Wrong datasource of the federated hub without the credentials: %v", datasources[1])
+	}
+	if !federatedHubSecretNames["hub-east-credentials"] || !federatedHubSecretNames["hub-west-credentials"] {
+		t.Errorf("The secrets of the credentials should be watched: %v", federatedHubSecretNames)
+	}
+
+	mco.Spec.FederatedHubs = append(mco.Spec.FederatedHubs,
+		mcov1beta2.FederatedHubSpec{Name: "east", QueryURL: "https://query.example.com"})
+	if err := config.ValidateFederatedHubs(mco); err == nil {
+		t.Errorf("The duplicated names of the federated hubs should be invalid")
+	}
+}
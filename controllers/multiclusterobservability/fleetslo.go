@@ -0,0 +1,253 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	sloWindowPlaceholder = "{{window}}"
+	defaultSLOWindow     = "30d"
+)
+
+// the windows of the error ratio which are used by the multi-window burn rate alerts
+var sloBurnRateWindows = []string{"5m", "30m", "1h", "2h", "6h", "1d", "3d"}
+
+// the multi-window burn rate alerts: the alert fires when the burn rate of both
+// the long and the short window is over the factor
+var sloBurnRateAlerts = []struct {
+	severity    string
+	longWindow  string
+	shortWindow string
+	factor      float64
+	forDuration string
+}{
+	{"critical", "1h", "5m", 14.4, "2m"},
+	{"critical", "6h", "30m", 6, "15m"},
+	{"warning", "1d", "2h", 3, "1h"},
+	{"warning", "3d", "6h", 1, "3h"},
+}
+
+// RuleGroups is the rule file of the thanos ruler
+type RuleGroups struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup is a group of the recording and alerting rules
+type RuleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is a recording or alerting rule
+type Rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// GenerateFleetSLORules translates all the FleetSLOs into the rules configmap of the thanos ruler,
+// and records in the status of each FleetSLO whether its rules are generated
+func GenerateFleetSLORules(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	sloList := &mcov1beta2.FleetSLOList{}
+	err := c.List(context.TODO(), sloList)
+	if err != nil && !meta.IsNoMatchError(err) {
+		log.Error(err, "Failed to list the FleetSLOs")
+		return &ctrl.Result{}, err
+	}
+
+	ruleGroups := RuleGroups{Groups: []RuleGroup{}}
+	for idx := range sloList.Items {
+		slo := &sloList.Items[idx]
+		group, err := newFleetSLORuleGroup(slo)
+		condition := mcoshared.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionTrue,
+			Reason:  "RulesGenerated",
+			Message: "The rules of the service level are generated",
+		}
+		if err != nil {
+			log.Info("Invalid FleetSLO, skip it", "name", slo.Name, "error", err.Error())
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "InvalidSpec"
+			condition.Message = err.Error()
+		} else {
+			ruleGroups.Groups = append(ruleGroups.Groups, *group)
+		}
+		err = updateFleetSLOStatus(c, slo, condition)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+
+	return generateRulesConfigMap(c, scheme, mco, mcoconfig.AlertRuleSLOConfigMapName,
+		mcoconfig.AlertRuleSLOFileKey, ruleGroups)
+}
+
+// generateRulesConfigMap creates or updates the rules configmap of the thanos ruler
+func generateRulesConfigMap(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability,
+	name string, key string, ruleGroups RuleGroups) (*ctrl.Result, error) {
+	data, err := yaml.Marshal(ruleGroups)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+		},
+		Data: map[string]string{
+			key: string(data),
+		},
+	}
+	// Set MultiClusterObservability instance as the owner and controller
+	if err = controllerutil.SetControllerReference(mco, cm, scheme); err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	found := &corev1.ConfigMap{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating the rules configmap", "name", cm.Name)
+		err = c.Create(context.TODO(), cm)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+		return nil, nil
+	} else if err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	if !reflect.DeepEqual(found.Data, cm.Data) {
+		log.Info("Updating the rules configmap", "name", cm.Name)
+		found.Data = cm.Data
+		err = c.Update(context.TODO(), found)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+	return nil, nil
+}
+
+// newFleetSLORuleGroup returns the rule group of the FleetSLO: the error ratio and the error
+// budget burn rate of each window are recorded per cluster, plus the remaining error budget
+// over the window of the objective and the multi-window burn rate alerts
+func newFleetSLORuleGroup(slo *mcov1beta2.FleetSLO) (*RuleGroup, error) {
+	objective, err := strconv.ParseFloat(slo.Spec.Objective, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid objective %s: %v", slo.Spec.Objective, err)
+	}
+	if objective <= 0 || objective >= 100 {
+		return nil, fmt.Errorf("the objective %s must be between 0 and 100", slo.Spec.Objective)
+	}
+	if slo.Spec.SLI.ErrorQuery == "" || slo.Spec.SLI.TotalQuery == "" {
+		return nil, fmt.Errorf("both the error query and the total query are required")
+	}
+	errorBudget := strconv.FormatFloat((100-objective)/100, 'g', 6, 64)
+	sloWindow := slo.Spec.Window
+	if sloWindow == "" {
+		sloWindow = defaultSLOWindow
+	}
+
+	labels := map[string]string{"slo": slo.Name}
+	selector := fmt.Sprintf(`{slo="%s"}`, slo.Name)
+	windows := sloBurnRateWindows
+	if !contains(windows, sloWindow) {
+		windows = append(append([]string{}, windows...), sloWindow)
+	}
+	group := &RuleGroup{Name: "fleet-slo-" + slo.Name}
+	for _, window := range windows {
+		group.Rules = append(group.Rules, Rule{
+			Record: "slo:sli_error:ratio_rate" + window,
+			Expr: fmt.Sprintf("sum by (%s) (%s) / sum by (%s) (%s)",
+				mcoconfig.GetClusterNameLabelKey(),
+				strings.ReplaceAll(slo.Spec.SLI.ErrorQuery, sloWindowPlaceholder, window),
+				mcoconfig.GetClusterNameLabelKey(),
+				strings.ReplaceAll(slo.Spec.SLI.TotalQuery, sloWindowPlaceholder, window)),
+			Labels: labels,
+		}, Rule{
+			Record: "slo:error_budget_burn_rate:" + window,
+			Expr:   fmt.Sprintf("slo:sli_error:ratio_rate%s%s / %s", window, selector, errorBudget),
+			Labels: labels,
+		})
+	}
+	group.Rules = append(group.Rules, Rule{
+		Record: "slo:error_budget_remaining:ratio",
+		Expr:   fmt.Sprintf("1 - slo:sli_error:ratio_rate%s%s / %s", sloWindow, selector, errorBudget),
+		Labels: labels,
+	})
+
+	if slo.Spec.DisableAlerts {
+		return group, nil
+	}
+	for _, alert := range sloBurnRateAlerts {
+		factor := strconv.FormatFloat(alert.factor, 'f', -1, 64)
+		group.Rules = append(group.Rules, Rule{
+			Alert: "FleetSLOErrorBudgetBurn",
+			Expr: fmt.Sprintf("slo:error_budget_burn_rate:%s%s > %s and slo:error_budget_burn_rate:%s%s > %s",
+				alert.longWindow, selector, factor, alert.shortWindow, selector, factor),
+			For: alert.forDuration,
+			Labels: map[string]string{
+				"slo":      slo.Name,
+				"severity": alert.severity,
+				"window":   alert.longWindow,
+			},
+			Annotations: map[string]string{
+				"summary": "The error budget of the service level is burning too fast.",
+				"description": fmt.Sprintf("The error budget of %s in cluster {{ $labels.cluster }} is burning "+
+					"%s times faster than allowed over the last %s.", slo.Name, factor, alert.longWindow),
+			},
+		})
+	}
+	return group, nil
+}
+
+func updateFleetSLOStatus(c client.Client, slo *mcov1beta2.FleetSLO, condition mcoshared.Condition) error {
+	conditions := append([]mcoshared.Condition{}, slo.Status.Conditions...)
+	setStatusCondition(&conditions, condition)
+	if reflect.DeepEqual(conditions, slo.Status.Conditions) {
+		return nil
+	}
+	slo.Status.Conditions = conditions
+	err := c.Status().Update(context.TODO(), slo)
+	if err != nil && !errors.IsConflict(err) {
+		log.Error(err, "Failed to update the status of FleetSLO", "name", slo.Name)
+		return err
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
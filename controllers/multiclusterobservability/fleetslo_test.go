@@ -0,0 +1,113 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func newTestFleetSLO(name string, objective string) *mcov1beta2.FleetSLO {
+	return &mcov1beta2.FleetSLO{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: mcov1beta2.FleetSLOSpec{
+			Objective: objective,
+			Window:    "30d",
+			SLI: mcov1beta2.ServiceLevelIndicator{
+				ErrorQuery: `rate(apiserver_request_total{code=~"5.."}[{{window}}])`,
+				TotalQuery: `rate(apiserver_request_total[{{window}}])`,
+			},
+		},
+	}
+}
+
+func TestNewFleetSLORuleGroup(t *testing.T) {
+	group, err := newFleetSLORuleGroup(newTestFleetSLO("apiserver", "99.9"))
+	if err != nil {
+		t.Fatalf("Failed to generate the rule group: (%v)", err)
+	}
+	records := map[string]string{}
+	alerts := 0
+	for _, rule := range group.Rules {
+		if rule.Record != "" {
+			records[rule.Record] = rule.Expr
+		} else {
+			alerts++
+		}
+	}
+	expr := records["slo:sli_error:ratio_rate30d"]
+	if !strings.Contains(expr, `sum by (cluster) (rate(apiserver_request_total{code=~"5.."}[30d]))`) {
+		t.Errorf("Wrong error ratio over the slo window: %s", expr)
+	}
+	if !strings.HasSuffix(records["slo:error_budget_burn_rate:1h"], "/ 0.001") {
+		t.Errorf("Wrong burn rate: %s", records["slo:error_budget_burn_rate:1h"])
+	}
+	if _, ok := records["slo:error_budget_remaining:ratio"]; !ok {
+		t.Errorf("The remaining error budget is not recorded")
+	}
+	if alerts != len(sloBurnRateAlerts) {
+		t.Errorf("Wrong number of alerts: %d", alerts)
+	}
+
+	for _, objective := range []string{"100", "abc"} {
+		_, err = newFleetSLORuleGroup(newTestFleetSLO("invalid", objective))
+		if err == nil {
+			t.Errorf("The objective %s should be invalid", objective)
+		}
+	}
+}
+
+func TestGenerateFleetSLORules(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+	}
+	valid := newTestFleetSLO("apiserver", "99.9")
+	invalid := newTestFleetSLO("invalid", "100")
+	c := fake.NewFakeClient(mco, valid, invalid)
+
+	_, err := GenerateFleetSLORules(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the FleetSLO rules: (%v)", err)
+	}
+	cm := &corev1.ConfigMap{}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.AlertRuleSLOConfigMapName,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, cm)
+	if err != nil {
+		t.Fatalf("Failed to get the FleetSLO rules configmap: (%v)", err)
+	}
+	ruleGroups := &RuleGroups{}
+	err = yaml.Unmarshal([]byte(cm.Data[mcoconfig.AlertRuleSLOFileKey]), ruleGroups)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the FleetSLO rules: (%v)", err)
+	}
+	if len(ruleGroups.Groups) != 1 || ruleGroups.Groups[0].Name != "fleet-slo-apiserver" {
+		t.Fatalf("Wrong FleetSLO rule groups: %v", ruleGroups.Groups)
+	}
+
+	found := &mcov1beta2.FleetSLO{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: invalid.Name}, found)
+	if err != nil {
+		t.Fatalf("Failed to get the FleetSLO: (%v)", err)
+	}
+	if len(found.Status.Conditions) != 1 || found.Status.Conditions[0].Status != metav1.ConditionFalse {
+		t.Fatalf("The invalid FleetSLO should not be ready: %v", found.Status.Conditions)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+
+	obsv1alpha1 "github.com/open-cluster-management/observatorium-operator/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// the secret of the OIDC client which is rendered into the Observatorium CR, the reconcile is triggered
+// once it is rotated
+var gatewayOIDCSecretName = ""
+
+func getGatewayOIDC(mco *mcov1beta2.MultiClusterObservability) *mcov1beta2.GatewayOIDCSpec {
+	if mco.Spec.GatewayAuth == nil {
+		return nil
+	}
+	return mco.Spec.GatewayAuth.OIDC
+}
+
+// newAPITenantOIDC returns the OIDC configuration of the tenant in the observatorium API, the secret of
+// the client is read from the secret in the spec since the Observatorium CR only takes it inline
+func newAPITenantOIDC(c client.Client, oidc *mcov1beta2.GatewayOIDCSpec) (*obsv1alpha1.TenantOIDC, error) {
+	tenantOIDC := &obsv1alpha1.TenantOIDC{
+		IssuerURL:     oidc.IssuerURL,
+		ClientID:      oidc.ClientID,
+		UsernameClaim: oidc.UsernameClaim,
+	}
+	if oidc.IssuerCA != nil {
+		tenantOIDC.ConfigMapName = oidc.IssuerCA.Name
+		tenantOIDC.CAKey = oidc.IssuerCA.Key
+	}
+	if oidc.ClientSecret == nil {
+		return tenantOIDC, nil
+	}
+	secret := &v1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      oidc.ClientSecret.Name,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, secret)
+	if err != nil {
+		log.Error(err, "Failed to get the secret of the OIDC client", "name", oidc.ClientSecret.Name)
+		return nil, err
+	}
+	clientSecret, ok := secret.Data[oidc.ClientSecret.Key]
+	if !ok {
+		return nil, fmt.Errorf("the key %s is not found in the secret %s of the OIDC client",
+			oidc.ClientSecret.Key, oidc.ClientSecret.Name)
+	}
+	tenantOIDC.ClientSecret = string(clientSecret)
+	return tenantOIDC, nil
+}
+
+// newAPITenantTokenOIDC returns the OIDC configuration of the tenant which validates the tokens of the
+// collector service account against the service account issuer of the hub
+func newAPITenantTokenOIDC(mco *mcov1beta2.MultiClusterObservability) *obsv1alpha1.TenantOIDC {
+	return &obsv1alpha1.TenantOIDC{
+		IssuerURL:     mcoconfig.GetHubIssuerURL(mco),
+		ClientID:      mcoconfig.GetTokenAudience(mco),
+		UsernameClaim: "sub",
+		ConfigMapName: mcoconfig.HubIssuerCAConfigMapName,
+		CAKey:         mcoconfig.HubIssuerCAKey,
+	}
+}
+
+// setGatewayAuth renders the OIDC authentication into the tenant of the observatorium API, and binds
+// the users of the OIDC provider or the collector service account to the read and write roles. The
+// mTLS of the tenant is kept so that the managed clusters with the client certificates still remote write.
+func setGatewayAuth(c client.Client, mco *mcov1beta2.MultiClusterObservability,
+	obs *obsv1alpha1.ObservatoriumSpec) error {
+	oidc := getGatewayOIDC(mco)
+	gatewayOIDCSecretName = ""
+	if oidc != nil && mcoconfig.IsTokenAuthEnabled(mco) {
+		return fmt.Errorf("the oidc and the serviceAccountToken of the gatewayAuth cannot be enabled together")
+	}
+
+	var tenantOIDC *obsv1alpha1.TenantOIDC
+	users := map[string][]string{}
+	if oidc != nil {
+		if oidc.ClientSecret != nil {
+			gatewayOIDCSecretName = oidc.ClientSecret.Name
+		}
+		var err error
+		tenantOIDC, err = newAPITenantOIDC(c, oidc)
+		if err != nil {
+			return err
+		}
+		users[readOnlyRoleName] = oidc.ReadUsers
+		users[writeOnlyRoleName] = oidc.WriteUsers
+	} else {
+		// the tokens are still validated while the managed clusters switch back to the client certificates
+		inUse, err := mcoconfig.IsTokenAuthInUse(c, mco)
+		if err != nil {
+			log.Error(err, "Failed to check whether the token authentication is in use")
+			return err
+		}
+		if !inUse {
+			return nil
+		}
+		tenantOIDC = newAPITenantTokenOIDC(mco)
+		users[writeOnlyRoleName] = []string{mcoconfig.GetCollectorServiceAccountUser()}
+	}
+
+	for i := range obs.API.Tenants {
+		if obs.API.Tenants[i].Name == mcoconfig.GetDefaultTenantName() {
+			obs.API.Tenants[i].OIDC = tenantOIDC
+		}
+	}
+	for i, binding := range obs.API.RBAC.RoleBindings {
+		for _, user := range users[binding.Name] {
+			obs.API.RBAC.RoleBindings[i].Subjects = append(obs.API.RBAC.RoleBindings[i].Subjects,
+				obsv1alpha1.Subject{Name: user, Kind: obsv1alpha1.User})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+	observatoriumv1alpha1 "github.com/open-cluster-management/observatorium-operator/api/v1alpha1"
+)
+
+func TestSetGatewayOIDC(t *testing.T) {
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+	}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "oidc-client", Namespace: mcoconfig.GetDefaultNamespace()},
+		Data:       map[string][]byte{"clientSecret": []byte("s3cr3t")},
+	}
+	s := scheme.Scheme
+	addonv1alpha1.AddToScheme(s)
+	c := fake.NewFakeClientWithScheme(s, secret)
+
+	obs := &observatoriumv1alpha1.ObservatoriumSpec{API: newAPISpec(mco)}
+	if err := setGatewayAuth(c, mco, obs); err != nil || obs.API.Tenants[0].OIDC != nil {
+		t.Errorf("the tenant should only use mTLS without the OIDC: %v (%v)", obs.API.Tenants[0], err)
+	}
+
+	mco.Spec.GatewayAuth = &mcov1beta2.GatewayAuthSpec{
+		OIDC: &mcov1beta2.GatewayOIDCSpec{
+			IssuerURL: "https://sso.example.com/auth/realms/observability",
+			ClientID:  "observatorium",
+			ClientSecret: &v1.SecretKeySelector{
+				LocalObjectReference: v1.LocalObjectReference{Name: "oidc-client"},
+				Key:                  "clientSecret",
+			},
+			WriteUsers: []string{"collector"},
+			ReadUsers:  []string{"dashboards"},
+		},
+	}
+	obs = &observatoriumv1alpha1.ObservatoriumSpec{API: newAPISpec(mco)}
+	if err := setGatewayAuth(c, mco, obs); err != nil {
+		t.Fatalf("Failed to set the OIDC of the gateway: (%v)", err)
+	}
+	tenant := obs.API.Tenants[0]
+	if tenant.OIDC == nil || tenant.OIDC.IssuerURL != mco.Spec.GatewayAuth.OIDC.IssuerURL ||
+		tenant.OIDC.ClientID != "observatorium" || tenant.OIDC.ClientSecret != "s3cr3t" {
+		t.Errorf("the OIDC should be rendered into the tenant: %v", tenant.OIDC)
+	}
+	if tenant.MTLS == nil {
+		t.Errorf("the mTLS of the tenant should be kept")
+	}
+	if gatewayOIDCSecretName != "oidc-client" {
+		t.Errorf("the secret of the OIDC client should be watched: %s", gatewayOIDCSecretName)
+	}
+	expected := map[string]string{readOnlyRoleName: "dashboards", writeOnlyRoleName: "collector"}
+	for _, binding := range obs.API.RBAC.RoleBindings {
+		last := binding.Subjects[len(binding.Subjects)-1]
+		if last.Name != expected[binding.Name] || last.Kind != observatoriumv1alpha1.User {
+			t.Errorf("the OIDC user should be bound to %s: %v", binding.Name, binding.Subjects)
+		}
+	}
+
+	mco.Spec.GatewayAuth.OIDC.ClientSecret.Key = "missing"
+	if err := setGatewayAuth(c, mco, obs); err == nil {
+		t.Errorf("the missing key of the OIDC client secret should fail")
+	}
+}
+
+func TestSetGatewayTokenAuth(t *testing.T) {
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			GatewayAuth: &mcov1beta2.GatewayAuthSpec{
+				ServiceAccountToken: &mcov1beta2.GatewayServiceAccountTokenSpec{Enabled: true},
+			},
+		},
+	}
+	s := scheme.Scheme
+	addonv1alpha1.AddToScheme(s)
+	c := fake.NewFakeClientWithScheme(s, &addonv1alpha1.ManagedClusterAddOn{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        util.ManagedClusterAddonName,
+			Namespace:   "cluster1",
+			Annotations: map[string]string{mcoconfig.AuthModeAnnotation: mcoconfig.RevertingAuthMode},
+		},
+	})
+
+	obs := &observatoriumv1alpha1.ObservatoriumSpec{API: newAPISpec(mco)}
+	if err := setGatewayAuth(c, mco, obs); err != nil {
+		t.Fatalf("Failed to set the token authentication of the gateway: (%v)", err)
+	}
+	oidc := obs.API.Tenants[0].OIDC
+	if oidc == nil || oidc.IssuerURL != "https://kubernetes.default.svc" || oidc.ClientID != "observability-api" ||
+		oidc.ConfigMapName != mcoconfig.HubIssuerCAConfigMapName {
+		t.Errorf("the tokens should be validated against the issuer of the hub: %v", oidc)
+	}
+	for _, binding := range obs.API.RBAC.RoleBindings {
+		last := binding.Subjects[len(binding.Subjects)-1]
+		isCollector := last.Name == mcoconfig.GetCollectorServiceAccountUser()
+		if isCollector != (binding.Name == writeOnlyRoleName) {
+			t.Errorf("the collector service account should only be allowed to write: %v", binding)
+		}
+	}
+
+	// the tokens are still validated while the managed clusters switch back to the client certificates
+	mco.Spec.GatewayAuth.ServiceAccountToken.Enabled = false
+	obs = &observatoriumv1alpha1.ObservatoriumSpec{API: newAPISpec(mco)}
+	if err := setGatewayAuth(c, mco, obs); err != nil || obs.API.Tenants[0].OIDC == nil {
+		t.Errorf("the tokens should be accepted until the clusters switch back: (%v)", err)
+	}
+
+	mco.Spec.GatewayAuth.ServiceAccountToken.Enabled = true
+	mco.Spec.GatewayAuth.OIDC = &mcov1beta2.GatewayOIDCSpec{IssuerURL: "https://sso.example.com", ClientID: "id"}
+	if err := setGatewayAuth(c, mco, obs); err == nil {
+		t.Errorf("the oidc and the service account tokens should not be enabled together")
+	}
+}
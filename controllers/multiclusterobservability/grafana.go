@@ -48,14 +48,18 @@ type GrafanaDatasource struct {
 }
 
 type JsonData struct {
-	TLSAuth   bool `yaml:"tlsAuth"`
-	TLSAuthCA bool `yaml:"tlsAuthWithCACert"`
+	TLSAuth         bool   `yaml:"tlsAuth"`
+	TLSAuthCA       bool   `yaml:"tlsAuthWithCACert"`
+	HTTPHeaderName1 string `yaml:"httpHeaderName1,omitempty"`
+	HTTPHeaderName2 string `yaml:"httpHeaderName2,omitempty"`
 }
 
 type SecureJsonData struct {
-	TLSCACert     string `yaml:"tlsCACert"`
-	TLSClientCert string `yaml:"tlsClientCert"`
-	TLSClientKey  string `yaml:"tlsClientKey"`
+	TLSCACert        string `yaml:"tlsCACert"`
+	TLSClientCert    string `yaml:"tlsClientCert"`
+	TLSClientKey     string `yaml:"tlsClientKey"`
+	HTTPHeaderValue1 string `yaml:"httpHeaderValue1,omitempty"`
+	HTTPHeaderValue2 string `yaml:"httpHeaderValue2,omitempty"`
 }
 
 // GenerateGrafanaDataSource is used to generate the GrafanaDatasource as a secret.
@@ -72,23 +76,34 @@ func GenerateGrafanaDataSource(
 		return &ctrl.Result{}, err
 	}
 
+	datasources := newGrafanaDatasources(mco, cm.Data["service-ca.crt"])
+	if config.IsExternalMetricsStoreEnabled(mco) {
+		externalDatasources, err := newExternalMetricsStoreDatasources(c, mco)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+		// the external metrics store replaces the observatorium on the hub
+		datasources = externalDatasources
+		if tracingDatasource := newTracingDatasource(mco); tracingDatasource != nil {
+			datasources = append(datasources, tracingDatasource)
+		}
+	}
+	federatedDatasources, err := newFederatedHubDatasources(c, mco)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	datasources = append(datasources, federatedDatasources...)
+	lokiDatasource, err := newLokiDatasource(c, mco)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	if lokiDatasource != nil {
+		datasources = append(datasources, lokiDatasource)
+	}
+
 	grafanaDatasources, err := yaml.Marshal(GrafanaDatasources{
-		APIVersion: 1,
-		Datasources: []*GrafanaDatasource{
-			{
-				Name:      "Observatorium",
-				Type:      "prometheus",
-				Access:    "proxy",
-				IsDefault: true,
-				URL:       "https://rbac-query-proxy." + config.GetDefaultNamespace() + ".svc.cluster.local:8443",
-				JSONData: &JsonData{
-					TLSAuthCA: true,
-				},
-				SecureJSONData: &SecureJsonData{
-					TLSCACert: cm.Data["service-ca.crt"],
-				},
-			},
-		},
+		APIVersion:  1,
+		Datasources: datasources,
 	})
 	if err != nil {
 		return &ctrl.Result{}, err
@@ -138,5 +153,60 @@ func GenerateGrafanaDataSource(
 		return &ctrl.Result{}, err
 	}
 
+	if string(grafanaDSFound.Data["datasources.yaml"]) != dsSecret.StringData["datasources.yaml"] {
+		log.Info("Updating grafana datasource secret",
+			"dsSecret.Namespace", dsSecret.Namespace,
+			"dsSecret.Name", dsSecret.Name,
+		)
+		dsSecret.ResourceVersion = grafanaDSFound.ResourceVersion
+		err = c.Update(context.TODO(), dsSecret)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+
 	return nil, nil
 }
+
+// newGrafanaDatasources returns the default datasource, and the datasource of the
+// tracing backend when the traces are collected
+func newGrafanaDatasources(mco *mcov1beta2.MultiClusterObservability, caCert string) []*GrafanaDatasource {
+	url := "https://rbac-query-proxy." + config.GetDefaultNamespace() + ".svc.cluster.local:8443"
+	datasources := []*GrafanaDatasource{
+		{
+			Name:      "Observatorium",
+			Type:      "prometheus",
+			Access:    "proxy",
+			IsDefault: true,
+			URL:       url,
+			JSONData: &JsonData{
+				TLSAuthCA: true,
+			},
+			SecureJSONData: &SecureJsonData{
+				TLSCACert: caCert,
+			},
+		},
+	}
+	if tracingDatasource := newTracingDatasource(mco); tracingDatasource != nil {
+		datasources = append(datasources, tracingDatasource)
+	}
+	return datasources
+}
+
+// newTracingDatasource returns the datasource of the tracing backend, or nil if the traces
+// are not collected
+func newTracingDatasource(mco *mcov1beta2.MultiClusterObservability) *GrafanaDatasource {
+	if !config.IsTracingEnabled(mco) {
+		return nil
+	}
+	backend := mco.Spec.Tracing.Backend
+	if backend == "" {
+		backend = config.TracingBackendTempo
+	}
+	return &GrafanaDatasource{
+		Name:   "Traces",
+		Type:   backend,
+		Access: "proxy",
+		URL:    mco.Spec.Tracing.QueryURL,
+	}
+}
@@ -5,6 +5,9 @@ package multiclusterobservability
 
 import (
 	"testing"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
 )
 
 func TestUpdateGrafanaSpec(t *testing.T) {
@@ -22,3 +25,44 @@ func TestUpdateGrafanaSpec(t *testing.T) {
 	// 	t.Errorf("Replicas (%v) is not the expected (%v)", mco.Spec.Grafana.Replicas, defaultReplicas)
 	// }
 }
+
+func TestNewGrafanaDatasources(t *testing.T) {
+	mco := &mcov1beta2.MultiClusterObservability{
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			ClusterSetTenants: []mcov1beta2.ClusterSetTenant{
+				{Name: "team-a", ClusterSets: []string{"team-a"}},
+			},
+		},
+	}
+
+	// the tenant label does not scope the queries on the hub, so the tenants do not get datasources
+	datasources := newGrafanaDatasources(mco, "test-ca")
+	if len(datasources) != 1 {
+		t.Fatalf("Wrong number of datasources: %v", len(datasources))
+	}
+	ds := datasources[0]
+	if ds.Name != "Observatorium" || !ds.IsDefault || ds.JSONData.HTTPHeaderName1 != "" ||
+		ds.SecureJSONData.TLSCACert != "test-ca" {
+		t.Errorf("Wrong default datasource: %v", ds)
+	}
+}
+
+func TestNewGrafanaDatasourcesWithTracing(t *testing.T) {
+	mco := &mcov1beta2.MultiClusterObservability{
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			Tracing: &mcov1beta2.TracingSpec{
+				Enabled:  true,
+				Endpoint: "tempo-distributor.tracing.svc:4317",
+				QueryURL: "http://tempo-query-frontend.tracing.svc:3100",
+			},
+		},
+	}
+	datasources := newGrafanaDatasources(mco, "test-ca")
+	if len(datasources) != 2 {
+		t.Fatalf("Wrong number of datasources: %v", len(datasources))
+	}
+	ds := datasources[1]
+	if ds.Type != config.TracingBackendTempo || ds.URL != mco.Spec.Tracing.QueryURL {
+		t.Errorf("Wrong tracing datasource: %v", ds)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const highlyAvailableConditionType = "HighlyAvailable"
+
+// getHubZones returns the zones of the schedulable nodes which the node selector of the
+// observability components selects
+func getHubZones(c client.Client, mco *mcov1beta2.MultiClusterObservability) ([]string, error) {
+	nodeList := &corev1.NodeList{}
+	err := c.List(context.TODO(), nodeList, client.MatchingLabels(mco.Spec.NodeSelector))
+	if err != nil {
+		return nil, err
+	}
+	zoneLabel := mcoconfig.GetHAZoneLabel(mco)
+	zoneSet := map[string]bool{}
+	for _, node := range nodeList.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		if zone := node.Labels[zoneLabel]; zone != "" {
+			zoneSet[zone] = true
+		}
+	}
+	zones := []string{}
+	for zone := range zoneSet {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return zones, nil
+}
+
+// checkHighAvailability validates the zones of the hub before the components are rendered, the
+// spread of the components across the zones is only enforced once the hub has enough zones
+func checkHighAvailability(c client.Client, mco *mcov1beta2.MultiClusterObservability) error {
+	if !mcoconfig.IsHighAvailabilityEnabled(mco) {
+		mcoconfig.SetHubZonesSatisfied(false)
+		return nil
+	}
+	zones, err := getHubZones(c, mco)
+	if err != nil {
+		log.Error(err, "Failed to get the zones of the nodes")
+		return err
+	}
+	mcoconfig.SetHubZonesSatisfied(len(zones) >= mcoconfig.GetHAMinZones(mco))
+	return nil
+}
+
+// updateHighAvailabilityStatus reports whether the hub meets the constraints of the highly
+// available topology
+func updateHighAvailabilityStatus(conditions *[]mcoshared.Condition, c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) {
+	if !mcoconfig.IsHighAvailabilityEnabled(mco) {
+		removeStatusCondition(conditions, highlyAvailableConditionType)
+		return
+	}
+	zones, err := getHubZones(c, mco)
+	if err != nil {
+		log.Error(err, "Failed to get the zones of the nodes")
+		return
+	}
+
+	minZones := mcoconfig.GetHAMinZones(mco)
+	problems := []string{}
+	if len(zones) < minZones {
+		problems = append(problems, fmt.Sprintf("the nodes span %d zones (%s), fewer than %d",
+			len(zones), strings.Join(zones, ", "), minZones))
+	}
+	replicationFactor := mcoconfig.GetHAReplicationFactor(mco)
+	if int(replicationFactor) < 2 {
+		problems = append(problems, "the replication factor of thanos receive is 1, a single replica loses the series")
+	}
+	if replicas := mcoconfig.GetObservabilityComponentReplicas(mcoconfig.Alertmanager); replicas != nil &&
+		int(*replicas) < minZones {
+		problems = append(problems, fmt.Sprintf("alertmanager has %d replicas, fewer than the %d zones",
+			*replicas, minZones))
+	}
+
+	condition := mcoshared.Condition{
+		Type:   highlyAvailableConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: "HighlyAvailable",
+		Message: fmt.Sprintf("The components are spread across the zones %s, every series is replicated %d times",
+			strings.Join(zones, ", "), replicationFactor),
+	}
+	if len(problems) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "NotHighlyAvailable"
+		condition.Message = "The hub does not meet the highly available topology: " + strings.Join(problems, "; ") +
+			". The components are spread across the zones on a best effort basis"
+	}
+	setStatusCondition(conditions, condition)
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func newZoneNode(name, zone string, unschedulable bool) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				mcoconfig.DefaultHAZoneLabel:     zone,
+				"node-role.kubernetes.io/worker": "",
+			},
+		},
+		Spec: corev1.NodeSpec{Unschedulable: unschedulable},
+	}
+}
+
+func TestHighAvailability(t *testing.T) {
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			NodeSelector:     map[string]string{"node-role.kubernetes.io/worker": ""},
+			HighAvailability: &mcov1beta2.HighAvailabilitySpec{},
+			StorageConfig:    &mcov1beta2.StorageConfig{ReceiveStorageSize: "1Gi"},
+			RetentionConfig:  &mcov1beta2.RetentionConfig{},
+		},
+	}
+	c := fake.NewFakeClient(
+		newZoneNode("worker-1", "us-east-1a", false),
+		newZoneNode("worker-2", "us-east-1b", false),
+		newZoneNode("worker-3", "us-east-1c", true),
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   "master-1",
+			Labels: map[string]string{mcoconfig.DefaultHAZoneLabel: "us-east-1c"},
+		}},
+	)
+
+	err := checkHighAvailability(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to check the high availability: (%v)", err)
+	}
+	if mcoconfig.GetHATopologySpreadConstraint(mco, nil).WhenUnsatisfiable != corev1.ScheduleAnyway {
+		t.Errorf("the spread should not be enforced with 2 zones")
+	}
+	conditions := []mcoshared.Condition{}
+	updateHighAvailabilityStatus(&conditions, c, mco)
+	condition := findStatusCondition(conditions, highlyAvailableConditionType)
+	if condition == nil || condition.Reason != "NotHighlyAvailable" ||
+		!strings.Contains(condition.Message, "2 zones (us-east-1a, us-east-1b)") {
+		t.Errorf("the missing zone should be reported: %v", conditions)
+	}
+
+	mco.Spec.HighAvailability.MinZones = 2
+	err = checkHighAvailability(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to check the high availability: (%v)", err)
+	}
+	if mcoconfig.GetHATopologySpreadConstraint(mco, nil).WhenUnsatisfiable != corev1.DoNotSchedule {
+		t.Errorf("the spread should be enforced with 2 zones")
+	}
+	updateHighAvailabilityStatus(&conditions, c, mco)
+	condition = findStatusCondition(conditions, highlyAvailableConditionType)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Errorf("the hub should be highly available: %v", conditions)
+	}
+
+	mco.Spec.HighAvailability.ReplicationFactor = 5
+	receSpec := newReceiversSpec(mco, "gp2")
+	if *receSpec.ReplicationFactor != 5 || *receSpec.Replicas != 5 {
+		t.Errorf("the receive replicas should be increased to the replication factor: %d/%d",
+			*receSpec.Replicas, *receSpec.ReplicationFactor)
+	}
+
+	mco.Spec.HighAvailability = nil
+	updateHighAvailabilityStatus(&conditions, c, mco)
+	if findStatusCondition(conditions, highlyAvailableConditionType) != nil {
+		t.Errorf("the condition should be removed once the topology is disabled")
+	}
+}
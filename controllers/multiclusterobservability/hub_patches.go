@@ -0,0 +1,58 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+// applyHubPatches applies the user-supplied patches to the rendered Deployments and StatefulSets of
+// the hub components, so that the customizations survive the reconciles. The hash of the patches
+// which apply to a resource is recorded in its annotation to deploy the change of the patches.
+func applyHubPatches(c client.Client, resources []*unstructured.Unstructured) error {
+	patches, _, err := util.GetManifestPatches(c, config.HubPatchesConfigMapName, config.GetDefaultNamespace())
+	if err != nil || len(patches) == 0 {
+		return err
+	}
+	for _, res := range resources {
+		kind := res.GetKind()
+		if kind != "Deployment" && kind != "StatefulSet" {
+			continue
+		}
+		doc, err := res.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		applied := []util.ManifestPatch{}
+		for _, patch := range patches {
+			if patch.Kind != kind || patch.Name != res.GetName() {
+				continue
+			}
+			patched, err := util.ApplyManifestPatch(doc, patch)
+			if err != nil {
+				log.Error(err, "Failed to apply the hub patch, skip it", "kind", kind, "name", patch.Name)
+				continue
+			}
+			doc = patched
+			applied = append(applied, patch)
+		}
+		if len(applied) == 0 {
+			continue
+		}
+		if err := res.UnmarshalJSON(doc); err != nil {
+			return err
+		}
+		annotations := res.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[config.HubPatchesHashAnnotation] = util.HashManifestPatches(applied)
+		res.SetAnnotations(annotations)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/deploying"
+)
+
+func TestApplyHubPatches(t *testing.T) {
+	namespace := config.GetDefaultNamespace()
+	sts := &appsv1.StatefulSet{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+		ObjectMeta: metav1.ObjectMeta{Name: "observability-alertmanager", Namespace: namespace},
+		Spec: appsv1.StatefulSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "alertmanager", Image: "alertmanager"}},
+				},
+			},
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(sts)
+	if err != nil {
+		t.Fatalf("Failed to convert the statefulset: (%v)", err)
+	}
+	res := &unstructured.Unstructured{Object: obj}
+	cm := &unstructured.Unstructured{}
+	cm.SetAPIVersion("v1")
+	cm.SetKind("ConfigMap")
+	cm.SetName("observability-alertmanager")
+
+	patches := `
+- kind: StatefulSet
+  name: observability-alertmanager
+  patch: |
+    metadata:
+      labels:
+        policy.example.com/team: observability
+    spec:
+      template:
+        spec:
+          containers:
+          - name: alertmanager
+            env:
+            - name: HTTPS_PROXY
+              value: http://proxy.example.com:3128
+- kind: ConfigMap
+  name: observability-alertmanager
+  patch: |
+    metadata:
+      labels:
+        foo: bar
+`
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: config.HubPatchesConfigMapName, Namespace: namespace},
+		Data:       map[string]string{"patches.yaml": patches},
+	})
+	if err := applyHubPatches(c, []*unstructured.Unstructured{res, cm}); err != nil {
+		t.Fatalf("Failed to apply the hub patches: (%v)", err)
+	}
+	if res.GetLabels()["policy.example.com/team"] != "observability" ||
+		res.GetAnnotations()[config.HubPatchesHashAnnotation] == "" {
+		t.Fatalf("the statefulset %s is not patched: %v", res.GetName(), res.GetLabels())
+	}
+	if len(cm.GetLabels()) != 0 {
+		t.Errorf("only the deployments and the statefulsets should be patched: %v", cm.GetLabels())
+	}
+
+	// the patches survive the reconciles
+	deployer := deploying.NewDeployer(c)
+	if err := c.Create(context.TODO(), sts); err != nil {
+		t.Fatalf("Failed to create the statefulset: (%v)", err)
+	}
+	if err := deployer.Deploy(res); err != nil {
+		t.Fatalf("Failed to deploy the statefulset: (%v)", err)
+	}
+	found := &appsv1.StatefulSet{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: sts.Name, Namespace: namespace}, found)
+	if err != nil {
+		t.Fatalf("Failed to get the statefulset: (%v)", err)
+	}
+	if found.Labels["policy.example.com/team"] != "observability" {
+		t.Errorf("the label is not deployed: %v", found.Labels)
+	}
+	env := found.Spec.Template.Spec.Containers[0].Env
+	if len(env) != 1 || env[0].Name != "HTTPS_PROXY" {
+		t.Errorf("the env is not deployed: %v", env)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	imageManifestConditionType = "ImageManifestApplied"
+	// the addons which become degraded in the window after a new image set is applied roll it back,
+	// the image set is considered stable after the window
+	imageManifestRollbackWindow = 30 * time.Minute
+)
+
+// reconcileImageManifest validates the image manifest of the release before its images are applied
+// to the components on the hub and the managed clusters, and rolls back to the previously applied
+// image set if the observability addons become degraded after the new image set is applied
+func reconcileImageManifest(c client.Client, mco *mcov1beta2.MultiClusterObservability) error {
+	release := mcoconfig.GetReleaseImageManifests()
+	if len(release) == 0 {
+		return nil
+	}
+	state, err := mcoconfig.GetImageManifestState(c)
+	if err != nil {
+		log.Error(err, "Failed to get the state of the image manifest")
+		return err
+	}
+	requireDigest, rollback := false, true
+	if mco.Spec.ImageManifest != nil {
+		requireDigest = mco.Spec.ImageManifest.RequireDigest
+		rollback = !mco.Spec.ImageManifest.DisableRollback
+	}
+
+	hash := mcoconfig.GetImageManifestsHash(release)
+	// the image set which is rolled back is not applied again until the release changes, the invalid
+	// one is validated again since the digest requirement can be changed
+	if hash != state.Rejected.Hash || state.Rejected.Reason == mcoconfig.ImageManifestRejectedInvalid {
+		if err := mcoconfig.ValidateImageManifests(release, requireDigest); err != nil {
+			log.Error(err, "Reject the image manifest", "hash", hash)
+			state.Reject(hash, mcoconfig.ImageManifestRejectedInvalid, err.Error())
+		} else if hash != state.Current.Hash {
+			log.Info("Apply the image manifest", "hash", hash, "previous", state.Current.Hash)
+			state.Apply(release)
+		} else if rollback && state.Previous.Hash != "" {
+			degraded, err := getDegradedClustersSince(c, state.AppliedAt)
+			if err != nil {
+				return err
+			}
+			if len(degraded) > 0 {
+				log.Info("Roll back the image manifest", "hash", hash, "previous", state.Previous.Hash,
+					"degraded", degraded)
+				state.Reject(hash, mcoconfig.ImageManifestRejectedRolledBack,
+					fmt.Sprintf("%d managed clusters became degraded after the image set is applied: %s",
+						len(degraded), strings.Join(degraded, ", ")))
+			}
+		}
+	}
+
+	if err := mcoconfig.SaveImageManifestState(c, state); err != nil {
+		log.Error(err, "Failed to save the state of the image manifest")
+		return err
+	}
+	mcoconfig.SetImageManifests(mcoconfig.GetAppliedImageManifests(state, release))
+	return nil
+}
+
+// getDegradedClustersSince returns the managed clusters whose observability addon became degraded
+// in the rollback window after the time, the window is over if the time is empty
+func getDegradedClustersSince(c client.Client, since string) ([]string, error) {
+	appliedAt, err := time.Parse(time.RFC3339, since)
+	if err != nil || time.Since(appliedAt) > imageManifestRollbackWindow {
+		return nil, nil
+	}
+	addonList := &mcov1beta1.ObservabilityAddonList{}
+	err = c.List(context.TODO(), addonList)
+	if err != nil {
+		log.Error(err, "Failed to list the ObservabilityAddons")
+		return nil, err
+	}
+	degraded := []string{}
+	for _, addon := range addonList.Items {
+		// the addons in the cluster namespaces on the hub
+		if addon.Namespace == mcoconfig.GetDefaultNamespace() {
+			continue
+		}
+		for _, condition := range addon.Status.Conditions {
+			if condition.Type == "Degraded" && condition.Status == metav1.ConditionTrue &&
+				!condition.LastTransitionTime.Time.Before(appliedAt) {
+				degraded = append(degraded, addon.Namespace)
+				break
+			}
+		}
+	}
+	sort.Strings(degraded)
+	return degraded, nil
+}
+
+// updateImageManifestStatus reports the hash of the image set which is applied, or the reason why the
+// image manifest of the release is rejected
+func updateImageManifestStatus(conditions *[]mcoshared.Condition, c client.Client) {
+	release := mcoconfig.GetReleaseImageManifests()
+	if len(release) == 0 {
+		removeStatusCondition(conditions, imageManifestConditionType)
+		return
+	}
+	state, err := mcoconfig.GetImageManifestState(c)
+	if err != nil {
+		return
+	}
+	condition := mcoshared.Condition{
+		Type:    imageManifestConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Applied",
+		Message: fmt.Sprintf("The image set %s of version %s is applied", state.Current.Hash, mcoconfig.GetComponentVersion()),
+	}
+	hash := mcoconfig.GetImageManifestsHash(release)
+	if state.Rejected.Hash == hash {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = state.Rejected.Reason
+		condition.Message = fmt.Sprintf("The image set %s of version %s is rejected: %s", hash,
+			mcoconfig.GetComponentVersion(), state.Rejected.Message)
+		if state.Current.Hash != "" {
+			condition.Message += fmt.Sprintf(". The image set %s is applied instead", state.Current.Hash)
+		} else {
+			condition.Message += ". The default images are applied instead"
+		}
+	}
+	setStatusCondition(conditions, condition)
+}
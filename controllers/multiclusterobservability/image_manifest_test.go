@@ -0,0 +1,92 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestReconcileImageManifest(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta1.SchemeBuilder.AddToScheme(s)
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	defer mcoconfig.SetReleaseImageManifests(map[string]string{})
+	defer mcoconfig.SetImageManifests(map[string]string{})
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+	}
+	addon := &mcov1beta1.ObservabilityAddon{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability-addon", Namespace: "cluster1"},
+	}
+	c := fake.NewFakeClient(mco, addon)
+
+	oldImages := map[string]string{"endpoint_monitoring_operator": "quay.io/endpoint-monitoring-operator:2.2.0"}
+	mcoconfig.SetReleaseImageManifests(oldImages)
+	if err := reconcileImageManifest(c, mco); err != nil {
+		t.Fatalf("Failed to reconcile the image manifest: (%v)", err)
+	}
+	newImages := map[string]string{"endpoint_monitoring_operator": "quay.io/endpoint-monitoring-operator:2.3.0"}
+	mcoconfig.SetReleaseImageManifests(newImages)
+	if err := reconcileImageManifest(c, mco); err != nil {
+		t.Fatalf("Failed to reconcile the image manifest: (%v)", err)
+	}
+	if !reflect.DeepEqual(mcoconfig.GetImageManifests(), newImages) {
+		t.Fatalf("the new image set is not applied: %v", mcoconfig.GetImageManifests())
+	}
+
+	// the addon becomes degraded after the new image set is applied
+	addon.Status.Conditions = []mcov1beta1.StatusCondition{{
+		Type:               "Degraded",
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "Degraded",
+	}}
+	if err := c.Update(context.TODO(), addon); err != nil {
+		t.Fatalf("Failed to update the addon: (%v)", err)
+	}
+	if err := reconcileImageManifest(c, mco); err != nil {
+		t.Fatalf("Failed to reconcile the image manifest: (%v)", err)
+	}
+	if !reflect.DeepEqual(mcoconfig.GetImageManifests(), oldImages) {
+		t.Fatalf("the image set is not rolled back: %v", mcoconfig.GetImageManifests())
+	}
+	conditions := []mcoshared.Condition{}
+	updateImageManifestStatus(&conditions, c)
+	condition := findStatusCondition(conditions, imageManifestConditionType)
+	if condition == nil || condition.Reason != mcoconfig.ImageManifestRejectedRolledBack ||
+		!strings.Contains(condition.Message, mcoconfig.GetImageManifestsHash(oldImages)) {
+		t.Fatalf("the rollback should be reported: %v", conditions)
+	}
+
+	// the unpinned images are rejected if the digest is required
+	mco.Spec.ImageManifest = &mcov1beta2.ImageManifestSpec{RequireDigest: true}
+	mcoconfig.SetReleaseImageManifests(map[string]string{
+		"endpoint_monitoring_operator": "quay.io/endpoint-monitoring-operator:2.3.1",
+	})
+	if err := reconcileImageManifest(c, mco); err != nil {
+		t.Fatalf("Failed to reconcile the image manifest: (%v)", err)
+	}
+	if !reflect.DeepEqual(mcoconfig.GetImageManifests(), oldImages) {
+		t.Fatalf("the unpinned image set is applied: %v", mcoconfig.GetImageManifests())
+	}
+	conditions = []mcoshared.Condition{}
+	updateImageManifestStatus(&conditions, c)
+	condition = findStatusCondition(conditions, imageManifestConditionType)
+	if condition == nil || condition.Reason != mcoconfig.ImageManifestRejectedInvalid {
+		t.Fatalf("the invalid image manifest should be reported: %v", conditions)
+	}
+}
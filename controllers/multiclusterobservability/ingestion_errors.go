@@ -0,0 +1,60 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	ingestionConditionType = "IngestionHealthy"
+	// the number of the managed clusters which are listed in the condition
+	ingestionConditionTopClusters = 3
+	// the report is regenerated by the placementrule controller in the same interval
+	ingestionErrorsStatusInterval = 10 * time.Minute
+)
+
+// updateIngestionErrorsStatus reports the managed clusters with most remote writes rejected by the hub
+// from the report which the placementrule controller generates
+func updateIngestionErrorsStatus(conditions *[]mcoshared.Condition, c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) {
+	if !mco.Spec.EnableIngestionErrorAttribution {
+		removeStatusCondition(conditions, ingestionConditionType)
+		return
+	}
+	report, err := mcoconfig.GetIngestionErrorsReport(c)
+	if err != nil || report == nil {
+		return
+	}
+	condition := mcoshared.Condition{
+		Type:    ingestionConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "NoIngestionErrors",
+		Message: "No remote writes of the managed clusters are rejected in the last hour",
+	}
+	if len(report.Clusters) > 0 {
+		offenders := []string{}
+		for i, cluster := range report.Clusters {
+			if i == ingestionConditionTopClusters {
+				break
+			}
+			offenders = append(offenders, fmt.Sprintf("%s (%d)", cluster.Cluster, cluster.Errors))
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "IngestionErrors"
+		condition.Message = fmt.Sprintf("The remote writes of %d managed clusters are rejected in the last hour, "+
+			"the most rejected are %s. See the %s configmap for details", len(report.Clusters),
+			strings.Join(offenders, ", "), mcoconfig.IngestionErrorsConfigMapName)
+	}
+	setStatusCondition(conditions, condition)
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestUpdateIngestionErrorsStatus(t *testing.T) {
+	report := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mcoconfig.IngestionErrorsConfigMapName,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+		},
+		Data: map[string]string{mcoconfig.IngestionErrorsFileKey: `
+clusters:
+- cluster: cluster2
+  errors: 340
+- cluster: cluster1
+  errors: 12
+`},
+	}
+	c := fake.NewFakeClient(report)
+	mco := &mcov1beta2.MultiClusterObservability{
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{EnableIngestionErrorAttribution: true},
+	}
+
+	conditions := []mcoshared.Condition{}
+	updateIngestionErrorsStatus(&conditions, c, mco)
+	condition := findStatusCondition(conditions, ingestionConditionType)
+	if condition == nil || condition.Status != metav1.ConditionFalse {
+		t.Fatalf("the ingestion should not be healthy: %v", conditions)
+	}
+	if !strings.Contains(condition.Message, "cluster2 (340), cluster1 (12)") {
+		t.Errorf("the most rejected clusters should be listed: %s", condition.Message)
+	}
+
+	mco.Spec.EnableIngestionErrorAttribution = false
+	updateIngestionErrorsStatus(&conditions, c, mco)
+	if findStatusCondition(conditions, ingestionConditionType) != nil {
+		t.Errorf("the condition should be removed once the attribution is disabled: %v", conditions)
+	}
+}
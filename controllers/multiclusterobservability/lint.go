@@ -0,0 +1,81 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// ValidateObjStorageSecret validates the object storage configuration in the metricObjectStorage
+// secret with the storage config of the MultiClusterObservability
+func ValidateObjStorageSecret(mco *mcov1beta2.MultiClusterObservability, data []byte) error {
+	_, err := mcoconfig.GenerateObjStorageConf(data, mco.Spec.StorageConfig)
+	return err
+}
+
+// ValidateRules validates the rule file of the thanos ruler, each rule is either a recording rule
+// or an alerting rule with the expression
+func ValidateRules(data string) error {
+	groups := &RuleGroups{}
+	err := yaml.Unmarshal([]byte(data), groups)
+	if err != nil {
+		return err
+	}
+	names := map[string]bool{}
+	for _, group := range groups.Groups {
+		if group.Name == "" {
+			return fmt.Errorf("the name of the rule group is required")
+		}
+		if names[group.Name] {
+			return fmt.Errorf("duplicate rule group %s", group.Name)
+		}
+		names[group.Name] = true
+		for i, rule := range group.Rules {
+			if (rule.Record == "") == (rule.Alert == "") {
+				return fmt.Errorf("rule %d in group %s must set exactly one of record and alert", i, group.Name)
+			}
+			if rule.Expr == "" {
+				return fmt.Errorf("rule %d in group %s has no expr", i, group.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateDashboard validates the grafana dashboard in the key of the custom dashboard configmap
+func ValidateDashboard(data string) error {
+	dashboard := map[string]interface{}{}
+	err := json.Unmarshal([]byte(data), &dashboard)
+	if err != nil {
+		return err
+	}
+	if _, ok := dashboard["title"].(string); !ok {
+		return fmt.Errorf("the title of the dashboard is required")
+	}
+	return nil
+}
+
+// ValidateMetricsImport validates the spec of the MetricsImport
+func ValidateMetricsImport(imp *mcov1beta2.MetricsImport) error {
+	return validateMetricsImport(imp)
+}
+
+// ValidateFleetSLO validates the spec of the FleetSLO and its generated rules
+func ValidateFleetSLO(slo *mcov1beta2.FleetSLO) error {
+	group, err := newFleetSLORuleGroup(slo)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(&RuleGroups{Groups: []RuleGroup{*group}})
+	if err != nil {
+		return err
+	}
+	return ValidateRules(string(data))
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const lokiPort = 3100
+
+// GenerateLokiRoute exposes the loki on the hub when the logs are collected into the hub,
+// or removes the loki resources when it is disabled
+func GenerateLokiRoute(
+	runclient client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	if !mcoconfig.IsHubLokiEnabled(mco) {
+		return nil, deleteLoki(runclient, mco)
+	}
+
+	loki := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mcoconfig.Loki,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+		},
+		Spec: routev1.RouteSpec{
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString("https"),
+			},
+			To: routev1.RouteTargetReference{
+				Kind: "Service",
+				Name: mcoconfig.Loki,
+			},
+			// loki terminates the mTLS itself
+			TLS: &routev1.TLSConfig{
+				Termination:                   routev1.TLSTerminationPassthrough,
+				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyNone,
+			},
+		},
+	}
+
+	// Set MultiClusterObservability instance as the owner and controller
+	if err := controllerutil.SetControllerReference(mco, loki, scheme); err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	err := runclient.Get(
+		context.TODO(),
+		types.NamespacedName{Name: loki.Name, Namespace: loki.Namespace},
+		&routev1.Route{})
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating a new route to expose loki",
+			"loki.Namespace", loki.Namespace,
+			"loki.Name", loki.Name,
+		)
+		err = runclient.Create(context.TODO(), loki)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+
+	return nil, nil
+}
+
+func deleteLoki(c client.Client, mco *mcov1beta2.MultiClusterObservability) error {
+	namespace := mcoconfig.GetDefaultNamespace()
+	objs := []client.Object{
+		&routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.Loki, Namespace: namespace},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: mco.Name + "-" + mcoconfig.Loki, Namespace: namespace},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.Loki, Namespace: namespace},
+		},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.Loki + "-config", Namespace: namespace},
+		},
+		&v1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.Loki, Namespace: namespace},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.LokiCerts, Namespace: namespace},
+		},
+	}
+	return deleteResources(c, objs)
+}
+
+// newLokiDatasource returns the grafana datasource of the loki which receives the logs,
+// grafana uses its client certificate to query the loki on the hub
+func newLokiDatasource(c client.Client, mco *mcov1beta2.MultiClusterObservability) (*GrafanaDatasource, error) {
+	logs := mco.Spec.LogsCollection
+	if logs == nil || !logs.Enabled {
+		return nil, nil
+	}
+	if logs.ExternalLokiURL != "" {
+		return &GrafanaDatasource{
+			Name:   "Loki",
+			Type:   "loki",
+			Access: "proxy",
+			URL:    logs.ExternalLokiURL,
+		}, nil
+	}
+
+	lokiCerts := &v1.Secret{}
+	err := c.Get(context.TODO(),
+		types.NamespacedName{Name: mcoconfig.LokiCerts, Namespace: mcoconfig.GetDefaultNamespace()}, lokiCerts)
+	if err != nil {
+		log.Error(err, "Failed to get loki certificates secret")
+		return nil, err
+	}
+	grafanaCerts := &v1.Secret{}
+	err = c.Get(context.TODO(),
+		types.NamespacedName{Name: mcoconfig.GrafanaCerts, Namespace: mcoconfig.GetDefaultNamespace()}, grafanaCerts)
+	if err != nil {
+		log.Error(err, "Failed to get grafana certificates secret")
+		return nil, err
+	}
+	return &GrafanaDatasource{
+		Name:   "Loki",
+		Type:   "loki",
+		Access: "proxy",
+		URL:    fmt.Sprintf("https://%s:%d", mcoconfig.GetLokiSvc(), lokiPort),
+		JSONData: &JsonData{
+			TLSAuth:   true,
+			TLSAuthCA: true,
+		},
+		SecureJSONData: &SecureJsonData{
+			TLSCACert:     string(lokiCerts.Data["ca.crt"]),
+			TLSClientCert: string(grafanaCerts.Data["tls.crt"]),
+			TLSClientKey:  string(grafanaCerts.Data["tls.key"]),
+		},
+	}, nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestGenerateLokiRoute(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	routev1.AddToScheme(s)
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			LogsCollection: &mcov1beta2.LogsCollectionSpec{Enabled: true},
+		},
+	}
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mco.Name + "-" + mcoconfig.Loki,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+		},
+	}
+	c := fake.NewFakeClient(mco, sts)
+	key := types.NamespacedName{Name: mcoconfig.Loki, Namespace: mcoconfig.GetDefaultNamespace()}
+
+	_, err := GenerateLokiRoute(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the loki route: (%v)", err)
+	}
+	err = c.Get(context.TODO(), key, &routev1.Route{})
+	if err != nil {
+		t.Fatalf("Failed to get the loki route: (%v)", err)
+	}
+
+	// the loki on the hub is removed when the logs are sent to an external loki
+	mco.Spec.LogsCollection.ExternalLokiURL = "https://loki.example.com"
+	_, err = GenerateLokiRoute(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to remove the loki: (%v)", err)
+	}
+	err = c.Get(context.TODO(), key, &routev1.Route{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The loki route is not deleted: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: sts.Name, Namespace: sts.Namespace}, &appsv1.StatefulSet{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The loki statefulset is not deleted: (%v)", err)
+	}
+
+	ds, err := newLokiDatasource(c, mco)
+	if err != nil || ds == nil || ds.URL != mco.Spec.LogsCollection.ExternalLokiURL {
+		t.Fatalf("Wrong loki datasource for the external loki: (%v, %v)", ds, err)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// the prefix of the receivers of the active maintenance windows, the receivers are named
+// maintenance/<name> and have no integrations, so that the alerts routed to them are dropped
+const maintenanceReceiverPrefix = "maintenance/"
+
+// newMaintenanceConfigs returns the receivers and the routes which silence the alerts of the
+// managed clusters in the active maintenance windows. The routes do not continue, so that the
+// alerts are not routed to any other receiver until the windows end. The invalid maintenance
+// windows are skipped, and the reasons are returned.
+func newMaintenanceConfigs(c client.Client, mco *mcov1beta2.MultiClusterObservability,
+	now time.Time) ([]interface{}, []interface{}, []string, error) {
+	receivers := []interface{}{}
+	routes := []interface{}{}
+	invalid := []string{}
+	names := map[string]bool{}
+	var clusterSets map[string][]string
+	for _, w := range mco.Spec.MaintenanceWindows {
+		if names[w.Name] {
+			invalid = append(invalid, fmt.Sprintf("the name of the maintenance window %q is duplicated", w.Name))
+			continue
+		}
+		names[w.Name] = true
+		if err := mcoconfig.ValidateMaintenanceWindow(w); err != nil {
+			invalid = append(invalid, err.Error())
+			continue
+		}
+		if _, active := mcoconfig.GetMaintenanceWindowEnd(w, now); !active {
+			continue
+		}
+
+		clusters := append([]string{}, w.Clusters...)
+		if len(w.ClusterSets) > 0 {
+			if clusterSets == nil {
+				var err error
+				clusterSets, err = getClusterSetMembers(c)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+			}
+			for _, set := range w.ClusterSets {
+				clusters = append(clusters, clusterSets[set]...)
+			}
+		}
+		if len(clusters) == 0 {
+			continue
+		}
+		sort.Strings(clusters)
+		receivers = append(receivers, map[string]interface{}{"name": maintenanceReceiverPrefix + w.Name})
+		routes = append(routes, map[string]interface{}{
+			"receiver": maintenanceReceiverPrefix + w.Name,
+			"match_re": map[string]interface{}{mcoconfig.GetClusterNameLabelKey(): newAlternation(clusters)},
+			"continue": false,
+		})
+	}
+	return receivers, routes, invalid, nil
+}
+
+// getClusterSetMembers returns the names of the managed clusters by the ManagedClusterSets which
+// they belong to
+func getClusterSetMembers(c client.Client) (map[string][]string, error) {
+	members := map[string][]string{}
+	clusters := &clusterv1.ManagedClusterList{}
+	err := c.List(context.TODO(), clusters, client.HasLabels{mcoconfig.ClusterSetLabelKey})
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return members, nil
+		}
+		log.Error(err, "Failed to list the managed clusters of the cluster sets")
+		return nil, err
+	}
+	for _, cluster := range clusters.Items {
+		set := cluster.Labels[mcoconfig.ClusterSetLabelKey]
+		members[set] = append(members[set], cluster.Name)
+	}
+	return members, nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestNewMaintenanceConfigs(t *testing.T) {
+	s := scheme.Scheme
+	clusterv1.AddToScheme(s)
+
+	now := time.Date(2021, 5, 1, 3, 30, 0, 0, time.UTC)
+	newCluster := func(name, clusterSet string) *clusterv1.ManagedCluster {
+		return &clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{mcoconfig.ClusterSetLabelKey: clusterSet},
+		}}
+	}
+	c := fake.NewFakeClient(newCluster("prod-2", "prod"), newCluster("prod-1", "prod"), newCluster("dev-1", "dev"))
+	mco := &mcov1beta2.MultiClusterObservability{
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			MaintenanceWindows: []mcov1beta2.MaintenanceWindow{
+				{
+					Name:        "upgrade",
+					Clusters:    []string{"edge.1"},
+					ClusterSets: []string{"prod"},
+					Start:       &metav1.Time{Time: now.Add(-time.Hour)},
+					End:         &metav1.Time{Time: now.Add(time.Hour)},
+				},
+				{
+					Name:     "weekly",
+					Clusters: []string{"dev-1"},
+					Schedule: "0 2 * * 0",
+					Duration: &metav1.Duration{Duration: 2 * time.Hour},
+				},
+				{
+					Name:     "upgrade",
+					Clusters: []string{"dev-1"},
+					Start:    &metav1.Time{Time: now.Add(-time.Hour)},
+					End:      &metav1.Time{Time: now.Add(time.Hour)},
+				},
+				{
+					Name:     "no-end",
+					Clusters: []string{"dev-1"},
+					Start:    &metav1.Time{Time: now.Add(-time.Hour)},
+				},
+			},
+		},
+	}
+
+	receivers, routes, invalid, err := newMaintenanceConfigs(c, mco, now)
+	if err != nil {
+		t.Fatalf("Failed to generate the maintenance configs: (%v)", err)
+	}
+	if len(invalid) != 2 {
+		t.Errorf("The duplicated name and the window without the end should be invalid: %v", invalid)
+	}
+	if len(receivers) != 1 || len(routes) != 1 {
+		t.Fatalf("Only the active window should be routed: %v", routes)
+	}
+	route := routes[0].(map[string]interface{})
+	matchRE := route["match_re"].(map[string]interface{})
+	if route["receiver"] != "maintenance/upgrade" || route["continue"] != false ||
+		matchRE[mcoconfig.GetClusterNameLabelKey()] != `edge\.1|prod-1|prod-2` {
+		t.Errorf("The route of the maintenance window is wrong: %v", route)
+	}
+	if !isManagedReceiver(receivers[0]) || !isManagedReceiver(route) {
+		t.Errorf("The receiver and the route of the maintenance window should be managed")
+	}
+}
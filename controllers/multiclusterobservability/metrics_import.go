@@ -0,0 +1,315 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+const (
+	metricsImportJobPrefix = "observability-import-"
+	// metricsImportLabel is the label of the import jobs with the name of the MetricsImport
+	metricsImportLabel      = "observability.open-cluster-management.io/metrics-import"
+	metricsImportSourcePath = "/var/thanos/source"
+	metricsImportUploadPath = "/var/thanos/upload"
+)
+
+// GenerateMetricsImportJobs runs a job for each MetricsImport which is not finished yet, the job
+// uploads the historical blocks of the source into the object storage, and records the progress
+// of the job in the status of the MetricsImport. A finished import is never run again.
+func GenerateMetricsImportJobs(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	importList := &mcov1beta2.MetricsImportList{}
+	err := c.List(context.TODO(), importList)
+	if err != nil && !meta.IsNoMatchError(err) {
+		log.Error(err, "Failed to list the MetricsImports")
+		return &ctrl.Result{}, err
+	}
+
+	for idx := range importList.Items {
+		imp := &importList.Items[idx]
+		if imp.Status.Phase == mcov1beta2.MetricsImportSucceeded || imp.Status.Phase == mcov1beta2.MetricsImportFailed {
+			continue
+		}
+		status := imp.Status.DeepCopy()
+		if mcoconfig.IsExternalMetricsStoreEnabled(mco) {
+			status.Phase = mcov1beta2.MetricsImportPending
+			setStatusCondition(&status.Conditions, mcoshared.Condition{
+				Type:    "Ready",
+				Status:  metav1.ConditionFalse,
+				Reason:  "ExternalMetricsStore",
+				Message: "The metrics are forwarded to the external metrics store, there is no object storage to import into",
+			})
+		} else if err := validateMetricsImport(imp); err != nil {
+			log.Info("Invalid MetricsImport, skip it", "name", imp.Name, "error", err.Error())
+			status.Phase = mcov1beta2.MetricsImportFailed
+			setStatusCondition(&status.Conditions, mcoshared.Condition{
+				Type:    "Ready",
+				Status:  metav1.ConditionFalse,
+				Reason:  "InvalidSpec",
+				Message: err.Error(),
+			})
+		} else {
+			err = syncMetricsImportJob(c, scheme, mco, imp, status)
+			if err != nil {
+				return &ctrl.Result{}, err
+			}
+		}
+		err = updateMetricsImportStatus(c, imp, status)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+	return nil, nil
+}
+
+func validateMetricsImport(imp *mcov1beta2.MetricsImport) error {
+	source := imp.Spec.Source
+	if (source.PrometheusTSDB == nil) == (source.ThanosBucket == nil) {
+		return fmt.Errorf("exactly one of prometheusTSDB and thanosBucket must be set in the source")
+	}
+	if source.PrometheusTSDB != nil && source.PrometheusTSDB.ClaimName == "" {
+		return fmt.Errorf("the claimName of prometheusTSDB is required")
+	}
+	if source.ThanosBucket != nil && (source.ThanosBucket.Config.Name == "" || source.ThanosBucket.Config.Key == "") {
+		return fmt.Errorf("the name and the key of the config of thanosBucket are required")
+	}
+	return nil
+}
+
+// syncMetricsImportJob creates the import job if it does not exist and records its progress
+func syncMetricsImportJob(c client.Client, scheme *runtime.Scheme, mco *mcov1beta2.MultiClusterObservability,
+	imp *mcov1beta2.MetricsImport, status *mcov1beta2.MetricsImportStatus) error {
+	job := &batchv1.Job{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      metricsImportJobPrefix + imp.Name,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, job)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get the import job", "name", imp.Name)
+		return err
+	}
+	if errors.IsNotFound(err) {
+		job, err = newMetricsImportJob(mco, imp)
+		if err != nil {
+			log.Error(err, "Failed to generate the import job", "name", imp.Name)
+			return err
+		}
+		// the job is deleted together with the MetricsImport
+		if err = controllerutil.SetControllerReference(imp, job, scheme); err != nil {
+			return err
+		}
+		log.Info("Creating the import job", "name", imp.Name, "cluster", imp.Spec.ClusterName)
+		err = c.Create(context.TODO(), job)
+		if err != nil {
+			log.Error(err, "Failed to create the import job", "name", imp.Name)
+			return err
+		}
+	}
+
+	status.JobName = job.Name
+	status.Phase = mcov1beta2.MetricsImportRunning
+	if job.Status.StartTime != nil {
+		status.StartTime = job.Status.StartTime
+	}
+	condition := mcoshared.Condition{
+		Type:    "Ready",
+		Status:  metav1.ConditionFalse,
+		Reason:  "ImportRunning",
+		Message: fmt.Sprintf("The job %s is importing the metrics of the cluster %s", job.Name, imp.Spec.ClusterName),
+	}
+	if job.Status.Failed > 0 {
+		condition.Message += fmt.Sprintf(", %d failed attempts", job.Status.Failed)
+	}
+	finished, result := isJobFinished(job)
+	if finished {
+		status.CompletionTime = job.Status.CompletionTime
+		if status.CompletionTime == nil {
+			now := metav1.Now()
+			status.CompletionTime = &now
+		}
+	}
+	if finished && result == batchv1.JobComplete {
+		status.Phase = mcov1beta2.MetricsImportSucceeded
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ImportSucceeded"
+		condition.Message = fmt.Sprintf("The metrics of the cluster %s are imported into the object storage",
+			imp.Spec.ClusterName)
+	} else if finished {
+		status.Phase = mcov1beta2.MetricsImportFailed
+		condition.Reason = "ImportFailed"
+		condition.Message = fmt.Sprintf("The job %s failed to import the metrics, check the logs of the job, "+
+			"the blocks which are imported already are kept in the object storage", job.Name)
+	}
+	setStatusCondition(&status.Conditions, condition)
+	return nil
+}
+
+func newMetricsImportJob(mco *mcov1beta2.MultiClusterObservability,
+	imp *mcov1beta2.MetricsImport) (*batchv1.Job, error) {
+	objStorageConfig := newThanosObjStorageConfig(mco)
+	volumes := []corev1.Volume{
+		{
+			Name: "objstore",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: objStorageConfig.Name},
+			},
+		},
+	}
+	volumeMounts := []corev1.VolumeMount{
+		{Name: "objstore", MountPath: objStorageMountPath, ReadOnly: true},
+	}
+
+	var command, args []string
+	var env []corev1.EnvVar
+	if tsdb := imp.Spec.Source.PrometheusTSDB; tsdb != nil {
+		thanosMeta, err := util.NewThanosMeta(getMetricsImportLabels(mco, imp))
+		if err != nil {
+			return nil, err
+		}
+		command = []string{"/bin/sh", "-c"}
+		args = []string{util.GetUploadBlocksScript(metricsImportSourcePath, metricsImportUploadPath,
+			objStorageMountPath+"/"+objStorageConfig.Key)}
+		env = []corev1.EnvVar{{Name: util.ThanosMetaEnv, Value: thanosMeta}}
+		volumes = append(volumes, corev1.Volume{
+			Name:         "upload",
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "upload", MountPath: metricsImportUploadPath})
+		volumes = append(volumes, corev1.Volume{
+			Name: "source",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: tsdb.ClaimName,
+					ReadOnly:  true,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name: "source", MountPath: metricsImportSourcePath, SubPath: tsdb.SubPath, ReadOnly: true,
+		})
+	} else {
+		bucket := imp.Spec.Source.ThanosBucket
+		args = []string{
+			"tools", "bucket", "replicate",
+			"--objstore.config-file=" + metricsImportSourcePath + "/" + bucket.Config.Key,
+			"--objstore-to.config-file=" + objStorageMountPath + "/" + objStorageConfig.Key,
+			"--single-run",
+			"--log.level=info",
+		}
+		matchers := bucket.Matchers
+		if len(matchers) == 0 {
+			matchers = []string{fmt.Sprintf("cluster=%q", imp.Spec.ClusterName)}
+		}
+		for _, matcher := range matchers {
+			args = append(args, "--matcher="+matcher)
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: "source",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: bucket.Config.Name},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name: "source", MountPath: metricsImportSourcePath, ReadOnly: true,
+		})
+	}
+
+	image := imp.Spec.Image
+	if image == "" {
+		image = getThanosImage(mco)
+	}
+	backoffLimit := int32(2)
+	labels := map[string]string{
+		"app.kubernetes.io/instance": mco.GetName(),
+		"app.kubernetes.io/name":     "observability-import",
+		metricsImportLabel:           imp.Name,
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      metricsImportJobPrefix + imp.Name,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					// the service account of the compactor with the workload identity
+					ServiceAccountName: mco.GetName() + "-" + mcoconfig.ThanosCompact,
+					RestartPolicy:      corev1.RestartPolicyNever,
+					NodeSelector:       mco.Spec.NodeSelector,
+					Tolerations:        mco.Spec.Tolerations,
+					ImagePullSecrets: []corev1.LocalObjectReference{
+						{Name: mco.Spec.ImagePullSecret},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            "thanos-import",
+							Image:           image,
+							ImagePullPolicy: mco.Spec.ImagePullPolicy,
+							Command:         command,
+							Args:            args,
+							Env:             env,
+							VolumeMounts:    volumeMounts,
+						},
+					},
+					Volumes: volumes,
+				},
+			},
+		},
+	}, nil
+}
+
+// getMetricsImportLabels returns the external labels of the imported blocks of Prometheus, the
+// cluster labels are the same as the labels of the metrics which the addon collects, and the
+// external labels of the hub are overridden by the ones of the import
+func getMetricsImportLabels(mco *mcov1beta2.MultiClusterObservability,
+	imp *mcov1beta2.MetricsImport) map[string]string {
+	clusterID := imp.Spec.ClusterID
+	if clusterID == "" {
+		clusterID = imp.Spec.ClusterName
+	}
+	externalLabels := mcoconfig.GetHubExternalLabels(mco)
+	for k, v := range imp.Spec.ExternalLabels {
+		externalLabels[k] = v
+	}
+	externalLabels["cluster"] = imp.Spec.ClusterName
+	externalLabels["clusterID"] = clusterID
+	return externalLabels
+}
+
+func updateMetricsImportStatus(c client.Client, imp *mcov1beta2.MetricsImport,
+	status *mcov1beta2.MetricsImportStatus) error {
+	if reflect.DeepEqual(*status, imp.Status) {
+		return nil
+	}
+	imp.Status = *status
+	err := c.Status().Update(context.TODO(), imp)
+	if err != nil && !errors.IsConflict(err) {
+		log.Error(err, "Failed to update the status of MetricsImport", "name", imp.Name)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+func TestGenerateMetricsImportJobs(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			StorageConfig: &mcov1beta2.StorageConfig{
+				MetricObjectStorage: &mcoshared.PreConfiguredStorage{
+					Name: "thanos-object-storage",
+					Key:  "thanos.yaml",
+				},
+			},
+		},
+	}
+	tsdbImport := &mcov1beta2.MetricsImport{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster1-history"},
+		Spec: mcov1beta2.MetricsImportSpec{
+			ClusterName:    "cluster1",
+			ExternalLabels: map[string]string{"source": "prometheus"},
+			Source: mcov1beta2.MetricsImportSource{
+				PrometheusTSDB: &mcov1beta2.PrometheusTSDBSource{ClaimName: "prometheus-data"},
+			},
+		},
+	}
+	bucketImport := &mcov1beta2.MetricsImport{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster2-history"},
+		Spec: mcov1beta2.MetricsImportSpec{
+			ClusterName: "cluster2",
+			Source: mcov1beta2.MetricsImportSource{
+				ThanosBucket: &mcov1beta2.ThanosBucketSource{
+					Config: mcoshared.PreConfiguredStorage{Name: "legacy-thanos", Key: "thanos.yaml"},
+				},
+			},
+		},
+	}
+	invalidImport := &mcov1beta2.MetricsImport{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid"},
+		Spec:       mcov1beta2.MetricsImportSpec{ClusterName: "cluster3"},
+	}
+	c := fake.NewFakeClient(tsdbImport, bucketImport, invalidImport)
+
+	_, err := GenerateMetricsImportJobs(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the import jobs: (%v)", err)
+	}
+
+	job := &batchv1.Job{}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      metricsImportJobPrefix + tsdbImport.Name,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, job)
+	if err != nil {
+		t.Fatalf("Failed to get the import job of the Prometheus TSDB: (%v)", err)
+	}
+	container := job.Spec.Template.Spec.Containers[0]
+	args := strings.Join(container.Args, " ")
+	if !strings.Contains(args, "thanos tools bucket replicate") || !strings.Contains(args, metricsImportSourcePath) {
+		t.Errorf("the args of the import job are not expected: %s", args)
+	}
+	if len(container.Env) != 1 || container.Env[0].Name != util.ThanosMetaEnv ||
+		!strings.Contains(container.Env[0].Value, `"cluster":"cluster1"`) ||
+		!strings.Contains(container.Env[0].Value, `"clusterID":"cluster1"`) ||
+		!strings.Contains(container.Env[0].Value, `"source":"prometheus"`) {
+		t.Errorf("the thanos meta of the import job is not expected: %v", container.Env)
+	}
+
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      metricsImportJobPrefix + bucketImport.Name,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, job)
+	if err != nil {
+		t.Fatalf("Failed to get the import job of the Thanos bucket: (%v)", err)
+	}
+	args = strings.Join(job.Spec.Template.Spec.Containers[0].Args, " ")
+	if !strings.Contains(args, "replicate") || !strings.Contains(args, `--matcher=cluster="cluster2"`) {
+		t.Errorf("the args of the import job are not expected: %s", args)
+	}
+
+	imp := &mcov1beta2.MetricsImport{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: invalidImport.Name}, imp)
+	if err != nil {
+		t.Fatalf("Failed to get the MetricsImport: (%v)", err)
+	}
+	if imp.Status.Phase != mcov1beta2.MetricsImportFailed {
+		t.Errorf("the MetricsImport without a source should fail: %v", imp.Status)
+	}
+
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+	}
+	err = c.Status().Update(context.TODO(), job)
+	if err != nil {
+		t.Fatalf("Failed to update the import job: (%v)", err)
+	}
+	_, err = GenerateMetricsImportJobs(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the import jobs: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: bucketImport.Name}, imp)
+	if err != nil {
+		t.Fatalf("Failed to get the MetricsImport: (%v)", err)
+	}
+	if imp.Status.Phase != mcov1beta2.MetricsImportSucceeded || imp.Status.CompletionTime == nil ||
+		imp.Status.JobName != job.Name {
+		t.Errorf("the completed import should be recorded: %v", imp.Status)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: tsdbImport.Name}, imp)
+	if err != nil {
+		t.Fatalf("Failed to get the MetricsImport: (%v)", err)
+	}
+	if imp.Status.Phase != mcov1beta2.MetricsImportRunning {
+		t.Errorf("the running import should be recorded: %v", imp.Status)
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	metricsUsageAnalysisInterval = time.Hour
+	// the time of the last analysis of the metrics usage
+	metricsUsageAnalyzedAnnotation = "observability.open-cluster-management.io/last-analysis"
+	queryFrontendLabelSelector     = "app.kubernetes.io/name=thanos-query-frontend"
+)
+
+var (
+	metricNameRegexp = regexp.MustCompile(`[a-zA-Z_:][a-zA-Z0-9_:]*`)
+	// the matcher of the metric name in a selector, e.g. {__name__="up"}
+	nameMatcherRegexp = regexp.MustCompile(`__name__\s*=\s*"([^"]+)"`)
+	// the query in the logs of thanos query frontend, e.g. param_query="sum(up)"
+	queryLogRegexp = regexp.MustCompile(`param_query="((?:[^"\\]|\\.)*)"`)
+)
+
+// MetricsUsageReport lists the collected metrics which are not used by the dashboards,
+// the rules of thanos ruler or the ad hoc queries
+type MetricsUsageReport struct {
+	Collected int      `yaml:"collected"`
+	Used      int      `yaml:"used"`
+	Unused    []string `yaml:"unused"`
+	// Queried are the collected metrics in the ad hoc queries since the report is created,
+	// since the logs of thanos query frontend are only read from the last analysis
+	Queried []string `yaml:"queried,omitempty"`
+}
+
+// getQueryLogs returns the logs of the thanos query frontend pods since the last analysis
+var getQueryLogs = func(since time.Duration) ([]string, error) {
+	kubeClient, err := kubernetes.NewForConfig(ctrl.GetConfigOrDie())
+	if err != nil {
+		return nil, err
+	}
+	pods, err := kubeClient.CoreV1().Pods(mcoconfig.GetDefaultNamespace()).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: queryFrontendLabelSelector})
+	if err != nil {
+		return nil, err
+	}
+	sinceSeconds := int64(since.Seconds())
+	lines := []string{}
+	for _, pod := range pods.Items {
+		stream, err := kubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name,
+			&corev1.PodLogOptions{SinceSeconds: &sinceSeconds}).Stream(context.TODO())
+		if err != nil {
+			log.Info("Failed to get the logs of thanos query frontend", "pod", pod.Name, "error", err.Error())
+			continue
+		}
+		scanner := bufio.NewScanner(stream)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			if strings.Contains(scanner.Text(), "param_query=") {
+				lines = append(lines, scanner.Text())
+			}
+		}
+		stream.Close()
+	}
+	return lines, nil
+}
+
+// GenerateMetricsUsageReport reports the collected metrics which are never queried, it is
+// regenerated at most once in the analysis interval, or removed when it is disabled. The dashboards
+// are not cached, so the configmaps are read by the reader
+func GenerateMetricsUsageReport(c client.Client, reader client.Reader,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	if !mco.Spec.EnableMetricsUsageAnalytics {
+		return nil, deleteResources(c, []client.Object{
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      mcoconfig.MetricsUsageReportConfigMapName,
+					Namespace: mcoconfig.GetDefaultNamespace(),
+				},
+			},
+		})
+	}
+
+	found := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.MetricsUsageReportConfigMapName,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		return &ctrl.Result{}, err
+	}
+	exists := err == nil
+	now := time.Now()
+	lastAnalysis, err := time.Parse(time.RFC3339, found.Annotations[metricsUsageAnalyzedAnnotation])
+	if err == nil && now.Sub(lastAnalysis) < metricsUsageAnalysisInterval {
+		return nil, nil
+	}
+
+	previous := &MetricsUsageReport{}
+	err = yaml.Unmarshal([]byte(found.Data[mcoconfig.MetricsUsageReportFileKey]), previous)
+	if err != nil {
+		log.Info("Failed to unmarshal the previous metrics usage report", "error", err.Error())
+	}
+	report, err := newMetricsUsageReport(c, reader, previous.Queried)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	data, err := yaml.Marshal(report)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        mcoconfig.MetricsUsageReportConfigMapName,
+			Namespace:   mcoconfig.GetDefaultNamespace(),
+			Annotations: map[string]string{metricsUsageAnalyzedAnnotation: now.Format(time.RFC3339)},
+		},
+		Data: map[string]string{mcoconfig.MetricsUsageReportFileKey: string(data)},
+	}
+	if !exists {
+		log.Info("Creating the metrics usage report", "unused", len(report.Unused))
+		err = c.Create(context.TODO(), cm)
+	} else {
+		if !reflect.DeepEqual(found.Data, cm.Data) {
+			log.Info("Updating the metrics usage report", "unused", len(report.Unused))
+		}
+		found.Annotations = cm.Annotations
+		found.Data = cm.Data
+		err = c.Update(context.TODO(), found)
+	}
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	return nil, nil
+}
+
+func newMetricsUsageReport(c client.Client, reader client.Reader, queried []string) (*MetricsUsageReport, error) {
+	collected, err := getCollectedMetrics(c)
+	if err != nil {
+		return nil, err
+	}
+
+	cms := &corev1.ConfigMapList{}
+	err = reader.List(context.TODO(), cms, client.InNamespace(mcoconfig.GetDefaultNamespace()))
+	if err != nil {
+		return nil, err
+	}
+	// the metrics which are queried by the dashboards, the alerts and the ad hoc queries,
+	// and the metrics which are recorded by the recording rules from the other metrics
+	exprs := []string{}
+	records := map[string][]string{}
+	for _, cm := range cms.Items {
+		// the default, custom and generated rules of thanos ruler
+		if strings.HasPrefix(cm.Name, "thanos-ruler-") {
+			for key, data := range cm.Data {
+				groups := &RuleGroups{}
+				err = yaml.Unmarshal([]byte(data), groups)
+				if err != nil {
+					log.Info("Failed to unmarshal the rules", "name", cm.Name, "key", key, "error", err.Error())
+					continue
+				}
+				for _, group := range groups.Groups {
+					for _, rule := range group.Rules {
+						if rule.Record != "" {
+							records[rule.Record] = append(records[rule.Record], rule.Expr)
+						} else {
+							exprs = append(exprs, rule.Expr)
+						}
+					}
+				}
+			}
+			continue
+		}
+		if _, ok := cm.Labels[mcoconfig.DashboardLabelKey]; ok {
+			exprs = append(exprs, getDashboardExprs(cm.Data)...)
+		} else if _, ok := cm.Labels[mcoconfig.CustomDashboardLabelKey]; ok {
+			exprs = append(exprs, getDashboardExprs(cm.Data)...)
+		}
+	}
+
+	queriedNames := map[string]bool{}
+	for _, name := range queried {
+		queriedNames[name] = true
+	}
+	lines, err := getQueryLogs(metricsUsageAnalysisInterval)
+	if err != nil {
+		log.Info("Failed to get the query logs of thanos query frontend", "error", err.Error())
+	}
+	for _, line := range lines {
+		match := queryLogRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		query, err := strconv.Unquote(`"` + match[1] + `"`)
+		if err != nil {
+			continue
+		}
+		for _, name := range getMetricNames(query) {
+			queriedNames[name] = true
+		}
+	}
+
+	used := map[string]bool{}
+	pending := []string{}
+	for _, expr := range exprs {
+		pending = append(pending, getMetricNames(expr)...)
+	}
+	for name := range queriedNames {
+		pending = append(pending, name)
+	}
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if used[name] {
+			continue
+		}
+		used[name] = true
+		for _, expr := range records[name] {
+			pending = append(pending, getMetricNames(expr)...)
+		}
+	}
+
+	report := &MetricsUsageReport{Collected: len(collected), Unused: []string{}}
+	for _, name := range collected {
+		if used[name] {
+			report.Used++
+		} else {
+			report.Unused = append(report.Unused, name)
+		}
+		if queriedNames[name] {
+			report.Queried = append(report.Queried, name)
+		}
+	}
+	return report, nil
+}
+
+// getCollectedMetrics returns the sorted names of the metrics in the allowlist and the custom allowlist,
+// including the names in the match expressions
+func getCollectedMetrics(c client.Client) ([]string, error) {
+	names := map[string]bool{}
+	for _, name := range []string{mcoconfig.AllowlistConfigMapName, mcoconfig.AllowlistCustomConfigMapName} {
+		cm := &corev1.ConfigMap{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: mcoconfig.GetDefaultNamespace()}, cm)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		for key, data := range cm.Data {
+			allowlist := &struct {
+				Names   []string `yaml:"names"`
+				Matches []string `yaml:"matches"`
+			}{}
+			err = yaml.Unmarshal([]byte(data), allowlist)
+			if err != nil {
+				log.Info("Failed to unmarshal the allowlist", "name", name, "key", key, "error", err.Error())
+				continue
+			}
+			for _, metric := range allowlist.Names {
+				names[metric] = true
+			}
+			for _, match := range allowlist.Matches {
+				if m := nameMatcherRegexp.FindStringSubmatch(match); m != nil {
+					names[m[1]] = true
+				}
+			}
+		}
+	}
+	collected := []string{}
+	for name := range names {
+		collected = append(collected, name)
+	}
+	sort.Strings(collected)
+	return collected, nil
+}
+
+// getDashboardExprs returns the expressions of the panels and the variables in the grafana dashboards
+func getDashboardExprs(data map[string]string) []string {
+	exprs := []string{}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			for key, child := range value {
+				if s, ok := child.(string); ok && (key == "expr" || key == "query") {
+					exprs = append(exprs, s)
+					continue
+				}
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				walk(child)
+			}
+		}
+	}
+	for _, dashboard := range data {
+		var v interface{}
+		if err := json.Unmarshal([]byte(dashboard), &v); err != nil {
+			continue
+		}
+		walk(v)
+	}
+	return exprs
+}
+
+// getMetricNames returns the identifiers in the expression which may be metric names, the
+// functions, the keywords and the label names are not filtered since they are only matched
+// against the collected metrics, but the quoted label values are skipped
+func getMetricNames(expr string) []string {
+	names := []string{}
+	for _, m := range nameMatcherRegexp.FindAllStringSubmatch(expr, -1) {
+		names = append(names, m[1])
+	}
+	var unquoted strings.Builder
+	var quote rune
+	escaped := false
+	for _, ch := range expr {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0 && ch == '\\':
+			escaped = true
+		case quote != 0 && ch == quote:
+			quote = 0
+		case quote != 0:
+		case ch == '"' || ch == '\'' || ch == '`':
+			quote = ch
+			unquoted.WriteRune(' ')
+		default:
+			unquoted.WriteRune(ch)
+		}
+	}
+	return append(names, metricNameRegexp.FindAllString(unquoted.String(), -1)...)
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestGetMetricNames(t *testing.T) {
+	names := getMetricNames(`sum by (cluster) (rate(http_requests_total{code=~"5..",job="api"}[5m])) / {__name__="up"}`)
+	expected := map[string]bool{"http_requests_total": true, "up": true, "code": true, "job": true}
+	for name := range expected {
+		found := false
+		for _, n := range names {
+			if n == name {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("The metric %s is not found in %v", name, names)
+		}
+	}
+	for _, n := range names {
+		if n == "api" {
+			t.Errorf("The label value should be skipped: %v", names)
+		}
+	}
+}
+
+func TestGenerateMetricsUsageReport(t *testing.T) {
+	getQueryLogsFn := getQueryLogs
+	getQueryLogs = func(since time.Duration) ([]string, error) {
+		return []string{
+			`level=info msg="slow query detected" param_query="sum(rate(adhoc_total{job=\"a\"}[5m]))"`,
+		}, nil
+	}
+	defer func() { getQueryLogs = getQueryLogsFn }()
+
+	namespace := mcoconfig.GetDefaultNamespace()
+	allowlist := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.AllowlistConfigMapName, Namespace: namespace},
+		Data: map[string]string{
+			"metrics_list.yaml": `
+names:
+  - adhoc_total
+  - alerted_total
+  - dashboard_total
+  - recorded_total
+  - unused_total
+`,
+			"etcd_metrics_list.yaml": `
+matches:
+  - __name__="etcd_unused",job="etcd"
+`,
+		},
+	}
+	rules := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.AlertRuleDefaultConfigMapName, Namespace: namespace},
+		Data: map[string]string{mcoconfig.AlertRuleDefaultFileKey: `
+groups:
+- name: test
+  rules:
+  - record: cluster:recorded:sum
+    expr: sum by (cluster) (recorded_total)
+  - alert: Test
+    expr: alerted_total > 0 and cluster:recorded:sum > 0
+`},
+	}
+	dashboard := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "grafana-dashboard-test",
+			Namespace: namespace,
+			Labels:    map[string]string{"grafana-custom-dashboard": "true"},
+		},
+		Data: map[string]string{"test.json": `{"panels":[{"targets":[{"expr":"sum(dashboard_total)"}]}]}`},
+	}
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			EnableMetricsUsageAnalytics: true,
+		},
+	}
+	c := fake.NewFakeClient(allowlist, rules, dashboard)
+
+	_, err := GenerateMetricsUsageReport(c, c, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the metrics usage report: (%v)", err)
+	}
+	cm := &corev1.ConfigMap{}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.MetricsUsageReportConfigMapName,
+		Namespace: namespace,
+	}, cm)
+	if err != nil {
+		t.Fatalf("Failed to get the metrics usage report: (%v)", err)
+	}
+	report := &MetricsUsageReport{}
+	err = yaml.Unmarshal([]byte(cm.Data[mcoconfig.MetricsUsageReportFileKey]), report)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the metrics usage report: (%v)", err)
+	}
+	if report.Collected != 6 || report.Used != 4 {
+		t.Errorf("Wrong number of the collected or used metrics: %v", report)
+	}
+	if !reflect.DeepEqual(report.Unused, []string{"etcd_unused", "unused_total"}) {
+		t.Errorf("Wrong unused metrics: %v", report.Unused)
+	}
+	if !reflect.DeepEqual(report.Queried, []string{"adhoc_total"}) {
+		t.Errorf("Wrong queried metrics: %v", report.Queried)
+	}
+
+	mco.Spec.EnableMetricsUsageAnalytics = false
+	_, err = GenerateMetricsUsageReport(c, c, mco)
+	if err != nil {
+		t.Fatalf("Failed to remove the metrics usage report: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.MetricsUsageReportConfigMapName,
+		Namespace: namespace,
+	}, cm)
+	if err == nil {
+		t.Fatalf("The metrics usage report should be removed")
+	}
+}
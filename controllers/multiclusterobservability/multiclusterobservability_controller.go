@@ -7,11 +7,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
+	routev1 "github.com/openshift/api/route/v1"
 	ocpClientSet "github.com/openshift/client-go/config/clientset/versioned"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	storev1 "k8s.io/api/storage/v1"
 	crdClientSet "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
@@ -20,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -32,6 +36,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
 	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/certificates"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
@@ -70,6 +76,17 @@ type MultiClusterObservabilityReconciler struct {
 // +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=multiclusterobservabilities,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=multiclusterobservabilities/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=multiclusterobservabilities/finalizers,verbs=update
+// +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=fleetslos,verbs=get;list;watch
+// +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=fleetslos/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=alertmanagerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=alertmanagerconfigs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=metricsimports,verbs=get;list;watch
+// +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=metricsimports/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=pods/log,verbs=get
+// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=keda.sh,resources=scaledobjects,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;list;watch;create;update;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -106,11 +123,18 @@ func (r *MultiClusterObservabilityReconciler) Reconcile(ctx context.Context, req
 		return ctrl.Result{}, err
 	} else if isTerminating {
 		reqLogger.Info("MCO instance is in Terminating status, skip the reconcile")
+		if util.Contains(instance.GetFinalizers(), resFinalizer) {
+			// follow the teardown of the managed clusters until the blockers are removed
+			return ctrl.Result{RequeueAfter: uninstallRefreshInterval}, nil
+		}
 		return ctrl.Result{}, err
 	}
 
 	//read image manifest configmap to be used to replace the image for each component.
-	if _, err = config.ReadImageManifestConfigMap(r.Client); err != nil {
+	if _, err = config.ReadImageManifestConfigMap(r.APIReader); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err = reconcileImageManifest(r.Client, instance); err != nil {
 		return ctrl.Result{}, err
 	}
 
@@ -120,19 +144,36 @@ func (r *MultiClusterObservabilityReconciler) Reconcile(ctx context.Context, req
 		return ctrl.Result{}, nil
 	}
 
+	// sync the observability secrets from the external secret manager before they are read
+	result, err := GenerateExternalSecrets(r.Client, r.CrdClient, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+
 	storageClassSelected, err := getStorageClass(instance, r.Client)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
 	// handle storagesize changes
-	result, err := r.HandleStorageSizeChange(instance)
+	result, err = r.HandleStorageSizeChange(instance)
 	if result != nil {
 		return *result, err
 	}
 
 	//instance.Namespace = config.GetDefaultNamespace()
 	instance.Spec.StorageConfig.StorageClass = storageClassSelected
+	// validate the zones of the hub before the components are spread across them
+	if err := checkHighAvailability(r.Client, instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	// create the priority class before the components which refer to it are deployed
+	if !config.IsNamespaceScoped() {
+		result, err = GenerateHubPriorityClass(r.Client, r.Scheme, instance)
+		if result != nil {
+			return *result, err
+		}
+	}
 	//Render the templates with a specified CR
 	renderer := rendering.NewRenderer(instance)
 	toDeploy, err := renderer.Render(r.Client)
@@ -140,6 +181,24 @@ func (r *MultiClusterObservabilityReconciler) Reconcile(ctx context.Context, req
 		reqLogger.Error(err, "Failed to render multiClusterMonitoring templates")
 		return ctrl.Result{}, err
 	}
+	// export the defaults of the running operator before they are replaced by the pinned defaults
+	if err := ExportDefaultsBundle(r.Client, r.Scheme, instance, toDeploy); err != nil {
+		reqLogger.Error(err, "Failed to export the bundle of the defaults")
+		return ctrl.Result{}, err
+	}
+	if err := ApplyPinnedDefaults(r.Client, instance, toDeploy); err != nil {
+		reqLogger.Error(err, "Failed to apply the pinned defaults")
+		return ctrl.Result{}, err
+	}
+	if err := applyHubPatches(r.Client, toDeploy); err != nil {
+		reqLogger.Error(err, "Failed to apply the hub patches")
+		return ctrl.Result{}, err
+	}
+	// hold the upgrade of the hub components until enough managed clusters run the new addon
+	upgradeGated, err := isHubUpgradeGated(r.Client, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 	deployer := deploying.NewDeployer(r.Client)
 	//Deploy the resources
 	ns := &corev1.Namespace{}
@@ -153,14 +212,19 @@ func (r *MultiClusterObservabilityReconciler) Reconcile(ctx context.Context, req
 		if resNS == "" {
 			resNS = config.GetDefaultNamespace()
 		}
-		if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: resNS}, ns); err != nil && apierrors.IsNotFound(err) {
-			ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
-				Name: resNS,
-			}}
-			if err := r.Client.Create(context.TODO(), ns); err != nil {
-				reqLogger.Error(err, fmt.Sprintf("Failed to create namespace %s", resNS))
+		if err := r.ensureNamespace(resNS, ns); err != nil {
+			return ctrl.Result{}, err
+		}
+		if upgradeGated {
+			held, err := isUpgradeHeld(r.Client, res)
+			if err != nil {
 				return ctrl.Result{}, err
 			}
+			if held {
+				reqLogger.Info("Holding the upgrade until more managed clusters run the new addon",
+					"kind", res.GetKind(), "name", res.GetName())
+				continue
+			}
 		}
 		if err := deployer.Deploy(res); err != nil {
 			reqLogger.Error(err, fmt.Sprintf("Failed to deploy %s %s/%s",
@@ -175,30 +239,159 @@ func (r *MultiClusterObservabilityReconciler) Reconcile(ctx context.Context, req
 		return *result, err
 	}
 
+	// expose otlp receiver
+	result, err = GenerateOTLPReceiverRoute(r.Client, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// expose loki on the hub
+	result, err = GenerateLokiRoute(r.Client, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// expose the query endpoint to the external systems
+	result, err = GenerateQueryEndpointRoute(r.Client, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// create the certificates
 	err = certificates.CreateObservabilityCerts(r.Client, r.Scheme, instance)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
+	// protect the certificates and the object storage secret against the deletion
+	if !config.IsNamespaceScoped() {
+		result, err = GenerateSecretProtectionWebhook(r.Client, r.Scheme, instance)
+		if result != nil {
+			return *result, err
+		}
+	}
+
+	// move the managed clusters to the new host of the observatorium api route
+	result, err = HandleObsAPIEndpointChange(r.Client, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+
+	// issue the server certificate for the cluster proxies which the managed clusters write through
+	result, err = HandleProxyEndpointChange(r.Client, instance)
+	if result != nil {
+		return *result, err
+	}
+
+	// translate the FleetSLOs into the thanos ruler rules
+	result, err = GenerateFleetSLORules(r.Client, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+
+	// aggregate the cost metrics of the managed clusters
+	result, err = GenerateCostRules(r.Client, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+
+	// detect the high-cardinality metrics of the managed clusters
+	result, err = GenerateCardinalityRules(r.Client, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+
+	// fire the watchdog alerts of the alerting self test on the hub
+	result, err = GenerateWatchdogRules(r.Client, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+
+	// merge the AlertmanagerConfigs of the tenants and the alert receivers into the alertmanager configuration
+	result, err = GenerateAlertmanagerConfig(r.Client, instance)
+	if result != nil {
+		return *result, err
+	}
+
+	// generate the object storage configuration with the workload identity and the encryption
+	result, err = GenerateObjStorageConfig(r.Client, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+
+	// delete the blocks beyond the reduced retention with the cleanup job
+	result, err = HandleRetentionCleanup(r.Client, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+
+	// verify the blocks in the object storage periodically
+	result, err = GenerateBucketVerifyCronJob(r.Client, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+
+	// import the historical metrics of the MetricsImports into the object storage
+	result, err = GenerateMetricsImportJobs(r.Client, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+
 	// create an Observatorium CR
 	result, err = GenerateObservatoriumCR(r.Client, r.Scheme, instance)
 	if result != nil {
 		return *result, err
 	}
 
+	// scale thanos receive, query and query frontend automatically
+	result, err = GenerateAutoscalers(r.Client, r.CrdClient, r.Scheme, instance)
+	if result != nil {
+		return *result, err
+	}
+
+	// restart the thanos components once the object storage secret is rotated
+	result, err = RolloutObjStorageSecret(r.Client, instance)
+	if result != nil {
+		return *result, err
+	}
+
 	// generate grafana datasource to point to observatorium api gateway
 	result, err = GenerateGrafanaDataSource(r.Client, r.Scheme, instance)
 	if result != nil {
 		return *result, err
 	}
 
-	pmCrdExists, err := util.CheckCRDExist(r.CrdClient, config.PlacementRuleCrdName)
-	if err != nil {
-		return ctrl.Result{}, err
+	// report the collected metrics which are never queried
+	result, err = GenerateMetricsUsageReport(r.Client, r.APIReader, instance)
+	if result != nil {
+		return *result, err
 	}
 
-	if pmCrdExists {
+	// the managed clusters are not observed in the namespace-scoped install mode
+	pmCrdExists := false
+	if !config.IsNamespaceScoped() {
+		pmCrdExists, err = util.CheckCRDExist(r.CrdClient, config.PlacementRuleCrdName)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if pmCrdExists && config.IsPlacementRefEnabled(instance) {
+		// the managed clusters are selected by the user-managed placement
+		err = deleteDefaultPlacementRule(r.Client, instance)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	} else if pmCrdExists {
 		// create the placementrule
 		err = createPlacementRule(r.Client, r.Scheme, instance)
 		if err != nil {
@@ -206,9 +399,12 @@ func (r *MultiClusterObservabilityReconciler) Reconcile(ctx context.Context, req
 		}
 	}
 
-	svmCrdExists, err := util.CheckCRDExist(r.CrdClient, config.StorageVersionMigrationCrdName)
-	if err != nil {
-		return ctrl.Result{}, err
+	svmCrdExists := false
+	if !config.IsNamespaceScoped() {
+		svmCrdExists, err = util.CheckCRDExist(r.CrdClient, config.StorageVersionMigrationCrdName)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
 	}
 
 	if svmCrdExists {
@@ -224,7 +420,34 @@ func (r *MultiClusterObservabilityReconciler) Reconcile(ctx context.Context, req
 		return *result, err
 	}
 
-	return ctrl.Result{}, nil
+	requeueAfter := getRequeueInterval(instance)
+	if next := config.NextMaintenanceWindowChange(instance.Spec.MaintenanceWindows, time.Now()); next > 0 &&
+		(requeueAfter == 0 || next < requeueAfter) {
+		// update the routes of the maintenance windows when a window starts or ends
+		requeueAfter = next
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// getRequeueInterval returns the interval of the periodic reconcile, or 0 if it is not required
+func getRequeueInterval(instance *mcov1beta2.MultiClusterObservability) time.Duration {
+	if instance.Spec.EnableAlertingSelfTest {
+		// evaluate the health of the alerting pipeline periodically
+		return alertingSelfTestInterval
+	}
+	if instance.Spec.EnableMetricsUsageAnalytics {
+		// analyze the usage of the metrics periodically
+		return metricsUsageAnalysisInterval
+	}
+	if instance.Spec.EnableIngestionErrorAttribution {
+		// report the latest attribution of the rejected remote writes periodically
+		return ingestionErrorsStatusInterval
+	}
+	if isAuthMigrating(instance) {
+		// report the progress of the authentication migration until it completes
+		return authMigrationStatusInterval
+	}
+	return 0
 }
 
 // UpdateStatus override UpdateStatus interface
@@ -236,8 +459,23 @@ func (r *MultiClusterObservabilityReconciler) UpdateStatus(
 	updateInstallStatus(&newStatus.Conditions)
 	updateReadyStatus(&newStatus.Conditions, r.Client, mco)
 	updateAddonSpecStatus(&newStatus.Conditions, mco)
+	updateAlertingPipelineStatus(&newStatus.Conditions, r.Client, mco)
+	updateAlertReceiversStatus(&newStatus.Conditions, r.Client, mco)
+	updateDefaultsPinnedStatus(&newStatus.Conditions, r.Client, mco)
+	updateExternalMetricsStoreStatus(&newStatus.Conditions, r.Client, mco)
+	updateAddonVersionStatus(&newStatus.Conditions, r.Client, mco)
+	updateRetentionCleanupStatus(&newStatus.Conditions, r.Client)
+	updateBucketHealthStatus(&newStatus.Conditions, r.Client, mco)
+	updateHighAvailabilityStatus(&newStatus.Conditions, r.Client, mco)
+	updateEndpointChangeStatus(&newStatus.Conditions, r.Client)
+	updateImageManifestStatus(&newStatus.Conditions, r.Client)
+	updateIngestionErrorsStatus(&newStatus.Conditions, r.Client, mco)
+	updateAuthMigrationStatus(&newStatus.Conditions, r.Client, mco)
+	updateConfigDriftStatus(&newStatus.Conditions)
 	fillupStatus(&newStatus.Conditions)
+	rollingOut := updateComponentStatuses(newStatus, r.Client, mco)
 	mco.Status.Conditions = newStatus.Conditions
+	mco.Status.Components = newStatus.Components
 	err := r.Client.Status().Update(context.TODO(), mco)
 	if err != nil {
 		if apierrors.IsConflict(err) {
@@ -252,6 +490,10 @@ func (r *MultiClusterObservabilityReconciler) UpdateStatus(
 				return &ctrl.Result{}, err
 			}
 			mco.ObjectMeta.ResourceVersion = found.ObjectMeta.ResourceVersion
+			// the install progress of the addons is updated by the addon status controller
+			if progress := findStatusCondition(found.Status.Conditions, config.AddonsInstalledConditionType); progress != nil {
+				setStatusCondition(&mco.Status.Conditions, *progress)
+			}
 			err = r.Client.Status().Update(context.TODO(), mco)
 			if err != nil {
 				log.Error(err, fmt.Sprintf("Failed to update %s status ", mco.Name))
@@ -267,10 +509,32 @@ func (r *MultiClusterObservabilityReconciler) UpdateStatus(
 	if findStatusCondition(newStatus.Conditions, "Ready") == nil {
 		return &ctrl.Result{Requeue: true, RequeueAfter: time.Second * 2}, nil
 	}
+	// follow the rollout of the components, e.g. during the upgrade
+	if rollingOut {
+		return &ctrl.Result{RequeueAfter: componentStatusRefreshInterval}, nil
+	}
 
 	return nil, nil
 }
 
+// ensureNamespace creates the namespace of the resources if it does not exist, it is skipped in
+// the namespace-scoped install mode which cannot read the namespaces
+func (r *MultiClusterObservabilityReconciler) ensureNamespace(name string, ns *corev1.Namespace) error {
+	if config.IsNamespaceScoped() {
+		return nil
+	}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name}, ns); err != nil && apierrors.IsNotFound(err) {
+		ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		}}
+		if err := r.Client.Create(context.TODO(), ns); err != nil {
+			log.Error(err, fmt.Sprintf("Failed to create namespace %s", name))
+			return err
+		}
+	}
+	return nil
+}
+
 // labelsForMultiClusterMonitoring returns the labels for selecting the resources
 // belonging to the given MultiClusterObservability CR name.
 func labelsForMultiClusterMonitoring(name string) map[string]string {
@@ -280,12 +544,18 @@ func labelsForMultiClusterMonitoring(name string) map[string]string {
 func (r *MultiClusterObservabilityReconciler) initFinalization(
 	mco *mcov1beta2.MultiClusterObservability) (bool, error) {
 	if mco.GetDeletionTimestamp() != nil && util.Contains(mco.GetFinalizers(), resFinalizer) {
+		// wait for the observability to be removed from the managed clusters, the blockers are
+		// reported in the Uninstalling condition
+		waiting, err := r.updateUninstallStatus(mco)
+		if err != nil || waiting {
+			return true, err
+		}
 		log.Info("To delete resources across namespaces")
 		svmCrdExists, err := util.CheckCRDExist(r.CrdClient, config.StorageVersionMigrationCrdName)
 		if err != nil {
 			return false, err
 		}
-		if svmCrdExists {
+		if svmCrdExists && !config.IsNamespaceScoped() {
 			// remove the StorageVersionMigration resource and ignore error
 			cleanObservabilityStorageVersionMigrationResource(r.Client, mco)
 		}
@@ -346,6 +616,8 @@ func (r *MultiClusterObservabilityReconciler) SetupWithManager(mgr ctrl.Manager)
 		UpdateFunc: func(e event.UpdateEvent) bool {
 			checkStorageChanged(e.ObjectOld.(*mcov1beta2.MultiClusterObservability).Spec.StorageConfig,
 				e.ObjectNew.(*mcov1beta2.MultiClusterObservability).Spec.StorageConfig)
+			checkRetentionChanged(e.ObjectOld.(*mcov1beta2.MultiClusterObservability).Spec.RetentionConfig,
+				e.ObjectNew.(*mcov1beta2.MultiClusterObservability).Spec.RetentionConfig)
 			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
@@ -402,9 +674,18 @@ func (r *MultiClusterObservabilityReconciler) SetupWithManager(mgr ctrl.Manager)
 				config.SetCustomRuleConfigMap(true)
 				return true
 			}
+			// deploy the hub components with the user-supplied patches
+			if e.Object.GetName() == config.HubPatchesConfigMapName &&
+				e.Object.GetNamespace() == config.GetDefaultNamespace() {
+				return true
+			}
 			return false
 		},
 		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectNew.GetName() == config.HubPatchesConfigMapName &&
+				e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() {
+				return e.ObjectOld.GetResourceVersion() != e.ObjectNew.GetResourceVersion()
+			}
 			// Find a way to restart the alertmanager to take the update
 			// if e.ObjectNew.GetName() == config.AlertRuleCustomConfigMapName &&
 			// 	e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() {
@@ -419,15 +700,52 @@ func (r *MultiClusterObservabilityReconciler) SetupWithManager(mgr ctrl.Manager)
 				config.SetCustomRuleConfigMap(false)
 				return true
 			}
+			if e.Object.GetName() == config.HubPatchesConfigMapName &&
+				e.Object.GetNamespace() == config.GetDefaultNamespace() {
+				return true
+			}
 			return false
 		},
 	}
 
 	secretPred := predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
-			return false
+			// continue the reconcile once the External Secrets Operator syncs the secret, and add
+			// the datasource of the federated hub once its credentials are created
+			return e.Object.GetNamespace() == config.GetDefaultNamespace() &&
+				(config.IsExternalSecretOwned(e.Object) || federatedHubSecretNames[e.Object.GetName()])
 		},
 		UpdateFunc: func(e event.UpdateEvent) bool {
+			// re-read the secret once the External Secrets Operator refreshes it
+			if e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				config.IsExternalSecretOwned(e.ObjectNew) &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion() {
+				return true
+			}
+			// merge the AlertmanagerConfigs again once the admin updates the alertmanager configuration
+			if e.ObjectNew.GetName() == config.AlertmanagerConfigName &&
+				e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion() {
+				return true
+			}
+			// render the secret of the OIDC client into the gateway once the admin rotates it
+			if gatewayOIDCSecretName != "" && e.ObjectNew.GetName() == gatewayOIDCSecretName &&
+				e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion() {
+				return true
+			}
+			// update the datasources once the admin rotates the credentials of the federated hubs
+			if federatedHubSecretNames[e.ObjectNew.GetName()] &&
+				e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion() {
+				return true
+			}
+			// roll the thanos components once the admin rotates the object storage credentials
+			if e.ObjectNew.GetName() == config.GetObjStorageSecretName() &&
+				e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion() {
+				return true
+			}
 			return false
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
@@ -439,8 +757,96 @@ func (r *MultiClusterObservabilityReconciler) SetupWithManager(mgr ctrl.Manager)
 		},
 	}
 
+	sloPred := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// ignore the status updates of the FleetSLOs and the AlertmanagerConfigs
+			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+		},
+	}
+
+	sloMapFn := func(a client.Object) []reconcile.Request {
+		return []reconcile.Request{
+			{NamespacedName: types.NamespacedName{Name: config.GetMonitoringCRName()}},
+		}
+	}
+
+	addonVersionPred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return e.Object.GetNamespace() != config.GetDefaultNamespace()
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// report the version skew once the addon of a managed cluster is upgraded
+			return e.ObjectNew.GetNamespace() != config.GetDefaultNamespace() &&
+				e.ObjectNew.(*mcov1beta1.ObservabilityAddon).Status.Version !=
+					e.ObjectOld.(*mcov1beta1.ObservabilityAddon).Status.Version
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return e.Object.GetNamespace() != config.GetDefaultNamespace()
+		},
+	}
+
+	bucketVerifyJobPred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// report the result once the verification job of the cronjob finishes
+			return e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				e.ObjectNew.GetLabels()["app.kubernetes.io/name"] == bucketVerifyCronJobName &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion()
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+	}
+
+	metricsImportJobPred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// record the progress of the import jobs in the MetricsImports
+			return e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				e.ObjectNew.GetLabels()[metricsImportLabel] != "" &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion()
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return e.Object.GetNamespace() == config.GetDefaultNamespace() &&
+				e.Object.GetLabels()[metricsImportLabel] != ""
+		},
+	}
+
+	nodePred := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// validate the zones of the hub again once a node moves or is cordoned
+			oldNode := e.ObjectOld.(*corev1.Node)
+			newNode := e.ObjectNew.(*corev1.Node)
+			return !reflect.DeepEqual(oldNode.Labels, newNode.Labels) ||
+				oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable
+		},
+	}
+
+	routePred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// move the managed clusters to the new endpoint once the host of the route changes
+			return e.ObjectNew.GetName() == obsAPIGateway &&
+				e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				e.ObjectNew.(*routev1.Route).Spec.Host != e.ObjectOld.(*routev1.Route).Spec.Host
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+	}
+
+	// receive the watchdog alerts of the alerting self test from the alertmanager
+	watchdogs.client = mgr.GetClient()
+	mgr.GetWebhookServer().Register(config.AlertingSelfTestPath, watchdogs)
+
 	// create a new controller and start watch for relevant resources
-	return ctrl.NewControllerManagedBy(mgr).
+	ctrBuilder := ctrl.NewControllerManagedBy(mgr).
 		// Watch for changes to primary resource MultiClusterObservability with predicate
 		For(&mcov1beta2.MultiClusterObservability{}, builder.WithPredicates(mcoPred)).
 		// Watch for changes to secondary resource Deployment and requeue the owner Observatorium
@@ -453,14 +859,60 @@ func (r *MultiClusterObservabilityReconciler) SetupWithManager(mgr ctrl.Manager)
 		Owns(&corev1.Secret{}).
 		// Watch for changes to secondary resource Service and requeue the owner MultiClusterObservability
 		Owns(&corev1.Service{}).
+		// Watch for changes to secondary resource Job and requeue the owner MultiClusterObservability
+		Owns(&batchv1.Job{}).
 		// Watch for changes to secondary Observatorium CR and requeue the owner MultiClusterObservability
 		Owns(&observatoriumv1alpha1.Observatorium{}).
 		// Watch the configmap for thanos-ruler-custom-rules update
 		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForObject{}, builder.WithPredicates(cmPred)).
-		// Watch the secret for deleting event of alertmanager-config
+		// Watch the secret for updating and deleting event of alertmanager-config
 		Watches(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForObject{}, builder.WithPredicates(secretPred)).
-		// actually create the controller with the reconciler
-		Complete(r)
+		// Watch the FleetSLOs and requeue the MultiClusterObservability to regenerate the rules
+		Watches(&source.Kind{Type: &mcov1beta2.FleetSLO{}}, handler.EnqueueRequestsFromMapFunc(sloMapFn),
+			builder.WithPredicates(sloPred)).
+		// Watch the AlertmanagerConfigs and requeue the MultiClusterObservability to merge them
+		Watches(&source.Kind{Type: &mcov1beta2.AlertmanagerConfig{}}, handler.EnqueueRequestsFromMapFunc(sloMapFn),
+			builder.WithPredicates(sloPred)).
+		// Watch the versions of the ObservabilityAddons to report the version skew and open the upgrade gate
+		Watches(&source.Kind{Type: &mcov1beta1.ObservabilityAddon{}}, handler.EnqueueRequestsFromMapFunc(sloMapFn),
+			builder.WithPredicates(addonVersionPred)).
+		// Watch the jobs of the bucket verify cronjob to report the health of the blocks
+		Watches(&source.Kind{Type: &batchv1.Job{}}, handler.EnqueueRequestsFromMapFunc(sloMapFn),
+			builder.WithPredicates(bucketVerifyJobPred)).
+		// Watch the nodes to validate the zones of the highly available topology
+		Watches(&source.Kind{Type: &corev1.Node{}}, handler.EnqueueRequestsFromMapFunc(sloMapFn),
+			builder.WithPredicates(nodePred)).
+		// Watch the route of the observatorium api to detect the change of its host
+		Watches(&source.Kind{Type: &routev1.Route{}}, handler.EnqueueRequestsFromMapFunc(sloMapFn),
+			builder.WithPredicates(routePred)).
+		// Watch the MetricsImports and their jobs to run the imports and record their progress
+		Watches(&source.Kind{Type: &mcov1beta2.MetricsImport{}}, handler.EnqueueRequestsFromMapFunc(sloMapFn),
+			builder.WithPredicates(sloPred)).
+		Watches(&source.Kind{Type: &batchv1.Job{}}, handler.EnqueueRequestsFromMapFunc(sloMapFn),
+			builder.WithPredicates(metricsImportJobPred))
+
+	managedClusterGroupKind := schema.GroupKind{Group: clusterv1.GroupVersion.Group, Kind: "ManagedCluster"}
+	if _, err := mgr.GetRESTMapper().RESTMapping(managedClusterGroupKind, clusterv1.GroupVersion.Version); err == nil {
+		clusterPred := predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return e.Object.GetAnnotations()[config.ProxyEndpointAnnotation] != ""
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				// reissue the server certificate once the proxy endpoint of a managed cluster changes
+				return e.ObjectNew.GetAnnotations()[config.ProxyEndpointAnnotation] !=
+					e.ObjectOld.GetAnnotations()[config.ProxyEndpointAnnotation]
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				return e.Object.GetAnnotations()[config.ProxyEndpointAnnotation] != ""
+			},
+		}
+		// Watch the managedclusters to issue the server certificate for their cluster proxies
+		ctrBuilder = ctrBuilder.Watches(&source.Kind{Type: &clusterv1.ManagedCluster{}},
+			handler.EnqueueRequestsFromMapFunc(sloMapFn), builder.WithPredicates(clusterPred))
+	}
+
+	// actually create the controller with the reconciler
+	return ctrBuilder.Complete(r)
 }
 
 func updateObservatoriumReplicas(objectNew, objectOld client.Object, watchedType string) bool {
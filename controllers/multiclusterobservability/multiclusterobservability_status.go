@@ -44,10 +44,13 @@ func updateReadyStatus(
 		return
 	}
 
-	objStorageStatus := checkObjStorageStatus(c, mco)
-	if objStorageStatus != nil {
-		setStatusCondition(conditions, *objStorageStatus)
-		return
+	// the object storage is not used when the metrics are stored in the external metrics store
+	if !config.IsExternalMetricsStoreEnabled(mco) {
+		objStorageStatus := checkObjStorageStatus(c, mco)
+		if objStorageStatus != nil {
+			setStatusCondition(conditions, *objStorageStatus)
+			return
+		}
 	}
 
 	deployStatus := checkDeployStatus(c, mco)
@@ -148,12 +151,23 @@ func getExpectedDeploymentNames(mcoCRName string) []string {
 	}
 }
 
+// getHubDeploymentNames returns the deployments of the observability components on the hub
+func getHubDeploymentNames(mco *mcov1beta2.MultiClusterObservability) []string {
+	mcoCRName := config.GetMonitoringCRName()
+	if config.IsExternalMetricsStoreEnabled(mco) {
+		// the observatorium and thanos are not deployed on the hub
+		return []string{
+			mcoCRName + "-" + config.Grafana,
+			mcoCRName + "-" + config.RbacQueryProxy,
+		}
+	}
+	return getExpectedDeploymentNames(mcoCRName)
+}
+
 func checkDeployStatus(
 	c client.Client,
 	mco *mcov1beta2.MultiClusterObservability) *mcoshared.Condition {
-	mcoCRName := config.GetMonitoringCRName()
-	expectedDeploymentNames := getExpectedDeploymentNames(mcoCRName)
-	for _, name := range expectedDeploymentNames {
+	for _, name := range getHubDeploymentNames(mco) {
 		found := &appsv1.Deployment{}
 		namespacedName := types.NamespacedName{
 			Name:      name,
@@ -185,11 +199,18 @@ func getExpectedStatefulSetNames(mcoCRName string) []string {
 	}
 }
 
+// getHubStatefulSetNames returns the statefulsets of the observability components on the hub
+func getHubStatefulSetNames(mco *mcov1beta2.MultiClusterObservability) []string {
+	if config.IsExternalMetricsStoreEnabled(mco) {
+		return []string{config.GetMonitoringCRName() + "-" + config.Alertmanager}
+	}
+	return getExpectedStatefulSetNames(config.GetMonitoringCRName())
+}
+
 func checkStatefulSetStatus(
 	c client.Client,
 	mco *mcov1beta2.MultiClusterObservability) *mcoshared.Condition {
-	expectedStatefulSetNames := getExpectedStatefulSetNames(config.GetMonitoringCRName())
-	for _, name := range expectedStatefulSetNames {
+	for _, name := range getHubStatefulSetNames(mco) {
 		found := &appsv1.StatefulSet{}
 		namespacedName := types.NamespacedName{
 			Name:      name,
@@ -231,6 +252,15 @@ func checkObjStorageStatus(
 		return newFailedCondition("ObjectStorageConfInvalid", msg)
 	}
 
+	if config.IsObjStorageConfGenerated(mco) {
+		// the static keys are not required with the workload identity, and the encryption is validated
+		_, err = config.GenerateObjStorageConf(data, mco.Spec.StorageConfig)
+		if err != nil {
+			return newFailedCondition("ObjectStorageConfInvalid", err.Error())
+		}
+		return nil
+	}
+
 	ok, err = config.CheckObjStorageConf(data)
 	if !ok {
 		return newFailedCondition("ObjectStorageConfInvalid", err.Error())
@@ -0,0 +1,92 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// GenerateObjStorageConfig generates the object storage configuration of the thanos components from
+// the metricObjectStorage secret with the workload identity and the server-side encryption, and
+// binds the service accounts of the thanos components to the cloud identity
+func GenerateObjStorageConfig(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	namespace := mcoconfig.GetDefaultNamespace()
+	if !mcoconfig.IsObjStorageConfGenerated(mco) || mcoconfig.IsExternalMetricsStoreEnabled(mco) {
+		err := deleteResources(c, []client.Object{
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:      mcoconfig.GeneratedObjStorageSecretName,
+				Namespace: namespace,
+			}},
+		})
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+		return nil, nil
+	}
+
+	objStorageConf := mco.Spec.StorageConfig.MetricObjectStorage
+	if objStorageConf == nil {
+		return &ctrl.Result{}, fmt.Errorf("no metricObjectStorage for the generated object storage configuration")
+	}
+	found := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: objStorageConf.Name, Namespace: namespace}, found)
+	if err != nil {
+		log.Error(err, "Failed to get the object storage secret", "name", objStorageConf.Name)
+		return &ctrl.Result{}, err
+	}
+	data, err := mcoconfig.GenerateObjStorageConf(found.Data[objStorageConf.Key], mco.Spec.StorageConfig)
+	if err != nil {
+		log.Error(err, "Invalid object storage configuration")
+		return &ctrl.Result{}, err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mcoconfig.GeneratedObjStorageSecretName,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			mcoconfig.GeneratedObjStorageKey: data,
+		},
+	}
+	if err = controllerutil.SetControllerReference(mco, secret, scheme); err != nil {
+		return &ctrl.Result{}, err
+	}
+	found = &corev1.Secret{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: secret.Name, Namespace: namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating the generated object storage secret", "name", secret.Name)
+		err = c.Create(context.TODO(), secret)
+	} else if err == nil && string(found.Data[mcoconfig.GeneratedObjStorageKey]) != string(data) {
+		log.Info("Updating the generated object storage secret", "name", secret.Name)
+		found.Data = secret.Data
+		err = c.Update(context.TODO(), found)
+	}
+	if err != nil {
+		log.Error(err, "Failed to create or update the generated object storage secret")
+		return &ctrl.Result{}, err
+	}
+
+	if mcoconfig.IsWorkloadIdentityEnabled(mco) {
+		err = bindWorkloadIdentity(c, scheme, mco)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+	return nil, nil
+}
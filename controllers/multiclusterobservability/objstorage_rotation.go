@@ -0,0 +1,83 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+// objStorageHashAnnotation is the annotation of the pod templates of the thanos components with the
+// hash of the object storage configuration, a new hash rolls the pods with the rotated credentials
+const objStorageHashAnnotation = "observability.open-cluster-management.io/object-storage-hash"
+
+// the thanos components which mount the object storage configuration
+var objStorageComponents = []string{"thanos-compact", "thanos-receive", "thanos-rule", "thanos-store"}
+
+// RolloutObjStorageSecret restarts the thanos components with a rolling update once the secret of
+// the object storage configuration is rotated, thanos only reads the credentials when it starts
+func RolloutObjStorageSecret(c client.Client, mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	if mcoconfig.IsExternalMetricsStoreEnabled(mco) ||
+		mco.Spec.StorageConfig == nil || mco.Spec.StorageConfig.MetricObjectStorage == nil {
+		mcoconfig.SetObjStorageSecretName("")
+		return nil, nil
+	}
+	// watch the secret which the admin rotates, the generated secret is owned by the
+	// MultiClusterObservability
+	mcoconfig.SetObjStorageSecretName(mco.Spec.StorageConfig.MetricObjectStorage.Name)
+
+	objStorageConfig := newThanosObjStorageConfig(mco)
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      objStorageConfig.Name,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// the status reports the missing secret
+			return nil, nil
+		}
+		log.Error(err, "Failed to get the object storage secret", "name", objStorageConfig.Name)
+		return &ctrl.Result{}, err
+	}
+	sum := sha256.Sum256(secret.Data[objStorageConfig.Key])
+	hash := hex.EncodeToString(sum[:])
+
+	for _, component := range objStorageComponents {
+		stsList, err := util.GetStatefulSetList(c, map[string]string{
+			"app.kubernetes.io/instance": mco.GetName(),
+			"app.kubernetes.io/name":     component,
+		})
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+		for index, sts := range stsList {
+			if sts.Spec.Template.Annotations[objStorageHashAnnotation] == hash {
+				continue
+			}
+			if stsList[index].Spec.Template.Annotations == nil {
+				stsList[index].Spec.Template.Annotations = map[string]string{}
+			}
+			stsList[index].Spec.Template.Annotations[objStorageHashAnnotation] = hash
+			log.Info("Rolling the statefulset with the object storage configuration", "name", sts.Name)
+			err = c.Update(context.TODO(), &stsList[index])
+			if err != nil {
+				log.Error(err, "Failed to roll the statefulset", "name", sts.Name)
+				return &ctrl.Result{}, err
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestRolloutObjStorageSecret(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	namespace := mcoconfig.GetDefaultNamespace()
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			StorageConfig: &mcov1beta2.StorageConfig{
+				MetricObjectStorage: &mcoshared.PreConfiguredStorage{
+					Name: "thanos-object-storage",
+					Key:  "thanos.yaml",
+				},
+			},
+		},
+	}
+	objStorage := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "thanos-object-storage", Namespace: namespace},
+		Data:       map[string][]byte{"thanos.yaml": []byte("secret_key: old")},
+	}
+	newStatefulSet := func(name string, component string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/instance": mco.Name,
+					"app.kubernetes.io/name":     component,
+				},
+			},
+		}
+	}
+	c := fake.NewFakeClient(objStorage,
+		newStatefulSet("observability-thanos-compact", "thanos-compact"),
+		newStatefulSet("observability-thanos-store-shard-0", "thanos-store"),
+		newStatefulSet("observability-grafana", "grafana"))
+
+	getHash := func(name string) string {
+		sts := &appsv1.StatefulSet{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, sts)
+		if err != nil {
+			t.Fatalf("Failed to get the statefulset %s: (%v)", name, err)
+		}
+		return sts.Spec.Template.Annotations[objStorageHashAnnotation]
+	}
+
+	_, err := RolloutObjStorageSecret(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to roll out the object storage secret: (%v)", err)
+	}
+	if mcoconfig.GetObjStorageSecretName() != objStorage.Name {
+		t.Fatalf("The object storage secret should be watched: %s", mcoconfig.GetObjStorageSecretName())
+	}
+	oldHash := getHash("observability-thanos-compact")
+	if oldHash == "" || getHash("observability-thanos-store-shard-0") != oldHash {
+		t.Fatalf("The thanos components should be annotated with the hash of the object storage secret")
+	}
+	if getHash("observability-grafana") != "" {
+		t.Fatalf("The components without the object storage should not be annotated")
+	}
+
+	objStorage.Data["thanos.yaml"] = []byte("secret_key: new")
+	err = c.Update(context.TODO(), objStorage)
+	if err != nil {
+		t.Fatalf("Failed to rotate the object storage secret: (%v)", err)
+	}
+	_, err = RolloutObjStorageSecret(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to roll out the object storage secret: (%v)", err)
+	}
+	newHash := getHash("observability-thanos-compact")
+	if newHash == oldHash || getHash("observability-thanos-store-shard-0") != newHash {
+		t.Fatalf("The thanos components should be rolled with the rotated secret")
+	}
+}
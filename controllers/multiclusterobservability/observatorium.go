@@ -40,6 +40,13 @@ func GenerateObservatoriumCR(
 	cl client.Client, scheme *runtime.Scheme,
 	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
 
+	if mcoconfig.IsExternalMetricsStoreEnabled(mco) {
+		if err := deleteStorageStack(cl, mco); err != nil {
+			return &ctrl.Result{}, err
+		}
+		return nil, nil
+	}
+
 	labels := map[string]string{
 		"app": mco.Name,
 	}
@@ -59,6 +66,9 @@ func GenerateObservatoriumCR(
 		},
 		Spec: *newDefaultObservatoriumSpec(mco, storageClassSelected),
 	}
+	if err := setGatewayAuth(cl, mco, &observatoriumCR.Spec); err != nil {
+		return &ctrl.Result{}, err
+	}
 
 	// Set MultiClusterObservability instance as the owner and controller
 	if err := controllerutil.SetControllerReference(mco, observatoriumCR, scheme); err != nil {
@@ -144,6 +154,11 @@ func GenerateAPIGatewayRoute(
 	runclient client.Client, scheme *runtime.Scheme,
 	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
 
+	if mcoconfig.IsExternalMetricsStoreEnabled(mco) {
+		// the managed clusters write to the external metrics store directly
+		return nil, nil
+	}
+
 	apiGateway := &routev1.Route{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      obsAPIGateway,
@@ -194,6 +209,9 @@ func newDefaultObservatoriumSpec(mco *mcov1beta2.MultiClusterObservability,
 	obs.SecurityContext = &v1.SecurityContext{}
 	obs.NodeSelector = mco.Spec.NodeSelector
 	obs.Tolerations = mco.Spec.Tolerations
+	if mcoconfig.IsHighAvailabilityEnabled(mco) {
+		obs.Affinity = newHAAffinity(mco)
+	}
 	obs.API = newAPISpec(mco)
 	obs.Thanos = newThanosSpec(mco, scSelected)
 
@@ -201,13 +219,50 @@ func newDefaultObservatoriumSpec(mco *mcov1beta2.MultiClusterObservability,
 		{Hashring: "default", Tenants: []string{mcoconfig.GetTenantUID()}},
 	}
 
-	obs.ObjectStorageConfig.Thanos = &obsv1alpha1.ThanosObjectStorageConfigSpec{}
+	obs.ObjectStorageConfig.Thanos = newThanosObjStorageConfig(mco)
+	return obs
+}
+
+// newHAAffinity spreads the replicas of thanos receive and store across the zones, the affinity of
+// the Observatorium CR applies to all the thanos components so that the spread is only preferred
+func newHAAffinity(mco *mcov1beta2.MultiClusterObservability) *v1.Affinity {
+	terms := []v1.WeightedPodAffinityTerm{}
+	for _, component := range []string{"thanos-receive", "thanos-store"} {
+		terms = append(terms, v1.WeightedPodAffinityTerm{
+			Weight: 100,
+			PodAffinityTerm: v1.PodAffinityTerm{
+				TopologyKey: mcoconfig.GetHAZoneLabel(mco),
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"app.kubernetes.io/name":     component,
+						"app.kubernetes.io/instance": mco.Name,
+					},
+				},
+			},
+		})
+	}
+	return &v1.Affinity{
+		PodAntiAffinity: &v1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: terms,
+		},
+	}
+}
+
+// newThanosObjStorageConfig returns the secret of the object storage configuration which the
+// thanos components mount
+func newThanosObjStorageConfig(mco *mcov1beta2.MultiClusterObservability) *obsv1alpha1.ThanosObjectStorageConfigSpec {
+	objStorageConfig := &obsv1alpha1.ThanosObjectStorageConfigSpec{}
 	if mco.Spec.StorageConfig != nil && mco.Spec.StorageConfig.MetricObjectStorage != nil {
 		objStorageConf := mco.Spec.StorageConfig.MetricObjectStorage
-		obs.ObjectStorageConfig.Thanos.Name = objStorageConf.Name
-		obs.ObjectStorageConfig.Thanos.Key = objStorageConf.Key
+		objStorageConfig.Name = objStorageConf.Name
+		objStorageConfig.Key = objStorageConf.Key
 	}
-	return obs
+	if mcoconfig.IsObjStorageConfGenerated(mco) {
+		// the configuration with the workload identity and the encryption
+		objStorageConfig.Name = mcoconfig.GeneratedObjStorageSecretName
+		objStorageConfig.Key = mcoconfig.GeneratedObjStorageKey
+	}
+	return objStorageConfig
 }
 
 func newAPIRBAC() obsv1alpha1.APIRBAC {
@@ -327,6 +382,13 @@ func newReceiversSpec(
 	} else {
 		receSpec.ReplicationFactor = &config.Replicas3
 	}
+	if mcoconfig.IsHighAvailabilityEnabled(mco) {
+		replicationFactor := mcoconfig.GetHAReplicationFactor(mco)
+		receSpec.ReplicationFactor = &replicationFactor
+		if *receSpec.Replicas < replicationFactor {
+			receSpec.Replicas = &replicationFactor
+		}
+	}
 
 	receSpec.ServiceMonitor = true
 	if !mcoconfig.WithoutResourcesRequests(mco.GetAnnotations()) {
@@ -402,6 +464,29 @@ func newRuleSpec(mco *mcov1beta2.MultiClusterObservability, scSelected string) o
 			},
 		}
 	}
+	// the rules translated from the FleetSLOs
+	ruleSpec.RulesConfig = append(ruleSpec.RulesConfig, obsv1alpha1.RuleConfig{
+		Name: mcoconfig.AlertRuleSLOConfigMapName,
+		Key:  mcoconfig.AlertRuleSLOFileKey,
+	})
+	if mco.Spec.EnableCostAggregation {
+		ruleSpec.RulesConfig = append(ruleSpec.RulesConfig, obsv1alpha1.RuleConfig{
+			Name: mcoconfig.AlertRuleCostConfigMapName,
+			Key:  mcoconfig.AlertRuleCostFileKey,
+		})
+	}
+	if mcoconfig.IsCardinalityGuardEnabled(mco) {
+		ruleSpec.RulesConfig = append(ruleSpec.RulesConfig, obsv1alpha1.RuleConfig{
+			Name: mcoconfig.AlertRuleCardinalityConfigMapName,
+			Key:  mcoconfig.AlertRuleCardinalityFileKey,
+		})
+	}
+	if mco.Spec.EnableAlertingSelfTest {
+		ruleSpec.RulesConfig = append(ruleSpec.RulesConfig, obsv1alpha1.RuleConfig{
+			Name: mcoconfig.AlertRuleWatchdogConfigMapName,
+			Key:  mcoconfig.AlertRuleWatchdogFileKey,
+		})
+	}
 
 	return ruleSpec
 }
@@ -470,7 +555,7 @@ func newMemCacheSpec(mco *mcov1beta2.MultiClusterObservability) obsv1alpha1.MemC
 
 func newThanosSpec(mco *mcov1beta2.MultiClusterObservability, scSelected string) obsv1alpha1.ThanosSpec {
 	thanosSpec := obsv1alpha1.ThanosSpec{}
-	thanosSpec.Image = mcoconfig.DefaultImgRepository + "/" + mcoconfig.ThanosImgName + ":" + mcoconfig.ThanosImgTag
+	thanosSpec.Image = getThanosImage(mco)
 	thanosSpec.Version = mcoconfig.ThanosImgTag
 
 	thanosSpec.Compact = newCompactSpec(mco, scSelected)
@@ -480,12 +565,17 @@ func newThanosSpec(mco *mcov1beta2.MultiClusterObservability, scSelected string)
 	thanosSpec.ReceiveController = newReceiverControllerSpec(mco)
 	thanosSpec.Query = newQuerySpec(mco)
 	thanosSpec.QueryFrontend = newQueryFrontendSpec(mco)
+	return thanosSpec
+}
 
-	replace, image := mcoconfig.ReplaceImage(mco.Annotations, thanosSpec.Image, mcoconfig.ThanosImgName)
+// getThanosImage returns the image of the thanos components
+func getThanosImage(mco *mcov1beta2.MultiClusterObservability) string {
+	image := mcoconfig.DefaultImgRepository + "/" + mcoconfig.ThanosImgName + ":" + mcoconfig.ThanosImgTag
+	replace, replacedImage := mcoconfig.ReplaceImage(mco.Annotations, image, mcoconfig.ThanosImgName)
 	if replace {
-		thanosSpec.Image = image
+		return replacedImage
 	}
-	return thanosSpec
+	return image
 }
 
 func newQueryFrontendSpec(mco *mcov1beta2.MultiClusterObservability) obsv1alpha1.QueryFrontendSpec {
@@ -547,6 +637,10 @@ func newCompactSpec(mco *mcov1beta2.MultiClusterObservability, scSelected string
 	//Compactor, generally, does not need to be highly available.
 	//Compactions are needed from time to time, only when new blocks appear.
 	compactSpec.Replicas = &mcoconfig.Replicas1
+	if retentionCleanupRunning {
+		// the compactor must not run on the bucket with the cleanup job at the same time
+		compactSpec.Replicas = &mcoconfig.Replicas0
+	}
 	if !mcoconfig.WithoutResourcesRequests(mco.GetAnnotations()) {
 		compactSpec.Resources = v1.ResourceRequirements{
 			Requests: v1.ResourceList{
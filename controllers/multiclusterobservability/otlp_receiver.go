@@ -0,0 +1,121 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// GenerateOTLPReceiverRoute exposes the otlp receiver when it is enabled,
+// or removes the otlp receiver resources when it is disabled
+func GenerateOTLPReceiverRoute(
+	runclient client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	if !mcoconfig.IsOTLPReceiverEnabled(mco) {
+		return nil, deleteOTLPReceiver(runclient, mco)
+	}
+
+	otlpReceiver := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mcoconfig.OTLPReceiver,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+		},
+		Spec: routev1.RouteSpec{
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString("otlp-grpc"),
+			},
+			To: routev1.RouteTargetReference{
+				Kind: "Service",
+				Name: mcoconfig.OTLPReceiver,
+			},
+			// the otlp receiver terminates the mTLS itself
+			TLS: &routev1.TLSConfig{
+				Termination:                   routev1.TLSTerminationPassthrough,
+				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyNone,
+			},
+		},
+	}
+
+	// Set MultiClusterObservability instance as the owner and controller
+	if err := controllerutil.SetControllerReference(mco, otlpReceiver, scheme); err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	err := runclient.Get(
+		context.TODO(),
+		types.NamespacedName{Name: otlpReceiver.Name, Namespace: otlpReceiver.Namespace},
+		&routev1.Route{})
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating a new route to expose otlp receiver",
+			"otlpReceiver.Namespace", otlpReceiver.Namespace,
+			"otlpReceiver.Name", otlpReceiver.Name,
+		)
+		err = runclient.Create(context.TODO(), otlpReceiver)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+
+	return nil, nil
+}
+
+func deleteOTLPReceiver(c client.Client, mco *mcov1beta2.MultiClusterObservability) error {
+	namespace := mcoconfig.GetDefaultNamespace()
+	objs := []client.Object{
+		&routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.OTLPReceiver, Namespace: namespace},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: mco.Name + "-" + mcoconfig.OTLPReceiver, Namespace: namespace},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.OTLPReceiver, Namespace: namespace},
+		},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.OTLPReceiver + "-config", Namespace: namespace},
+		},
+		&v1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.OTLPReceiver, Namespace: namespace},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.OTLPReceiverCerts, Namespace: namespace},
+		},
+	}
+	return deleteResources(c, objs)
+}
+
+// deleteResources deletes the resources of an optional component which is disabled
+func deleteResources(c client.Client, objs []client.Object) error {
+	for _, obj := range objs {
+		err := c.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err == nil {
+			log.Info("Deleting the resource of the disabled component",
+				"kind", obj.GetObjectKind().GroupVersionKind().Kind, "name", obj.GetName())
+			err = c.Delete(context.TODO(), obj)
+		}
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete the resource of the disabled component", "name", obj.GetName())
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestGenerateOTLPReceiverRoute(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	routev1.AddToScheme(s)
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			EnableOTLPReceiver: true,
+		},
+	}
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mco.Name + "-" + mcoconfig.OTLPReceiver,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+		},
+	}
+	c := fake.NewFakeClient(mco, dep)
+	key := types.NamespacedName{Name: mcoconfig.OTLPReceiver, Namespace: mcoconfig.GetDefaultNamespace()}
+
+	_, err := GenerateOTLPReceiverRoute(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the otlp receiver route: (%v)", err)
+	}
+	route := &routev1.Route{}
+	err = c.Get(context.TODO(), key, route)
+	if err != nil {
+		t.Fatalf("Failed to get the otlp receiver route: (%v)", err)
+	}
+	if route.Spec.TLS.Termination != routev1.TLSTerminationPassthrough {
+		t.Fatalf("The otlp receiver route should be passthrough: %v", route.Spec.TLS.Termination)
+	}
+
+	mco.Spec.EnableOTLPReceiver = false
+	_, err = GenerateOTLPReceiverRoute(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to remove the otlp receiver: (%v)", err)
+	}
+	err = c.Get(context.TODO(), key, &routev1.Route{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The otlp receiver route is not deleted: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: dep.Name, Namespace: dep.Namespace}, &appsv1.Deployment{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The otlp receiver deployment is not deleted: (%v)", err)
+	}
+}
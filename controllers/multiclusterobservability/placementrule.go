@@ -84,3 +84,29 @@ func createPlacementRule(client client.Client, scheme *runtime.Scheme,
 	log.Info("PlacementRule already existed", "name", name)
 	return nil
 }
+
+// deleteDefaultPlacementRule deletes the default PlacementRule which the operator created, once the
+// managed clusters are selected by a user-managed placement. The placement is kept if it is not
+// owned by the MultiClusterObservability, e.g. the user references a rule with the same name.
+func deleteDefaultPlacementRule(c client.Client, mco *mcov1beta2.MultiClusterObservability) error {
+	name := config.GetPlacementRuleName()
+	found := &appsv1.PlacementRule{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: config.GetDefaultNamespace()}, found)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		log.Error(err, "Failed to check PlacementRule", "name", name)
+		return err
+	}
+	if !metav1.IsControlledBy(found, mco) {
+		return nil
+	}
+	log.Info("Deleting the default PlacementRule", "name", name)
+	err = c.Delete(context.TODO(), found)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to delete PlacementRule", "name", name)
+		return err
+	}
+	return nil
+}
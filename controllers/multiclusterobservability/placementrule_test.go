@@ -7,6 +7,7 @@ import (
 	"context"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -77,3 +78,51 @@ func TestCreatePlacementRule(t *testing.T) {
 	}
 
 }
+
+func TestDeleteDefaultPlacementRule(t *testing.T) {
+	namespace := mcoconfig.GetDefaultNamespace()
+	pName := mcoconfig.GetPlacementRuleName()
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "observability", UID: "mco-uid"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			PlacementRef: &mcov1beta2.PlacementRefSpec{Name: pName},
+		},
+	}
+
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	appsv1.SchemeBuilder.AddToScheme(s)
+
+	// the placementrule of the user with the same name is kept
+	p := &appsv1.PlacementRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pName,
+			Namespace: namespace,
+		},
+	}
+	c := fake.NewFakeClient(p)
+	err := deleteDefaultPlacementRule(c, mco)
+	if err != nil {
+		t.Fatalf("deleteDefaultPlacementRule: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: pName, Namespace: namespace}, &appsv1.PlacementRule{})
+	if err != nil {
+		t.Fatalf("The placementrule of the user is deleted: (%v)", err)
+	}
+
+	// the placementrule created by the operator is deleted
+	c = fake.NewFakeClient()
+	err = createPlacementRule(c, s, mco)
+	if err != nil {
+		t.Fatalf("createPlacementRule: (%v)", err)
+	}
+	err = deleteDefaultPlacementRule(c, mco)
+	if err != nil {
+		t.Fatalf("deleteDefaultPlacementRule: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: pName, Namespace: namespace}, &appsv1.PlacementRule{})
+	if err == nil || !errors.IsNotFound(err) {
+		t.Fatalf("The default placementrule is not deleted: (%v)", err)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// GenerateHubPriorityClass creates the priority class of the observability components on the hub
+// before they are deployed, the pods which refer to a missing priority class are rejected. The
+// priority class is deleted when it is not assigned or an existing one is used instead.
+func GenerateHubPriorityClass(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	priorityClass := mcoconfig.NewHubPriorityClass(mco)
+	if priorityClass == nil {
+		err := deleteResources(c, []client.Object{
+			&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.HubPriorityClassName}},
+		})
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+		return nil, nil
+	}
+
+	if err := controllerutil.SetControllerReference(mco, priorityClass, scheme); err != nil {
+		return &ctrl.Result{}, err
+	}
+	found := &schedulingv1.PriorityClass{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: priorityClass.Name}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get the priority class", "name", priorityClass.Name)
+		return &ctrl.Result{}, err
+	}
+	if err == nil {
+		if found.Value == priorityClass.Value {
+			return nil, nil
+		}
+		// the value of a priority class is immutable, the running pods keep their priority until
+		// they are recreated
+		log.Info("Recreating the priority class with the new value", "name", found.Name,
+			"previous", found.Value, "new", priorityClass.Value)
+		err = c.Delete(context.TODO(), found)
+		if err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete the priority class", "name", found.Name)
+			return &ctrl.Result{}, err
+		}
+	}
+	log.Info("Creating the priority class", "name", priorityClass.Name, "value", priorityClass.Value)
+	err = c.Create(context.TODO(), priorityClass)
+	if err != nil {
+		log.Error(err, "Failed to create the priority class", "name", priorityClass.Name)
+		return &ctrl.Result{}, err
+	}
+	return nil, nil
+}
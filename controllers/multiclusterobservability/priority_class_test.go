@@ -0,0 +1,63 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"testing"
+
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestGenerateHubPriorityClass(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			PriorityClasses: &mcov1beta2.PriorityClassesSpec{Hub: &mcov1beta2.PriorityClassSpec{}},
+		},
+	}
+	c := fake.NewFakeClient(mco)
+
+	_, err := GenerateHubPriorityClass(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the priority class: (%v)", err)
+	}
+	found := &schedulingv1.PriorityClass{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: mcoconfig.HubPriorityClassName}, found)
+	if err != nil || found.Value != mcoconfig.DefaultPriorityClassValue {
+		t.Fatalf("The priority class should be created with the default value: %v (%v)", found.Value, err)
+	}
+
+	mco.Spec.PriorityClasses.Hub.Value = 2000000
+	_, err = GenerateHubPriorityClass(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the priority class: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: mcoconfig.HubPriorityClassName}, found)
+	if err != nil || found.Value != 2000000 {
+		t.Errorf("The priority class should be recreated with the new value: %v (%v)", found.Value, err)
+	}
+
+	mco.Spec.PriorityClasses.Hub.Name = "system-cluster-critical"
+	_, err = GenerateHubPriorityClass(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the priority class: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: mcoconfig.HubPriorityClassName}, found)
+	if !errors.IsNotFound(err) {
+		t.Errorf("The priority class should be deleted when an existing one is used: (%v)", err)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/certificates"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// proxyDrainInterval is the interval in which the hosts of the proxies which are no longer used
+// are checked until every managed cluster moves off them
+const proxyDrainInterval = time.Minute
+
+// HandleProxyEndpointChange keeps the server certificate of the observatorium api issued for the hosts
+// of the cluster proxies which the managed clusters are annotated with. The certificate is reissued for
+// a new proxy before the managed clusters write through it, and the host of a proxy is only removed
+// once no manifestwork refers to it and every manifestwork is applied, so the managed clusters which
+// move between the direct and the proxied path are not interrupted.
+func HandleProxyEndpointChange(c client.Client, mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	if mcoconfig.IsExternalMetricsStoreEnabled(mco) {
+		return nil, nil
+	}
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.ServerCerts,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		log.Error(err, "Failed to get the server certificate secret")
+		return &ctrl.Result{}, err
+	}
+	endpoint := secret.Annotations[mcoconfig.ObsAPIEndpointAnnotation]
+	if endpoint == "" {
+		// the endpoint of the route is recorded first
+		return nil, nil
+	}
+
+	desired, err := getDesiredProxyHosts(c)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	inUse, applied, err := getProxyHostsInUse(c)
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	hosts := append([]string{}, desired...)
+	draining := []string{}
+	for _, host := range mcoconfig.GetServerCertProxyHosts(secret) {
+		if contains(hosts, host) {
+			continue
+		}
+		if inUse[host] || !applied {
+			hosts = append(hosts, host)
+			draining = append(draining, host)
+		}
+	}
+	sort.Strings(hosts)
+
+	if strings.Join(hosts, ",") != secret.Annotations[mcoconfig.ObsAPIProxyEndpointsAnnotation] {
+		log.Info("The proxy endpoints of the managed clusters changed", "hosts", hosts)
+		endpoints := append([]string{endpoint}, getPreviousEndpoints(secret)...)
+		err = certificates.ReissueServerCerts(c, mco, endpoints, map[string]string{
+			mcoconfig.ObsAPIProxyEndpointsAnnotation: strings.Join(hosts, ","),
+		})
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+	if len(draining) > 0 {
+		log.Info("Serving the previous proxy endpoints until the managed clusters move off them", "hosts", draining)
+		return &ctrl.Result{RequeueAfter: proxyDrainInterval}, nil
+	}
+	return nil, nil
+}
+
+// getDesiredProxyHosts returns the sorted hosts of the proxy endpoints of the managed clusters
+func getDesiredProxyHosts(c client.Client) ([]string, error) {
+	clusters := &clusterv1.ManagedClusterList{}
+	err := c.List(context.TODO(), clusters)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		log.Error(err, "Failed to list the managedclusters")
+		return nil, err
+	}
+	hosts := []string{}
+	for _, cluster := range clusters.Items {
+		endpoint := cluster.GetAnnotations()[mcoconfig.ProxyEndpointAnnotation]
+		if host := mcoconfig.GetProxyHost(endpoint); host != "" && !contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts, nil
+}
+
+// getProxyHostsInUse returns the hosts of the proxies which the manifestworks of the managed clusters
+// are generated with, and whether every manifestwork is applied
+func getProxyHostsInUse(c client.Client) (map[string]bool, bool, error) {
+	works := &workv1.ManifestWorkList{}
+	err := c.List(context.TODO(), works, client.MatchingLabels{workOwnerLabelKey: workOwnerLabelValue})
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, true, nil
+		}
+		log.Error(err, "Failed to list the manifestworks")
+		return nil, false, err
+	}
+	inUse := map[string]bool{}
+	applied := true
+	for _, work := range works.Items {
+		if endpoint := work.Annotations[mcoconfig.ProxyEndpointAnnotation]; endpoint != "" {
+			inUse[mcoconfig.GetProxyHost(endpoint)] = true
+		}
+		if !meta.IsStatusConditionTrue(work.Status.Conditions, workv1.WorkApplied) {
+			applied = false
+		}
+	}
+	return inUse, applied, nil
+}
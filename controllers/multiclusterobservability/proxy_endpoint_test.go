@@ -0,0 +1,120 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/certificates"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestHandleProxyEndpointChange(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	routev1.AddToScheme(s)
+	workv1.AddToScheme(s)
+	clusterv1.AddToScheme(s)
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+	}
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      obsAPIGateway,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+		},
+		Spec: routev1.RouteSpec{Host: "observatorium-api.apps.hub.example.com"},
+	}
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cluster1",
+			Annotations: map[string]string{mcoconfig.ProxyEndpointAnnotation: "proxy.example.com:8443"},
+		},
+	}
+	work := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster1-observability",
+			Namespace: "cluster1",
+			Labels:    map[string]string{workOwnerLabelKey: workOwnerLabelValue},
+			Annotations: map[string]string{
+				mcoconfig.ObsAPIEndpointAnnotation: "observatorium-api.apps.hub.example.com",
+			},
+		},
+	}
+	c := fake.NewFakeClient(mco, route, cluster, work)
+	err := certificates.CreateObservabilityCerts(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to create the certificates: (%v)", err)
+	}
+	_, err = HandleObsAPIEndpointChange(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to handle the endpoint change: (%v)", err)
+	}
+
+	_, err = HandleProxyEndpointChange(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to handle the proxy endpoint change: (%v)", err)
+	}
+	secret, hosts := getServerCertsSecret(t, c)
+	if !contains(hosts, "proxy.example.com") || !contains(hosts, "observatorium-api.apps.hub.example.com") {
+		t.Errorf("the server certificate should cover both the route and the proxy: %v", hosts)
+	}
+	if secret.Annotations[mcoconfig.ObsAPIProxyEndpointsAnnotation] != "proxy.example.com" {
+		t.Errorf("the proxy endpoints should be recorded: %v", secret.Annotations)
+	}
+
+	// the cluster moves back to the direct path, the proxy is kept while the manifestwork refers to it
+	cluster.Annotations = nil
+	err = c.Update(context.TODO(), cluster)
+	if err != nil {
+		t.Fatalf("Failed to update the managedcluster: (%v)", err)
+	}
+	work.Annotations[mcoconfig.ProxyEndpointAnnotation] = "proxy.example.com:8443"
+	work.Status.Conditions = []metav1.Condition{
+		{Type: workv1.WorkApplied, Status: metav1.ConditionTrue, Reason: "AppliedManifestWorkComplete"},
+	}
+	err = c.Update(context.TODO(), work)
+	if err != nil {
+		t.Fatalf("Failed to update the manifestwork: (%v)", err)
+	}
+	result, err := HandleProxyEndpointChange(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to handle the proxy endpoint change: (%v)", err)
+	}
+	if result == nil || result.RequeueAfter == 0 {
+		t.Errorf("the change should be requeued until the manifestwork moves off the proxy")
+	}
+	_, hosts = getServerCertsSecret(t, c)
+	if !contains(hosts, "proxy.example.com") {
+		t.Errorf("the proxy should be covered until the manifestwork moves off it: %v", hosts)
+	}
+
+	delete(work.Annotations, mcoconfig.ProxyEndpointAnnotation)
+	err = c.Update(context.TODO(), work)
+	if err != nil {
+		t.Fatalf("Failed to update the manifestwork: (%v)", err)
+	}
+	_, err = HandleProxyEndpointChange(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to handle the proxy endpoint change: (%v)", err)
+	}
+	secret, hosts = getServerCertsSecret(t, c)
+	if contains(hosts, "proxy.example.com") || !contains(hosts, "observatorium-api.apps.hub.example.com") {
+		t.Errorf("the proxy should be removed from the server certificate: %v", hosts)
+	}
+	if _, found := secret.Annotations[mcoconfig.ObsAPIProxyEndpointsAnnotation]; found {
+		t.Errorf("the proxy endpoints should be removed: %v", secret.Annotations)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"reflect"
+
+	routev1 "github.com/openshift/api/route/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// GenerateQueryEndpointRoute exposes the rbac-query-proxy to the external systems with a dedicated
+// route when the query endpoint is enabled, or removes the route and its certificate when it is
+// disabled. The route is served by the certificate of the query endpoint once it is issued, and
+// re-encrypts to the serving certificate of the rbac-query-proxy which authenticates the requests.
+func GenerateQueryEndpointRoute(
+	runclient client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	if !mcoconfig.IsQueryEndpointEnabled(mco) {
+		return nil, deleteQueryEndpoint(runclient)
+	}
+
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mcoconfig.QueryEndpointRouteName,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+		},
+		Spec: routev1.RouteSpec{
+			Host: mco.Spec.QueryEndpoint.Host,
+			Port: &routev1.RoutePort{
+				TargetPort: intstr.FromString("https"),
+			},
+			To: routev1.RouteTargetReference{
+				Kind: "Service",
+				Name: mcoconfig.RbacQueryProxy,
+			},
+			TLS: &routev1.TLSConfig{
+				Termination:                   routev1.TLSTerminationReencrypt,
+				InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyNone,
+			},
+		},
+	}
+	certs := &v1.Secret{}
+	err := runclient.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.QueryEndpointCerts,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, certs)
+	if err == nil {
+		route.Spec.TLS.Certificate = string(certs.Data["tls.crt"])
+		route.Spec.TLS.Key = string(certs.Data["tls.key"])
+		route.Spec.TLS.CACertificate = string(certs.Data["ca.crt"])
+	} else if !errors.IsNotFound(err) {
+		return &ctrl.Result{}, err
+	}
+
+	// Set MultiClusterObservability instance as the owner and controller
+	if err := controllerutil.SetControllerReference(mco, route, scheme); err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	found := &routev1.Route{}
+	err = runclient.Get(context.TODO(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating a new route to expose the query endpoint",
+			"route.Namespace", route.Namespace,
+			"route.Name", route.Name,
+		)
+		err = runclient.Create(context.TODO(), route)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+
+	// the generated host is kept, and the route moves to the certificate once it is issued or renewed
+	if route.Spec.Host == "" {
+		route.Spec.Host = found.Spec.Host
+	}
+	if route.Spec.Host != found.Spec.Host || !reflect.DeepEqual(route.Spec.TLS, found.Spec.TLS) {
+		log.Info("Updating the route of the query endpoint", "route.Name", route.Name)
+		found.Spec.Host = route.Spec.Host
+		found.Spec.TLS = route.Spec.TLS
+		err = runclient.Update(context.TODO(), found)
+		if err != nil {
+			return &ctrl.Result{}, err
+		}
+	}
+	return nil, nil
+}
+
+func deleteQueryEndpoint(c client.Client) error {
+	namespace := mcoconfig.GetDefaultNamespace()
+	objs := []client.Object{
+		&routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.QueryEndpointRouteName, Namespace: namespace},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.QueryEndpointCerts, Namespace: namespace},
+		},
+	}
+	return deleteResources(c, objs)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestGenerateQueryEndpointRoute(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	routev1.AddToScheme(s)
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			QueryEndpoint: &mcov1beta2.QueryEndpointSpec{Enabled: true, Host: "query.apps.example.com"},
+		},
+	}
+	c := fake.NewFakeClient(mco)
+	key := types.NamespacedName{Name: mcoconfig.QueryEndpointRouteName, Namespace: mcoconfig.GetDefaultNamespace()}
+
+	_, err := GenerateQueryEndpointRoute(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the query endpoint route: (%v)", err)
+	}
+	route := &routev1.Route{}
+	err = c.Get(context.TODO(), key, route)
+	if err != nil {
+		t.Fatalf("Failed to get the query endpoint route: (%v)", err)
+	}
+	if route.Spec.Host != "query.apps.example.com" || route.Spec.To.Name != mcoconfig.RbacQueryProxy ||
+		route.Spec.TLS.Termination != routev1.TLSTerminationReencrypt || route.Spec.TLS.Certificate != "" {
+		t.Fatalf("Wrong query endpoint route: %v", route.Spec)
+	}
+
+	// the route is served by the certificate of the query endpoint once it is issued
+	certs := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.QueryEndpointCerts, Namespace: mcoconfig.GetDefaultNamespace()},
+		Data: map[string][]byte{
+			"ca.crt":  []byte("ca"),
+			"tls.crt": []byte("crt"),
+			"tls.key": []byte("key"),
+		},
+	}
+	if err := c.Create(context.TODO(), certs); err != nil {
+		t.Fatalf("Failed to create the query endpoint certificate: (%v)", err)
+	}
+	_, err = GenerateQueryEndpointRoute(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the query endpoint route: (%v)", err)
+	}
+	err = c.Get(context.TODO(), key, route)
+	if err != nil || route.Spec.TLS.Certificate != "crt" || route.Spec.TLS.CACertificate != "ca" {
+		t.Fatalf("The query endpoint route should be served by its certificate: %v (%v)", route.Spec.TLS, err)
+	}
+
+	mco.Spec.QueryEndpoint.Enabled = false
+	_, err = GenerateQueryEndpointRoute(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to remove the query endpoint: (%v)", err)
+	}
+	err = c.Get(context.TODO(), key, &routev1.Route{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The query endpoint route is not deleted: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: certs.Name, Namespace: certs.Namespace}, &v1.Secret{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The query endpoint certificate is not deleted: (%v)", err)
+	}
+}
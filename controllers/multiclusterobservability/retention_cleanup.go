@@ -0,0 +1,276 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	retentionCleanupJobName       = "observability-retention-cleanup"
+	retentionCleanupConditionType = "RetentionCleanup"
+	// retentionAnnotation is the annotation of the cleanup job with the retention which it applies
+	retentionAnnotation = "observability.open-cluster-management.io/retention"
+	objStorageMountPath = "/etc/thanos/objstore"
+	compactDataPath     = "/var/thanos/compact"
+)
+
+var (
+	// isRetentionReduced is set once the retention of the blocks in the object storage is reduced
+	isRetentionReduced = false
+	// retentionCleanupRunning scales the thanos compact down while the cleanup job runs
+	retentionCleanupRunning = false
+
+	retentionRegexp = regexp.MustCompile(`^([0-9]+)(ms|s|m|h|d|w|y)$`)
+	retentionUnits  = map[string]time.Duration{
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"m":  time.Minute,
+		"h":  time.Hour,
+		"d":  24 * time.Hour,
+		"w":  7 * 24 * time.Hour,
+		"y":  365 * 24 * time.Hour,
+	}
+)
+
+// parseRetention parses the retention of thanos, e.g. 5d or 1y
+func parseRetention(retention string) (time.Duration, error) {
+	matches := retentionRegexp.FindStringSubmatch(retention)
+	if matches == nil {
+		return 0, fmt.Errorf("invalid retention %s", retention)
+	}
+	value, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(value) * retentionUnits[matches[2]], nil
+}
+
+// isRetentionShrunk returns true if the new retention deletes the blocks which the old one keeps,
+// the retention of 0 keeps the blocks forever
+func isRetentionShrunk(oldRetention, newRetention string) bool {
+	oldDuration, err := parseRetention(oldRetention)
+	if err != nil {
+		return false
+	}
+	newDuration, err := parseRetention(newRetention)
+	if err != nil || newDuration == 0 {
+		return false
+	}
+	return oldDuration == 0 || newDuration < oldDuration
+}
+
+func checkRetentionChanged(oldConfig, newConfig *mcov1beta2.RetentionConfig) {
+	if oldConfig == nil || newConfig == nil {
+		return
+	}
+	if isRetentionShrunk(oldConfig.RetentionResolutionRaw, newConfig.RetentionResolutionRaw) ||
+		isRetentionShrunk(oldConfig.RetentionResolution5m, newConfig.RetentionResolution5m) ||
+		isRetentionShrunk(oldConfig.RetentionResolution1h, newConfig.RetentionResolution1h) {
+		isRetentionReduced = true
+	}
+}
+
+func isJobFinished(job *batchv1.Job) (bool, batchv1.JobConditionType) {
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return true, c.Type
+		}
+	}
+	return false, ""
+}
+
+// HandleRetentionCleanup runs the cleanup job once the retention is reduced, the job applies the
+// new retention and deletes the blocks beyond it from the object storage at once instead of
+// leaving them to the compactor. The compactor is scaled down while the job runs.
+func HandleRetentionCleanup(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	if mcoconfig.IsExternalMetricsStoreEnabled(mco) {
+		isRetentionReduced = false
+		retentionCleanupRunning = false
+		return nil, nil
+	}
+	job := &batchv1.Job{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      retentionCleanupJobName,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, job)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get the retention cleanup job")
+		return &ctrl.Result{}, err
+	}
+	found := err == nil
+	finished := false
+	if found {
+		finished, _ = isJobFinished(job)
+	}
+
+	if isRetentionReduced {
+		if found && !finished {
+			// apply the latest retention once the running job finishes
+			retentionCleanupRunning = true
+			return nil, nil
+		}
+		if found {
+			log.Info("Deleting the finished retention cleanup job")
+			err = c.Delete(context.TODO(), job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+			if err != nil && !errors.IsNotFound(err) {
+				log.Error(err, "Failed to delete the retention cleanup job")
+				return &ctrl.Result{}, err
+			}
+			return &ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+		job = newRetentionCleanupJob(mco)
+		if err = controllerutil.SetControllerReference(mco, job, scheme); err != nil {
+			return &ctrl.Result{}, err
+		}
+		log.Info("Creating the retention cleanup job", "retention", job.Annotations[retentionAnnotation])
+		err = c.Create(context.TODO(), job)
+		if err != nil {
+			log.Error(err, "Failed to create the retention cleanup job")
+			return &ctrl.Result{}, err
+		}
+		isRetentionReduced = false
+		retentionCleanupRunning = true
+		return nil, nil
+	}
+
+	retentionCleanupRunning = found && !finished
+	return nil, nil
+}
+
+func newRetentionCleanupJob(mco *mcov1beta2.MultiClusterObservability) *batchv1.Job {
+	retention := mco.Spec.RetentionConfig
+	objStorageConfig := newThanosObjStorageConfig(mco)
+	args := []string{
+		"compact",
+		"--data-dir=" + compactDataPath,
+		"--objstore.config-file=" + objStorageMountPath + "/" + objStorageConfig.Key,
+		"--retention.resolution-raw=" + retention.RetentionResolutionRaw,
+		"--retention.resolution-5m=" + retention.RetentionResolution5m,
+		"--retention.resolution-1h=" + retention.RetentionResolution1h,
+		// delete the blocks beyond the retention at once
+		"--delete-delay=0s",
+		"--log.level=info",
+	}
+	if !mco.Spec.EnableDownsampling {
+		args = append(args, "--downsampling.disable")
+	}
+	backoffLimit := int32(2)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      retentionCleanupJobName,
+			Namespace: mcoconfig.GetDefaultNamespace(),
+			Labels: map[string]string{
+				"app.kubernetes.io/instance": mco.GetName(),
+				"app.kubernetes.io/name":     retentionCleanupJobName,
+			},
+			Annotations: map[string]string{
+				retentionAnnotation: fmt.Sprintf("raw=%s,5m=%s,1h=%s", retention.RetentionResolutionRaw,
+					retention.RetentionResolution5m, retention.RetentionResolution1h),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					// the service account of the compactor with the workload identity
+					ServiceAccountName: mco.GetName() + "-" + mcoconfig.ThanosCompact,
+					RestartPolicy:      corev1.RestartPolicyNever,
+					NodeSelector:       mco.Spec.NodeSelector,
+					Tolerations:        mco.Spec.Tolerations,
+					ImagePullSecrets: []corev1.LocalObjectReference{
+						{Name: mco.Spec.ImagePullSecret},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            "thanos-compact",
+							Image:           getThanosImage(mco),
+							ImagePullPolicy: mco.Spec.ImagePullPolicy,
+							Args:            args,
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "objstore", MountPath: objStorageMountPath, ReadOnly: true},
+								{Name: "data", MountPath: compactDataPath},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "objstore",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{SecretName: objStorageConfig.Name},
+							},
+						},
+						{
+							Name:         "data",
+							VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// updateRetentionCleanupStatus reports the progress of the retention cleanup job
+func updateRetentionCleanupStatus(conditions *[]mcoshared.Condition, c client.Client) {
+	job := &batchv1.Job{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      retentionCleanupJobName,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, job)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			removeStatusCondition(conditions, retentionCleanupConditionType)
+		}
+		return
+	}
+
+	retention := job.Annotations[retentionAnnotation]
+	condition := mcoshared.Condition{
+		Type:    retentionCleanupConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  "CleanupRunning",
+		Message: fmt.Sprintf("The job %s is applying the retention %s to the object storage", job.Name, retention),
+	}
+	if job.Status.StartTime != nil {
+		condition.Message += fmt.Sprintf(", started at %s", job.Status.StartTime.UTC().Format(time.RFC3339))
+	}
+	if job.Status.Failed > 0 {
+		condition.Message += fmt.Sprintf(", %d failed attempts", job.Status.Failed)
+	}
+	finished, result := isJobFinished(job)
+	if finished && result == batchv1.JobComplete {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "CleanupCompleted"
+		condition.Message = fmt.Sprintf("The blocks beyond the retention %s are deleted from the object storage", retention)
+		if job.Status.CompletionTime != nil {
+			condition.Message += fmt.Sprintf(" at %s", job.Status.CompletionTime.UTC().Format(time.RFC3339))
+		}
+	} else if finished {
+		condition.Reason = "CleanupFailed"
+		condition.Message = fmt.Sprintf("The job %s failed to apply the retention %s, check the logs of the job, "+
+			"the compactor applies the retention instead", job.Name, retention)
+	}
+	setStatusCondition(conditions, condition)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestIsRetentionShrunk(t *testing.T) {
+	caseList := []struct {
+		oldRetention string
+		newRetention string
+		expected     bool
+	}{
+		{"5d", "3d", true},
+		{"1y", "90d", true},
+		{"0d", "30d", true},
+		{"3d", "5d", false},
+		{"30d", "0d", false},
+		{"2w", "14d", false},
+		{"invalid", "1d", false},
+	}
+	for _, c := range caseList {
+		if isRetentionShrunk(c.oldRetention, c.newRetention) != c.expected {
+			t.Errorf("the shrink from %s to %s is not expected: %v", c.oldRetention, c.newRetention, c.expected)
+		}
+	}
+}
+
+func TestHandleRetentionCleanup(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			StorageConfig: &mcov1beta2.StorageConfig{
+				MetricObjectStorage: &mcoshared.PreConfiguredStorage{
+					Name: "thanos-object-storage",
+					Key:  "thanos.yaml",
+				},
+				CompactStorageSize: "1Gi",
+			},
+			RetentionConfig: &mcov1beta2.RetentionConfig{
+				RetentionResolutionRaw: "5d",
+				RetentionResolution5m:  "14d",
+				RetentionResolution1h:  "30d",
+			},
+		},
+	}
+	c := fake.NewFakeClient()
+
+	newConfig := mco.Spec.RetentionConfig.DeepCopy()
+	newConfig.RetentionResolution1h = "7d"
+	checkRetentionChanged(mco.Spec.RetentionConfig, newConfig)
+	if !isRetentionReduced {
+		t.Fatalf("the reduced retention should be detected")
+	}
+	mco.Spec.RetentionConfig = newConfig
+
+	_, err := HandleRetentionCleanup(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to handle the retention cleanup: (%v)", err)
+	}
+	if isRetentionReduced || !retentionCleanupRunning {
+		t.Errorf("the cleanup job should be running")
+	}
+	if *newCompactSpec(mco, "gp2").Replicas != 0 {
+		t.Errorf("the compactor should be scaled down while the cleanup job runs")
+	}
+	job := &batchv1.Job{}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      retentionCleanupJobName,
+		Namespace: mcoconfig.GetDefaultNamespace(),
+	}, job)
+	if err != nil {
+		t.Fatalf("Failed to get the retention cleanup job: (%v)", err)
+	}
+	if job.Annotations[retentionAnnotation] != "raw=5d,5m=14d,1h=7d" {
+		t.Errorf("the retention of the job is not expected: %s", job.Annotations[retentionAnnotation])
+	}
+
+	conditions := []mcoshared.Condition{}
+	updateRetentionCleanupStatus(&conditions, c)
+	condition := findStatusCondition(conditions, retentionCleanupConditionType)
+	if condition == nil || condition.Reason != "CleanupRunning" {
+		t.Errorf("the running cleanup job should be reported: %v", conditions)
+	}
+
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+	}
+	err = c.Status().Update(context.TODO(), job)
+	if err != nil {
+		t.Fatalf("Failed to update the retention cleanup job: (%v)", err)
+	}
+	_, err = HandleRetentionCleanup(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to handle the retention cleanup: (%v)", err)
+	}
+	if retentionCleanupRunning {
+		t.Errorf("the compactor should be scaled up once the cleanup job completes")
+	}
+	updateRetentionCleanupStatus(&conditions, c)
+	condition = findStatusCondition(conditions, retentionCleanupConditionType)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Errorf("the completed cleanup job should be reported: %v", conditions)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	// SecretProtectionWebhookPath is the path of the webhook server which validates the deletion
+	// of the secrets
+	SecretProtectionWebhookPath = "/validate-observability-secrets"
+	// AllowDeletionAnnotation allows the deletion of a secret which the observability components use
+	AllowDeletionAnnotation = "observability.open-cluster-management.io/allow-deletion"
+	// ProtectedSecretLabel is the label of the secrets which the webhook validates the deletion of
+	ProtectedSecretLabel = "observability.open-cluster-management.io/protected-secret"
+	// namespaceNameLabel is the label of the name of the namespace which kubernetes sets since 1.21
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+
+	secretProtectionWebhookName = "multicluster-observability-secret-protection"
+	webhookServiceName          = "multicluster-observability-webhook-service"
+	operatorServiceAccountName  = "multicluster-observability-operator"
+)
+
+// GenerateSecretProtectionWebhook registers the webhook which rejects the deletion of the secrets
+// which the observability components use, e.g. the certificates of the observatorium api and the
+// object storage secret. The CA bundle of the webhook is injected by the service CA of OpenShift.
+// The webhook only receives the deletions of the secrets with ProtectedSecretLabel in the namespace
+// of the operands, so the protected secrets are labeled first.
+func GenerateSecretProtectionWebhook(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability) (*ctrl.Result, error) {
+	if err := labelProtectedSecrets(c, mco); err != nil {
+		return &ctrl.Result{}, err
+	}
+	webhookConfig := newSecretProtectionWebhook()
+	if err := controllerutil.SetControllerReference(mco, webhookConfig, scheme); err != nil {
+		return &ctrl.Result{}, err
+	}
+	found := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: webhookConfig.Name}, found)
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating the secret protection webhook")
+		err = c.Create(context.TODO(), webhookConfig)
+	} else if err == nil && !equality.Semantic.DeepDerivative(webhookConfig.Webhooks, found.Webhooks) {
+		log.Info("Updating the secret protection webhook")
+		// keep the CA bundle which is injected by the service CA
+		for idx := range webhookConfig.Webhooks {
+			if idx < len(found.Webhooks) {
+				webhookConfig.Webhooks[idx].ClientConfig.CABundle = found.Webhooks[idx].ClientConfig.CABundle
+			}
+		}
+		found.Webhooks = webhookConfig.Webhooks
+		err = c.Update(context.TODO(), found)
+	}
+	if err != nil {
+		log.Error(err, "Failed to create or update the secret protection webhook")
+		return &ctrl.Result{}, err
+	}
+	return nil, nil
+}
+
+func newSecretProtectionWebhook() *admissionregistrationv1.ValidatingWebhookConfiguration {
+	path := SecretProtectionWebhookPath
+	port := int32(443)
+	// the deletion is not blocked when the operator is down
+	failurePolicy := admissionregistrationv1.Ignore
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	timeoutSeconds := int32(5)
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: secretProtectionWebhookName,
+			Annotations: map[string]string{
+				"service.beta.openshift.io/inject-cabundle": "true",
+			},
+		},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			{
+				Name: "secrets.observability.open-cluster-management.io",
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{
+						Namespace: mcoconfig.GetMCONamespace(),
+						Name:      webhookServiceName,
+						Path:      &path,
+						Port:      &port,
+					},
+				},
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{namespaceNameLabel: mcoconfig.GetDefaultNamespace()},
+				},
+				ObjectSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{ProtectedSecretLabel: "true"},
+				},
+				Rules: []admissionregistrationv1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Delete},
+						Rule: admissionregistrationv1.Rule{
+							APIGroups:   []string{""},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"secrets"},
+						},
+					},
+				},
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				TimeoutSeconds:          &timeoutSeconds,
+				AdmissionReviewVersions: []string{"v1", "v1beta1"},
+			},
+		},
+	}
+}
+
+// getProtectedSecrets returns the names of the secrets in the namespace of the operands which
+// the observability components cannot run without
+func getProtectedSecrets(mco *mcov1beta2.MultiClusterObservability) map[string]bool {
+	secrets := map[string]bool{
+		mcoconfig.ServerCACerts: true,
+		mcoconfig.ClientCACerts: true,
+		mcoconfig.ServerCerts:   true,
+		mcoconfig.GrafanaCerts:  true,
+	}
+	if mco.Spec.StorageConfig != nil && mco.Spec.StorageConfig.MetricObjectStorage != nil &&
+		!mcoconfig.IsExternalMetricsStoreEnabled(mco) {
+		secrets[mco.Spec.StorageConfig.MetricObjectStorage.Name] = true
+		if mcoconfig.IsObjStorageConfGenerated(mco) {
+			secrets[mcoconfig.GeneratedObjStorageSecretName] = true
+		}
+	}
+	return secrets
+}
+
+// labelProtectedSecrets adds ProtectedSecretLabel to the protected secrets which exist, so that the
+// webhook receives their deletions
+func labelProtectedSecrets(c client.Client, mco *mcov1beta2.MultiClusterObservability) error {
+	for name := range getProtectedSecrets(mco) {
+		secret := &corev1.Secret{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: mcoconfig.GetDefaultNamespace()}, secret)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			log.Error(err, "Failed to get the protected secret", "name", name)
+			return err
+		}
+		if secret.GetLabels()[ProtectedSecretLabel] == "true" {
+			continue
+		}
+		labels := secret.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[ProtectedSecretLabel] = "true"
+		secret.SetLabels(labels)
+		if err := c.Update(context.TODO(), secret); err != nil {
+			log.Error(err, "Failed to label the protected secret", "name", name)
+			return err
+		}
+	}
+	return nil
+}
+
+// SecretProtectionHandler rejects the deletion of the secrets which the observability components
+// use while the MultiClusterObservability exists, unless the secret is annotated with
+// AllowDeletionAnnotation
+type SecretProtectionHandler struct {
+	Client client.Client
+}
+
+func (h *SecretProtectionHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Delete || req.Namespace != mcoconfig.GetDefaultNamespace() ||
+		req.UserInfo.Username == "system:serviceaccount:"+mcoconfig.GetMCONamespace()+":"+operatorServiceAccountName {
+		return admission.Allowed("")
+	}
+
+	mcoList := &mcov1beta2.MultiClusterObservabilityList{}
+	err := h.Client.List(ctx, mcoList)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if len(mcoList.Items) == 0 || mcoList.Items[0].GetDeletionTimestamp() != nil {
+		return admission.Allowed("")
+	}
+	if !getProtectedSecrets(&mcoList.Items[0])[req.Name] {
+		return admission.Allowed("")
+	}
+
+	secret := &corev1.Secret{}
+	if len(req.OldObject.Raw) != 0 {
+		err = json.Unmarshal(req.OldObject.Raw, secret)
+	} else {
+		err = h.Client.Get(ctx, types.NamespacedName{Name: req.Name, Namespace: req.Namespace}, secret)
+	}
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if secret.GetAnnotations()[AllowDeletionAnnotation] == "true" {
+		log.Info("Allowing the deletion of the secret which the observability components use", "name", req.Name)
+		return admission.Allowed("")
+	}
+	return admission.Denied(fmt.Sprintf("the secret %s is used by the observability components and deleting it "+
+		"interrupts the metrics of the managed clusters, annotate it with %s=true to delete it",
+		req.Name, AllowDeletionAnnotation))
+}
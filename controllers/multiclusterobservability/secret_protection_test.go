@@ -0,0 +1,101 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func newSecretDeleteRequest(t *testing.T, secret *corev1.Secret) admission.Request {
+	raw, err := json.Marshal(secret)
+	if err != nil {
+		t.Fatalf("Failed to marshal the secret: (%v)", err)
+	}
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Delete,
+		Name:      secret.Name,
+		Namespace: secret.Namespace,
+		OldObject: runtime.RawExtension{Raw: raw},
+	}}
+}
+
+func TestSecretProtection(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	namespace := mcoconfig.GetDefaultNamespace()
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			StorageConfig: &mcov1beta2.StorageConfig{
+				MetricObjectStorage: &mcoshared.PreConfiguredStorage{Name: "thanos-object-storage", Key: "thanos.yaml"},
+			},
+		},
+	}
+	c := fake.NewFakeClient(mco, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.ServerCerts, Namespace: namespace},
+	})
+	handler := &SecretProtectionHandler{Client: c}
+
+	storageSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "thanos-object-storage", Namespace: namespace}}
+	if resp := handler.Handle(context.TODO(), newSecretDeleteRequest(t, storageSecret)); resp.Allowed {
+		t.Errorf("the deletion of the object storage secret should be rejected")
+	}
+	serverCerts := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: mcoconfig.ServerCerts, Namespace: namespace}}
+	if resp := handler.Handle(context.TODO(), newSecretDeleteRequest(t, serverCerts)); resp.Allowed {
+		t.Errorf("the deletion of the server certificate should be rejected")
+	}
+	other := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: namespace}}
+	if resp := handler.Handle(context.TODO(), newSecretDeleteRequest(t, other)); !resp.Allowed {
+		t.Errorf("the deletion of the other secrets should be allowed")
+	}
+	serverCerts.Annotations = map[string]string{AllowDeletionAnnotation: "true"}
+	if resp := handler.Handle(context.TODO(), newSecretDeleteRequest(t, serverCerts)); !resp.Allowed {
+		t.Errorf("the deletion of the annotated secret should be allowed")
+	}
+
+	_, err := GenerateSecretProtectionWebhook(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the secret protection webhook: (%v)", err)
+	}
+	webhookConfig := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: secretProtectionWebhookName}, webhookConfig)
+	if err != nil || *webhookConfig.Webhooks[0].ClientConfig.Service.Path != SecretProtectionWebhookPath {
+		t.Errorf("the secret protection webhook should be registered: (%v)", err)
+	}
+	if webhookConfig.Webhooks[0].NamespaceSelector.MatchLabels[namespaceNameLabel] != namespace ||
+		webhookConfig.Webhooks[0].ObjectSelector.MatchLabels[ProtectedSecretLabel] != "true" {
+		t.Errorf("the secret protection webhook should be scoped to the protected secrets: %v", webhookConfig.Webhooks[0])
+	}
+	secret := &corev1.Secret{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: mcoconfig.ServerCerts, Namespace: namespace}, secret)
+	if err != nil || secret.Labels[ProtectedSecretLabel] != "true" {
+		t.Errorf("the protected secret should be labeled: %v (%v)", secret.Labels, err)
+	}
+
+	err = c.Delete(context.TODO(), mco)
+	if err != nil {
+		t.Fatalf("Failed to delete the MultiClusterObservability: (%v)", err)
+	}
+	if resp := handler.Handle(context.TODO(), newSecretDeleteRequest(t, storageSecret)); !resp.Allowed {
+		t.Errorf("the secrets should be deletable once the MultiClusterObservability is deleted")
+	}
+}
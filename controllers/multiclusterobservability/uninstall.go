@@ -0,0 +1,132 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	uninstallingConditionType = "Uninstalling"
+	// uninstallRefreshInterval is the interval to refresh the blockers of the uninstall
+	uninstallRefreshInterval = 10 * time.Second
+	// uninstallTimeout is how long the deletion of the MultiClusterObservability waits for the observability
+	// to be removed from the managed clusters, the rest of the teardown continues in the background after it
+	uninstallTimeout = 30 * time.Minute
+	// stuckDeletionThreshold is how long a resource is deleted before its finalizer is reported as stuck
+	stuckDeletionThreshold = 5 * time.Minute
+	// maxStuckClusters is the maximum number of the clusters whose stuck finalizers are reported
+	maxStuckClusters = 5
+)
+
+// getUninstallBlockers returns the stage of the uninstall and the blockers of the stage. The
+// ObservabilityAddons are removed from the managed clusters first, then the ManifestWorks. The stage
+// is empty once the observability is removed from all the managed clusters.
+func getUninstallBlockers(c client.Client, now time.Time) (string, []string, error) {
+	addonList := &mcov1beta1.ObservabilityAddonList{}
+	err := c.List(context.TODO(), addonList, client.MatchingLabels{workOwnerLabelKey: workOwnerLabelValue})
+	if err != nil {
+		return "", nil, err
+	}
+	if len(addonList.Items) > 0 {
+		objs := []metav1.Object{}
+		for index := range addonList.Items {
+			objs = append(objs, &addonList.Items[index])
+		}
+		blockers := []string{fmt.Sprintf("%d ObservabilityAddons pending deletion", len(objs))}
+		return "RemovingObservabilityAddons", append(blockers, getStuckDeletions(objs, now)...), nil
+	}
+
+	workList := &workv1.ManifestWorkList{}
+	err = c.List(context.TODO(), workList, client.MatchingLabels{workOwnerLabelKey: workOwnerLabelValue})
+	if err != nil {
+		return "", nil, err
+	}
+	if len(workList.Items) > 0 {
+		objs := []metav1.Object{}
+		for index := range workList.Items {
+			objs = append(objs, &workList.Items[index])
+		}
+		blockers := []string{fmt.Sprintf("%d ManifestWorks pending deletion", len(objs))}
+		return "RemovingManifestWorks", append(blockers, getStuckDeletions(objs, now)...), nil
+	}
+	return "", nil, nil
+}
+
+// getStuckDeletions returns the finalizers which block the deletion of the resources in the namespaces
+// of the managed clusters for longer than the threshold, e.g. the managed cluster is not reachable
+func getStuckDeletions(objs []metav1.Object, now time.Time) []string {
+	stuck := []metav1.Object{}
+	for _, obj := range objs {
+		deleted := obj.GetDeletionTimestamp()
+		if deleted != nil && len(obj.GetFinalizers()) > 0 && now.Sub(deleted.Time) > stuckDeletionThreshold {
+			stuck = append(stuck, obj)
+		}
+	}
+	sort.Slice(stuck, func(i, j int) bool { return stuck[i].GetNamespace() < stuck[j].GetNamespace() })
+	blockers := []string{}
+	for index, obj := range stuck {
+		if index == maxStuckClusters {
+			blockers = append(blockers, fmt.Sprintf("finalizers stuck on %d more clusters", len(stuck)-index))
+			break
+		}
+		blockers = append(blockers, fmt.Sprintf("finalizer %s stuck on cluster %s for %s",
+			strings.Join(obj.GetFinalizers(), ","), obj.GetNamespace(),
+			now.Sub(obj.GetDeletionTimestamp().Time).Round(time.Minute)))
+	}
+	return blockers
+}
+
+// updateUninstallStatus reports the blockers of the deletion of the MultiClusterObservability in the
+// Uninstalling condition, it returns true if the deletion should wait for the blockers to be removed
+func (r *MultiClusterObservabilityReconciler) updateUninstallStatus(
+	mco *mcov1beta2.MultiClusterObservability) (bool, error) {
+	reason, blockers, err := getUninstallBlockers(r.Client, time.Now())
+	if err != nil {
+		log.Error(err, "Failed to get the blockers of the uninstall")
+		return true, err
+	}
+	if reason == "" {
+		return false, nil
+	}
+	message := strings.Join(blockers, "; ")
+	if time.Since(mco.GetDeletionTimestamp().Time) > uninstallTimeout {
+		log.Info("Timed out waiting for the observability to be removed from the managed clusters, "+
+			"the teardown continues in the background", "blockers", message)
+		return false, nil
+	}
+
+	existing := findStatusCondition(mco.Status.Conditions, uninstallingConditionType)
+	if existing != nil && existing.Reason == reason && existing.Message == message {
+		return true, nil
+	}
+	log.Info("Waiting for the uninstall", "reason", reason, "blockers", message)
+	newStatus := mco.Status.DeepCopy()
+	setStatusCondition(&newStatus.Conditions, mcoshared.Condition{
+		Type:    uninstallingConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+	mco.Status.Conditions = newStatus.Conditions
+	err = r.Client.Status().Update(context.TODO(), mco)
+	if err != nil && !apierrors.IsConflict(err) {
+		log.Error(err, "Failed to update the uninstall status")
+		return true, err
+	}
+	return true, nil
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+func TestUninstallStatus(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta1.SchemeBuilder.AddToScheme(s)
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	workv1.AddToScheme(s)
+
+	now := time.Now()
+	ownerLabels := map[string]string{workOwnerLabelKey: workOwnerLabelValue}
+	stuckSince := metav1.NewTime(now.Add(-10 * time.Minute))
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "observability",
+			DeletionTimestamp: &metav1.Time{Time: now.Add(-time.Minute)},
+			Finalizers:        []string{resFinalizer},
+		},
+	}
+	addon := &mcov1beta1.ObservabilityAddon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "observability-addon",
+			Namespace:         "cluster1",
+			Labels:            ownerLabels,
+			DeletionTimestamp: &stuckSince,
+			Finalizers:        []string{"observability.open-cluster-management.io/addon-cleanup"},
+		},
+	}
+	work := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster2-observability", Namespace: "cluster2", Labels: ownerLabels},
+	}
+	c := fake.NewFakeClient(mco, addon, work)
+	r := &MultiClusterObservabilityReconciler{Client: c, Scheme: s}
+
+	// the ObservabilityAddons are removed first
+	reason, blockers, err := getUninstallBlockers(c, now)
+	if err != nil {
+		t.Fatalf("Failed to get the blockers of the uninstall: (%v)", err)
+	}
+	if reason != "RemovingObservabilityAddons" || len(blockers) != 2 ||
+		blockers[0] != "1 ObservabilityAddons pending deletion" ||
+		!strings.Contains(blockers[1], "stuck on cluster cluster1 for 10m") {
+		t.Errorf("Wrong blockers of the uninstall: %s %v", reason, blockers)
+	}
+
+	waiting, err := r.updateUninstallStatus(mco)
+	if err != nil || !waiting {
+		t.Fatalf("The uninstall should wait for the blockers: (%v)", err)
+	}
+	found := &mcov1beta2.MultiClusterObservability{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: mco.Name}, found); err != nil {
+		t.Fatalf("Failed to get the mco: (%v)", err)
+	}
+	condition := findStatusCondition(found.Status.Conditions, uninstallingConditionType)
+	if condition == nil || condition.Reason != "RemovingObservabilityAddons" ||
+		!strings.HasPrefix(condition.Message, "1 ObservabilityAddons pending deletion; ") {
+		t.Errorf("Wrong Uninstalling condition: %v", found.Status.Conditions)
+	}
+
+	// then the ManifestWorks
+	if err := c.Delete(context.TODO(), addon); err != nil {
+		t.Fatalf("Failed to delete the addon: (%v)", err)
+	}
+	reason, blockers, err = getUninstallBlockers(c, now)
+	if err != nil || reason != "RemovingManifestWorks" || len(blockers) != 1 ||
+		blockers[0] != "1 ManifestWorks pending deletion" {
+		t.Errorf("Wrong blockers of the uninstall: %s %v (%v)", reason, blockers, err)
+	}
+
+	// the uninstall does not wait once the observability is removed from the managed clusters
+	if err := c.Delete(context.TODO(), work); err != nil {
+		t.Fatalf("Failed to delete the manifestwork: (%v)", err)
+	}
+	waiting, err = r.updateUninstallStatus(found)
+	if err != nil || waiting {
+		t.Errorf("The uninstall should not wait without the blockers: (%v)", err)
+	}
+}
+
+func TestUninstallTimeout(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta1.SchemeBuilder.AddToScheme(s)
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "observability",
+			DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-uninstallTimeout - time.Minute)},
+			Finalizers:        []string{resFinalizer},
+		},
+	}
+	addon := &mcov1beta1.ObservabilityAddon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "observability-addon",
+			Namespace: "cluster1",
+			Labels:    map[string]string{workOwnerLabelKey: workOwnerLabelValue},
+		},
+	}
+	r := &MultiClusterObservabilityReconciler{Client: fake.NewFakeClient(mco, addon), Scheme: s}
+	waiting, err := r.updateUninstallStatus(mco)
+	if err != nil || waiting {
+		t.Errorf("The uninstall should not wait for the blockers after the timeout: (%v)", err)
+	}
+}
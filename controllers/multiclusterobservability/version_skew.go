@@ -0,0 +1,158 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	addonVersionConditionType = "AddonsUpToDate"
+	// the version of the addons which do not report their version
+	unknownAddonVersion = "unknown"
+)
+
+var (
+	addonOutdated = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "acm_observability_addon_outdated",
+		Help: "Whether the managed cluster runs the observability addon of another version than the operator on the hub.",
+	}, []string{"cluster", "version"})
+	addonsUpdatedRatio = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "acm_observability_addons_updated_ratio",
+		Help: "The fraction of the managed clusters which run the observability addon of the version of the operator.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(addonOutdated, addonsUpdatedRatio)
+}
+
+// addonVersionSkew is the version skew between the operator on the hub and the observability
+// addons of the managed clusters
+type addonVersionSkew struct {
+	total int
+	// the versions of the addons which are outdated by cluster
+	outdated map[string]string
+}
+
+func (s *addonVersionSkew) updatedPercentage() float64 {
+	if s.total == 0 {
+		return 100
+	}
+	return float64(s.total-len(s.outdated)) * 100 / float64(s.total)
+}
+
+// getAddonVersionSkew compares the versions which the addons report with the version of the operator
+func getAddonVersionSkew(c client.Client) (*addonVersionSkew, error) {
+	addonList := &mcov1beta1.ObservabilityAddonList{}
+	err := c.List(context.TODO(), addonList)
+	if err != nil {
+		log.Error(err, "Failed to list the ObservabilityAddons")
+		return nil, err
+	}
+	skew := &addonVersionSkew{outdated: map[string]string{}}
+	for _, addon := range addonList.Items {
+		// the addons in the cluster namespaces on the hub
+		cluster := addon.Namespace
+		if cluster == mcoconfig.GetDefaultNamespace() {
+			continue
+		}
+		skew.total++
+		version := addon.Status.Version
+		if version == "" {
+			version = unknownAddonVersion
+		}
+		if version != mcoconfig.GetComponentVersion() {
+			skew.outdated[cluster] = version
+		}
+	}
+	return skew, nil
+}
+
+// isHubUpgradeGated returns true if the upgrade of the components on the hub is held until more
+// managed clusters run the addon of the version of the operator
+func isHubUpgradeGated(c client.Client, mco *mcov1beta2.MultiClusterObservability) (bool, error) {
+	if mco.Spec.HubUpgradeGate == nil {
+		return false, nil
+	}
+	skew, err := getAddonVersionSkew(c)
+	if err != nil {
+		return false, err
+	}
+	return skew.updatedPercentage() < float64(mco.Spec.HubUpgradeGate.MinUpdatedAddonsPercentage), nil
+}
+
+// isUpgradeHeld returns true if the resource is a running component of another version, the new
+// components are always deployed
+func isUpgradeHeld(c client.Client, res *unstructured.Unstructured) (bool, error) {
+	if res.GetKind() != "Deployment" && res.GetKind() != "StatefulSet" {
+		return false, nil
+	}
+	found := &unstructured.Unstructured{}
+	found.SetGroupVersionKind(res.GroupVersionKind())
+	err := c.Get(context.TODO(), types.NamespacedName{Name: res.GetName(), Namespace: res.GetNamespace()}, found)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return found.GetLabels()[mcoconfig.VersionLabelKey] != mcoconfig.GetComponentVersion(), nil
+}
+
+// updateAddonVersionStatus reports the managed clusters which run the outdated addons
+func updateAddonVersionStatus(conditions *[]mcoshared.Condition, c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) {
+	skew, err := getAddonVersionSkew(c)
+	if err != nil {
+		return
+	}
+	addonOutdated.Reset()
+	addonsUpdatedRatio.Set(skew.updatedPercentage() / 100)
+	if skew.total == 0 {
+		removeStatusCondition(conditions, addonVersionConditionType)
+		return
+	}
+
+	outdated := []string{}
+	for cluster, version := range skew.outdated {
+		addonOutdated.WithLabelValues(cluster, version).Set(1)
+		outdated = append(outdated, fmt.Sprintf("%s (%s)", cluster, version))
+	}
+	condition := mcoshared.Condition{
+		Type:    addonVersionConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "AddonsUpdated",
+		Message: fmt.Sprintf("The managed clusters run the observability addon of version %s", mcoconfig.GetComponentVersion()),
+	}
+	if len(outdated) > 0 {
+		sort.Strings(outdated)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "AddonsOutdated"
+		condition.Message = fmt.Sprintf("%d of %d managed clusters run the observability addon of another version than %s: %s",
+			len(outdated), skew.total, mcoconfig.GetComponentVersion(), strings.Join(outdated, ", "))
+		gate := mco.Spec.HubUpgradeGate
+		if gate != nil && skew.updatedPercentage() < float64(gate.MinUpdatedAddonsPercentage) {
+			condition.Message += fmt.Sprintf(". The upgrade of the hub components is held until %d%% of them are updated",
+				gate.MinUpdatedAddonsPercentage)
+		}
+	}
+	setStatusCondition(conditions, condition)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func newVersionedAddon(cluster, version string) *mcov1beta1.ObservabilityAddon {
+	return &mcov1beta1.ObservabilityAddon{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability-addon", Namespace: cluster},
+		Status:     mcov1beta1.ObservabilityAddonStatus{Version: version},
+	}
+}
+
+func TestAddonVersionSkew(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta1.SchemeBuilder.AddToScheme(s)
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	version := mcoconfig.GetComponentVersion()
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			HubUpgradeGate: &mcov1beta2.HubUpgradeGateSpec{MinUpdatedAddonsPercentage: 50},
+		},
+	}
+	c := fake.NewFakeClient(
+		newVersionedAddon("cluster1", version),
+		newVersionedAddon("cluster2", "2.2.0"),
+		newVersionedAddon("cluster3", ""),
+		newVersionedAddon(mcoconfig.GetDefaultNamespace(), ""))
+
+	conditions := []mcoshared.Condition{}
+	updateAddonVersionStatus(&conditions, c, mco)
+	condition := findStatusCondition(conditions, addonVersionConditionType)
+	if condition == nil || condition.Status != metav1.ConditionFalse {
+		t.Fatalf("the outdated addons should be reported: %v", conditions)
+	}
+	if !strings.Contains(condition.Message, "2 of 3") ||
+		!strings.Contains(condition.Message, "cluster2 (2.2.0), cluster3 (unknown)") {
+		t.Errorf("the message of the condition is not expected: %s", condition.Message)
+	}
+
+	gated, err := isHubUpgradeGated(c, mco)
+	if err != nil || !gated {
+		t.Fatalf("the upgrade should be gated with 1 of 3 clusters updated: %v", err)
+	}
+
+	sts := &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "observability-alertmanager",
+			Namespace: mcoconfig.GetDefaultNamespace(),
+			Labels:    map[string]string{mcoconfig.VersionLabelKey: "2.2.0"},
+		},
+	}
+	err = c.Create(context.TODO(), sts)
+	if err != nil {
+		t.Fatalf("Failed to create the statefulset: (%v)", err)
+	}
+	res := &unstructured.Unstructured{}
+	res.SetAPIVersion("apps/v1")
+	res.SetKind("StatefulSet")
+	res.SetName(sts.Name)
+	res.SetNamespace(sts.Namespace)
+	held, err := isUpgradeHeld(c, res)
+	if err != nil || !held {
+		t.Errorf("the upgrade of the running statefulset should be held: %v", err)
+	}
+	res.SetName("observability-new-component")
+	held, err = isUpgradeHeld(c, res)
+	if err != nil || held {
+		t.Errorf("the new component should be deployed: %v", err)
+	}
+
+	mco.Spec.HubUpgradeGate.MinUpdatedAddonsPercentage = 30
+	gated, err = isHubUpgradeGated(c, mco)
+	if err != nil || gated {
+		t.Errorf("the upgrade should not be gated with 1 of 3 clusters updated: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// getObjStorageServiceAccountNames returns the service accounts of the thanos components which
+// read or write the blocks in the object storage
+func getObjStorageServiceAccountNames(mcoName string) []string {
+	return []string{
+		mcoName + "-" + mcoconfig.ThanosCompact,
+		mcoName + "-" + mcoconfig.ThanosReceive,
+		mcoName + "-" + mcoconfig.ThanosRule,
+		mcoName + "-" + mcoconfig.ThanosStoreShard,
+	}
+}
+
+// bindWorkloadIdentity annotates the service accounts of the thanos components with the cloud identity
+func bindWorkloadIdentity(c client.Client, scheme *runtime.Scheme, mco *mcov1beta2.MultiClusterObservability) error {
+	annotations := mcoconfig.GetWorkloadIdentityAnnotations(mco.Spec.StorageConfig.WorkloadIdentity)
+	for _, name := range getObjStorageServiceAccountNames(mco.Name) {
+		err := annotateServiceAccount(c, scheme, mco, name, annotations)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// annotateServiceAccount adds the annotations of the cloud identity to the service account, the
+// service account is created ahead of the thanos component if it does not exist yet
+func annotateServiceAccount(c client.Client, scheme *runtime.Scheme,
+	mco *mcov1beta2.MultiClusterObservability, name string, annotations map[string]string) error {
+	namespace := mcoconfig.GetDefaultNamespace()
+	sa := &corev1.ServiceAccount{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, sa)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get the service account", "name", name)
+			return err
+		}
+		sa = &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: annotations,
+			},
+		}
+		if err = controllerutil.SetControllerReference(mco, sa, scheme); err != nil {
+			return err
+		}
+		log.Info("Creating the service account of the workload identity", "name", name)
+		err = c.Create(context.TODO(), sa)
+		if err != nil {
+			log.Error(err, "Failed to create the service account", "name", name)
+		}
+		return err
+	}
+
+	changed := false
+	if sa.Annotations == nil {
+		sa.Annotations = map[string]string{}
+	}
+	for key, value := range annotations {
+		if sa.Annotations[key] != value {
+			sa.Annotations[key] = value
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	log.Info("Annotating the service account with the workload identity", "name", name)
+	err = c.Update(context.TODO(), sa)
+	if err != nil {
+		log.Error(err, "Failed to annotate the service account", "name", name)
+	}
+	return err
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package multiclusterobservability
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestGenerateObjStorageWorkloadIdentity(t *testing.T) {
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+
+	namespace := mcoconfig.GetDefaultNamespace()
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			StorageConfig: &mcov1beta2.StorageConfig{
+				MetricObjectStorage: &mcoshared.PreConfiguredStorage{
+					Name: "thanos-object-storage",
+					Key:  "thanos.yaml",
+				},
+				WorkloadIdentity: &mcov1beta2.WorkloadIdentitySpec{
+					Provider: mcoconfig.WorkloadIdentityAWS,
+					RoleARN:  "arn:aws:iam::123456789012:role/thanos",
+				},
+			},
+		},
+	}
+	objStorage := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "thanos-object-storage", Namespace: namespace},
+		Data: map[string][]byte{
+			"thanos.yaml": []byte(`type: s3
+config:
+  bucket: bucket
+  endpoint: s3.us-east-1.amazonaws.com
+  access_key: access_key
+  secret_key: secret_key`),
+		},
+	}
+	// the service account of the compactor exists already
+	compactSA := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        mco.Name + "-" + mcoconfig.ThanosCompact,
+			Namespace:   namespace,
+			Annotations: map[string]string{"owner": "observatorium"},
+		},
+	}
+	c := fake.NewFakeClient(objStorage, compactSA)
+
+	_, err := GenerateObjStorageConfig(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to generate the workload identity: (%v)", err)
+	}
+	secret := &corev1.Secret{}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.GeneratedObjStorageSecretName,
+		Namespace: namespace,
+	}, secret)
+	if err != nil {
+		t.Fatalf("Failed to get the object storage secret of the workload identity: (%v)", err)
+	}
+	conf := string(secret.Data[mcoconfig.GeneratedObjStorageKey])
+	if strings.Contains(conf, "access_key") || !strings.Contains(conf, "bucket: bucket") {
+		t.Fatalf("The static keys should be removed from the object storage configuration: %s", conf)
+	}
+	for _, name := range getObjStorageServiceAccountNames(mco.Name) {
+		sa := &corev1.ServiceAccount{}
+		err = c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, sa)
+		if err != nil {
+			t.Fatalf("Failed to get the service account %s: (%v)", name, err)
+		}
+		if sa.Annotations["eks.amazonaws.com/role-arn"] != mco.Spec.StorageConfig.WorkloadIdentity.RoleARN {
+			t.Fatalf("The service account %s should be bound to the IAM role: %v", name, sa.Annotations)
+		}
+	}
+	sa := &corev1.ServiceAccount{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: compactSA.Name, Namespace: namespace}, sa)
+	if err != nil || sa.Annotations["owner"] != "observatorium" {
+		t.Fatalf("The existing annotations of the service account should be kept: %v (%v)", sa.Annotations, err)
+	}
+
+	mco.Spec.StorageConfig.WorkloadIdentity = nil
+	_, err = GenerateObjStorageConfig(c, s, mco)
+	if err != nil {
+		t.Fatalf("Failed to remove the workload identity: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      mcoconfig.GeneratedObjStorageSecretName,
+		Namespace: namespace,
+	}, &corev1.Secret{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The object storage secret of the workload identity should be deleted: (%v)", err)
+	}
+}
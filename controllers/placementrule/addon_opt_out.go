@@ -0,0 +1,159 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	placementv1 "github.com/open-cluster-management/multicloud-operators-placementrule/pkg/apis/apps/v1"
+	obsv1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+const (
+	// obsAddonProtectionFinalizer is removed by the controller before it deletes the observabilityaddon,
+	// so the observabilityaddon which is deleted with the finalizer is deleted by hand
+	obsAddonProtectionFinalizer = "observability.open-cluster-management.io/addon-protection"
+	reasonOptedOut              = "OptedOut"
+)
+
+// checkAddonOptOut returns true if the managed cluster is opted out of the observability. Deleting the
+// observabilityaddon by hand opts the cluster out, which is recorded by the annotation of the
+// ManagedCluster, and the observability is only enabled again once the annotation is removed.
+func checkAddonOptOut(c client.Client, clusterName string, namespace string, now time.Time) (bool, error) {
+	addon := &obsv1beta1.ObservabilityAddon{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: obsAddonName, Namespace: namespace}, addon)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		errorlog.ClusterError(log, err, namespace, "Failed to check observabilityaddon for the opt-out")
+		return false, err
+	}
+	if err == nil && addon.GetDeletionTimestamp() != nil &&
+		util.Contains(addon.GetFinalizers(), obsAddonProtectionFinalizer) {
+		log.Info("observabilityaddon is deleted by hand, opting the cluster out", "cluster", clusterName)
+		if err := recordOptOut(c, clusterName, now); err != nil {
+			return false, err
+		}
+		if err := removeProtectionFinalizer(c, addon); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return isClusterOptedOut(c, clusterName)
+}
+
+// recordOptOut annotates the ManagedCluster with the time when it is opted out
+func recordOptOut(c client.Client, clusterName string, now time.Time) error {
+	cluster := &clusterv1.ManagedCluster{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		errorlog.ClusterError(log, err, clusterName, "Failed to get managedcluster")
+		return err
+	}
+	annotations := cluster.GetAnnotations()
+	if annotations[config.AddonOptedOutAnnotation] != "" {
+		return nil
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[config.AddonOptedOutAnnotation] = now.UTC().Format(time.RFC3339)
+	cluster.SetAnnotations(annotations)
+	err = c.Update(context.TODO(), cluster)
+	if err != nil {
+		errorlog.ClusterError(log, err, clusterName, "Failed to record the opt-out of managedcluster")
+	}
+	return err
+}
+
+// isClusterOptedOut returns true if the ManagedCluster is annotated as opted out
+func isClusterOptedOut(c client.Client, clusterName string) (bool, error) {
+	cluster := &clusterv1.ManagedCluster{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		errorlog.ClusterError(log, err, clusterName, "Failed to get managedcluster")
+		return false, err
+	}
+	return cluster.GetAnnotations()[config.AddonOptedOutAnnotation] != "", nil
+}
+
+// isPlacementDecision returns true if the cluster in the namespace is selected by the placement
+func isPlacementDecision(placement *placementv1.PlacementRule, namespace string) bool {
+	for _, decision := range placement.Status.Decisions {
+		if decision.ClusterNamespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func removeProtectionFinalizer(c client.Client, addon *obsv1beta1.ObservabilityAddon) error {
+	if !util.Contains(addon.GetFinalizers(), obsAddonProtectionFinalizer) {
+		return nil
+	}
+	addon.SetFinalizers(util.Remove(addon.GetFinalizers(), obsAddonProtectionFinalizer))
+	err := c.Update(context.TODO(), addon)
+	if err != nil {
+		errorlog.ClusterError(log, err, addon.Namespace, "Failed to remove the protection finalizer of observabilityaddon")
+	}
+	return err
+}
+
+// updateOptedOutStatus sets the condition of the managedclusteraddon of the opted out cluster, which is
+// kept instead of deleted so that the opt-out is visible
+func updateOptedOutStatus(c client.Client, namespace string) error {
+	return setManagedClusterAddonCondition(c, namespace, metav1.Condition{
+		Type:   "Available",
+		Status: metav1.ConditionFalse,
+		Reason: reasonOptedOut,
+		Message: fmt.Sprintf("The observabilityaddon is deleted by hand, remove the annotation %s "+
+			"of the managedcluster to enable the observability again", config.AddonOptedOutAnnotation),
+	})
+}
+
+// setManagedClusterAddonCondition replaces the conditions of the managedclusteraddon of the cluster
+// whose observabilityaddon is not deployed with the condition which the hub detects
+func setManagedClusterAddonCondition(c client.Client, namespace string, condition metav1.Condition) error {
+	managedclusteraddon := &addonv1alpha1.ManagedClusterAddOn{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      util.ManagedClusterAddonName,
+		Namespace: namespace,
+	}, managedclusteraddon)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		errorlog.ClusterError(log, err, namespace, "Failed to get managedclusteraddon")
+		return err
+	}
+	condition.LastTransitionTime = metav1.NewTime(time.Now())
+	conditions := []metav1.Condition{condition}
+	keepTransitionTime(conditions, managedclusteraddon.Status.Conditions)
+	if reflect.DeepEqual(conditions, managedclusteraddon.Status.Conditions) {
+		return nil
+	}
+	managedclusteraddon.Status.Conditions = conditions
+	err = c.Status().Update(context.TODO(), managedclusteraddon)
+	if err != nil {
+		errorlog.ClusterError(log, err, namespace, "Failed to update status for managedclusteraddon")
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+func TestAddonOptOut(t *testing.T) {
+	initSchema(t)
+
+	cluster := &clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: clusterName}}
+	maddon := &addonv1alpha1.ManagedClusterAddOn{
+		ObjectMeta: metav1.ObjectMeta{Name: util.ManagedClusterAddonName, Namespace: namespace},
+	}
+	c := fake.NewFakeClient(cluster, maddon, newTestRoute())
+
+	err := createObsAddon(c, namespace)
+	if err != nil {
+		t.Fatalf("Failed to create observabilityaddon: (%v)", err)
+	}
+	optedOut, err := checkAddonOptOut(c, clusterName, namespace, time.Now())
+	if err != nil || optedOut {
+		t.Fatalf("The cluster should not be opted out with the observabilityaddon: %v (%v)", optedOut, err)
+	}
+
+	found := &mcov1beta1.ObservabilityAddon{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: obsAddonName, Namespace: namespace}, found)
+	if err != nil {
+		t.Fatalf("Failed to get observabilityaddon: (%v)", err)
+	}
+	if !util.Contains(found.GetFinalizers(), obsAddonProtectionFinalizer) {
+		t.Fatalf("The observabilityaddon should be protected by the finalizer: %v", found.GetFinalizers())
+	}
+	// the deletion by hand keeps the finalizers of the observabilityaddon
+	found.ObjectMeta.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	err = c.Update(context.TODO(), found)
+	if err != nil {
+		t.Fatalf("Failed to update observabilityaddon: (%v)", err)
+	}
+
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	optedOut, err = checkAddonOptOut(c, clusterName, namespace, now)
+	if err != nil || !optedOut {
+		t.Fatalf("The cluster should be opted out once the observabilityaddon is deleted: %v (%v)", optedOut, err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+	if err != nil {
+		t.Fatalf("Failed to get managedcluster: (%v)", err)
+	}
+	if cluster.GetAnnotations()[config.AddonOptedOutAnnotation] != "2021-06-01T00:00:00Z" {
+		t.Fatalf("The opt-out should be recorded in the managedcluster: %v", cluster.GetAnnotations())
+	}
+	deleted := &mcov1beta1.ObservabilityAddon{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: obsAddonName, Namespace: namespace}, deleted)
+	if err != nil && !errors.IsNotFound(err) {
+		t.Fatalf("Failed to get observabilityaddon: (%v)", err)
+	}
+	if err == nil && util.Contains(deleted.GetFinalizers(), obsAddonProtectionFinalizer) {
+		t.Fatalf("The protection finalizer should be removed: %v", deleted.GetFinalizers())
+	}
+
+	err = updateOptedOutStatus(c, namespace)
+	if err != nil {
+		t.Fatalf("Failed to update the status of managedclusteraddon: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: util.ManagedClusterAddonName, Namespace: namespace}, maddon)
+	if err != nil {
+		t.Fatalf("Failed to get managedclusteraddon: (%v)", err)
+	}
+	if len(maddon.Status.Conditions) != 1 || maddon.Status.Conditions[0].Reason != reasonOptedOut {
+		t.Fatalf("The managedclusteraddon should be reported as opted out: %v", maddon.Status.Conditions)
+	}
+
+	// removing the annotation enables the observability again
+	cluster.SetAnnotations(nil)
+	err = c.Update(context.TODO(), cluster)
+	if err != nil {
+		t.Fatalf("Failed to update managedcluster: (%v)", err)
+	}
+	optedOut, err = isClusterOptedOut(c, clusterName)
+	if err != nil || optedOut {
+		t.Fatalf("The cluster should not be opted out without the annotation: %v (%v)", optedOut, err)
+	}
+}
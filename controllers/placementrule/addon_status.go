@@ -0,0 +1,250 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// hubConditionEventsBuffer is the number of the clusters whose hub conditions can be changed at once
+// without waiting for the addon status controller
+const hubConditionEventsBuffer = 1024
+
+// the sources of the hub conditions, in the order in which their conditions are added to the addons
+const (
+	// the manifestworks which are not applied, detected by the placementrule reconcile
+	hubConditionSourceWork = "work"
+	// the skewed clocks, detected by the FleetAnalyzer
+	hubConditionSourceClock = "clock"
+)
+
+var hubConditionSources = []string{hubConditionSourceWork, hubConditionSourceClock}
+
+// hubConditions holds the conditions which the hub detects for the clusters, e.g. the manifestworks which
+// are not applied or the skewed clocks, they are added to the managedclusteraddons by the
+// AddonStatusReconciler. All of them run on the leader only, the store is rebuilt by the first reconcile
+// and analysis after a failover.
+var hubConditions = &hubConditionStore{
+	conditions: map[string]map[string][]metav1.Condition{},
+	events:     make(chan event.GenericEvent, hubConditionEventsBuffer),
+}
+
+type hubConditionStore struct {
+	sync.Mutex
+	// the hub conditions of each cluster namespace by their source
+	conditions map[string]map[string][]metav1.Condition
+	events     chan event.GenericEvent
+}
+
+// get returns the hub conditions of the cluster namespace
+func (s *hubConditionStore) get(namespace string) []metav1.Condition {
+	s.Lock()
+	defer s.Unlock()
+	merged := []metav1.Condition{}
+	for _, source := range hubConditionSources {
+		merged = append(merged, s.conditions[source][namespace]...)
+	}
+	return merged
+}
+
+// set replaces the hub conditions of the clusters from the source, the status of the addons whose hub
+// conditions are changed is refreshed. The change which cannot be queued is kept out of the store, so that
+// it is queued again by the next update from the source.
+func (s *hubConditionStore) set(source string, conditions map[string][]metav1.Condition) {
+	s.Lock()
+	defer s.Unlock()
+	if s.conditions[source] == nil {
+		s.conditions[source] = map[string][]metav1.Condition{}
+	}
+	namespaces := map[string]bool{}
+	for namespace := range s.conditions[source] {
+		namespaces[namespace] = true
+	}
+	for namespace := range conditions {
+		namespaces[namespace] = true
+	}
+	for namespace := range namespaces {
+		if isSameConditions(s.conditions[source][namespace], conditions[namespace]) {
+			continue
+		}
+		addon := &mcov1beta1.ObservabilityAddon{
+			ObjectMeta: metav1.ObjectMeta{Name: obsAddonName, Namespace: namespace},
+		}
+		select {
+		case s.events <- event.GenericEvent{Object: addon}:
+		default:
+			log.Info("The addon status controller is busy, refresh the hub conditions later", "namespace", namespace)
+			continue
+		}
+		if len(conditions[namespace]) == 0 {
+			delete(s.conditions[source], namespace)
+		} else {
+			s.conditions[source][namespace] = conditions[namespace]
+		}
+	}
+}
+
+// isSameConditions returns true if the conditions only differ in the transition time, which the hub
+// conditions are detected with on every reconcile
+func isSameConditions(conditions []metav1.Condition, others []metav1.Condition) bool {
+	if len(conditions) != len(others) {
+		return false
+	}
+	for i := range conditions {
+		if conditions[i].Type != others[i].Type || conditions[i].Status != others[i].Status ||
+			conditions[i].Reason != others[i].Reason || conditions[i].Message != others[i].Message {
+			return false
+		}
+	}
+	return true
+}
+
+// AddonStatusReconciler propagates the status of each observabilityaddon and the hub conditions of its
+// cluster to the managedclusteraddon, and counts the install progress of the addons in the status of
+// the multiclusterobservability. It is separated from the placementrule reconcile, so that a status change only
+// reconciles the addon of its cluster.
+type AddonStatusReconciler struct {
+	Client client.Client
+	// the status of the addon in each cluster namespace
+	statuses map[string]string
+}
+
+// Reconcile updates the status of the managedclusteraddon from the observabilityaddon, and the install
+// progress once the status of the addon is changed or the addon is deleted
+func (r *AddonStatusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if r.statuses == nil {
+		r.statuses = map[string]string{}
+	}
+	addon := &mcov1beta1.ObservabilityAddon{}
+	err := r.Client.Get(ctx, req.NamespacedName, addon)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		log.Error(err, "Failed to get observabilityaddon", "namespace", req.Namespace)
+		return ctrl.Result{}, err
+	}
+	if k8serrors.IsNotFound(err) || addon.GetLabels()[ownerLabelKey] != ownerLabelValue {
+		delete(r.statuses, req.Namespace)
+		return ctrl.Result{}, r.updateProgress()
+	}
+
+	status, err := updateAddonStatus(r.Client, addon, hubConditions.get(req.Namespace))
+	r.statuses[req.Namespace] = status
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, r.updateProgress()
+}
+
+// updateProgress counts the addons by their status into the AddonsInstalled condition of the
+// multiclusterobservability, the status of the clustermanagementaddon has no fields to hold it
+func (r *AddonStatusReconciler) updateProgress() error {
+	available, progressing, degraded := 0, 0, 0
+	for _, status := range r.statuses {
+		switch status {
+		case "Degraded":
+			degraded++
+		case "Available":
+			available++
+		default:
+			progressing++
+		}
+	}
+	if config.GetMonitoringCRName() == "" {
+		return nil
+	}
+	condition := mcoshared.Condition{
+		Type:    config.AddonsInstalledConditionType,
+		Status:  metav1.ConditionTrue,
+		Reason:  "AddonsAvailable",
+		Message: fmt.Sprintf("%d available, %d progressing, %d degraded", available, progressing, degraded),
+	}
+	if degraded > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "AddonsDegraded"
+	} else if progressing > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "AddonsProgressing"
+	}
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		mco := &mcov1beta2.MultiClusterObservability{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: config.GetMonitoringCRName()}, mco)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		updated := condition
+		conditions := []mcoshared.Condition{}
+		for _, existing := range mco.Status.Conditions {
+			if existing.Type != updated.Type {
+				conditions = append(conditions, existing)
+				continue
+			}
+			if existing.Status == updated.Status && existing.Reason == updated.Reason &&
+				existing.Message == updated.Message {
+				return nil
+			}
+			if existing.Status == updated.Status {
+				updated.LastTransitionTime = existing.LastTransitionTime
+			}
+		}
+		if updated.LastTransitionTime.IsZero() {
+			updated.LastTransitionTime = metav1.NewTime(time.Now())
+		}
+		mco.Status.Conditions = append(conditions, updated)
+		err = r.Client.Status().Update(context.TODO(), mco)
+		if err != nil {
+			return err
+		}
+		log.Info("Updated the install progress of the addons", "progress", updated.Message)
+		return nil
+	})
+}
+
+// SetupWithManager watches the status of the observabilityaddons and the changes of the hub conditions
+func (r *AddonStatusReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	isOwnedAddon := func(obj client.Object) bool {
+		return obj.GetName() == obsAddonName && obj.GetLabels()[ownerLabelKey] == ownerLabelValue
+	}
+	addonStatusPred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isOwnedAddon(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			newAddon, newOK := e.ObjectNew.(*mcov1beta1.ObservabilityAddon)
+			oldAddon, oldOK := e.ObjectOld.(*mcov1beta1.ObservabilityAddon)
+			return isOwnedAddon(e.ObjectNew) &&
+				(!newOK || !oldOK || !reflect.DeepEqual(newAddon.Status, oldAddon.Status))
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isOwnedAddon(e.Object)
+		},
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("addon-status").
+		For(&mcov1beta1.ObservabilityAddon{}, builder.WithPredicates(addonStatusPred)).
+		Watches(&source.Channel{Source: hubConditions.events}, &handler.EnqueueRequestForObject{}).
+		Complete(r)
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+func TestAddonStatusReconcile(t *testing.T) {
+	initSchema(t)
+
+	addon := &mcov1beta1.ObservabilityAddon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      obsAddonName,
+			Namespace: namespace,
+			Labels:    map[string]string{ownerLabelKey: ownerLabelValue},
+		},
+		Status: mcov1beta1.ObservabilityAddonStatus{
+			Conditions: []mcov1beta1.StatusCondition{{
+				Type:               "Available",
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(time.Now()),
+				Reason:             "Deployed",
+				Message:            "Metrics collector deployed and functional",
+			}},
+		},
+	}
+	maddon := &addonv1alpha1.ManagedClusterAddOn{
+		ObjectMeta: metav1.ObjectMeta{Name: util.ManagedClusterAddonName, Namespace: namespace},
+	}
+	config.SetMonitoringCRName(mcoName)
+	c := fake.NewFakeClient(addon, maddon, newTestMCO())
+	hubConditions = &hubConditionStore{
+		conditions: map[string]map[string][]metav1.Condition{},
+		events:     make(chan event.GenericEvent, 1),
+	}
+	r := &AddonStatusReconciler{Client: c}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: obsAddonName, Namespace: namespace}}
+	checkStatus := func(progress string) *addonv1alpha1.ManagedClusterAddOn {
+		if _, err := r.Reconcile(context.TODO(), req); err != nil {
+			t.Fatalf("Failed to reconcile the addon status: (%v)", err)
+		}
+		found := &addonv1alpha1.ManagedClusterAddOn{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: util.ManagedClusterAddonName, Namespace: namespace},
+			found)
+		if err != nil {
+			t.Fatalf("Failed to get managedclusteraddon: (%v)", err)
+		}
+		mco := &mcov1beta2.MultiClusterObservability{}
+		err = c.Get(context.TODO(), types.NamespacedName{Name: mcoName}, mco)
+		if err != nil {
+			t.Fatalf("Failed to get multiclusterobservability: (%v)", err)
+		}
+		condition := findAllowlistCondition(mco.Status.Conditions, config.AddonsInstalledConditionType)
+		if condition == nil || condition.Message != progress {
+			t.Errorf("Wrong install progress: %v", mco.Status.Conditions)
+		}
+		return found
+	}
+
+	found := checkStatus("1 available, 0 progressing, 0 degraded")
+	if len(found.Status.Conditions) != 1 || found.Status.Conditions[0].Type != "Available" {
+		t.Errorf("Status not updated correctly in managedclusteraddon: %v", found.Status.Conditions)
+	}
+
+	// the changed hub conditions queue the addon of their cluster
+	staleWork := map[string][]metav1.Condition{
+		namespace: {{
+			Type:               "Degraded",
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(time.Now()),
+			Reason:             ReasonManifestWorkStale,
+		}},
+	}
+	hubConditions.set(hubConditionSourceWork, staleWork)
+	if len(hubConditions.events) != 1 {
+		t.Fatalf("The addon should be queued once its hub conditions are changed")
+	}
+	<-hubConditions.events
+	hubConditions.set(hubConditionSourceWork, staleWork)
+	if len(hubConditions.events) != 0 {
+		t.Errorf("The addon should not be queued with the unchanged hub conditions")
+	}
+	found = checkStatus("0 available, 0 progressing, 1 degraded")
+	if findDegradedCondition(found.Status.Conditions) == nil {
+		t.Errorf("the addon of the stale work should be degraded: %v", found.Status.Conditions)
+	}
+
+	// the deleted addon is not counted
+	if err := c.Delete(context.TODO(), addon); err != nil {
+		t.Fatalf("Failed to delete observabilityaddon: (%v)", err)
+	}
+	checkStatus("0 available, 0 progressing, 0 degraded")
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/audit"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// MetricsAllowlistReconciler converts the legacy custom allowlist configmap, reports the status of the
+// ObservabilityMetricsAllowlists, records their changes into the audit history and reports the impact of
+// the custom allowlist. It is separated from the placementrule reconcile, so that only the changes of the
+// allowlists run it. The allowlists are still pushed to the managed clusters by the placementrule reconcile.
+type MetricsAllowlistReconciler struct {
+	Client client.Client
+}
+
+// Reconcile syncs all the allowlists whichever of them the request is for
+func (r *MetricsAllowlistReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if config.GetMonitoringCRName() == "" {
+		return ctrl.Result{}, nil
+	}
+	mco := &mcov1beta2.MultiClusterObservability{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: config.GetMonitoringCRName()}, mco)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if mco.GetDeletionTimestamp() != nil || config.IsPaused(mco.GetAnnotations()) {
+		return ctrl.Result{}, nil
+	}
+
+	err = convertCustomAllowlist(r.Client)
+	if err != nil {
+		log.Error(err, "Failed to convert the custom metrics allowlist configmap")
+		return ctrl.Result{}, err
+	}
+	err = updateMetricsAllowlistStatus(r.Client)
+	if err != nil {
+		log.Error(err, "Failed to update the status of the metrics allowlists")
+		return ctrl.Result{}, err
+	}
+	err = recordAllowlistVersion(r.Client)
+	if err != nil {
+		log.Error(err, "Failed to record the version of the metrics allowlists")
+		return ctrl.Result{}, err
+	}
+	if err = audit.Flush(r.Client); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	obsAddonList := &mcov1beta1.ObservabilityAddonList{}
+	err = r.Client.List(ctx, obsAddonList, &client.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{ownerLabelKey: ownerLabelValue}),
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	err = updateAllowlistReport(r.Client, mco, len(obsAddonList.Items))
+	if err != nil {
+		log.Error(err, "Failed to update the metrics allowlist report")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager watches the ObservabilityMetricsAllowlists and the allowlist configmaps
+func (r *MetricsAllowlistReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	isAllowlistCM := func(obj client.Object) bool {
+		return obj.GetNamespace() == config.GetDefaultNamespace() &&
+			(obj.GetName() == config.AllowlistConfigMapName || isCustomAllowlist(obj.GetName()))
+	}
+	allowlistCMPred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isAllowlistCM(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isAllowlistCM(e.ObjectNew) &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion()
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isAllowlistCM(e.Object)
+		},
+	}
+	allowlistPred := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// the updates of the status are skipped
+			return e.ObjectNew.GetGeneration() != e.ObjectOld.GetGeneration() ||
+				!reflect.DeepEqual(e.ObjectNew.GetAnnotations(), e.ObjectOld.GetAnnotations())
+		},
+	}
+	mcoPred := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectNew.GetGeneration() != e.ObjectOld.GetGeneration() ||
+				!reflect.DeepEqual(e.ObjectNew.GetAnnotations(), e.ObjectOld.GetAnnotations())
+		},
+	}
+	mapFn := handler.MapFunc(func(a client.Object) []ctrl.Request {
+		return []ctrl.Request{{NamespacedName: types.NamespacedName{Name: config.AllowlistConfigMapName}}}
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("metrics-allowlist").
+		For(&mcov1beta2.ObservabilityMetricsAllowlist{}, builder.WithPredicates(allowlistPred)).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(allowlistCMPred)).
+		Watches(&source.Kind{Type: &mcov1beta2.MultiClusterObservability{}},
+			handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(mcoPred)).
+		Complete(r)
+}
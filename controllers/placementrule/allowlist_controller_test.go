@@ -0,0 +1,38 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestMetricsAllowlistReconcile(t *testing.T) {
+	initSchema(t)
+	config.SetMonitoringCRName(mcoName)
+
+	c := fake.NewFakeClient(newTestMCO(), NewMetricsAllowListCM(), NewMetricsCustomAllowListCM())
+	r := &MetricsAllowlistReconciler{Client: c}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: config.AllowlistConfigMapName}}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("Failed to reconcile the metrics allowlists: (%v)", err)
+	}
+
+	converted := &mcov1beta2.ObservabilityMetricsAllowlist{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: config.AllowlistCustomConfigMapName}, converted)
+	if err != nil {
+		t.Fatalf("The custom allowlist configmap should be converted: (%v)", err)
+	}
+	ready := findAllowlistCondition(converted.Status.Conditions, "Ready")
+	if ready == nil || ready.Reason != "ConvertedFromConfigMap" {
+		t.Errorf("the status of the converted allowlist should be reported: %v", converted.Status.Conditions)
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	allowlistReportKey = "report.yaml"
+	// the resource version of the reported custom allowlist, the report is only
+	// regenerated when the custom allowlist changes
+	allowlistSourceVersionAnnotation = "observability.open-cluster-management.io/source-resource-version"
+)
+
+// AllowlistReport shows the impact of the custom allowlist before it is pushed to the managed clusters
+type AllowlistReport struct {
+	// Source is the name of the reported custom allowlist configmap
+	Source string `yaml:"source"`
+	// Added are the entries which are not in the default allowlist
+	Added []MetricImpact `yaml:"added"`
+	// Removed are the entries of the pushed custom allowlist which are not in the preview
+	Removed []MetricImpact `yaml:"removed"`
+	// Unchanged are the entries which are already in the default allowlist
+	Unchanged []string `yaml:"unchanged,omitempty"`
+	// EstimatedSeriesDelta is the estimated change of the series on the hub
+	EstimatedSeriesDelta int64 `yaml:"estimated-series-delta"`
+}
+
+// MetricImpact is the cardinality of an allowlist entry, which is a metric name or a match expression
+type MetricImpact struct {
+	Metric string `yaml:"metric"`
+	// CurrentSeries is the number of the series on the hub now
+	CurrentSeries int64 `yaml:"current-series"`
+	// ReportingClusters is the number of the clusters which have the series on the hub now
+	ReportingClusters int `yaml:"reporting-clusters"`
+	// EstimatedSeries is the number of the series once all the managed clusters forward it,
+	// it is 0 if none of the clusters have the series so that it cannot be estimated
+	EstimatedSeries int64 `yaml:"estimated-series"`
+}
+
+// thanosQuerier runs the instant queries against the thanos on the hub
+type thanosQuerier struct {
+	url        string
+	httpClient *http.Client
+}
+
+var newThanosQuerier = func(mco *mcov1beta2.MultiClusterObservability) *thanosQuerier {
+	return &thanosQuerier{
+		url:        config.GetThanosQueryFrontendURL(mco.Name),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type queryResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// vectorSample is a sample of the instant vector
+type vectorSample struct {
+	labels map[string]string
+	value  float64
+}
+
+func (q *thanosQuerier) query(query string) ([]vectorSample, error) {
+	resp, err := q.httpClient.Get(q.url + "/api/v1/query?query=" + url.QueryEscape(query))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to query %s: %s", query, resp.Status)
+	}
+	result := &queryResponse{}
+	err = json.NewDecoder(resp.Body).Decode(result)
+	if err != nil {
+		return nil, err
+	}
+	samples := []vectorSample{}
+	for _, sample := range result.Data.Result {
+		if len(sample.Value) != 2 {
+			continue
+		}
+		value, ok := sample.Value[1].(string)
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, vectorSample{labels: sample.Metric, value: v})
+	}
+	return samples, nil
+}
+
+// countByCluster counts the series of the selector per cluster
+func (q *thanosQuerier) countByCluster(selector string) (map[string]int64, error) {
+	samples, err := q.query(fmt.Sprintf("count by (%s) ({%s})", config.GetClusterNameLabelKey(), selector))
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int64{}
+	for _, sample := range samples {
+		counts[sample.labels[config.GetClusterNameLabelKey()]] = int64(sample.value)
+	}
+	return counts, nil
+}
+
+// updateAllowlistReport reports the impact of the custom allowlist preview if it exists, or the
+// custom allowlist otherwise. The report is removed when there is no custom allowlist at all.
+func updateAllowlistReport(c client.Client, mco *mcov1beta2.MultiClusterObservability, clusters int) error {
+	namespace := config.GetDefaultNamespace()
+	source := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(),
+		types.NamespacedName{Name: config.AllowlistPreviewConfigMapName, Namespace: namespace}, source)
+	if k8serrors.IsNotFound(err) {
+		err = c.Get(context.TODO(),
+			types.NamespacedName{Name: config.AllowlistCustomConfigMapName, Namespace: namespace}, source)
+	}
+	if k8serrors.IsNotFound(err) {
+		return deleteAllowlistReport(c)
+	}
+	if err != nil {
+		return err
+	}
+
+	found := &corev1.ConfigMap{}
+	err = c.Get(context.TODO(),
+		types.NamespacedName{Name: config.AllowlistReportConfigMapName, Namespace: namespace}, found)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	sourceVersion := source.Name + "/" + source.ResourceVersion
+	if err == nil && found.Annotations[allowlistSourceVersionAnnotation] == sourceVersion {
+		return nil
+	}
+
+	report, err := newAllowlistReport(c, mco, source, clusters)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(report)
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        config.AllowlistReportConfigMapName,
+			Namespace:   namespace,
+			Annotations: map[string]string{allowlistSourceVersionAnnotation: sourceVersion},
+		},
+		Data: map[string]string{allowlistReportKey: string(data)},
+	}
+	if found.Name == "" {
+		log.Info("Creating the metrics allowlist report", "source", source.Name)
+		return c.Create(context.TODO(), cm)
+	}
+	if !reflect.DeepEqual(found.Data, cm.Data) || !reflect.DeepEqual(found.Annotations, cm.Annotations) {
+		log.Info("Updating the metrics allowlist report", "source", source.Name)
+		found.Annotations = cm.Annotations
+		found.Data = cm.Data
+		return c.Update(context.TODO(), found)
+	}
+	return nil
+}
+
+func newAllowlistReport(c client.Client, mco *mcov1beta2.MultiClusterObservability,
+	source *corev1.ConfigMap, clusters int) (*AllowlistReport, error) {
+	candidate, err := unmarshalCustomAllowlist(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the allowlist in %s: %v", source.Name, err)
+	}
+
+	defaults := map[string]bool{}
+	keys := append([]string{metricsListKey, selfMetricsListKey},
+		getEnabledMetricsListKeys(mco.Spec.ObservabilityAddonSpec)...)
+	for _, key := range keys {
+		allowlist, err := getAllowList(c, config.AllowlistConfigMapName, key)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range getAllowlistEntries(allowlist) {
+			defaults[entry] = true
+		}
+	}
+
+	report := &AllowlistReport{Source: source.Name, Added: []MetricImpact{}, Removed: []MetricImpact{}}
+	querier := newThanosQuerier(mco)
+	candidates := map[string]bool{}
+	for _, entry := range getAllowlistEntries(candidate) {
+		candidates[entry] = true
+		if defaults[entry] {
+			report.Unchanged = append(report.Unchanged, entry)
+			continue
+		}
+		impact := newMetricImpact(querier, entry, clusters)
+		report.Added = append(report.Added, impact)
+		report.EstimatedSeriesDelta += impact.EstimatedSeries - impact.CurrentSeries
+	}
+
+	// the entries which are dropped when the preview replaces the pushed custom allowlist
+	if source.Name == config.AllowlistPreviewConfigMapName {
+		currentCM, err := getCustomAllowlistCM(c)
+		if err != nil {
+			return nil, err
+		}
+		if currentCM != nil {
+			current, err := unmarshalCustomAllowlist(currentCM)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal the allowlist in %s: %v", currentCM.Name, err)
+			}
+			for _, entry := range getAllowlistEntries(current) {
+				if candidates[entry] || defaults[entry] {
+					continue
+				}
+				impact := newMetricImpact(querier, entry, clusters)
+				impact.EstimatedSeries = 0
+				report.Removed = append(report.Removed, impact)
+				report.EstimatedSeriesDelta -= impact.CurrentSeries
+			}
+		}
+	}
+	return report, nil
+}
+
+// newMetricImpact estimates the series of the entry once all the clusters forward it
+// from the average series of the clusters which already have it on the hub
+func newMetricImpact(querier *thanosQuerier, entry string, clusters int) MetricImpact {
+	impact := MetricImpact{Metric: entry}
+	selector := entry
+	if !isMatchExpression(entry) {
+		selector = fmt.Sprintf(`__name__="%s"`, entry)
+	}
+	counts, err := querier.countByCluster(selector)
+	if err != nil {
+		log.Info("Failed to count the series on the hub", "metric", entry, "error", err.Error())
+		return impact
+	}
+	for _, count := range counts {
+		impact.CurrentSeries += count
+	}
+	impact.ReportingClusters = len(counts)
+	if impact.ReportingClusters > 0 {
+		if clusters < impact.ReportingClusters {
+			clusters = impact.ReportingClusters
+		}
+		impact.EstimatedSeries = impact.CurrentSeries * int64(clusters) / int64(impact.ReportingClusters)
+	}
+	return impact
+}
+
+func getAllowlistEntries(allowlist *MetricsAllowlist) []string {
+	entries := append([]string{}, allowlist.NameList...)
+	return append(entries, allowlist.MatchList...)
+}
+
+// isMatchExpression returns true if the entry is a match expression, e.g. __name__="etcd_b",job="etcd"
+func isMatchExpression(entry string) bool {
+	return strings.ContainsAny(entry, "=~")
+}
+
+func deleteAllowlistReport(c client.Client) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.AllowlistReportConfigMapName,
+			Namespace: config.GetDefaultNamespace(),
+		},
+	}
+	err := c.Delete(context.TODO(), cm)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// isCustomAllowlist returns true for the custom allowlist and its preview
+func isCustomAllowlist(name string) bool {
+	return name == config.AllowlistCustomConfigMapName || name == config.AllowlistPreviewConfigMapName
+}
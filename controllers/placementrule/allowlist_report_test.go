@@ -0,0 +1,127 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestUpdateAllowlistReport(t *testing.T) {
+	initSchema(t)
+
+	// the metric c is on the hub for 2 clusters, the others are not
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Query().Get("query"), `__name__="c"`) {
+			w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+{"metric":{"cluster":"c1"},"value":[1,"10"]},
+{"metric":{"cluster":"c2"},"value":[1,"30"]}]}}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer server.Close()
+	newThanosQuerierFn := newThanosQuerier
+	newThanosQuerier = func(mco *mcov1beta2.MultiClusterObservability) *thanosQuerier {
+		return &thanosQuerier{url: server.URL, httpClient: server.Client()}
+	}
+	defer func() { newThanosQuerier = newThanosQuerierFn }()
+
+	preview := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.AllowlistPreviewConfigMapName,
+			Namespace: mcoNamespace,
+		},
+		Data: map[string]string{"metrics_list.yaml": `
+  names:
+    - a
+    - f
+  matches:
+    - __name__="g",job="etcd"
+`},
+	}
+	mco := newTestMCO()
+	c := fake.NewFakeClient(NewMetricsAllowListCM(), NewMetricsCustomAllowListCM(), preview)
+
+	err := updateAllowlistReport(c, mco, 4)
+	if err != nil {
+		t.Fatalf("Failed to update the allowlist report: (%v)", err)
+	}
+	found := &corev1.ConfigMap{}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      config.AllowlistReportConfigMapName,
+		Namespace: mcoNamespace,
+	}, found)
+	if err != nil {
+		t.Fatalf("Failed to get the allowlist report: (%v)", err)
+	}
+	report := &AllowlistReport{}
+	err = yaml.Unmarshal([]byte(found.Data[allowlistReportKey]), report)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the allowlist report: (%v)", err)
+	}
+	if report.Source != config.AllowlistPreviewConfigMapName {
+		t.Errorf("Wrong source of the report: %s", report.Source)
+	}
+	if len(report.Unchanged) != 1 || report.Unchanged[0] != "a" {
+		t.Errorf("Wrong unchanged entries: %v", report.Unchanged)
+	}
+	if len(report.Added) != 2 || report.Added[0].Metric != "f" || report.Added[1].Metric != `__name__="g",job="etcd"` {
+		t.Errorf("Wrong added entries: %v", report.Added)
+	}
+	// c and d of the custom allowlist are removed, c has 40 series on the hub
+	if len(report.Removed) != 2 || report.Removed[0].Metric != "c" || report.Removed[0].CurrentSeries != 40 {
+		t.Errorf("Wrong removed entries: %v", report.Removed)
+	}
+	if report.EstimatedSeriesDelta != -40 {
+		t.Errorf("Wrong estimated series delta: %d", report.EstimatedSeriesDelta)
+	}
+
+	err = c.Delete(context.TODO(), preview)
+	if err != nil {
+		t.Fatalf("Failed to delete the preview: (%v)", err)
+	}
+	err = c.Delete(context.TODO(), NewMetricsCustomAllowListCM())
+	if err != nil {
+		t.Fatalf("Failed to delete the custom allowlist: (%v)", err)
+	}
+	err = updateAllowlistReport(c, mco, 4)
+	if err != nil {
+		t.Fatalf("Failed to update the allowlist report: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      config.AllowlistReportConfigMapName,
+		Namespace: mcoNamespace,
+	}, found)
+	if err == nil {
+		t.Fatalf("The allowlist report should be removed without the custom allowlist")
+	}
+}
+
+func TestNewMetricImpact(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+{"metric":{"cluster":"c1"},"value":[1,"10"]},
+{"metric":{"cluster":"c2"},"value":[1,"30"]}]}}`))
+	}))
+	defer server.Close()
+
+	querier := &thanosQuerier{url: server.URL, httpClient: server.Client()}
+	impact := newMetricImpact(querier, "c", 4)
+	if impact.CurrentSeries != 40 || impact.ReportingClusters != 2 || impact.EstimatedSeries != 80 {
+		t.Fatalf("Wrong metric impact: %v", impact)
+	}
+}
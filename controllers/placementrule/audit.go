@@ -0,0 +1,131 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/audit"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+var (
+	// the versions of the allowlists which are pushed to the managed clusters
+	appliedAllowlistVersion = ""
+	appliedAllowlistData    = ""
+)
+
+// manifestsToYAML renders the manifests of the manifestwork to be compared in the audit history,
+// the data of the secrets is replaced with its hash so that it is not exposed in the history
+func manifestsToYAML(manifests []workv1.Manifest) string {
+	docs := []string{}
+	for _, manifest := range manifests {
+		raw := manifest.Raw
+		if raw == nil && manifest.Object != nil {
+			raw, _ = json.Marshal(manifest.Object)
+		}
+		obj := map[string]interface{}{}
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			continue
+		}
+		if obj["kind"] == "Secret" {
+			redactSecretData(obj, "data")
+			redactSecretData(obj, "stringData")
+		}
+		doc, err := yaml.Marshal(obj)
+		if err != nil {
+			continue
+		}
+		docs = append(docs, string(doc))
+	}
+	return strings.Join(docs, "---\n")
+}
+
+func redactSecretData(obj map[string]interface{}, field string) {
+	data, ok := obj[field].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for key, value := range data {
+		data[key] = fmt.Sprintf("<redacted sha256:%x>", sha256.Sum256([]byte(fmt.Sprint(value))))
+	}
+}
+
+// recordManifestWorkChange records the manifestwork which is created or updated for the managed cluster
+func recordManifestWorkChange(found, work *workv1.ManifestWork, action string) {
+	entry := audit.Entry{
+		Kind:    audit.KindManifestWork,
+		Cluster: work.Namespace,
+		Name:    work.Name,
+		Action:  action,
+	}
+	if found != nil {
+		entry.Diff = audit.Diff(manifestsToYAML(found.Spec.Workload.Manifests),
+			manifestsToYAML(work.Spec.Workload.Manifests))
+	}
+	audit.Record(entry)
+}
+
+// recordAllowlistVersion records the change of the default and the custom metrics allowlists and the
+// ObservabilityMetricsAllowlists, which are pushed to all the managed clusters
+func recordAllowlistVersion(c client.Client) error {
+	versions := []string{}
+	data := []string{}
+	for _, name := range []string{config.AllowlistConfigMapName, config.AllowlistCustomConfigMapName} {
+		cm := &corev1.ConfigMap{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: config.GetDefaultNamespace()}, cm)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		versions = append(versions, name+"@"+cm.ResourceVersion)
+		content, err := yaml.Marshal(cm.Data)
+		if err != nil {
+			return err
+		}
+		data = append(data, "# "+name+"\n"+string(content))
+	}
+	allowlists, err := listMetricsAllowlists(c)
+	if err != nil {
+		return err
+	}
+	for _, allowlist := range allowlists {
+		// the generation is not changed by the updates of the status
+		name := "ObservabilityMetricsAllowlist/" + allowlist.Name
+		versions = append(versions, fmt.Sprintf("%s@%d", name, allowlist.Generation))
+		content, err := yaml.Marshal(allowlist.Spec)
+		if err != nil {
+			return err
+		}
+		data = append(data, "# "+name+"\n"+string(content))
+	}
+
+	version := strings.Join(versions, ",")
+	if version == appliedAllowlistVersion {
+		return nil
+	}
+	newData := strings.Join(data, "")
+	audit.Record(audit.Entry{
+		Kind:    audit.KindAllowlist,
+		Name:    config.AllowlistConfigMapName,
+		Action:  audit.ActionUpdated,
+		Version: version,
+		Diff:    audit.Diff(appliedAllowlistData, newData),
+	})
+	appliedAllowlistVersion, appliedAllowlistData = version, newData
+	return nil
+}
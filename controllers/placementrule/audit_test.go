@@ -0,0 +1,74 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/audit"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestManifestsToYAML(t *testing.T) {
+	secret := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "observability-managed-cluster-certs"},
+		Data:       map[string][]byte{"tls.key": []byte("private-key")},
+	}
+	cm := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: config.AllowlistConfigMapName},
+		Data:       map[string]string{metricsListKey: "names:\n- up\n"},
+	}
+	manifests := []workv1.Manifest{
+		{RawExtension: runtime.RawExtension{Object: secret}},
+		{RawExtension: runtime.RawExtension{Object: cm}},
+	}
+	out := manifestsToYAML(manifests)
+	if strings.Contains(out, "cHJpdmF0ZS1rZXk=") || !strings.Contains(out, "redacted sha256") {
+		t.Errorf("The data of the secret should be redacted: %s", out)
+	}
+	if !strings.Contains(out, "- up") {
+		t.Errorf("The allowlist should be rendered: %s", out)
+	}
+}
+
+func TestRecordAllowlistVersion(t *testing.T) {
+	initSchema(t)
+	appliedAllowlistVersion, appliedAllowlistData = "", ""
+
+	allowlist := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            config.AllowlistConfigMapName,
+			Namespace:       mcoNamespace,
+			ResourceVersion: "1",
+		},
+		Data: map[string]string{metricsListKey: "names:\n- up\n"},
+	}
+	c := fake.NewFakeClient(allowlist)
+	if err := recordAllowlistVersion(c); err != nil {
+		t.Fatalf("Failed to record the allowlist version: (%v)", err)
+	}
+	version := appliedAllowlistVersion
+	if !strings.Contains(version, config.AllowlistConfigMapName) {
+		t.Errorf("Wrong version of the allowlists: %s", version)
+	}
+	if err := recordAllowlistVersion(c); err != nil || appliedAllowlistVersion != version {
+		t.Errorf("The unchanged allowlists should not be recorded again: %s (%v)", appliedAllowlistVersion, err)
+	}
+	if err := audit.Flush(c); err != nil {
+		t.Fatalf("Failed to flush the audit history: (%v)", err)
+	}
+	history, err := audit.GetHistory(c)
+	if err != nil || len(history) == 0 || history[len(history)-1].Kind != audit.KindAllowlist {
+		t.Errorf("The allowlist version is not recorded: %v (%v)", history, err)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"time"
+
+	obsv1alpha1 "github.com/open-cluster-management/observatorium-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+const (
+	authMigrationWindow = time.Minute
+	// the interval to check again whether the gateway accepts the tokens
+	gatewayReadyCheckInterval = 30 * time.Second
+)
+
+// the migration window is kept by the leader, the mode of each cluster is recorded on its addon
+var (
+	// the times when the managed clusters are switched in the last migration window
+	authMigrationTimes = []time.Time{}
+	// authMigrationPending is true if the switch of some clusters is deferred in the reconcile
+	authMigrationPending = false
+)
+
+// isGatewayTokenReady returns true once the observatorium API accepts the service account tokens, which
+// is when the tenant of the Observatorium CR validates them and the API is rolled out with it
+func isGatewayTokenReady(c client.Client, mco *mcov1beta2.MultiClusterObservability) (bool, error) {
+	obs := &obsv1alpha1.Observatorium{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: mco.Name, Namespace: config.GetDefaultNamespace()}, obs)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		log.Error(err, "Failed to get the observatorium CR")
+		return false, err
+	}
+	accepted := false
+	for _, tenant := range obs.Spec.API.Tenants {
+		if tenant.OIDC != nil && tenant.OIDC.ClientID == config.GetTokenAudience(mco) {
+			accepted = true
+		}
+	}
+	if !accepted {
+		return false, nil
+	}
+
+	api := &appsv1.Deployment{}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      mco.Name + "-observatorium-api",
+		Namespace: config.GetDefaultNamespace(),
+	}, api)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		log.Error(err, "Failed to get the deployment of the observatorium API")
+		return false, err
+	}
+	replicas := int32(1)
+	if api.Spec.Replicas != nil {
+		replicas = *api.Spec.Replicas
+	}
+	return api.Status.ObservedGeneration >= api.Generation && api.Status.UpdatedReplicas == replicas &&
+		api.Status.AvailableReplicas == replicas, nil
+}
+
+// throttleAuthMigration returns true if the managed cluster can be switched in the migration window
+func throttleAuthMigration(mco *mcov1beta2.MultiClusterObservability, clusterName string, now time.Time) bool {
+	rate := config.GetAuthMigrationRate(mco)
+	if rate <= 0 {
+		return true
+	}
+	recent := []time.Time{}
+	for _, switchedAt := range authMigrationTimes {
+		if now.Sub(switchedAt) < authMigrationWindow {
+			recent = append(recent, switchedAt)
+		}
+	}
+	authMigrationTimes = recent
+	if len(authMigrationTimes) >= int(rate) {
+		log.Info("Deferring the switch of the authentication for the migration rate", "cluster", clusterName, "rate", rate)
+		authMigrationPending = true
+		return false
+	}
+	authMigrationTimes = append(authMigrationTimes, now)
+	return true
+}
+
+// isClientCertIssued returns true if the managed cluster has a valid client certificate, which is
+// requested again once it switches back and is recorded on its addon once it is signed
+func isClientCertIssued(addon *addonv1alpha1.ManagedClusterAddOn, now time.Time) bool {
+	notAfter, err := time.Parse(time.RFC3339, addon.Annotations[config.ClientCertNotAfterAnnotation])
+	return err == nil && now.Before(notAfter)
+}
+
+func setAuthMode(c client.Client, addon *addonv1alpha1.ManagedClusterAddOn, mode string, now time.Time) error {
+	if addon.Annotations == nil {
+		addon.Annotations = map[string]string{}
+	}
+	if mode == "" {
+		delete(addon.Annotations, config.AuthModeAnnotation)
+		delete(addon.Annotations, config.AuthModeSinceAnnotation)
+	} else {
+		addon.Annotations[config.AuthModeAnnotation] = mode
+		addon.Annotations[config.AuthModeSinceAnnotation] = now.UTC().Format(time.RFC3339)
+	}
+	err := c.Update(context.TODO(), addon)
+	if err != nil {
+		log.Error(err, "Failed to record the authentication of the managed cluster", "cluster", addon.Namespace)
+		return err
+	}
+	log.Info("Switching the authentication of the managed cluster", "cluster", addon.Namespace, "mode", mode)
+	return nil
+}
+
+// getClusterAuthMode returns the authentication which the managed cluster uses in its hub info. The
+// cluster is switched to the tokens only once the gateway accepts them, and switched back to the client
+// certificate only once it is issued, the gateway accepts both in the meantime. The switches are throttled
+// by the migration rate, and recorded on the ManagedClusterAddOn of the cluster.
+func getClusterAuthMode(c client.Client, mco *mcov1beta2.MultiClusterObservability,
+	clusterName string, now time.Time) (string, error) {
+	addon := &addonv1alpha1.ManagedClusterAddOn{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: util.ManagedClusterAddonName, Namespace: clusterName}, addon)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			// the addon is created after the hub info, the cluster is switched in the next reconcile
+			return "", nil
+		}
+		log.Error(err, "Failed to get the managedclusteraddon", "cluster", clusterName)
+		return "", err
+	}
+	current := addon.Annotations[config.AuthModeAnnotation]
+	selected, err := isTokenAuthCluster(c, clusterName, mco)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case selected && current == config.TokenAuthMode:
+		return config.TokenAuthMode, nil
+	case selected && current == config.RevertingAuthMode:
+		// the tokens are still accepted
+		return config.TokenAuthMode, setAuthMode(c, addon, config.TokenAuthMode, now)
+	case selected:
+		ready, err := isGatewayTokenReady(c, mco)
+		if err != nil {
+			return "", err
+		}
+		if !ready {
+			log.Info("Waiting for the gateway to accept the tokens", "cluster", clusterName)
+			authMigrationPending = true
+			return "", nil
+		}
+		if !throttleAuthMigration(mco, clusterName, now) {
+			return "", nil
+		}
+		return config.TokenAuthMode, setAuthMode(c, addon, config.TokenAuthMode, now)
+	case current == config.TokenAuthMode:
+		if !throttleAuthMigration(mco, clusterName, now) {
+			return config.TokenAuthMode, nil
+		}
+		return config.TokenAuthMode, setAuthMode(c, addon, config.RevertingAuthMode, now)
+	case current == config.RevertingAuthMode:
+		if !isClientCertIssued(addon, now) {
+			log.Info("Waiting for the client certificate to be issued", "cluster", clusterName)
+			authMigrationPending = true
+			return config.TokenAuthMode, nil
+		}
+		return "", setAuthMode(c, addon, "", now)
+	}
+	return "", nil
+}
+
+// nextAuthMigration returns the duration until the deferred clusters can be switched, or 0 if no
+// cluster is deferred
+func nextAuthMigration(now time.Time) time.Duration {
+	if !authMigrationPending {
+		return 0
+	}
+	if len(authMigrationTimes) == 0 {
+		return gatewayReadyCheckInterval
+	}
+	next := authMigrationTimes[0].Add(authMigrationWindow).Sub(now)
+	if next < time.Second {
+		next = time.Second
+	}
+	return next
+}
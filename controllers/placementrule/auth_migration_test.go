@@ -0,0 +1,154 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	obsv1alpha1 "github.com/open-cluster-management/observatorium-operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+func newTestAddon(cluster string, annotations map[string]string) *addonv1alpha1.ManagedClusterAddOn {
+	return &addonv1alpha1.ManagedClusterAddOn{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        util.ManagedClusterAddonName,
+			Namespace:   cluster,
+			Annotations: annotations,
+		},
+	}
+}
+
+// newTestGatewayObjs returns the Observatorium CR and the deployment of the observatorium API, which
+// accept the tokens once the API is rolled out
+func newTestGatewayObjs(mco *mcov1beta2.MultiClusterObservability, rolledOut bool) []runtime.Object {
+	replicas := int32(2)
+	api := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mco.Name + "-observatorium-api",
+			Namespace: config.GetDefaultNamespace(),
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	if rolledOut {
+		api.Status = appsv1.DeploymentStatus{UpdatedReplicas: replicas, AvailableReplicas: replicas}
+	}
+	return []runtime.Object{
+		&obsv1alpha1.Observatorium{
+			ObjectMeta: metav1.ObjectMeta{Name: mco.Name, Namespace: config.GetDefaultNamespace()},
+			Spec: obsv1alpha1.ObservatoriumSpec{
+				API: obsv1alpha1.APISpec{
+					Tenants: []obsv1alpha1.APITenant{
+						{
+							Name: config.GetDefaultTenantName(),
+							OIDC: &obsv1alpha1.TenantOIDC{ClientID: config.GetTokenAudience(mco)},
+						},
+					},
+				},
+			},
+		},
+		api,
+	}
+}
+
+func recordTestClientCert(t *testing.T, c client.Client, cluster string, notAfter time.Time) {
+	addon := &addonv1alpha1.ManagedClusterAddOn{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: util.ManagedClusterAddonName, Namespace: cluster}, addon)
+	if err != nil {
+		t.Fatalf("Failed to get the managedclusteraddon: (%v)", err)
+	}
+	if addon.Annotations == nil {
+		addon.Annotations = map[string]string{}
+	}
+	addon.Annotations[config.ClientCertNotAfterAnnotation] = notAfter.UTC().Format(time.RFC3339)
+	if err := c.Update(context.TODO(), addon); err != nil {
+		t.Fatalf("Failed to record the client certificate: (%v)", err)
+	}
+}
+
+func TestAuthMigration(t *testing.T) {
+	initSchema(t)
+	authMigrationTimes = []time.Time{}
+
+	mco := newTestMCO()
+	mco.Spec.GatewayAuth = &mcov1beta2.GatewayAuthSpec{
+		ServiceAccountToken: &mcov1beta2.GatewayServiceAccountTokenSpec{Enabled: true},
+		MigrationRate:       1,
+	}
+	objs := []runtime.Object{
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: clusterName}},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: clusterName2}},
+		newTestAddon(clusterName, nil),
+		newTestAddon(clusterName2, nil),
+	}
+	now := time.Now()
+
+	// the clusters are not switched before the gateway accepts the tokens
+	c := fake.NewFakeClient(append(objs, newTestGatewayObjs(mco, false)...)...)
+	authMigrationPending = false
+	mode, err := getClusterAuthMode(c, mco, clusterName, now)
+	if err != nil {
+		t.Fatalf("Failed to get the authentication of the cluster: (%v)", err)
+	}
+	if mode != "" || nextAuthMigration(now) != gatewayReadyCheckInterval {
+		t.Errorf("the cluster should wait for the gateway: %s", mode)
+	}
+
+	// the clusters are switched in the migration rate
+	c = fake.NewFakeClient(append(objs, newTestGatewayObjs(mco, true)...)...)
+	authMigrationPending = false
+	for _, expected := range []struct{ cluster, mode string }{
+		{clusterName, config.TokenAuthMode},
+		{clusterName2, ""},
+	} {
+		mode, err := getClusterAuthMode(c, mco, expected.cluster, now)
+		if err != nil {
+			t.Fatalf("Failed to get the authentication of the cluster: (%v)", err)
+		}
+		if mode != expected.mode {
+			t.Errorf("the authentication of %s should be %q: %q", expected.cluster, expected.mode, mode)
+		}
+	}
+	if next := nextAuthMigration(now); next != authMigrationWindow {
+		t.Errorf("the deferred cluster should be switched in the next window: %v", next)
+	}
+	mode, err = getClusterAuthMode(c, mco, clusterName2, now.Add(authMigrationWindow))
+	if err != nil || mode != config.TokenAuthMode {
+		t.Errorf("the deferred cluster should be switched in the next window: %q (%v)", mode, err)
+	}
+
+	// the clusters keep the tokens until their client certificates are issued
+	mco.Spec.GatewayAuth.ServiceAccountToken.Enabled = false
+	mco.Spec.GatewayAuth.MigrationRate = 0
+	mode, err = getClusterAuthMode(c, mco, clusterName, now)
+	if err != nil || mode != config.TokenAuthMode {
+		t.Errorf("the cluster should keep the tokens: %q (%v)", mode, err)
+	}
+	addon := &addonv1alpha1.ManagedClusterAddOn{}
+	_ = c.Get(context.TODO(), types.NamespacedName{Name: util.ManagedClusterAddonName, Namespace: clusterName}, addon)
+	if addon.Annotations[config.AuthModeAnnotation] != config.RevertingAuthMode {
+		t.Errorf("the cluster should switch back to the client certificate: %v", addon.Annotations)
+	}
+	if inUse, _ := config.IsTokenAuthInUse(c, mco); !inUse {
+		t.Errorf("the tokens should be accepted until the clusters switch back")
+	}
+	recordTestClientCert(t, c, clusterName, now.Add(time.Hour))
+	mode, err = getClusterAuthMode(c, mco, clusterName, now)
+	if err != nil || mode != "" {
+		t.Errorf("the cluster should authenticate with the client certificate: %q (%v)", mode, err)
+	}
+}
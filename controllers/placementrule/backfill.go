@@ -0,0 +1,376 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+const (
+	backfillName          = "observability-backfill"
+	backfillRulesKey      = "rules.yaml"
+	backfillHTTPConfigKey = "http-config.yaml"
+	backfillStorageKey    = "thanos.yaml"
+	backfillBlocksPath    = "/var/backfill/blocks"
+	backfillUploadPath    = "/var/backfill/upload"
+	backfillConfigPath    = "/etc/backfill/config"
+	backfillStoragePath   = "/etc/backfill/storage"
+	// the local Prometheus of OpenShift is readable with the cluster-monitoring-view role
+	backfillClusterRole = "cluster-monitoring-view"
+	// the default evaluation interval of the backfill when the interval of the addon is not set
+	defaultBackfillInterval = int32(30)
+)
+
+// backfillRuleGroups is the rule file which promtool evaluates against the local Prometheus to
+// create the blocks of the history
+type backfillRuleGroups struct {
+	Groups []backfillRuleGroup `yaml:"groups"`
+}
+
+type backfillRuleGroup struct {
+	Name     string          `yaml:"name"`
+	Interval string          `yaml:"interval"`
+	Rules    []RecordingRule `yaml:"rules"`
+}
+
+// getBackfillManifests returns the manifests of the one-shot job which backfills the recent history
+// of the local Prometheus of the managed cluster into the object storage of the hub. The job is only
+// shipped to the cluster which is onboarded while the backfill is enabled, i.e. whose manifestwork
+// does not exist yet, and it is kept unchanged in the manifestwork afterwards so that it is not
+// run again.
+func getBackfillManifests(c client.Client, workName, clusterNamespace, clusterName string,
+	mco *mcov1beta2.MultiClusterObservability, allowlistCM *corev1.ConfigMap) ([]workv1.Manifest, error) {
+	if !config.IsBackfillEnabled(mco) {
+		return nil, nil
+	}
+	if err := config.ValidateBackfill(mco); err != nil {
+		log.Error(err, "Invalid backfill, skip it", "cluster", clusterName)
+		return nil, nil
+	}
+
+	var job *workv1.Manifest
+	found := &workv1.ManifestWork{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: workName, Namespace: clusterNamespace}, found)
+	if err == nil {
+		job = findBackfillJob(found)
+		if job == nil {
+			// the cluster is onboarded before the backfill is enabled
+			return nil, nil
+		}
+	} else if !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	storage, err := config.GetBackfillUploadStorage(c, mco)
+	if err != nil {
+		// the missing upload storage does not block the other manifests of the cluster
+		log.Error(err, "Failed to get the upload storage of the backfill, skip it", "cluster", clusterName)
+		return nil, nil
+	}
+	allowlist := &MetricsAllowlist{}
+	if err := yaml.Unmarshal([]byte(allowlistCM.Data[metricsListKey]), allowlist); err != nil {
+		log.Error(err, "Failed to unmarshal the metrics allowlist of the backfill")
+		return nil, err
+	}
+	configCM, err := newBackfillConfigCM(mco, allowlist)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		labels, err := getBackfillLabels(c, clusterName, mco)
+		if err != nil {
+			return nil, err
+		}
+		thanosMeta, err := util.NewThanosMeta(labels)
+		if err != nil {
+			return nil, err
+		}
+		job = &workv1.Manifest{RawExtension: runtime.RawExtension{Object: newBackfillJob(mco, thanosMeta)}}
+	}
+
+	manifests := []workv1.Manifest{}
+	manifests = injectIntoWork(manifests, newBackfillServiceAccount(mco))
+	manifests = injectIntoWork(manifests, newBackfillClusterRoleBinding())
+	manifests = injectIntoWork(manifests, configCM)
+	manifests = injectIntoWork(manifests, newBackfillStorageSecret(storage))
+	return append(manifests, *job), nil
+}
+
+// findBackfillJob returns the manifest of the backfill job in the manifestwork, or nil if the
+// manifestwork does not contain it
+func findBackfillJob(work *workv1.ManifestWork) *workv1.Manifest {
+	for i, manifest := range work.Spec.Workload.Manifests {
+		if manifest.Raw == nil {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(manifest.Raw); err != nil {
+			continue
+		}
+		if obj.GetKind() == "Job" && obj.GetName() == backfillName {
+			return &work.Spec.Workload.Manifests[i]
+		}
+	}
+	return nil
+}
+
+// getBackfillLabels returns the external labels of the backfilled blocks, which are the same as the
+// labels of the metrics which the addon collects from the cluster
+func getBackfillLabels(c client.Client, clusterName string,
+	mco *mcov1beta2.MultiClusterObservability) (map[string]string, error) {
+	externalLabels, err := getClusterExternalLabels(c, clusterName, mco)
+	if err != nil {
+		return nil, err
+	}
+	if externalLabels == nil {
+		externalLabels = map[string]string{}
+	}
+	externalLabels[config.GetClusterNameLabelKey()] = clusterName
+	if _, found := externalLabels[config.ClusterIDLabelName]; !found {
+		cluster := &clusterv1.ManagedCluster{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return nil, err
+		}
+		clusterID := getClusterID(cluster, mco.Spec.ClusterIdentity)
+		if clusterID == "" {
+			clusterID = clusterName
+		}
+		externalLabels[config.ClusterIDLabelName] = clusterID
+	}
+
+	return externalLabels, nil
+}
+
+// newBackfillConfigCM returns the configmap with the recording rules which copy the allowlisted
+// metrics of the local Prometheus into the blocks under the names which the collector forwards, and
+// the http config which authenticates to the local Prometheus with the token of the job
+func newBackfillConfigCM(mco *mcov1beta2.MultiClusterObservability,
+	allowlist *MetricsAllowlist) (*corev1.ConfigMap, error) {
+	records := map[string]string{}
+	for _, name := range allowlist.NameList {
+		record := name
+		if renamed, found := allowlist.ReNameMap[name]; found {
+			record = renamed
+		}
+		records[record] = name
+	}
+	for _, rule := range allowlist.RecordingRuleList {
+		records[rule.Record] = rule.Expr
+	}
+	rules := []RecordingRule{}
+	for record, expr := range records {
+		rules = append(rules, RecordingRule{Record: record, Expr: expr})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Record < rules[j].Record })
+
+	ruleGroups, err := yaml.Marshal(backfillRuleGroups{
+		Groups: []backfillRuleGroup{
+			{
+				Name:     "backfill",
+				Interval: fmt.Sprintf("%ds", getBackfillInterval(mco)),
+				Rules:    rules,
+			},
+		},
+	})
+	if err != nil {
+		log.Error(err, "Failed to marshal the rules of the backfill")
+		return nil, err
+	}
+	httpConfig := `authorization:
+  credentials_file: /var/run/secrets/kubernetes.io/serviceaccount/token
+tls_config:
+  ca_file: /var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt
+`
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backfillName,
+			Namespace: spokeNameSpace,
+		},
+		Data: map[string]string{
+			backfillRulesKey:      string(ruleGroups),
+			backfillHTTPConfigKey: httpConfig,
+		},
+	}, nil
+}
+
+// getBackfillInterval returns the resolution of the backfilled history in seconds, which is the
+// same as the interval in which the addon collects the metrics
+func getBackfillInterval(mco *mcov1beta2.MultiClusterObservability) int32 {
+	if mco.Spec.ObservabilityAddonSpec != nil && mco.Spec.ObservabilityAddonSpec.Interval != 0 {
+		return mco.Spec.ObservabilityAddonSpec.Interval
+	}
+	return defaultBackfillInterval
+}
+
+func newBackfillStorageSecret(storage []byte) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backfillName,
+			Namespace: spokeNameSpace,
+		},
+		Data: map[string][]byte{
+			backfillStorageKey: storage,
+		},
+	}
+}
+
+func newBackfillServiceAccount(mco *mcov1beta2.MultiClusterObservability) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ServiceAccount",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backfillName,
+			Namespace: spokeNameSpace,
+		},
+		ImagePullSecrets: []corev1.LocalObjectReference{
+			{Name: mco.Spec.ImagePullSecret},
+		},
+	}
+}
+
+func newBackfillClusterRoleBinding() *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "ClusterRoleBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "open-cluster-management:" + backfillName,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     backfillClusterRole,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      backfillName,
+				Namespace: spokeNameSpace,
+			},
+		},
+	}
+}
+
+// newBackfillJob returns the job which creates the blocks of the recent history from the local
+// Prometheus with promtool, then uploads them into the object storage of the hub with the external
+// labels of the cluster in the thanos section of their meta.json. The history ends when the job starts, which is when the collector starts
+// to forward the metrics of the cluster, so that the backfilled blocks do not overlap with them.
+func newBackfillJob(mco *mcov1beta2.MultiClusterObservability, thanosMeta string) *batchv1.Job {
+	interval := getBackfillInterval(mco)
+	lookback := int64(config.GetBackfillLookback(mco).Seconds())
+	createBlocks := fmt.Sprintf("end=$(date +%%s) && promtool tsdb create-blocks-from rules "+
+		"--start=$((end-%d)) --end=${end} --url=%s --http.config.file=%s --eval-interval=%ds "+
+		"--output-dir=%s %s",
+		lookback, config.GetBackfillPrometheusURL(mco), backfillConfigPath+"/"+backfillHTTPConfigKey,
+		interval, backfillBlocksPath, backfillConfigPath+"/"+backfillRulesKey)
+
+	uploadBlocks := util.GetUploadBlocksScript(backfillBlocksPath, backfillUploadPath,
+		backfillStoragePath+"/"+backfillStorageKey)
+
+	backoffLimit := int32(2)
+	podLabels := map[string]string{"component": backfillName}
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backfillName,
+			Namespace: spokeNameSpace,
+		},
+		// the finished job is not removed after a ttl, otherwise the work agent creates it again
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: backfillName,
+					RestartPolicy:      corev1.RestartPolicyNever,
+					InitContainers: []corev1.Container{
+						{
+							Name: "create-blocks",
+							Image: getExternalImage(mco, config.PrometheusImgRepo,
+								config.PrometheusImgName, config.PrometheusImgTag, config.PrometheusKey),
+							ImagePullPolicy: mco.Spec.ImagePullPolicy,
+							Command:         []string{"/bin/sh", "-c", createBlocks},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "blocks", MountPath: backfillBlocksPath},
+								{Name: "config", MountPath: backfillConfigPath, ReadOnly: true},
+							},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            "upload-blocks",
+							Image:           getImage(mco, config.ThanosImgName, config.ThanosImgTag, config.ThanosImgName),
+							ImagePullPolicy: mco.Spec.ImagePullPolicy,
+							Command:         []string{"/bin/sh", "-c", uploadBlocks},
+							Env:             []corev1.EnvVar{{Name: util.ThanosMetaEnv, Value: thanosMeta}},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "blocks", MountPath: backfillBlocksPath, ReadOnly: true},
+								{Name: "upload", MountPath: backfillUploadPath},
+								{Name: "storage", MountPath: backfillStoragePath, ReadOnly: true},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name:         "blocks",
+							VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+						},
+						{
+							Name:         "upload",
+							VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+						},
+						{
+							Name: "config",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: backfillName},
+								},
+							},
+						},
+						{
+							Name: "storage",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{SecretName: backfillName},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+func TestBackfillManifests(t *testing.T) {
+	initSchema(t)
+
+	storage := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "backfill-storage", Namespace: config.GetDefaultNamespace()},
+		Data:       map[string][]byte{"thanos.yaml": []byte("type: s3")},
+	}
+	allowlistCM := &corev1.ConfigMap{
+		Data: map[string]string{metricsListKey: `
+names:
+  - up
+  - cluster_version
+renames:
+  cluster_version: acm_cluster_version
+recording_rules:
+  - record: cluster:cpu_usage
+    expr: sum(rate(container_cpu_usage_seconds_total[5m]))
+`},
+	}
+	mco := newTestMCO()
+	c := fake.NewFakeClient(storage)
+	workName := namespace + workNameSuffix
+
+	manifests, err := getBackfillManifests(c, workName, namespace, clusterName, mco, allowlistCM)
+	if err != nil || manifests != nil {
+		t.Fatalf("The backfill should be skipped when it is disabled: %v (%v)", manifests, err)
+	}
+
+	mco.Spec.Backfill = &mcov1beta2.BackfillSpec{
+		Enabled:       true,
+		Lookback:      "2h",
+		UploadStorage: &mcoshared.PreConfiguredStorage{Name: "backfill-storage", Key: "thanos.yaml"},
+	}
+	manifests, err = getBackfillManifests(c, workName, namespace, clusterName, mco, allowlistCM)
+	if err != nil {
+		t.Fatalf("Failed to get the backfill manifests: (%v)", err)
+	}
+	if len(manifests) != 5 {
+		t.Fatalf("Wrong number of the backfill manifests: %d", len(manifests))
+	}
+	configCM := manifests[2].Object.(*corev1.ConfigMap)
+	rules := configCM.Data[backfillRulesKey]
+	for _, rule := range []string{"record: up\n", "record: acm_cluster_version\n    expr: cluster_version\n",
+		"record: cluster:cpu_usage\n", "interval: 1s"} {
+		if !strings.Contains(rules, rule) {
+			t.Errorf("The rules of the backfill should contain %q: %s", rule, rules)
+		}
+	}
+	if secret := manifests[3].Object.(*corev1.Secret); string(secret.Data[backfillStorageKey]) != "type: s3" {
+		t.Errorf("The upload storage should be copied to the cluster: %v", secret.Data)
+	}
+	job := manifests[4].Object.(*batchv1.Job)
+	command := job.Spec.Template.Spec.InitContainers[0].Command[2]
+	if !strings.Contains(command, "--start=$((end-7200))") ||
+		!strings.Contains(command, "--url="+config.DefaultBackfillPrometheusURL) {
+		t.Errorf("Wrong command to create the blocks: %s", command)
+	}
+	upload := job.Spec.Template.Spec.Containers[0]
+	if !strings.Contains(upload.Command[2], "thanos tools bucket replicate") {
+		t.Errorf("Wrong command to upload the blocks: %s", upload.Command[2])
+	}
+	if len(upload.Env) != 1 || upload.Env[0].Name != util.ThanosMetaEnv ||
+		!strings.Contains(upload.Env[0].Value, `"cluster":"`+clusterName+`"`) ||
+		!strings.Contains(upload.Env[0].Value, `"clusterID":"`+clusterName+`"`) {
+		t.Errorf("The blocks should be uploaded with the labels of the cluster: %v", upload.Env)
+	}
+
+	// the job is kept unchanged in the manifestwork of the onboarded cluster
+	work := newManifestwork(workName, namespace)
+	work.Spec.Workload.Manifests = manifests
+	if err := c.Create(context.TODO(), work); err != nil {
+		t.Fatalf("Failed to create the manifestwork: (%v)", err)
+	}
+	mco.Spec.Backfill.Lookback = "6h"
+	manifests, err = getBackfillManifests(c, workName, namespace, clusterName, mco, allowlistCM)
+	if err != nil || len(manifests) != 5 || manifests[4].Raw == nil ||
+		!strings.Contains(string(manifests[4].Raw), "end-7200") {
+		t.Errorf("The backfill job should be kept unchanged in the manifestwork: %v (%v)", manifests, err)
+	}
+
+	// the cluster which is onboarded before the backfill is enabled is not backfilled
+	work.Spec.Workload.Manifests = []workv1.Manifest{}
+	if err := c.Update(context.TODO(), work); err != nil {
+		t.Fatalf("Failed to update the manifestwork: (%v)", err)
+	}
+	manifests, err = getBackfillManifests(c, workName, namespace, clusterName, mco, allowlistCM)
+	if err != nil || manifests != nil {
+		t.Errorf("The onboarded cluster should not be backfilled: %v (%v)", manifests, err)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	cardinalityAnalysisInterval = 5 * time.Minute
+	// the time of the last analysis of the cardinality on the hub
+	cardinalityAnalyzedAnnotation = "observability.open-cluster-management.io/last-analysis"
+)
+
+// ThrottledMetric is a high-cardinality metric which is not forwarded from the cluster until it expires
+type ThrottledMetric struct {
+	Cluster string `yaml:"cluster"`
+	Metric  string `yaml:"metric"`
+	Expires string `yaml:"expires"`
+}
+
+func isCardinalityThrottleEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return config.IsCardinalityGuardEnabled(mco) && mco.Spec.CardinalityGuard.AutoThrottle
+}
+
+// updateCardinalityThrottle throttles the metrics whose series are exploding in the clusters for the
+// throttle duration. The exploding metrics are queried from the hub at most once in the analysis interval.
+func updateCardinalityThrottle(c client.Client, mco *mcov1beta2.MultiClusterObservability) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.CardinalityThrottleConfigMapName,
+			Namespace: config.GetDefaultNamespace(),
+		},
+	}
+	if !isCardinalityThrottleEnabled(mco) {
+		err := c.Delete(context.TODO(), cm)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	found := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}, found)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	throttled, err := parseThrottledMetrics(found)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	metrics := []ThrottledMetric{}
+	for _, metric := range throttled {
+		if !isThrottleExpired(metric, now) {
+			metrics = append(metrics, metric)
+		}
+	}
+
+	analyzed := found.Annotations[cardinalityAnalyzedAnnotation]
+	lastAnalysis, err := time.Parse(time.RFC3339, analyzed)
+	if err != nil || now.Sub(lastAnalysis) >= cardinalityAnalysisInterval {
+		metrics, err = throttleExplodingMetrics(mco, metrics, now)
+		if err != nil {
+			// keep the current throttled metrics and analyze again in the next analysis
+			log.Error(err, "Failed to query the exploding metrics")
+		} else {
+			analyzed = now.Format(time.RFC3339)
+		}
+	}
+
+	data, err := yaml.Marshal(metrics)
+	if err != nil {
+		return err
+	}
+	cm.Annotations = map[string]string{cardinalityAnalyzedAnnotation: analyzed}
+	cm.Data = map[string]string{config.CardinalityThrottleFileKey: string(data)}
+	if !exists {
+		log.Info("Creating the cardinality throttle configmap")
+		return c.Create(context.TODO(), cm)
+	}
+	if !reflect.DeepEqual(found.Data, cm.Data) || !reflect.DeepEqual(found.Annotations, cm.Annotations) {
+		if !reflect.DeepEqual(found.Data, cm.Data) {
+			log.Info("Updating the throttled high-cardinality metrics", "metrics", len(metrics))
+		}
+		found.Annotations = cm.Annotations
+		found.Data = cm.Data
+		return c.Update(context.TODO(), found)
+	}
+	return nil
+}
+
+// throttleExplodingMetrics adds the exploding metrics to the throttled metrics,
+// or extends the expiration if they are throttled already
+func throttleExplodingMetrics(mco *mcov1beta2.MultiClusterObservability,
+	metrics []ThrottledMetric, now time.Time) ([]ThrottledMetric, error) {
+	duration := mco.Spec.CardinalityGuard.ThrottleDuration
+	if duration == "" {
+		duration = config.DefaultCardinalityThrottleDuration
+	}
+	throttleDuration, err := time.ParseDuration(duration)
+	if err != nil {
+		return metrics, fmt.Errorf("invalid throttle duration %s: %v", duration, err)
+	}
+
+	samples, err := newThanosQuerier(mco).query(config.GetCardinalityExplosionExpr(mco))
+	if err != nil {
+		return metrics, err
+	}
+	expires := now.Add(throttleDuration).Format(time.RFC3339)
+	for _, sample := range samples {
+		metric := ThrottledMetric{
+			Cluster: sample.labels[config.GetClusterNameLabelKey()],
+			Metric:  sample.labels["metric"],
+			Expires: expires,
+		}
+		if metric.Cluster == "" || metric.Metric == "" {
+			continue
+		}
+		found := false
+		for i := range metrics {
+			if metrics[i].Cluster == metric.Cluster && metrics[i].Metric == metric.Metric {
+				metrics[i].Expires = expires
+				found = true
+			}
+		}
+		if !found {
+			log.Info("Throttling the high-cardinality metric", "cluster", metric.Cluster, "metric", metric.Metric)
+			metrics = append(metrics, metric)
+		}
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		if metrics[i].Cluster != metrics[j].Cluster {
+			return metrics[i].Cluster < metrics[j].Cluster
+		}
+		return metrics[i].Metric < metrics[j].Metric
+	})
+	return metrics, nil
+}
+
+// getThrottledMetrics returns the metrics which are throttled in the cluster now
+func getThrottledMetrics(c client.Client, clusterName string) ([]string, error) {
+	found := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      config.CardinalityThrottleConfigMapName,
+		Namespace: config.GetDefaultNamespace(),
+	}, found)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	throttled, err := parseThrottledMetrics(found)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	metrics := []string{}
+	for _, metric := range throttled {
+		if metric.Cluster == clusterName && !isThrottleExpired(metric, now) {
+			metrics = append(metrics, metric.Metric)
+		}
+	}
+	return metrics, nil
+}
+
+// removeThrottledMetrics removes the names and the match expressions of the throttled metrics from the allowlist
+func removeThrottledMetrics(allowlist *MetricsAllowlist, metrics []string) {
+	if len(metrics) == 0 {
+		return
+	}
+	throttled := map[string]bool{}
+	for _, metric := range metrics {
+		throttled[metric] = true
+		throttled[fmt.Sprintf(`__name__="%s"`, metric)] = true
+	}
+	names := []string{}
+	for _, name := range allowlist.NameList {
+		if !throttled[name] {
+			names = append(names, name)
+		}
+	}
+	matches := []string{}
+	for _, match := range allowlist.MatchList {
+		drop := false
+		for _, selector := range strings.Split(match, ",") {
+			if throttled[strings.TrimSpace(selector)] {
+				drop = true
+			}
+		}
+		if !drop {
+			matches = append(matches, match)
+		}
+	}
+	allowlist.NameList = names
+	allowlist.MatchList = matches
+}
+
+func parseThrottledMetrics(cm *corev1.ConfigMap) ([]ThrottledMetric, error) {
+	throttled := []ThrottledMetric{}
+	err := yaml.Unmarshal([]byte(cm.Data[config.CardinalityThrottleFileKey]), &throttled)
+	if err != nil {
+		log.Error(err, "Failed to unmarshal the throttled metrics")
+		return nil, err
+	}
+	return throttled, nil
+}
+
+func isThrottleExpired(metric ThrottledMetric, now time.Time) bool {
+	expires, err := time.Parse(time.RFC3339, metric.Expires)
+	return err != nil || !now.Before(expires)
+}
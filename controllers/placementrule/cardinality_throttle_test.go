@@ -0,0 +1,99 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestUpdateCardinalityThrottle(t *testing.T) {
+	initSchema(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+{"metric":{"cluster":"cluster1","metric":"http_requests_total"},"value":[1,"50000"]}]}}`))
+	}))
+	defer server.Close()
+	newThanosQuerierFn := newThanosQuerier
+	newThanosQuerier = func(mco *mcov1beta2.MultiClusterObservability) *thanosQuerier {
+		return &thanosQuerier{url: server.URL, httpClient: server.Client()}
+	}
+	defer func() { newThanosQuerier = newThanosQuerierFn }()
+
+	// the throttle of etcd_b is expired
+	throttle := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.CardinalityThrottleConfigMapName,
+			Namespace: mcoNamespace,
+		},
+		Data: map[string]string{config.CardinalityThrottleFileKey: `
+- cluster: cluster1
+  metric: etcd_b
+  expires: "` + time.Now().Add(-time.Minute).Format(time.RFC3339) + `"
+`},
+	}
+	mco := newTestMCO()
+	mco.Spec.CardinalityGuard = &mcov1beta2.CardinalityGuardSpec{
+		Enabled:          true,
+		AutoThrottle:     true,
+		ThrottleDuration: "30m",
+	}
+	c := fake.NewFakeClient(throttle)
+
+	err := updateCardinalityThrottle(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to update the cardinality throttle: (%v)", err)
+	}
+	metrics, err := getThrottledMetrics(c, clusterName)
+	if err != nil {
+		t.Fatalf("Failed to get the throttled metrics: (%v)", err)
+	}
+	if !reflect.DeepEqual(metrics, []string{"http_requests_total"}) {
+		t.Fatalf("Wrong throttled metrics: %v", metrics)
+	}
+	metrics, err = getThrottledMetrics(c, "cluster2")
+	if err != nil || len(metrics) != 0 {
+		t.Fatalf("No metrics should be throttled in cluster2: %v (%v)", metrics, err)
+	}
+
+	mco.Spec.CardinalityGuard.AutoThrottle = false
+	err = updateCardinalityThrottle(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to update the cardinality throttle: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      config.CardinalityThrottleConfigMapName,
+		Namespace: mcoNamespace,
+	}, &corev1.ConfigMap{})
+	if err == nil {
+		t.Fatalf("The cardinality throttle configmap should be removed")
+	}
+}
+
+func TestRemoveThrottledMetrics(t *testing.T) {
+	allowlist := &MetricsAllowlist{
+		NameList:  []string{"a", "b"},
+		MatchList: []string{`__name__="etcd_b",job="etcd"`, `__name__="etcd_c"`},
+	}
+	removeThrottledMetrics(allowlist, []string{"b", "etcd_b"})
+	if !reflect.DeepEqual(allowlist.NameList, []string{"a"}) {
+		t.Errorf("Wrong metrics names: %v", allowlist.NameList)
+	}
+	if !reflect.DeepEqual(allowlist.MatchList, []string{`__name__="etcd_c"`}) {
+		t.Errorf("Wrong metrics matches: %v", allowlist.MatchList)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const caRolloutWindow = time.Minute
+
+// the rollout state is kept by the leader, a new leader starts with an empty rollout window
+var (
+	// the times when the renewed server CA is pushed to the clusters in the last rollout window
+	caRolloutTimes = []time.Time{}
+	// caRolloutPending is true if the renewed server CA is deferred for some clusters in the reconcile
+	caRolloutPending = false
+)
+
+func getCARolloutRate(mco *mcov1beta2.MultiClusterObservability) int32 {
+	if mco.Spec.TLSConfig == nil {
+		return 0
+	}
+	return mco.Spec.TLSConfig.CARolloutRate
+}
+
+// getPushedCA returns the server CA in the manifestwork of the cluster, or nil if it is not pushed yet
+func getPushedCA(c client.Client, namespace string) ([]byte, error) {
+	found := &workv1.ManifestWork{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: namespace + workNameSuffix, Namespace: namespace}, found)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, manifest := range found.Spec.Workload.Manifests {
+		raw := manifest.Raw
+		if raw == nil && manifest.Object != nil {
+			raw, _ = json.Marshal(manifest.Object)
+		}
+		secret := &corev1.Secret{}
+		if err := json.Unmarshal(raw, secret); err != nil {
+			continue
+		}
+		if secret.Kind == "Secret" && secret.Name == certsName {
+			return secret.Data["ca.crt"], nil
+		}
+	}
+	return nil, nil
+}
+
+// throttleCARollout returns the server CA to push to the cluster. The renewed CA is pushed to at most
+// the rollout rate of clusters in the rollout window, the other clusters keep the CA which is pushed
+// to them already. The new clusters always get the current CA.
+func throttleCARollout(c client.Client, namespace string, ca []byte,
+	mco *mcov1beta2.MultiClusterObservability, now time.Time) ([]byte, error) {
+	rate := getCARolloutRate(mco)
+	if rate <= 0 {
+		return ca, nil
+	}
+	pushed, err := getPushedCA(c, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if pushed == nil || bytes.Equal(pushed, ca) {
+		return ca, nil
+	}
+
+	recent := []time.Time{}
+	for _, pushedAt := range caRolloutTimes {
+		if now.Sub(pushedAt) < caRolloutWindow {
+			recent = append(recent, pushedAt)
+		}
+	}
+	caRolloutTimes = recent
+	if len(caRolloutTimes) >= int(rate) {
+		log.Info("Deferring the renewed server CA for the rollout rate", "namespace", namespace, "rate", rate)
+		caRolloutPending = true
+		return pushed, nil
+	}
+	log.Info("Pushing the renewed server CA", "namespace", namespace)
+	caRolloutTimes = append(caRolloutTimes, now)
+	return ca, nil
+}
+
+// nextCARollout returns the duration until the renewed server CA can be pushed to the deferred
+// clusters, or 0 if no cluster is deferred
+func nextCARollout(now time.Time) time.Duration {
+	if !caRolloutPending || len(caRolloutTimes) == 0 {
+		return 0
+	}
+	next := caRolloutTimes[0].Add(caRolloutWindow).Sub(now)
+	if next < time.Second {
+		next = time.Second
+	}
+	return next
+}
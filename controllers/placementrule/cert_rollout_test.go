@@ -0,0 +1,83 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+func newWorkWithCA(ns string, ca string) *workv1.ManifestWork {
+	work := newManifestwork(ns+workNameSuffix, ns)
+	work.Spec.Workload.Manifests = injectIntoWork(work.Spec.Workload.Manifests, &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: certsName, Namespace: spokeNameSpace},
+		Data:       map[string][]byte{"ca.crt": []byte(ca)},
+	})
+	return work
+}
+
+func TestThrottleCARollout(t *testing.T) {
+	initSchema(t)
+	caRolloutTimes = []time.Time{}
+	caRolloutPending = false
+
+	c := fake.NewFakeClient(
+		newWorkWithCA("cluster1", "old-ca"),
+		newWorkWithCA("cluster2", "old-ca"),
+		newWorkWithCA("cluster3", "old-ca"),
+		newWorkWithCA("cluster4", "new-ca"),
+	)
+	mco := newTestMCO()
+	mco.Spec.TLSConfig = &mcov1beta2.TLSConfigSpec{CARolloutRate: 2}
+	now := time.Now()
+
+	expected := map[string]string{
+		"cluster1": "new-ca",
+		"cluster2": "new-ca",
+		"cluster3": "old-ca",
+		// the renewed CA is pushed already
+		"cluster4": "new-ca",
+		// the new cluster has no CA to keep
+		"cluster5": "new-ca",
+	}
+	for _, cluster := range []string{"cluster1", "cluster2", "cluster3", "cluster4", "cluster5"} {
+		ca, err := throttleCARollout(c, cluster, []byte("new-ca"), mco, now)
+		if err != nil {
+			t.Fatalf("Failed to throttle the CA rollout: (%v)", err)
+		}
+		if string(ca) != expected[cluster] {
+			t.Errorf("cluster %s: expected the CA %s, got %s", cluster, expected[cluster], ca)
+		}
+	}
+	if next := nextCARollout(now); next != caRolloutWindow {
+		t.Errorf("the deferred clusters should be reconciled after the rollout window: %v", next)
+	}
+
+	// the deferred cluster gets the renewed CA in the next window
+	caRolloutPending = false
+	ca, err := throttleCARollout(c, "cluster3", []byte("new-ca"), mco, now.Add(caRolloutWindow))
+	if err != nil {
+		t.Fatalf("Failed to throttle the CA rollout: (%v)", err)
+	}
+	if string(ca) != "new-ca" {
+		t.Errorf("the renewed CA should be pushed to the deferred cluster: %s", ca)
+	}
+	if next := nextCARollout(now.Add(caRolloutWindow)); next != 0 {
+		t.Errorf("no reconcile should be scheduled once the rollout completes: %v", next)
+	}
+
+	mco.Spec.TLSConfig = nil
+	ca, err = throttleCARollout(c, "cluster1", []byte("newer-ca"), mco, now)
+	if err != nil || string(ca) != "newer-ca" {
+		t.Errorf("the renewed CA should be pushed at once without the rollout rate: %s (%v)", ca, err)
+	}
+}
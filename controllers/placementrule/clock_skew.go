@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// the lease which the registration agent renews with the clock of the managed cluster
+	clusterLeaseName = "managed-cluster-lease"
+
+	clockConditionType      = "ClockSynchronized"
+	ReasonClockSynchronized = "ClockSynchronized"
+	ReasonClockSkewed       = "ClockSkewed"
+
+	clockSkewCheckInterval = 5 * time.Minute
+)
+
+var (
+	// clockSkewThreshold is the skew above which the samples of the managed cluster may be rejected
+	// as out of order or too far in the future
+	clockSkewThreshold = 30 * time.Second
+
+	// the cluster label is overridden with local-cluster when the metric is forwarded from the hub
+	clusterClockSkew = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "acm_observability_cluster_clock_skew_seconds",
+		Help: "The skew of the clock of the managed cluster from the clock of the hub, positive if the managed cluster is ahead.",
+	}, []string{"managed_cluster"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(clusterClockSkew)
+}
+
+// getClockSkew returns the skew of the clock of the managed cluster measured on its lease. The renew
+// time is set by the agent with the clock of the managed cluster, and the time of the same update is
+// recorded in the managed fields by the apiserver of the hub. The managed fields are in seconds, so
+// the skew is measured in seconds.
+func getClockSkew(lease *coordinationv1.Lease) (time.Duration, bool) {
+	if lease.Spec.RenewTime == nil {
+		return 0, false
+	}
+	updated := time.Time{}
+	for _, field := range lease.ManagedFields {
+		if field.Time != nil && field.Time.Time.After(updated) {
+			updated = field.Time.Time
+		}
+	}
+	if updated.IsZero() {
+		return 0, false
+	}
+	return lease.Spec.RenewTime.Time.Truncate(time.Second).Sub(updated), true
+}
+
+// checkClockSkew measures the clock skew of the managed clusters, exposes it as a metric on the hub
+// and returns the condition of the clock of every measured cluster
+func checkClockSkew(c client.Client, clusters []string) (map[string]metav1.Condition, error) {
+	clusterClockSkew.Reset()
+	conditions := map[string]metav1.Condition{}
+	for _, cluster := range clusters {
+		lease := &coordinationv1.Lease{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: clusterLeaseName, Namespace: cluster}, lease)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			log.Error(err, "Failed to get the lease of the managed cluster", "cluster", cluster)
+			return nil, err
+		}
+		skew, ok := getClockSkew(lease)
+		if !ok {
+			continue
+		}
+		clusterClockSkew.WithLabelValues(cluster).Set(skew.Seconds())
+
+		condition := metav1.Condition{
+			Type:               clockConditionType,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             ReasonClockSynchronized,
+			Message:            fmt.Sprintf("The clock of the managed cluster is within %s of the hub", clockSkewThreshold),
+		}
+		if skew > clockSkewThreshold || skew < -clockSkewThreshold {
+			log.Info("The clock of the managed cluster is skewed from the hub, its samples may be rejected",
+				"cluster", cluster, "skew", skew.String())
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = ReasonClockSkewed
+			condition.Message = fmt.Sprintf("The clock of the managed cluster differs from the hub by more than %s, "+
+				"its samples may be rejected as out of order", clockSkewThreshold)
+		}
+		conditions[cluster] = condition
+	}
+	return conditions, nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+func newClusterLease(cluster string, updated time.Time, skew time.Duration) *coordinationv1.Lease {
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterLeaseName,
+			Namespace: cluster,
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "registration-agent", Operation: metav1.ManagedFieldsOperationUpdate,
+					Time: &metav1.Time{Time: updated}},
+			},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			RenewTime: &metav1.MicroTime{Time: updated.Add(skew)},
+		},
+	}
+}
+
+func TestCheckClockSkew(t *testing.T) {
+	updated := time.Now().Truncate(time.Second)
+	c := fake.NewFakeClient(
+		newClusterLease("ahead", updated, 45*time.Second),
+		newClusterLease("synced", updated, 2*time.Second),
+		newClusterLease("behind", updated, -time.Minute),
+	)
+	conditions, err := checkClockSkew(c, []string{"ahead", "synced", "behind", "missing"})
+	if err != nil {
+		t.Fatalf("Failed to check the clock skew: (%v)", err)
+	}
+	if len(conditions) != 3 {
+		t.Fatalf("only the clusters with the lease should be measured: %v", conditions)
+	}
+	for cluster, expected := range map[string]metav1.ConditionStatus{
+		"ahead":  metav1.ConditionFalse,
+		"synced": metav1.ConditionTrue,
+		"behind": metav1.ConditionFalse,
+	} {
+		if conditions[cluster].Status != expected {
+			t.Errorf("cluster %s: expected the clock condition %s, got %v", cluster, expected, conditions[cluster])
+		}
+	}
+	if skew, _ := getClockSkew(newClusterLease("ahead", updated, 45*time.Second)); skew != 45*time.Second {
+		t.Errorf("unexpected clock skew %s", skew)
+	}
+}
+
+func TestUpdateAddonStatusWithClockSkew(t *testing.T) {
+	maddon := &addonv1alpha1.ManagedClusterAddOn{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      util.ManagedClusterAddonName,
+			Namespace: namespace,
+		},
+	}
+	c := fake.NewFakeClient(maddon)
+	addonList := mcov1beta1.ObservabilityAddonList{
+		Items: []mcov1beta1.ObservabilityAddon{
+			{ObjectMeta: metav1.ObjectMeta{Name: obsAddonName, Namespace: namespace}},
+		},
+	}
+	newConditions := func(now time.Time) map[string][]metav1.Condition {
+		return map[string][]metav1.Condition{
+			namespace: {{
+				Type:               clockConditionType,
+				Status:             metav1.ConditionFalse,
+				LastTransitionTime: metav1.NewTime(now.Truncate(time.Second)),
+				Reason:             ReasonClockSkewed,
+			}},
+		}
+	}
+	first := time.Now().Add(-time.Hour)
+	if _, err := updateAddonStatus(c, &addonList.Items[0], newConditions(first)[namespace]); err != nil {
+		t.Fatalf("Failed to update status for managedclusteraddon: (%v)", err)
+	}
+	if _, err := updateAddonStatus(c, &addonList.Items[0], newConditions(time.Now())[namespace]); err != nil {
+		t.Fatalf("Failed to update status for managedclusteraddon: (%v)", err)
+	}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: util.ManagedClusterAddonName, Namespace: namespace}, maddon)
+	if err != nil {
+		t.Fatalf("Failed to get managedclusteraddon: (%v)", err)
+	}
+	condition := meta.FindStatusCondition(maddon.Status.Conditions, clockConditionType)
+	if condition == nil || condition.Reason != ReasonClockSkewed {
+		t.Fatalf("the clock condition is not added to the addon: %v", maddon.Status.Conditions)
+	}
+	if !condition.LastTransitionTime.Time.Equal(first.Truncate(time.Second)) {
+		t.Errorf("the transition time should be kept while the status is not changed: %v", condition.LastTransitionTime)
+	}
+}
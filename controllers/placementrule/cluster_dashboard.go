@@ -0,0 +1,247 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+)
+
+const (
+	clusterDashboardPrefix      = "grafana-cluster-dashboard-"
+	customDashboardLabelKey     = "grafana-custom-dashboard"
+	dashboardFolderAnnotation   = "observability.open-cluster-management.io/dashboard-folder"
+	clusterDashboardTitleSuffix = " / Cluster Drill-Down"
+)
+
+// clusterDashboardTemplate is the drill-down dashboard of a managed cluster, the panels query
+// the series of the cluster with the $cluster variable which is set to the name of the cluster
+const clusterDashboardTemplate = `{
+  "editable": true,
+  "graphTooltip": 1,
+  "panels": [
+    {
+      "datasource": "$datasource",
+      "format": "percentunit",
+      "gridPos": {"h": 6, "w": 12, "x": 0, "y": 0},
+      "id": 1,
+      "targets": [
+        {
+          "expr": "sum(cluster:cpu_usage_cores:sum{cluster=\"$cluster\"}) / sum(cluster:capacity_cpu_cores:sum{cluster=\"$cluster\"})",
+          "instant": true,
+          "refId": "A"
+        }
+      ],
+      "title": "CPU Utilisation",
+      "type": "singlestat",
+      "valueName": "current"
+    },
+    {
+      "datasource": "$datasource",
+      "format": "percentunit",
+      "gridPos": {"h": 6, "w": 12, "x": 12, "y": 0},
+      "id": 2,
+      "targets": [
+        {
+          "expr": "sum(cluster:memory_usage:ratio{cluster=\"$cluster\"})",
+          "instant": true,
+          "refId": "A"
+        }
+      ],
+      "title": "Memory Utilisation",
+      "type": "singlestat",
+      "valueName": "current"
+    },
+    {
+      "datasource": "$datasource",
+      "fill": 1,
+      "gridPos": {"h": 9, "w": 12, "x": 0, "y": 6},
+      "id": 3,
+      "legend": {"show": true},
+      "lines": true,
+      "linewidth": 1,
+      "stack": true,
+      "targets": [
+        {
+          "expr": "sum(node_namespace_pod_container:container_cpu_usage_seconds_total:sum_rate{cluster=\"$cluster\", namespace=~\"$namespace\"}) by (namespace)",
+          "legendFormat": "{{namespace}}",
+          "refId": "A"
+        }
+      ],
+      "title": "CPU Usage by Namespace",
+      "type": "graph",
+      "yaxes": [{"format": "short", "min": 0, "show": true}, {"format": "short", "show": false}]
+    },
+    {
+      "datasource": "$datasource",
+      "fill": 1,
+      "gridPos": {"h": 9, "w": 12, "x": 12, "y": 6},
+      "id": 4,
+      "legend": {"show": true},
+      "lines": true,
+      "linewidth": 1,
+      "stack": true,
+      "targets": [
+        {
+          "expr": "sum(namespace:container_memory_usage_bytes:sum{cluster=\"$cluster\", namespace=~\"$namespace\"}) by (namespace)",
+          "legendFormat": "{{namespace}}",
+          "refId": "A"
+        }
+      ],
+      "title": "Memory Usage by Namespace",
+      "type": "graph",
+      "yaxes": [{"format": "bytes", "min": 0, "show": true}, {"format": "short", "show": false}]
+    }
+  ],
+  "refresh": "5m",
+  "schemaVersion": 22,
+  "tags": ["cluster-drill-down"],
+  "templating": {
+    "list": [
+      {
+        "hide": 2,
+        "name": "datasource",
+        "query": "prometheus",
+        "type": "datasource"
+      },
+      {
+        "hide": 2,
+        "name": "cluster",
+        "type": "constant"
+      },
+      {
+        "datasource": "$datasource",
+        "hide": 0,
+        "includeAll": true,
+        "multi": true,
+        "name": "namespace",
+        "query": "label_values(kube_pod_info{cluster=\"$cluster\"}, namespace)",
+        "refresh": 2,
+        "sort": 1,
+        "type": "query"
+      }
+    ]
+  },
+  "time": {"from": "now-1h", "to": "now"},
+  "timezone": "browser"
+}`
+
+// newClusterDashboard returns the configmap of the drill-down dashboard of the managed cluster,
+// the dashboard is loaded into the grafana folder with the name of the cluster
+func newClusterDashboard(clusterName string, namespace string) (*corev1.ConfigMap, error) {
+	dashboard := map[string]interface{}{}
+	err := json.Unmarshal([]byte(clusterDashboardTemplate), &dashboard)
+	if err != nil {
+		return nil, err
+	}
+	// the uid is stable across the reconciles so that the links to the dashboard keep working
+	uid := md5.Sum([]byte(clusterName))
+	dashboard["uid"] = hex.EncodeToString(uid[:])
+	dashboard["title"] = clusterName + clusterDashboardTitleSuffix
+	for _, v := range dashboard["templating"].(map[string]interface{})["list"].([]interface{}) {
+		variable := v.(map[string]interface{})
+		if variable["name"] == "cluster" {
+			variable["query"] = clusterName
+			variable["current"] = map[string]interface{}{"text": clusterName, "value": clusterName}
+		}
+	}
+	data, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	name := clusterDashboardPrefix + namespace
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: config.GetDefaultNamespace(),
+			Labels: map[string]string{
+				ownerLabelKey:           ownerLabelValue,
+				customDashboardLabelKey: "true",
+			},
+			Annotations: map[string]string{
+				dashboardFolderAnnotation: clusterName,
+			},
+		},
+		Data: map[string]string{
+			name + ".json": string(data),
+		},
+	}, nil
+}
+
+// createClusterDashboard generates the drill-down dashboard of the managed cluster, and removes
+// it when the cluster dashboards are disabled
+func createClusterDashboard(c client.Client, mco *mcov1beta2.MultiClusterObservability,
+	clusterName string, namespace string) error {
+	if !config.IsClusterDashboardsEnabled(mco) {
+		return deleteClusterDashboard(c, namespace)
+	}
+	dashboard, err := newClusterDashboard(clusterName, namespace)
+	if err != nil {
+		return err
+	}
+	found := &corev1.ConfigMap{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: dashboard.Name, Namespace: dashboard.Namespace}, found)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			log.Info("Creating the cluster dashboard", "cluster", clusterName)
+			err = c.Create(context.TODO(), dashboard)
+		}
+		if err != nil {
+			errorlog.ClusterError(log, err, clusterName, "Failed to create the cluster dashboard")
+		}
+		return err
+	}
+	if reflect.DeepEqual(found.Data, dashboard.Data) &&
+		reflect.DeepEqual(found.Labels, dashboard.Labels) &&
+		reflect.DeepEqual(found.Annotations, dashboard.Annotations) {
+		return nil
+	}
+	log.Info("Updating the cluster dashboard", "cluster", clusterName)
+	found.Data = dashboard.Data
+	found.Labels = dashboard.Labels
+	found.Annotations = dashboard.Annotations
+	err = c.Update(context.TODO(), found)
+	if err != nil {
+		errorlog.ClusterError(log, err, clusterName, "Failed to update the cluster dashboard")
+	}
+	return err
+}
+
+// deleteClusterDashboard removes the drill-down dashboard of the managed cluster
+func deleteClusterDashboard(c client.Client, namespace string) error {
+	dashboard := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterDashboardPrefix + namespace,
+			Namespace: config.GetDefaultNamespace(),
+		},
+	}
+	// check it first so that the reconciles without the cluster dashboards only hit the cache
+	err := c.Get(context.TODO(), client.ObjectKeyFromObject(dashboard), dashboard)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err == nil {
+		log.Info("Deleting the cluster dashboard", "name", dashboard.Name)
+		err = c.Delete(context.TODO(), dashboard)
+	}
+	if err != nil && !k8serrors.IsNotFound(err) {
+		log.Error(err, "Failed to delete the cluster dashboard", "name", dashboard.Name)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestClusterDashboard(t *testing.T) {
+	initSchema(t)
+
+	c := fake.NewFakeClient()
+	mco := newTestMCO()
+	name := clusterDashboardPrefix + namespace
+	key := types.NamespacedName{Name: name, Namespace: config.GetDefaultNamespace()}
+
+	// the cluster dashboards are disabled by default
+	err := createClusterDashboard(c, mco, clusterName, namespace)
+	if err != nil {
+		t.Fatalf("Failed to create the cluster dashboard: (%v)", err)
+	}
+	err = c.Get(context.TODO(), key, &corev1.ConfigMap{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The cluster dashboard should not be created when it is disabled: (%v)", err)
+	}
+
+	mco.Spec.Grafana = &mcov1beta2.GrafanaSpec{ClusterDashboards: true}
+	err = createClusterDashboard(c, mco, clusterName, namespace)
+	if err != nil {
+		t.Fatalf("Failed to create the cluster dashboard: (%v)", err)
+	}
+	found := &corev1.ConfigMap{}
+	err = c.Get(context.TODO(), key, found)
+	if err != nil {
+		t.Fatalf("Failed to get the cluster dashboard: (%v)", err)
+	}
+	if found.Labels[customDashboardLabelKey] != "true" || found.Annotations[dashboardFolderAnnotation] != clusterName {
+		t.Fatalf("The cluster dashboard should be loaded into the folder of the cluster: %v %v",
+			found.Labels, found.Annotations)
+	}
+	dashboard := map[string]interface{}{}
+	err = json.Unmarshal([]byte(found.Data[name+".json"]), &dashboard)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the cluster dashboard: (%v)", err)
+	}
+	if !strings.HasPrefix(dashboard["title"].(string), clusterName) || len(dashboard["uid"].(string)) != 32 {
+		t.Fatalf("Wrong title or uid of the cluster dashboard: %v %v", dashboard["title"], dashboard["uid"])
+	}
+	variables := dashboard["templating"].(map[string]interface{})["list"].([]interface{})
+	if variables[1].(map[string]interface{})["query"] != clusterName {
+		t.Fatalf("The cluster variable should be set to the name of the cluster: %v", variables[1])
+	}
+
+	err = deleteManagedClusterRes(c, namespace, false)
+	if err != nil {
+		t.Fatalf("Failed to delete the managedcluster resources: (%v)", err)
+	}
+	err = c.Get(context.TODO(), key, &corev1.ConfigMap{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The cluster dashboard is not deleted: (%v)", err)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+var (
+	// the claims of the id of the managed cluster in the order of preference
+	defaultClusterIDClaims = []string{"id.openshift.io", "id.k8s.io"}
+)
+
+// getClusterID returns the id of the managed cluster from the source of the cluster identity, the id
+// claim by default, or empty if the cluster does not report the claim or the label
+func getClusterID(cluster *clusterv1.ManagedCluster, spec *mcov1beta2.ClusterIdentitySpec) string {
+	if spec != nil {
+		switch spec.Source {
+		case config.ClusterIDSourceClusterName:
+			return cluster.Name
+		case config.ClusterIDSourceClusterLabel:
+			return cluster.GetLabels()[spec.LabelKey]
+		}
+	}
+	claims := defaultClusterIDClaims
+	if spec != nil && spec.ClaimName != "" {
+		claims = []string{spec.ClaimName}
+	}
+	for _, name := range claims {
+		for _, claim := range cluster.Status.ClusterClaims {
+			if claim.Name == name && claim.Value != "" {
+				return claim.Value
+			}
+		}
+	}
+	return ""
+}
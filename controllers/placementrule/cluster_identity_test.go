@@ -0,0 +1,76 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func newClusterWithID(name, claim, id string) *clusterv1.ManagedCluster {
+	return &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: clusterv1.ManagedClusterStatus{
+			ClusterClaims: []clusterv1.ManagedClusterClaim{{Name: claim, Value: id}},
+		},
+	}
+}
+
+func TestClusterIDExternalLabel(t *testing.T) {
+	initSchema(t)
+
+	c := fake.NewFakeClient(newTestRoute(), newClusterWithID(clusterName, "id.k8s.io", "1234"))
+	mco := newTestMCO()
+	labels, err := getClusterExternalLabels(c, clusterName, mco)
+	if err != nil || labels != nil {
+		t.Fatalf("no label should be injected without the cluster identity: %v (%v)", labels, err)
+	}
+
+	mco.Spec.ClusterIdentity = &mcov1beta2.ClusterIdentitySpec{}
+	labels, err = getClusterExternalLabels(c, clusterName, mco)
+	if err != nil {
+		t.Fatalf("Failed to get the external labels: (%v)", err)
+	}
+	if labels[config.ClusterIDLabelName] != "1234" {
+		t.Errorf("the id claim should be injected as the clusterID label: %v", labels)
+	}
+
+	mco.Spec.ClusterIdentity.ClaimName = "id.example.com"
+	labels, err = getClusterExternalLabels(c, clusterName, mco)
+	if err != nil || labels != nil {
+		t.Errorf("the cluster without the configured claim should not have the label: %v (%v)", labels, err)
+	}
+
+	mco.Spec.ClusterIdentity.Source = config.ClusterIDSourceClusterName
+	labels, err = getClusterExternalLabels(c, clusterName, mco)
+	if err != nil || labels[config.ClusterIDLabelName] != clusterName {
+		t.Errorf("the name of the cluster should be injected as the clusterID label: %v (%v)", labels, err)
+	}
+
+	mco.Spec.ClusterIdentity.Source = config.ClusterIDSourceClusterLabel
+	if err := config.ValidateClusterIdentity(mco); err == nil {
+		t.Errorf("the label source without the label key should be invalid")
+	}
+	mco.Spec.ClusterIdentity.LabelKey = "cmdb.example.com/id"
+	cluster := &clusterv1.ManagedCluster{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster); err != nil {
+		t.Fatalf("Failed to get managedcluster: (%v)", err)
+	}
+	cluster.SetLabels(map[string]string{"cmdb.example.com/id": "CI0042"})
+	if err := c.Update(context.TODO(), cluster); err != nil {
+		t.Fatalf("Failed to update managedcluster: (%v)", err)
+	}
+	labels, err = getClusterExternalLabels(c, clusterName, mco)
+	if err != nil || labels[config.ClusterIDLabelName] != "CI0042" {
+		t.Errorf("the label of the cluster should be injected as the clusterID label: %v (%v)", labels, err)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+const (
+	reasonUnsupported = "Unsupported"
+	// the claims of the versions of the managed cluster which the registration agent reports
+	kubeVersionClaim      = "kubeversion.open-cluster-management.io"
+	openshiftVersionClaim = "version.openshift.io"
+	crdV1beta1APIVersion  = "apiextensions.k8s.io/v1beta1"
+	crdV1APIVersion       = "apiextensions.k8s.io/v1"
+)
+
+var (
+	// the oldest versions of the managed clusters which the endpoint manifests can be applied to, the
+	// CustomResourceDefinitions of apiextensions.k8s.io/v1 are served since 1.16
+	minKubernetesVersion = version.MustParseGeneric("1.16.0")
+	minOpenShiftVersion  = version.MustParseGeneric("4.3.0")
+	// the CustomResourceDefinitions of apiextensions.k8s.io/v1beta1 are not served since 1.22
+	crdV1beta1RemovedVersion = version.MustParseGeneric("1.22.0")
+)
+
+// clusterVersions is the Kubernetes and the OpenShift versions of the managed cluster, they are nil
+// if the cluster does not report them
+type clusterVersions struct {
+	kubernetes *version.Version
+	openshift  *version.Version
+}
+
+// getClusterVersions returns the versions of the managed cluster from its status and its claims
+func getClusterVersions(c client.Client, clusterName string) (*clusterVersions, error) {
+	cluster := &clusterv1.ManagedCluster{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return &clusterVersions{}, nil
+		}
+		errorlog.ClusterError(log, err, clusterName, "Failed to get managedcluster")
+		return nil, err
+	}
+	kubeVersion := cluster.Status.Version.Kubernetes
+	openshiftVersion := ""
+	for _, claim := range cluster.Status.ClusterClaims {
+		switch claim.Name {
+		case kubeVersionClaim:
+			if kubeVersion == "" {
+				kubeVersion = claim.Value
+			}
+		case openshiftVersionClaim:
+			openshiftVersion = claim.Value
+		}
+	}
+	versions := &clusterVersions{}
+	// the versions which cannot be parsed are treated as not reported
+	if kubeVersion != "" {
+		versions.kubernetes, _ = version.ParseGeneric(kubeVersion)
+	}
+	if openshiftVersion != "" {
+		versions.openshift, _ = version.ParseGeneric(openshiftVersion)
+	}
+	return versions, nil
+}
+
+// getUnsupportedMessage returns why the observability is not supported on the managed cluster, or empty
+// if it is supported. The cluster which does not report its versions yet is supported.
+func getUnsupportedMessage(versions *clusterVersions) string {
+	if versions.kubernetes != nil && versions.kubernetes.LessThan(minKubernetesVersion) {
+		return fmt.Sprintf("The Kubernetes version %s of the managed cluster is not supported, "+
+			"the oldest supported version is %s", versions.kubernetes, minKubernetesVersion)
+	}
+	if versions.openshift != nil && versions.openshift.LessThan(minOpenShiftVersion) {
+		return fmt.Sprintf("The OpenShift version %s of the managed cluster is not supported, "+
+			"the oldest supported version is %s", versions.openshift, minOpenShiftVersion)
+	}
+	return ""
+}
+
+// updateUnsupportedStatus reports the Unsupported condition on the managedclusteraddon of the cluster
+// instead of shipping the manifestwork which fails to apply
+func updateUnsupportedStatus(c client.Client, namespace string, message string) error {
+	if err := util.CreateManagedClusterAddonCR(c, namespace); err != nil {
+		log.Error(err, "Failed to create ManagedClusterAddon")
+		return err
+	}
+	return setManagedClusterAddonCondition(c, namespace, metav1.Condition{
+		Type:    "Degraded",
+		Status:  metav1.ConditionTrue,
+		Reason:  reasonUnsupported,
+		Message: message,
+	})
+}
+
+// adaptTemplates adapts the endpoint manifests to the Kubernetes version of the managed cluster, the
+// CustomResourceDefinitions of apiextensions.k8s.io/v1beta1 are converted to apiextensions.k8s.io/v1
+// for the clusters which do not serve the former any more
+func adaptTemplates(templates []runtime.RawExtension, versions *clusterVersions) ([]runtime.RawExtension, error) {
+	if versions.kubernetes == nil || versions.kubernetes.LessThan(crdV1beta1RemovedVersion) {
+		return templates, nil
+	}
+	for i, raw := range templates {
+		if raw.Raw == nil {
+			continue
+		}
+		crd := map[string]interface{}{}
+		if err := json.Unmarshal(raw.Raw, &crd); err != nil {
+			return nil, err
+		}
+		if crd["kind"] != "CustomResourceDefinition" || crd["apiVersion"] != crdV1beta1APIVersion {
+			continue
+		}
+		convertCRDToV1(crd)
+		data, err := json.Marshal(crd)
+		if err != nil {
+			return nil, err
+		}
+		templates[i] = runtime.RawExtension{Raw: data}
+	}
+	return templates, nil
+}
+
+// convertCRDToV1 moves the schema, the subresources and the printer columns of the
+// CustomResourceDefinition of apiextensions.k8s.io/v1beta1 into its versions
+func convertCRDToV1(crd map[string]interface{}) {
+	crd["apiVersion"] = crdV1APIVersion
+	spec, ok := crd["spec"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	validation := spec["validation"]
+	subresources := spec["subresources"]
+	columns, _ := spec["additionalPrinterColumns"].([]interface{})
+	delete(spec, "validation")
+	delete(spec, "subresources")
+	delete(spec, "additionalPrinterColumns")
+	delete(spec, "version")
+	delete(spec, "preserveUnknownFields")
+	versions, _ := spec["versions"].([]interface{})
+	for _, v := range versions {
+		crdVersion, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, found := crdVersion["schema"]; !found && validation != nil {
+			crdVersion["schema"] = validation
+		}
+		if _, found := crdVersion["subresources"]; !found && subresources != nil {
+			crdVersion["subresources"] = subresources
+		}
+		if _, found := crdVersion["additionalPrinterColumns"]; !found && len(columns) > 0 {
+			v1Columns := []interface{}{}
+			for _, column := range columns {
+				if c, ok := column.(map[string]interface{}); ok {
+					v1Column := map[string]interface{}{}
+					for k, v := range c {
+						if k == "JSONPath" {
+							k = "jsonPath"
+						}
+						v1Column[k] = v
+					}
+					v1Columns = append(v1Columns, v1Column)
+				}
+			}
+			crdVersion["additionalPrinterColumns"] = v1Columns
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+func TestClusterVersions(t *testing.T) {
+	initSchema(t)
+
+	newCluster := func(name, kubeVersion string, claims ...clusterv1.ManagedClusterClaim) *clusterv1.ManagedCluster {
+		return &clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: clusterv1.ManagedClusterStatus{
+				Version:       clusterv1.ManagedClusterVersion{Kubernetes: kubeVersion},
+				ClusterClaims: claims,
+			},
+		}
+	}
+	c := fake.NewFakeClient(
+		newCluster("old-kube", "v1.11.0+d4cacc0"),
+		newCluster("old-ocp", "", clusterv1.ManagedClusterClaim{Name: kubeVersionClaim, Value: "v1.16.2"},
+			clusterv1.ManagedClusterClaim{Name: openshiftVersionClaim, Value: "4.2.36"}),
+		newCluster("supported", "v1.20.0", clusterv1.ManagedClusterClaim{Name: openshiftVersionClaim, Value: "4.7.0"}),
+		newCluster("unknown", ""))
+
+	cases := map[string]string{
+		"old-kube":  "The Kubernetes version 1.11.0",
+		"old-ocp":   "The OpenShift version 4.2.36",
+		"supported": "",
+		"unknown":   "",
+		"missing":   "",
+	}
+	for name, expected := range cases {
+		versions, err := getClusterVersions(c, name)
+		if err != nil {
+			t.Fatalf("Failed to get the versions of the cluster %s: (%v)", name, err)
+		}
+		message := getUnsupportedMessage(versions)
+		if expected == "" && message != "" || !strings.HasPrefix(message, expected) {
+			t.Errorf("Wrong support of the cluster %s: %s", name, message)
+		}
+	}
+
+	maddon := &addonv1alpha1.ManagedClusterAddOn{}
+	err := updateUnsupportedStatus(c, "old-kube", "The Kubernetes version is not supported")
+	if err != nil {
+		t.Fatalf("Failed to update the status of the unsupported cluster: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: util.ManagedClusterAddonName, Namespace: "old-kube"}, maddon)
+	if err != nil {
+		t.Fatalf("Failed to get managedclusteraddon: (%v)", err)
+	}
+	if len(maddon.Status.Conditions) != 1 || maddon.Status.Conditions[0].Reason != reasonUnsupported {
+		t.Errorf("The managedclusteraddon should be reported as unsupported: %v", maddon.Status.Conditions)
+	}
+}
+
+func TestAdaptTemplates(t *testing.T) {
+	crd := []byte(`{"apiVersion":"apiextensions.k8s.io/v1beta1","kind":"CustomResourceDefinition",
+"metadata":{"name":"observabilityaddons.observability.open-cluster-management.io"},
+"spec":{"group":"observability.open-cluster-management.io","scope":"Namespaced","version":"v1beta1",
+"subresources":{"status":{}},"validation":{"openAPIV3Schema":{"type":"object"}},
+"additionalPrinterColumns":[{"name":"Age","type":"date","JSONPath":".metadata.creationTimestamp"}],
+"versions":[{"name":"v1beta1","served":true,"storage":true}],"preserveUnknownFields":false}}`)
+	templates := []runtime.RawExtension{{Raw: crd}}
+
+	c := fake.NewFakeClient(&clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterName},
+		Status:     clusterv1.ManagedClusterStatus{Version: clusterv1.ManagedClusterVersion{Kubernetes: "v1.21.1"}},
+	})
+	versions, err := getClusterVersions(c, clusterName)
+	if err != nil {
+		t.Fatalf("Failed to get the versions of the cluster: (%v)", err)
+	}
+	adapted, err := adaptTemplates(templates, versions)
+	if err != nil || string(adapted[0].Raw) != string(crd) {
+		t.Errorf("The templates should not be adapted for the cluster which serves the v1beta1 CRDs (%v)", err)
+	}
+
+	versions.kubernetes = crdV1beta1RemovedVersion
+	adapted, err = adaptTemplates(templates, versions)
+	if err != nil {
+		t.Fatalf("Failed to adapt the templates: (%v)", err)
+	}
+	converted := map[string]interface{}{}
+	if err := json.Unmarshal(adapted[0].Raw, &converted); err != nil {
+		t.Fatalf("Failed to unmarshal the adapted CRD: (%v)", err)
+	}
+	spec := converted["spec"].(map[string]interface{})
+	version := spec["versions"].([]interface{})[0].(map[string]interface{})
+	if converted["apiVersion"] != crdV1APIVersion || spec["validation"] != nil || spec["version"] != nil ||
+		version["schema"] == nil || version["subresources"] == nil ||
+		version["additionalPrinterColumns"].([]interface{})[0].(map[string]interface{})["jsonPath"] == nil {
+		t.Errorf("The CRD should be converted to v1: %s", string(adapted[0].Raw))
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+var clusterSetMapping = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "acm_observability_managed_cluster_clusterset",
+	Help: "The ManagedClusterSet of the managed cluster, the clusterset is empty if the cluster is not in any set.",
+}, []string{"managed_cluster", "clusterset"})
+
+func init() {
+	metrics.Registry.MustRegister(clusterSetMapping)
+}
+
+// ClusterSetMappingReconciler exports the ManagedClusterSet of each managed cluster as a metric, which
+// is forwarded from the hub so that the dashboards can filter the clusters by their sets
+type ClusterSetMappingReconciler struct {
+	Client client.Client
+	// the exported clusterset of each managed cluster
+	exported map[string]string
+}
+
+// Reconcile exports the clusterset of the managed cluster, and removes the mapping of the deleted cluster
+func (r *ClusterSetMappingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if r.exported == nil {
+		r.exported = map[string]string{}
+	}
+	cluster := &clusterv1.ManagedCluster{}
+	err := r.Client.Get(ctx, req.NamespacedName, cluster)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		log.Error(err, "Failed to get managedcluster", "name", req.Name)
+		return ctrl.Result{}, err
+	}
+	clusterSet, found := r.exported[req.Name]
+	deleted := k8serrors.IsNotFound(err) || cluster.GetDeletionTimestamp() != nil
+	if found && (deleted || clusterSet != cluster.GetLabels()[config.ClusterSetLabelKey]) {
+		clusterSetMapping.DeleteLabelValues(req.Name, clusterSet)
+		delete(r.exported, req.Name)
+	}
+	if deleted {
+		return ctrl.Result{}, nil
+	}
+	clusterSet = cluster.GetLabels()[config.ClusterSetLabelKey]
+	clusterSetMapping.WithLabelValues(req.Name, clusterSet).Set(1)
+	r.exported[req.Name] = clusterSet
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager watches the managed clusters and the changes of their clustersets
+func (r *ClusterSetMappingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	clusterSetPred := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectNew.GetLabels()[config.ClusterSetLabelKey] !=
+				e.ObjectOld.GetLabels()[config.ClusterSetLabelKey] ||
+				e.ObjectNew.GetDeletionTimestamp() != nil
+		},
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("clusterset-mapping").
+		For(&clusterv1.ManagedCluster{}, builder.WithPredicates(clusterSetPred)).
+		Complete(r)
+}
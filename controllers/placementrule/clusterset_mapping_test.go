@@ -0,0 +1,62 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestClusterSetMapping(t *testing.T) {
+	initSchema(t)
+
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   clusterName,
+			Labels: map[string]string{config.ClusterSetLabelKey: "team-a"},
+		},
+	}
+	c := fake.NewFakeClient(cluster)
+	r := &ClusterSetMappingReconciler{Client: c}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: clusterName}}
+
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("Failed to reconcile the clusterset mapping: (%v)", err)
+	}
+	if value := testutil.ToFloat64(clusterSetMapping.WithLabelValues(clusterName, "team-a")); value != 1 {
+		t.Errorf("The clusterset of the cluster should be exported")
+	}
+
+	// the cluster is moved to another set
+	cluster.Labels[config.ClusterSetLabelKey] = "team-b"
+	if err := c.Update(context.TODO(), cluster); err != nil {
+		t.Fatalf("Failed to update the managedcluster: (%v)", err)
+	}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("Failed to reconcile the clusterset mapping: (%v)", err)
+	}
+	if count := testutil.CollectAndCount(clusterSetMapping); count != 1 ||
+		testutil.ToFloat64(clusterSetMapping.WithLabelValues(clusterName, "team-b")) != 1 {
+		t.Errorf("Only the current clusterset of the cluster should be exported: %d", count)
+	}
+
+	if err := c.Delete(context.TODO(), cluster); err != nil {
+		t.Fatalf("Failed to delete the managedcluster: (%v)", err)
+	}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("Failed to reconcile the clusterset mapping: (%v)", err)
+	}
+	if count := testutil.CollectAndCount(clusterSetMapping); count != 0 {
+		t.Errorf("The mapping of the deleted cluster should be removed: %d", count)
+	}
+}
@@ -0,0 +1,353 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	defaultTopAlertsLimit = 10
+	// the series which is used to find the last time the metrics of a cluster are received
+	lastReceivedQuery = "max by (%s) (timestamp(up))"
+)
+
+// FleetOverview is the overview of the observability of the managed clusters
+type FleetOverview struct {
+	// Clusters is the number of the managed clusters where the observability is enabled
+	Clusters    int `json:"clusters"`
+	Available   int `json:"available"`
+	Progressing int `json:"progressing"`
+	Degraded    int `json:"degraded"`
+	// Reporting is the number of the clusters whose metrics are received in the last 5 minutes
+	Reporting int `json:"reporting"`
+	// FiringAlerts is the number of the active alerts in the alertmanager on the hub
+	FiringAlerts int `json:"firingAlerts"`
+}
+
+// ClusterStatus is the status of the observability addon of a managed cluster
+type ClusterStatus struct {
+	Name string `json:"name"`
+	// Status is Available, Progressing or Degraded
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	// LastReceived is the time of the latest metrics of the cluster on the hub, it is not set if no
+	// metrics are received in the last 5 minutes
+	LastReceived *metav1.Time `json:"lastReceived,omitempty"`
+}
+
+// AlertSummary is an alert which fires on the managed clusters
+type AlertSummary struct {
+	AlertName string `json:"alertName"`
+	Severity  string `json:"severity,omitempty"`
+	// Firing is the number of the active alerts with the name and the severity
+	Firing   int      `json:"firing"`
+	Clusters []string `json:"clusters"`
+}
+
+// consoleAPI serves the REST API of the observability for the console, so that the console does
+// not need to query the metrics and the custom resources by itself
+type consoleAPI struct {
+	client          client.Client
+	alertmanagerURL string
+	httpClient      *http.Client
+}
+
+func newConsoleAPI(c client.Client) *consoleAPI {
+	return &consoleAPI{
+		client:          c,
+		alertmanagerURL: config.GetAlertmanagerSvcURL(),
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// authorizeConsoleRequest returns true if the user of the bearer token can list the managed
+// clusters, which is the permission the console requires to show the fleet
+var authorizeConsoleRequest = func(c client.Client, token string) (bool, error) {
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}
+	err := c.Create(context.TODO(), review)
+	if err != nil {
+		return false, err
+	}
+	if !review.Status.Authenticated {
+		return false, nil
+	}
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range review.Status.User.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   review.Status.User.Username,
+			UID:    review.Status.User.UID,
+			Groups: review.Status.User.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Group:    "cluster.open-cluster-management.io",
+				Resource: "managedclusters",
+				Verb:     "list",
+			},
+		},
+	}
+	err = c.Create(context.TODO(), sar)
+	if err != nil {
+		return false, err
+	}
+	return sar.Status.Allowed, nil
+}
+
+func (a *consoleAPI) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == req.Header.Get("Authorization") {
+		http.Error(w, "the bearer token is required", http.StatusUnauthorized)
+		return
+	}
+	allowed, err := authorizeConsoleRequest(a.client, token)
+	if err != nil {
+		log.Error(err, "Failed to authorize the request of the console api")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		http.Error(w, "the user cannot list the managed clusters", http.StatusForbidden)
+		return
+	}
+
+	// every replica serves the api, the name of the MultiClusterObservability is only known by the leader
+	mcoList := &mcov1beta2.MultiClusterObservabilityList{}
+	err = a.client.List(context.TODO(), mcoList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(mcoList.Items) == 0 {
+		http.Error(w, "multicluster observability is not enabled", http.StatusServiceUnavailable)
+		return
+	}
+	mco := &mcoList.Items[0]
+
+	var resp interface{}
+	path := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, config.ConsoleAPIPath), "/")
+	switch {
+	case path == "/fleet":
+		resp, err = a.getFleetOverview(mco)
+	case path == "/clusters":
+		resp, err = a.getClusterStatuses(mco)
+	case strings.HasPrefix(path, "/clusters/"):
+		resp, err = a.getClusterStatus(mco, strings.TrimPrefix(path, "/clusters/"))
+	case path == "/alerts":
+		limit := defaultTopAlertsLimit
+		if value := req.URL.Query().Get("limit"); value != "" {
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit <= 0 {
+				http.Error(w, "the limit should be a positive integer", http.StatusBadRequest)
+				return
+			}
+		}
+		resp, err = a.getTopAlerts(limit)
+	default:
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Error(err, "Failed to serve the console api", "path", req.URL.Path)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		log.Error(err, "Failed to write the response of the console api", "path", req.URL.Path)
+	}
+}
+
+// getFleetOverview counts the managed clusters by the status of their addons
+func (a *consoleAPI) getFleetOverview(mco *mcov1beta2.MultiClusterObservability) (*FleetOverview, error) {
+	statuses, err := a.getClusterStatuses(mco)
+	if err != nil {
+		return nil, err
+	}
+	overview := &FleetOverview{Clusters: len(statuses)}
+	for _, status := range statuses {
+		switch status.Status {
+		case "Available":
+			overview.Available++
+		case "Degraded":
+			overview.Degraded++
+		default:
+			overview.Progressing++
+		}
+		if status.LastReceived != nil {
+			overview.Reporting++
+		}
+	}
+	alerts, err := a.getAlerts()
+	if err != nil {
+		// the overview is still useful when the alertmanager is not ready
+		log.Info("Failed to get the alerts from the alertmanager", "error", err.Error())
+	}
+	overview.FiringAlerts = len(alerts)
+	return overview, nil
+}
+
+// getClusterStatuses returns the status of the addon and the last time the metrics are received
+// for each managed cluster where the observability is enabled
+func (a *consoleAPI) getClusterStatuses(mco *mcov1beta2.MultiClusterObservability) ([]ClusterStatus, error) {
+	addonList := &mcov1beta1.ObservabilityAddonList{}
+	err := a.client.List(context.TODO(), addonList, &client.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{ownerLabelKey: ownerLabelValue}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	lastReceived := a.getLastReceived(mco)
+	statuses := []ClusterStatus{}
+	for _, addon := range addonList.Items {
+		statuses = append(statuses, newClusterStatus(addon, lastReceived))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+// getClusterStatus returns the status of the managed cluster
+func (a *consoleAPI) getClusterStatus(mco *mcov1beta2.MultiClusterObservability,
+	cluster string) (*ClusterStatus, error) {
+	addon := &mcov1beta1.ObservabilityAddon{}
+	err := a.client.Get(context.TODO(), types.NamespacedName{Name: obsAddonName, Namespace: cluster}, addon)
+	if err != nil {
+		return nil, err
+	}
+	status := newClusterStatus(*addon, a.getLastReceived(mco))
+	return &status, nil
+}
+
+func newClusterStatus(addon mcov1beta1.ObservabilityAddon, lastReceived map[string]time.Time) ClusterStatus {
+	// the namespace of the addon is the name of the managed cluster
+	status := ClusterStatus{Name: addon.Namespace, Status: "Progressing"}
+	if len(addon.Status.Conditions) != 0 {
+		conditions := newAddonConditions(addon)
+		status.Status = getAddonStatus(conditions)
+		if degraded := findDegradedCondition(conditions); degraded != nil {
+			status.Message = degraded.Message
+		} else if len(conditions) > 0 {
+			status.Message = conditions[len(conditions)-1].Message
+		}
+	}
+	if received, ok := lastReceived[addon.Namespace]; ok {
+		status.LastReceived = &metav1.Time{Time: received}
+	}
+	return status
+}
+
+// getLastReceived returns the time of the latest metrics of each cluster on the hub, the clusters
+// are missing if the query fails so that the statuses of the addons are still returned
+func (a *consoleAPI) getLastReceived(mco *mcov1beta2.MultiClusterObservability) map[string]time.Time {
+	lastReceived := map[string]time.Time{}
+	samples, err := newThanosQuerier(mco).query(fmt.Sprintf(lastReceivedQuery, config.GetClusterNameLabelKey()))
+	if err != nil {
+		log.Info("Failed to query the last time the metrics are received", "error", err.Error())
+		return lastReceived
+	}
+	for _, sample := range samples {
+		sec := int64(sample.value)
+		lastReceived[sample.labels[config.GetClusterNameLabelKey()]] = time.Unix(sec, 0).UTC()
+	}
+	return lastReceived
+}
+
+// alertmanagerAlert is an alert in the response of the api of the alertmanager
+type alertmanagerAlert struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// getAlerts returns the active alerts which are not silenced or inhibited in the alertmanager
+func (a *consoleAPI) getAlerts() ([]alertmanagerAlert, error) {
+	resp, err := a.httpClient.Get(a.alertmanagerURL + "/api/v2/alerts?active=true&silenced=false&inhibited=false")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get the alerts: %s", resp.Status)
+	}
+	alerts := []alertmanagerAlert{}
+	err = json.NewDecoder(resp.Body).Decode(&alerts)
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// getTopAlerts groups the active alerts by the name and the severity, and returns the groups
+// which fire on the most clusters
+func (a *consoleAPI) getTopAlerts(limit int) ([]AlertSummary, error) {
+	alerts, err := a.getAlerts()
+	if err != nil {
+		return nil, err
+	}
+	summaries := map[string]*AlertSummary{}
+	clusters := map[string]map[string]bool{}
+	for _, alert := range alerts {
+		name := alert.Labels["alertname"]
+		severity := alert.Labels["severity"]
+		key := name + "/" + severity
+		if summaries[key] == nil {
+			summaries[key] = &AlertSummary{AlertName: name, Severity: severity, Clusters: []string{}}
+			clusters[key] = map[string]bool{}
+		}
+		summaries[key].Firing++
+		cluster := alert.Labels[config.GetClusterNameLabelKey()]
+		if cluster != "" && !clusters[key][cluster] {
+			clusters[key][cluster] = true
+			summaries[key].Clusters = append(summaries[key].Clusters, cluster)
+		}
+	}
+	top := []AlertSummary{}
+	for _, summary := range summaries {
+		sort.Strings(summary.Clusters)
+		top = append(top, *summary)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if len(top[i].Clusters) != len(top[j].Clusters) {
+			return len(top[i].Clusters) > len(top[j].Clusters)
+		}
+		if top[i].Firing != top[j].Firing {
+			return top[i].Firing > top[j].Firing
+		}
+		return top[i].AlertName+top[i].Severity < top[j].AlertName+top[j].Severity
+	})
+	if len(top) > limit {
+		top = top[:limit]
+	}
+	return top, nil
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/testutil"
+)
+
+func TestConsoleAPI(t *testing.T) {
+	initSchema(t)
+	// the standby replica serves the api without the name of the MultiClusterObservability
+	config.SetMonitoringCRName("")
+	defer config.SetMonitoringCRName(mcoName)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/alerts" {
+			w.Write([]byte(`[
+{"labels":{"alertname":"KubePodCrashLooping","severity":"warning","cluster":"c1"}},
+{"labels":{"alertname":"KubePodCrashLooping","severity":"warning","cluster":"c1"}},
+{"labels":{"alertname":"TargetDown","severity":"warning","cluster":"c1"}},
+{"labels":{"alertname":"TargetDown","severity":"warning","cluster":"c2"}}]`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+{"metric":{"cluster":"c1"},"value":[1,"1600000000"]}]}}`))
+	}))
+	defer server.Close()
+	newThanosQuerierFn := newThanosQuerier
+	newThanosQuerier = func(mco *mcov1beta2.MultiClusterObservability) *thanosQuerier {
+		return &thanosQuerier{url: server.URL, httpClient: server.Client()}
+	}
+	defer func() { newThanosQuerier = newThanosQuerierFn }()
+	authorizeFn := authorizeConsoleRequest
+	authorizeConsoleRequest = func(c client.Client, token string) (bool, error) {
+		return token == "allowed", nil
+	}
+	defer func() { authorizeConsoleRequest = authorizeFn }()
+
+	c := fake.NewFakeClient(newTestMCO(),
+		testutil.NewObservabilityAddon("c1", testutil.AddonAvailable()),
+		testutil.NewObservabilityAddon("c2", testutil.AddonDegraded("Metrics collector failed to forward")))
+	api := newConsoleAPI(c)
+	api.alertmanagerURL = server.URL
+	api.httpClient = server.Client()
+
+	get := func(path string, token string, resp interface{}) int {
+		req := httptest.NewRequest(http.MethodGet, config.ConsoleAPIPath+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		api.ServeHTTP(w, req)
+		if w.Code == http.StatusOK {
+			err := json.Unmarshal(w.Body.Bytes(), resp)
+			if err != nil {
+				t.Fatalf("Failed to unmarshal the response of %s: (%v)", path, err)
+			}
+		}
+		return w.Code
+	}
+
+	if code := get("/fleet", "", nil); code != http.StatusUnauthorized {
+		t.Fatalf("The request without the token should be unauthorized: %d", code)
+	}
+	if code := get("/fleet", "denied", nil); code != http.StatusForbidden {
+		t.Fatalf("The request of the user who cannot list the clusters should be forbidden: %d", code)
+	}
+
+	overview := &FleetOverview{}
+	if code := get("/fleet", "allowed", overview); code != http.StatusOK {
+		t.Fatalf("Failed to get the fleet overview: %d", code)
+	}
+	expected := FleetOverview{Clusters: 2, Available: 1, Degraded: 1, Reporting: 1, FiringAlerts: 4}
+	if *overview != expected {
+		t.Fatalf("Wrong fleet overview: %v", overview)
+	}
+
+	statuses := []ClusterStatus{}
+	if code := get("/clusters", "allowed", &statuses); code != http.StatusOK {
+		t.Fatalf("Failed to get the cluster statuses: %d", code)
+	}
+	if len(statuses) != 2 || statuses[0].Name != "c1" || statuses[0].LastReceived == nil ||
+		statuses[0].LastReceived.Unix() != 1600000000 ||
+		statuses[1].Status != "Degraded" || statuses[1].LastReceived != nil {
+		t.Fatalf("Wrong cluster statuses: %v", statuses)
+	}
+	status := &ClusterStatus{}
+	if code := get("/clusters/c2", "allowed", status); code != http.StatusOK {
+		t.Fatalf("Failed to get the cluster status: %d", code)
+	}
+	if status.Status != "Degraded" || status.Message != "Metrics collector failed to forward" {
+		t.Fatalf("Wrong cluster status: %v", status)
+	}
+	if code := get("/clusters/c3", "allowed", status); code != http.StatusNotFound {
+		t.Fatalf("The unknown cluster should not be found: %d", code)
+	}
+
+	alerts := []AlertSummary{}
+	if code := get("/alerts?limit=1", "allowed", &alerts); code != http.StatusOK {
+		t.Fatalf("Failed to get the top alerts: %d", code)
+	}
+	if len(alerts) != 1 || alerts[0].AlertName != "TargetDown" || len(alerts[0].Clusters) != 2 {
+		t.Fatalf("The alert which fires on the most clusters should be the top alert: %v", alerts)
+	}
+
+	api.client = fake.NewFakeClient()
+	if code := get("/fleet", "allowed", nil); code != http.StatusServiceUnavailable {
+		t.Fatalf("The api should be unavailable without the MultiClusterObservability: %d", code)
+	}
+}
@@ -26,11 +26,12 @@ var (
 	templatePath = "/usr/local/manifests/endpoint-observability"
 )
 
-func loadTemplates(namespace string,
+// loadTemplates renders the endpoint manifests from the overlay of the platform of the managed cluster,
+// the user-provided strategic merge patches are applied on top of the overlay
+func loadTemplates(namespace, overlay string, patches []string,
 	mco *mcov1beta2.MultiClusterObservability) ([]runtime.RawExtension, error) {
 	templateRenderer := templates.NewTemplateRenderer(templatePath)
-	resourceList := []*resource.Resource{}
-	err := templateRenderer.AddTemplateFromPath(templatePath, &resourceList)
+	resourceList, err := templateRenderer.GetOverlayTemplates(overlay, patches)
 	if err != nil {
 		log.Error(err, "Failed to load templates")
 		return nil, err
@@ -69,12 +70,13 @@ func updateRes(r *resource.Resource, namespace string,
 	}
 	// set the images and watch_namespace for endpoint metrics operator
 	if r.GetKind() == "Deployment" && r.GetName() == deployName {
-		spec := obj.(*v1.Deployment).Spec.Template.Spec
+		spec := &obj.(*v1.Deployment).Spec.Template.Spec
 		for i, container := range spec.Containers {
 			if container.Name == "endpoint-observability-operator" {
 				spec.Containers[i] = updateEndpointOperator(mco, namespace, container)
 			}
 		}
+		spec.PriorityClassName = mcoconfig.GetAddonPriorityClassName(mco)
 	}
 	// set the imagepullsecrets for sa
 	if r.GetKind() == "ServiceAccount" && r.GetName() == saName {
@@ -108,10 +110,29 @@ func updateEndpointOperator(mco *mcov1beta2.MultiClusterObservability,
 			container.Env[i].Value = getImage(mco, mcoconfig.MetricsCollectorImgName,
 				mcoconfig.MetricsCollectorImgTagSuffix, mcoconfig.MetricsCollectorKey)
 		}
+		if env.Name == "LOG_FORWARDER_IMAGE" {
+			container.Env[i].Value = getExternalImage(mco, mcoconfig.LokiImgRepo,
+				mcoconfig.PromtailImgName, mcoconfig.LokiImgTag, mcoconfig.PromtailKey)
+		}
+		if env.Name == "EVENT_EXPORTER_IMAGE" {
+			container.Env[i].Value = getExternalImage(mco, mcoconfig.EventExporterImgRepo,
+				mcoconfig.EventExporterImgName, mcoconfig.EventExporterImgTag, mcoconfig.EventExporterKey)
+		}
 	}
 	return container
 }
 
+// getExternalImage returns the image which is not built in the default image repository,
+// it can be overridden by the image manifest of the mco annotations as well
+func getExternalImage(mco *mcov1beta2.MultiClusterObservability,
+	repo, name, tag, key string) string {
+	image := repo + "/" + name + ":" + tag
+	if found, replacedImage := mcoconfig.ReplaceImage(mco.Annotations, image, key); found {
+		return replacedImage
+	}
+	return image
+}
+
 func getImage(mco *mcov1beta2.MultiClusterObservability,
 	name, tag, key string) string {
 	image := mcoconfig.DefaultImgRepository +
@@ -0,0 +1,121 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+)
+
+const (
+	// the overlays of the endpoint manifests by the platform of the managed cluster
+	endpointOverlayOCP        = "ocp"
+	endpointOverlaySNO        = "sno"
+	endpointOverlayKubernetes = "kubernetes"
+	// the patches of the endpoint manifests which apply to all the platforms
+	endpointOverlayAll = "all"
+
+	clusterVendorLabelKey         = "vendor"
+	controlPlaneTopologyClaimName = "controlplanetopology.openshift.io"
+	singleReplicaTopology         = "SingleReplica"
+)
+
+// getEndpointOverlay returns the overlay of the endpoint manifests for the platform of the managed
+// cluster. The clusters of the vendors other than OpenShift use the kubernetes overlay, and the single
+// node OpenShift clusters use the sno overlay.
+func getEndpointOverlay(c client.Client, clusterName string) (string, error) {
+	cluster := &clusterv1.ManagedCluster{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return endpointOverlayOCP, nil
+		}
+		errorlog.ClusterError(log, err, clusterName, "Failed to get managedcluster")
+		return "", err
+	}
+	vendor := cluster.GetLabels()[clusterVendorLabelKey]
+	if vendor != "" && !strings.HasPrefix(vendor, "OpenShift") {
+		return endpointOverlayKubernetes, nil
+	}
+	for _, claim := range cluster.Status.ClusterClaims {
+		if claim.Name == controlPlaneTopologyClaimName && claim.Value == singleReplicaTopology {
+			return endpointOverlaySNO, nil
+		}
+	}
+	return endpointOverlayOCP, nil
+}
+
+// getEndpointOverlayPatches returns the strategic merge patches of the endpoint manifests for the
+// overlay, they are read from the labelled configmaps in the order of the names of the configmaps
+// and their keys
+func getEndpointOverlayPatches(c client.Client, overlay string) ([]string, error) {
+	cms := &corev1.ConfigMapList{}
+	err := c.List(context.TODO(), cms, client.InNamespace(config.GetDefaultNamespace()),
+		client.HasLabels{config.EndpointOverlayLabelKey})
+	if err != nil {
+		log.Error(err, "Failed to list the endpoint overlay configmaps")
+		return nil, err
+	}
+	sort.Slice(cms.Items, func(i, j int) bool { return cms.Items[i].Name < cms.Items[j].Name })
+
+	patches := []string{}
+	for _, cm := range cms.Items {
+		target := cm.Labels[config.EndpointOverlayLabelKey]
+		if target != endpointOverlayAll && target != overlay {
+			continue
+		}
+		keys := make([]string, 0, len(cm.Data))
+		for key := range cm.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if strings.TrimSpace(cm.Data[key]) != "" {
+				patches = append(patches, cm.Data[key])
+			}
+		}
+	}
+	return patches, nil
+}
+
+// isEndpointOverlayConfigMap returns true if the object is a configmap of the patches of the endpoint manifests
+func isEndpointOverlayConfigMap(obj client.Object) bool {
+	_, ok := obj.GetLabels()[config.EndpointOverlayLabelKey]
+	return ok && obj.GetNamespace() == config.GetDefaultNamespace()
+}
+
+// newEndpointOperatorScrapeConfigs returns the scrape config of the metrics of the endpoint operator
+// for the clusters without the in-cluster prometheus, so that its self-health metrics are forwarded
+// to the hub on every platform
+func newEndpointOperatorScrapeConfigs() []ScrapeConfig {
+	return []ScrapeConfig{
+		{
+			JobName:             deployName,
+			Scheme:              "http",
+			KubernetesSDConfigs: []map[string]string{{"role": "endpoints"}},
+			RelabelConfigs: []RelabelConfig{
+				{
+					SourceLabels: []string{"__meta_kubernetes_namespace", "__meta_kubernetes_service_name",
+						"__meta_kubernetes_endpoint_port_name"},
+					Regex:  spokeNameSpace + ";" + deployName + ";metrics",
+					Action: "keep",
+				},
+				{
+					SourceLabels: []string{"__meta_kubernetes_pod_name"},
+					TargetLabel:  "pod",
+				},
+			},
+		},
+	}
+}
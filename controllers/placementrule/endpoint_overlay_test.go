@@ -0,0 +1,150 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func newEndpointOverlayCM(name, overlay string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: mcoNamespace,
+			Labels:    map[string]string{config.EndpointOverlayLabelKey: overlay},
+		},
+		Data: data,
+	}
+}
+
+func TestGetEndpointOverlay(t *testing.T) {
+	initSchema(t)
+
+	objs := []runtime.Object{
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "ocp", Labels: map[string]string{clusterVendorLabelKey: "OpenShift"}},
+		},
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "eks", Labels: map[string]string{clusterVendorLabelKey: "EKS"}},
+		},
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "sno", Labels: map[string]string{clusterVendorLabelKey: "OpenShift"}},
+			Status: clusterv1.ManagedClusterStatus{
+				ClusterClaims: []clusterv1.ManagedClusterClaim{
+					{Name: controlPlaneTopologyClaimName, Value: singleReplicaTopology},
+				},
+			},
+		},
+	}
+	c := fake.NewFakeClient(objs...)
+
+	for cluster, expected := range map[string]string{
+		"ocp":     endpointOverlayOCP,
+		"eks":     endpointOverlayKubernetes,
+		"sno":     endpointOverlaySNO,
+		"missing": endpointOverlayOCP,
+	} {
+		overlay, err := getEndpointOverlay(c, cluster)
+		if err != nil {
+			t.Fatalf("Failed to get the endpoint overlay: (%v)", err)
+		}
+		if overlay != expected {
+			t.Errorf("cluster %s: expected overlay %s, got %s", cluster, expected, overlay)
+		}
+	}
+}
+
+func TestEndpointOverlayPatches(t *testing.T) {
+	initSchema(t)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get work dir: (%v)", err)
+	}
+	templatePath = path.Join(wd, "../../manifests/endpoint-observability")
+
+	patch := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: endpoint-observability-operator
+spec:
+  template:
+    metadata:
+      annotations:
+        policy.example.com/scan: "true"
+`
+	c := fake.NewFakeClient(
+		newEndpointOverlayCM("all", endpointOverlayAll, map[string]string{"deployment.yaml": patch}),
+		newEndpointOverlayCM("kubernetes", endpointOverlayKubernetes, map[string]string{"deployment.yaml": patch}),
+	)
+	patches, err := getEndpointOverlayPatches(c, endpointOverlaySNO)
+	if err != nil {
+		t.Fatalf("Failed to get the endpoint overlay patches: (%v)", err)
+	}
+	if !reflect.DeepEqual(patches, []string{patch}) {
+		t.Fatalf("only the patches for all the platforms should be selected: %v", patches)
+	}
+
+	mco := newTestMCO()
+	templates, err := loadTemplates(namespace, endpointOverlaySNO, patches, mco)
+	if err != nil {
+		t.Fatalf("Failed to load templates: (%v)", err)
+	}
+	var dep *v1.Deployment
+	for _, raw := range templates {
+		if d, ok := raw.Object.(*v1.Deployment); ok && d.Name == deployName {
+			dep = d
+		}
+	}
+	if dep == nil {
+		t.Fatalf("The endpoint operator deployment is not found")
+	}
+	if dep.Spec.Template.Annotations["policy.example.com/scan"] != "true" {
+		t.Errorf("the user patch is not applied: %v", dep.Spec.Template.Annotations)
+	}
+	if dep.Spec.Template.Spec.Containers[0].Resources.Requests.Memory().String() != "50Mi" {
+		t.Errorf("the sno overlay is not applied: %v", dep.Spec.Template.Spec.Containers[0].Resources)
+	}
+
+	templates, err = loadTemplates(namespace, endpointOverlayKubernetes, nil, mco)
+	if err != nil {
+		t.Fatalf("Failed to load templates: (%v)", err)
+	}
+	for _, raw := range templates {
+		if d, ok := raw.Object.(*v1.Deployment); ok && d.Name == deployName {
+			sc := d.Spec.Template.Spec.SecurityContext
+			if sc == nil || sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot {
+				t.Errorf("the kubernetes overlay is not applied: %v", sc)
+			}
+		}
+	}
+}
+
+func TestEndpointOperatorScrapeConfigs(t *testing.T) {
+	spokeNameSpace = "spoke-ns"
+	scrapeConfig, err := newScrapeConfigCM(newEndpointOperatorScrapeConfigs())
+	if err != nil {
+		t.Fatalf("Failed to generate the scrape config: (%v)", err)
+	}
+	data := scrapeConfig.Data[config.ScrapeConfigFileKey]
+	for _, s := range []string{"job_name: endpoint-observability-operator", "role: endpoints",
+		"regex: spoke-ns;endpoint-observability-operator;metrics"} {
+		if !strings.Contains(data, s) {
+			t.Errorf("The scrape config should contain %s: %s", s, data)
+		}
+	}
+}
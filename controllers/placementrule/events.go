@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	defaultEventsSeverity = "Warning"
+	eventsStreamJob       = "kubernetes-events"
+)
+
+// EventsFilter is the filter of the events forwarder on the managed clusters
+type EventsFilter struct {
+	Severity           string   `yaml:"severity"`
+	Namespaces         []string `yaml:"namespaces,omitempty"`
+	ExcludedNamespaces []string `yaml:"excluded-namespaces,omitempty"`
+	// StreamLabels are added to the forwarded events so that they are kept in
+	// a dedicated stream of loki apart from the logs
+	StreamLabels map[string]string `yaml:"stream-labels"`
+}
+
+// getEventsFilterCM returns the filter configmap for the events forwarder on the managed
+// clusters, or nil if the events collection is disabled or there is no logs collection
+func getEventsFilterCM(mco *mcov1beta2.MultiClusterObservability) (*corev1.ConfigMap, error) {
+	events := mco.Spec.EventsCollection
+	if events == nil || !events.Enabled {
+		return nil, nil
+	}
+	if mco.Spec.LogsCollection == nil || !mco.Spec.LogsCollection.Enabled {
+		log.Info("The logs collection is disabled, skip forwarding the kubernetes events")
+		return nil, nil
+	}
+	filter := &EventsFilter{
+		Severity:           events.Severity,
+		Namespaces:         events.Namespaces,
+		ExcludedNamespaces: events.ExcludedNamespaces,
+		StreamLabels: map[string]string{
+			"job": eventsStreamJob,
+		},
+	}
+	if filter.Severity == "" {
+		filter.Severity = defaultEventsSeverity
+	}
+	data, err := yaml.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.EventsFilterConfigMapName,
+			Namespace: spokeNameSpace,
+		},
+		Data: map[string]string{
+			config.EventsFilterFileKey: string(data),
+		},
+	}, nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestGetEventsFilterCM(t *testing.T) {
+	mco := newTestMCO()
+	mco.Spec.EventsCollection = &mcov1beta2.EventsCollectionSpec{
+		Enabled:            true,
+		ExcludedNamespaces: []string{"kube-system"},
+	}
+
+	// the events are not forwarded without the logs collection
+	cm, err := getEventsFilterCM(mco)
+	if err != nil || cm != nil {
+		t.Fatalf("The events filter should not be created without logs collection: (%v, %v)", cm, err)
+	}
+
+	mco.Spec.LogsCollection = &mcov1beta2.LogsCollectionSpec{Enabled: true}
+	cm, err = getEventsFilterCM(mco)
+	if err != nil || cm == nil {
+		t.Fatalf("Failed to get the events filter: (%v)", err)
+	}
+	if cm.Namespace != spokeNameSpace || cm.Name != config.EventsFilterConfigMapName {
+		t.Fatalf("Wrong events filter configmap: %s/%s", cm.Namespace, cm.Name)
+	}
+	filter := &EventsFilter{}
+	err = yaml.Unmarshal([]byte(cm.Data[config.EventsFilterFileKey]), filter)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the events filter: (%v)", err)
+	}
+	if filter.Severity != defaultEventsSeverity ||
+		!reflect.DeepEqual(filter.ExcludedNamespaces, []string{"kube-system"}) ||
+		filter.StreamLabels["job"] != eventsStreamJob {
+		t.Fatalf("Wrong events filter: %v", filter)
+	}
+}
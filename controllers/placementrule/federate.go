@@ -0,0 +1,247 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/deploying"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+)
+
+const (
+	federateCollectorPrefix = "metrics-collector-federate-"
+	federateTokenPath       = "/etc/federate"
+	receiveRemoteWritePort  = 19291
+)
+
+func isPullMode(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.ObservabilityAddonSpec != nil &&
+		mco.Spec.ObservabilityAddonSpec.CollectionMode == config.CollectionModePull
+}
+
+// getFederateToken returns the bearer token secret which is used by the hub to scrape
+// the /federate endpoint of the managed cluster, the token is generated at the first time
+func getFederateToken(c client.Client, namespace string) (*corev1.Secret, error) {
+	name := federateCollectorPrefix + namespace
+	found := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: config.GetDefaultNamespace()}, found)
+	if err == nil {
+		return found, nil
+	}
+	if !k8serrors.IsNotFound(err) {
+		log.Error(err, "Failed to check the federate token secret", "name", name)
+		return nil, err
+	}
+	token := make([]byte, 32)
+	_, err = rand.Read(token)
+	if err != nil {
+		return nil, err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: config.GetDefaultNamespace(),
+			Labels: map[string]string{
+				ownerLabelKey: ownerLabelValue,
+			},
+		},
+		Data: map[string][]byte{
+			config.FederateTokenKey: []byte(hex.EncodeToString(token)),
+		},
+	}
+	log.Info("Creating the federate token secret", "name", name)
+	err = c.Create(context.TODO(), secret)
+	if err != nil {
+		log.Error(err, "Failed to create the federate token secret", "name", name)
+		return nil, err
+	}
+	return secret, nil
+}
+
+// newSpokeFederateToken returns the copy of the federate token for the managed cluster,
+// the observability addon only accepts the scrapes of /federate which carry this token
+func newSpokeFederateToken(token *corev1.Secret) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.FederateTokenSecretName,
+			Namespace: spokeNameSpace,
+		},
+		Data: map[string][]byte{
+			config.FederateTokenKey: token.Data[config.FederateTokenKey],
+		},
+	}
+}
+
+// newFederateCollector returns the metrics collector deployment on the hub which scrapes the
+// /federate endpoint of the managed cluster and writes the series into thanos receive
+func newFederateCollector(mco *mcov1beta2.MultiClusterObservability,
+	clusterName string, namespace string, url string, matchers []string) *appsv1.Deployment {
+	name := federateCollectorPrefix + namespace
+	replicas := int32(1)
+	labels := map[string]string{
+		ownerLabelKey: ownerLabelValue,
+		"component":   name,
+	}
+	interval := int32(30)
+	if mco.Spec.ObservabilityAddonSpec.Interval != 0 {
+		interval = mco.Spec.ObservabilityAddonSpec.Interval
+	}
+	command := []string{
+		"/usr/bin/metrics-collector",
+		"--from=" + url,
+		"--from-token-file=" + federateTokenPath + "/" + config.FederateTokenKey,
+		fmt.Sprintf("--to-upload=http://%s:%d/api/v1/receive",
+			config.GetThanosReceiveSvc(config.GetMonitoringCRName()), receiveRemoteWritePort),
+		fmt.Sprintf("--interval=%ds", interval),
+	}
+	for _, matcher := range matchers {
+		command = append(command, "--match="+matcher)
+	}
+	command = append(command, "--label="+config.GetClusterNameLabelKey()+"="+clusterName)
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: config.GetDefaultNamespace(),
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "metrics-collector",
+							Image: getImage(mco, config.MetricsCollectorImgName,
+								config.MetricsCollectorImgTagSuffix, config.MetricsCollectorKey),
+							ImagePullPolicy: mco.Spec.ImagePullPolicy,
+							Command:         command,
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "federate-token",
+									MountPath: federateTokenPath,
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					ImagePullSecrets: []corev1.LocalObjectReference{
+						{Name: mco.Spec.ImagePullSecret},
+					},
+					NodeSelector: mco.Spec.NodeSelector,
+					Tolerations:  mco.Spec.Tolerations,
+					Volumes: []corev1.Volume{
+						{
+							Name: "federate-token",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: name,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// createFederateCollector deploys the federate metrics collector for the managed cluster
+// in pull mode, and removes it when the managed cluster has no federate url or is in push mode
+func createFederateCollector(c client.Client, mco *mcov1beta2.MultiClusterObservability,
+	clusterName string, namespace string) error {
+	if !isPullMode(mco) {
+		return deleteFederateCollector(c, namespace)
+	}
+	if config.IsExternalMetricsStoreEnabled(mco) {
+		// the federate collector writes into the thanos receive on the hub, which is not deployed
+		log.Info("The pull mode is not supported with the external metrics store, skip scraping the managedcluster",
+			"name", clusterName)
+		return deleteFederateCollector(c, namespace)
+	}
+	cluster := &clusterv1.ManagedCluster{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		errorlog.ClusterError(log, err, clusterName, "Failed to get managedcluster")
+		return err
+	}
+	url := cluster.GetAnnotations()[config.FederateURLAnnotation]
+	if url == "" {
+		log.Info("No federate url is set for the managedcluster in pull mode, skip scraping it",
+			"name", clusterName, "annotation", config.FederateURLAnnotation)
+		return deleteFederateCollector(c, namespace)
+	}
+	// the namespace filter is enforced by the series selectors of /federate in pull mode
+	filter, err := getClusterNamespaceFilter(c, mco, namespace)
+	if err != nil {
+		return err
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(
+		newFederateCollector(mco, clusterName, namespace, url, getNamespaceMatchers(filter)))
+	if err != nil {
+		return err
+	}
+	err = deploying.NewDeployer(c).Deploy(&unstructured.Unstructured{Object: obj})
+	if err != nil {
+		errorlog.ClusterError(log, err, clusterName, "Failed to deploy the federate metrics collector")
+	}
+	return err
+}
+
+// deleteFederateCollector removes the federate metrics collector and token of the managed cluster
+func deleteFederateCollector(c client.Client, namespace string) error {
+	name := federateCollectorPrefix + namespace
+	objs := []client.Object{
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: config.GetDefaultNamespace()},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: config.GetDefaultNamespace()},
+		},
+	}
+	for _, obj := range objs {
+		// check it first so that the clusters in push mode only hit the cache
+		err := c.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)
+		if k8serrors.IsNotFound(err) {
+			continue
+		}
+		if err == nil {
+			err = c.Delete(context.TODO(), obj)
+		}
+		if err != nil && !k8serrors.IsNotFound(err) {
+			log.Error(err, "Failed to delete the federate metrics collector", "name", name)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestFederateCollector(t *testing.T) {
+	initSchema(t)
+
+	federateURL := "https://federate.cluster1.example.com/federate"
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: clusterName,
+			Annotations: map[string]string{
+				config.FederateURLAnnotation: federateURL,
+			},
+		},
+	}
+	c := fake.NewFakeClient(cluster)
+	mco := newTestMCO()
+	name := federateCollectorPrefix + namespace
+	key := types.NamespacedName{Name: name, Namespace: config.GetDefaultNamespace()}
+
+	// push mode does not deploy the collector
+	err := createFederateCollector(c, mco, clusterName, namespace)
+	if err != nil {
+		t.Fatalf("Failed to create the federate collector: (%v)", err)
+	}
+	err = c.Get(context.TODO(), key, &appsv1.Deployment{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The federate collector should not be created in push mode: (%v)", err)
+	}
+
+	mco.Spec.ObservabilityAddonSpec.CollectionMode = config.CollectionModePull
+	token, err := getFederateToken(c, namespace)
+	if err != nil {
+		t.Fatalf("Failed to get the federate token: (%v)", err)
+	}
+	again, err := getFederateToken(c, namespace)
+	if err != nil {
+		t.Fatalf("Failed to get the federate token: (%v)", err)
+	}
+	if string(again.Data[config.FederateTokenKey]) != string(token.Data[config.FederateTokenKey]) {
+		t.Fatalf("The federate token should not be regenerated")
+	}
+	spokeToken := newSpokeFederateToken(token)
+	if spokeToken.Namespace != spokeNameSpace || spokeToken.Name != config.FederateTokenSecretName {
+		t.Fatalf("Wrong federate token for the managed cluster: %s/%s", spokeToken.Namespace, spokeToken.Name)
+	}
+
+	err = createFederateCollector(c, mco, clusterName, namespace)
+	if err != nil {
+		t.Fatalf("Failed to create the federate collector: (%v)", err)
+	}
+	dep := &appsv1.Deployment{}
+	err = c.Get(context.TODO(), key, dep)
+	if err != nil {
+		t.Fatalf("Failed to get the federate collector: (%v)", err)
+	}
+	args := strings.Join(dep.Spec.Template.Spec.Containers[0].Command, " ")
+	if !strings.Contains(args, "--from="+federateURL) ||
+		!strings.Contains(args, "--label="+config.GetClusterNameLabelKey()+"="+clusterName) ||
+		!strings.Contains(args, `--match={__name__=~".+"}`) {
+		t.Fatalf("Wrong args of the federate collector: %s", args)
+	}
+
+	err = deleteManagedClusterRes(c, namespace, false)
+	if err != nil {
+		t.Fatalf("Failed to delete the managedcluster resources: (%v)", err)
+	}
+	err = c.Get(context.TODO(), key, &appsv1.Deployment{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The federate collector is not deleted: (%v)", err)
+	}
+	err = c.Get(context.TODO(), key, &corev1.Secret{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("The federate token is not deleted: (%v)", err)
+	}
+}
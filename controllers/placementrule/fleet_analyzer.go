@@ -0,0 +1,117 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// the interval in which the fleet analyzer checks whether the next analysis is due, the cardinality
+// throttle and the ingestion errors report are analyzed in their own intervals
+const fleetAnalysisPollInterval = time.Minute
+
+// FleetAnalyzer runs the analyses over the whole fleet periodically: the cardinality throttle, the
+// attribution of the ingestion errors and the clock skew of the managed clusters. They are separated from
+// the placementrule reconcile, so that an event of a single cluster does not pass over the fleet. It is
+// added to the manager as a runnable, which runs on the leader only.
+type FleetAnalyzer struct {
+	Client           client.Client
+	lastClockChecked time.Time
+}
+
+// Start analyzes the fleet in the poll interval until the context is done
+func (a *FleetAnalyzer) Start(ctx context.Context) error {
+	ticker := time.NewTicker(fleetAnalysisPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := a.analyze(time.Now()); err != nil {
+				log.Error(err, "Failed to analyze the fleet")
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// analyze runs the analyses which are due
+func (a *FleetAnalyzer) analyze(now time.Time) error {
+	mco, err := a.getMCO()
+	if err != nil {
+		return err
+	}
+	if mco == nil {
+		a.lastClockChecked = time.Time{}
+		hubConditions.set(hubConditionSourceClock, nil)
+		return nil
+	}
+
+	err = updateCardinalityThrottle(a.Client, mco)
+	if err != nil {
+		return fmt.Errorf("failed to update the throttled high-cardinality metrics: %v", err)
+	}
+	err = updateIngestionErrorsReport(a.Client, mco)
+	if err != nil {
+		return fmt.Errorf("failed to update the ingestion errors report: %v", err)
+	}
+
+	if !a.lastClockChecked.IsZero() && now.Sub(a.lastClockChecked) < clockSkewCheckInterval {
+		return nil
+	}
+	obsAddonList := &mcov1beta1.ObservabilityAddonList{}
+	err = a.Client.List(context.TODO(), obsAddonList, &client.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{ownerLabelKey: ownerLabelValue}),
+	})
+	if err != nil {
+		return err
+	}
+	clusters := []string{}
+	for _, addon := range obsAddonList.Items {
+		clusters = append(clusters, addon.Namespace)
+	}
+	clockConditions, err := checkClockSkew(a.Client, clusters)
+	if err != nil {
+		return fmt.Errorf("failed to measure the clock skew of the managed clusters: %v", err)
+	}
+	conditions := map[string][]metav1.Condition{}
+	for cluster, condition := range clockConditions {
+		conditions[cluster] = []metav1.Condition{condition}
+	}
+	// the conditions are added to the managedclusteraddons by the addon status controller
+	hubConditions.set(hubConditionSourceClock, conditions)
+	a.lastClockChecked = now
+	return nil
+}
+
+// getMCO returns the MultiClusterObservability, or nil if the fleet is not analyzed for it
+func (a *FleetAnalyzer) getMCO() (*mcov1beta2.MultiClusterObservability, error) {
+	if config.GetMonitoringCRName() == "" {
+		return nil, nil
+	}
+	mco := &mcov1beta2.MultiClusterObservability{}
+	err := a.Client.Get(context.TODO(), types.NamespacedName{Name: config.GetMonitoringCRName()}, mco)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if mco.GetDeletionTimestamp() != nil || config.IsPaused(mco.GetAnnotations()) {
+		return nil, nil
+	}
+	return mco, nil
+}
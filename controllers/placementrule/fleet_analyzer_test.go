@@ -0,0 +1,65 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestFleetAnalyzer(t *testing.T) {
+	initSchema(t)
+	config.SetMonitoringCRName(mcoName)
+
+	now := time.Now().Truncate(time.Second)
+	addon := &mcov1beta1.ObservabilityAddon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      obsAddonName,
+			Namespace: namespace,
+			Labels:    map[string]string{ownerLabelKey: ownerLabelValue},
+		},
+	}
+	mco := newTestMCO()
+	lease := newClusterLease(namespace, now, time.Minute)
+	c := fake.NewFakeClient(mco, addon, lease)
+	hubConditions = &hubConditionStore{
+		conditions: map[string]map[string][]metav1.Condition{},
+		events:     make(chan event.GenericEvent, 1),
+	}
+	a := &FleetAnalyzer{Client: c}
+
+	if err := a.analyze(now); err != nil {
+		t.Fatalf("Failed to analyze the fleet: (%v)", err)
+	}
+	conditions := hubConditions.get(namespace)
+	if len(conditions) != 1 || conditions[0].Reason != ReasonClockSkewed {
+		t.Fatalf("the skewed clock should be reported: %v", conditions)
+	}
+	<-hubConditions.events
+
+	// the clock skew is not measured again until the interval passes
+	if err := c.Delete(context.TODO(), lease); err != nil {
+		t.Fatalf("Failed to delete the lease: (%v)", err)
+	}
+	if err := a.analyze(now.Add(time.Minute)); err != nil {
+		t.Fatalf("Failed to analyze the fleet: (%v)", err)
+	}
+	if len(hubConditions.get(namespace)) != 1 {
+		t.Errorf("the clock skew should not be measured again within the interval")
+	}
+	if err := a.analyze(now.Add(clockSkewCheckInterval)); err != nil {
+		t.Fatalf("Failed to analyze the fleet: (%v)", err)
+	}
+	if conditions := hubConditions.get(namespace); len(conditions) != 0 {
+		t.Errorf("the cluster without the lease should not be measured: %v", conditions)
+	}
+}
@@ -4,14 +4,21 @@
 package placementrule
 
 import (
+	"context"
+	"reflect"
 	"strings"
 	"testing"
 
 	routev1 "github.com/openshift/api/route/v1"
 	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
 )
 
 const (
@@ -49,3 +56,325 @@ func TestNewSecret(t *testing.T) {
 		t.Fatalf("Wrong content in hub info secret: (%s)", hub.ClusterName+" "+hub.Endpoint)
 	}
 }
+
+func TestNewSecretWithClusterLabels(t *testing.T) {
+	initSchema(t)
+
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: clusterName,
+			Labels: map[string]string{
+				"env":                               "prod",
+				"region.open-cluster-management.io": "us-east",
+				"vendor":                            "OpenShift",
+			},
+		},
+	}
+	objs := []runtime.Object{newTestRoute(), cluster}
+	c := fake.NewFakeClient(objs...)
+
+	mco := newTestMCO()
+	mco.Spec.InjectedClusterLabels = []string{"env", "region.open-cluster-management.io", "owner"}
+	// the labels of the cluster take precedence over the labels of the hub, and the reserved ones are skipped
+	mco.Spec.ExternalLabels = map[string]string{"hub": "hub-east", "env": "hub", "cluster": "hub"}
+	hubInfo, err := newHubInfoSecret(c, mcoNamespace, namespace, clusterName, mco)
+	if err != nil {
+		t.Fatalf("Failed to initial the hub info secret: (%v)", err)
+	}
+	hub := &HubInfo{}
+	err = yaml.Unmarshal(hubInfo.Data[hubInfoKey], &hub)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal data in hub info secret (%v)", err)
+	}
+	expected := map[string]string{
+		"env":                               "prod",
+		"hub":                               "hub-east",
+		"region_open_cluster_management_io": "us-east",
+	}
+	if !reflect.DeepEqual(hub.ExternalLabels, expected) {
+		t.Fatalf("Wrong external labels in hub info secret: (%v)", hub.ExternalLabels)
+	}
+}
+
+func TestNewSecretWithTenant(t *testing.T) {
+	initSchema(t)
+
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: clusterName,
+			Labels: map[string]string{
+				config.ClusterSetLabelKey: "team-a-prod",
+			},
+		},
+	}
+	objs := []runtime.Object{newTestRoute(), cluster}
+	c := fake.NewFakeClient(objs...)
+
+	mco := newTestMCO()
+	mco.Spec.ClusterSetTenants = []mcov1beta2.ClusterSetTenant{
+		{Name: "team-b", ClusterSets: []string{"team-b"}},
+		{Name: "team-a", ClusterSets: []string{"team-a-dev", "team-a-prod"}},
+	}
+	hubInfo, err := newHubInfoSecret(c, mcoNamespace, namespace, clusterName, mco)
+	if err != nil {
+		t.Fatalf("Failed to initial the hub info secret: (%v)", err)
+	}
+	hub := &HubInfo{}
+	err = yaml.Unmarshal(hubInfo.Data[hubInfoKey], &hub)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal data in hub info secret (%v)", err)
+	}
+	if hub.ExternalLabels[config.TenantLabelName] != "team-a" {
+		t.Fatalf("Wrong tenant label in hub info secret: (%v)", hub.ExternalLabels)
+	}
+}
+
+func TestNewSecretWithRegionalGateway(t *testing.T) {
+	initSchema(t)
+
+	newCluster := func(name string, labels map[string]string, annotations map[string]string) *clusterv1.ManagedCluster {
+		return &clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Labels:      labels,
+				Annotations: annotations,
+			},
+		}
+	}
+	objs := []runtime.Object{
+		newTestRoute(),
+		newCluster(clusterName, map[string]string{"region": "east"}, nil),
+		newCluster("gateway-east", map[string]string{"region": "east", defaultGatewayLabel: "true"},
+			map[string]string{config.GatewayURLAnnotation: "gateway.east.example.com"}),
+		newCluster("gateway-west", map[string]string{"region": "west", defaultGatewayLabel: "true"},
+			map[string]string{config.GatewayURLAnnotation: "gateway.west.example.com"}),
+		newCluster("cluster-south", map[string]string{"region": "south"}, nil),
+	}
+	c := fake.NewFakeClient(objs...)
+
+	mco := newTestMCO()
+	mco.Spec.RegionalGateway = &mcov1beta2.RegionalGatewaySpec{RegionLabel: "region"}
+	caseList := []struct {
+		cluster       string
+		endpoint      string
+		enableGateway bool
+	}{
+		{clusterName, "https://gateway.east.example.com" + urlSubPath, false},
+		{"gateway-east", "https://" + routeHost + urlSubPath, true},
+		{"cluster-south", "https://" + routeHost + urlSubPath, false},
+	}
+	for _, c1 := range caseList {
+		hubInfo, err := newHubInfoSecret(c, mcoNamespace, namespace, c1.cluster, mco)
+		if err != nil {
+			t.Fatalf("Failed to initial the hub info secret: (%v)", err)
+		}
+		hub := &HubInfo{}
+		err = yaml.Unmarshal(hubInfo.Data[hubInfoKey], &hub)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal data in hub info secret (%v)", err)
+		}
+		if hub.Endpoint != c1.endpoint || hub.EnableGateway != c1.enableGateway {
+			t.Fatalf("Wrong hub info for %s: (%s, %v)", c1.cluster, hub.Endpoint, hub.EnableGateway)
+		}
+	}
+}
+
+func TestNewSecretWithProxyEndpoint(t *testing.T) {
+	initSchema(t)
+
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        clusterName,
+			Annotations: map[string]string{config.ProxyEndpointAnnotation: "proxy.example.com:8443"},
+		},
+	}
+	serverCerts := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: config.ServerCerts, Namespace: mcoNamespace},
+	}
+	c := fake.NewFakeClient(newTestRoute(), cluster, serverCerts)
+
+	// the cluster writes to the hub directly until the server certificate covers the proxy
+	hubInfo, err := newHubInfoSecret(c, mcoNamespace, namespace, clusterName, newTestMCO())
+	if err != nil {
+		t.Fatalf("Failed to initial the hub info secret: (%v)", err)
+	}
+	hub := &HubInfo{}
+	err = yaml.Unmarshal(hubInfo.Data[hubInfoKey], &hub)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal data in hub info secret (%v)", err)
+	}
+	if hub.Endpoint != "https://"+routeHost+urlSubPath || hubInfo.GetAnnotations()[config.ProxyEndpointAnnotation] != "" {
+		t.Fatalf("Wrong hub info before the proxy is covered: (%s, %v)", hub.Endpoint, hubInfo.GetAnnotations())
+	}
+
+	serverCerts.Annotations = map[string]string{config.ObsAPIProxyEndpointsAnnotation: "proxy.example.com"}
+	err = c.Update(context.TODO(), serverCerts)
+	if err != nil {
+		t.Fatalf("Failed to update the server certificate secret: (%v)", err)
+	}
+	hubInfo, err = newHubInfoSecret(c, mcoNamespace, namespace, clusterName, newTestMCO())
+	if err != nil {
+		t.Fatalf("Failed to initial the hub info secret: (%v)", err)
+	}
+	err = yaml.Unmarshal(hubInfo.Data[hubInfoKey], &hub)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal data in hub info secret (%v)", err)
+	}
+	if hub.Endpoint != "https://proxy.example.com:8443"+urlSubPath ||
+		hubInfo.GetAnnotations()[config.ProxyEndpointAnnotation] != "proxy.example.com:8443" {
+		t.Fatalf("Wrong hub info through the proxy: (%s, %v)", hub.Endpoint, hubInfo.GetAnnotations())
+	}
+}
+
+func TestNewSecretWithLogsEndpoint(t *testing.T) {
+	initSchema(t)
+
+	lokiRoute := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.Loki,
+			Namespace: mcoNamespace,
+		},
+		Spec: routev1.RouteSpec{
+			Host: "loki-host",
+		},
+	}
+	c := fake.NewFakeClient(newTestRoute(), lokiRoute)
+
+	mco := newTestMCO()
+	caseList := []struct {
+		name     string
+		logs     *mcov1beta2.LogsCollectionSpec
+		endpoint string
+	}{
+		{"disabled", nil, ""},
+		{"hub loki", &mcov1beta2.LogsCollectionSpec{Enabled: true}, "https://loki-host" + lokiPushSubPath},
+		{"external loki", &mcov1beta2.LogsCollectionSpec{Enabled: true, ExternalLokiURL: "https://loki.example.com/"},
+			"https://loki.example.com" + lokiPushSubPath},
+	}
+	for _, c1 := range caseList {
+		mco.Spec.LogsCollection = c1.logs
+		hubInfo, err := newHubInfoSecret(c, mcoNamespace, namespace, clusterName, mco)
+		if err != nil {
+			t.Fatalf("Failed to initial the hub info secret: (%v)", err)
+		}
+		hub := &HubInfo{}
+		err = yaml.Unmarshal(hubInfo.Data[hubInfoKey], &hub)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal data in hub info secret (%v)", err)
+		}
+		if hub.LogsEndpoint != c1.endpoint {
+			t.Fatalf("Wrong logs endpoint for case %s: %s", c1.name, hub.LogsEndpoint)
+		}
+	}
+}
+
+func TestNewSecretWithTracesEndpoint(t *testing.T) {
+	initSchema(t)
+
+	otlpRoute := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.OTLPReceiver,
+			Namespace: mcoNamespace,
+		},
+		Spec: routev1.RouteSpec{
+			Host: "otlp-host",
+		},
+	}
+	c := fake.NewFakeClient(newTestRoute(), otlpRoute)
+
+	mco := newTestMCO()
+	mco.Spec.Tracing = &mcov1beta2.TracingSpec{
+		Enabled:  true,
+		Endpoint: "tempo-distributor.tracing.svc:4317",
+		QueryURL: "http://tempo-query-frontend.tracing.svc:3100",
+	}
+	hubInfo, err := newHubInfoSecret(c, mcoNamespace, namespace, clusterName, mco)
+	if err != nil {
+		t.Fatalf("Failed to initial the hub info secret: (%v)", err)
+	}
+	hub := &HubInfo{}
+	err = yaml.Unmarshal(hubInfo.Data[hubInfoKey], &hub)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal data in hub info secret (%v)", err)
+	}
+	if hub.TracesEndpoint != "otlp-host:443" {
+		t.Fatalf("Wrong traces endpoint: %s", hub.TracesEndpoint)
+	}
+}
+
+func TestNewSecretWithExternalMetricsStore(t *testing.T) {
+	initSchema(t)
+
+	// the route of the observatorium api does not exist with the external metrics store
+	c := fake.NewFakeClient()
+	mco := newTestMCO()
+	mco.Spec.ExternalMetricsStore = &mcov1beta2.ExternalMetricsStoreSpec{
+		RemoteWriteURL:    "https://mimir.example.com/api/v1/push",
+		QueryURL:          "https://mimir.example.com/prometheus",
+		CredentialsSecret: "mimir",
+	}
+	hubInfo, err := newHubInfoSecret(c, mcoNamespace, namespace, clusterName, mco)
+	if err != nil {
+		t.Fatalf("Failed to initial the hub info secret: (%v)", err)
+	}
+	hub := &HubInfo{}
+	err = yaml.Unmarshal(hubInfo.Data[hubInfoKey], &hub)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal data in hub info secret (%v)", err)
+	}
+	if hub.Endpoint != mco.Spec.ExternalMetricsStore.RemoteWriteURL ||
+		hub.EndpointAuthSecret != config.ExternalMetricsStoreSecretName {
+		t.Fatalf("The managed cluster should write to the external metrics store: %v", hub)
+	}
+}
+
+func TestNewSecretWithMimir(t *testing.T) {
+	initSchema(t)
+
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: clusterName,
+			Labels: map[string]string{
+				config.ClusterSetLabelKey: "team-a",
+			},
+		},
+	}
+	c := fake.NewFakeClient(cluster)
+	mco := newTestMCO()
+	mco.Spec.ExternalMetricsStore = &mcov1beta2.ExternalMetricsStoreSpec{
+		Type:           config.ExternalMetricsStoreMimir,
+		RemoteWriteURL: "https://mimir.example.com/api/v1/push",
+		QueryURL:       "https://mimir.example.com/prometheus",
+	}
+	getHubInfo := func() *HubInfo {
+		hubInfo, err := newHubInfoSecret(c, mcoNamespace, namespace, clusterName, mco)
+		if err != nil {
+			t.Fatalf("Failed to initial the hub info secret: (%v)", err)
+		}
+		hub := &HubInfo{}
+		err = yaml.Unmarshal(hubInfo.Data[hubInfoKey], &hub)
+		if err != nil {
+			t.Fatalf("Failed to unmarshal data in hub info secret (%v)", err)
+		}
+		return hub
+	}
+
+	hub := getHubInfo()
+	if hub.EndpointHeaders[config.ScopeOrgIDHeader] != config.DefaultExternalMetricsStoreTenantID {
+		t.Fatalf("The cluster without the tenant should use the default tenant ID: %v", hub.EndpointHeaders)
+	}
+
+	mco.Spec.ClusterSetTenants = []mcov1beta2.ClusterSetTenant{
+		{Name: "team-a", ClusterSets: []string{"team-a"}},
+	}
+	hub = getHubInfo()
+	if hub.EndpointHeaders[config.ScopeOrgIDHeader] != "team-a" {
+		t.Fatalf("The cluster of the tenant should use the tenant ID of the tenant: %v", hub.EndpointHeaders)
+	}
+
+	mco.Spec.ExternalMetricsStore.Type = config.ExternalMetricsStoreThanos
+	hub = getHubInfo()
+	if len(hub.EndpointHeaders) != 0 {
+		t.Fatalf("Thanos does not require the X-Scope-OrgID header: %v", hub.EndpointHeaders)
+	}
+}
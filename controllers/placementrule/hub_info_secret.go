@@ -4,15 +4,22 @@
 package placementrule
 
 import (
+	"context"
+	"regexp"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
 	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
 )
 
 const (
@@ -20,27 +27,88 @@ const (
 	hubInfoKey  = "hub-info.yaml"
 	urlSubPath  = "/api/metrics/v1/default/api/v1/receive"
 	protocol    = "https://"
+
+	lokiPushSubPath = "/loki/api/v1/push"
+
+	defaultGatewayLabel = "observability.open-cluster-management.io/gateway"
 )
 
+var invalidLabelNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
 // HubInfo is the struct for hub info
 type HubInfo struct {
-	ClusterName string `yaml:"cluster-name"`
-	Endpoint    string `yaml:"endpoint"`
+	ClusterName    string            `yaml:"cluster-name"`
+	Endpoint       string            `yaml:"endpoint"`
+	ExternalLabels map[string]string `yaml:"external-labels,omitempty"`
+	// EnableGateway is true when the managed cluster is the regional gateway, which receives
+	// the series from the other managed clusters of its region and forwards them to Endpoint
+	EnableGateway bool `yaml:"enable-gateway,omitempty"`
+	// LogsEndpoint is the loki push endpoint which the log forwarder sends the logs to
+	LogsEndpoint string `yaml:"logs-endpoint,omitempty"`
+	// TracesEndpoint is the otlp receiver on the hub which the traces forwarder sends the traces to
+	TracesEndpoint string `yaml:"traces-endpoint,omitempty"`
+	// EndpointAuthSecret is the secret in the namespace of the addon which contains the credentials
+	// of the external metrics store, the collector uses them instead of the client certificate
+	EndpointAuthSecret string `yaml:"endpoint-auth-secret,omitempty"`
+	// EndpointHeaders are the headers which the collector adds to the remote writes, e.g. the
+	// X-Scope-OrgID of the tenant of the cluster when the external metrics store is Cortex or Mimir
+	EndpointHeaders map[string]string `yaml:"endpoint-headers,omitempty"`
+	// PriorityClassName is the priority class of the collectors and the forwarders which the
+	// endpoint operator deploys
+	PriorityClassName string `yaml:"priority-class-name,omitempty"`
+	// EndpointAuthMode is token when the collector authenticates the remote writes with the tokens of
+	// TokenServiceAccount on the hub, which it requests with the hub kubeconfig of the addon, instead
+	// of the client certificate
+	EndpointAuthMode       string `yaml:"endpoint-auth-mode,omitempty"`
+	TokenServiceAccount    string `yaml:"token-service-account,omitempty"`
+	TokenAudience          string `yaml:"token-audience,omitempty"`
+	TokenExpirationSeconds int64  `yaml:"token-expiration-seconds,omitempty"`
 }
 
 func newHubInfoSecret(client client.Client, obsNamespace string,
 	namespace string, clusterName string, mco *mcov1beta2.MultiClusterObservability) (*corev1.Secret, error) {
-	url, err := config.GetObsAPIUrl(client, obsNamespace)
+	endpoint, authSecret, err := getMetricsEndpoint(client, obsNamespace, mco)
 	if err != nil {
-		log.Error(err, "Failed to get api gateway")
 		return nil, err
 	}
-	if !strings.HasPrefix(url, "http") {
-		url = protocol + url
+	externalLabels, err := getClusterExternalLabels(client, clusterName, mco)
+	if err != nil {
+		return nil, err
+	}
+	proxyEndpoint, err := getProxyEndpoint(client, obsNamespace, clusterName, mco)
+	if err != nil {
+		return nil, err
+	}
+	if proxyEndpoint != "" {
+		// remote write to the hub through the cluster proxy
+		endpoint = getProxyMetricsEndpoint(proxyEndpoint)
+	}
+	gatewayURL, isGateway, err := getRegionalGateway(client, clusterName, mco)
+	if err != nil {
+		return nil, err
+	}
+	if gatewayURL != "" {
+		// remote write to the gateway of the region instead of the hub
+		url := gatewayURL
+		if !strings.HasPrefix(url, "http") {
+			url = protocol + url
+		}
+		endpoint = url + urlSubPath
+		proxyEndpoint = ""
 	}
 	hubInfo := &HubInfo{
-		ClusterName: clusterName,
-		Endpoint:    url + urlSubPath,
+		ClusterName:        clusterName,
+		Endpoint:           endpoint,
+		ExternalLabels:     externalLabels,
+		EnableGateway:      isGateway,
+		LogsEndpoint:       getLogsEndpoint(client, obsNamespace, mco),
+		TracesEndpoint:     getTracesEndpoint(client, obsNamespace, mco),
+		EndpointAuthSecret: authSecret,
+		EndpointHeaders:    getEndpointHeaders(externalLabels, mco),
+		PriorityClassName:  config.GetAddonPriorityClassName(mco),
+	}
+	if err := setTokenAuth(client, hubInfo, mco); err != nil {
+		return nil, err
 	}
 	configYaml, err := yaml.Marshal(hubInfo)
 	if err != nil {
@@ -48,7 +116,7 @@ func newHubInfoSecret(client client.Client, obsNamespace string,
 	}
 	configYamlMap := map[string][]byte{}
 	configYamlMap[hubInfoKey] = configYaml
-	return &corev1.Secret{
+	secret := &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: corev1.SchemeGroupVersion.String(),
 			Kind:       "Secret",
@@ -58,5 +126,218 @@ func newHubInfoSecret(client client.Client, obsNamespace string,
 			Namespace: namespace,
 		},
 		Data: configYamlMap,
-	}, nil
+	}
+	if proxyEndpoint != "" {
+		// the manifestwork records the proxy which the cluster writes through
+		secret.Annotations = map[string]string{config.ProxyEndpointAnnotation: proxyEndpoint}
+	}
+	return secret, nil
+}
+
+// getMetricsEndpoint returns the remote write endpoint of the observatorium api on the hub, or the
+// external metrics store and the name of the secret of its credentials on the managed cluster
+func getMetricsEndpoint(c client.Client, obsNamespace string,
+	mco *mcov1beta2.MultiClusterObservability) (string, string, error) {
+	if config.IsExternalMetricsStoreEnabled(mco) {
+		authSecret := ""
+		if mco.Spec.ExternalMetricsStore.CredentialsSecret != "" {
+			authSecret = config.ExternalMetricsStoreSecretName
+		}
+		return mco.Spec.ExternalMetricsStore.RemoteWriteURL, authSecret, nil
+	}
+	// the endpoint which the server certificate covers, it follows the route once the certificate
+	// is reissued for the new host of the route
+	url, err := config.GetObsAPIEndpoint(c, obsNamespace)
+	if err != nil {
+		log.Error(err, "Failed to get api gateway")
+		return "", "", err
+	}
+	if !strings.HasPrefix(url, "http") {
+		url = protocol + url
+	}
+	return url + urlSubPath, "", nil
+}
+
+// getEndpointHeaders returns the X-Scope-OrgID header of the tenant of the cluster when the
+// external metrics store is Cortex or Mimir, the regional gateway keeps the headers of the clusters
+// of its region when it forwards the series
+func getEndpointHeaders(externalLabels map[string]string,
+	mco *mcov1beta2.MultiClusterObservability) map[string]string {
+	if !config.IsScopeOrgIDRequired(mco) {
+		return nil
+	}
+	return map[string]string{
+		config.ScopeOrgIDHeader: config.GetExternalMetricsStoreTenantID(mco, externalLabels[config.TenantLabelName]),
+	}
+}
+
+// newSpokeExternalMetricsStoreSecret returns the copy of the credentials of the external metrics
+// store for the managed cluster
+func newSpokeExternalMetricsStoreSecret(secret *corev1.Secret) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.ExternalMetricsStoreSecretName,
+			Namespace: spokeNameSpace,
+		},
+		Data: secret.Data,
+	}
+}
+
+// getLogsEndpoint returns the push endpoint of the loki which receives the logs,
+// or empty if the logs collection is disabled or the loki route is not ready yet
+func getLogsEndpoint(c client.Client, obsNamespace string, mco *mcov1beta2.MultiClusterObservability) string {
+	logs := mco.Spec.LogsCollection
+	if logs == nil || !logs.Enabled {
+		return ""
+	}
+	if logs.ExternalLokiURL != "" {
+		return strings.TrimSuffix(logs.ExternalLokiURL, "/") + lokiPushSubPath
+	}
+	host, err := config.GetLokiUrl(c, obsNamespace)
+	if err != nil {
+		log.Info("Failed to get the loki route, skip forwarding the logs", "error", err.Error())
+		return ""
+	}
+	return protocol + host + lokiPushSubPath
+}
+
+// getTracesEndpoint returns the otlp gRPC endpoint of the otlp receiver route, or empty
+// if the traces collection is disabled or the otlp receiver route is not ready yet
+func getTracesEndpoint(c client.Client, obsNamespace string, mco *mcov1beta2.MultiClusterObservability) string {
+	if !config.IsTracingEnabled(mco) {
+		return ""
+	}
+	host, err := config.GetOTLPReceiverUrl(c, obsNamespace)
+	if err != nil {
+		log.Info("Failed to get the otlp receiver route, skip forwarding the traces", "error", err.Error())
+		return ""
+	}
+	return host + ":443"
+}
+
+// getClusterExternalLabels returns the external labels for the managed cluster
+// from the external labels of the hub, the values of its labels whose keys are
+// listed in InjectedClusterLabels, the tenant label if the cluster belongs to a
+// cluster set of a tenant, and the clusterID label from its id claim when the
+// cluster identity is enabled
+func getClusterExternalLabels(c client.Client, clusterName string,
+	mco *mcov1beta2.MultiClusterObservability) (map[string]string, error) {
+	if len(mco.Spec.InjectedClusterLabels) == 0 && len(mco.Spec.ClusterSetTenants) == 0 &&
+		mco.Spec.ClusterIdentity == nil && len(mco.Spec.ExternalLabels) == 0 {
+		return nil, nil
+	}
+	cluster := &clusterv1.ManagedCluster{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			log.Info("managedcluster does not exist, skip injecting the cluster labels", "name", clusterName)
+			return nil, nil
+		}
+		errorlog.ClusterError(log, err, clusterName, "Failed to get managedcluster")
+		return nil, err
+	}
+	// the labels of the cluster take precedence over the labels of the hub
+	labels := config.GetHubExternalLabels(mco)
+	for _, key := range mco.Spec.InjectedClusterLabels {
+		value, found := cluster.GetLabels()[key]
+		if !found || value == "" {
+			continue
+		}
+		labels[toLabelName(key)] = value
+	}
+	if tenant := getClusterTenant(cluster, mco.Spec.ClusterSetTenants); tenant != "" {
+		labels[config.TenantLabelName] = tenant
+	}
+	if mco.Spec.ClusterIdentity != nil {
+		if id := getClusterID(cluster, mco.Spec.ClusterIdentity); id != "" {
+			labels[config.ClusterIDLabelName] = id
+		}
+	}
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	return labels, nil
+}
+
+// getClusterTenant returns the name of the tenant whose cluster sets contain the
+// managed cluster, or empty if the cluster does not belong to any tenant
+func getClusterTenant(cluster *clusterv1.ManagedCluster, tenants []mcov1beta2.ClusterSetTenant) string {
+	clusterSet := cluster.GetLabels()[config.ClusterSetLabelKey]
+	if clusterSet == "" {
+		return ""
+	}
+	for _, tenant := range tenants {
+		for _, set := range tenant.ClusterSets {
+			if set == clusterSet {
+				return tenant.Name
+			}
+		}
+	}
+	return ""
+}
+
+// getRegionalGateway returns the url of the gateway which the managed cluster remote writes to,
+// or true if the managed cluster is the gateway of its region itself. The url is empty when
+// the regional gateway is not configured, or there is no gateway in the region of the cluster
+func getRegionalGateway(c client.Client, clusterName string,
+	mco *mcov1beta2.MultiClusterObservability) (string, bool, error) {
+	spec := mco.Spec.RegionalGateway
+	if spec == nil || spec.RegionLabel == "" {
+		return "", false, nil
+	}
+	gatewayLabel := spec.GatewayLabel
+	if gatewayLabel == "" {
+		gatewayLabel = defaultGatewayLabel
+	}
+	cluster := &clusterv1.ManagedCluster{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", false, nil
+		}
+		errorlog.ClusterError(log, err, clusterName, "Failed to get managedcluster")
+		return "", false, err
+	}
+	if cluster.GetLabels()[gatewayLabel] == "true" {
+		return "", true, nil
+	}
+	region := cluster.GetLabels()[spec.RegionLabel]
+	if region == "" {
+		return "", false, nil
+	}
+	gateways := &clusterv1.ManagedClusterList{}
+	err = c.List(context.TODO(), gateways, client.MatchingLabels{
+		spec.RegionLabel: region,
+		gatewayLabel:     "true",
+	})
+	if err != nil {
+		log.Error(err, "Failed to list the gateway managedclusters", "region", region)
+		return "", false, err
+	}
+	// pick the same gateway on every reconcile when there are multiple in the region
+	sort.Slice(gateways.Items, func(i, j int) bool {
+		return gateways.Items[i].Name < gateways.Items[j].Name
+	})
+	for _, gateway := range gateways.Items {
+		if url := gateway.GetAnnotations()[config.GatewayURLAnnotation]; url != "" {
+			return url, false, nil
+		}
+	}
+	log.Info("No gateway with url in the region, remote write to the hub directly",
+		"cluster", clusterName, "region", region, "annotation", config.GatewayURLAnnotation)
+	return "", false, nil
+}
+
+// toLabelName converts the kubernetes label key to a valid prometheus label name,
+// e.g. region.open-cluster-management.io/zone -> region_open_cluster_management_io_zone
+func toLabelName(key string) string {
+	name := invalidLabelNameChars.ReplaceAllString(key, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
 }
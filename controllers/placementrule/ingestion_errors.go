@@ -0,0 +1,107 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	ingestionErrorsAnalysisInterval = 10 * time.Minute
+	// the time of the last attribution of the rejected remote writes
+	ingestionErrorsAnalyzedAnnotation = "observability.open-cluster-management.io/last-analysis"
+)
+
+// updateIngestionErrorsReport attributes the remote writes which the hub rejects to the managed
+// clusters. The rejected remote writes are queried from the hub at most once in the analysis interval.
+func updateIngestionErrorsReport(c client.Client, mco *mcov1beta2.MultiClusterObservability) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.IngestionErrorsConfigMapName,
+			Namespace: config.GetDefaultNamespace(),
+		},
+	}
+	if !mco.Spec.EnableIngestionErrorAttribution {
+		err := c.Delete(context.TODO(), cm)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	found := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}, found)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	now := time.Now()
+	lastAnalysis, err := time.Parse(time.RFC3339, found.Annotations[ingestionErrorsAnalyzedAnnotation])
+	if err == nil && now.Sub(lastAnalysis) < ingestionErrorsAnalysisInterval {
+		return nil
+	}
+	report, err := newIngestionErrorsReport(mco)
+	if err != nil {
+		// keep the current report and attribute again in the next analysis
+		log.Error(err, "Failed to query the rejected remote writes")
+		return nil
+	}
+
+	data, err := yaml.Marshal(report)
+	if err != nil {
+		return err
+	}
+	cm.Annotations = map[string]string{ingestionErrorsAnalyzedAnnotation: now.Format(time.RFC3339)}
+	cm.Data = map[string]string{config.IngestionErrorsFileKey: string(data)}
+	if !exists {
+		log.Info("Creating the ingestion errors report")
+		return c.Create(context.TODO(), cm)
+	}
+	if !reflect.DeepEqual(found.Data, cm.Data) {
+		log.Info("Updating the ingestion errors report", "clusters", len(report.Clusters))
+	}
+	found.Annotations = cm.Annotations
+	found.Data = cm.Data
+	return c.Update(context.TODO(), found)
+}
+
+// newIngestionErrorsReport returns the managed clusters with most rejected remote writes in the last hour
+func newIngestionErrorsReport(mco *mcov1beta2.MultiClusterObservability) (*config.IngestionErrorsReport, error) {
+	samples, err := newThanosQuerier(mco).query(config.GetIngestionErrorsExpr())
+	if err != nil {
+		return nil, err
+	}
+	report := &config.IngestionErrorsReport{Clusters: []config.ClusterIngestionErrors{}}
+	for _, sample := range samples {
+		cluster := sample.labels[config.GetClusterNameLabelKey()]
+		if cluster == "" || int64(sample.value) <= 0 {
+			continue
+		}
+		report.Clusters = append(report.Clusters, config.ClusterIngestionErrors{
+			Cluster: cluster,
+			Errors:  int64(sample.value),
+		})
+	}
+	sort.Slice(report.Clusters, func(i, j int) bool {
+		if report.Clusters[i].Errors != report.Clusters[j].Errors {
+			return report.Clusters[i].Errors > report.Clusters[j].Errors
+		}
+		return report.Clusters[i].Cluster < report.Clusters[j].Cluster
+	})
+	return report, nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestUpdateIngestionErrorsReport(t *testing.T) {
+	initSchema(t)
+
+	queries := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries++
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+{"metric":{"cluster":"cluster1"},"value":[1,"12"]},
+{"metric":{"cluster":"cluster2"},"value":[1,"340.5"]}]}}`))
+	}))
+	defer server.Close()
+	newThanosQuerierFn := newThanosQuerier
+	newThanosQuerier = func(mco *mcov1beta2.MultiClusterObservability) *thanosQuerier {
+		return &thanosQuerier{url: server.URL, httpClient: server.Client()}
+	}
+	defer func() { newThanosQuerier = newThanosQuerierFn }()
+
+	mco := newTestMCO()
+	mco.Spec.EnableIngestionErrorAttribution = true
+	c := fake.NewFakeClient()
+
+	err := updateIngestionErrorsReport(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to update the ingestion errors report: (%v)", err)
+	}
+	report, err := config.GetIngestionErrorsReport(c)
+	if err != nil || report == nil {
+		t.Fatalf("Failed to get the ingestion errors report: %v (%v)", report, err)
+	}
+	expected := []config.ClusterIngestionErrors{
+		{Cluster: "cluster2", Errors: 340},
+		{Cluster: "cluster1", Errors: 12},
+	}
+	if !reflect.DeepEqual(report.Clusters, expected) {
+		t.Fatalf("the clusters should be sorted by the rejected remote writes: %v", report.Clusters)
+	}
+
+	// the report is not analyzed again within the interval
+	err = updateIngestionErrorsReport(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to update the ingestion errors report: (%v)", err)
+	}
+	if queries != 1 {
+		t.Errorf("the rejected remote writes should be queried once in the interval: %d", queries)
+	}
+
+	mco.Spec.EnableIngestionErrorAttribution = false
+	err = updateIngestionErrorsReport(c, mco)
+	if err != nil {
+		t.Fatalf("Failed to update the ingestion errors report: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      config.IngestionErrorsConfigMapName,
+		Namespace: mcoNamespace,
+	}, &corev1.ConfigMap{})
+	if !k8serrors.IsNotFound(err) {
+		t.Fatalf("the report should be removed once the attribution is disabled: (%v)", err)
+	}
+}
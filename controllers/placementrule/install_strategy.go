@@ -0,0 +1,166 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"encoding/json"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	clusterv1alpha1 "github.com/open-cluster-management/api/cluster/v1alpha1"
+	placementv1 "github.com/open-cluster-management/multicloud-operators-placementrule/pkg/apis/apps/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+// getClusterManagementAddon fetches the ClusterManagementAddOn of the observability addon as
+// unstructured, the vendored API of the ClusterManagementAddOn predates the install strategy
+func getClusterManagementAddon(c client.Reader) (*unstructured.Unstructured, error) {
+	cma := &unstructured.Unstructured{}
+	cma.SetAPIVersion(addonv1alpha1.SchemeGroupVersion.String())
+	cma.SetKind("ClusterManagementAddOn")
+	err := c.Get(context.TODO(), types.NamespacedName{Name: util.ObservabilityController}, cma)
+	if err != nil {
+		return nil, err
+	}
+	return cma, nil
+}
+
+// installStrategyPlacements returns the placements in the install strategy of the
+// ClusterManagementAddOn, nil is returned if the type of the strategy is not Placements
+func installStrategyPlacements(cma *unstructured.Unstructured) []types.NamespacedName {
+	strategyType, _, _ := unstructured.NestedString(cma.Object, "spec", "installStrategy", "type")
+	if strategyType != config.InstallStrategyPlacements {
+		return nil
+	}
+	placements, _, _ := unstructured.NestedSlice(cma.Object, "spec", "installStrategy", "placements")
+	refs := []types.NamespacedName{}
+	for _, p := range placements {
+		ref, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(ref, "name")
+		namespace, _, _ := unstructured.NestedString(ref, "namespace")
+		if name == "" || namespace == "" {
+			continue
+		}
+		refs = append(refs, types.NamespacedName{Name: name, Namespace: namespace})
+	}
+	return refs
+}
+
+// getInstallStrategyPlacements returns the placements in the install strategy of the
+// ClusterManagementAddOn of the observability addon, nil is returned if the strategy is not set
+func getInstallStrategyPlacements(c client.Reader) ([]types.NamespacedName, error) {
+	cma, err := getClusterManagementAddon(c)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		log.Error(err, "Failed to get clustermanagementaddon", "name", util.ObservabilityController)
+		return nil, err
+	}
+	return installStrategyPlacements(cma), nil
+}
+
+// getInstallStrategyDecisions merges the decisions of the placements in the install strategy into
+// the placement, the NotFound error is returned if none of the placements exists
+func getInstallStrategyDecisions(c client.Reader, refs []types.NamespacedName,
+	placement *placementv1.PlacementRule) error {
+	placement.SetName(util.ObservabilityController)
+	placement.SetNamespace(watchNamespace)
+	found := false
+	for _, ref := range refs {
+		if config.IsNamespaceScoped() && ref.Namespace != watchNamespace {
+			log.Info("The placement out of the namespace of the operator is skipped", "placement", ref.String())
+			continue
+		}
+		p := &unstructured.Unstructured{}
+		p.SetAPIVersion(config.PlacementAPIVersion)
+		p.SetKind(config.PlacementKindPlacement)
+		err := c.Get(context.TODO(), ref, p)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				log.Info("The placement of the install strategy does not exist", "placement", ref.String())
+				continue
+			}
+			return err
+		}
+		found = true
+		err = appendPlacementDecisions(c, ref.Namespace, ref.Name, placement)
+		if err != nil {
+			return err
+		}
+	}
+	if !found {
+		return k8serrors.NewNotFound(schema.GroupResource{Group: clusterv1alpha1.GroupVersion.Group, Resource: "placements"},
+			refs[0].String())
+	}
+	return nil
+}
+
+// isInstallStrategyPlacement returns true if the Placement is in the install strategy of the
+// ClusterManagementAddOn
+func isInstallStrategyPlacement(c client.Client, namespace, name string) bool {
+	refs, err := getInstallStrategyPlacements(c)
+	if err != nil {
+		return false
+	}
+	for _, ref := range refs {
+		if ref.Namespace == namespace && ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// initInstallStrategy sets the install strategy of the ClusterManagementAddOn to the Placement
+// referenced by the MultiClusterObservability if the strategy is not set, so that the targeting
+// of the addon is visible and editable with the OCM APIs. The strategy set by the user is kept.
+func initInstallStrategy(c client.Client, mco *mcov1beta2.MultiClusterObservability) error {
+	if config.GetPlacementRefKind(mco) != config.PlacementKindPlacement {
+		return nil
+	}
+	cma, err := getClusterManagementAddon(c)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			// the strategy is set once the clustermanagementaddon is created
+			return nil
+		}
+		log.Error(err, "Failed to get clustermanagementaddon", "name", util.ObservabilityController)
+		return err
+	}
+	if _, found, _ := unstructured.NestedMap(cma.Object, "spec", "installStrategy"); found {
+		return nil
+	}
+	name := config.GetPlacementRefName(mco)
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"installStrategy": map[string]interface{}{
+				"type": config.InstallStrategyPlacements,
+				"placements": []interface{}{
+					map[string]interface{}{"name": name, "namespace": watchNamespace},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	err = c.Patch(context.TODO(), cma, client.RawPatch(types.MergePatchType, patch))
+	if err != nil {
+		log.Error(err, "Failed to set the install strategy of clustermanagementaddon", "placement", name)
+		return err
+	}
+	log.Info("Set the install strategy of clustermanagementaddon", "placement", name)
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"testing"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	placementv1 "github.com/open-cluster-management/multicloud-operators-placementrule/pkg/apis/apps/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func newTestPlacement(namespace, name string) *unstructured.Unstructured {
+	p := &unstructured.Unstructured{}
+	p.SetAPIVersion(config.PlacementAPIVersion)
+	p.SetKind(config.PlacementKindPlacement)
+	p.SetName(name)
+	p.SetNamespace(namespace)
+	return p
+}
+
+func TestInstallStrategyPlacements(t *testing.T) {
+	cma := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"installStrategy": map[string]interface{}{
+				"type": "Manual",
+			},
+		},
+	}}
+	if refs := installStrategyPlacements(cma); refs != nil {
+		t.Errorf("The placements of the manual install strategy should be skipped: %v", refs)
+	}
+
+	cma.Object["spec"] = map[string]interface{}{
+		"installStrategy": map[string]interface{}{
+			"type": config.InstallStrategyPlacements,
+			"placements": []interface{}{
+				map[string]interface{}{"name": "prod", "namespace": "fleet"},
+				map[string]interface{}{"name": "no-namespace"},
+			},
+		},
+	}
+	refs := installStrategyPlacements(cma)
+	if len(refs) != 1 || refs[0] != (types.NamespacedName{Name: "prod", Namespace: "fleet"}) {
+		t.Errorf("The placements of the install strategy are wrong: %v", refs)
+	}
+}
+
+func TestGetInstallStrategyDecisions(t *testing.T) {
+	initSchema(t)
+
+	fleetDecision := newTestPlacementDecision("prod-decision-1", "prod", "cluster2", "cluster3")
+	fleetDecision.SetNamespace("fleet")
+	c := fake.NewFakeClient(newTestPlacement(mcoNamespace, "gitops-placement"), newTestPlacement("fleet", "prod"),
+		newTestPlacementDecision("gitops-placement-decision-1", "gitops-placement", "cluster1", "cluster2"),
+		fleetDecision)
+
+	placement := &placementv1.PlacementRule{}
+	err := getInstallStrategyDecisions(c, []types.NamespacedName{
+		{Name: "gitops-placement", Namespace: mcoNamespace},
+		{Name: "prod", Namespace: "fleet"},
+		{Name: "missing", Namespace: "fleet"},
+	}, placement)
+	if err != nil {
+		t.Fatalf("Failed to get the decisions of the install strategy: (%v)", err)
+	}
+	clusters := []string{}
+	for _, decision := range placement.Status.Decisions {
+		clusters = append(clusters, decision.ClusterName)
+	}
+	if len(clusters) != 3 || clusters[0] != "cluster1" || clusters[1] != "cluster2" || clusters[2] != "cluster3" {
+		t.Errorf("The decisions of the install strategy are wrong: %v", clusters)
+	}
+
+	err = getInstallStrategyDecisions(c, []types.NamespacedName{{Name: "missing", Namespace: "fleet"}},
+		&placementv1.PlacementRule{})
+	if !k8serrors.IsNotFound(err) {
+		t.Errorf("The NotFound error should be returned if no placement exists: (%v)", err)
+	}
+
+	// the placements of the mco are used if the clustermanagementaddon does not exist
+	refs, err := getInstallStrategyPlacements(c)
+	if err != nil || refs != nil {
+		t.Errorf("The install strategy should not be set: %v (%v)", refs, err)
+	}
+	if err := initInstallStrategy(c, newTestMCO()); err != nil {
+		t.Errorf("Failed to skip the install strategy of the default placementrule: (%v)", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+// AllowlistKeys are the keys of the allowlist configmaps which are merged into the allowlist of the
+// metrics collector
+var AllowlistKeys = []string{metricsListKey, selfMetricsListKey, nodeMetricsListKey, containerMetricsListKey,
+	etcdMetricsListKey, apiserverMetricsListKey, costMetricsListKey, windowsMetricsListKey}
+
+// ValidateAllowlist validates the allowlist in the key of the allowlist configmap, the unknown fields
+// are rejected since they are silently ignored when the allowlist is merged
+func ValidateAllowlist(data string) error {
+	allowlist := &MetricsAllowlist{}
+	err := yaml.UnmarshalStrict([]byte(data), allowlist)
+	if err != nil {
+		return err
+	}
+	for from, to := range allowlist.ReNameMap {
+		if from == "" || to == "" {
+			return fmt.Errorf("the metric name to rename from and to are required: %q: %q", from, to)
+		}
+	}
+	return nil
+}
+
+// ValidateMetricsAllowlist validates the spec of the ObservabilityMetricsAllowlist, the allowlist with
+// the invalid spec is not merged
+func ValidateMetricsAllowlist(allowlist *mcov1beta2.ObservabilityMetricsAllowlist) error {
+	return validateMetricsAllowlistSpec(&allowlist.Spec)
+}
+
+// ValidateSpokeRules validates the rule files of the spoke rules configmap, the keys which are
+// skipped when the PrometheusRule of the managed cluster is generated are reported
+func ValidateSpokeRules(cm *corev1.ConfigMap) []error {
+	keys := []string{}
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	errs := []error{}
+	groups := 0
+	for _, key := range keys {
+		ruleGroups, err := parseSpokeRuleGroups(cm.Data[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid rules in %s: %v", key, err))
+			continue
+		}
+		groups += len(ruleGroups)
+	}
+	if groups == 0 {
+		errs = append(errs, fmt.Errorf("no rule groups found, the configmap is not pushed to the managed clusters"))
+	}
+	return errs
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+)
+
+// isCollectionPaused returns true if the managed cluster is in an active maintenance window which
+// pauses the metrics collection. The collection is resumed once the window ends.
+func isCollectionPaused(c client.Client, mco *mcov1beta2.MultiClusterObservability,
+	clusterName string, now time.Time) (bool, error) {
+	clusterSet := ""
+	clusterFetched := false
+	for _, w := range mco.Spec.MaintenanceWindows {
+		if !w.PauseCollection {
+			continue
+		}
+		if _, active := config.GetMaintenanceWindowEnd(w, now); !active {
+			continue
+		}
+		if len(w.ClusterSets) > 0 && !clusterFetched {
+			cluster := &clusterv1.ManagedCluster{}
+			err := c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+			if err == nil {
+				clusterSet = cluster.GetLabels()[config.ClusterSetLabelKey]
+			} else if !k8serrors.IsNotFound(err) {
+				errorlog.ClusterError(log, err, clusterName, "Failed to get managedcluster")
+				return false, err
+			}
+			clusterFetched = true
+		}
+		if config.IsClusterInMaintenanceWindow(w, clusterName, clusterSet) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
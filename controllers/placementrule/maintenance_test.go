@@ -0,0 +1,68 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestIsCollectionPaused(t *testing.T) {
+	initSchema(t)
+
+	now := time.Now()
+	cluster := &clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{
+		Name:   "prod-1",
+		Labels: map[string]string{config.ClusterSetLabelKey: "prod"},
+	}}
+	addon := &mcov1beta1.ObservabilityAddon{ObjectMeta: metav1.ObjectMeta{Name: obsAddonName, Namespace: "prod-1"}}
+	c := fake.NewFakeClient(cluster, addon)
+
+	mco := newTestMCO()
+	mco.Spec.MaintenanceWindows = []mcov1beta2.MaintenanceWindow{
+		{
+			Name:     "silence-only",
+			Clusters: []string{"prod-1"},
+			Start:    &metav1.Time{Time: now.Add(-time.Hour)},
+			End:      &metav1.Time{Time: now.Add(time.Hour)},
+		},
+		{
+			Name:            "upgrade",
+			ClusterSets:     []string{"prod"},
+			Start:           &metav1.Time{Time: now.Add(time.Hour)},
+			End:             &metav1.Time{Time: now.Add(2 * time.Hour)},
+			PauseCollection: true,
+		},
+	}
+	paused, err := isCollectionPaused(c, mco, "prod-1", now)
+	if err != nil || paused {
+		t.Errorf("The collection should not be paused before the window starts: %v (%v)", paused, err)
+	}
+	paused, err = isCollectionPaused(c, mco, "prod-1", now.Add(90*time.Minute))
+	if err != nil || !paused {
+		t.Errorf("The collection of the cluster set should be paused in the window: %v (%v)", paused, err)
+	}
+	paused, err = isCollectionPaused(c, mco, "dev-1", now.Add(90*time.Minute))
+	if err != nil || paused {
+		t.Errorf("The collection of other clusters should not be paused: %v (%v)", paused, err)
+	}
+
+	// the collection is paused in the manifestwork while the window is active
+	mco.Spec.MaintenanceWindows[1].Start = &metav1.Time{Time: now.Add(-time.Hour)}
+	found, err := getObservabilityAddon(c, "prod-1", mco)
+	if err != nil || found == nil {
+		t.Fatalf("Failed to get the observabilityaddon: (%v)", err)
+	}
+	if found.Spec.EnableMetrics {
+		t.Errorf("The metrics collection should be paused in the maintenance window")
+	}
+}
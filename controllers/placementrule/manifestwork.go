@@ -6,6 +6,7 @@ package placementrule
 import (
 	"context"
 	"errors"
+	"time"
 
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
@@ -20,19 +21,44 @@ import (
 	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
 	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
 	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/audit"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/deploying"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
 )
 
 const (
 	workNameSuffix   = "-observability"
 	localClusterName = "local-cluster"
+
+	metricsListKey          = "metrics_list.yaml"
+	selfMetricsListKey      = "self_metrics_list.yaml"
+	nodeMetricsListKey      = "node_metrics_list.yaml"
+	containerMetricsListKey = "container_metrics_list.yaml"
+	etcdMetricsListKey      = "etcd_metrics_list.yaml"
+	apiserverMetricsListKey = "apiserver_metrics_list.yaml"
+	costMetricsListKey      = "cost_metrics_list.yaml"
 )
 
 type MetricsAllowlist struct {
-	NameList  []string          `yaml:"names"`
-	MatchList []string          `yaml:"matches"`
-	ReNameMap map[string]string `yaml:"renames"`
+	NameList            []string          `yaml:"names"`
+	MatchList           []string          `yaml:"matches"`
+	ReNameMap           map[string]string `yaml:"renames"`
+	RecordingRuleList   []RecordingRule   `yaml:"recording_rules,omitempty"`
+	CollectIntervalList []CollectInterval `yaml:"collect_intervals,omitempty"`
+}
+
+// RecordingRule is evaluated by the metrics collector, the recorded metric is collected
+type RecordingRule struct {
+	Record string `yaml:"record"`
+	Expr   string `yaml:"expr"`
+}
+
+// CollectInterval is the interval in which the metrics are collected instead of the interval of the addon
+type CollectInterval struct {
+	Names    []string `yaml:"names"`
+	Interval string   `yaml:"interval"`
 }
 
 func deleteManifestWork(c client.Client, name string, namespace string) error {
@@ -45,9 +71,12 @@ func deleteManifestWork(c client.Client, name string, namespace string) error {
 	}
 	err := c.Delete(context.TODO(), addon)
 	if err != nil && !k8serrors.IsNotFound(err) {
-		log.Error(err, "Failed to delete manifestworks", "name", name, "namespace", namespace)
+		errorlog.ClusterError(log, err, namespace, "Failed to delete manifestworks", "name", name)
 		return err
 	}
+	if err == nil {
+		recordManifestWorkChange(nil, addon, audit.ActionDeleted)
+	}
 	return nil
 }
 
@@ -56,9 +85,16 @@ func deleteManifestWorks(c client.Client, namespace string) error {
 	err := c.DeleteAllOf(context.TODO(), &workv1.ManifestWork{},
 		client.InNamespace(namespace), client.MatchingLabels{ownerLabelKey: ownerLabelValue})
 	if err != nil {
-		log.Error(err, "Failed to delete observability manifestworks", "namespace", namespace)
+		errorlog.ClusterError(log, err, namespace, "Failed to delete observability manifestworks")
+		return err
 	}
-	return err
+	audit.Record(audit.Entry{
+		Kind:    audit.KindManifestWork,
+		Cluster: namespace,
+		Name:    namespace + workNameSuffix,
+		Action:  audit.ActionDeleted,
+	})
+	return nil
 }
 
 func injectIntoWork(works []workv1.Manifest, obj runtime.Object) []workv1.Manifest {
@@ -77,7 +113,8 @@ func newManifestwork(name string, namespace string) *workv1.ManifestWork {
 			Name:      name,
 			Namespace: namespace,
 			Labels: map[string]string{
-				ownerLabelKey: ownerLabelValue,
+				ownerLabelKey:          ownerLabelValue,
+				config.VersionLabelKey: config.GetComponentVersion(),
 			},
 		},
 		Spec: workv1.ManifestWorkSpec{
@@ -91,6 +128,12 @@ func newManifestwork(name string, namespace string) *workv1.ManifestWork {
 func createManifestwork(c client.Client, work *workv1.ManifestWork) error {
 	name := work.ObjectMeta.Name
 	namespace := work.ObjectMeta.Namespace
+	// the hash of the desired manifestwork tells the external modifications from the changes of the desired state
+	hash := deploying.HashDesired([]interface{}{work.Spec, work.GetAnnotations()})
+	if work.Annotations == nil {
+		work.Annotations = map[string]string{}
+	}
+	work.Annotations[config.DesiredHashAnnotation] = hash
 	found := &workv1.ManifestWork{}
 	err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, found)
 	if err != nil && k8serrors.IsNotFound(err) {
@@ -98,9 +141,10 @@ func createManifestwork(c client.Client, work *workv1.ManifestWork) error {
 
 		err = c.Create(context.TODO(), work)
 		if err != nil {
-			log.Error(err, "Failed to create manifestwork", "namespace", namespace, "name", name)
+			errorlog.ClusterError(log, err, namespace, "Failed to create manifestwork", "name", name)
 			return err
 		}
+		recordManifestWorkChange(nil, work, audit.ActionCreated)
 		return nil
 	} else if err != nil {
 		log.Error(err, "Failed to check manifestwork", namespace, "name", name)
@@ -124,15 +168,34 @@ func createManifestwork(c client.Client, work *workv1.ManifestWork) error {
 	} else {
 		updated = true
 	}
+	endpoint := work.GetAnnotations()[config.ObsAPIEndpointAnnotation]
+	if found.GetAnnotations()[config.ObsAPIEndpointAnnotation] != endpoint ||
+		found.GetAnnotations()[config.ProxyEndpointAnnotation] != work.GetAnnotations()[config.ProxyEndpointAnnotation] {
+		updated = true
+	}
+	// the compare of the manifests skips some fields which the spoke patches can change
+	if found.GetAnnotations()[config.SpokePatchesHashAnnotation] != work.GetAnnotations()[config.SpokePatchesHashAnnotation] {
+		updated = true
+	}
 
 	if updated {
 		log.Info("Updating manifestwork", namespace, namespace, "name", name)
+		if found.GetAnnotations()[config.DesiredHashAnnotation] == hash {
+			deploying.RecordDrift(&corev1.ObjectReference{
+				APIVersion: workv1.GroupVersion.String(),
+				Kind:       "ManifestWork",
+				Namespace:  namespace,
+				Name:       name,
+				UID:        found.GetUID(),
+			})
+		}
 		work.ObjectMeta.ResourceVersion = found.ObjectMeta.ResourceVersion
 		err = c.Update(context.TODO(), work)
 		if err != nil {
 			log.Error(err, "Failed to update monitoring-endpoint-monitoring-work work")
 			return err
 		}
+		recordManifestWorkChange(found, work, audit.ActionUpdated)
 		return nil
 	}
 
@@ -157,12 +220,39 @@ func createManifestWorks(c client.Client, restMapper meta.RESTMapper,
 		manifests = injectIntoWork(manifests, obaddon)
 	}
 
+	// inject the priority class of the addon before the deployments which refer to it
+	if priorityClass := config.NewAddonPriorityClass(mco); priorityClass != nil {
+		manifests = injectIntoWork(manifests, priorityClass)
+	}
+
 	// inject resouces in templates
-	templates, err := loadTemplates(clusterNamespace, mco)
+	overlay, err := getEndpointOverlay(c, clusterName)
+	if err != nil {
+		return err
+	}
+	patches, err := getEndpointOverlayPatches(c, overlay)
+	if err != nil {
+		return err
+	}
+	templates, err := loadTemplates(clusterNamespace, overlay, patches, mco)
+	if err != nil && len(patches) > 0 {
+		// the invalid patches do not block the updates of the endpoint manifests
+		log.Error(err, "Failed to apply the endpoint overlay patches, skip them", "overlay", overlay)
+		templates, err = loadTemplates(clusterNamespace, overlay, nil, mco)
+	}
 	if err != nil {
 		log.Error(err, "Failed to load templates")
 		return err
 	}
+	versions, err := getClusterVersions(c, clusterName)
+	if err != nil {
+		return err
+	}
+	templates, err = adaptTemplates(templates, versions)
+	if err != nil {
+		log.Error(err, "Failed to adapt the templates to the version of the cluster")
+		return err
+	}
 	for _, raw := range templates {
 		if clusterName == localClusterName &&
 			raw.Object == nil {
@@ -180,6 +270,17 @@ func createManifestWorks(c client.Client, restMapper meta.RESTMapper,
 		return err
 	}
 	manifests = injectIntoWork(manifests, hubInfo)
+	if !config.IsExternalMetricsStoreEnabled(mco) {
+		// record the endpoint of the hub info to track the change of the endpoint
+		endpoint, err := config.GetObsAPIEndpoint(c, config.GetDefaultNamespace())
+		if err != nil {
+			return err
+		}
+		work.Annotations = map[string]string{config.ObsAPIEndpointAnnotation: endpoint}
+		if proxyEndpoint := hubInfo.GetAnnotations()[config.ProxyEndpointAnnotation]; proxyEndpoint != "" {
+			work.Annotations[config.ProxyEndpointAnnotation] = proxyEndpoint
+		}
+	}
 
 	// inject namespace
 	manifests = injectIntoWork(manifests, createNameSpace())
@@ -195,15 +296,118 @@ func createManifestWorks(c client.Client, restMapper meta.RESTMapper,
 	if err != nil {
 		return err
 	}
+	// roll out the renewed server CA at the configured rate
+	certs.Data["ca.crt"], err = throttleCARollout(c, clusterNamespace, certs.Data["ca.crt"], mco, time.Now())
+	if err != nil {
+		return err
+	}
 	manifests = injectIntoWork(manifests, certs)
 
+	// inject the token of the /federate endpoint in pull mode
+	if isPullMode(mco) {
+		token, err := getFederateToken(c, clusterNamespace)
+		if err != nil {
+			return err
+		}
+		manifests = injectIntoWork(manifests, newSpokeFederateToken(token))
+	}
+
+	// inject the credentials of the external metrics store
+	storeCredentials, err := config.GetExternalMetricsStoreCredentials(c, mco)
+	if err != nil {
+		log.Error(err, "Failed to get the credentials of the external metrics store")
+		return err
+	}
+	if storeCredentials != nil {
+		manifests = injectIntoWork(manifests, newSpokeExternalMetricsStoreSecret(storeCredentials))
+	}
+
+	// inject the client certificates of etcd and kubelet for the platform, and the scrape config
+	// of the targets which the collector scrapes directly
+	scrapeCredentials, scrapeConfigs, err := getScrapeCredentials(c, clusterName)
+	if err != nil {
+		return err
+	}
+	for _, secret := range scrapeCredentials {
+		manifests = injectIntoWork(manifests, secret)
+	}
+	windowsEnabled, err := isWindowsMetricsEnabled(c, clusterName)
+	if err != nil {
+		return err
+	}
+	if windowsEnabled {
+		scrapeConfigs = append(scrapeConfigs, newWindowsScrapeConfigs()...)
+	}
+	if overlay == endpointOverlayKubernetes {
+		scrapeConfigs = append(scrapeConfigs, newEndpointOperatorScrapeConfigs()...)
+	}
+	if len(scrapeConfigs) > 0 {
+		scrapeConfig, err := newScrapeConfigCM(scrapeConfigs)
+		if err != nil {
+			return err
+		}
+		manifests = injectIntoWork(manifests, scrapeConfig)
+	}
+
 	// inject the metrics allowlist configmap
-	mList, err := getMetricsListCM(c)
+	mList, err := getMetricsListCM(c, clusterName, mco.Spec.ObservabilityAddonSpec)
 	if err != nil {
 		return err
 	}
 	manifests = injectIntoWork(manifests, mList)
 
+	// inject the one-shot job which backfills the history of the freshly onboarded cluster
+	backfill, err := getBackfillManifests(c, work.Name, clusterNamespace, clusterName, mco, mList)
+	if err != nil {
+		return err
+	}
+	manifests = append(manifests, backfill...)
+
+	// inject the kube-state-metrics custom resource state configmap
+	ksmCRConfig, err := getKubeStateMetricsCustomResourceCM(c)
+	if err != nil {
+		return err
+	}
+	if ksmCRConfig != nil {
+		manifests = injectIntoWork(manifests, ksmCRConfig)
+	}
+
+	// inject the filter configmap of the events forwarder
+	eventsFilter, err := getEventsFilterCM(mco)
+	if err != nil {
+		return err
+	}
+	if eventsFilter != nil {
+		manifests = injectIntoWork(manifests, eventsFilter)
+	}
+
+	// inject the alert rules which are defined on the hub for the cluster
+	spokeRules, err := getSpokeRules(c, clusterName)
+	if err != nil {
+		return err
+	}
+	for _, rule := range spokeRules {
+		manifests = injectIntoWork(manifests, rule)
+	}
+
+	// inject the watchdog alert of the alerting self test
+	if mco.Spec.EnableAlertingSelfTest {
+		manifests = injectIntoWork(manifests, newWatchdogRule())
+	}
+
+	// apply the user-supplied patches to the manifests
+	spokePatches, patchesHash, err := getSpokePatches(c)
+	if err != nil {
+		return err
+	}
+	if patchesHash != "" {
+		manifests = applySpokePatches(manifests, spokePatches)
+		if work.Annotations == nil {
+			work.Annotations = map[string]string{}
+		}
+		work.Annotations[config.SpokePatchesHashAnnotation] = patchesHash
+	}
+
 	work.Spec.Workload.Manifests = manifests
 
 	err = createManifestwork(c, work)
@@ -237,6 +441,28 @@ func getCerts(client client.Client, namespace string) (*corev1.Secret, error) {
 		log.Error(err, "Failed to get ca cert secret", "name", caName)
 		return nil, err
 	}
+	caBundle := ca.Data["tls.crt"]
+	// the server certificate is signed by the intermediate CA if it exists, which may be issued by
+	// another root, e.g. of the enterprise PKI
+	intermediate := &corev1.Secret{}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: config.ServerIntermediateCACerts,
+		Namespace: config.GetDefaultNamespace()}, intermediate)
+	if err == nil {
+		caBundle = append(append([]byte{}, caBundle...), intermediate.Data["ca.crt"]...)
+	} else if !k8serrors.IsNotFound(err) {
+		log.Error(err, "Failed to get the intermediate ca secret", "name", config.ServerIntermediateCACerts)
+		return nil, err
+	}
+	// the route of the observatorium api may be served by the certificate of the ingress controller
+	// instead, e.g. once it is changed to the edge termination
+	ingressCA, err := config.GetObsAPIIngressCA(client, config.GetDefaultNamespace())
+	if err != nil {
+		log.Error(err, "Failed to get the ca of the observatorium api route")
+		return nil, err
+	}
+	if len(ingressCA) > 0 {
+		caBundle = append(append([]byte{}, caBundle...), ingressCA...)
+	}
 
 	return &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{
@@ -248,12 +474,13 @@ func getCerts(client client.Client, namespace string) (*corev1.Secret, error) {
 			Namespace: spokeNameSpace,
 		},
 		Data: map[string][]byte{
-			"ca.crt": ca.Data["tls.crt"],
+			"ca.crt": caBundle,
 		},
 	}, nil
 }
 
-func getMetricsListCM(client client.Client) (*corev1.ConfigMap, error) {
+func getMetricsListCM(client client.Client, clusterName string,
+	addonSpec *mcoshared.ObservabilityAddonSpec) (*corev1.ConfigMap, error) {
 	metricsAllowlist := &corev1.ConfigMap{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: corev1.SchemeGroupVersion.String(),
@@ -266,33 +493,144 @@ func getMetricsListCM(client client.Client) (*corev1.ConfigMap, error) {
 		Data: map[string]string{},
 	}
 
-	allowlist, err := getAllowList(client, config.AllowlistConfigMapName)
+	allowlist, err := getAllowList(client, config.AllowlistConfigMapName, metricsListKey)
 	if err != nil {
 		log.Error(err, "Failed to get metrics allowlist configmap "+config.AllowlistConfigMapName)
 		return nil, err
 	}
 
-	customAllowlist, err := getAllowList(client, config.AllowlistCustomConfigMapName)
-	if err == nil {
-		allowlist.NameList = append(allowlist.NameList, customAllowlist.NameList...)
-		allowlist.MatchList = append(allowlist.MatchList, customAllowlist.MatchList...)
-		for k, v := range customAllowlist.ReNameMap {
-			allowlist.ReNameMap[k] = v
+	// the self-metrics of the collector and endpoint operator are always forwarded,
+	// so that the hub can alert on the collectors which fail to scrape or forward
+	selfAllowlist, err := getAllowList(client, config.AllowlistConfigMapName, selfMetricsListKey)
+	if err != nil {
+		log.Error(err, "Failed to get self-metrics from configmap "+config.AllowlistConfigMapName)
+		return nil, err
+	}
+	mergeAllowlist(allowlist, selfAllowlist)
+
+	// merge the curated metrics bundles which are enabled in the addon spec
+	for _, key := range getEnabledMetricsListKeys(addonSpec) {
+		bundle, err := getAllowList(client, config.AllowlistConfigMapName, key)
+		if err != nil {
+			log.Error(err, "Failed to get metrics bundle from configmap "+config.AllowlistConfigMapName, "key", key)
+			return nil, err
 		}
-	} else {
-		log.Info("There is no custom metrics allowlist configmap in the cluster")
+		mergeAllowlist(allowlist, bundle)
+	}
+
+	// merge the windows bundle for the cluster with the windows worker nodes
+	windowsEnabled, err := isWindowsMetricsEnabled(client, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if windowsEnabled {
+		bundle, err := getAllowList(client, config.AllowlistConfigMapName, windowsMetricsListKey)
+		if err != nil {
+			log.Error(err, "Failed to get the windows metrics bundle from configmap "+config.AllowlistConfigMapName)
+			return nil, err
+		}
+		mergeAllowlist(allowlist, bundle)
+	}
+
+	customAllowlist, err := getCustomAllowlist(client)
+	if err != nil {
+		return nil, err
 	}
+	mergeAllowlist(allowlist, customAllowlist)
+
+	// stop forwarding the high-cardinality metrics which are throttled in the cluster
+	throttled, err := getThrottledMetrics(client, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	removeThrottledMetrics(allowlist, throttled)
 
 	data, err := yaml.Marshal(allowlist)
 	if err != nil {
 		log.Error(err, "Failed to marshal allowlist data")
 		return nil, err
 	}
-	metricsAllowlist.Data["metrics_list.yaml"] = string(data)
+	metricsAllowlist.Data[metricsListKey] = string(data)
 	return metricsAllowlist, nil
 }
 
-func getAllowList(client client.Client, name string) (*MetricsAllowlist, error) {
+// getEnabledMetricsListKeys returns the keys of the curated metrics bundles
+// in the allowlist configmap which are enabled by the toggles in addon spec
+func getEnabledMetricsListKeys(addonSpec *mcoshared.ObservabilityAddonSpec) []string {
+	if addonSpec == nil {
+		return []string{nodeMetricsListKey, containerMetricsListKey, etcdMetricsListKey, apiserverMetricsListKey}
+	}
+	keys := []string{}
+	if addonSpec.CollectNodeMetrics {
+		keys = append(keys, nodeMetricsListKey)
+	}
+	if addonSpec.CollectContainerMetrics {
+		keys = append(keys, containerMetricsListKey)
+	}
+	if addonSpec.CollectEtcdMetrics {
+		keys = append(keys, etcdMetricsListKey)
+	}
+	if addonSpec.CollectApiserverMetrics {
+		keys = append(keys, apiserverMetricsListKey)
+	}
+	if addonSpec.CollectCostMetrics {
+		keys = append(keys, costMetricsListKey)
+	}
+	return keys
+}
+
+func mergeAllowlist(allowlist, other *MetricsAllowlist) {
+	allowlist.NameList = append(allowlist.NameList, other.NameList...)
+	allowlist.MatchList = append(allowlist.MatchList, other.MatchList...)
+	if len(other.ReNameMap) != 0 && allowlist.ReNameMap == nil {
+		allowlist.ReNameMap = map[string]string{}
+	}
+	for k, v := range other.ReNameMap {
+		allowlist.ReNameMap[k] = v
+	}
+	allowlist.RecordingRuleList = append(allowlist.RecordingRuleList, other.RecordingRuleList...)
+	allowlist.CollectIntervalList = append(allowlist.CollectIntervalList, other.CollectIntervalList...)
+}
+
+// getKubeStateMetricsCustomResourceCM returns the custom resource state configuration
+// for kube-state-metrics on the managed clusters, or nil if it is not defined in the hub
+func getKubeStateMetricsCustomResourceCM(client client.Client) (*corev1.ConfigMap, error) {
+	found := &corev1.ConfigMap{}
+	namespacedName := types.NamespacedName{
+		Name:      config.KubeStateMetricsCustomResourceConfigMapName,
+		Namespace: config.GetDefaultNamespace(),
+	}
+	err := client.Get(context.TODO(), namespacedName, found)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		log.Error(err, "Failed to get configmap "+config.KubeStateMetricsCustomResourceConfigMapName)
+		return nil, err
+	}
+	if _, ok := found.Data[config.KubeStateMetricsCustomResourceFileKey]; !ok {
+		log.Info("No custom resource state configuration found in configmap",
+			"name", config.KubeStateMetricsCustomResourceConfigMapName,
+			"key", config.KubeStateMetricsCustomResourceFileKey)
+		return nil, nil
+	}
+
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.KubeStateMetricsCustomResourceConfigMapName,
+			Namespace: spokeNameSpace,
+		},
+		Data: map[string]string{
+			config.KubeStateMetricsCustomResourceFileKey: found.Data[config.KubeStateMetricsCustomResourceFileKey],
+		},
+	}, nil
+}
+
+func getAllowList(client client.Client, name string, key string) (*MetricsAllowlist, error) {
 	found := &corev1.ConfigMap{}
 	namespacedName := types.NamespacedName{
 		Name:      name,
@@ -303,7 +641,7 @@ func getAllowList(client client.Client, name string) (*MetricsAllowlist, error)
 		return nil, err
 	}
 	allowlist := &MetricsAllowlist{}
-	err = yaml.Unmarshal([]byte(found.Data["metrics_list.yaml"]), allowlist)
+	err = yaml.Unmarshal([]byte(found.Data[key]), allowlist)
 	if err != nil {
 		log.Error(err, "Failed to unmarshal data in configmap "+name)
 		return nil, err
@@ -331,10 +669,24 @@ func getObservabilityAddon(c client.Client, namespace string,
 	}
 	if mco.Spec.ObservabilityAddonSpec == nil {
 		mco.Spec.ObservabilityAddonSpec = &mcoshared.ObservabilityAddonSpec{
-			EnableMetrics: true,
-			Interval:      30,
+			EnableMetrics:           true,
+			Interval:                30,
+			CollectNodeMetrics:      true,
+			CollectContainerMetrics: true,
+			CollectEtcdMetrics:      true,
+			CollectApiserverMetrics: true,
 		}
 	}
+	// the metrics collection is paused while the managed cluster is in a maintenance window
+	paused, err := isCollectionPaused(c, mco, namespace, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	sharding := mco.Spec.ObservabilityAddonSpec.CollectorSharding
+	if found.Spec.CollectorSharding != nil {
+		// the sharding configured for the managed cluster overrides the global one
+		sharding = found.Spec.CollectorSharding
+	}
 	return &mcov1beta1.ObservabilityAddon{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "observability.open-cluster-management.io/v1beta1",
@@ -345,8 +697,13 @@ func getObservabilityAddon(c client.Client, namespace string,
 			Namespace: spokeNameSpace,
 		},
 		Spec: mcoshared.ObservabilityAddonSpec{
-			EnableMetrics: mco.Spec.ObservabilityAddonSpec.EnableMetrics,
-			Interval:      mco.Spec.ObservabilityAddonSpec.Interval,
+			EnableMetrics:          mco.Spec.ObservabilityAddonSpec.EnableMetrics && !paused,
+			Interval:               mco.Spec.ObservabilityAddonSpec.Interval,
+			ServiceMonitorSelector: mco.Spec.ObservabilityAddonSpec.ServiceMonitorSelector.DeepCopy(),
+			PodMonitorSelector:     mco.Spec.ObservabilityAddonSpec.PodMonitorSelector.DeepCopy(),
+			CollectorSharding:      sharding.DeepCopy(),
+			CollectionMode:         mco.Spec.ObservabilityAddonSpec.CollectionMode,
+			NamespaceFilter:        getNamespaceFilter(mco, found).DeepCopy(),
 		},
 	}, nil
 }
@@ -359,7 +716,7 @@ func removeObservabilityAddon(client client.Client, namespace string) error {
 		if k8serrors.IsNotFound(err) {
 			return nil
 		}
-		log.Error(err, "Failed to check manifestwork", "namespace", namespace, "name", name)
+		errorlog.ClusterError(log, err, namespace, "Failed to check manifestwork", "name", name)
 		return err
 	}
 
@@ -373,7 +730,7 @@ func removeObservabilityAddon(client client.Client, namespace string) error {
 
 		err = client.Update(context.TODO(), found)
 		if err != nil {
-			log.Error(err, "Failed to update manifestwork", "namespace", namespace, "name", name)
+			errorlog.ClusterError(log, err, namespace, "Failed to update manifestwork", "name", name)
 			return err
 		}
 	}
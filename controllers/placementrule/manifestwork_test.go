@@ -7,8 +7,10 @@ import (
 	"context"
 	"os"
 	"path"
+	"reflect"
 	"testing"
 
+	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,13 +20,14 @@ import (
 
 	workv1 "github.com/open-cluster-management/api/work/v1"
 	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
 	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
 )
 
 const (
 	pullSecretName = "test-pull-secret"
-	workSize       = 11
+	workSize       = 12
 )
 
 func newTestMCO() *mcov1beta2.MultiClusterObservability {
@@ -89,12 +92,31 @@ func NewMetricsAllowListCM() *corev1.ConfigMap {
 			Name:      config.AllowlistConfigMapName,
 			Namespace: mcoNamespace,
 		},
-		Data: map[string]string{"metrics_list.yaml": `
+		Data: map[string]string{
+			"metrics_list.yaml": `
   names:
     - a
     - b
   renames:
     a: c
+`,
+			"self_metrics_list.yaml": `
+  names:
+    - self_a
+`,
+			"node_metrics_list.yaml": `
+  names:
+    - node_a
+`,
+			"etcd_metrics_list.yaml": `
+  names:
+    - etcd_a
+  matches:
+    - __name__="etcd_b",job="etcd"
+`,
+			"cost_metrics_list.yaml": `
+  names:
+    - cost_a
 `},
 	}
 }
@@ -168,3 +190,176 @@ func TestManifestWork(t *testing.T) {
 		t.Fatalf("Manifestwork not deleted: (%v)", err)
 	}
 }
+
+func TestGetObservabilityAddon(t *testing.T) {
+	initSchema(t)
+
+	addon := &mcov1beta1.ObservabilityAddon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      obsAddonName,
+			Namespace: namespace,
+		},
+	}
+	c := fake.NewFakeClient(addon)
+
+	mco := newTestMCO()
+	mco.Spec.ObservabilityAddonSpec.ServiceMonitorSelector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{"observability": "enabled"},
+	}
+	found, err := getObservabilityAddon(c, namespace, mco)
+	if err != nil {
+		t.Fatalf("Failed to get observabilityaddon: (%v)", err)
+	}
+	if found == nil || found.Spec.ServiceMonitorSelector == nil ||
+		found.Spec.ServiceMonitorSelector.MatchLabels["observability"] != "enabled" {
+		t.Fatalf("ServiceMonitorSelector is not propagated to the observabilityaddon: %v", found)
+	}
+	if found.Spec.PodMonitorSelector != nil {
+		t.Fatalf("PodMonitorSelector should not be set: %v", found.Spec.PodMonitorSelector)
+	}
+}
+
+func TestGetObservabilityAddonWithSharding(t *testing.T) {
+	initSchema(t)
+
+	addon := &mcov1beta1.ObservabilityAddon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      obsAddonName,
+			Namespace: namespace,
+		},
+	}
+	c := fake.NewFakeClient(addon)
+
+	mco := newTestMCO()
+	mco.Spec.ObservabilityAddonSpec.CollectorSharding = &mcoshared.CollectorShardingSpec{
+		Shards: 2,
+		Key:    "target",
+	}
+	found, err := getObservabilityAddon(c, namespace, mco)
+	if err != nil {
+		t.Fatalf("Failed to get observabilityaddon: (%v)", err)
+	}
+	if found.Spec.CollectorSharding == nil || found.Spec.CollectorSharding.Shards != 2 {
+		t.Fatalf("Global collector sharding is not propagated to the observabilityaddon: %v", found.Spec)
+	}
+
+	// the sharding of the managed cluster overrides the global one
+	addon.Spec.CollectorSharding = &mcoshared.CollectorShardingSpec{
+		Shards: 4,
+		Key:    "metric",
+	}
+	c = fake.NewFakeClient(addon)
+	found, err = getObservabilityAddon(c, namespace, mco)
+	if err != nil {
+		t.Fatalf("Failed to get observabilityaddon: (%v)", err)
+	}
+	if found.Spec.CollectorSharding == nil || found.Spec.CollectorSharding.Shards != 4 ||
+		found.Spec.CollectorSharding.Key != "metric" {
+		t.Fatalf("Cluster collector sharding does not override the global one: %v", found.Spec)
+	}
+}
+
+func TestGetKubeStateMetricsCustomResourceCM(t *testing.T) {
+	initSchema(t)
+
+	c := fake.NewFakeClient()
+	cm, err := getKubeStateMetricsCustomResourceCM(c)
+	if err != nil {
+		t.Fatalf("Failed to get kube-state-metrics custom resource configmap: (%v)", err)
+	}
+	if cm != nil {
+		t.Fatalf("Configmap should be nil when it is not defined in the hub")
+	}
+
+	content := `
+kind: CustomResourceStateMetrics
+spec:
+  resources:
+    - groupVersionKind:
+        group: observability.open-cluster-management.io
+        version: v1beta1
+        kind: ObservabilityAddon
+`
+	c = fake.NewFakeClient(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.KubeStateMetricsCustomResourceConfigMapName,
+			Namespace: mcoNamespace,
+		},
+		Data: map[string]string{config.KubeStateMetricsCustomResourceFileKey: content},
+	})
+	cm, err = getKubeStateMetricsCustomResourceCM(c)
+	if err != nil {
+		t.Fatalf("Failed to get kube-state-metrics custom resource configmap: (%v)", err)
+	}
+	if cm == nil || cm.Namespace != spokeNameSpace ||
+		cm.Data[config.KubeStateMetricsCustomResourceFileKey] != content {
+		t.Fatalf("Wrong kube-state-metrics custom resource configmap: %v", cm)
+	}
+}
+
+func TestGetMetricsListCM(t *testing.T) {
+	initSchema(t)
+
+	c := fake.NewFakeClient(NewMetricsAllowListCM(), NewMetricsCustomAllowListCM())
+
+	cases := []struct {
+		name      string
+		addonSpec *mcoshared.ObservabilityAddonSpec
+		expected  []string
+		matches   int
+	}{
+		{
+			name:      "all bundles enabled by default",
+			addonSpec: nil,
+			expected:  []string{"a", "b", "self_a", "node_a", "etcd_a", "c", "d"},
+			matches:   1,
+		},
+		{
+			name: "etcd bundle disabled",
+			addonSpec: &mcoshared.ObservabilityAddonSpec{
+				CollectNodeMetrics:      true,
+				CollectContainerMetrics: true,
+				CollectApiserverMetrics: true,
+			},
+			expected: []string{"a", "b", "self_a", "node_a", "c", "d"},
+			matches:  0,
+		},
+		{
+			name:      "self-metrics forwarded with all bundles disabled",
+			addonSpec: &mcoshared.ObservabilityAddonSpec{},
+			expected:  []string{"a", "b", "self_a", "c", "d"},
+			matches:   0,
+		},
+		{
+			name: "cost bundle enabled",
+			addonSpec: &mcoshared.ObservabilityAddonSpec{
+				CollectCostMetrics: true,
+			},
+			expected: []string{"a", "b", "self_a", "cost_a", "c", "d"},
+			matches:  0,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cm, err := getMetricsListCM(c, clusterName, tc.addonSpec)
+			if err != nil {
+				t.Fatalf("Failed to get metrics allowlist configmap: (%v)", err)
+			}
+			allowlist := &MetricsAllowlist{}
+			err = yaml.Unmarshal([]byte(cm.Data[metricsListKey]), allowlist)
+			if err != nil {
+				t.Fatalf("Failed to unmarshal allowlist: (%v)", err)
+			}
+			if !reflect.DeepEqual(allowlist.NameList, tc.expected) {
+				t.Fatalf("Wrong metrics names: expected %v, got %v", tc.expected, allowlist.NameList)
+			}
+			if len(allowlist.MatchList) != tc.matches {
+				t.Fatalf("Wrong metrics matches: %v", allowlist.MatchList)
+			}
+			if allowlist.ReNameMap["a"] != "c" || allowlist.ReNameMap["d"] != "e" {
+				t.Fatalf("Wrong metrics renames: %v", allowlist.ReNameMap)
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	// the annotation of the ObservabilityMetricsAllowlist which is converted from the legacy custom
+	// allowlist configmap, it is kept in sync with the configmap until the configmap is deleted
+	allowlistConvertedAnnotation = "observability.open-cluster-management.io/converted-from-configmap"
+)
+
+// getCustomAllowlist returns the legacy custom allowlist configmap merged with the
+// ObservabilityMetricsAllowlists, the allowlists with the invalid spec are skipped. The allowlist
+// which is converted from the configmap is skipped while the configmap exists.
+func getCustomAllowlist(c client.Client) (*MetricsAllowlist, error) {
+	allowlist := &MetricsAllowlist{}
+	cm, err := getCustomAllowlistCM(c)
+	if err != nil {
+		return nil, err
+	}
+	if cm != nil {
+		legacy, err := unmarshalCustomAllowlist(cm)
+		if err != nil {
+			log.Error(err, "Failed to unmarshal data in configmap "+config.AllowlistCustomConfigMapName)
+		} else {
+			mergeAllowlist(allowlist, legacy)
+		}
+	}
+
+	allowlists, err := listMetricsAllowlists(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range allowlists {
+		if cm != nil && item.Annotations[allowlistConvertedAnnotation] != "" {
+			continue
+		}
+		if validateMetricsAllowlistSpec(&item.Spec) != nil {
+			continue
+		}
+		mergeAllowlist(allowlist, newMetricsAllowlist(&item.Spec))
+	}
+	return allowlist, nil
+}
+
+func getCustomAllowlistCM(c client.Client) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      config.AllowlistCustomConfigMapName,
+		Namespace: config.GetDefaultNamespace(),
+	}, cm)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		log.Error(err, "Failed to get configmap "+config.AllowlistCustomConfigMapName)
+		return nil, err
+	}
+	return cm, nil
+}
+
+// unmarshalCustomAllowlist merges the allowlists in the keys of the legacy custom allowlist configmap. The
+// metrics_list.yaml key is always read, so the custom allowlists which were merged into it before the
+// curated bundles were split out of the default allowlist keep working, and the custom allowlists can
+// use the keys of the bundles as well.
+func unmarshalCustomAllowlist(cm *corev1.ConfigMap) (*MetricsAllowlist, error) {
+	allowlist := &MetricsAllowlist{}
+	for _, key := range AllowlistKeys {
+		data, ok := cm.Data[key]
+		if !ok {
+			continue
+		}
+		other := &MetricsAllowlist{}
+		err := yaml.Unmarshal([]byte(data), other)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal the key %s: %v", key, err)
+		}
+		mergeAllowlist(allowlist, other)
+	}
+	return allowlist, nil
+}
+
+// listMetricsAllowlists returns the ObservabilityMetricsAllowlists sorted by name, so that the merged
+// allowlist is stable
+func listMetricsAllowlists(c client.Client) ([]mcov1beta2.ObservabilityMetricsAllowlist, error) {
+	list := &mcov1beta2.ObservabilityMetricsAllowlistList{}
+	err := c.List(context.TODO(), list)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		log.Error(err, "Failed to list the ObservabilityMetricsAllowlists")
+		return nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+	return list.Items, nil
+}
+
+// newMetricsAllowlist returns the allowlist of the metrics collector for the spec
+func newMetricsAllowlist(spec *mcov1beta2.ObservabilityMetricsAllowlistSpec) *MetricsAllowlist {
+	allowlist := &MetricsAllowlist{
+		NameList:  metricNames(spec.Names),
+		MatchList: append([]string{}, spec.Matches...),
+	}
+	if len(spec.Renames) > 0 {
+		allowlist.ReNameMap = map[string]string{}
+		for _, rename := range spec.Renames {
+			allowlist.ReNameMap[string(rename.From)] = string(rename.To)
+		}
+	}
+	for _, rule := range spec.RecordingRules {
+		allowlist.RecordingRuleList = append(allowlist.RecordingRuleList,
+			RecordingRule{Record: string(rule.Record), Expr: rule.Expr})
+	}
+	for _, interval := range spec.CollectIntervals {
+		allowlist.CollectIntervalList = append(allowlist.CollectIntervalList,
+			CollectInterval{Names: metricNames(interval.Names), Interval: interval.Interval})
+	}
+	return allowlist
+}
+
+// newMetricsAllowlistSpec converts the legacy allowlist of the configmap into the spec
+func newMetricsAllowlistSpec(allowlist *MetricsAllowlist) mcov1beta2.ObservabilityMetricsAllowlistSpec {
+	spec := mcov1beta2.ObservabilityMetricsAllowlistSpec{}
+	for _, name := range allowlist.NameList {
+		spec.Names = append(spec.Names, mcov1beta2.MetricName(name))
+	}
+	spec.Matches = append(spec.Matches, allowlist.MatchList...)
+	from := []string{}
+	for name := range allowlist.ReNameMap {
+		from = append(from, name)
+	}
+	sort.Strings(from)
+	for _, name := range from {
+		spec.Renames = append(spec.Renames, mcov1beta2.MetricRename{
+			From: mcov1beta2.MetricName(name),
+			To:   mcov1beta2.MetricName(allowlist.ReNameMap[name]),
+		})
+	}
+	for _, rule := range allowlist.RecordingRuleList {
+		spec.RecordingRules = append(spec.RecordingRules, mcov1beta2.MetricsRecordingRule{
+			Record: mcov1beta2.MetricName(rule.Record),
+			Expr:   rule.Expr,
+		})
+	}
+	for _, interval := range allowlist.CollectIntervalList {
+		names := []mcov1beta2.MetricName{}
+		for _, name := range interval.Names {
+			names = append(names, mcov1beta2.MetricName(name))
+		}
+		spec.CollectIntervals = append(spec.CollectIntervals, mcov1beta2.MetricsCollectInterval{
+			Names:    names,
+			Interval: interval.Interval,
+		})
+	}
+	return spec
+}
+
+func metricNames(names []mcov1beta2.MetricName) []string {
+	result := []string{}
+	for _, name := range names {
+		result = append(result, string(name))
+	}
+	return result
+}
+
+// validateMetricsAllowlistSpec validates the spec beyond the openapi schema of the CRD
+func validateMetricsAllowlistSpec(spec *mcov1beta2.ObservabilityMetricsAllowlistSpec) error {
+	for _, match := range spec.Matches {
+		if !isMatchExpression(match) {
+			return fmt.Errorf("%s is not a series selector, e.g. __name__=\"etcd_b\",job=\"etcd\"", match)
+		}
+	}
+	renamed := map[mcov1beta2.MetricName]bool{}
+	for _, rename := range spec.Renames {
+		if rename.From == rename.To {
+			return fmt.Errorf("the metric %s is renamed to itself", rename.From)
+		}
+		if renamed[rename.From] {
+			return fmt.Errorf("the metric %s is renamed more than once", rename.From)
+		}
+		renamed[rename.From] = true
+	}
+	records := map[mcov1beta2.MetricName]bool{}
+	for _, rule := range spec.RecordingRules {
+		if rule.Expr == "" {
+			return fmt.Errorf("the expr of the recording rule %s is required", rule.Record)
+		}
+		if records[rule.Record] {
+			return fmt.Errorf("the metric %s is recorded more than once", rule.Record)
+		}
+		records[rule.Record] = true
+	}
+	intervals := map[mcov1beta2.MetricName]bool{}
+	for _, interval := range spec.CollectIntervals {
+		duration, err := time.ParseDuration(interval.Interval)
+		if err != nil || duration <= 0 {
+			return fmt.Errorf("invalid collect interval %s", interval.Interval)
+		}
+		for _, name := range interval.Names {
+			if intervals[name] {
+				return fmt.Errorf("the collect interval of the metric %s is set more than once", name)
+			}
+			intervals[name] = true
+		}
+	}
+	return nil
+}
+
+// convertCustomAllowlist converts the legacy custom allowlist configmap into the ObservabilityMetricsAllowlist
+// of the same name, and keeps it in sync while the configmap exists. Once the configmap is deleted, the
+// converted allowlist is released to be managed directly.
+func convertCustomAllowlist(c client.Client) error {
+	cm, err := getCustomAllowlistCM(c)
+	if err != nil {
+		return err
+	}
+	found := &mcov1beta2.ObservabilityMetricsAllowlist{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: config.AllowlistCustomConfigMapName}, found)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		found = nil
+	}
+
+	if cm == nil {
+		if found == nil || found.Annotations[allowlistConvertedAnnotation] == "" {
+			return nil
+		}
+		log.Info("The custom metrics allowlist configmap is deleted, release the converted allowlist",
+			"name", found.Name)
+		delete(found.Annotations, allowlistConvertedAnnotation)
+		return c.Update(context.TODO(), found)
+	}
+
+	legacy, err := unmarshalCustomAllowlist(cm)
+	if err != nil {
+		// the broken configmap is skipped when the allowlist is merged
+		return nil
+	}
+	spec := newMetricsAllowlistSpec(legacy)
+	if found == nil {
+		log.Info("Converting the custom metrics allowlist configmap", "name", config.AllowlistCustomConfigMapName)
+		return c.Create(context.TODO(), &mcov1beta2.ObservabilityMetricsAllowlist{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        config.AllowlistCustomConfigMapName,
+				Annotations: map[string]string{allowlistConvertedAnnotation: config.AllowlistCustomConfigMapName},
+			},
+			Spec: spec,
+		})
+	}
+	if found.Annotations[allowlistConvertedAnnotation] == "" {
+		// the allowlist of the same name is created by the user
+		return nil
+	}
+	if reflect.DeepEqual(found.Spec, spec) {
+		return nil
+	}
+	found.Spec = spec
+	return c.Update(context.TODO(), found)
+}
+
+// updateMetricsAllowlistStatus reports in the Ready condition whether each ObservabilityMetricsAllowlist
+// is merged into the allowlist of the managed clusters
+func updateMetricsAllowlistStatus(c client.Client) error {
+	cm, err := getCustomAllowlistCM(c)
+	if err != nil {
+		return err
+	}
+	allowlists, err := listMetricsAllowlists(c)
+	if err != nil {
+		return err
+	}
+	for i := range allowlists {
+		allowlist := &allowlists[i]
+		condition := mcoshared.Condition{
+			Type:    "Ready",
+			Status:  metav1.ConditionTrue,
+			Reason:  "Merged",
+			Message: "The metrics are merged into the allowlist of the managed clusters",
+		}
+		if err := validateMetricsAllowlistSpec(&allowlist.Spec); err != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "InvalidSpec"
+			condition.Message = err.Error()
+		} else if cm != nil && allowlist.Annotations[allowlistConvertedAnnotation] != "" {
+			condition.Reason = "ConvertedFromConfigMap"
+			condition.Message = fmt.Sprintf("The allowlist is converted from the configmap %s, "+
+				"delete the configmap to manage the allowlist here", cm.Name)
+		}
+		existing := findAllowlistCondition(allowlist.Status.Conditions, condition.Type)
+		if existing != nil && existing.Status == condition.Status && existing.Reason == condition.Reason &&
+			existing.Message == condition.Message {
+			continue
+		}
+		condition.LastTransitionTime = metav1.NewTime(time.Now())
+		if existing != nil && existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		conditions := []mcoshared.Condition{condition}
+		for _, other := range allowlist.Status.Conditions {
+			if other.Type != condition.Type {
+				conditions = append(conditions, other)
+			}
+		}
+		allowlist.Status.Conditions = conditions
+		err = c.Status().Update(context.TODO(), allowlist)
+		if err != nil && !k8serrors.IsConflict(err) {
+			log.Error(err, "Failed to update the status of ObservabilityMetricsAllowlist", "name", allowlist.Name)
+			return err
+		}
+	}
+	return nil
+}
+
+func findAllowlistCondition(conditions []mcoshared.Condition, conditionType string) *mcoshared.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
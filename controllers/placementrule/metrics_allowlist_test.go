@@ -0,0 +1,128 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestMetricsAllowlist(t *testing.T) {
+	initSchema(t)
+
+	etcd := &mcov1beta2.ObservabilityMetricsAllowlist{
+		ObjectMeta: metav1.ObjectMeta{Name: "etcd"},
+		Spec: mcov1beta2.ObservabilityMetricsAllowlistSpec{
+			Names: []mcov1beta2.MetricName{"etcd_server_has_leader"},
+			RecordingRules: []mcov1beta2.MetricsRecordingRule{
+				{Record: "etcd:leader_changes:rate5m", Expr: "sum(rate(etcd_server_leader_changes_seen_total[5m]))"},
+			},
+			CollectIntervals: []mcov1beta2.MetricsCollectInterval{
+				{Names: []mcov1beta2.MetricName{"etcd_server_has_leader"}, Interval: "30s"},
+			},
+		},
+	}
+	broken := &mcov1beta2.ObservabilityMetricsAllowlist{
+		ObjectMeta: metav1.ObjectMeta{Name: "broken"},
+		Spec: mcov1beta2.ObservabilityMetricsAllowlistSpec{
+			Names:   []mcov1beta2.MetricName{"broken_a"},
+			Renames: []mcov1beta2.MetricRename{{From: "broken_a", To: "broken_a"}},
+		},
+	}
+	cm := NewMetricsCustomAllowListCM()
+	c := fake.NewFakeClient(cm, etcd, broken)
+
+	err := convertCustomAllowlist(c)
+	if err != nil {
+		t.Fatalf("Failed to convert the custom allowlist configmap: (%v)", err)
+	}
+	converted := &mcov1beta2.ObservabilityMetricsAllowlist{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: config.AllowlistCustomConfigMapName}, converted)
+	if err != nil {
+		t.Fatalf("The custom allowlist configmap should be converted: (%v)", err)
+	}
+	if !reflect.DeepEqual(converted.Spec.Names, []mcov1beta2.MetricName{"c", "d"}) ||
+		!reflect.DeepEqual(converted.Spec.Renames, []mcov1beta2.MetricRename{{From: "d", To: "e"}}) {
+		t.Errorf("Wrong converted allowlist: %v", converted.Spec)
+	}
+
+	// the converted allowlist is skipped while the configmap exists
+	allowlist, err := getCustomAllowlist(c)
+	if err != nil {
+		t.Fatalf("Failed to get the custom allowlist: (%v)", err)
+	}
+	if !reflect.DeepEqual(allowlist.NameList, []string{"c", "d", "etcd_server_has_leader"}) ||
+		len(allowlist.RecordingRuleList) != 1 || len(allowlist.CollectIntervalList) != 1 {
+		t.Errorf("Wrong merged allowlist: %v", allowlist)
+	}
+
+	err = updateMetricsAllowlistStatus(c)
+	if err != nil {
+		t.Fatalf("Failed to update the status of the allowlists: (%v)", err)
+	}
+	for name, reason := range map[string]string{
+		"etcd": "Merged", "broken": "InvalidSpec", config.AllowlistCustomConfigMapName: "ConvertedFromConfigMap",
+	} {
+		found := &mcov1beta2.ObservabilityMetricsAllowlist{}
+		err = c.Get(context.TODO(), types.NamespacedName{Name: name}, found)
+		if err != nil {
+			t.Fatalf("Failed to get the allowlist %s: (%v)", name, err)
+		}
+		if len(found.Status.Conditions) != 1 || found.Status.Conditions[0].Reason != reason {
+			t.Errorf("the status of the allowlist %s should be %s: %v", name, reason, found.Status.Conditions)
+		}
+	}
+
+	// the converted allowlist is released once the configmap is deleted
+	err = c.Delete(context.TODO(), cm)
+	if err != nil {
+		t.Fatalf("Failed to delete the configmap: (%v)", err)
+	}
+	err = convertCustomAllowlist(c)
+	if err != nil {
+		t.Fatalf("Failed to release the converted allowlist: (%v)", err)
+	}
+	released := &mcov1beta2.ObservabilityMetricsAllowlist{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: config.AllowlistCustomConfigMapName}, released)
+	if err != nil || released.Annotations[allowlistConvertedAnnotation] != "" {
+		t.Errorf("the converted allowlist should be released: %v (%v)", released.Annotations, err)
+	}
+	allowlist, err = getCustomAllowlist(c)
+	if err != nil {
+		t.Fatalf("Failed to get the custom allowlist: (%v)", err)
+	}
+	if !reflect.DeepEqual(allowlist.NameList, []string{"etcd_server_has_leader", "c", "d"}) ||
+		allowlist.ReNameMap["d"] != "e" {
+		t.Errorf("the released allowlist should be merged: %v", allowlist)
+	}
+}
+
+func TestUnmarshalCustomAllowlist(t *testing.T) {
+	cm := NewMetricsCustomAllowListCM()
+	cm.Data[nodeMetricsListKey] = `
+  names:
+    - node_custom
+`
+	allowlist, err := unmarshalCustomAllowlist(cm)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal the custom allowlist: (%v)", err)
+	}
+	if !reflect.DeepEqual(allowlist.NameList, []string{"c", "d", "node_custom"}) || allowlist.ReNameMap["d"] != "e" {
+		t.Errorf("the keys of the custom allowlist should be merged: %v", allowlist)
+	}
+
+	cm.Data[metricsListKey] = "names: ["
+	_, err = unmarshalCustomAllowlist(cm)
+	if err == nil {
+		t.Errorf("the broken custom allowlist should be rejected")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// getNamespaceFilter returns the namespace filter of the managed cluster, the filter set in its
+// observabilityaddon on the hub overrides the global one unless it is invalid
+func getNamespaceFilter(mco *mcov1beta2.MultiClusterObservability,
+	addon *mcov1beta1.ObservabilityAddon) *mcoshared.NamespaceFilterSpec {
+	var filter *mcoshared.NamespaceFilterSpec
+	if mco.Spec.ObservabilityAddonSpec != nil {
+		filter = mco.Spec.ObservabilityAddonSpec.NamespaceFilter
+	}
+	if addon == nil || addon.Spec.NamespaceFilter == nil {
+		return filter
+	}
+	if err := config.ValidateNamespaceFilter(addon.Spec.NamespaceFilter); err != nil {
+		log.Error(err, "Invalid namespace filter of the observabilityaddon, use the global one",
+			"namespace", addon.Namespace)
+		return filter
+	}
+	return addon.Spec.NamespaceFilter
+}
+
+// getClusterNamespaceFilter returns the namespace filter of the managed cluster in the cluster namespace
+func getClusterNamespaceFilter(c client.Client, mco *mcov1beta2.MultiClusterObservability,
+	namespace string) (*mcoshared.NamespaceFilterSpec, error) {
+	addon := &mcov1beta1.ObservabilityAddon{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: obsAddonName, Namespace: namespace}, addon)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.Error(err, "Failed to get observabilityaddon", "namespace", namespace)
+			return nil, err
+		}
+		addon = nil
+	}
+	return getNamespaceFilter(mco, addon), nil
+}
+
+// getNamespaceMatchers returns the series selectors of /federate which honour the namespace filter,
+// the series without the namespace label are always selected
+func getNamespaceMatchers(filter *mcoshared.NamespaceFilterSpec) []string {
+	if filter == nil || len(filter.Include) == 0 && len(filter.Exclude) == 0 {
+		return []string{`{__name__=~".+"}`}
+	}
+	matcher := `{__name__=~".+"`
+	if len(filter.Include) > 0 {
+		matcher += fmt.Sprintf(`,namespace=~%q`, joinRegexps(filter.Include))
+	} else {
+		matcher += `,namespace!=""`
+	}
+	if len(filter.Exclude) > 0 {
+		matcher += fmt.Sprintf(`,namespace!~%q`, joinRegexps(filter.Exclude))
+	}
+	return []string{`{__name__=~".+",namespace=""}`, matcher + "}"}
+}
+
+func joinRegexps(regexps []string) string {
+	groups := make([]string, len(regexps))
+	for i, r := range regexps {
+		groups[i] = "(?:" + r + ")"
+	}
+	return strings.Join(groups, "|")
+}
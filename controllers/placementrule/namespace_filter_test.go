@@ -0,0 +1,83 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+)
+
+func TestNamespaceMatchers(t *testing.T) {
+	cases := map[string]struct {
+		filter   *mcoshared.NamespaceFilterSpec
+		matchers []string
+	}{
+		"no filter": {
+			filter:   nil,
+			matchers: []string{`{__name__=~".+"}`},
+		},
+		"include": {
+			filter: &mcoshared.NamespaceFilterSpec{Include: []string{"app-.*", "db"}},
+			matchers: []string{`{__name__=~".+",namespace=""}`,
+				`{__name__=~".+",namespace=~"(?:app-.*)|(?:db)"}`},
+		},
+		"exclude": {
+			filter: &mcoshared.NamespaceFilterSpec{Exclude: []string{"openshift-.*"}},
+			matchers: []string{`{__name__=~".+",namespace=""}`,
+				`{__name__=~".+",namespace!="",namespace!~"(?:openshift-.*)"}`},
+		},
+		"include and exclude": {
+			filter: &mcoshared.NamespaceFilterSpec{Include: []string{"app-.*"}, Exclude: []string{"app-test"}},
+			matchers: []string{`{__name__=~".+",namespace=""}`,
+				`{__name__=~".+",namespace=~"(?:app-.*)",namespace!~"(?:app-test)"}`},
+		},
+	}
+	for name, c := range cases {
+		if matchers := getNamespaceMatchers(c.filter); !reflect.DeepEqual(matchers, c.matchers) {
+			t.Errorf("Wrong matchers for %s: %v", name, matchers)
+		}
+	}
+}
+
+func TestNamespaceFilterOverride(t *testing.T) {
+	initSchema(t)
+
+	global := &mcoshared.NamespaceFilterSpec{Exclude: []string{"openshift-.*"}}
+	addon := &mcov1beta1.ObservabilityAddon{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      obsAddonName,
+			Namespace: namespace,
+		},
+		Spec: mcoshared.ObservabilityAddonSpec{
+			NamespaceFilter: &mcoshared.NamespaceFilterSpec{Include: []string{"app-.*"}},
+		},
+	}
+	c := fake.NewFakeClient(addon)
+	mco := newTestMCO()
+	mco.Spec.ObservabilityAddonSpec.NamespaceFilter = global
+
+	found, err := getObservabilityAddon(c, namespace, mco)
+	if err != nil {
+		t.Fatalf("Failed to get observabilityaddon: (%v)", err)
+	}
+	if !reflect.DeepEqual(found.Spec.NamespaceFilter, addon.Spec.NamespaceFilter) {
+		t.Errorf("The filter of the cluster should override the global one: %v", found.Spec.NamespaceFilter)
+	}
+
+	// the invalid filter of the cluster falls back to the global one
+	addon.Spec.NamespaceFilter.Include = []string{"app-("}
+	if filter := getNamespaceFilter(mco, addon); filter != global {
+		t.Errorf("The invalid filter of the cluster should be ignored: %v", filter)
+	}
+	filter, err := getClusterNamespaceFilter(c, mco, "missing")
+	if err != nil || filter != global {
+		t.Errorf("The global filter should be used for the cluster without observabilityaddon: %v (%v)", filter, err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+)
+
+// The reasons of the cluster observability lifecycle notifications,
+// they are kept stable for the external systems which consume them
+const (
+	ReasonClusterOnboarded = "ObservabilityOnboarded"
+	ReasonClusterDegraded  = "ObservabilityDegraded"
+	ReasonClusterRemoved   = "ObservabilityRemoved"
+)
+
+// LifecycleNotification is the payload posted to the notification webhook
+type LifecycleNotification struct {
+	Cluster   string      `json:"cluster"`
+	Reason    string      `json:"reason"`
+	Message   string      `json:"message"`
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+type lifecycleNotifier struct {
+	mutex      sync.RWMutex
+	recorder   record.EventRecorder
+	webhookURL string
+	httpClient *http.Client
+}
+
+// notifier is only called by the placementrule reconcile, which runs on the leader. It keeps no
+// history, the notifications are decided from the manifestworks and the managedclusteraddons.
+var notifier = &lifecycleNotifier{
+	httpClient: &http.Client{Timeout: 10 * time.Second},
+}
+
+func (n *lifecycleNotifier) setRecorder(recorder record.EventRecorder) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.recorder = recorder
+}
+
+func (n *lifecycleNotifier) setWebhookURL(url string) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.webhookURL = url
+}
+
+// notify records the kubernetes event on the managed cluster and posts
+// the notification to the webhook if it is configured
+func (n *lifecycleNotifier) notify(cluster string, reason string, message string) {
+	n.mutex.RLock()
+	recorder := n.recorder
+	url := n.webhookURL
+	n.mutex.RUnlock()
+
+	log.Info("Cluster observability lifecycle event", "cluster", cluster, "reason", reason, "message", message)
+	if recorder != nil {
+		eventType := corev1.EventTypeNormal
+		if reason == ReasonClusterDegraded {
+			eventType = corev1.EventTypeWarning
+		}
+		ref := &corev1.ObjectReference{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "ManagedCluster",
+			Name:       cluster,
+		}
+		recorder.Event(ref, eventType, reason, message)
+	}
+	if url == "" {
+		return
+	}
+	payload, err := json.Marshal(LifecycleNotification{
+		Cluster:   cluster,
+		Reason:    reason,
+		Message:   message,
+		Timestamp: metav1.Now(),
+	})
+	if err != nil {
+		log.Error(err, "Failed to marshal the lifecycle notification", "cluster", cluster)
+		return
+	}
+	// do not block the reconcile on the webhook
+	go func() {
+		err := n.post(url, payload)
+		if err != nil {
+			log.Error(err, "Failed to send the lifecycle notification", "cluster", cluster, "reason", reason)
+		}
+	}()
+}
+
+func (n *lifecycleNotifier) post(url string, payload []byte) error {
+	resp, err := n.httpClient.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d from notification webhook", resp.StatusCode)
+	}
+	return nil
+}
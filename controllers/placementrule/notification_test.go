@@ -0,0 +1,49 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/record"
+)
+
+func TestLifecycleNotifier(t *testing.T) {
+	received := make(chan LifecycleNotification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notification := LifecycleNotification{}
+		err := json.NewDecoder(r.Body).Decode(&notification)
+		if err != nil {
+			t.Errorf("Failed to decode the notification: (%v)", err)
+		}
+		received <- notification
+	}))
+	defer server.Close()
+
+	recorder := record.NewFakeRecorder(1)
+	n := &lifecycleNotifier{httpClient: server.Client()}
+	n.setRecorder(recorder)
+	n.setWebhookURL(server.URL)
+
+	n.notify(clusterName, ReasonClusterDegraded, "metrics collector is degraded")
+
+	event := <-recorder.Events
+	if !strings.HasPrefix(event, "Warning "+ReasonClusterDegraded) {
+		t.Fatalf("Wrong event recorded: %s", event)
+	}
+	select {
+	case notification := <-received:
+		if notification.Cluster != clusterName || notification.Reason != ReasonClusterDegraded ||
+			notification.Message != "metrics collector is degraded" {
+			t.Fatalf("Wrong notification posted: %v", notification)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Notification is not posted to the webhook")
+	}
+}
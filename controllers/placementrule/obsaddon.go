@@ -12,7 +12,11 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	placementv1 "github.com/open-cluster-management/multicloud-operators-placementrule/pkg/apis/apps/v1"
 	obsv1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
 )
 
@@ -21,6 +25,15 @@ const (
 	obsAddonFinalizer = "observability.open-cluster-management.io/addon-cleanup"
 )
 
+var (
+	// staleAddonGracePeriod is how long the observabilityaddon stays without its manifestwork before it is
+	// force deleted, so that a transient failure or a lagging cache does not remove a live cluster
+	staleAddonGracePeriod = 5 * time.Minute
+	// staleAddonsSince records since when the observabilityaddon of the cluster namespace is found without
+	// its manifestwork
+	staleAddonsSince = map[string]time.Time{}
+)
+
 func deleteObsAddon(c client.Client, namespace string) error {
 	found := &obsv1beta1.ObservabilityAddon{}
 	err := c.Get(context.TODO(), types.NamespacedName{Name: obsAddonName, Namespace: namespace}, found)
@@ -28,13 +41,18 @@ func deleteObsAddon(c client.Client, namespace string) error {
 		if errors.IsNotFound(err) {
 			return nil
 		}
-		log.Error(err, "Failed to check observabilityaddon cr before delete", "namespace", namespace)
+		errorlog.ClusterError(log, err, namespace, "Failed to check observabilityaddon cr before delete")
+		return err
+	}
+	// the observabilityaddon is deleted by the controller, not by hand
+	err = removeProtectionFinalizer(c, found)
+	if err != nil {
 		return err
 	}
 
 	err = c.Delete(context.TODO(), found)
 	if err != nil {
-		log.Error(err, "Failed to delete observabilityaddon", "namespace", namespace)
+		errorlog.ClusterError(log, err, namespace, "Failed to delete observabilityaddon")
 	}
 
 	err = removeObservabilityAddon(c, namespace)
@@ -44,7 +62,7 @@ func deleteObsAddon(c client.Client, namespace string) error {
 
 	// forcely remove observabilityaddon if it's already stuck in Terminating more than 5 minutes
 	time.AfterFunc(time.Duration(5)*time.Minute, func() {
-		deleteStaleObsAddon(c, namespace, false)
+		deleteStaleObsAddon(c, namespace, false, false)
 	})
 
 	log.Info("observabilityaddon is deleted", "namespace", namespace)
@@ -59,6 +77,7 @@ func createObsAddon(c client.Client, namespace string) error {
 			Labels: map[string]string{
 				ownerLabelKey: ownerLabelValue,
 			},
+			Finalizers: []string{obsAddonProtectionFinalizer},
 		},
 	}
 	found := &obsv1beta1.ObservabilityAddon{}
@@ -82,24 +101,131 @@ func createObsAddon(c client.Client, namespace string) error {
 		return err
 	}
 
+	if !util.Contains(found.GetFinalizers(), obsAddonProtectionFinalizer) {
+		// protect the observabilityaddon which is created before the protection finalizer
+		found.SetFinalizers(append(found.GetFinalizers(), obsAddonProtectionFinalizer))
+		err = c.Update(context.TODO(), found)
+		if err != nil {
+			errorlog.ClusterError(log, err, namespace, "Failed to add the protection finalizer of observabilityaddon")
+			return err
+		}
+		return nil
+	}
+
 	log.Info("observabilityaddon already existed/unchanged", "namespace", namespace)
 	return nil
 }
 
-func deleteStaleObsAddon(c client.Client, namespace string, isForce bool) error {
+// deleteStaleObsAddons force deletes the observabilityaddons which are found without their manifestwork,
+// once the deletion is confirmed by confirmStaleObsAddon with the API server reader. With the dry run, the
+// addons which would be deleted are only logged.
+func deleteStaleObsAddons(c client.Client, apiReader client.Reader, namespaces []string,
+	placement *placementv1.PlacementRule, deleteAll bool, dryRun bool, now time.Time) error {
+	for namespace := range staleAddonsSince {
+		if !util.Contains(namespaces, namespace) {
+			delete(staleAddonsSince, namespace)
+		}
+	}
+	for _, namespace := range namespaces {
+		selected := !deleteAll && isPlacementDecision(placement, namespace)
+		confirmed, err := confirmStaleObsAddon(apiReader, namespace, selected, now)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			continue
+		}
+		err = deleteStaleObsAddon(c, namespace, true, dryRun)
+		if err != nil {
+			return err
+		}
+		if !dryRun {
+			delete(staleAddonsSince, namespace)
+		}
+	}
+	return nil
+}
+
+// confirmStaleObsAddon returns true if the observabilityaddon in the namespace, which is found without its
+// manifestwork, can be force deleted. The manifestwork is read again, the addon of the managed cluster which
+// still exists and is selected by the placement is kept, and the addon is only deleted once it stays without
+// its manifestwork for longer than staleAddonGracePeriod.
+func confirmStaleObsAddon(c client.Reader, namespace string, selected bool, now time.Time) (bool, error) {
+	work := &workv1.ManifestWork{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: namespace + workNameSuffix, Namespace: namespace}, work)
+	if err == nil {
+		delete(staleAddonsSince, namespace)
+		return false, nil
+	} else if !errors.IsNotFound(err) {
+		errorlog.ClusterError(log, err, namespace,
+			"Failed to check manifestwork before delete stale observabilityaddon")
+		return false, err
+	}
+
+	if selected {
+		cluster := &clusterv1.ManagedCluster{}
+		err = c.Get(context.TODO(), types.NamespacedName{Name: namespace}, cluster)
+		if err == nil && cluster.GetDeletionTimestamp() == nil {
+			// the manifestwork is re-created by the next reconcile of the cluster
+			log.Info("observabilityaddon without manifestwork belongs to a live managed cluster, skip",
+				"namespace", namespace)
+			delete(staleAddonsSince, namespace)
+			return false, nil
+		} else if err != nil && !errors.IsNotFound(err) {
+			errorlog.ClusterError(log, err, namespace,
+				"Failed to check managedcluster before delete stale observabilityaddon")
+			return false, err
+		}
+	}
+
+	since, found := staleAddonsSince[namespace]
+	if !found {
+		since = now
+		staleAddonsSince[namespace] = since
+	}
+	if now.Sub(since) < staleAddonGracePeriod {
+		log.Info("observabilityaddon is found without manifestwork, wait for the grace period", "namespace", namespace,
+			"since", since)
+		return false, nil
+	}
+	return true, nil
+}
+
+// nextStaleAddonCheck returns the time until the grace period of the first stale observabilityaddon ends,
+// or 0 if no observabilityaddon is waiting for it
+func nextStaleAddonCheck(now time.Time) time.Duration {
+	next := time.Duration(0)
+	for _, since := range staleAddonsSince {
+		wait := since.Add(staleAddonGracePeriod).Sub(now)
+		if wait <= 0 {
+			wait = time.Second
+		}
+		if next == 0 || wait < next {
+			next = wait
+		}
+	}
+	return next
+}
+
+func deleteStaleObsAddon(c client.Client, namespace string, isForce bool, dryRun bool) error {
 	found := &obsv1beta1.ObservabilityAddon{}
 	err := c.Get(context.TODO(), types.NamespacedName{Name: obsAddonName, Namespace: namespace}, found)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			return nil
 		}
-		log.Error(err, "Failed to check observabilityaddon cr before delete stale ones", "namespace", namespace)
+		errorlog.ClusterError(log, err, namespace, "Failed to check observabilityaddon cr before delete stale ones")
 		return err
 	}
 	if found.GetDeletionTimestamp() == nil && !isForce {
 		log.Info("observabilityaddon is not in Terminating status, skip", "namespace", namespace)
 		return nil
 	}
+	if dryRun {
+		log.Info("Dry run: observabilityaddon would be deleted thoroughly", "namespace", namespace,
+			"finalizers", found.GetFinalizers())
+		return nil
+	}
 	err = deleteFinalizer(c, found)
 	if err != nil {
 		return err
@@ -112,7 +238,7 @@ func deleteStaleObsAddon(c client.Client, namespace string, isForce bool) error
 	}
 	err = c.Delete(context.TODO(), obsaddon)
 	if err != nil && !errors.IsNotFound(err) {
-		log.Error(err, "Failed to delete observabilityaddon", "namespace", namespace)
+		errorlog.ClusterError(log, err, namespace, "Failed to delete observabilityaddon")
 		return err
 	}
 	log.Info("observabilityaddon is deleted thoroughly", "namespace", namespace)
@@ -120,11 +246,13 @@ func deleteStaleObsAddon(c client.Client, namespace string, isForce bool) error
 }
 
 func deleteFinalizer(c client.Client, obsaddon *obsv1beta1.ObservabilityAddon) error {
-	if util.Contains(obsaddon.GetFinalizers(), obsAddonFinalizer) {
-		obsaddon.SetFinalizers(util.Remove(obsaddon.GetFinalizers(), obsAddonFinalizer))
+	if util.Contains(obsaddon.GetFinalizers(), obsAddonFinalizer) ||
+		util.Contains(obsaddon.GetFinalizers(), obsAddonProtectionFinalizer) {
+		obsaddon.SetFinalizers(util.Remove(util.Remove(obsaddon.GetFinalizers(), obsAddonFinalizer),
+			obsAddonProtectionFinalizer))
 		err := c.Update(context.TODO(), obsaddon)
 		if err != nil {
-			log.Error(err, "Failed to delete finalizer in observabilityaddon", "namespace", obsaddon.Namespace)
+			errorlog.ClusterError(log, err, obsaddon.Namespace, "Failed to delete finalizer in observabilityaddon")
 			return err
 		}
 		log.Info("observabilityaddon's finalizer is deleted", "namespace", obsaddon.Namespace)
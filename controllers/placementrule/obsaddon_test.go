@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	placementv1 "github.com/open-cluster-management/multicloud-operators-placementrule/pkg/apis/apps/v1"
 	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -75,8 +77,66 @@ func TestStaleObsAddonCR(t *testing.T) {
 		t.Fatalf("Failed to update observabilityaddon: (%v)", err)
 	}
 
-	err = deleteStaleObsAddon(c, namespace, true)
+	err = deleteStaleObsAddon(c, namespace, true, false)
 	if err != nil {
 		t.Fatalf("Failed to remove stale observabilityaddon: (%v)", err)
 	}
 }
+
+func TestDeleteStaleObsAddons(t *testing.T) {
+	initSchema(t)
+
+	now := time.Now()
+	cluster := &clusterv1.ManagedCluster{ObjectMeta: v1.ObjectMeta{Name: namespace}}
+	c := fake.NewFakeClient(newTestRoute(), cluster)
+	if err := createObsAddon(c, namespace); err != nil {
+		t.Fatalf("Failed to create observabilityaddon: (%v)", err)
+	}
+	placement := &placementv1.PlacementRule{
+		Status: placementv1.PlacementRuleStatus{
+			Decisions: []placementv1.PlacementDecision{{ClusterName: namespace, ClusterNamespace: namespace}},
+		},
+	}
+	staleAddonsSince = map[string]time.Time{}
+	getAddon := func() error {
+		return c.Get(context.TODO(), types.NamespacedName{Name: obsAddonName, Namespace: namespace},
+			&mcov1beta1.ObservabilityAddon{})
+	}
+
+	// the addon of the live and selected cluster is kept
+	if err := deleteStaleObsAddons(c, c, []string{namespace}, placement, false, false,
+		now.Add(staleAddonGracePeriod)); err != nil {
+		t.Fatalf("Failed to delete stale observabilityaddons: (%v)", err)
+	}
+	if err := getAddon(); err != nil || len(staleAddonsSince) != 0 {
+		t.Fatalf("The observabilityaddon of the live cluster should be kept: (%v)", err)
+	}
+
+	// the addon of the unselected cluster waits for the grace period
+	placement.Status.Decisions = nil
+	if err := deleteStaleObsAddons(c, c, []string{namespace}, placement, false, false, now); err != nil {
+		t.Fatalf("Failed to delete stale observabilityaddons: (%v)", err)
+	}
+	if err := getAddon(); err != nil {
+		t.Fatalf("The observabilityaddon should wait for the grace period: (%v)", err)
+	}
+	if next := nextStaleAddonCheck(now); next != staleAddonGracePeriod {
+		t.Errorf("Wrong time of the next stale observabilityaddon check: %v", next)
+	}
+
+	// the dry run only logs the addon
+	later := now.Add(staleAddonGracePeriod)
+	if err := deleteStaleObsAddons(c, c, []string{namespace}, placement, false, true, later); err != nil {
+		t.Fatalf("Failed to delete stale observabilityaddons: (%v)", err)
+	}
+	if err := getAddon(); err != nil {
+		t.Fatalf("The observabilityaddon should be kept in the dry run: (%v)", err)
+	}
+
+	if err := deleteStaleObsAddons(c, c, []string{namespace}, placement, false, false, later); err != nil {
+		t.Fatalf("Failed to delete stale observabilityaddons: (%v)", err)
+	}
+	if err := getAddon(); !errors.IsNotFound(err) || len(staleAddonsSince) != 0 {
+		t.Errorf("The stale observabilityaddon should be deleted: (%v)", err)
+	}
+}
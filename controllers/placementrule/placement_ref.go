@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	placementv1 "github.com/open-cluster-management/multicloud-operators-placementrule/pkg/apis/apps/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// getPlacement fetches the placement which selects the managed clusters into the PlacementRule,
+// it is the placements in the install strategy of the ClusterManagementAddOn if it is set, or else
+// the default PlacementRule or the user-managed placement referenced by the
+// MultiClusterObservability. The decisions of a Placement are read from its PlacementDecisions.
+// The NotFound error is returned if the placement does not exist.
+func getPlacement(c client.Reader, mco *mcov1beta2.MultiClusterObservability,
+	placement *placementv1.PlacementRule) error {
+	refs, err := getInstallStrategyPlacements(c)
+	if err != nil {
+		return err
+	}
+	if len(refs) > 0 {
+		return getInstallStrategyDecisions(c, refs, placement)
+	}
+
+	name := config.GetPlacementRefName(mco)
+	if config.GetPlacementRefKind(mco) == config.PlacementKindPlacementRule {
+		return c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: watchNamespace}, placement)
+	}
+
+	found := &unstructured.Unstructured{}
+	found.SetAPIVersion(config.PlacementAPIVersion)
+	found.SetKind(config.PlacementKindPlacement)
+	err = c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: watchNamespace}, found)
+	if err != nil {
+		return err
+	}
+	placement.SetName(name)
+	placement.SetNamespace(watchNamespace)
+	return appendPlacementDecisions(c, watchNamespace, name, placement)
+}
+
+// appendPlacementDecisions appends the managed clusters in the PlacementDecisions of the Placement
+// to the decisions of the placement, the clusters which are already decided are skipped
+func appendPlacementDecisions(c client.Reader, namespace, name string, placement *placementv1.PlacementRule) error {
+	decisionList := &unstructured.UnstructuredList{}
+	decisionList.SetAPIVersion(config.PlacementAPIVersion)
+	decisionList.SetKind(config.PlacementDecisionListKind)
+	err := c.List(context.TODO(), decisionList, client.InNamespace(namespace),
+		client.MatchingLabels{config.PlacementDecisionLabelKey: name})
+	if err != nil {
+		log.Error(err, "Failed to list the placementdecisions", "placement", name, "namespace", namespace)
+		return err
+	}
+	decided := map[string]bool{}
+	for _, decision := range placement.Status.Decisions {
+		decided[decision.ClusterName] = true
+	}
+	for _, decision := range decisionList.Items {
+		clusters, _, _ := unstructured.NestedSlice(decision.Object, "status", "decisions")
+		for _, cluster := range clusters {
+			clusterName, _, _ := unstructured.NestedString(cluster.(map[string]interface{}), "clusterName")
+			if clusterName == "" || decided[clusterName] {
+				continue
+			}
+			decided[clusterName] = true
+			// the namespace of the managed cluster is named after the cluster
+			placement.Status.Decisions = append(placement.Status.Decisions, placementv1.PlacementDecision{
+				ClusterName:      clusterName,
+				ClusterNamespace: clusterName,
+			})
+		}
+	}
+	return nil
+}
+
+// isObservabilityPlacement returns true if the placement is in the install strategy of the
+// ClusterManagementAddOn, or is the default PlacementRule or the user-managed placement referenced
+// by the MultiClusterObservability
+func isObservabilityPlacement(c client.Client, namespace, name, kind string) bool {
+	if kind == config.PlacementKindPlacement && isInstallStrategyPlacement(c, namespace, name) {
+		return true
+	}
+	if namespace != watchNamespace {
+		return false
+	}
+	if kind == config.PlacementKindPlacementRule && name == config.GetPlacementRuleName() {
+		return true
+	}
+	if config.GetMonitoringCRName() == "" {
+		return false
+	}
+	mco := &mcov1beta2.MultiClusterObservability{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: config.GetMonitoringCRName()}, mco)
+	if err != nil || !config.IsPlacementRefEnabled(mco) {
+		return false
+	}
+	return config.GetPlacementRefKind(mco) == kind && config.GetPlacementRefName(mco) == name
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	placementv1 "github.com/open-cluster-management/multicloud-operators-placementrule/pkg/apis/apps/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func newTestPlacementDecision(name, placementName string, clusters ...string) *unstructured.Unstructured {
+	decisions := []interface{}{}
+	for _, cluster := range clusters {
+		decisions = append(decisions, map[string]interface{}{"clusterName": cluster, "reason": ""})
+	}
+	decision := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"decisions": decisions,
+			},
+		},
+	}
+	decision.SetAPIVersion(config.PlacementAPIVersion)
+	decision.SetKind(config.PlacementDecisionKind)
+	decision.SetName(name)
+	decision.SetNamespace(mcoNamespace)
+	decision.SetLabels(map[string]string{config.PlacementDecisionLabelKey: placementName})
+	return decision
+}
+
+func TestGetPlacement(t *testing.T) {
+	initSchema(t)
+
+	userRule := &placementv1.PlacementRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gitops-rule",
+			Namespace: mcoNamespace,
+		},
+		Status: placementv1.PlacementRuleStatus{
+			Decisions: []placementv1.PlacementDecision{
+				{ClusterName: "cluster1", ClusterNamespace: "cluster1"},
+			},
+		},
+	}
+	userPlacement := &unstructured.Unstructured{}
+	userPlacement.SetAPIVersion(config.PlacementAPIVersion)
+	userPlacement.SetKind(config.PlacementKindPlacement)
+	userPlacement.SetName("gitops-placement")
+	userPlacement.SetNamespace(mcoNamespace)
+
+	c := fake.NewFakeClient(userRule, userPlacement,
+		newTestPlacementDecision("gitops-placement-decision-1", "gitops-placement", "cluster2", "cluster3"),
+		newTestPlacementDecision("other-placement-decision-1", "other-placement", "cluster4"))
+
+	mco := newTestMCO()
+	placement := &placementv1.PlacementRule{}
+	if err := getPlacement(c, mco, placement); err == nil {
+		t.Errorf("The default placementrule should not be found")
+	}
+
+	mco.Spec.PlacementRef = &mcov1beta2.PlacementRefSpec{Kind: config.PlacementKindPlacementRule, Name: "gitops-rule"}
+	placement = &placementv1.PlacementRule{}
+	if err := getPlacement(c, mco, placement); err != nil {
+		t.Fatalf("Failed to get the referenced placementrule: (%v)", err)
+	}
+	if len(placement.Status.Decisions) != 1 || placement.Status.Decisions[0].ClusterName != "cluster1" {
+		t.Errorf("The decisions of the referenced placementrule are wrong: %v", placement.Status.Decisions)
+	}
+
+	mco.Spec.PlacementRef = &mcov1beta2.PlacementRefSpec{Kind: config.PlacementKindPlacement, Name: "gitops-placement"}
+	placement = &placementv1.PlacementRule{}
+	if err := getPlacement(c, mco, placement); err != nil {
+		t.Fatalf("Failed to get the referenced placement: (%v)", err)
+	}
+	if len(placement.Status.Decisions) != 2 || placement.Status.Decisions[0].ClusterNamespace != "cluster2" ||
+		placement.Status.Decisions[1].ClusterNamespace != "cluster3" {
+		t.Errorf("The decisions of the referenced placement are wrong: %v", placement.Status.Decisions)
+	}
+
+	if !isObservabilityPlacement(c, mcoNamespace, config.GetPlacementRuleName(), config.PlacementKindPlacementRule) {
+		t.Errorf("The default placementrule should be watched")
+	}
+	if isObservabilityPlacement(c, "default", config.GetPlacementRuleName(), config.PlacementKindPlacementRule) {
+		t.Errorf("The placementrule in other namespaces should not be watched")
+	}
+}
@@ -6,12 +6,16 @@ package placementrule
 import (
 	"context"
 	"errors"
+	"reflect"
+	"time"
 
 	"github.com/go-logr/logr"
+	routev1 "github.com/openshift/api/route/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -26,11 +30,15 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	clusterv1alpha1 "github.com/open-cluster-management/api/cluster/v1alpha1"
 	workv1 "github.com/open-cluster-management/api/work/v1"
 	placementv1 "github.com/open-cluster-management/multicloud-operators-placementrule/pkg/apis/apps/v1"
 	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
 	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/audit"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
 )
 
@@ -49,16 +57,24 @@ var (
 
 // PlacementRuleReconciler reconciles a PlacementRule object
 type PlacementRuleReconciler struct {
-	Client     client.Client
-	Log        logr.Logger
-	Scheme     *runtime.Scheme
+	Client client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	// APIReader reads from the API server directly. The decisions which remove the observability from
+	// the managed clusters are confirmed with it, i.e. that the MultiClusterObservability or the placement
+	// is deleted and that the observabilityaddon is stale, so that the stale cache after the operator
+	// restarts does not remove the observability from the fleet.
 	APIReader  client.Reader
 	RESTMapper meta.RESTMapper
+	// StaleAddonDryRun only logs the stale observabilityaddons instead of force deleting them
+	StaleAddonDryRun bool
 }
 
 // +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=placementrules,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=placementrules/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=placementrules/finalizers,verbs=update
+// +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=observabilitymetricsallowlists,verbs=get;list;watch;create;update
+// +kubebuilder:rbac:groups=observability.open-cluster-management.io,resources=observabilitymetricsallowlists/status,verbs=get;update;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -85,6 +101,10 @@ func (r *PlacementRuleReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		types.NamespacedName{
 			Name: config.GetMonitoringCRName(),
 		}, mco)
+	if err != nil && k8serrors.IsNotFound(err) {
+		// confirm the deletion with the API server before the observability is removed from the fleet
+		err = r.APIReader.Get(context.TODO(), types.NamespacedName{Name: config.GetMonitoringCRName()}, mco)
+	}
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			deleteAll = true
@@ -92,11 +112,26 @@ func (r *PlacementRuleReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			// Error reading the object - requeue the request.
 			return ctrl.Result{}, err
 		}
+	} else if mco.GetDeletionTimestamp() != nil {
+		// the MultiClusterObservability is kept until the observability is removed from the managed clusters
+		deleteAll = true
+	}
+	if !deleteAll {
+		notifier.setWebhookURL(mco.Spec.NotificationWebhookURL)
 	}
+
 	placement := &placementv1.PlacementRule{}
 	if !deleteAll {
-		// Fetch the PlacementRule instance
-		err = r.Client.Get(context.TODO(), req.NamespacedName, placement)
+		err = initInstallStrategy(r.Client, mco)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		// Fetch the PlacementRule instance or the decisions of the referenced placement
+		err = getPlacement(r.Client, mco, placement)
+		if err != nil && k8serrors.IsNotFound(err) {
+			*placement = placementv1.PlacementRule{}
+			err = getPlacement(r.APIReader, mco, placement)
+		}
 		if err != nil {
 			if k8serrors.IsNotFound(err) {
 				deleteAll = true
@@ -114,7 +149,7 @@ func (r *PlacementRuleReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 
 	//read image manifest configmap to be used to replace the image for each component.
-	if _, err = config.ReadImageManifestConfigMap(r.Client); err != nil {
+	if _, err = config.ReadImageManifestConfigMap(r.APIReader); err != nil {
 		return ctrl.Result{}, err
 	}
 
@@ -129,6 +164,8 @@ func (r *PlacementRuleReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 
 	if !deleteAll {
+		caRolloutPending = false
+		authMigrationPending = false
 		res, err := createAllRelatedRes(r.Client, r.RESTMapper, req, mco, placement, obsAddonList)
 		if err != nil {
 			return res, err
@@ -168,27 +205,37 @@ func (r *PlacementRuleReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 		if !util.Contains(latestClusters, work.Namespace) {
 			reqLogger.Info("To delete manifestwork", "namespace", work.Namespace)
-			err = deleteManagedClusterRes(r.Client, work.Namespace)
+			// keep the managedclusteraddon of the opted out or unsupported cluster to show why
+			err = deleteManagedClusterRes(r.Client, work.Namespace,
+				!deleteAll && isPlacementDecision(placement, work.Namespace))
 			if err != nil {
 				return ctrl.Result{}, err
 			}
+			notifier.notify(work.Namespace, ReasonClusterRemoved,
+				"Observability is removed from the managed cluster")
 		} else {
 			staleAddons = util.Remove(staleAddons, work.Namespace)
 		}
 	}
 
 	// delete stale addons if manifestwork does not exist
-	for _, addon := range staleAddons {
-		err = deleteStaleObsAddon(r.Client, addon, true)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
+	err = deleteStaleObsAddons(r.Client, r.APIReader, staleAddons, placement, deleteAll, r.StaleAddonDryRun, time.Now())
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
-	err = updateAddonStatus(r.Client, *obsAddonList)
+	// detect the manifestworks which are not applied by the work agents
+	workConditions, err := checkStaleWorks(r.Client, workList.Items, time.Now())
 	if err != nil {
+		reqLogger.Error(err, "Failed to re-create the stale manifestworks")
 		return ctrl.Result{}, err
 	}
+	clusterConditions := map[string][]metav1.Condition{}
+	for cluster, condition := range workConditions {
+		clusterConditions[cluster] = []metav1.Condition{condition}
+	}
+	// the conditions are added to the managedclusteraddons by the addon status controller
+	hubConditions.set(hubConditionSourceWork, clusterConditions)
 
 	err = r.Client.List(context.TODO(), workList, opts)
 	if err != nil {
@@ -198,8 +245,38 @@ func (r *PlacementRuleReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	if len(workList.Items) == 0 && deleteAll {
 		err = deleteGlobalResource(r.Client)
 	}
+	// write the changes pushed to the managed clusters into the audit history
+	if auditErr := audit.Flush(r.Client); auditErr != nil && err == nil {
+		err = auditErr
+	}
 
-	return ctrl.Result{}, err
+	result := ctrl.Result{}
+	if next := nextStaleWorkCheck(time.Now()); next > 0 &&
+		(result.RequeueAfter == 0 || next < result.RequeueAfter) {
+		// check again when the pending manifestworks become stale
+		result.RequeueAfter = next
+	}
+	if next := nextStaleAddonCheck(time.Now()); next > 0 &&
+		(result.RequeueAfter == 0 || next < result.RequeueAfter) {
+		// delete the stale observabilityaddons once their grace period ends
+		result.RequeueAfter = next
+	}
+	if next := nextCARollout(time.Now()); next > 0 &&
+		(result.RequeueAfter == 0 || next < result.RequeueAfter) {
+		// push the renewed server CA to the deferred clusters
+		result.RequeueAfter = next
+	}
+	if next := nextAuthMigration(time.Now()); next > 0 &&
+		(result.RequeueAfter == 0 || next < result.RequeueAfter) {
+		// switch the authentication of the deferred clusters
+		result.RequeueAfter = next
+	}
+	if next := config.NextMaintenanceWindowChange(mco.Spec.MaintenanceWindows, time.Now()); !deleteAll && next > 0 &&
+		(result.RequeueAfter == 0 || next < result.RequeueAfter) {
+		// pause or resume the metrics collection when a maintenance window starts or ends
+		result.RequeueAfter = next
+	}
+	return result, err
 }
 
 func createAllRelatedRes(
@@ -231,8 +308,13 @@ func createAllRelatedRes(
 		isClusterManagementAddonCreated = true
 	}
 
+	err := createCollectorTokenRes(client, mco)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	imagePullSecret := &corev1.Secret{}
-	err := client.Get(context.TODO(),
+	err = client.Get(context.TODO(),
 		types.NamespacedName{
 			Name:      mco.Spec.ImagePullSecret,
 			Namespace: request.Namespace,
@@ -254,13 +336,46 @@ func createAllRelatedRes(
 
 	failedCreateManagedClusterRes := false
 	for _, decision := range placement.Status.Decisions {
+		optedOut, err := checkAddonOptOut(client, decision.ClusterName, decision.ClusterNamespace, time.Now())
+		if err != nil {
+			failedCreateManagedClusterRes = true
+			continue
+		}
+		if optedOut {
+			// the observabilityaddon of the cluster is deleted below if it still exists
+			log.Info("Monitoring operator is opted out in cluster", "cluster_name", decision.ClusterName)
+			err = updateOptedOutStatus(client, decision.ClusterNamespace)
+			if err != nil {
+				failedCreateManagedClusterRes = true
+			}
+			continue
+		}
+		versions, err := getClusterVersions(client, decision.ClusterName)
+		if err != nil {
+			failedCreateManagedClusterRes = true
+			continue
+		}
+		if message := getUnsupportedMessage(versions); message != "" {
+			// the observabilityaddon of the cluster is deleted below if it still exists
+			log.Info("Monitoring operator is not supported in cluster", "cluster_name", decision.ClusterName,
+				"reason", message)
+			err = updateUnsupportedStatus(client, decision.ClusterNamespace, message)
+			if err != nil {
+				failedCreateManagedClusterRes = true
+			}
+			continue
+		}
 		log.Info("Monitoring operator should be installed in cluster", "cluster_name", decision.ClusterName)
+		onboarded := util.Contains(currentClusters, decision.ClusterNamespace)
 		currentClusters = util.Remove(currentClusters, decision.ClusterNamespace)
 		err = createManagedClusterRes(client, restMapper, mco, imagePullSecret,
 			decision.ClusterName, decision.ClusterNamespace)
 		if err != nil {
 			failedCreateManagedClusterRes = true
-			log.Error(err, "Failed to create managedcluster resources", "namespace", decision.ClusterNamespace)
+			errorlog.ClusterError(log, err, decision.ClusterNamespace, "Failed to create managedcluster resources")
+		} else if !onboarded {
+			notifier.notify(decision.ClusterName, ReasonClusterOnboarded,
+				"Observability is enabled on the managed cluster")
 		}
 	}
 
@@ -270,7 +385,7 @@ func createAllRelatedRes(
 		err = deleteObsAddon(client, cluster)
 		if err != nil {
 			failedDeleteOba = true
-			log.Error(err, "Failed to delete observabilityaddon", "namespace", cluster)
+			errorlog.ClusterError(log, err, cluster, "Failed to delete observabilityaddon")
 		}
 	}
 
@@ -288,7 +403,7 @@ func deleteAllObsAddons(
 	for _, ep := range obsAddonList.Items {
 		err := deleteObsAddon(client, ep.Namespace)
 		if err != nil {
-			log.Error(err, "Failed to delete observabilityaddon", "namespace", ep.Namespace)
+			errorlog.ClusterError(log, err, ep.Namespace, "Failed to delete observabilityaddon")
 			return ctrl.Result{}, err
 		}
 	}
@@ -328,6 +443,11 @@ func createManagedClusterRes(client client.Client, restMapper meta.RESTMapper,
 		return err
 	}
 
+	err = updateCollectorTokenRoleBinding(client, mco, namespace, name)
+	if err != nil {
+		return err
+	}
+
 	err = createManifestWorks(client, restMapper, namespace, name, mco, imagePullSecret)
 	if err != nil {
 		log.Error(err, "Failed to create manifestwork")
@@ -340,23 +460,64 @@ func createManagedClusterRes(client client.Client, restMapper meta.RESTMapper,
 		return err
 	}
 
+	err = createFederateCollector(client, mco, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	err = createClusterDashboard(client, mco, name, namespace)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func deleteManagedClusterRes(c client.Client, namespace string) error {
+func deleteManagedClusterRes(c client.Client, namespace string, keepStatus bool) error {
+	errorlog.Forget(namespace)
 
-	managedclusteraddon := &addonv1alpha1.ManagedClusterAddOn{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      util.ManagedClusterAddonName,
-			Namespace: namespace,
-		},
+	optedOut, unsupported := false, ""
+	if keepStatus {
+		var err error
+		optedOut, err = isClusterOptedOut(c, namespace)
+		if err != nil {
+			return err
+		}
+		versions, err := getClusterVersions(c, namespace)
+		if err != nil {
+			return err
+		}
+		unsupported = getUnsupportedMessage(versions)
 	}
-	err := c.Delete(context.TODO(), managedclusteraddon)
-	if err != nil && !k8serrors.IsNotFound(err) {
+	if optedOut {
+		err := updateOptedOutStatus(c, namespace)
+		if err != nil {
+			return err
+		}
+	} else if unsupported != "" {
+		err := updateUnsupportedStatus(c, namespace, unsupported)
+		if err != nil {
+			return err
+		}
+	} else {
+		managedclusteraddon := &addonv1alpha1.ManagedClusterAddOn{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      util.ManagedClusterAddonName,
+				Namespace: namespace,
+			},
+		}
+		err := c.Delete(context.TODO(), managedclusteraddon)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	err := deleteRolebindings(c, namespace)
+	if err != nil {
 		return err
 	}
 
-	err = deleteRolebindings(c, namespace)
+	err = deleteCollectorTokenRoleBinding(c, namespace)
 	if err != nil {
 		return err
 	}
@@ -366,29 +527,41 @@ func deleteManagedClusterRes(c client.Client, namespace string) error {
 		log.Error(err, "Failed to delete manifestwork")
 		return err
 	}
+
+	err = deleteFederateCollector(c, namespace)
+	if err != nil {
+		return err
+	}
+
+	err = deleteClusterDashboard(c, namespace)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *PlacementRuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	notifier.setRecorder(mgr.GetEventRecorderFor("multicluster-observability-operator"))
+
 	name := config.GetPlacementRuleName()
+	c := mgr.GetClient()
 	pmPred := predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
-			if e.Object.GetName() == name && e.Object.GetNamespace() == watchNamespace {
-				return true
-			}
-			return false
+			return isObservabilityPlacement(c, e.Object.GetNamespace(), e.Object.GetName(),
+				config.PlacementKindPlacementRule)
 		},
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			if e.ObjectNew.GetName() == name &&
-				e.ObjectNew.GetNamespace() == watchNamespace &&
+			if isObservabilityPlacement(c, e.ObjectNew.GetNamespace(), e.ObjectNew.GetName(),
+				config.PlacementKindPlacementRule) &&
 				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion() {
 				return true
 			}
 			return false
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
-			if e.Object.GetName() == name && e.Object.GetNamespace() == watchNamespace {
+			if isObservabilityPlacement(c, e.Object.GetNamespace(), e.Object.GetName(),
+				config.PlacementKindPlacementRule) {
 				return e.DeleteStateUnknown
 			}
 			return false
@@ -411,8 +584,12 @@ func (r *PlacementRuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			return false
 		},
 		UpdateFunc: func(e event.UpdateEvent) bool {
+			// the status of the addon is propagated by the addon status controller
 			if e.ObjectNew.GetName() == obsAddonName &&
-				e.ObjectNew.GetLabels()[ownerLabelKey] == ownerLabelValue {
+				e.ObjectNew.GetLabels()[ownerLabelKey] == ownerLabelValue &&
+				(e.ObjectNew.GetGeneration() != e.ObjectOld.GetGeneration() ||
+					e.ObjectNew.GetDeletionTimestamp() != nil ||
+					!reflect.DeepEqual(e.ObjectNew.GetFinalizers(), e.ObjectOld.GetFinalizers())) {
 				return true
 			}
 			return false
@@ -443,14 +620,98 @@ func (r *PlacementRuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	customAllowlistPred := predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
-			if e.Object.GetName() == config.AllowlistCustomConfigMapName &&
+			if isCustomAllowlist(e.Object.GetName()) &&
+				e.Object.GetNamespace() == config.GetDefaultNamespace() {
+				return true
+			}
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if isCustomAllowlist(e.ObjectNew.GetName()) &&
+				e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion() {
+				return true
+			}
+			return false
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			if isCustomAllowlist(e.Object.GetName()) &&
+				e.Object.GetNamespace() == config.GetDefaultNamespace() {
+				return true
+			}
+			return false
+		},
+	}
+
+	// the throttled metrics are updated by the FleetAnalyzer and removed from the allowlists of the clusters
+	throttlePred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return e.Object.GetName() == config.CardinalityThrottleConfigMapName &&
+				e.Object.GetNamespace() == config.GetDefaultNamespace()
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			newCM, newOK := e.ObjectNew.(*corev1.ConfigMap)
+			oldCM, oldOK := e.ObjectOld.(*corev1.ConfigMap)
+			return e.ObjectNew.GetName() == config.CardinalityThrottleConfigMapName &&
+				e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				(!newOK || !oldOK || !reflect.DeepEqual(newCM.Data, oldCM.Data))
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return e.Object.GetName() == config.CardinalityThrottleConfigMapName &&
+				e.Object.GetNamespace() == config.GetDefaultNamespace()
+		},
+	}
+
+	allowlistPred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return true
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// the updates of the status are skipped
+			return e.ObjectNew.GetGeneration() != e.ObjectOld.GetGeneration() ||
+				!reflect.DeepEqual(e.ObjectNew.GetAnnotations(), e.ObjectOld.GetAnnotations())
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return true
+		},
+	}
+
+	ksmCustomResourcePred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			if e.Object.GetName() == config.KubeStateMetricsCustomResourceConfigMapName &&
+				e.Object.GetNamespace() == config.GetDefaultNamespace() {
+				return true
+			}
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectNew.GetName() == config.KubeStateMetricsCustomResourceConfigMapName &&
+				e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion() {
+				return true
+			}
+			return false
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			if e.Object.GetName() == config.KubeStateMetricsCustomResourceConfigMapName &&
+				e.Object.GetNamespace() == config.GetDefaultNamespace() {
+				return true
+			}
+			return false
+		},
+	}
+
+	spokeRulesPred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			if e.Object.GetLabels()[config.SpokeRulesLabelKey] == "true" &&
 				e.Object.GetNamespace() == config.GetDefaultNamespace() {
 				return true
 			}
 			return false
 		},
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			if e.ObjectNew.GetName() == config.AllowlistCustomConfigMapName &&
+			if (e.ObjectNew.GetLabels()[config.SpokeRulesLabelKey] == "true" ||
+				e.ObjectOld.GetLabels()[config.SpokeRulesLabelKey] == "true") &&
 				e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
 				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion() {
 				return true
@@ -458,7 +719,7 @@ func (r *PlacementRuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			return false
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
-			if e.Object.GetName() == config.AllowlistCustomConfigMapName &&
+			if e.Object.GetLabels()[config.SpokeRulesLabelKey] == "true" &&
 				e.Object.GetNamespace() == config.GetDefaultNamespace() {
 				return true
 			}
@@ -466,27 +727,113 @@ func (r *PlacementRuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		},
 	}
 
+	endpointOverlayPred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isEndpointOverlayConfigMap(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return (isEndpointOverlayConfigMap(e.ObjectNew) || isEndpointOverlayConfigMap(e.ObjectOld)) &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion()
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isEndpointOverlayConfigMap(e.Object)
+		},
+	}
+
+	spokePatchesPred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return e.Object.GetName() == config.SpokePatchesConfigMapName &&
+				e.Object.GetNamespace() == config.GetDefaultNamespace()
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectNew.GetName() == config.SpokePatchesConfigMapName &&
+				e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion()
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return e.Object.GetName() == config.SpokePatchesConfigMapName &&
+				e.Object.GetNamespace() == config.GetDefaultNamespace()
+		},
+	}
+
 	certSecretPred := predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
-			if e.Object.GetName() == config.ServerCACerts &&
+			if (e.Object.GetName() == config.ServerCACerts ||
+				e.Object.GetName() == config.ServerIntermediateCACerts) &&
 				e.Object.GetNamespace() == config.GetDefaultNamespace() {
 				return true
 			}
+			// push the pull secret once it is synced from the external secret manager
+			if e.Object.GetNamespace() == config.GetDefaultNamespace() &&
+				config.IsExternalSecretOwned(e.Object) {
+				return true
+			}
+			if isScrapeCredentials(e.Object) {
+				return true
+			}
 			return false
 		},
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			if (e.ObjectNew.GetName() == config.ServerCACerts &&
+			if ((e.ObjectNew.GetName() == config.ServerCACerts ||
+				e.ObjectNew.GetName() == config.ServerIntermediateCACerts) &&
 				e.ObjectNew.GetNamespace() == config.GetDefaultNamespace()) &&
 				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion() {
 				return true
 			}
+			// update the hub info once the server certificate is reissued for the new endpoint or proxies
+			if e.ObjectNew.GetName() == config.ServerCerts &&
+				e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				(e.ObjectNew.GetAnnotations()[config.ObsAPIEndpointAnnotation] !=
+					e.ObjectOld.GetAnnotations()[config.ObsAPIEndpointAnnotation] ||
+					e.ObjectNew.GetAnnotations()[config.ObsAPIProxyEndpointsAnnotation] !=
+						e.ObjectOld.GetAnnotations()[config.ObsAPIProxyEndpointsAnnotation]) {
+				return true
+			}
+			if e.ObjectNew.GetNamespace() == config.GetDefaultNamespace() &&
+				config.IsExternalSecretOwned(e.ObjectNew) &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion() {
+				return true
+			}
+			if (isScrapeCredentials(e.ObjectNew) || isScrapeCredentials(e.ObjectOld)) &&
+				e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion() {
+				return true
+			}
 			return false
 		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			// push the server CA without the chain of the removed intermediate CA
+			if e.Object.GetName() == config.ServerIntermediateCACerts &&
+				e.Object.GetNamespace() == config.GetDefaultNamespace() {
+				return true
+			}
+			return isScrapeCredentials(e.Object)
+		},
+	}
+
+	obsAPIRoutePred := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return config.IsObsAPIRoute(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// push the CA of the certificate which the route is served by once its TLS config or
+			// the ingress controller which admits it changes
+			if !config.IsObsAPIRoute(e.ObjectNew) {
+				return false
+			}
+			newRoute := e.ObjectNew.(*routev1.Route)
+			oldRoute := e.ObjectOld.(*routev1.Route)
+			return !reflect.DeepEqual(newRoute.Spec.TLS, oldRoute.Spec.TLS) ||
+				!reflect.DeepEqual(newRoute.Status.Ingress, oldRoute.Status.Ingress)
+		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
 			return false
 		},
 	}
 
+	// serve the rest api of the observability for the console, every replica serves it from its own
+	// informer cache and the live queries, it keeps no state
+	mgr.GetWebhookServer().Register(config.ConsoleAPIPath+"/", newConsoleAPI(mgr.GetClient()))
+
 	ctrBuilder := ctrl.NewControllerManagedBy(mgr).
 		// Watch for changes to primary resource PlacementRule with predicate
 		For(&placementv1.PlacementRule{}, builder.WithPredicates(pmPred)).
@@ -496,8 +843,22 @@ func (r *PlacementRuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(&source.Kind{Type: &mcov1beta2.MultiClusterObservability{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(mcoPred)).
 		// secondary watch for custom allowlist configmap
 		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(customAllowlistPred)).
+		// secondary watch for the metrics allowlists
+		Watches(&source.Kind{Type: &mcov1beta2.ObservabilityMetricsAllowlist{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(allowlistPred)).
+		// secondary watch for the throttled high-cardinality metrics
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(throttlePred)).
+		// secondary watch for kube-state-metrics custom resource state configmap
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(ksmCustomResourcePred)).
+		// secondary watch for the alert rules configmaps which are pushed to the managed clusters
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(spokeRulesPred)).
+		// secondary watch for the configmaps of the patches of the endpoint manifests
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(endpointOverlayPred)).
+		// secondary watch for the configmap of the patches of the spoke manifests
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(spokePatchesPred)).
 		// secondary watch for certificate secrets
-		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(certSecretPred))
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(certSecretPred)).
+		// secondary watch for the route of the observatorium api
+		Watches(&source.Kind{Type: &routev1.Route{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(obsAPIRoutePred))
 
 	manifestWorkGroupKind := schema.GroupKind{Group: workv1.GroupVersion.Group, Kind: "ManifestWork"}
 	if _, err := r.RESTMapper.RESTMapping(manifestWorkGroupKind, workv1.GroupVersion.Version); err == nil {
@@ -524,6 +885,87 @@ func (r *PlacementRuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		ctrBuilder = ctrBuilder.Watches(&source.Kind{Type: &workv1.ManifestWork{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(workPred))
 	}
 
+	placementDecisionGroupKind := schema.GroupKind{Group: clusterv1alpha1.GroupVersion.Group, Kind: config.PlacementDecisionKind}
+	if _, err := r.RESTMapper.RESTMapping(placementDecisionGroupKind, clusterv1alpha1.GroupVersion.Version); err == nil {
+		isReferencedDecision := func(obj client.Object) bool {
+			placementName, ok := obj.GetLabels()[config.PlacementDecisionLabelKey]
+			return ok && isObservabilityPlacement(c, obj.GetNamespace(), placementName, config.PlacementKindPlacement)
+		}
+		placementDecisionPred := predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return isReferencedDecision(e.Object)
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return isReferencedDecision(e.ObjectNew) &&
+					e.ObjectNew.GetResourceVersion() != e.ObjectOld.GetResourceVersion()
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				return isReferencedDecision(e.Object)
+			},
+		}
+		placementDecision := &unstructured.Unstructured{}
+		placementDecision.SetAPIVersion(config.PlacementAPIVersion)
+		placementDecision.SetKind(config.PlacementDecisionKind)
+
+		// secondary watch for the decisions of the user-managed placement
+		ctrBuilder = ctrBuilder.Watches(&source.Kind{Type: placementDecision}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(placementDecisionPred))
+	}
+
+	clusterManagementAddonGroupKind := schema.GroupKind{Group: addonv1alpha1.GroupVersion.Group, Kind: "ClusterManagementAddOn"}
+	if _, err := r.RESTMapper.RESTMapping(clusterManagementAddonGroupKind, addonv1alpha1.GroupVersion.Version); err == nil {
+		clusterManagementAddonPred := predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return false
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				// the install strategy is in the spec, the install progress annotation is skipped
+				return e.ObjectNew.GetName() == util.ObservabilityController &&
+					e.ObjectNew.GetGeneration() != e.ObjectOld.GetGeneration()
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				return false
+			},
+		}
+
+		// secondary watch for the install strategy of the clustermanagementaddon
+		ctrBuilder = ctrBuilder.Watches(&source.Kind{Type: &addonv1alpha1.ClusterManagementAddOn{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(clusterManagementAddonPred))
+	}
+
+	managedClusterGroupKind := schema.GroupKind{Group: clusterv1.GroupVersion.Group, Kind: "ManagedCluster"}
+	if _, err := r.RESTMapper.RESTMapping(managedClusterGroupKind, clusterv1.GroupVersion.Version); err == nil {
+		clusterPred := predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return false
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				// the cluster labels may be injected as external labels into the hub info,
+				// the urls of the federate endpoint, the regional gateway and the proxy are annotations,
+				// and the observability is enabled again once the opt-out annotation is removed
+				newAnnotations, oldAnnotations := e.ObjectNew.GetAnnotations(), e.ObjectOld.GetAnnotations()
+				if !reflect.DeepEqual(e.ObjectNew.GetLabels(), e.ObjectOld.GetLabels()) ||
+					newAnnotations[config.FederateURLAnnotation] != oldAnnotations[config.FederateURLAnnotation] ||
+					newAnnotations[config.GatewayURLAnnotation] != oldAnnotations[config.GatewayURLAnnotation] ||
+					newAnnotations[config.ProxyEndpointAnnotation] != oldAnnotations[config.ProxyEndpointAnnotation] ||
+					newAnnotations[config.AddonOptedOutAnnotation] != oldAnnotations[config.AddonOptedOutAnnotation] {
+					return true
+				}
+				// the id claim of the cluster is injected as the clusterID label
+				newCluster, newOK := e.ObjectNew.(*clusterv1.ManagedCluster)
+				oldCluster, oldOK := e.ObjectOld.(*clusterv1.ManagedCluster)
+				// and the manifests are adapted to the version of the cluster
+				return newOK && oldOK &&
+					(!reflect.DeepEqual(newCluster.Status.ClusterClaims, oldCluster.Status.ClusterClaims) ||
+						newCluster.Status.Version != oldCluster.Status.Version)
+			},
+			DeleteFunc: func(e event.DeleteEvent) bool {
+				return false
+			},
+		}
+
+		// secondary watch for managedcluster
+		ctrBuilder = ctrBuilder.Watches(&source.Kind{Type: &clusterv1.ManagedCluster{}}, handler.EnqueueRequestsFromMapFunc(mapFn), builder.WithPredicates(clusterPred))
+	}
+
 	// create and return a new controller
 	return ctrBuilder.Complete(r)
 }
@@ -0,0 +1,89 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	placementv1 "github.com/open-cluster-management/multicloud-operators-placementrule/pkg/apis/apps/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/tests/perf"
+)
+
+// BenchmarkReconcile measures the latency and the API calls of the steady state
+// reconcile for the simulated fleets. It is run by make perf-tests, set
+// PERF_KUBECONFIG to run it against envtest or a kwok-backed API server.
+func BenchmarkReconcile(b *testing.B) {
+	for _, size := range []int{100, 1000, 3000} {
+		b.Run(fmt.Sprintf("clusters-%d", size), func(b *testing.B) {
+			benchmarkReconcile(b, size)
+		})
+	}
+}
+
+func benchmarkReconcile(b *testing.B, size int) {
+	s := scheme.Scheme
+	addonv1alpha1.AddToScheme(s)
+	initSchema(b)
+	config.SetMonitoringCRName(mcoName)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		b.Fatalf("Failed to get work dir: (%v)", err)
+	}
+	templatePath = path.Join(wd, "../../manifests/endpoint-observability")
+
+	fleet := perf.NewFleet(size)
+	p := &placementv1.PlacementRule{}
+	p.Name = config.GetPlacementRuleName()
+	p.Namespace = mcoNamespace
+	p.Status.Decisions = fleet.Decisions()
+	objs := []runtime.Object{p, newTestMCO(), newTestPullSecret(), newTestRoute(), newCASecret(),
+		newCertSecret(mcoNamespace), NewMetricsAllowListCM(), newManagedClusterAddon()}
+	objs = append(objs, fleet.Objects()...)
+	c, err := perf.NewClient(s, objs...)
+	if err != nil {
+		b.Fatalf("Failed to create the simulated fleet: (%v)", err)
+	}
+	counting := perf.NewCountingClient(c)
+
+	r := &PlacementRuleReconciler{Client: counting, Scheme: s, APIReader: counting}
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      p.Name,
+			Namespace: mcoNamespace,
+		},
+	}
+	// the first reconcile creates the resources for all the clusters
+	_, err = r.Reconcile(context.TODO(), req)
+	if err != nil {
+		b.Fatalf("reconcile: (%v)", err)
+	}
+
+	counting.Reset()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err = r.Reconcile(context.TODO(), req)
+		if err != nil {
+			b.Fatalf("reconcile: (%v)", err)
+		}
+	}
+	b.StopTimer()
+
+	counts := counting.Counts()
+	b.ReportMetric(float64(counting.Total())/float64(b.N), "apicalls/op")
+	for _, verb := range counting.Verbs() {
+		b.ReportMetric(float64(counts[verb])/float64(b.N), verb+"/op")
+	}
+}
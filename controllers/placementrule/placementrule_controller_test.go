@@ -8,12 +8,14 @@ import (
 	"testing"
 
 	cert "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	obsv1alpha1 "github.com/open-cluster-management/observatorium-operator/api/v1alpha1"
 	ocinfrav1 "github.com/openshift/api/config/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -21,6 +23,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	clusterv1alpha1 "github.com/open-cluster-management/api/cluster/v1alpha1"
 	workv1 "github.com/open-cluster-management/api/work/v1"
 	placementv1 "github.com/open-cluster-management/multicloud-operators-placementrule/pkg/apis/apps/v1"
 	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
@@ -40,7 +44,7 @@ var (
 	mcoNamespace = config.GetDefaultNamespace()
 )
 
-func initSchema(t *testing.T) {
+func initSchema(t testing.TB) {
 	s := scheme.Scheme
 	if err := placementv1.AddToScheme(s); err != nil {
 		t.Fatalf("Unable to add placementrule scheme: (%v)", err)
@@ -63,6 +67,23 @@ func initSchema(t *testing.T) {
 	if err := cert.AddToScheme(s); err != nil {
 		t.Fatalf("Unable to add cert scheme: (%v)", err)
 	}
+	if err := clusterv1.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add clusterv1 scheme: (%v)", err)
+	}
+	if err := clusterv1alpha1.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add clusterv1alpha1 scheme: (%v)", err)
+	}
+	// the Placement APIs are not in the vendored OCM cluster API yet, they are read as unstructured
+	for _, kind := range []string{config.PlacementKindPlacement, config.PlacementDecisionKind} {
+		s.AddKnownTypeWithName(clusterv1alpha1.GroupVersion.WithKind(kind), &unstructured.Unstructured{})
+		s.AddKnownTypeWithName(clusterv1alpha1.GroupVersion.WithKind(kind+"List"), &unstructured.UnstructuredList{})
+	}
+	if err := addonv1alpha1.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add addonv1alpha1 scheme: (%v)", err)
+	}
+	if err := obsv1alpha1.AddToScheme(s); err != nil {
+		t.Fatalf("Unable to add obsv1alpha1 scheme: (%v)", err)
+	}
 }
 
 func TestObservabilityAddonController(t *testing.T) {
@@ -105,7 +126,7 @@ func TestObservabilityAddonController(t *testing.T) {
 		newManagedClusterAddon(), deprecatedRole}
 	c := fake.NewFakeClient(objs...)
 
-	r := &PlacementRuleReconciler{Client: c, Scheme: s}
+	r := &PlacementRuleReconciler{Client: c, Scheme: s, APIReader: c}
 	req := ctrl.Request{
 		NamespacedName: types.NamespacedName{
 			Name:      placementRuleName,
@@ -0,0 +1,67 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	v1 "k8s.io/api/apps/v1"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func getEndpointOperatorDeployment(t *testing.T, mco *mcov1beta2.MultiClusterObservability) *v1.Deployment {
+	templates, err := loadTemplates(namespace, endpointOverlayOCP, nil, mco)
+	if err != nil {
+		t.Fatalf("Failed to load templates: (%v)", err)
+	}
+	for _, raw := range templates {
+		if dep, ok := raw.Object.(*v1.Deployment); ok && dep.Name == deployName {
+			return dep
+		}
+	}
+	t.Fatalf("The endpoint operator deployment is not found")
+	return nil
+}
+
+func TestAddonPriorityClass(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get work dir: (%v)", err)
+	}
+	templatePath = path.Join(wd, "../../manifests/endpoint-observability")
+
+	mco := newTestMCO()
+	if config.NewAddonPriorityClass(mco) != nil {
+		t.Errorf("No priority class should be pushed when it is not assigned")
+	}
+	if dep := getEndpointOperatorDeployment(t, mco); dep.Spec.Template.Spec.PriorityClassName != "" {
+		t.Errorf("No priority class should be assigned: %s", dep.Spec.Template.Spec.PriorityClassName)
+	}
+
+	mco.Spec.PriorityClasses = &mcov1beta2.PriorityClassesSpec{Addon: &mcov1beta2.PriorityClassSpec{}}
+	priorityClass := config.NewAddonPriorityClass(mco)
+	if priorityClass == nil || priorityClass.Name != config.AddonPriorityClassName ||
+		priorityClass.Value != config.DefaultPriorityClassValue {
+		t.Errorf("The priority class of the addon should be pushed: %v", priorityClass)
+	}
+	dep := getEndpointOperatorDeployment(t, mco)
+	if dep.Spec.Template.Spec.PriorityClassName != config.AddonPriorityClassName {
+		t.Errorf("The endpoint operator should run with the priority class: %s",
+			dep.Spec.Template.Spec.PriorityClassName)
+	}
+
+	mco.Spec.PriorityClasses.Addon.Name = "system-cluster-critical"
+	if config.NewAddonPriorityClass(mco) != nil {
+		t.Errorf("No priority class should be pushed when an existing one is used")
+	}
+	dep = getEndpointOperatorDeployment(t, mco)
+	if dep.Spec.Template.Spec.PriorityClassName != "system-cluster-critical" {
+		t.Errorf("The endpoint operator should run with the existing priority class: %s",
+			dep.Spec.Template.Spec.PriorityClassName)
+	}
+}
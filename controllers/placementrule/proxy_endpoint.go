@@ -0,0 +1,61 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+)
+
+// getProxyEndpoint returns the cluster-proxy or the secure gateway endpoint which the managed cluster
+// reaches the observatorium api through. It is empty until the server certificate is reissued for the
+// host of the proxy, so the managed cluster keeps writing to the hub directly in the meantime.
+func getProxyEndpoint(c client.Client, obsNamespace string, clusterName string,
+	mco *mcov1beta2.MultiClusterObservability) (string, error) {
+	if config.IsExternalMetricsStoreEnabled(mco) {
+		return "", nil
+	}
+	cluster := &clusterv1.ManagedCluster{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", nil
+		}
+		errorlog.ClusterError(log, err, clusterName, "Failed to get managedcluster")
+		return "", err
+	}
+	endpoint := strings.TrimSpace(cluster.GetAnnotations()[config.ProxyEndpointAnnotation])
+	if endpoint == "" {
+		return "", nil
+	}
+	covered, err := config.IsProxyEndpointCovered(c, obsNamespace, endpoint)
+	if err != nil {
+		errorlog.ClusterError(log, err, clusterName, "Failed to check the server certificate for the proxy endpoint")
+		return "", err
+	}
+	if !covered {
+		log.Info("Writing to the hub directly until the server certificate covers the proxy endpoint",
+			"cluster", clusterName, "endpoint", endpoint)
+		return "", nil
+	}
+	return endpoint, nil
+}
+
+// getProxyMetricsEndpoint returns the remote write endpoint of the observatorium api through the proxy
+func getProxyMetricsEndpoint(proxyEndpoint string) string {
+	url := strings.TrimSuffix(proxyEndpoint, "/")
+	if !strings.HasPrefix(url, "http") {
+		url = protocol + url
+	}
+	return url + urlSubPath
+}
@@ -17,6 +17,7 @@ import (
 
 	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
 )
 
 const (
@@ -252,12 +253,12 @@ func createResourceRoleBinding(c client.Client, namespace string, name string) e
 		log.Info("Creating endpoint-observability-res-rolebinding rolebinding", "namespace", namespace)
 		err = c.Create(context.TODO(), rb)
 		if err != nil {
-			log.Error(err, "Failed to create endpoint-observability-res-rolebinding rolebinding", "namespace", namespace)
+			errorlog.ClusterError(log, err, namespace, "Failed to create endpoint-observability-res-rolebinding rolebinding")
 			return err
 		}
 		return nil
 	} else if err != nil {
-		log.Error(err, "Failed to check endpoint-observability-res-rolebinding rolebinding", "namespace", namespace)
+		errorlog.ClusterError(log, err, namespace, "Failed to check endpoint-observability-res-rolebinding rolebinding")
 		return err
 	}
 
@@ -266,7 +267,7 @@ func createResourceRoleBinding(c client.Client, namespace string, name string) e
 		rb.ObjectMeta.ResourceVersion = found.ObjectMeta.ResourceVersion
 		err = c.Update(context.TODO(), rb)
 		if err != nil {
-			log.Error(err, "Failed to update endpoint-observability-res-rolebinding rolebinding", "namespace", namespace)
+			errorlog.ClusterError(log, err, namespace, "Failed to update endpoint-observability-res-rolebinding rolebinding")
 			return err
 		}
 		return nil
@@ -327,7 +328,7 @@ func deleteRolebindings(c client.Client, namespace string) error {
 	}
 	err = c.Delete(context.TODO(), rb)
 	if err != nil && !errors.IsNotFound(err) {
-		log.Error(err, "Failed to delete rolebinding", "name", resRoleBindingName, "namespace", namespace)
+		errorlog.ClusterError(log, err, namespace, "Failed to delete rolebinding", "name", resRoleBindingName)
 		return err
 	}
 	log.Info("Rolebinding deleted", "name", resRoleBindingName, "namespace", namespace)
@@ -0,0 +1,216 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+)
+
+const (
+	etcdPort = "2379"
+)
+
+// ScrapeConfig is the prometheus scrape config of a target which the collector scrapes directly
+type ScrapeConfig struct {
+	JobName             string              `yaml:"job_name"`
+	Scheme              string              `yaml:"scheme"`
+	MetricsPath         string              `yaml:"metrics_path,omitempty"`
+	KubernetesSDConfigs []map[string]string `yaml:"kubernetes_sd_configs"`
+	TLSConfig           map[string]string   `yaml:"tls_config,omitempty"`
+	RelabelConfigs      []RelabelConfig     `yaml:"relabel_configs,omitempty"`
+}
+
+// RelabelConfig is the prometheus relabel config of the targets
+type RelabelConfig struct {
+	SourceLabels []string `yaml:"source_labels,omitempty"`
+	Regex        string   `yaml:"regex,omitempty"`
+	TargetLabel  string   `yaml:"target_label,omitempty"`
+	Replacement  string   `yaml:"replacement,omitempty"`
+	Action       string   `yaml:"action,omitempty"`
+}
+
+// getScrapeCredentials returns the copies of the scrape credentials secrets for the platform of the
+// managed cluster, and the scrape configs of their targets. The first secret by name is used when
+// multiple secrets of the same target are selected.
+func getScrapeCredentials(c client.Client, clusterName string) ([]*corev1.Secret, []ScrapeConfig, error) {
+	secrets := &corev1.SecretList{}
+	err := c.List(context.TODO(), secrets, client.InNamespace(config.GetDefaultNamespace()),
+		client.HasLabels{config.ScrapeCredentialsLabelKey})
+	if err != nil {
+		log.Error(err, "Failed to list the scrape credentials secrets")
+		return nil, nil, err
+	}
+	if len(secrets.Items) == 0 {
+		return nil, nil, nil
+	}
+	sort.Slice(secrets.Items, func(i, j int) bool { return secrets.Items[i].Name < secrets.Items[j].Name })
+
+	platform := ""
+	cluster := &clusterv1.ManagedCluster{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+	if err == nil {
+		platform = cluster.GetLabels()[config.ClusterPlatformLabelKey]
+	} else if !k8serrors.IsNotFound(err) {
+		errorlog.ClusterError(log, err, clusterName, "Failed to get managedcluster")
+		return nil, nil, err
+	}
+
+	spokeSecrets := []*corev1.Secret{}
+	scrapeConfigs := []ScrapeConfig{}
+	for _, target := range []string{config.ScrapeTargetEtcd, config.ScrapeTargetKubelet} {
+		for i := range secrets.Items {
+			secret := &secrets.Items[i]
+			if secret.Labels[config.ScrapeCredentialsLabelKey] != target ||
+				!isScrapeCredentialsSelected(secret, platform) {
+				continue
+			}
+			if len(secret.Data["ca.crt"]) == 0 || len(secret.Data["tls.crt"]) == 0 ||
+				len(secret.Data["tls.key"]) == 0 {
+				log.Info("The scrape credentials secret requires ca.crt, tls.crt and tls.key, skip it",
+					"name", secret.Name)
+				continue
+			}
+			spokeSecrets = append(spokeSecrets, newSpokeScrapeCredentialsSecret(secret, target))
+			scrapeConfigs = append(scrapeConfigs, newScrapeConfigs(target)...)
+			break
+		}
+	}
+	return spokeSecrets, scrapeConfigs, nil
+}
+
+// newScrapeConfigCM returns the configmap of the scrape configs which the collector merges into
+// its own scrape config
+func newScrapeConfigCM(scrapeConfigs []ScrapeConfig) (*corev1.ConfigMap, error) {
+	data, err := yaml.Marshal(map[string][]ScrapeConfig{"scrape_configs": scrapeConfigs})
+	if err != nil {
+		log.Error(err, "Failed to marshal the scrape config")
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.ScrapeConfigConfigMapName,
+			Namespace: spokeNameSpace,
+		},
+		Data: map[string]string{
+			config.ScrapeConfigFileKey: string(data),
+		},
+	}, nil
+}
+
+// isScrapeCredentials returns true if the object is a scrape credentials secret on the hub
+func isScrapeCredentials(obj client.Object) bool {
+	_, found := obj.GetLabels()[config.ScrapeCredentialsLabelKey]
+	return found && obj.GetNamespace() == config.GetDefaultNamespace()
+}
+
+// isScrapeCredentialsSelected returns true if the secret is not scoped to any platforms,
+// or the platform of the managed cluster is one of its platforms
+func isScrapeCredentialsSelected(secret *corev1.Secret, platform string) bool {
+	platforms := strings.TrimSpace(secret.Annotations[config.ScrapeCredentialsPlatformsAnnotation])
+	if platforms == "" {
+		return true
+	}
+	for _, p := range strings.Split(platforms, ",") {
+		if platform != "" && strings.EqualFold(strings.TrimSpace(p), platform) {
+			return true
+		}
+	}
+	return false
+}
+
+func getScrapeCredentialsSecretName(target string) string {
+	return "observability-" + target + "-scrape-certs"
+}
+
+// newSpokeScrapeCredentialsSecret returns the copy of the client certificates for the managed
+// cluster, the endpoint operator mounts it into the collector under the mount path of the target
+func newSpokeScrapeCredentialsSecret(secret *corev1.Secret, target string) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getScrapeCredentialsSecretName(target),
+			Namespace: spokeNameSpace,
+			Labels:    map[string]string{config.ScrapeCredentialsLabelKey: target},
+		},
+		Data: map[string][]byte{
+			"ca.crt":  secret.Data["ca.crt"],
+			"tls.crt": secret.Data["tls.crt"],
+			"tls.key": secret.Data["tls.key"],
+		},
+	}
+}
+
+// newScrapeConfigs returns the scrape configs of the target with the certificates mounted from
+// its secret. etcd is scraped on the control plane nodes, kubelet and cAdvisor on all the nodes.
+func newScrapeConfigs(target string) []ScrapeConfig {
+	certsPath := config.ScrapeCredentialsMountPath + "/" + target
+	tlsConfig := map[string]string{
+		"ca_file":   certsPath + "/ca.crt",
+		"cert_file": certsPath + "/tls.crt",
+		"key_file":  certsPath + "/tls.key",
+	}
+	nodeSD := []map[string]string{{"role": "node"}}
+
+	if target == config.ScrapeTargetEtcd {
+		return []ScrapeConfig{
+			{
+				JobName:             "etcd",
+				Scheme:              "https",
+				KubernetesSDConfigs: nodeSD,
+				TLSConfig:           tlsConfig,
+				RelabelConfigs: []RelabelConfig{
+					{
+						SourceLabels: []string{
+							"__meta_kubernetes_node_labelpresent_node_role_kubernetes_io_master",
+							"__meta_kubernetes_node_labelpresent_node_role_kubernetes_io_control_plane",
+						},
+						Regex:  ".*true.*",
+						Action: "keep",
+					},
+					{
+						SourceLabels: []string{"__address__"},
+						Regex:        `([^:]+)(?::\d+)?`,
+						TargetLabel:  "__address__",
+						Replacement:  "${1}:" + etcdPort,
+					},
+				},
+			},
+		}
+	}
+	return []ScrapeConfig{
+		{
+			JobName:             "kubelet",
+			Scheme:              "https",
+			KubernetesSDConfigs: nodeSD,
+			TLSConfig:           tlsConfig,
+		},
+		{
+			JobName:             "kubelet-cadvisor",
+			Scheme:              "https",
+			MetricsPath:         "/metrics/cadvisor",
+			KubernetesSDConfigs: nodeSD,
+			TLSConfig:           tlsConfig,
+		},
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func newScrapeCredentialsSecret(name, target, platforms string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: mcoNamespace,
+			Labels:    map[string]string{config.ScrapeCredentialsLabelKey: target},
+		},
+		Data: map[string][]byte{
+			"ca.crt":  []byte(name + "-ca"),
+			"tls.crt": []byte(name + "-crt"),
+			"tls.key": []byte(name + "-key"),
+		},
+	}
+	if platforms != "" {
+		secret.Annotations = map[string]string{config.ScrapeCredentialsPlatformsAnnotation: platforms}
+	}
+	return secret
+}
+
+func TestGetScrapeCredentials(t *testing.T) {
+	initSchema(t)
+
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   clusterName,
+			Labels: map[string]string{config.ClusterPlatformLabelKey: "BareMetal"},
+		},
+	}
+	invalid := newScrapeCredentialsSecret("a-invalid", config.ScrapeTargetKubelet, "")
+	delete(invalid.Data, "tls.key")
+	objs := []runtime.Object{
+		cluster,
+		invalid,
+		newScrapeCredentialsSecret("etcd-baremetal", config.ScrapeTargetEtcd, "VSphere, baremetal"),
+		newScrapeCredentialsSecret("etcd-vsphere", config.ScrapeTargetEtcd, "VSphere"),
+		newScrapeCredentialsSecret("kubelet", config.ScrapeTargetKubelet, ""),
+	}
+	c := fake.NewFakeClient(objs...)
+
+	secrets, scrapeConfigs, err := getScrapeCredentials(c, clusterName)
+	if err != nil {
+		t.Fatalf("Failed to get the scrape credentials: (%v)", err)
+	}
+	if len(secrets) != 2 {
+		t.Fatalf("Wrong number of the scrape credentials: %d", len(secrets))
+	}
+	if secrets[0].Name != "observability-etcd-scrape-certs" || string(secrets[0].Data["ca.crt"]) != "etcd-baremetal-ca" {
+		t.Errorf("The etcd credentials of the platform should be pushed: %v", secrets[0])
+	}
+	if secrets[1].Name != "observability-kubelet-scrape-certs" || string(secrets[1].Data["tls.key"]) != "kubelet-key" {
+		t.Errorf("The valid kubelet credentials should be pushed: %v", secrets[1])
+	}
+	scrapeConfig, err := newScrapeConfigCM(scrapeConfigs)
+	if err != nil || scrapeConfig.Namespace != spokeNameSpace {
+		t.Fatalf("Failed to generate the scrape config: %v (%v)", scrapeConfig, err)
+	}
+	data := scrapeConfig.Data[config.ScrapeConfigFileKey]
+	for _, s := range []string{"job_name: etcd", "job_name: kubelet", "/metrics/cadvisor",
+		config.ScrapeCredentialsMountPath + "/etcd/tls.crt", "${1}:2379"} {
+		if !strings.Contains(data, s) {
+			t.Errorf("The scrape config should contain %s: %s", s, data)
+		}
+	}
+
+	// the cluster without platform only gets the credentials for all the platforms
+	secrets, scrapeConfigs, err = getScrapeCredentials(c, "cluster2")
+	if err != nil {
+		t.Fatalf("Failed to get the scrape credentials: (%v)", err)
+	}
+	if len(secrets) != 1 || secrets[0].Name != "observability-kubelet-scrape-certs" {
+		t.Fatalf("Wrong scrape credentials for the cluster without platform: %v", secrets)
+	}
+	if len(scrapeConfigs) != 2 || scrapeConfigs[0].JobName != "kubelet" {
+		t.Errorf("etcd should not be scraped without the credentials: %v", scrapeConfigs)
+	}
+}
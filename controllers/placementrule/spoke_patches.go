@@ -0,0 +1,75 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+// getSpokePatches returns the patches of the spoke patches configmap in the order of its keys, and
+// the hash of the patches to track their change
+func getSpokePatches(c client.Client) ([]util.ManifestPatch, string, error) {
+	return util.GetManifestPatches(c, config.SpokePatchesConfigMapName, config.GetDefaultNamespace())
+}
+
+// applySpokePatches applies the patches to the manifests of their kind and name, the patches which
+// cannot be applied are skipped so that they do not block the updates of the manifestwork
+func applySpokePatches(manifests []workv1.Manifest, patches []util.ManifestPatch) []workv1.Manifest {
+	if len(patches) == 0 {
+		return manifests
+	}
+	for i, manifest := range manifests {
+		raw := manifest.Raw
+		if raw == nil && manifest.Object != nil {
+			var err error
+			raw, err = json.Marshal(manifest.Object)
+			if err != nil {
+				continue
+			}
+		}
+		meta := &unstructured.Unstructured{}
+		if err := meta.UnmarshalJSON(raw); err != nil {
+			continue
+		}
+
+		patched := raw
+		for _, patch := range patches {
+			if patch.Kind != meta.GetKind() || patch.Name != meta.GetName() {
+				continue
+			}
+			doc, err := util.ApplyManifestPatch(patched, patch)
+			if err != nil {
+				log.Error(err, "Failed to apply the spoke patch, skip it", "kind", patch.Kind, "name", patch.Name)
+				continue
+			}
+			patched = doc
+		}
+		if reflect.DeepEqual(patched, raw) {
+			continue
+		}
+
+		if manifest.Object == nil {
+			manifests[i] = workv1.Manifest{RawExtension: runtime.RawExtension{Raw: patched}}
+			continue
+		}
+		// keep the type of the object so that the manifest is compared with the found one by its kind
+		obj := reflect.New(reflect.TypeOf(manifest.Object).Elem()).Interface().(runtime.Object)
+		if err := json.Unmarshal(patched, obj); err != nil {
+			log.Error(err, "Failed to decode the patched manifest, skip the patches", "kind", meta.GetKind(),
+				"name", meta.GetName())
+			continue
+		}
+		manifests[i] = workv1.Manifest{RawExtension: runtime.RawExtension{Object: obj}}
+	}
+	return manifests
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestSpokePatches(t *testing.T) {
+	initSchema(t)
+
+	patches := `
+- kind: Deployment
+  name: endpoint-observability-operator
+  patch: |
+    spec:
+      template:
+        metadata:
+          labels:
+            policy.example.com/team: observability
+        spec:
+          containers:
+          - name: sidecar
+            image: quay.io/example/sidecar:1.0
+- kind: ConfigMap
+  name: observability-metrics-allowlist
+  type: json
+  patch: |
+    - op: add
+      path: /metadata/annotations
+      value:
+        policy.example.com/owner: observability
+- kind: Secret
+  name: missing
+  patch: |
+    metadata:
+      labels:
+        foo: bar
+- kind: Deployment
+  name: endpoint-observability-operator
+  type: unknown
+  patch: |
+    metadata: {}
+`
+	c := fake.NewFakeClient(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: config.SpokePatchesConfigMapName, Namespace: mcoNamespace},
+		Data: map[string]string{
+			"patches.yaml": patches,
+			"invalid.yaml": "not a list",
+		},
+	})
+	spokePatches, hash, err := getSpokePatches(c)
+	if err != nil {
+		t.Fatalf("Failed to get the spoke patches: (%v)", err)
+	}
+	if len(spokePatches) != 4 || hash == "" {
+		t.Fatalf("unexpected spoke patches %v with hash %s", spokePatches, hash)
+	}
+
+	dep := &v1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: deployName, Namespace: spokeNameSpace},
+		Spec: v1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "endpoint-observability-operator", Image: "operator"}},
+				},
+			},
+		},
+	}
+	cm := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "observability-metrics-allowlist", Namespace: spokeNameSpace},
+	}
+	sa := &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+		ObjectMeta: metav1.ObjectMeta{Name: saName, Namespace: spokeNameSpace},
+	}
+	manifests := []workv1.Manifest{
+		{RawExtension: runtime.RawExtension{Object: dep}},
+		{RawExtension: runtime.RawExtension{Object: cm}},
+		{RawExtension: runtime.RawExtension{Object: sa}},
+	}
+	manifests = applySpokePatches(manifests, spokePatches)
+
+	patchedDep, ok := manifests[0].Object.(*v1.Deployment)
+	if !ok {
+		t.Fatalf("the patched deployment should keep its type: %T", manifests[0].Object)
+	}
+	if patchedDep.Spec.Template.Labels["policy.example.com/team"] != "observability" {
+		t.Errorf("the label is not injected: %v", patchedDep.Spec.Template.Labels)
+	}
+	if len(patchedDep.Spec.Template.Spec.Containers) != 2 {
+		t.Errorf("the sidecar should be merged into the containers: %v", patchedDep.Spec.Template.Spec.Containers)
+	}
+	patchedCM := manifests[1].Object.(*corev1.ConfigMap)
+	if patchedCM.Annotations["policy.example.com/owner"] != "observability" {
+		t.Errorf("the json patch is not applied: %v", patchedCM.Annotations)
+	}
+	if manifests[2].Object != sa {
+		t.Errorf("the manifest without any patch should not be changed")
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+)
+
+const (
+	spokeRulesNamePrefix = "observability-"
+)
+
+// getSpokeRules returns the PrometheusRules of the hub rules configmaps which are pushed down to the
+// managed cluster. A configmap is pushed to the clusters in the cluster sets of its annotation, or to
+// all the clusters if it is not annotated.
+func getSpokeRules(c client.Client, clusterName string) ([]*unstructured.Unstructured, error) {
+	cms := &corev1.ConfigMapList{}
+	err := c.List(context.TODO(), cms, client.InNamespace(config.GetDefaultNamespace()),
+		client.MatchingLabels{config.SpokeRulesLabelKey: "true"})
+	if err != nil {
+		log.Error(err, "Failed to list the spoke rules configmaps")
+		return nil, err
+	}
+	if len(cms.Items) == 0 {
+		return nil, nil
+	}
+	sort.Slice(cms.Items, func(i, j int) bool { return cms.Items[i].Name < cms.Items[j].Name })
+
+	clusterSet := ""
+	cluster := &clusterv1.ManagedCluster{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+	if err == nil {
+		clusterSet = cluster.GetLabels()[config.ClusterSetLabelKey]
+	} else if !k8serrors.IsNotFound(err) {
+		errorlog.ClusterError(log, err, clusterName, "Failed to get managedcluster")
+		return nil, err
+	}
+
+	rules := []*unstructured.Unstructured{}
+	for i := range cms.Items {
+		cm := &cms.Items[i]
+		if !isSpokeRulesSelected(cm, clusterSet) {
+			continue
+		}
+		if spokeRulesNamePrefix+cm.Name == config.WatchdogRuleName {
+			log.Info("The spoke rules configmap conflicts with the watchdog rule, skip it", "name", cm.Name)
+			continue
+		}
+		rule := newSpokeRule(cm)
+		if rule != nil {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// isSpokeRulesSelected returns true if the configmap is not scoped to any cluster sets,
+// or the cluster set is one of its cluster sets
+func isSpokeRulesSelected(cm *corev1.ConfigMap, clusterSet string) bool {
+	sets := strings.TrimSpace(cm.Annotations[config.SpokeRulesClusterSetsAnnotation])
+	if sets == "" {
+		return true
+	}
+	for _, set := range strings.Split(sets, ",") {
+		if clusterSet != "" && strings.TrimSpace(set) == clusterSet {
+			return true
+		}
+	}
+	return false
+}
+
+// newSpokeRule merges the rule groups in all the keys of the configmap into a PrometheusRule,
+// it returns nil if there is no valid rule group so that a bad configmap is not pushed
+func newSpokeRule(cm *corev1.ConfigMap) *unstructured.Unstructured {
+	keys := []string{}
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	groups := []interface{}{}
+	for _, key := range keys {
+		ruleGroups, err := parseSpokeRuleGroups(cm.Data[key])
+		if err != nil {
+			log.Error(err, "Invalid rules in spoke rules configmap, skip it", "name", cm.Name, "key", key)
+			continue
+		}
+		groups = append(groups, ruleGroups...)
+	}
+	if len(groups) == 0 {
+		log.Info("No rule groups found in spoke rules configmap", "name", cm.Name)
+		return nil
+	}
+
+	return newPrometheusRule(spokeRulesNamePrefix+cm.Name, groups)
+}
+
+// parseSpokeRuleGroups returns the rule groups in the rule file of the spoke rules configmap
+func parseSpokeRuleGroups(data string) ([]interface{}, error) {
+	ruleFile := &struct {
+		Groups []interface{} `json:"groups"`
+	}{}
+	err := yaml.Unmarshal([]byte(data), ruleFile)
+	if err != nil {
+		return nil, err
+	}
+	return ruleFile.Groups, nil
+}
+
+// newWatchdogRule returns the PrometheusRule of the watchdog alert which always fires, it is
+// forwarded to the hub to verify the alerting pipeline from the managed cluster
+func newWatchdogRule() *unstructured.Unstructured {
+	return newPrometheusRule(config.WatchdogRuleName, []interface{}{
+		map[string]interface{}{
+			"name": "observability-watchdog",
+			"rules": []interface{}{
+				map[string]interface{}{
+					"alert": config.WatchdogAlertName,
+					"expr":  "vector(1)",
+					"labels": map[string]interface{}{
+						"severity": "none",
+					},
+					"annotations": map[string]interface{}{
+						"summary": "The watchdog alert of the alerting self test, it always fires.",
+					},
+				},
+			},
+		},
+	})
+}
+
+func newPrometheusRule(name string, groups []interface{}) *unstructured.Unstructured {
+	rule := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"groups": groups,
+			},
+		},
+	}
+	rule.SetAPIVersion("monitoring.coreos.com/v1")
+	rule.SetKind("PrometheusRule")
+	rule.SetName(name)
+	rule.SetNamespace(spokeNameSpace)
+	return rule
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+func newSpokeRulesCM(name string, clusterSets string, data map[string]string) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: mcoNamespace,
+			Labels:    map[string]string{config.SpokeRulesLabelKey: "true"},
+		},
+		Data: data,
+	}
+	if clusterSets != "" {
+		cm.Annotations = map[string]string{config.SpokeRulesClusterSetsAnnotation: clusterSets}
+	}
+	return cm
+}
+
+func TestGetSpokeRules(t *testing.T) {
+	initSchema(t)
+
+	rules := `
+groups:
+- name: test
+  rules:
+  - alert: Test
+    expr: up == 0
+    for: 5m
+    labels:
+      severity: warning
+`
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   clusterName,
+			Labels: map[string]string{config.ClusterSetLabelKey: "team-a"},
+		},
+	}
+	unlabelled := newSpokeRulesCM("unlabelled", "", map[string]string{"rules.yaml": rules})
+	unlabelled.Labels = nil
+	objs := []runtime.Object{
+		cluster,
+		unlabelled,
+		newSpokeRulesCM("all", "", map[string]string{"a.yaml": rules, "b.yaml": rules}),
+		newSpokeRulesCM("team-a", "team-b, team-a", map[string]string{"rules.yaml": rules}),
+		newSpokeRulesCM("team-b", "team-b", map[string]string{"rules.yaml": rules}),
+		newSpokeRulesCM("invalid", "", map[string]string{"rules.yaml": "groups: invalid"}),
+	}
+	c := fake.NewFakeClient(objs...)
+
+	spokeRules, err := getSpokeRules(c, clusterName)
+	if err != nil {
+		t.Fatalf("Failed to get the spoke rules: (%v)", err)
+	}
+	if len(spokeRules) != 2 {
+		t.Fatalf("Wrong number of the spoke rules: %d", len(spokeRules))
+	}
+	if spokeRules[0].GetName() != "observability-all" || spokeRules[1].GetName() != "observability-team-a" {
+		t.Fatalf("Wrong spoke rules: %s, %s", spokeRules[0].GetName(), spokeRules[1].GetName())
+	}
+	if spokeRules[0].GetKind() != "PrometheusRule" || spokeRules[0].GetNamespace() != spokeNameSpace {
+		t.Fatalf("Wrong kind or namespace of the spoke rule: %v", spokeRules[0])
+	}
+	groups := spokeRules[0].Object["spec"].(map[string]interface{})["groups"].([]interface{})
+	if len(groups) != 2 {
+		t.Fatalf("The rule groups of all the keys should be merged: %v", groups)
+	}
+
+	// the rules are not changed once they are pushed in the manifestwork
+	raw, err := spokeRules[0].MarshalJSON()
+	if err != nil {
+		t.Fatalf("Failed to marshal the spoke rule: (%v)", err)
+	}
+	if !util.CompareObject(runtime.RawExtension{Raw: raw}, runtime.RawExtension{Object: spokeRules[0]}) {
+		t.Fatalf("The pushed spoke rule should be unchanged")
+	}
+
+	// the cluster without cluster set only gets the rules for all the clusters
+	spokeRules, err = getSpokeRules(c, "cluster2")
+	if err != nil {
+		t.Fatalf("Failed to get the spoke rules: (%v)", err)
+	}
+	if len(spokeRules) != 1 || spokeRules[0].GetName() != "observability-all" {
+		t.Fatalf("Wrong spoke rules for the cluster without cluster set: %v", spokeRules)
+	}
+}
+
+func TestNewWatchdogRule(t *testing.T) {
+	rule := newWatchdogRule()
+	if rule.GetName() != config.WatchdogRuleName || rule.GetNamespace() != spokeNameSpace {
+		t.Fatalf("Wrong name or namespace of the watchdog rule: %v", rule)
+	}
+	groups := rule.Object["spec"].(map[string]interface{})["groups"].([]interface{})
+	rules := groups[0].(map[string]interface{})["rules"].([]interface{})
+	if rules[0].(map[string]interface{})["alert"] != config.WatchdogAlertName {
+		t.Fatalf("Wrong alert of the watchdog rule: %v", rules)
+	}
+}
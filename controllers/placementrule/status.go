@@ -8,12 +8,15 @@ import (
 	"reflect"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
 	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
 )
 
@@ -28,22 +31,20 @@ var (
 	}
 )
 
-func updateAddonStatus(c client.Client, addonList mcov1beta1.ObservabilityAddonList) error {
-	for _, addon := range addonList.Items {
-		if addon.Status.Conditions == nil || len(addon.Status.Conditions) == 0 {
-			continue
-		}
-		conditions := []metav1.Condition{}
-		for _, c := range addon.Status.Conditions {
-			condition := metav1.Condition{
-				Type:               statusMap[c.Type],
-				Status:             c.Status,
-				LastTransitionTime: c.LastTransitionTime,
-				Reason:             c.Reason,
-				Message:            c.Message,
-			}
-			conditions = append(conditions, condition)
-		}
+// updateAddonStatus updates the conditions of the managedclusteraddon from the observabilityaddon, the
+// conditions which the hub detects for the cluster, e.g. the manifestwork which is not applied, are added
+// to the addon. The status is patched with the optimistic lock and retried on the conflicts. It returns
+// Available, Progressing or Degraded as the status of the addon.
+func updateAddonStatus(c client.Client, addon *mcov1beta1.ObservabilityAddon,
+	hubConditions []metav1.Condition) (string, error) {
+	if len(addon.Status.Conditions) == 0 && len(hubConditions) == 0 {
+		return "Progressing", nil
+	}
+	status := getAddonStatus(append(newAddonConditions(*addon), hubConditions...))
+	updated := false
+	var notification *metav1.Condition
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		updated, notification = false, nil
 		managedclusteraddon := &addonv1alpha1.ManagedClusterAddOn{}
 		err := c.Get(context.TODO(), types.NamespacedName{
 			Name:      util.ManagedClusterAddonName,
@@ -52,20 +53,83 @@ func updateAddonStatus(c client.Client, addonList mcov1beta1.ObservabilityAddonL
 		if err != nil {
 			if errors.IsNotFound(err) {
 				log.Info("managedclusteraddon does not exist", "namespace", addon.ObjectMeta.Namespace)
-				continue
+				return nil
 			}
-			log.Error(err, "Failed to get managedclusteraddon", "namespace", addon.ObjectMeta.Namespace)
 			return err
 		}
-		if !reflect.DeepEqual(conditions, managedclusteraddon.Status.Conditions) {
-			managedclusteraddon.Status.Conditions = conditions
-			err = c.Status().Update(context.TODO(), managedclusteraddon)
-			if err != nil {
-				log.Error(err, "Failed to update status for managedclusteraddon", "namespace", addon.ObjectMeta.Namespace)
-				return err
-			}
-			log.Info("Updated status for managedclusteraddon", "namespace", addon.ObjectMeta.Namespace)
+		conditions := append(newAddonConditions(*addon), hubConditions...)
+		keepTransitionTime(conditions[len(conditions)-len(hubConditions):], managedclusteraddon.Status.Conditions)
+		if reflect.DeepEqual(conditions, managedclusteraddon.Status.Conditions) {
+			return nil
+		}
+		if degraded := findDegradedCondition(conditions); degraded != nil &&
+			findDegradedCondition(managedclusteraddon.Status.Conditions) == nil {
+			notification = degraded
+		}
+		original := managedclusteraddon.DeepCopy()
+		managedclusteraddon.Status.Conditions = conditions
+		err = c.Status().Patch(context.TODO(), managedclusteraddon,
+			client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+		updated = err == nil
+		return err
+	})
+	if err != nil {
+		errorlog.ClusterError(log, err, addon.ObjectMeta.Namespace, "Failed to update status for managedclusteraddon")
+		return status, err
+	}
+	if updated {
+		if notification != nil {
+			notifier.notify(addon.ObjectMeta.Namespace, ReasonClusterDegraded, notification.Message)
+		}
+		log.Info("Updated status for managedclusteraddon", "namespace", addon.ObjectMeta.Namespace)
+	}
+	return status, nil
+}
+
+// newAddonConditions converts the conditions of the observabilityaddon into the conditions
+// of the managedclusteraddon
+func newAddonConditions(addon mcov1beta1.ObservabilityAddon) []metav1.Condition {
+	conditions := []metav1.Condition{}
+	for _, c := range addon.Status.Conditions {
+		condition := metav1.Condition{
+			Type:               statusMap[c.Type],
+			Status:             c.Status,
+			LastTransitionTime: c.LastTransitionTime,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions
+}
+
+// getAddonStatus returns Available, Progressing or Degraded from the conditions of the addon
+func getAddonStatus(conditions []metav1.Condition) string {
+	if findDegradedCondition(conditions) != nil {
+		return "Degraded"
+	} else if meta.IsStatusConditionTrue(conditions, "Available") {
+		return "Available"
+	}
+	return "Progressing"
+}
+
+// findDegradedCondition returns the Degraded condition with true status, or nil
+func findDegradedCondition(conditions []metav1.Condition) *metav1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == "Degraded" && conditions[i].Status == metav1.ConditionTrue {
+			return &conditions[i]
 		}
 	}
 	return nil
 }
+
+// keepTransitionTime keeps the transition time of the conditions whose status is not changed, so that
+// the conditions which the hub detects on every reconcile do not update the addon
+func keepTransitionTime(conditions []metav1.Condition, existing []metav1.Condition) {
+	for i := range conditions {
+		found := meta.FindStatusCondition(existing, conditions[i].Type)
+		if found != nil && found.Status == conditions[i].Status {
+			conditions[i].LastTransitionTime = found.LastTransitionTime
+		}
+	}
+}
@@ -50,8 +50,8 @@ func TestUpdateAddonStatus(t *testing.T) {
 		},
 	}
 
-	err := updateAddonStatus(c, *addonList)
-	if err != nil {
+	status, err := updateAddonStatus(c, &addonList.Items[0], nil)
+	if err != nil || status != "Available" {
 		t.Fatalf("Failed to update status for managedclusteraddon: (%v)", err)
 	}
 
@@ -0,0 +1,233 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+const (
+	collectorTokenRoleBindingPrefix = "observability-collector-token-"
+)
+
+// isTokenAuthCluster returns true if the managed cluster authenticates its remote writes with the
+// tokens of the collector service account instead of the client certificate
+func isTokenAuthCluster(c client.Client, clusterName string, mco *mcov1beta2.MultiClusterObservability) (bool, error) {
+	if !config.IsTokenAuthEnabled(mco) {
+		return false, nil
+	}
+	cluster := &clusterv1.ManagedCluster{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		errorlog.ClusterError(log, err, clusterName, "Failed to get managedcluster")
+		return false, err
+	}
+	return config.IsTokenAuthCluster(mco, cluster.GetLabels())
+}
+
+// createCollectorTokenRes creates the collector service account in the namespace of the operands and
+// the role which allows to request its tokens, or deletes them once no managed cluster uses the tokens
+func createCollectorTokenRes(c client.Client, mco *mcov1beta2.MultiClusterObservability) error {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.CollectorServiceAccountName,
+			Namespace: config.GetDefaultNamespace(),
+			Labels: map[string]string{
+				ownerLabelKey: ownerLabelValue,
+			},
+		},
+	}
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.CollectorTokenRoleName,
+			Namespace: config.GetDefaultNamespace(),
+			Labels: map[string]string{
+				ownerLabelKey: ownerLabelValue,
+			},
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				Resources:     []string{"serviceaccounts/token"},
+				ResourceNames: []string{config.CollectorServiceAccountName},
+				Verbs:         []string{"create"},
+				APIGroups:     []string{""},
+			},
+		},
+	}
+	inUse, err := config.IsTokenAuthInUse(c, mco)
+	if err != nil {
+		log.Error(err, "Failed to check whether the token authentication is in use")
+		return err
+	}
+	if !inUse {
+		for _, obj := range []client.Object{sa, role} {
+			err := c.Delete(context.TODO(), obj)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				log.Error(err, "Failed to delete the resource of the token authentication", "name", obj.GetName())
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = c.Get(context.TODO(), types.NamespacedName{Name: sa.Name, Namespace: sa.Namespace}, &corev1.ServiceAccount{})
+	if err != nil && k8serrors.IsNotFound(err) {
+		log.Info("Creating the collector service account")
+		if err = c.Create(context.TODO(), sa); err != nil {
+			log.Error(err, "Failed to create the collector service account")
+			return err
+		}
+	} else if err != nil {
+		log.Error(err, "Failed to check the collector service account")
+		return err
+	}
+
+	found := &rbacv1.Role{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: role.Name, Namespace: role.Namespace}, found)
+	if err != nil && k8serrors.IsNotFound(err) {
+		log.Info("Creating the collector token role")
+		if err = c.Create(context.TODO(), role); err != nil {
+			log.Error(err, "Failed to create the collector token role")
+			return err
+		}
+		return nil
+	} else if err != nil {
+		log.Error(err, "Failed to check the collector token role")
+		return err
+	}
+	if !reflect.DeepEqual(found.Rules, role.Rules) {
+		log.Info("Updating the collector token role")
+		role.ResourceVersion = found.ResourceVersion
+		if err = c.Update(context.TODO(), role); err != nil {
+			log.Error(err, "Failed to update the collector token role")
+			return err
+		}
+	}
+	return nil
+}
+
+// updateCollectorTokenRoleBinding allows the addon of the managed cluster to request the tokens of the
+// collector service account with its hub kubeconfig, or revokes it once the cluster uses the client certificate.
+// It is kept while the cluster switches between them so that the remote writes are not interrupted.
+func updateCollectorTokenRoleBinding(c client.Client, mco *mcov1beta2.MultiClusterObservability,
+	namespace string, name string) error {
+	isToken, err := isTokenAuthCluster(c, name, mco)
+	if err != nil {
+		return err
+	}
+	if !isToken {
+		isToken, err = isTokenAuthMigrating(c, namespace)
+		if err != nil {
+			return err
+		}
+	}
+	if !isToken {
+		return deleteCollectorTokenRoleBinding(c, namespace)
+	}
+	rb := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      collectorTokenRoleBindingPrefix + namespace,
+			Namespace: config.GetDefaultNamespace(),
+			Labels: map[string]string{
+				ownerLabelKey: ownerLabelValue,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "Role",
+			Name:     config.CollectorTokenRoleName,
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind: "Group",
+				Name: fmt.Sprintf("system:open-cluster-management:cluster:%s:addon:%s", name, addonName),
+			},
+		},
+	}
+	found := &rbacv1.RoleBinding{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: rb.Name, Namespace: rb.Namespace}, found)
+	if err != nil && k8serrors.IsNotFound(err) {
+		log.Info("Creating the collector token rolebinding", "namespace", namespace)
+		if err = c.Create(context.TODO(), rb); err != nil {
+			errorlog.ClusterError(log, err, namespace, "Failed to create the collector token rolebinding")
+			return err
+		}
+		return nil
+	} else if err != nil {
+		errorlog.ClusterError(log, err, namespace, "Failed to check the collector token rolebinding")
+		return err
+	}
+	if !reflect.DeepEqual(found.Subjects, rb.Subjects) || !reflect.DeepEqual(found.RoleRef, rb.RoleRef) {
+		log.Info("Updating the collector token rolebinding", "namespace", namespace)
+		rb.ResourceVersion = found.ResourceVersion
+		if err = c.Update(context.TODO(), rb); err != nil {
+			errorlog.ClusterError(log, err, namespace, "Failed to update the collector token rolebinding")
+			return err
+		}
+	}
+	return nil
+}
+
+// isTokenAuthMigrating returns true if the managed cluster is switched to or from the tokens
+func isTokenAuthMigrating(c client.Client, namespace string) (bool, error) {
+	addon := &addonv1alpha1.ManagedClusterAddOn{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: util.ManagedClusterAddonName, Namespace: namespace}, addon)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		errorlog.ClusterError(log, err, namespace, "Failed to get the managedclusteraddon")
+		return false, err
+	}
+	return addon.Annotations[config.AuthModeAnnotation] != "", nil
+}
+
+func deleteCollectorTokenRoleBinding(c client.Client, namespace string) error {
+	rb := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      collectorTokenRoleBindingPrefix + namespace,
+			Namespace: config.GetDefaultNamespace(),
+		},
+	}
+	err := c.Delete(context.TODO(), rb)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		errorlog.ClusterError(log, err, namespace, "Failed to delete the collector token rolebinding")
+		return err
+	}
+	return nil
+}
+
+// setTokenAuth tells the endpoint operator to request the tokens of the collector service account with
+// the hub kubeconfig of the addon, and to authenticate the remote writes with them
+func setTokenAuth(c client.Client, hubInfo *HubInfo, mco *mcov1beta2.MultiClusterObservability) error {
+	mode, err := getClusterAuthMode(c, mco, hubInfo.ClusterName, time.Now())
+	if err != nil || mode != config.TokenAuthMode {
+		return err
+	}
+	hubInfo.EndpointAuthMode = config.TokenAuthMode
+	hubInfo.TokenServiceAccount = config.GetDefaultNamespace() + "/" + config.CollectorServiceAccountName
+	hubInfo.TokenAudience = config.GetTokenAudience(mco)
+	hubInfo.TokenExpirationSeconds = config.GetTokenExpirationSeconds(mco)
+	return nil
+}
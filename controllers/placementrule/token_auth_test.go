@@ -0,0 +1,115 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestTokenAuth(t *testing.T) {
+	initSchema(t)
+
+	objs := []runtime.Object{
+		newTestRoute(),
+		&clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterName, Labels: map[string]string{"auth": "token"}},
+		},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster-certs"}},
+		newTestAddon(clusterName, nil),
+		newTestAddon("cluster-certs", nil),
+	}
+	mco := newTestMCO()
+	mco.Spec.GatewayAuth = &mcov1beta2.GatewayAuthSpec{
+		ServiceAccountToken: &mcov1beta2.GatewayServiceAccountTokenSpec{
+			Enabled:         true,
+			ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"auth": "token"}},
+		},
+	}
+	c := fake.NewFakeClient(append(objs, newTestGatewayObjs(mco, true)...)...)
+
+	if err := createCollectorTokenRes(c, mco); err != nil {
+		t.Fatalf("Failed to create the collector token resources: (%v)", err)
+	}
+	sa := &corev1.ServiceAccount{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      config.CollectorServiceAccountName,
+		Namespace: config.GetDefaultNamespace(),
+	}, sa)
+	if err != nil {
+		t.Fatalf("Failed to get the collector service account: (%v)", err)
+	}
+
+	for _, cluster := range []string{clusterName, "cluster-certs"} {
+		if err := updateCollectorTokenRoleBinding(c, mco, cluster, cluster); err != nil {
+			t.Fatalf("Failed to update the collector token rolebinding: (%v)", err)
+		}
+		secret, err := newHubInfoSecret(c, mcoNamespace, namespace, cluster, mco)
+		if err != nil {
+			t.Fatalf("Failed to initial the hub info secret: (%v)", err)
+		}
+		hub := &HubInfo{}
+		if err := yaml.Unmarshal(secret.Data[hubInfoKey], hub); err != nil {
+			t.Fatalf("Failed to unmarshal data in hub info secret (%v)", err)
+		}
+		rb := &rbacv1.RoleBinding{}
+		err = c.Get(context.TODO(), types.NamespacedName{
+			Name:      collectorTokenRoleBindingPrefix + cluster,
+			Namespace: config.GetDefaultNamespace(),
+		}, rb)
+		if cluster == clusterName {
+			if hub.EndpointAuthMode != config.TokenAuthMode || hub.TokenAudience != "observability-api" ||
+				hub.TokenExpirationSeconds != 3600 {
+				t.Errorf("the selected cluster should authenticate with the tokens: %v", hub)
+			}
+			if err != nil {
+				t.Errorf("the addon of the selected cluster should request the tokens: (%v)", err)
+			}
+		} else {
+			if hub.EndpointAuthMode != "" {
+				t.Errorf("the other clusters should authenticate with the client certificates: %v", hub)
+			}
+			if !k8serrors.IsNotFound(err) {
+				t.Errorf("the addon of the other clusters should not request the tokens: (%v)", err)
+			}
+		}
+	}
+
+	// the tokens are revoked once the cluster switches back to the client certificate
+	mco.Spec.GatewayAuth.ServiceAccountToken.Enabled = false
+	if _, err := getClusterAuthMode(c, mco, clusterName, time.Now()); err != nil {
+		t.Fatalf("Failed to switch back the cluster: (%v)", err)
+	}
+	recordTestClientCert(t, c, clusterName, time.Now().Add(time.Hour))
+	if _, err := getClusterAuthMode(c, mco, clusterName, time.Now()); err != nil {
+		t.Fatalf("Failed to switch back the cluster: (%v)", err)
+	}
+	if err := updateCollectorTokenRoleBinding(c, mco, clusterName, clusterName); err != nil {
+		t.Fatalf("Failed to update the collector token rolebinding: (%v)", err)
+	}
+	if err := createCollectorTokenRes(c, mco); err != nil {
+		t.Fatalf("Failed to delete the collector token resources: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      config.CollectorServiceAccountName,
+		Namespace: config.GetDefaultNamespace(),
+	}, sa)
+	if !k8serrors.IsNotFound(err) {
+		t.Errorf("the collector service account should be deleted: (%v)", err)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+)
+
+const (
+	windowsMetricsListKey = "windows_metrics_list.yaml"
+)
+
+// isWindowsMetricsEnabled returns true if the managed cluster is labelled to collect the metrics
+// of its windows worker nodes
+func isWindowsMetricsEnabled(c client.Client, clusterName string) (bool, error) {
+	cluster := &clusterv1.ManagedCluster{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: clusterName}, cluster)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		errorlog.ClusterError(log, err, clusterName, "Failed to get managedcluster")
+		return false, err
+	}
+	return cluster.GetLabels()[config.WindowsMetricsLabelKey] == "true", nil
+}
+
+// newWindowsScrapeConfigs returns the scrape config of windows_exporter on the windows nodes
+func newWindowsScrapeConfigs() []ScrapeConfig {
+	return []ScrapeConfig{
+		{
+			JobName:             "windows-exporter",
+			Scheme:              "http",
+			KubernetesSDConfigs: []map[string]string{{"role": "node"}},
+			RelabelConfigs: []RelabelConfig{
+				{
+					SourceLabels: []string{"__meta_kubernetes_node_label_kubernetes_io_os"},
+					Regex:        "windows",
+					Action:       "keep",
+				},
+				{
+					SourceLabels: []string{"__address__"},
+					Regex:        `([^:]+)(?::\d+)?`,
+					TargetLabel:  "__address__",
+					Replacement:  "${1}:" + config.WindowsExporterPort,
+				},
+				{
+					SourceLabels: []string{"__meta_kubernetes_node_name"},
+					TargetLabel:  "instance",
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+func TestWindowsMetrics(t *testing.T) {
+	initSchema(t)
+
+	cluster := &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   clusterName,
+			Labels: map[string]string{config.WindowsMetricsLabelKey: "true"},
+		},
+	}
+	allowlistCM := NewMetricsAllowListCM()
+	allowlistCM.Data[windowsMetricsListKey] = `
+  names:
+    - windows_a
+`
+	c := fake.NewFakeClient(cluster, allowlistCM)
+
+	cm, err := getMetricsListCM(c, clusterName, nil)
+	if err != nil {
+		t.Fatalf("Failed to get metrics allowlist configmap: (%v)", err)
+	}
+	allowlist := &MetricsAllowlist{}
+	err = yaml.Unmarshal([]byte(cm.Data[metricsListKey]), allowlist)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal allowlist: (%v)", err)
+	}
+	if !util.Contains(allowlist.NameList, "windows_a") {
+		t.Errorf("The windows bundle should be merged for the labelled cluster: %v", allowlist.NameList)
+	}
+
+	cm, err = getMetricsListCM(c, "cluster2", nil)
+	if err != nil {
+		t.Fatalf("Failed to get metrics allowlist configmap: (%v)", err)
+	}
+	if strings.Contains(cm.Data[metricsListKey], "windows_a") {
+		t.Errorf("The windows bundle should not be merged for the cluster without the label")
+	}
+
+	scrapeConfig, err := newScrapeConfigCM(newWindowsScrapeConfigs())
+	if err != nil {
+		t.Fatalf("Failed to generate the scrape config: (%v)", err)
+	}
+	data := scrapeConfig.Data[config.ScrapeConfigFileKey]
+	for _, s := range []string{"job_name: windows-exporter", "regex: windows", "${1}:9182"} {
+		if !strings.Contains(data, s) {
+			t.Errorf("The scrape config should contain %s: %s", s, data)
+		}
+	}
+	if strings.Contains(data, "tls_config") {
+		t.Errorf("windows_exporter is scraped without the client certificates: %s", data)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workv1 "github.com/open-cluster-management/api/work/v1"
+)
+
+const (
+	workConditionApplied = "Applied"
+
+	ReasonManifestWorkNotApplied = "ManifestWorkNotApplied"
+	ReasonManifestWorkStale      = "ManifestWorkStale"
+)
+
+var (
+	// staleWorkThreshold is the time after which the manifestwork which is not applied by the work
+	// agent is considered stale
+	staleWorkThreshold = 10 * time.Minute
+	// pendingWorks records since when the generation of the manifestwork of the cluster namespace is
+	// waiting to be applied, the leader which takes over restarts the wait
+	pendingWorks = map[string]*pendingWork{}
+)
+
+type pendingWork struct {
+	uid        types.UID
+	generation int64
+	since      time.Time
+	recreated  bool
+}
+
+// isWorkApplied returns true if the work agent reports the current generation of the manifestwork as
+// applied. The agents which do not report the observed generation are trusted with the condition.
+func isWorkApplied(work *workv1.ManifestWork) bool {
+	applied := meta.FindStatusCondition(work.Status.Conditions, workConditionApplied)
+	if applied == nil || applied.Status != metav1.ConditionTrue {
+		return false
+	}
+	return applied.ObservedGeneration == 0 || applied.ObservedGeneration >= work.Generation
+}
+
+// checkStaleWorks returns the addon conditions of the clusters whose manifestwork is not applied. The
+// work is reported Progressing until it stays not applied for longer than staleWorkThreshold, then it
+// is reported Degraded and deleted once per generation, so that it is re-created by the next
+// reconcile of the cluster. The re-created work is not re-created again until its generation changes.
+func checkStaleWorks(c client.Client, works []workv1.ManifestWork, now time.Time) (map[string]metav1.Condition, error) {
+	var err error
+	conditions := map[string]metav1.Condition{}
+	latest := map[string]*pendingWork{}
+	listed := map[string]bool{}
+	for i := range works {
+		work := &works[i]
+		if work.Name != work.Namespace+workNameSuffix {
+			continue
+		}
+		listed[work.Namespace] = true
+		if work.GetDeletionTimestamp() != nil {
+			if pending := pendingWorks[work.Namespace]; pending != nil {
+				latest[work.Namespace] = pending
+			}
+			continue
+		}
+		if isWorkApplied(work) {
+			continue
+		}
+		pending := pendingWorks[work.Namespace]
+		if pending != nil && pending.recreated && pending.uid != work.UID {
+			// the work is re-created, wait for the new work to be applied
+			pending = &pendingWork{uid: work.UID, generation: work.Generation, since: now, recreated: true}
+		} else if pending == nil || pending.generation != work.Generation {
+			pending = &pendingWork{uid: work.UID, generation: work.Generation, since: now}
+		}
+		latest[work.Namespace] = pending
+
+		if now.Sub(pending.since) < staleWorkThreshold {
+			conditions[work.Namespace] = metav1.Condition{
+				Type:               "Progressing",
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: newConditionTime(pending.since),
+				Reason:             ReasonManifestWorkNotApplied,
+				Message:            "The manifestwork is waiting to be applied by the work agent",
+			}
+			continue
+		}
+		conditions[work.Namespace] = metav1.Condition{
+			Type:               "Degraded",
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: newConditionTime(pending.since.Add(staleWorkThreshold)),
+			Reason:             ReasonManifestWorkStale,
+			Message:            "The manifestwork is not applied by the work agent for " + staleWorkThreshold.String(),
+		}
+		if !pending.recreated {
+			log.Info("To re-create the stale manifestwork", "namespace", work.Namespace,
+				"generation", work.Generation)
+			if delErr := deleteManifestWork(c, work.Name, work.Namespace); delErr != nil {
+				err = delErr
+				continue
+			}
+			pending.recreated = true
+		}
+	}
+	// keep the re-created works which are not created yet
+	for namespace, pending := range pendingWorks {
+		if pending.recreated && !listed[namespace] {
+			latest[namespace] = pending
+		}
+	}
+	pendingWorks = latest
+	return conditions, err
+}
+
+// newConditionTime returns the time of the condition in the precision which it is stored in, so that
+// the conditions are not updated on every reconcile
+func newConditionTime(t time.Time) metav1.Time {
+	return metav1.NewTime(t.Truncate(time.Second))
+}
+
+// nextStaleWorkCheck returns the time until the first pending manifestwork becomes stale, or 0 if no
+// manifestwork is pending to become stale
+func nextStaleWorkCheck(now time.Time) time.Duration {
+	next := time.Duration(0)
+	for _, pending := range pendingWorks {
+		wait := pending.since.Add(staleWorkThreshold).Sub(now)
+		if wait <= 0 {
+			if pending.recreated {
+				continue
+			}
+			wait = time.Second
+		}
+		if next == 0 || wait < next {
+			next = wait
+		}
+	}
+	return next
+}
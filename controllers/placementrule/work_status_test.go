@@ -0,0 +1,133 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package placementrule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+func newTestWork(ns string, uid types.UID, generation int64, applied bool) workv1.ManifestWork {
+	work := workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       ns + workNameSuffix,
+			Namespace:  ns,
+			UID:        uid,
+			Generation: generation,
+		},
+	}
+	if applied {
+		work.Status.Conditions = []metav1.Condition{
+			{Type: workConditionApplied, Status: metav1.ConditionTrue, ObservedGeneration: generation},
+		}
+	}
+	return work
+}
+
+func TestCheckStaleWorks(t *testing.T) {
+	initSchema(t)
+	pendingWorks = map[string]*pendingWork{}
+
+	stale := newTestWork(namespace, "uid-1", 1, false)
+	applied := newTestWork("cluster2", "uid-2", 2, true)
+	c := fake.NewFakeClient(stale.DeepCopy(), applied.DeepCopy())
+	now := time.Now()
+
+	conditions, err := checkStaleWorks(c, []workv1.ManifestWork{stale, applied}, now)
+	if err != nil {
+		t.Fatalf("Failed to check the stale manifestworks: (%v)", err)
+	}
+	if len(conditions) != 1 || conditions[namespace].Type != "Progressing" {
+		t.Fatalf("only the pending work should be progressing: %v", conditions)
+	}
+	if next := nextStaleWorkCheck(now); next != staleWorkThreshold {
+		t.Errorf("the next check should be scheduled when the work becomes stale: %v", next)
+	}
+
+	// the generation applied previously is not the current one
+	applied.Generation = 3
+	conditions, err = checkStaleWorks(c, []workv1.ManifestWork{stale, applied}, now.Add(staleWorkThreshold))
+	if err != nil {
+		t.Fatalf("Failed to check the stale manifestworks: (%v)", err)
+	}
+	if conditions[namespace].Type != "Degraded" || conditions[namespace].Reason != ReasonManifestWorkStale {
+		t.Errorf("the work should be degraded after the threshold: %v", conditions[namespace])
+	}
+	if conditions["cluster2"].Reason != ReasonManifestWorkNotApplied {
+		t.Errorf("the work of the new generation should be progressing: %v", conditions["cluster2"])
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: stale.Name, Namespace: namespace}, &workv1.ManifestWork{})
+	if !k8serrors.IsNotFound(err) {
+		t.Fatalf("the stale work should be deleted to be re-created: (%v)", err)
+	}
+
+	// the re-created work is not re-created again
+	recreated := newTestWork(namespace, "uid-3", 1, false)
+	if err := c.Create(context.TODO(), recreated.DeepCopy()); err != nil {
+		t.Fatalf("Failed to re-create the manifestwork: (%v)", err)
+	}
+	later := now.Add(3 * staleWorkThreshold)
+	_, err = checkStaleWorks(c, []workv1.ManifestWork{recreated}, later)
+	if err != nil {
+		t.Fatalf("Failed to check the stale manifestworks: (%v)", err)
+	}
+	conditions, err = checkStaleWorks(c, []workv1.ManifestWork{recreated}, later.Add(staleWorkThreshold))
+	if err != nil {
+		t.Fatalf("Failed to check the stale manifestworks: (%v)", err)
+	}
+	if conditions[namespace].Type != "Degraded" {
+		t.Errorf("the re-created work should be degraded after the threshold: %v", conditions[namespace])
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: recreated.Name, Namespace: namespace}, &workv1.ManifestWork{})
+	if err != nil {
+		t.Errorf("the re-created work should not be deleted again: (%v)", err)
+	}
+	if next := nextStaleWorkCheck(later.Add(staleWorkThreshold)); next != 0 {
+		t.Errorf("no check should be scheduled for the re-created stale work: %v", next)
+	}
+}
+
+func TestUpdateAddonStatusWithStaleWork(t *testing.T) {
+	maddon := &addonv1alpha1.ManagedClusterAddOn{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      util.ManagedClusterAddonName,
+			Namespace: namespace,
+		},
+	}
+	c := fake.NewFakeClient(maddon)
+	addonList := mcov1beta1.ObservabilityAddonList{
+		Items: []mcov1beta1.ObservabilityAddon{
+			{ObjectMeta: metav1.ObjectMeta{Name: obsAddonName, Namespace: namespace}},
+		},
+	}
+	workConditions := map[string][]metav1.Condition{
+		namespace: {{
+			Type:               "Degraded",
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(time.Now()),
+			Reason:             ReasonManifestWorkStale,
+		}},
+	}
+	if _, err := updateAddonStatus(c, &addonList.Items[0], workConditions[namespace]); err != nil {
+		t.Fatalf("Failed to update status for managedclusteraddon: (%v)", err)
+	}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: util.ManagedClusterAddonName, Namespace: namespace}, maddon)
+	if err != nil {
+		t.Fatalf("Failed to get managedclusteraddon: (%v)", err)
+	}
+	if findDegradedCondition(maddon.Status.Conditions) == nil {
+		t.Errorf("the addon of the stale work should be degraded: %v", maddon.Status.Conditions)
+	}
+}
@@ -5,6 +5,7 @@ go 1.16
 require (
 	github.com/Azure/go-autorest/autorest v0.11.6 // indirect
 	github.com/cloudflare/cfssl v1.5.0
+	github.com/evanphx/json-patch v4.9.0+incompatible
 	github.com/go-logr/logr v0.4.0
 	github.com/jetstack/cert-manager v0.0.0-00010101000000-000000000000
 	github.com/kr/pretty v0.2.1 // indirect
@@ -14,6 +15,7 @@ require (
 	github.com/open-cluster-management/observatorium-operator v0.0.0-20210428083021-438dd3cd8102
 	github.com/openshift/api v3.9.1-0.20190924102528-32369d4db2ad+incompatible
 	github.com/openshift/client-go v0.0.0-20201214125552-e615e336eb49
+	github.com/prometheus/client_golang v1.7.1
 	gopkg.in/yaml.v2 v2.4.0
 	k8s.io/api v0.20.5
 	k8s.io/apiextensions-apiserver v0.20.2
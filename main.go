@@ -19,9 +19,11 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -31,6 +33,9 @@ import (
 	certv1alpha1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
 	ocinfrav1 "github.com/openshift/api/config/v1"
 	routev1 "github.com/openshift/api/route/v1"
+	ocpClientSet "github.com/openshift/client-go/config/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	crdClientSet "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -38,10 +43,13 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	ctrlruntimescheme "sigs.k8s.io/controller-runtime/pkg/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	migrationv1alpha1 "sigs.k8s.io/kube-storage-version-migrator/pkg/apis/migration/v1alpha1"
 
 	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
 	workv1 "github.com/open-cluster-management/api/work/v1"
 	placementv1 "github.com/open-cluster-management/multicloud-operators-placementrule/pkg/apis/apps/v1"
 	observabilityv1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
@@ -49,7 +57,12 @@ import (
 	mcoctrl "github.com/open-cluster-management/multicluster-observability-operator/controllers/multiclusterobservability"
 	prctrl "github.com/open-cluster-management/multicluster-observability-operator/controllers/placementrule"
 	certctrl "github.com/open-cluster-management/multicluster-observability-operator/pkg/certificates"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/clusterprobe"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/deploying"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/linter"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/telemetry"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
 	observatoriumAPIs "github.com/open-cluster-management/observatorium-operator/api/v1alpha1"
 	// +kubebuilder:scaffold:imports
@@ -71,20 +84,55 @@ func init() {
 	utilruntime.Must(observabilityv1beta2.AddToScheme(scheme))
 	utilruntime.Must(placementv1.AddToScheme(scheme))
 	utilruntime.Must(observatoriumAPIs.AddToScheme(scheme))
+	// the controllers resolve the kinds they watch when they are set up, so every API which the
+	// controllers watch is registered before the manager is created
+	utilruntime.Must(routev1.AddToScheme(scheme))
+	utilruntime.Must(ocinfrav1.AddToScheme(scheme))
+	utilruntime.Must(workv1.AddToScheme(scheme))
+	utilruntime.Must(certv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(migrationv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(addonv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(clusterv1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
+	// the linter validates the user configurations offline in the CI of the GitOps repository
+	if len(os.Args) > 1 && os.Args[1] == "linter" {
+		os.Exit(linter.Main(os.Args[2:], os.Stdout))
+	}
+
 	// var metricsAddr string
 	var enableLeaderElection bool
+	var leaseDuration, renewDeadline, retryPeriod time.Duration
 	var probeAddr string
 	var webhookPort int
+	var syncPeriod time.Duration
+	var scopeCache bool
+	var kubeAPIQPS float64
+	var kubeAPIBurst int
+	var staleAddonDryRun bool
 	// flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&leaseDuration, "leader-elect-lease-duration", 15*time.Second,
+		"The duration that the standby replicas wait before taking over the leadership of the lost leader.")
+	flag.DurationVar(&renewDeadline, "leader-elect-renew-deadline", 10*time.Second,
+		"The duration that the leader retries renewing the leadership before giving it up.")
+	flag.DurationVar(&retryPeriod, "leader-elect-retry-period", 2*time.Second,
+		"The duration that the replicas wait between the attempts to acquire or renew the leadership.")
 	flag.IntVar(&webhookPort, "webhook-server-port", 9443, "The listening port of the webhook server.")
+	flag.DurationVar(&syncPeriod, "sync-period", 10*time.Hour,
+		"The minimum interval at which the watched resources are resynced and reconciled.")
+	flag.BoolVar(&scopeCache, "scope-cache", true,
+		"Only cache the ConfigMaps and Secrets in the observability namespace. "+
+			"Reading them from other namespaces goes to the API server directly.")
+	flag.Float64Var(&kubeAPIQPS, "kube-api-qps", 20, "The maximum QPS of the client to the API server.")
+	flag.IntVar(&kubeAPIBurst, "kube-api-burst", 30, "The maximum burst of the client to the API server.")
+	flag.BoolVar(&staleAddonDryRun, "stale-addon-dry-run", false,
+		"Only log the observabilityaddons without manifestwork instead of force deleting them.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -93,14 +141,39 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	mgrOptions := ctrl.Options{
 		Port:                   webhookPort,
 		Scheme:                 scheme,
 		MetricsBindAddress:     fmt.Sprintf("%s:%d", metricsHost, metricsPort),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "b9d51391.open-cluster-management.io",
-	})
+		LeaseDuration:          &leaseDuration,
+		RenewDeadline:          &renewDeadline,
+		RetryPeriod:            &retryPeriod,
+		SyncPeriod:             &syncPeriod,
+	}
+	namespaceScoped := config.IsNamespaceScoped()
+	if namespaceScoped {
+		// the operator and the hub components are installed into the namespace of the operands
+		if config.GetWatchNamespace() != config.GetDefaultNamespace() {
+			setupLog.Error(fmt.Errorf("the watch namespace %s is not %s", config.GetWatchNamespace(),
+				config.GetDefaultNamespace()), "The operator can only be restricted to the namespace of the operands")
+			os.Exit(1)
+		}
+		setupLog.Info("The operator is restricted to the namespace", "namespace", config.GetWatchNamespace())
+		mgrOptions.Namespace = config.GetWatchNamespace()
+	} else if scopeCache {
+		mgrOptions.NewCache = util.NewScopedCacheFunc(config.GetDefaultNamespace(),
+			[]string{config.DashboardLabelKey, config.CustomDashboardLabelKey},
+			&corev1.ConfigMap{}, &corev1.Secret{})
+	}
+
+	restConfig := ctrl.GetConfigOrDie()
+	restConfig.QPS = float32(kubeAPIQPS)
+	restConfig.Burst = kubeAPIBurst
+
+	mgr, err := ctrl.NewManager(restConfig, mgrOptions)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
@@ -118,35 +191,17 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&mcoctrl.MultiClusterObservabilityReconciler{
-		Client:    mgr.GetClient(),
-		Log:       ctrl.Log.WithName("controllers").WithName("MultiClusterObservability"),
-		Scheme:    mgr.GetScheme(),
-		OcpClient: ocpClient,
-		CrdClient: crdClient,
-		APIReader: mgr.GetAPIReader(),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "MultiClusterObservability")
-		os.Exit(1)
-	}
-
 	crdExists, err := util.CheckCRDExist(crdClient, config.PlacementRuleCrdName)
 	if err != nil {
 		setupLog.Error(err, "Failed to check if the CRD exists")
 		os.Exit(1)
 	}
 
-	if crdExists {
-		if err = (&prctrl.PlacementRuleReconciler{
-			Client:     mgr.GetClient(),
-			Log:        ctrl.Log.WithName("controllers").WithName("PlacementRule"),
-			Scheme:     mgr.GetScheme(),
-			APIReader:  mgr.GetAPIReader(),
-			RESTMapper: mgr.GetRESTMapper(),
-		}).SetupWithManager(mgr); err != nil {
-			setupLog.Error(err, "unable to create controller", "controller", "PlacementRule")
-			os.Exit(1)
-		}
+	// the observability addon is not pushed to the managed clusters in the namespace-scoped install
+	// mode, which cannot create the resources in the namespaces of the managed clusters
+	if err = setupControllers(mgr, ocpClient, crdClient, crdExists && !namespaceScoped, staleAddonDryRun); err != nil {
+		setupLog.Error(err, "unable to create controller")
+		os.Exit(1)
 	}
 	// +kubebuilder:scaffold:builder
 
@@ -158,68 +213,118 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
-
-	// Add route Openshift scheme
-	if err := routev1.AddToScheme(mgr.GetScheme()); err != nil {
-		setupLog.Error(err, "")
+	// every replica serves the webhooks, the replica is ready once the webhook server is serving
+	if err := mgr.AddReadyzCheck("webhook", util.NewServingChecker(webhookPort)); err != nil {
+		setupLog.Error(err, "unable to set up webhook ready check")
 		os.Exit(1)
 	}
 
-	if err := ocinfrav1.AddToScheme(mgr.GetScheme()); err != nil {
-		setupLog.Error(err, "")
-		os.Exit(1)
+	// Setup Scheme for observatorium resources
+	schemeBuilder := &ctrlruntimescheme.Builder{
+		GroupVersion: schema.GroupVersion{
+			Group:   "core.observatorium.io",
+			Version: "v1alpha1",
+		},
 	}
-
-	if err := workv1.AddToScheme(mgr.GetScheme()); err != nil {
+	schemeBuilder.Register(&observatoriumAPIs.Observatorium{}, &observatoriumAPIs.ObservatoriumList{})
+	if err := schemeBuilder.AddToScheme(mgr.GetScheme()); err != nil {
 		setupLog.Error(err, "")
 		os.Exit(1)
 	}
 
-	if err := placementv1.AddToScheme(mgr.GetScheme()); err != nil {
-		setupLog.Error(err, "")
+	if err = (&observabilityv1beta2.MultiClusterObservability{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "Captain")
 		os.Exit(1)
 	}
+	// reject the deletion of the secrets which the observability components use
+	mgr.GetWebhookServer().Register(mcoctrl.SecretProtectionWebhookPath,
+		&webhook.Admission{Handler: &mcoctrl.SecretProtectionHandler{Client: mgr.GetClient()}})
 
-	if err := certv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
-		setupLog.Error(err, "")
-		os.Exit(1)
-	}
+	// report the external modifications of the resources owned by the operator which are reverted
+	deploying.SetDriftRecorder(mgr.GetEventRecorderFor("multicluster-observability-operator"))
 
-	// add scheme of storage version migration
-	if err := migrationv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
-		setupLog.Error(err, "")
+	// summarize the repeated errors of the managed clusters periodically
+	if err := mgr.Add(&errorlog.Reporter{}); err != nil {
+		setupLog.Error(err, "unable to add the error reporter")
 		os.Exit(1)
 	}
 
-	if err := addonv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
-		setupLog.Error(err, "")
+	// post the anonymous usage and health reports if they are enabled in the MultiClusterObservability
+	if err := mgr.Add(&telemetry.Reporter{Client: mgr.GetClient()}); err != nil {
+		setupLog.Error(err, "unable to add the telemetry reporter")
 		os.Exit(1)
 	}
 
-	// Setup Scheme for observatorium resources
-	schemeBuilder := &ctrlruntimescheme.Builder{
-		GroupVersion: schema.GroupVersion{
-			Group:   "core.observatorium.io",
-			Version: "v1alpha1",
-		},
-	}
-	schemeBuilder.Register(&observatoriumAPIs.Observatorium{}, &observatoriumAPIs.ObservatoriumList{})
-	if err := schemeBuilder.AddToScheme(mgr.GetScheme()); err != nil {
-		setupLog.Error(err, "")
+	// probe the API servers of the managed clusters if the probes are enabled in the MultiClusterObservability
+	if err := mgr.Add(&clusterprobe.Prober{Client: mgr.GetClient()}); err != nil {
+		setupLog.Error(err, "unable to add the cluster API prober")
 		os.Exit(1)
 	}
 
-	if err = (&observabilityv1beta2.MultiClusterObservability{}).SetupWebhookWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create webhook", "webhook", "Captain")
-		os.Exit(1)
+	// setup ocm addon manager, only the leader signs and renews the certificates
+	if !namespaceScoped {
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			certctrl.Start()
+			<-ctx.Done()
+			return nil
+		})); err != nil {
+			setupLog.Error(err, "unable to add the certificate controller")
+			os.Exit(1)
+		}
 	}
 
-	// setup ocm addon manager
-	certctrl.Start()
-
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
+
+// setupControllers sets up the controllers with the manager, the controllers of the managed clusters are
+// only set up with the placementrule enabled
+func setupControllers(mgr manager.Manager, ocpClient ocpClientSet.Interface, crdClient crdClientSet.Interface,
+	placementRuleEnabled bool, staleAddonDryRun bool) error {
+	if err := (&mcoctrl.MultiClusterObservabilityReconciler{
+		Client:    mgr.GetClient(),
+		Log:       ctrl.Log.WithName("controllers").WithName("MultiClusterObservability"),
+		Scheme:    mgr.GetScheme(),
+		OcpClient: ocpClient,
+		CrdClient: crdClient,
+		APIReader: mgr.GetAPIReader(),
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("failed to set up the MultiClusterObservability controller: %v", err)
+	}
+	if !placementRuleEnabled {
+		return nil
+	}
+	if err := (&prctrl.PlacementRuleReconciler{
+		Client:           mgr.GetClient(),
+		Log:              ctrl.Log.WithName("controllers").WithName("PlacementRule"),
+		Scheme:           mgr.GetScheme(),
+		APIReader:        mgr.GetAPIReader(),
+		RESTMapper:       mgr.GetRESTMapper(),
+		StaleAddonDryRun: staleAddonDryRun,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("failed to set up the PlacementRule controller: %v", err)
+	}
+	if err := (&prctrl.AddonStatusReconciler{
+		Client: mgr.GetClient(),
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("failed to set up the AddonStatus controller: %v", err)
+	}
+	if err := (&prctrl.MetricsAllowlistReconciler{
+		Client: mgr.GetClient(),
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("failed to set up the MetricsAllowlist controller: %v", err)
+	}
+	// analyze the cardinality, the ingestion errors and the clock skew of the fleet periodically
+	if err := mgr.Add(&prctrl.FleetAnalyzer{Client: mgr.GetClient()}); err != nil {
+		return fmt.Errorf("failed to add the fleet analyzer: %v", err)
+	}
+	if err := (&prctrl.ClusterSetMappingReconciler{
+		Client: mgr.GetClient(),
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("failed to set up the ClusterSetMapping controller: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package main
+
+import (
+	"testing"
+
+	fakeconfigclient "github.com/openshift/client-go/config/clientset/versioned/fake"
+	fakecrdclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestSetupControllers(t *testing.T) {
+	mgr, err := ctrl.NewManager(&rest.Config{Host: "https://127.0.0.1:6443"}, ctrl.Options{
+		Scheme:                 scheme,
+		MetricsBindAddress:     "0",
+		HealthProbeBindAddress: "0",
+		MapperProvider: func(*rest.Config) (meta.RESTMapper, error) {
+			return meta.NewDefaultRESTMapper(nil), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create the manager: (%v)", err)
+	}
+
+	err = setupControllers(mgr, fakeconfigclient.NewSimpleClientset(), fakecrdclient.NewSimpleClientset(), true, false)
+	if err != nil {
+		t.Fatalf("failed to set up the controllers: (%v)", err)
+	}
+}
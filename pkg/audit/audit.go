@@ -0,0 +1,190 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package audit
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	// ConfigMapName is the configmap in the namespace of the operands which keeps the history of
+	// the changes applied to the fleet
+	ConfigMapName = "observability-audit-log"
+	// HistoryKey is the key of the history in the configmap, the oldest change first
+	HistoryKey = "history.yaml"
+
+	KindManifestWork = "ManifestWork"
+	KindAllowlist    = "Allowlist"
+	KindCertificate  = "Certificate"
+
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionDeleted = "deleted"
+	ActionRotated = "rotated"
+
+	// the size of the configmap is limited to 1MiB, the history keeps the latest changes only
+	maxEntries    = 200
+	maxDiffLength = 2048
+)
+
+var (
+	log     = logf.Log.WithName("audit")
+	mutex   sync.Mutex
+	pending []Entry
+)
+
+// Entry is a change which the operator applies to the fleet
+type Entry struct {
+	Timestamp string `yaml:"timestamp"`
+	Kind      string `yaml:"kind"`
+	// Cluster is the managed cluster which the change is pushed to, it is empty for the changes
+	// on the hub which apply to all the managed clusters
+	Cluster string `yaml:"cluster,omitempty"`
+	Name    string `yaml:"name"`
+	Action  string `yaml:"action"`
+	// Version identifies the applied content, e.g. the resource versions of the allowlists
+	Version string `yaml:"version,omitempty"`
+	// Diff is the lines removed with the prefix "-" and added with the prefix "+"
+	Diff string `yaml:"diff,omitempty"`
+}
+
+// Record logs the change and queues it to be written into the history by Flush
+func Record(entry Entry) {
+	if entry.Timestamp == "" {
+		entry.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	if len(entry.Diff) > maxDiffLength {
+		entry.Diff = entry.Diff[:maxDiffLength] + "\n... truncated"
+	}
+	log.Info("Applied the change to the fleet", "kind", entry.Kind, "cluster", entry.Cluster,
+		"name", entry.Name, "action", entry.Action, "version", entry.Version)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	pending = append(pending, entry)
+}
+
+// Flush appends the recorded changes into the history configmap, the changes are kept to be
+// written by the next flush if the configmap cannot be updated
+func Flush(c client.Client) error {
+	mutex.Lock()
+	entries := pending
+	pending = nil
+	mutex.Unlock()
+	if len(entries) == 0 {
+		return nil
+	}
+
+	err := appendHistory(c, entries)
+	if err != nil {
+		log.Error(err, "Failed to write the audit history", "configmap", ConfigMapName)
+		mutex.Lock()
+		pending = append(entries, pending...)
+		mutex.Unlock()
+	}
+	return err
+}
+
+func appendHistory(c client.Client, entries []Entry) error {
+	cm := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      ConfigMapName,
+		Namespace: config.GetDefaultNamespace(),
+	}, cm)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	history := []Entry{}
+	if exists {
+		if err := yaml.Unmarshal([]byte(cm.Data[HistoryKey]), &history); err != nil {
+			log.Error(err, "The audit history is corrupted, start a new one", "configmap", ConfigMapName)
+			history = []Entry{}
+		}
+	}
+	history = append(history, entries...)
+	if len(history) > maxEntries {
+		history = history[len(history)-maxEntries:]
+	}
+	data, err := yaml.Marshal(history)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ConfigMapName,
+				Namespace: config.GetDefaultNamespace(),
+			},
+			Data: map[string]string{HistoryKey: string(data)},
+		}
+		return c.Create(context.TODO(), cm)
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[HistoryKey] = string(data)
+	return c.Update(context.TODO(), cm)
+}
+
+// GetHistory returns the changes in the history configmap, the oldest change first
+func GetHistory(c client.Client) ([]Entry, error) {
+	cm := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      ConfigMapName,
+		Namespace: config.GetDefaultNamespace(),
+	}, cm)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return []Entry{}, nil
+		}
+		return nil, err
+	}
+	history := []Entry{}
+	err = yaml.Unmarshal([]byte(cm.Data[HistoryKey]), &history)
+	return history, err
+}
+
+// Diff returns the changed lines between the texts, the common leading and trailing lines are
+// skipped and the lines in between are removed from the old text with the prefix "-" and added
+// into the new text with the prefix "+"
+func Diff(oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	oldLines, newLines := strings.Split(oldText, "\n"), strings.Split(newText, "\n")
+	start := 0
+	for start < len(oldLines) && start < len(newLines) && oldLines[start] == newLines[start] {
+		start++
+	}
+	oldEnd, newEnd := len(oldLines), len(newLines)
+	for oldEnd > start && newEnd > start && oldLines[oldEnd-1] == newLines[newEnd-1] {
+		oldEnd--
+		newEnd--
+	}
+
+	var diff strings.Builder
+	for _, line := range oldLines[start:oldEnd] {
+		diff.WriteString("-" + line + "\n")
+	}
+	for _, line := range newLines[start:newEnd] {
+		diff.WriteString("+" + line + "\n")
+	}
+	return diff.String()
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package audit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDiff(t *testing.T) {
+	if diff := Diff("a\nb\nc", "a\nb\nc"); diff != "" {
+		t.Errorf("No diff should be returned for the same texts: %s", diff)
+	}
+	diff := Diff("a\nb\nc\nd", "a\nx\ny\nd")
+	if diff != "-b\n-c\n+x\n+y\n" {
+		t.Errorf("Wrong diff: %q", diff)
+	}
+	diff = Diff("", "a\nb")
+	if diff != "-\n+a\n+b\n" {
+		t.Errorf("Wrong diff of the new text: %q", diff)
+	}
+}
+
+func TestRecordAndFlush(t *testing.T) {
+	c := fake.NewFakeClientWithScheme(scheme.Scheme)
+
+	if err := Flush(c); err != nil {
+		t.Fatalf("Failed to flush no changes: (%v)", err)
+	}
+	Record(Entry{Kind: KindManifestWork, Cluster: "cluster1", Name: "cluster1-observability", Action: ActionCreated})
+	Record(Entry{Kind: KindAllowlist, Name: "observability-metrics-allowlist", Action: ActionUpdated,
+		Diff: strings.Repeat("+metric\n", maxDiffLength)})
+	if err := Flush(c); err != nil {
+		t.Fatalf("Failed to flush the changes: (%v)", err)
+	}
+
+	history, err := GetHistory(c)
+	if err != nil {
+		t.Fatalf("Failed to get the history: (%v)", err)
+	}
+	if len(history) != 2 || history[0].Cluster != "cluster1" || history[0].Timestamp == "" {
+		t.Fatalf("Wrong history: %v", history)
+	}
+	if !strings.HasSuffix(history[1].Diff, "truncated") {
+		t.Errorf("The long diff should be truncated")
+	}
+
+	for i := 0; i < maxEntries; i++ {
+		Record(Entry{Kind: KindManifestWork, Cluster: fmt.Sprintf("cluster%d", i), Action: ActionUpdated})
+	}
+	if err := Flush(c); err != nil {
+		t.Fatalf("Failed to flush the changes: (%v)", err)
+	}
+	history, err = GetHistory(c)
+	if err != nil {
+		t.Fatalf("Failed to get the history: (%v)", err)
+	}
+	if len(history) != maxEntries || history[0].Cluster != "cluster0" {
+		t.Errorf("The history should keep the latest %d changes: %d", maxEntries, len(history))
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package certificates
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// getAdditionalSANs returns the additional DNS names and IP addresses of the server certificate
+func getAdditionalSANs(mco *mcov1beta2.MultiClusterObservability) ([]string, []net.IP) {
+	dns := []string{}
+	ips := []net.IP{}
+	if mco.Spec.TLSConfig == nil {
+		return dns, ips
+	}
+	for _, san := range mco.Spec.TLSConfig.AdditionalSANs {
+		san = strings.TrimSpace(san)
+		if san == "" {
+			continue
+		}
+		if ip := net.ParseIP(san); ip != nil {
+			ips = append(ips, ip)
+		} else {
+			dns = append(dns, san)
+		}
+	}
+	return dns, ips
+}
+
+func formatAdditionalSANs(dns []string, ips []net.IP) string {
+	sans := append([]string{}, dns...)
+	for _, ip := range ips {
+		sans = append(sans, ip.String())
+	}
+	return strings.Join(sans, ",")
+}
+
+// updateAdditionalSANs reissues the server certificate when the additional SANs change. The hosts of
+// the certificate which are not the previous additional SANs, e.g. the endpoints of the route, are kept.
+func updateAdditionalSANs(c client.Client, mco *mcov1beta2.MultiClusterObservability) error {
+	crtSecret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: config.GetDefaultNamespace(), Name: serverCerts}, crtSecret)
+	if err != nil {
+		log.Error(err, "Failed to get the server certificate secret", "name", serverCerts)
+		return err
+	}
+	applied := crtSecret.Annotations[config.AdditionalSANsAnnotation]
+	if applied == formatAdditionalSANs(getAdditionalSANs(mco)) {
+		return nil
+	}
+	current, err := GetServerCertHosts(crtSecret)
+	if err != nil {
+		return err
+	}
+	previous := map[string]bool{
+		serverCertificateCN:                true,
+		config.GetObsAPISvc(mco.GetName()): true,
+	}
+	for _, san := range strings.Split(applied, ",") {
+		previous[san] = true
+	}
+	endpoints := []string{}
+	for _, host := range current {
+		if !previous[host] {
+			endpoints = append(endpoints, host)
+		}
+	}
+	log.Info("The additional SANs of the server certificate changed", "previous", applied)
+	return ReissueServerCerts(c, mco, endpoints, nil)
+}
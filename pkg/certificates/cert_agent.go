@@ -4,11 +4,16 @@
 package certificates
 
 import (
+	"context"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/open-cluster-management/addon-framework/pkg/agent"
 	addonapiv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
 	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
 )
 
 const (
@@ -45,6 +50,33 @@ func observabilitySignerConfigurations() func(cluster *clusterv1.ManagedCluster)
 				OrganizationUnits: []string{"acm"},
 			},
 		}
-		return append(agent.KubeClientSignerConfigurations(addonName, agentName)(cluster), observabilityConfig)
+		if subject := getClientCARotationSubject(); subject != "" {
+			observabilityConfig.Subject.OrganizationUnits = append(observabilityConfig.Subject.OrganizationUnits, subject)
+		}
+		configs := agent.KubeClientSignerConfigurations(addonName, agentName)(cluster)
+		if isTokenAuthCluster(cluster) {
+			// the collector authenticates with the service account tokens instead of the client certificate
+			return configs
+		}
+		return append(configs, observabilityConfig)
+	}
+}
+
+// isTokenAuthCluster returns true if the managed cluster is switched to the tokens of the collector
+// service account, the client certificate is still issued while the cluster switches back to it or if
+// its addon cannot be read
+func isTokenAuthCluster(cluster *clusterv1.ManagedCluster) bool {
+	c, err := getAddonClient()
+	if err != nil {
+		return false
+	}
+	addon := &addonapiv1alpha1.ManagedClusterAddOn{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: addonName, Namespace: cluster.Name}, addon)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.Error(err, "Failed to get the addon to check the authentication of the cluster", "cluster", cluster.Name)
+		}
+		return false
 	}
+	return addon.Annotations[config.AuthModeAnnotation] == config.TokenAuthMode
 }
@@ -15,11 +15,13 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/open-cluster-management/addon-framework/pkg/addonmanager"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/audit"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
 )
@@ -45,6 +47,12 @@ func Start() {
 		log.Error(err, "Failed to create kube client")
 		os.Exit(1)
 	}
+	// export the client certificates of the managed clusters which are signed before the operator starts
+	if addonClient, err := getAddonClient(); err != nil {
+		log.Error(err, "Failed to create the client to load the client certificates")
+	} else if err := loadClientCerts(addonClient); err != nil {
+		log.Error(err, "Failed to load the client certificates of the managed clusters")
+	}
 	watchlist := cache.NewListWatchFromClient(kubeClient.CoreV1().RESTClient(), "secrets", config.GetDefaultNamespace(),
 		fields.Everything())
 	_, controller := cache.NewInformer(
@@ -53,15 +61,20 @@ func Start() {
 		time.Minute*60,
 		cache.ResourceEventHandlerFuncs{
 			AddFunc: func(obj interface{}) {
+				recordCertSecret(*obj.(*v1.Secret))
 				restartPods(*kubeClient, *obj.(*v1.Secret))
 			},
 
 			DeleteFunc: func(obj interface{}) {
+				if s, ok := obj.(*v1.Secret); ok && isManagedCertSecret(s.Name) {
+					managedCerts.remove(s.Name, "")
+				}
 			},
 
 			UpdateFunc: func(oldObj, newObj interface{}) {
 				oldS := *oldObj.(*v1.Secret)
 				newS := *newObj.(*v1.Secret)
+				recordCertSecret(newS)
 				if !reflect.DeepEqual(oldS.Data, newS.Data) {
 					restartPods(*kubeClient, newS)
 				} else {
@@ -83,6 +96,18 @@ func Start() {
 						}
 						if err != nil {
 							log.Error(err, "Failed to renew the certificate", "name", newS.Name)
+							return
+						}
+						renewed := &v1.Secret{}
+						_ = c.Get(context.TODO(), types.NamespacedName{Name: newS.Name, Namespace: newS.Namespace}, renewed)
+						audit.Record(audit.Entry{
+							Kind:   audit.KindCertificate,
+							Name:   newS.Name,
+							Action: audit.ActionRotated,
+							Diff:   audit.Diff(getCertValidity(newS), getCertValidity(*renewed)),
+						})
+						if err := audit.Flush(c); err != nil {
+							log.Error(err, "Failed to record the renewal of the certificate", "name", newS.Name)
 						}
 					}
 				}
@@ -127,6 +152,21 @@ func updateDeployLabel(c kubernetes.Clientset, dName string, sTime time.Time) {
 	}
 }
 
+// getCertValidity returns the validity of the certificate in the secret to be recorded in the
+// audit history, the certificate itself is not recorded
+func getCertValidity(s v1.Secret) string {
+	block, _ := pem.Decode(s.Data["tls.crt"])
+	if block == nil {
+		return ""
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("serialNumber: %s\nnotBefore: %s\nnotAfter: %s", cert.SerialNumber.String(),
+		cert.NotBefore.UTC().Format(time.RFC3339), cert.NotAfter.UTC().Format(time.RFC3339))
+}
+
 func needsRenew(s v1.Secret) bool {
 	certSecretNames := []string{serverCACerts, clientCACerts, grafanaCerts}
 	if !util.Contains(certSecretNames, s.Name) {
@@ -0,0 +1,210 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package certificates
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+const (
+	// the name of the client certificate of the managed clusters in the metrics, which is its user
+	clientCertName = "managed-cluster-observability"
+	// the label of the CSR with the name of the managed cluster which requests it
+	csrClusterLabel = "open-cluster-management.io/cluster-name"
+)
+
+var (
+	certExpiryDesc = prometheus.NewDesc("acm_observability_certificate_expiry_seconds",
+		"The seconds until the certificate managed by the operator expires.",
+		[]string{"name", "managed_cluster"}, nil)
+	certIssuedDesc = prometheus.NewDesc("acm_observability_certificate_issued_timestamp_seconds",
+		"The time when the certificate managed by the operator is issued, in seconds since the epoch.",
+		[]string{"name", "managed_cluster"}, nil)
+	certInfoDesc = prometheus.NewDesc("acm_observability_certificate_info",
+		"The serial number and the issuer of the certificate managed by the operator.",
+		[]string{"name", "managed_cluster", "serial", "issuer"}, nil)
+
+	managedCerts = &certCollector{certs: map[certKey]certInfo{}}
+)
+
+func init() {
+	metrics.Registry.MustRegister(managedCerts)
+}
+
+// certKey is the certificate in the secret on the hub, or the client certificate of the managed cluster
+type certKey struct {
+	name    string
+	cluster string
+}
+
+type certInfo struct {
+	notBefore time.Time
+	notAfter  time.Time
+	serial    string
+	issuer    string
+}
+
+// certCollector exports the validity of the certificates which the operator manages, the seconds
+// until the expiry are computed when the metrics are scraped
+type certCollector struct {
+	sync.Mutex
+	certs map[certKey]certInfo
+}
+
+func (c *certCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- certExpiryDesc
+	ch <- certIssuedDesc
+	ch <- certInfoDesc
+}
+
+func (c *certCollector) Collect(ch chan<- prometheus.Metric) {
+	c.Lock()
+	defer c.Unlock()
+	now := time.Now()
+	for key, info := range c.certs {
+		ch <- prometheus.MustNewConstMetric(certExpiryDesc, prometheus.GaugeValue,
+			info.notAfter.Sub(now).Seconds(), key.name, key.cluster)
+		ch <- prometheus.MustNewConstMetric(certIssuedDesc, prometheus.GaugeValue,
+			float64(info.notBefore.Unix()), key.name, key.cluster)
+		ch <- prometheus.MustNewConstMetric(certInfoDesc, prometheus.GaugeValue, 1,
+			key.name, key.cluster, info.serial, info.issuer)
+	}
+}
+
+func (c *certCollector) set(name, cluster string, info certInfo) {
+	c.Lock()
+	defer c.Unlock()
+	c.certs[certKey{name: name, cluster: cluster}] = info
+}
+
+func (c *certCollector) remove(name, cluster string) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.certs, certKey{name: name, cluster: cluster})
+}
+
+func newCertInfo(cert *x509.Certificate) certInfo {
+	return certInfo{
+		notBefore: cert.NotBefore,
+		notAfter:  cert.NotAfter,
+		serial:    cert.SerialNumber.String(),
+		issuer:    cert.Issuer.CommonName,
+	}
+}
+
+// isManagedCertSecret returns true for the secrets of the certificates which the operator manages on the hub
+func isManagedCertSecret(name string) bool {
+	return util.Contains([]string{
+		serverCACerts, clientCACerts, config.ServerIntermediateCACerts, config.ClientIntermediateCACerts,
+		serverCerts, grafanaCerts, config.OTLPReceiverCerts, config.LokiCerts, config.QueryEndpointCerts,
+	}, name)
+}
+
+// recordCertSecret exports the validity of the certificate in the secret if the operator manages it
+func recordCertSecret(s v1.Secret) {
+	if !isManagedCertSecret(s.Name) {
+		return
+	}
+	block, _ := pem.Decode(s.Data["tls.crt"])
+	if block == nil {
+		managedCerts.remove(s.Name, "")
+		return
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		managedCerts.remove(s.Name, "")
+		return
+	}
+	managedCerts.set(s.Name, "", newCertInfo(cert))
+}
+
+func getAddonClient() (client.Client, error) {
+	cfg, err := clientcmd.BuildConfigFromFlags("", "")
+	if err != nil {
+		return nil, err
+	}
+	s := runtime.NewScheme()
+	if err := addonv1alpha1.AddToScheme(s); err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: s})
+}
+
+// recordClientCert exports the validity of the client certificate which is signed for the managed
+// cluster, and records it on the addon of the managed cluster
+func recordClientCert(c client.Client, csr *certificatesv1.CertificateSigningRequest, signedCert []byte) {
+	cluster := csr.Labels[csrClusterLabel]
+	block, _ := pem.Decode(signedCert)
+	if cluster == "" || block == nil {
+		return
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return
+	}
+	info := newCertInfo(cert)
+	managedCerts.set(clientCertName, cluster, info)
+
+	addon := &addonv1alpha1.ManagedClusterAddOn{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: addonName, Namespace: cluster}, addon)
+	if err != nil {
+		log.Error(err, "Failed to get the managedclusteraddon to record the client certificate", "cluster", cluster)
+		return
+	}
+	if addon.Annotations == nil {
+		addon.Annotations = map[string]string{}
+	}
+	addon.Annotations[config.ClientCertNotBeforeAnnotation] = info.notBefore.UTC().Format(time.RFC3339)
+	addon.Annotations[config.ClientCertNotAfterAnnotation] = info.notAfter.UTC().Format(time.RFC3339)
+	addon.Annotations[config.ClientCertSerialAnnotation] = info.serial
+	addon.Annotations[config.ClientCertIssuerAnnotation] = info.issuer
+	if err := c.Update(context.TODO(), addon); err != nil {
+		log.Error(err, "Failed to record the client certificate on the managedclusteraddon", "cluster", cluster)
+	}
+}
+
+// loadClientCerts exports the validity of the client certificates recorded on the addons of the managed clusters
+func loadClientCerts(c client.Client) error {
+	addons := &addonv1alpha1.ManagedClusterAddOnList{}
+	if err := c.List(context.TODO(), addons); err != nil {
+		return err
+	}
+	for _, addon := range addons.Items {
+		if addon.Name != addonName {
+			continue
+		}
+		notBefore, err := time.Parse(time.RFC3339, addon.Annotations[config.ClientCertNotBeforeAnnotation])
+		if err != nil {
+			continue
+		}
+		notAfter, err := time.Parse(time.RFC3339, addon.Annotations[config.ClientCertNotAfterAnnotation])
+		if err != nil {
+			continue
+		}
+		managedCerts.set(clientCertName, addon.Namespace, certInfo{
+			notBefore: notBefore,
+			notAfter:  notAfter,
+			serial:    addon.Annotations[config.ClientCertSerialAnnotation],
+			issuer:    addon.Annotations[config.ClientCertIssuerAnnotation],
+		})
+	}
+	return nil
+}
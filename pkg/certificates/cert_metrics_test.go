@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package certificates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func TestRecordCertificates(t *testing.T) {
+	managedCerts.certs = map[certKey]certInfo{}
+	namespace := mcoconfig.GetDefaultNamespace()
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "observability"},
+	}
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	addonv1alpha1.AddToScheme(s)
+	addon := &addonv1alpha1.ManagedClusterAddOn{
+		ObjectMeta: metav1.ObjectMeta{Name: addonName, Namespace: "cluster1"},
+	}
+	c := fake.NewFakeClient(addon)
+	if err := CreateObservabilityCerts(c, s, mco); err != nil {
+		t.Fatalf("CreateObservabilityCerts: (%v)", err)
+	}
+
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: serverCerts, Namespace: namespace}, secret)
+	if err != nil {
+		t.Fatalf("Failed to get the server certificate secret: (%v)", err)
+	}
+	recordCertSecret(*secret)
+	recordCertSecret(corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other-certs"}})
+	info, ok := managedCerts.certs[certKey{name: serverCerts}]
+	if !ok || len(managedCerts.certs) != 1 {
+		t.Fatalf("only the server certificate should be recorded: %v", managedCerts.certs)
+	}
+	if info.issuer != serverCACertifcateCN || time.Until(info.notAfter) < 364*24*time.Hour {
+		t.Errorf("unexpected validity of the server certificate: %v", info)
+	}
+
+	// the client certificate signed for the managed cluster is recorded on its addon
+	csr := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{csrClusterLabel: "cluster1"}},
+	}
+	recordClientCert(c, csr, secret.Data["tls.crt"])
+	managedCerts.certs = map[certKey]certInfo{}
+	if err := loadClientCerts(c); err != nil {
+		t.Fatalf("Failed to load the client certificates: (%v)", err)
+	}
+	loaded, ok := managedCerts.certs[certKey{name: clientCertName, cluster: "cluster1"}]
+	if !ok || loaded.serial != info.serial || !loaded.notAfter.Equal(info.notAfter) {
+		t.Errorf("the client certificate should be loaded from the addon: %v", managedCerts.certs)
+	}
+}
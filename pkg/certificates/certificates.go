@@ -11,6 +11,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"fmt"
 	"math/big"
 	"net"
 	"time"
@@ -55,6 +56,14 @@ func CreateObservabilityCerts(c client.Client, scheme *runtime.Scheme, mco *mcov
 	if err != nil {
 		return err
 	}
+	err = rotateClientCA(c, mco)
+	if err != nil {
+		return err
+	}
+	err = updateIntermediateCAs(c, scheme, mco)
+	if err != nil {
+		return err
+	}
 
 	hosts := []string{config.GetObsAPISvc(mco.GetName())}
 	url, err := config.GetObsAPIUrl(c, config.GetDefaultNamespace())
@@ -63,7 +72,13 @@ func CreateObservabilityCerts(c client.Client, scheme *runtime.Scheme, mco *mcov
 	} else {
 		hosts = append(hosts, url)
 	}
-	err = createCertSecret(c, scheme, mco, false, serverCerts, true, serverCertificateCN, nil, hosts, nil)
+	dnsSANs, ipSANs := getAdditionalSANs(mco)
+	err = createCertSecret(c, scheme, mco, false, serverCerts, true, serverCertificateCN, nil,
+		append(hosts, dnsSANs...), ipSANs)
+	if err != nil {
+		return err
+	}
+	err = updateAdditionalSANs(c, mco)
 	if err != nil {
 		return err
 	}
@@ -73,6 +88,55 @@ func CreateObservabilityCerts(c client.Client, scheme *runtime.Scheme, mco *mcov
 		return err
 	}
 
+	if config.IsOTLPReceiverEnabled(mco) {
+		hosts := []string{config.GetOTLPReceiverSvc()}
+		url, err := config.GetOTLPReceiverUrl(c, config.GetDefaultNamespace())
+		if err != nil {
+			log.Info("Failed to get otlp receiver route address", "error", err.Error())
+		} else {
+			hosts = append(hosts, url)
+		}
+		err = createCertSecret(c, scheme, mco, false, config.OTLPReceiverCerts, true,
+			config.OTLPReceiverCertCN, nil, hosts, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.IsQueryEndpointEnabled(mco) {
+		hosts := []string{}
+		if mco.Spec.QueryEndpoint.Host != "" {
+			hosts = append(hosts, mco.Spec.QueryEndpoint.Host)
+		} else if host, err := config.GetQueryEndpointHost(c, config.GetDefaultNamespace()); err != nil {
+			log.Info("Failed to get query endpoint route address", "error", err.Error())
+		} else if host != "" {
+			hosts = append(hosts, host)
+		}
+		// the certificate is issued once the host of the route is known
+		if len(hosts) > 0 {
+			err = createCertSecret(c, scheme, mco, false, config.QueryEndpointCerts, true,
+				config.QueryEndpointCertCN, nil, hosts, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if config.IsHubLokiEnabled(mco) {
+		hosts := []string{config.GetLokiSvc()}
+		url, err := config.GetLokiUrl(c, config.GetDefaultNamespace())
+		if err != nil {
+			log.Info("Failed to get loki route address", "error", err.Error())
+		} else {
+			hosts = append(hosts, url)
+		}
+		err = createCertSecret(c, scheme, mco, false, config.LokiCerts, true,
+			config.LokiCertCN, nil, hosts, nil)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -209,8 +273,8 @@ func createCertSecret(c client.Client,
 					Namespace: config.GetDefaultNamespace(),
 				},
 				Data: map[string][]byte{
-					"ca.crt":  caSecret.Data["tls.crt"],
-					"tls.crt": certPEM.Bytes(),
+					"ca.crt":  getCABundle(caSecret),
+					"tls.crt": getCertChain(certPEM.Bytes(), caSecret),
 					"tls.key": keyPEM.Bytes(),
 				},
 			}
@@ -244,8 +308,8 @@ func createCertSecret(c client.Client,
 				return err
 			}
 			certPEM, keyPEM := pemEncode(cert, key)
-			crtSecret.Data["ca.crt"] = caSecret.Data["tls.crt"]
-			crtSecret.Data["tls.crt"] = certPEM.Bytes()
+			crtSecret.Data["ca.crt"] = getCABundle(caSecret)
+			crtSecret.Data["tls.crt"] = getCertChain(certPEM.Bytes(), caSecret)
 			crtSecret.Data["tls.key"] = keyPEM.Bytes()
 			if err := c.Update(context.TODO(), crtSecret); err != nil {
 				log.Error(err, "Failed to update secret", "name", name)
@@ -311,30 +375,56 @@ func createCertificate(isServer bool, cn string, ou []string, dns []string, ips
 	return keyBytes, caBytes, nil
 }
 
+// getCA returns the CA which signs the leaf certificates, which is the intermediate CA if it exists
 func getCA(c client.Client, isServer bool) (*corev1.Secret, *x509.Certificate, *rsa.PrivateKey, error) {
 	caCertName := serverCACerts
+	intermediateName := config.ServerIntermediateCACerts
 	if !isServer {
 		caCertName = clientCACerts
+		intermediateName = config.ClientIntermediateCACerts
 	}
 	caSecret := &corev1.Secret{}
-	err := c.Get(context.TODO(), types.NamespacedName{Namespace: config.GetDefaultNamespace(), Name: caCertName}, caSecret)
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: config.GetDefaultNamespace(), Name: intermediateName}, caSecret)
+	if err == nil {
+		caCertName = intermediateName
+	} else if errors.IsNotFound(err) {
+		err = c.Get(context.TODO(), types.NamespacedName{Namespace: config.GetDefaultNamespace(), Name: caCertName}, caSecret)
+	}
 	if err != nil {
 		log.Error(err, "Failed to get ca secret", "name", caCertName)
 		return nil, nil, nil, err
 	}
+	caCert, caKey, err := parseCASecret(caSecret)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return caSecret, caCert, caKey, nil
+}
+
+func parseCASecret(caSecret *corev1.Secret) (*x509.Certificate, *rsa.PrivateKey, error) {
 	block1, _ := pem.Decode(caSecret.Data["tls.crt"])
+	if block1 == nil {
+		err := fmt.Errorf("no certificate found in the secret %s", caSecret.Name)
+		log.Error(err, "Failed to parse ca cert", "name", caSecret.Name)
+		return nil, nil, err
+	}
 	caCert, err := x509.ParseCertificate(block1.Bytes)
 	if err != nil {
-		log.Error(err, "Failed to parse ca cert", "name", caCertName)
-		return nil, nil, nil, err
+		log.Error(err, "Failed to parse ca cert", "name", caSecret.Name)
+		return nil, nil, err
 	}
 	block2, _ := pem.Decode(caSecret.Data["tls.key"])
+	if block2 == nil {
+		err := fmt.Errorf("no private key found in the secret %s", caSecret.Name)
+		log.Error(err, "Failed to parse ca key", "name", caSecret.Name)
+		return nil, nil, err
+	}
 	caKey, err := x509.ParsePKCS1PrivateKey(block2.Bytes)
 	if err != nil {
-		log.Error(err, "Failed to parse ca key", "name", caCertName)
-		return nil, nil, nil, err
+		log.Error(err, "Failed to parse ca key", "name", caSecret.Name)
+		return nil, nil, err
 	}
-	return caSecret, caCert, caKey, nil
+	return caCert, caKey, nil
 }
 
 func pemEncode(cert []byte, key []byte) (*bytes.Buffer, *bytes.Buffer) {
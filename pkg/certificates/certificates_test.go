@@ -4,13 +4,21 @@
 package certificates
 
 import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"testing"
 
 	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
 	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
 	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
 )
@@ -50,3 +58,205 @@ func TestCreateCertificates(t *testing.T) {
 	}
 
 }
+
+func TestAdditionalSANs(t *testing.T) {
+	namespace := mcoconfig.GetDefaultNamespace()
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			TLSConfig: &mcov1beta2.TLSConfigSpec{AdditionalSANs: []string{"obs.example.com", "10.0.0.1"}},
+		},
+	}
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "observatorium-api",
+			Namespace: namespace,
+		},
+		Spec: routev1.RouteSpec{
+			Host: "apiServerURL",
+		},
+	}
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	routev1.AddToScheme(s)
+	c := fake.NewFakeClient(route)
+
+	getSANs := func() ([]string, []string) {
+		secret := &corev1.Secret{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: serverCerts, Namespace: namespace}, secret)
+		if err != nil {
+			t.Fatalf("Failed to get the server certificate secret: (%v)", err)
+		}
+		hosts, err := GetServerCertHosts(secret)
+		if err != nil {
+			t.Fatalf("Failed to get the hosts of the server certificate: (%v)", err)
+		}
+		ips, err := getServerCertIPs(secret)
+		if err != nil {
+			t.Fatalf("Failed to get the IPs of the server certificate: (%v)", err)
+		}
+		return hosts, ips
+	}
+
+	err := CreateObservabilityCerts(c, s, mco)
+	if err != nil {
+		t.Fatalf("CreateObservabilityCerts: (%v)", err)
+	}
+	hosts, ips := getSANs()
+	if !sameHosts(hosts, []string{serverCertificateCN, mcoconfig.GetObsAPISvc(mco.Name), "apiServerURL", "obs.example.com"}) {
+		t.Errorf("the additional DNS names should be in the server certificate: %v", hosts)
+	}
+	if !sameHosts(ips, []string{"10.0.0.1"}) {
+		t.Errorf("the additional IPs should be in the server certificate: %v", ips)
+	}
+
+	mco.Spec.TLSConfig.AdditionalSANs = []string{"obs.internal.example.com"}
+	err = CreateObservabilityCerts(c, s, mco)
+	if err != nil {
+		t.Fatalf("CreateObservabilityCerts: (%v)", err)
+	}
+	hosts, ips = getSANs()
+	if !sameHosts(hosts, []string{serverCertificateCN, mcoconfig.GetObsAPISvc(mco.Name), "apiServerURL",
+		"obs.internal.example.com"}) {
+		t.Errorf("the server certificate should be reissued for the new SANs: %v", hosts)
+	}
+	if len(ips) != 0 {
+		t.Errorf("the removed IPs should not be in the server certificate: %v", ips)
+	}
+}
+
+func TestIntermediateCA(t *testing.T) {
+	namespace := mcoconfig.GetDefaultNamespace()
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			TLSConfig: &mcov1beta2.TLSConfigSpec{IntermediateCA: &mcov1beta2.IntermediateCASpec{Enabled: true}},
+		},
+	}
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	c := fake.NewFakeClient()
+
+	err := CreateObservabilityCerts(c, s, mco)
+	if err != nil {
+		t.Fatalf("CreateObservabilityCerts: (%v)", err)
+	}
+	getSecret := func(name string) *corev1.Secret {
+		secret := &corev1.Secret{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, secret)
+		if err != nil {
+			t.Fatalf("Failed to get the secret %s: (%v)", name, err)
+		}
+		return secret
+	}
+	parseChain := func(data []byte) []*x509.Certificate {
+		certs := []*x509.Certificate{}
+		for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatalf("Failed to parse the certificate: (%v)", err)
+			}
+			certs = append(certs, cert)
+		}
+		return certs
+	}
+
+	// the server certificate is verified by the root with the intermediate CA in its chain
+	root := parseChain(getSecret(serverCACerts).Data["tls.crt"])[0]
+	server := getSecret(serverCerts)
+	chain := parseChain(server.Data["tls.crt"])
+	if len(chain) != 2 || chain[1].Subject.CommonName != serverIntermediateCACN {
+		t.Fatalf("the server certificate should be followed by the intermediate CA: %v", chain)
+	}
+	roots, intermediates := x509.NewCertPool(), x509.NewCertPool()
+	roots.AddCert(root)
+	intermediates.AddCert(chain[1])
+	_, err = chain[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+	if err != nil {
+		t.Errorf("the server certificate should be verified by the root CA: (%v)", err)
+	}
+	if len(parseChain(server.Data["ca.crt"])) != 2 {
+		t.Errorf("the full chain should be in the ca.crt of the server certificate")
+	}
+	getSecret(mcoconfig.ClientIntermediateCACerts)
+
+	mco.Spec.TLSConfig.IntermediateCA.Enabled = false
+	err = CreateObservabilityCerts(c, s, mco)
+	if err != nil {
+		t.Fatalf("CreateObservabilityCerts: (%v)", err)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: mcoconfig.ServerIntermediateCACerts, Namespace: namespace},
+		&corev1.Secret{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("the intermediate CA should be removed once it is disabled: (%v)", err)
+	}
+}
+
+func TestClientCARotation(t *testing.T) {
+	namespace := mcoconfig.GetDefaultNamespace()
+	mco := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			TLSConfig: &mcov1beta2.TLSConfigSpec{ClientCARotationID: "2021-10"},
+		},
+	}
+	s := scheme.Scheme
+	mcov1beta2.SchemeBuilder.AddToScheme(s)
+	addonv1alpha1.AddToScheme(s)
+	newAddon := func(cluster string, annotations map[string]string) *addonv1alpha1.ManagedClusterAddOn {
+		return &addonv1alpha1.ManagedClusterAddOn{
+			ObjectMeta: metav1.ObjectMeta{Name: addonName, Namespace: cluster, Annotations: annotations},
+		}
+	}
+	c := fake.NewFakeClient(
+		newAddon("cluster-certs", map[string]string{mcoconfig.ClientCertNotAfterAnnotation: "2030-01-01T00:00:00Z"}),
+		newAddon("cluster-token", map[string]string{mcoconfig.AuthModeAnnotation: mcoconfig.TokenAuthMode}),
+	)
+	err := createCASecret(c, s, mco, false, clientCACerts, clientCACertificateCN)
+	if err != nil {
+		t.Fatalf("Failed to create the client CA: (%v)", err)
+	}
+	previous := &corev1.Secret{}
+	_ = c.Get(context.TODO(), types.NamespacedName{Name: clientCACerts, Namespace: namespace}, previous)
+
+	// both client CAs are trusted until the client certificates are reissued
+	if err := rotateClientCA(c, mco); err != nil {
+		t.Fatalf("Failed to rotate the client CA: (%v)", err)
+	}
+	rotating, pending, err := GetClientCARotationProgress(c)
+	if err != nil || !rotating || len(pending) != 1 || pending[0] != "cluster-certs" {
+		t.Fatalf("the client certificate of cluster-certs should be reissued: %v %v (%v)", rotating, pending, err)
+	}
+	rotated := &corev1.Secret{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: clientCACerts, Namespace: namespace}, rotated)
+	if err != nil {
+		t.Fatalf("Failed to get the client CA: (%v)", err)
+	}
+	if bytes.Equal(rotated.Data["tls.key"], previous.Data["tls.key"]) ||
+		!bytes.HasSuffix(rotated.Data["ca.crt"], previous.Data["tls.crt"]) {
+		t.Errorf("the client CA should be rotated with a new key and trust the previous one")
+	}
+
+	// the previous client CA is removed once the client certificates are reissued
+	addon := &addonv1alpha1.ManagedClusterAddOn{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: addonName, Namespace: "cluster-certs"}, addon)
+	if err != nil {
+		t.Fatalf("Failed to get the managedclusteraddon: (%v)", err)
+	}
+	if addon.Annotations == nil {
+		addon.Annotations = map[string]string{}
+	}
+	addon.Annotations[mcoconfig.ClientCertNotAfterAnnotation] = "2031-01-01T00:00:00Z"
+	if err := c.Update(context.TODO(), addon); err != nil {
+		t.Fatalf("Failed to record the client certificate: (%v)", err)
+	}
+	if err := rotateClientCA(c, mco); err != nil {
+		t.Fatalf("Failed to rotate the client CA: (%v)", err)
+	}
+	if rotating, _, _ := GetClientCARotationProgress(c); rotating {
+		t.Errorf("the client CA rotation should be completed")
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package certificates
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+func getClientCARotationID(mco *mcov1beta2.MultiClusterObservability) string {
+	if mco.Spec.TLSConfig == nil {
+		return ""
+	}
+	return mco.Spec.TLSConfig.ClientCARotationID
+}
+
+// rotateClientCA rotates the client CA with a new private key once the clientCARotationID in the spec
+// changes. The previous client CA stays in the bundle which the gateway trusts until the client
+// certificates of all the managed clusters are reissued by the new client CA.
+func rotateClientCA(c client.Client, mco *mcov1beta2.MultiClusterObservability) error {
+	caSecret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: config.GetDefaultNamespace(), Name: clientCACerts}, caSecret)
+	if err != nil {
+		log.Error(err, "Failed to get ca secret", "name", clientCACerts)
+		return err
+	}
+
+	rotationID := getClientCARotationID(mco)
+	if rotationID != "" && caSecret.Annotations[config.ClientCARotationAnnotation] != rotationID {
+		return startClientCARotation(c, caSecret, rotationID)
+	}
+	if bytes.Equal(caSecret.Data["ca.crt"], caSecret.Data["tls.crt"]) {
+		return nil
+	}
+	pending, err := getClientCARotationPending(c)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		log.Info("Waiting for the client certificates to be reissued by the new client CA", "clusters", len(pending))
+		return nil
+	}
+	// the previous client CA is not trusted any more
+	caSecret.Data["ca.crt"] = caSecret.Data["tls.crt"]
+	if err := c.Update(context.TODO(), caSecret); err != nil {
+		log.Error(err, "Failed to update secret", "name", clientCACerts)
+		return err
+	}
+	log.Info("Client CA rotation completed", "rotationID", rotationID)
+	return nil
+}
+
+func startClientCARotation(c client.Client, caSecret *corev1.Secret, rotationID string) error {
+	log.Info("To rotate the client CA", "rotationID", rotationID)
+	key, cert, err := createCACertificate(clientCACertificateCN, nil)
+	if err != nil {
+		return err
+	}
+	certPEM, keyPEM := pemEncode(cert, key)
+	caSecret.Data["ca.crt"] = append(append([]byte{}, certPEM.Bytes()...), caSecret.Data["ca.crt"]...)
+	caSecret.Data["tls.crt"] = certPEM.Bytes()
+	caSecret.Data["tls.key"] = keyPEM.Bytes()
+	if caSecret.Annotations == nil {
+		caSecret.Annotations = map[string]string{}
+	}
+	caSecret.Annotations[config.ClientCARotationAnnotation] = rotationID
+	caSecret.Annotations[config.ClientCARotatedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	if err := c.Update(context.TODO(), caSecret); err != nil {
+		log.Error(err, "Failed to update secret", "name", clientCACerts)
+		return err
+	}
+
+	// the intermediate CA is signed by the new client CA again in the reconcile
+	intermediate := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: config.ClientIntermediateCACerts, Namespace: config.GetDefaultNamespace()},
+	}
+	if err := c.Delete(context.TODO(), intermediate); err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to delete the intermediate CA", "name", config.ClientIntermediateCACerts)
+		return err
+	}
+
+	// the client certificates are recorded again once they are reissued by the new client CA
+	addons, err := listObservabilityAddons(c)
+	if err != nil {
+		return err
+	}
+	for i := range addons {
+		addon := &addons[i]
+		if addon.Annotations[config.ClientCertNotAfterAnnotation] == "" {
+			continue
+		}
+		for _, key := range []string{config.ClientCertNotBeforeAnnotation, config.ClientCertNotAfterAnnotation,
+			config.ClientCertSerialAnnotation, config.ClientCertIssuerAnnotation} {
+			delete(addon.Annotations, key)
+		}
+		if err := c.Update(context.TODO(), addon); err != nil {
+			log.Error(err, "Failed to reset the client certificate on the managedclusteraddon", "cluster", addon.Namespace)
+			return err
+		}
+		managedCerts.remove(clientCertName, addon.Namespace)
+	}
+	log.Info("Client CA rotated", "rotationID", rotationID)
+	return nil
+}
+
+func listObservabilityAddons(c client.Client) ([]addonv1alpha1.ManagedClusterAddOn, error) {
+	addons := &addonv1alpha1.ManagedClusterAddOnList{}
+	if err := c.List(context.TODO(), addons); err != nil {
+		log.Error(err, "Failed to list the managedclusteraddons")
+		return nil, err
+	}
+	items := []addonv1alpha1.ManagedClusterAddOn{}
+	for _, addon := range addons.Items {
+		if addon.Name == addonName {
+			items = append(items, addon)
+		}
+	}
+	return items, nil
+}
+
+// getClientCARotationPending returns the managed clusters whose client certificates are not reissued by
+// the new client CA yet, the clusters which authenticate with the service account tokens are skipped
+func getClientCARotationPending(c client.Client) ([]string, error) {
+	addons, err := listObservabilityAddons(c)
+	if err != nil {
+		return nil, err
+	}
+	pending := []string{}
+	for _, addon := range addons {
+		if addon.Annotations[config.AuthModeAnnotation] == config.TokenAuthMode {
+			continue
+		}
+		if addon.Annotations[config.ClientCertNotAfterAnnotation] == "" {
+			pending = append(pending, addon.Namespace)
+		}
+	}
+	return pending, nil
+}
+
+// GetClientCARotationProgress returns whether the previous client CA is still trusted, and the managed
+// clusters whose client certificates are not reissued by the new client CA yet
+func GetClientCARotationProgress(c client.Client) (bool, []string, error) {
+	caSecret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: config.GetDefaultNamespace(), Name: clientCACerts}, caSecret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	if bytes.Equal(caSecret.Data["ca.crt"], caSecret.Data["tls.crt"]) {
+		return false, nil, nil
+	}
+	pending, err := getClientCARotationPending(c)
+	return true, pending, err
+}
+
+// getClientCARotationSubject returns the organization unit which carries the rotation of the client CA in
+// the subject of the client certificates, the managed clusters request a new client certificate once the
+// subject changes
+func getClientCARotationSubject() string {
+	c, err := getClient()
+	if err != nil {
+		return ""
+	}
+	caSecret := &corev1.Secret{}
+	err = c.Get(context.TODO(), types.NamespacedName{Namespace: config.GetDefaultNamespace(), Name: clientCACerts}, caSecret)
+	if err != nil || caSecret.Annotations[config.ClientCARotationAnnotation] == "" {
+		return ""
+	}
+	return "rotation-" + caSecret.Annotations[config.ClientCARotationAnnotation]
+}
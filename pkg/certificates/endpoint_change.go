@@ -0,0 +1,171 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package certificates
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+// GetServerCertHosts returns the DNS names which the server certificate of the observatorium api
+// is issued for
+func GetServerCertHosts(secret *corev1.Secret) ([]string, error) {
+	block, _ := pem.Decode(secret.Data["tls.crt"])
+	if block == nil {
+		return nil, fmt.Errorf("no certificate found in the secret %s", secret.Name)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return cert.DNSNames, nil
+}
+
+// getServerCertIPs returns the IP addresses which the server certificate of the observatorium api
+// is issued for
+func getServerCertIPs(secret *corev1.Secret) ([]string, error) {
+	block, _ := pem.Decode(secret.Data["tls.crt"])
+	if block == nil {
+		return nil, fmt.Errorf("no certificate found in the secret %s", secret.Name)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ips := []string{}
+	for _, ip := range cert.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+	return ips, nil
+}
+
+// ReissueServerCerts issues the server certificate of the observatorium api for the endpoints, the
+// additional SANs and the hosts of the cluster proxies with the same private key, and records the
+// annotations on the secret in the same update so that the annotations never claim an endpoint which
+// the certificate does not cover. An empty annotation value removes the annotation. The certificate is
+// kept if it already covers exactly the endpoints, the additional SANs and the cluster proxies.
+func ReissueServerCerts(c client.Client, mco *mcov1beta2.MultiClusterObservability,
+	endpoints []string, annotations map[string]string) error {
+	crtSecret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: config.GetDefaultNamespace(), Name: serverCerts}, crtSecret)
+	if err != nil {
+		log.Error(err, "Failed to get the server certificate secret", "name", serverCerts)
+		return err
+	}
+	dnsSANs, ipSANs := getAdditionalSANs(mco)
+	hosts := append([]string{config.GetObsAPISvc(mco.GetName())}, endpoints...)
+	hosts = append(hosts, dnsSANs...)
+	// the hosts of the cluster proxies are kept across the changes of the other endpoints
+	proxyHosts := config.GetServerCertProxyHosts(crtSecret)
+	if value, ok := annotations[config.ObsAPIProxyEndpointsAnnotation]; ok {
+		proxyHosts = nil
+		if value != "" {
+			proxyHosts = strings.Split(value, ",")
+		}
+	}
+	certIPs := append([]net.IP{}, ipSANs...)
+	for _, host := range proxyHosts {
+		if ip := net.ParseIP(host); ip != nil {
+			certIPs = append(certIPs, ip)
+		} else if !containsHost(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	ips := []string{}
+	for _, ip := range certIPs {
+		ips = append(ips, ip.String())
+	}
+
+	current, err := GetServerCertHosts(crtSecret)
+	if err != nil {
+		log.Info("Wrong server certificate found, create new one", "error", err.Error())
+	}
+	currentIPs, _ := getServerCertIPs(crtSecret)
+	if err != nil || !sameHosts(current, append([]string{serverCertificateCN}, hosts...)) ||
+		!sameHosts(currentIPs, ips) {
+		caSecret, caCert, caKey, err := getCA(c, true)
+		if err != nil {
+			return err
+		}
+		var crtKey *rsa.PrivateKey
+		if block, _ := pem.Decode(crtSecret.Data["tls.key"]); block != nil {
+			crtKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				log.Error(err, "Wrong private key found, create new one", "name", serverCerts)
+				crtKey = nil
+			}
+		}
+		log.Info("Reissuing the server certificate", "hosts", hosts, "ips", ips)
+		key, cert, err := createCertificate(true, serverCertificateCN, nil, hosts, certIPs, caCert, caKey, crtKey)
+		if err != nil {
+			return err
+		}
+		certPEM, keyPEM := pemEncode(cert, key)
+		crtSecret.Data["ca.crt"] = getCABundle(caSecret)
+		crtSecret.Data["tls.crt"] = getCertChain(certPEM.Bytes(), caSecret)
+		crtSecret.Data["tls.key"] = keyPEM.Bytes()
+	}
+
+	if crtSecret.Annotations == nil {
+		crtSecret.Annotations = map[string]string{}
+	}
+	if sans := formatAdditionalSANs(dnsSANs, ipSANs); sans != "" {
+		crtSecret.Annotations[config.AdditionalSANsAnnotation] = sans
+	} else {
+		delete(crtSecret.Annotations, config.AdditionalSANsAnnotation)
+	}
+	for key, value := range annotations {
+		if value == "" {
+			delete(crtSecret.Annotations, key)
+		} else {
+			crtSecret.Annotations[key] = value
+		}
+	}
+	err = c.Update(context.TODO(), crtSecret)
+	if err != nil {
+		log.Error(err, "Failed to update the server certificate secret", "name", serverCerts)
+		return err
+	}
+	return nil
+}
+
+func containsHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
+
+func sameHosts(a, b []string) bool {
+	setA, setB := map[string]bool{}, map[string]bool{}
+	for _, host := range a {
+		setA[host] = true
+	}
+	for _, host := range b {
+		setB[host] = true
+	}
+	if len(setA) != len(setB) {
+		return false
+	}
+	for host := range setA {
+		if !setB[host] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package certificates
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	serverIntermediateCACN = "observability-server-intermediate-ca-certificate"
+	clientIntermediateCACN = "observability-client-intermediate-ca-certificate"
+
+	// the secret which the intermediate CA is copied from, it is not set for the generated intermediate CA
+	intermediateCASourceAnnotation = "observability.open-cluster-management.io/intermediate-ca-source"
+)
+
+func isIntermediateCA(caSecret *corev1.Secret) bool {
+	return caSecret.Name == config.ServerIntermediateCACerts || caSecret.Name == config.ClientIntermediateCACerts
+}
+
+// getCABundle returns the CA certificates which verify the leaf certificates signed by the CA, which is
+// the full chain up to the root for the intermediate CA
+func getCABundle(caSecret *corev1.Secret) []byte {
+	if isIntermediateCA(caSecret) {
+		return caSecret.Data["ca.crt"]
+	}
+	return caSecret.Data["tls.crt"]
+}
+
+// getCertChain returns the leaf certificate followed by the intermediate CA which signs it, so that
+// the peers which only trust the root can verify it
+func getCertChain(cert []byte, caSecret *corev1.Secret) []byte {
+	if isIntermediateCA(caSecret) {
+		return append(append([]byte{}, cert...), caSecret.Data["tls.crt"]...)
+	}
+	return cert
+}
+
+// updateIntermediateCAs generates the intermediate CAs from the root CAs, or copies the intermediate CA
+// from the secret in the spec, and removes them once the intermediate CA is disabled so that the leaf
+// certificates are signed by the root CAs again
+func updateIntermediateCAs(c client.Client, scheme *runtime.Scheme, mco *mcov1beta2.MultiClusterObservability) error {
+	if mco.Spec.TLSConfig == nil || mco.Spec.TLSConfig.IntermediateCA == nil ||
+		!mco.Spec.TLSConfig.IntermediateCA.Enabled {
+		for _, name := range []string{config.ServerIntermediateCACerts, config.ClientIntermediateCACerts} {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: config.GetDefaultNamespace()},
+			}
+			err := c.Delete(context.TODO(), secret)
+			if err == nil {
+				log.Info("Intermediate CA removed", "name", name)
+			} else if !errors.IsNotFound(err) {
+				log.Error(err, "Failed to delete the intermediate CA", "name", name)
+				return err
+			}
+		}
+		return nil
+	}
+
+	var err error
+	if secretName := mco.Spec.TLSConfig.IntermediateCA.SecretName; secretName != "" {
+		err = copyIntermediateCA(c, scheme, mco, secretName)
+	} else {
+		err = createIntermediateCA(c, scheme, mco, serverCACerts, config.ServerIntermediateCACerts, serverIntermediateCACN)
+	}
+	if err != nil {
+		return err
+	}
+	return createIntermediateCA(c, scheme, mco, clientCACerts, config.ClientIntermediateCACerts, clientIntermediateCACN)
+}
+
+// createIntermediateCA generates the intermediate CA signed by the root CA, and renews it with the same
+// private key in the last fifth of its validity
+func createIntermediateCA(c client.Client, scheme *runtime.Scheme, mco *mcov1beta2.MultiClusterObservability,
+	rootName string, name string, cn string) error {
+	found := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: config.GetDefaultNamespace(), Name: name}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to check the intermediate CA", "name", name)
+		return err
+	}
+	exists := err == nil
+	var key *rsa.PrivateKey
+	if exists && found.Annotations[intermediateCASourceAnnotation] == "" {
+		cert, caKey, err := parseCASecret(found)
+		if err == nil {
+			if !isCertExpiring(cert) {
+				return nil
+			}
+			log.Info("To renew the intermediate CA", "name", name)
+			key = caKey
+		}
+	}
+
+	root := &corev1.Secret{}
+	err = c.Get(context.TODO(), types.NamespacedName{Namespace: config.GetDefaultNamespace(), Name: rootName}, root)
+	if err != nil {
+		log.Error(err, "Failed to get ca secret", "name", rootName)
+		return err
+	}
+	rootCert, rootKey, err := parseCASecret(root)
+	if err != nil {
+		return err
+	}
+	keyBytes, certBytes, err := createIntermediateCACertificate(cn, rootCert, rootKey, key)
+	if err != nil {
+		return err
+	}
+	certPEM, keyPEM := pemEncode(certBytes, keyBytes)
+	data := map[string][]byte{
+		"ca.crt":  append(append([]byte{}, certPEM.Bytes()...), root.Data["tls.crt"]...),
+		"tls.crt": certPEM.Bytes(),
+		"tls.key": keyPEM.Bytes(),
+	}
+	return saveIntermediateCA(c, scheme, mco, found, exists, name, data, "")
+}
+
+// copyIntermediateCA copies the intermediate CA from the secret in the spec. The invalid intermediate CA
+// is not copied, and the current intermediate CA keeps signing the server certificates.
+func copyIntermediateCA(c client.Client, scheme *runtime.Scheme, mco *mcov1beta2.MultiClusterObservability,
+	secretName string) error {
+	source := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: config.GetDefaultNamespace(), Name: secretName}, source)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("The secret of the intermediate CA is not found", "name", secretName)
+			return nil
+		}
+		log.Error(err, "Failed to get the secret of the intermediate CA", "name", secretName)
+		return err
+	}
+	cert, _, err := parseCASecret(source)
+	if err != nil {
+		return nil
+	}
+	if !cert.IsCA {
+		log.Info("The certificate is not a CA, skip the intermediate CA", "name", secretName)
+		return nil
+	}
+
+	found := &corev1.Secret{}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Namespace: config.GetDefaultNamespace(),
+		Name:      config.ServerIntermediateCACerts,
+	}, found)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to check the intermediate CA", "name", config.ServerIntermediateCACerts)
+		return err
+	}
+	data := map[string][]byte{
+		"ca.crt":  append(append([]byte{}, source.Data["tls.crt"]...), source.Data["ca.crt"]...),
+		"tls.crt": source.Data["tls.crt"],
+		"tls.key": source.Data["tls.key"],
+	}
+	return saveIntermediateCA(c, scheme, mco, found, err == nil, config.ServerIntermediateCACerts, data, secretName)
+}
+
+func saveIntermediateCA(c client.Client, scheme *runtime.Scheme, mco *mcov1beta2.MultiClusterObservability,
+	found *corev1.Secret, exists bool, name string, data map[string][]byte, source string) error {
+	if !exists {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: config.GetDefaultNamespace(),
+			},
+			Data: data,
+		}
+		if source != "" {
+			secret.Annotations = map[string]string{intermediateCASourceAnnotation: source}
+		}
+		if err := controllerutil.SetControllerReference(mco, secret, scheme); err != nil {
+			return err
+		}
+		if err := c.Create(context.TODO(), secret); err != nil {
+			log.Error(err, "Failed to create secret", "name", name)
+			return err
+		}
+		log.Info("Intermediate CA created", "name", name, "source", source)
+		return nil
+	}
+	if reflect.DeepEqual(found.Data, data) && found.Annotations[intermediateCASourceAnnotation] == source {
+		return nil
+	}
+	found.Data = data
+	if found.Annotations == nil {
+		found.Annotations = map[string]string{}
+	}
+	if source != "" {
+		found.Annotations[intermediateCASourceAnnotation] = source
+	} else {
+		delete(found.Annotations, intermediateCASourceAnnotation)
+	}
+	if err := c.Update(context.TODO(), found); err != nil {
+		log.Error(err, "Failed to update secret", "name", name)
+		return err
+	}
+	log.Info("Intermediate CA updated", "name", name, "source", source)
+	return nil
+}
+
+func createIntermediateCACertificate(cn string, rootCert *x509.Certificate, rootKey *rsa.PrivateKey,
+	key *rsa.PrivateKey) ([]byte, []byte, error) {
+	sn, err := rand.Int(rand.Reader, serialNumberLimit)
+	if err != nil {
+		log.Error(err, "failed to generate serial number")
+		return nil, nil, err
+	}
+	notAfter := time.Now().AddDate(2, 0, 0)
+	if notAfter.After(rootCert.NotAfter) {
+		notAfter = rootCert.NotAfter
+	}
+	ca := &x509.Certificate{
+		SerialNumber: sn,
+		Subject: pkix.Name{
+			Organization: []string{"Red Hat, Inc."},
+			Country:      []string{"US"},
+			CommonName:   cn,
+		},
+		NotBefore:             time.Now(),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	if key == nil {
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			log.Error(err, "Failed to generate private key", "cn", cn)
+			return nil, nil, err
+		}
+	}
+
+	caBytes, err := x509.CreateCertificate(rand.Reader, ca, rootCert, &key.PublicKey, rootKey)
+	if err != nil {
+		log.Error(err, "Failed to create certificate", "cn", cn)
+		return nil, nil, err
+	}
+	return x509.MarshalPKCS1PrivateKey(key), caBytes, nil
+}
+
+// isCertExpiring returns true in the last fifth of the validity of the certificate
+func isCertExpiring(cert *x509.Certificate) bool {
+	maxWait := cert.NotAfter.Sub(cert.NotBefore) / 5
+	return time.Now().After(cert.NotAfter.Add(-maxWait))
+}
@@ -33,7 +33,7 @@ func sign(csr *certificatesv1.CertificateSigningRequest) []byte {
 		log.Error(err, err.Error())
 		return nil
 	}
-	_, caCert, caKey, err := getCA(c, false)
+	caSecret, caCert, caKey, err := getCA(c, false)
 	if err != nil {
 		return nil
 	}
@@ -73,5 +73,10 @@ func sign(csr *certificatesv1.CertificateSigningRequest) []byte {
 		log.Error(err, "Failed to sign the CSR")
 		return nil
 	}
-	return signedCert
+	if addonClient, err := getAddonClient(); err != nil {
+		log.Error(err, "Failed to create the client to record the client certificate")
+	} else {
+		recordClientCert(addonClient, csr, signedCert)
+	}
+	return getCertChain(signedCert, caSecret)
 }
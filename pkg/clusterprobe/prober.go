@@ -0,0 +1,187 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package clusterprobe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	// the interval in which the prober checks whether the next round of the probes is due
+	pollInterval = 5 * time.Second
+	// the number of the managed clusters which are probed at the same time
+	maxConcurrentProbes = 20
+	// the endpoint of the API server which is readable without the credentials
+	readyzPath = "/readyz"
+)
+
+var (
+	log = logf.Log.WithName("clusterprobe")
+
+	// the cluster label is overridden with local-cluster when the metrics are forwarded from the hub
+	probeSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "acm_observability_cluster_api_probe_success",
+		Help: "Whether the API server of the managed cluster is reachable and ready from the hub, 1 if it is.",
+	}, []string{"managed_cluster"})
+	probeDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "acm_observability_cluster_api_probe_duration_seconds",
+		Help: "The latency of the last probe of the API server of the managed cluster from the hub.",
+	}, []string{"managed_cluster"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(probeSuccess, probeDuration)
+}
+
+// probeResult is the result of the probe of a managed cluster
+type probeResult struct {
+	cluster  string
+	success  bool
+	duration time.Duration
+}
+
+// Prober probes the API servers of the managed clusters from the hub periodically if the probes
+// are enabled in the MultiClusterObservability, it is added to the manager as a runnable
+type Prober struct {
+	Client     client.Client
+	lastProbed time.Time
+}
+
+// Start probes the managed clusters in their interval until the context is done
+func (p *Prober) Start(ctx context.Context) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.probeIfDue(ctx, time.Now()); err != nil {
+				log.Error(err, "Failed to probe the managed clusters")
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// probeIfDue probes every managed cluster once the interval passes since the last round
+func (p *Prober) probeIfDue(ctx context.Context, now time.Time) error {
+	mcoList := &mcov1beta2.MultiClusterObservabilityList{}
+	if err := p.Client.List(ctx, mcoList); err != nil {
+		return err
+	}
+	if len(mcoList.Items) == 0 || !config.IsClusterAPIProbeEnabled(&mcoList.Items[0]) {
+		probeSuccess.Reset()
+		probeDuration.Reset()
+		p.lastProbed = time.Time{}
+		return nil
+	}
+	mco := &mcoList.Items[0]
+	if err := config.ValidateClusterAPIProbe(mco); err != nil {
+		return err
+	}
+	if !p.lastProbed.IsZero() && now.Sub(p.lastProbed) < config.GetClusterAPIProbeInterval(mco) {
+		return nil
+	}
+	p.lastProbed = now
+
+	clusters := &clusterv1.ManagedClusterList{}
+	if err := p.Client.List(ctx, clusters); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	results := probeClusters(ctx, clusters.Items, config.GetClusterAPIProbeTimeout(mco))
+	// the metrics of the deleted clusters are dropped
+	probeSuccess.Reset()
+	probeDuration.Reset()
+	for _, result := range results {
+		success := 0.0
+		if result.success {
+			success = 1
+		}
+		probeSuccess.WithLabelValues(result.cluster).Set(success)
+		probeDuration.WithLabelValues(result.cluster).Set(result.duration.Seconds())
+	}
+	return nil
+}
+
+// probeClusters probes the managed clusters with the API server URL at most maxConcurrentProbes at a time
+func probeClusters(ctx context.Context, clusters []clusterv1.ManagedCluster, timeout time.Duration) []probeResult {
+	results := make(chan probeResult, len(clusters))
+	sem := make(chan struct{}, maxConcurrentProbes)
+	wg := sync.WaitGroup{}
+	for idx := range clusters {
+		cluster := &clusters[idx]
+		if len(cluster.Spec.ManagedClusterClientConfigs) == 0 || cluster.Spec.ManagedClusterClientConfigs[0].URL == "" {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results <- probeCluster(ctx, cluster, timeout)
+		}()
+	}
+	wg.Wait()
+	close(results)
+	list := []probeResult{}
+	for result := range results {
+		list = append(list, result)
+	}
+	return list
+}
+
+// probeCluster requests the readyz endpoint of the API server of the managed cluster, which the
+// apiserver serves without the credentials. The CA bundle of the client config is trusted when it
+// is set, otherwise the system roots are.
+func probeCluster(ctx context.Context, cluster *clusterv1.ManagedCluster, timeout time.Duration) probeResult {
+	clientConfig := cluster.Spec.ManagedClusterClientConfigs[0]
+	result := probeResult{cluster: cluster.Name}
+	tlsConfig := &tls.Config{}
+	if len(clientConfig.CABundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(clientConfig.CABundle) {
+			log.Info("Failed to parse the CA bundle of the managed cluster", "cluster", cluster.Name)
+			return result
+		}
+		tlsConfig.RootCAs = pool
+	}
+	httpClient := &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimSuffix(clientConfig.URL, "/")+readyzPath, nil)
+	if err != nil {
+		return result
+	}
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	result.duration = time.Since(start)
+	if err != nil {
+		log.V(1).Info("Failed to probe the managed cluster", "cluster", cluster.Name, "error", err.Error())
+		return result
+	}
+	resp.Body.Close()
+	result.success = resp.StatusCode == http.StatusOK
+	return result
+}
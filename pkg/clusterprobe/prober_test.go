@@ -0,0 +1,89 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package clusterprobe
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+func TestProber(t *testing.T) {
+	s := scheme.Scheme
+	clusterv1.AddToScheme(s)
+	mcov1beta2.AddToScheme(s)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != readyzPath {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	newCluster := func(name string, configs []clusterv1.ClientConfig) *clusterv1.ManagedCluster {
+		return &clusterv1.ManagedCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       clusterv1.ManagedClusterSpec{ManagedClusterClientConfigs: configs},
+		}
+	}
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			ClusterAPIProbe: &mcov1beta2.ClusterAPIProbeSpec{Interval: "1m", Timeout: "2s"},
+		},
+	}
+	c := fake.NewFakeClient(mco,
+		newCluster("cluster1", []clusterv1.ClientConfig{{URL: server.URL, CABundle: caBundle}}),
+		// the certificate of the server is not trusted without the CA bundle
+		newCluster("cluster2", []clusterv1.ClientConfig{{URL: server.URL}}),
+		newCluster("cluster3", nil))
+
+	p := &Prober{Client: c}
+	now := time.Now()
+	if err := p.probeIfDue(context.TODO(), now); err != nil || testutil.CollectAndCount(probeSuccess) != 0 {
+		t.Fatalf("The clusters should not be probed before the probes are enabled (%v)", err)
+	}
+
+	mco.Spec.ClusterAPIProbe.Enabled = true
+	if err := c.Update(context.TODO(), mco); err != nil {
+		t.Fatalf("Failed to enable the probes: (%v)", err)
+	}
+	if err := p.probeIfDue(context.TODO(), now); err != nil {
+		t.Fatalf("Failed to probe the managed clusters: (%v)", err)
+	}
+	if count := testutil.CollectAndCount(probeSuccess); count != 2 {
+		t.Errorf("Only the clusters with the API server URL should be probed: %d", count)
+	}
+	if value := testutil.ToFloat64(probeSuccess.WithLabelValues("cluster1")); value != 1 {
+		t.Errorf("The cluster with the trusted API server should be reachable: %v", value)
+	}
+	if value := testutil.ToFloat64(probeSuccess.WithLabelValues("cluster2")); value != 0 {
+		t.Errorf("The cluster with the untrusted API server should not be reachable: %v", value)
+	}
+	if value := testutil.ToFloat64(probeDuration.WithLabelValues("cluster1")); value <= 0 {
+		t.Errorf("The latency of the probe should be recorded: %v", value)
+	}
+
+	server.Close()
+	if err := p.probeIfDue(context.TODO(), now.Add(30*time.Second)); err != nil ||
+		testutil.ToFloat64(probeSuccess.WithLabelValues("cluster1")) != 1 {
+		t.Errorf("The clusters should not be probed again in the interval (%v)", err)
+	}
+	if err := p.probeIfDue(context.TODO(), now.Add(time.Minute)); err != nil ||
+		testutil.ToFloat64(probeSuccess.WithLabelValues("cluster1")) != 0 {
+		t.Errorf("The unreachable cluster should be recorded after the interval (%v)", err)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	AutoscalingTypeHPA  = "HPA"
+	AutoscalingTypeKEDA = "KEDA"
+
+	ScaledObjectCrdName    = "scaledobjects.keda.sh"
+	ScaledObjectAPIVersion = "keda.sh/v1alpha1"
+	ScaledObjectKind       = "ScaledObject"
+
+	DefaultAutoscalingPrometheusURL = "https://thanos-querier.openshift-monitoring.svc:9091"
+	DefaultAutoscalingMinReplicas   = 2
+	DefaultAutoscalingMaxReplicas   = 10
+	// DefaultAutoscalingCPUTarget is the average CPU utilization percentage of the pods with HPA
+	DefaultAutoscalingCPUTarget = 70
+	// DefaultReceiveIngestionTarget is the samples ingested per second by a replica of thanos receive
+	DefaultReceiveIngestionTarget = 100000
+	// DefaultQueryConcurrencyTarget is the concurrent queries of a replica of thanos query and query frontend
+	DefaultQueryConcurrencyTarget = 10
+)
+
+// AutoscaledComponents are the thanos components which can be scaled automatically
+var AutoscaledComponents = []string{ThanosReceive, ThanosQuery, ThanosQueryFrontend}
+
+// IsKEDAAutoscaling returns true if the components are scaled by KEDA instead of HPA
+func IsKEDAAutoscaling(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.Autoscaling != nil && mco.Spec.Autoscaling.Type == AutoscalingTypeKEDA
+}
+
+// GetComponentAutoscaling returns the spec of the automatic scaling of the component, it is nil
+// if the component is not scaled automatically
+func GetComponentAutoscaling(mco *mcov1beta2.MultiClusterObservability,
+	component string) *mcov1beta2.ComponentAutoscalingSpec {
+	if mco.Spec.Autoscaling == nil {
+		return nil
+	}
+	switch component {
+	case ThanosReceive:
+		return mco.Spec.Autoscaling.Receive
+	case ThanosQuery:
+		return mco.Spec.Autoscaling.Query
+	case ThanosQueryFrontend:
+		return mco.Spec.Autoscaling.QueryFrontend
+	}
+	return nil
+}
+
+// GetAutoscalingBounds returns the minimum and the maximum replicas of the component. The minimum
+// of thanos receive is raised to the replication factor of the highly available topology, and the
+// maximum is raised to the minimum.
+func GetAutoscalingBounds(mco *mcov1beta2.MultiClusterObservability, component string) (int32, int32) {
+	spec := GetComponentAutoscaling(mco, component)
+	minReplicas, maxReplicas := int32(DefaultAutoscalingMinReplicas), int32(DefaultAutoscalingMaxReplicas)
+	if spec.MinReplicas != 0 {
+		minReplicas = spec.MinReplicas
+	}
+	if spec.MaxReplicas != 0 {
+		maxReplicas = spec.MaxReplicas
+	}
+	if component == ThanosReceive && IsHighAvailabilityEnabled(mco) &&
+		minReplicas < GetHAReplicationFactor(mco) {
+		minReplicas = GetHAReplicationFactor(mco)
+	}
+	if maxReplicas < minReplicas {
+		maxReplicas = minReplicas
+	}
+	return minReplicas, maxReplicas
+}
+
+// GetAutoscalingTarget returns the target of the metric per replica of the component
+func GetAutoscalingTarget(mco *mcov1beta2.MultiClusterObservability, component string) int32 {
+	if spec := GetComponentAutoscaling(mco, component); spec.Target != 0 {
+		return spec.Target
+	}
+	if !IsKEDAAutoscaling(mco) {
+		return DefaultAutoscalingCPUTarget
+	}
+	if component == ThanosReceive {
+		return DefaultReceiveIngestionTarget
+	}
+	return DefaultQueryConcurrencyTarget
+}
+
+// GetAutoscalingPrometheusURL returns the address of the Prometheus server which KEDA queries
+func GetAutoscalingPrometheusURL(mco *mcov1beta2.MultiClusterObservability) string {
+	if mco.Spec.Autoscaling.PrometheusURL != "" {
+		return mco.Spec.Autoscaling.PrometheusURL
+	}
+	return DefaultAutoscalingPrometheusURL
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	// DefaultBackfillLookback is the default history of the local Prometheus which is backfilled
+	DefaultBackfillLookback = 6 * time.Hour
+	// DefaultBackfillPrometheusURL is the default address of the local Prometheus on the managed clusters
+	DefaultBackfillPrometheusURL = "https://thanos-querier.openshift-monitoring.svc:9091"
+)
+
+// IsBackfillEnabled returns true if the history of the freshly onboarded managed clusters is backfilled
+func IsBackfillEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.Backfill != nil && mco.Spec.Backfill.Enabled
+}
+
+// GetBackfillLookback returns how much of the recent history of the local Prometheus is backfilled
+func GetBackfillLookback(mco *mcov1beta2.MultiClusterObservability) time.Duration {
+	if mco.Spec.Backfill == nil || mco.Spec.Backfill.Lookback == "" {
+		return DefaultBackfillLookback
+	}
+	lookback, err := time.ParseDuration(mco.Spec.Backfill.Lookback)
+	if err != nil || lookback <= 0 {
+		return DefaultBackfillLookback
+	}
+	return lookback
+}
+
+// GetBackfillPrometheusURL returns the address of the local Prometheus which the history is read from
+func GetBackfillPrometheusURL(mco *mcov1beta2.MultiClusterObservability) string {
+	if mco.Spec.Backfill != nil && mco.Spec.Backfill.PrometheusURL != "" {
+		return mco.Spec.Backfill.PrometheusURL
+	}
+	return DefaultBackfillPrometheusURL
+}
+
+// GetBackfillUploadStorage returns the configuration of the object storage which the backfilled
+// blocks are uploaded to
+func GetBackfillUploadStorage(c client.Client, mco *mcov1beta2.MultiClusterObservability) ([]byte, error) {
+	storage := mco.Spec.Backfill.UploadStorage
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      storage.Name,
+		Namespace: GetDefaultNamespace(),
+	}, secret)
+	if err != nil {
+		return nil, err
+	}
+	data, found := secret.Data[storage.Key]
+	if !found {
+		return nil, fmt.Errorf("the key %s is not found in the secret %s of the upload storage of the backfill",
+			storage.Key, storage.Name)
+	}
+	return data, nil
+}
+
+// ValidateBackfill returns an error if the backfill is enabled without the upload storage or with
+// an invalid lookback
+func ValidateBackfill(mco *mcov1beta2.MultiClusterObservability) error {
+	if !IsBackfillEnabled(mco) {
+		return nil
+	}
+	storage := mco.Spec.Backfill.UploadStorage
+	if storage == nil || storage.Name == "" || storage.Key == "" {
+		return fmt.Errorf("the uploadStorage of the backfill is required")
+	}
+	if lookback := mco.Spec.Backfill.Lookback; lookback != "" {
+		if d, err := time.ParseDuration(lookback); err != nil || d <= 0 {
+			return fmt.Errorf("the lookback of the backfill %q is invalid", lookback)
+		}
+	}
+	return nil
+}
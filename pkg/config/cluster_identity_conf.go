@@ -0,0 +1,46 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"fmt"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	// ClusterIDSourceClusterClaim derives the clusterID label from a ClusterClaim of the managed cluster
+	ClusterIDSourceClusterClaim = "ClusterClaim"
+	// ClusterIDSourceClusterName derives the clusterID label from the name of the ManagedCluster
+	ClusterIDSourceClusterName = "ClusterName"
+	// ClusterIDSourceClusterLabel derives the clusterID label from a label of the ManagedCluster
+	ClusterIDSourceClusterLabel = "ClusterLabel"
+)
+
+// GetClusterIDSource returns how the clusterID label of the managed clusters is derived
+func GetClusterIDSource(mco *mcov1beta2.MultiClusterObservability) string {
+	if mco.Spec.ClusterIdentity == nil || mco.Spec.ClusterIdentity.Source == "" {
+		return ClusterIDSourceClusterClaim
+	}
+	return mco.Spec.ClusterIdentity.Source
+}
+
+// ValidateClusterIdentity returns an error if the clusterID label is derived from a label of the
+// ManagedCluster without its key
+func ValidateClusterIdentity(mco *mcov1beta2.MultiClusterObservability) error {
+	if mco.Spec.ClusterIdentity == nil {
+		return nil
+	}
+	switch GetClusterIDSource(mco) {
+	case ClusterIDSourceClusterClaim, ClusterIDSourceClusterName:
+	case ClusterIDSourceClusterLabel:
+		if mco.Spec.ClusterIdentity.LabelKey == "" {
+			return fmt.Errorf("the labelKey of the clusterIdentity is required with the %s source",
+				ClusterIDSourceClusterLabel)
+		}
+	default:
+		return fmt.Errorf("the source of the clusterIdentity %q is invalid", mco.Spec.ClusterIdentity.Source)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	// DefaultClusterAPIProbeInterval is the default interval of the probes of the managed clusters
+	DefaultClusterAPIProbeInterval = time.Minute
+	// DefaultClusterAPIProbeTimeout is the default timeout of each probe
+	DefaultClusterAPIProbeTimeout = 5 * time.Second
+	// the managed clusters are not probed more often than the minimum interval
+	minClusterAPIProbeInterval = 10 * time.Second
+)
+
+// IsClusterAPIProbeEnabled returns true if the API servers of the managed clusters are probed from the hub
+func IsClusterAPIProbeEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.ClusterAPIProbe != nil && mco.Spec.ClusterAPIProbe.Enabled
+}
+
+// GetClusterAPIProbeInterval returns the interval of the probes of the managed clusters
+func GetClusterAPIProbeInterval(mco *mcov1beta2.MultiClusterObservability) time.Duration {
+	if mco.Spec.ClusterAPIProbe == nil || mco.Spec.ClusterAPIProbe.Interval == "" {
+		return DefaultClusterAPIProbeInterval
+	}
+	interval, err := time.ParseDuration(mco.Spec.ClusterAPIProbe.Interval)
+	if err != nil {
+		return DefaultClusterAPIProbeInterval
+	}
+	if interval < minClusterAPIProbeInterval {
+		return minClusterAPIProbeInterval
+	}
+	return interval
+}
+
+// GetClusterAPIProbeTimeout returns the timeout of each probe, which is at most the interval
+func GetClusterAPIProbeTimeout(mco *mcov1beta2.MultiClusterObservability) time.Duration {
+	timeout := DefaultClusterAPIProbeTimeout
+	if mco.Spec.ClusterAPIProbe != nil && mco.Spec.ClusterAPIProbe.Timeout != "" {
+		if t, err := time.ParseDuration(mco.Spec.ClusterAPIProbe.Timeout); err == nil && t > 0 {
+			timeout = t
+		}
+	}
+	if interval := GetClusterAPIProbeInterval(mco); timeout > interval {
+		return interval
+	}
+	return timeout
+}
+
+// ValidateClusterAPIProbe returns an error if the probes are enabled with an invalid interval or timeout
+func ValidateClusterAPIProbe(mco *mcov1beta2.MultiClusterObservability) error {
+	if !IsClusterAPIProbeEnabled(mco) {
+		return nil
+	}
+	if interval := mco.Spec.ClusterAPIProbe.Interval; interval != "" {
+		if _, err := time.ParseDuration(interval); err != nil {
+			return fmt.Errorf("the interval of the clusterAPIProbe %q is invalid: %v", interval, err)
+		}
+	}
+	if timeout := mco.Spec.ClusterAPIProbe.Timeout; timeout != "" {
+		if t, err := time.ParseDuration(timeout); err != nil || t <= 0 {
+			return fmt.Errorf("the timeout of the clusterAPIProbe %q is invalid", timeout)
+		}
+	}
+	return nil
+}
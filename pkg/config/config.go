@@ -5,6 +5,7 @@ package config
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strings"
 
@@ -18,6 +19,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
 )
 
 const (
@@ -25,9 +28,26 @@ const (
 	obsAPIGateway            = "observatorium-api"
 	infrastructureConfigName = "cluster"
 	defaultNamespace         = "open-cluster-management-observability"
+	defaultMCONamespace      = "open-cluster-management"
 	defaultTenantName        = "default"
 	placementRuleName        = "observability"
 
+	defaultCardinalitySeriesThreshold = 10000
+	// DefaultCardinalityThrottleDuration is the default duration which a high-cardinality metric is throttled for
+	DefaultCardinalityThrottleDuration = "1h"
+	// DefaultGrafanaStorageSize is the default size of the persistent volume claim of grafana
+	DefaultGrafanaStorageSize = "1Gi"
+	// DefaultAlertmanagerStorageSize is the default size of the persistent volume claims of alertmanager
+	DefaultAlertmanagerStorageSize = "1Gi"
+	// GrafanaStorageName is the name of the volume and the persistent volume claim of the data of grafana
+	GrafanaStorageName = "grafana-storage"
+	// GrafanaDashboardSyncName is the name of the sidecar, the volume and the provider configmap
+	// of the dashboards which are synced from a git repository
+	GrafanaDashboardSyncName = "grafana-dashboard-sync"
+	// ExternalMetricsStoreSecretName is the name of the secret on the managed clusters which contains
+	// the credentials of the external metrics store
+	ExternalMetricsStoreSecretName = "observability-external-metrics-store"
+
 	AnnotationKeyImageRepository          = "mco-imageRepository"
 	AnnotationKeyImageTagSuffix           = "mco-imageTagSuffix"
 	AnnotationMCOPause                    = "mco-pause"
@@ -41,6 +61,18 @@ const (
 	ImageManifestConfigMapName = "mch-image-manifest-"
 
 	ComponentVersion = "COMPONENT_VERSION"
+	// the version of the bundle of the defaults when the version of the operator is unknown
+	defaultComponentVersion = "latest"
+
+	// DefaultsBundleNamePrefix is the prefix of the configmaps of the bundles of the default dashboards,
+	// alert rules and metrics allowlist, the bundles are named with the version of the operator
+	DefaultsBundleNamePrefix = "observability-defaults-"
+	// DefaultsBundleLabelKey is the label of the configmaps of the bundles of the defaults
+	DefaultsBundleLabelKey = "observability.open-cluster-management.io/defaults-bundle"
+	// DefaultsBundleVersionAnnotation is the annotation of the version of the operator which exports the bundle
+	DefaultsBundleVersionAnnotation = "observability.open-cluster-management.io/defaults-version"
+	// VersionLabelKey is the label of the resources with the version of the operator which renders them
+	VersionLabelKey = "observability.open-cluster-management.io/version"
 
 	ServerCACerts    = "observability-server-ca-certs"
 	ClientCACerts    = "observability-client-ca-certs"
@@ -50,15 +82,165 @@ const (
 	GrafanaCN        = "grafana"
 	ManagedClusterOU = "acm"
 
-	AlertRuleDefaultConfigMapName = "thanos-ruler-default-rules"
-	AlertRuleDefaultFileKey       = "default_rules.yaml"
-	AlertRuleCustomConfigMapName  = "thanos-ruler-custom-rules"
-	AlertRuleCustomFileKey        = "custom_rules.yaml"
-	AlertmanagerURL               = "http://alertmanager:9093"
-	AlertmanagerConfigName        = "alertmanager-config"
-
-	AllowlistConfigMapName       = "observability-metrics-allowlist"
-	AllowlistCustomConfigMapName = "observability-metrics-custom-allowlist"
+	// the intermediate CAs which sign the leaf certificates instead of the root CAs if they exist
+	ServerIntermediateCACerts = "observability-server-intermediate-ca-certs"
+	ClientIntermediateCACerts = "observability-client-intermediate-ca-certs"
+
+	OTLPReceiverCerts   = "observability-otlp-receiver-certs"
+	OTLPReceiverCertCN  = "observability-otlp-receiver-certificate"
+	LokiCerts           = "observability-loki-certs"
+	LokiCertCN          = "observability-loki-certificate"
+	QueryEndpointCerts  = "observability-query-endpoint-certs"
+	QueryEndpointCertCN = "observability-query-endpoint-certificate"
+
+	AlertRuleDefaultConfigMapName     = "thanos-ruler-default-rules"
+	AlertRuleDefaultFileKey           = "default_rules.yaml"
+	AlertRuleCustomConfigMapName      = "thanos-ruler-custom-rules"
+	AlertRuleCustomFileKey            = "custom_rules.yaml"
+	AlertRuleSLOConfigMapName         = "thanos-ruler-slo-rules"
+	AlertRuleSLOFileKey               = "slo_rules.yaml"
+	AlertRuleCostConfigMapName        = "thanos-ruler-cost-rules"
+	AlertRuleCostFileKey              = "cost_rules.yaml"
+	AlertRuleCardinalityConfigMapName = "thanos-ruler-cardinality-rules"
+	AlertRuleCardinalityFileKey       = "cardinality_rules.yaml"
+	CardinalityThrottleConfigMapName  = "observability-cardinality-throttle"
+	CardinalityThrottleFileKey        = "throttled.yaml"
+	// the recorded number of the series per cluster and metric
+	CardinalitySeriesRecord = "cluster_metric:series:count"
+	AlertmanagerURL         = "http://alertmanager:9093"
+	AlertmanagerConfigName  = "alertmanager-config"
+
+	AlertRuleWatchdogConfigMapName = "thanos-ruler-watchdog-rules"
+	AlertRuleWatchdogFileKey       = "watchdog_rules.yaml"
+	// the watchdog alert which always fires on the managed clusters, and the alert on the hub
+	// which fires for each cluster whose watchdog alert is forwarded to the hub
+	WatchdogAlertName        = "ObservabilityWatchdog"
+	ClusterWatchdogAlertName = "ObservabilityClusterWatchdog"
+	WatchdogRuleName         = "observability-watchdog"
+	AlertingSelfTestPath     = "/alerting-self-test"
+	// the secret with the bearer token which the alertmanager sends to the receiver of the watchdog alerts
+	AlertingSelfTestTokenSecretName = "observability-alerting-self-test-token"
+	AlertingSelfTestTokenKey        = "token"
+	// the path of the REST API of the observability for the console, which is served by the
+	// webhook server of the operator
+	ConsoleAPIPath = "/api/v1/observability"
+
+	AllowlistConfigMapName        = "observability-metrics-allowlist"
+	AllowlistCustomConfigMapName  = "observability-metrics-custom-allowlist"
+	AllowlistPreviewConfigMapName = "observability-metrics-custom-allowlist-preview"
+	AllowlistReportConfigMapName  = "observability-metrics-allowlist-report"
+
+	MetricsUsageReportConfigMapName = "observability-metrics-usage-report"
+	MetricsUsageReportFileKey       = "report.yaml"
+
+	KubeStateMetricsCustomResourceConfigMapName = "observability-kube-state-metrics-custom-resource-state"
+	KubeStateMetricsCustomResourceFileKey       = "custom-resource-state.yaml"
+
+	TenantLabelName    = "tenant"
+	ClusterSetLabelKey = "cluster.open-cluster-management.io/clusterset"
+
+	SpokeRulesLabelKey              = "observability.open-cluster-management.io/spoke-rules"
+	SpokeRulesClusterSetsAnnotation = "observability.open-cluster-management.io/cluster-sets"
+
+	// DashboardLabelKey and CustomDashboardLabelKey label the configmaps of the built-in and the custom
+	// grafana dashboards, which are kept out of the informer cache
+	DashboardLabelKey       = "general-folder"
+	CustomDashboardLabelKey = "grafana-custom-dashboard"
+
+	// EndpointOverlayLabelKey labels the configmaps of the patches of the endpoint manifests, the value
+	// is the overlay of the platform which the patches apply to, or "all" for all the platforms
+	EndpointOverlayLabelKey = "observability.open-cluster-management.io/endpoint-overlay"
+
+	// SpokePatchesConfigMapName is the configmap of the patches of the manifests which are pushed
+	// to the managed clusters, every key is a list of the patches keyed by the kind and the name
+	SpokePatchesConfigMapName = "observability-spoke-patches"
+	// SpokePatchesHashAnnotation records the hash of the spoke patches in the manifestwork to track
+	// the change of the patches
+	SpokePatchesHashAnnotation = "observability.open-cluster-management.io/spoke-patches-hash"
+	// HubPatchesConfigMapName is the configmap of the patches of the Deployments and the StatefulSets
+	// of the hub components, every key is a list of the patches keyed by the kind and the name
+	HubPatchesConfigMapName = "observability-hub-patches"
+
+	// AddonsInstalledConditionType is the condition of the MultiClusterObservability with the install
+	// progress of the addon across the fleet, which is updated by the addon status controller
+	AddonsInstalledConditionType = "AddonsInstalled"
+	// HubPatchesHashAnnotation records the hash of the hub patches which apply to the resource to
+	// track the change of the patches
+	HubPatchesHashAnnotation = "observability.open-cluster-management.io/hub-patches-hash"
+	// DesiredHashAnnotation records the hash of the desired state of the resource which the operator
+	// deploys, to tell the external modifications of the resource from the changes of the desired state
+	DesiredHashAnnotation = "observability.open-cluster-management.io/desired-hash"
+
+	// ClusterIDLabelName is the label of the id of the managed cluster which is retained across the
+	// renames and the reimports of the cluster
+	ClusterIDLabelName = "clusterID"
+
+	// ScrapeCredentialsLabelKey is the label of the secrets with the client certificates which the
+	// collectors on the managed clusters use to scrape etcd or kubelet, its value is the target
+	ScrapeCredentialsLabelKey = "observability.open-cluster-management.io/scrape-credentials"
+	// ScrapeCredentialsPlatformsAnnotation lists the platforms of the managed clusters which the
+	// scrape credentials are pushed to, they are pushed to all the managed clusters if it is not set
+	ScrapeCredentialsPlatformsAnnotation = "observability.open-cluster-management.io/platforms"
+	ClusterPlatformLabelKey              = "cloud"
+	ScrapeTargetEtcd                     = "etcd"
+	ScrapeTargetKubelet                  = "kubelet"
+	ScrapeConfigConfigMapName            = "observability-scrape-config"
+	ScrapeConfigFileKey                  = "scrape-config.yaml"
+	ScrapeCredentialsMountPath           = "/etc/scrape-certs"
+
+	// WindowsMetricsLabelKey is the label of the managed clusters with the windows worker nodes whose
+	// metrics are collected from windows_exporter
+	WindowsMetricsLabelKey = "observability.open-cluster-management.io/windows-metrics"
+	WindowsExporterPort    = "9182"
+
+	CollectionModePush      = "push"
+	CollectionModePull      = "pull"
+	FederateURLAnnotation   = "observability.open-cluster-management.io/federate-url"
+	FederateTokenSecretName = "observability-federate-token"
+	FederateTokenKey        = "token"
+
+	GatewayURLAnnotation = "observability.open-cluster-management.io/gateway-url"
+	// AddonOptedOutAnnotation is the annotation of the ManagedCluster with the time when its
+	// ObservabilityAddon is deleted by hand, the observability is not enabled on the cluster again
+	// until the annotation is removed
+	AddonOptedOutAnnotation = "observability.open-cluster-management.io/opted-out"
+	// ProxyEndpointAnnotation is the host[:port] of the cluster-proxy or the secure gateway through which
+	// the managed cluster reaches the observatorium api of the hub, the cluster writes to the route of
+	// the hub directly without it
+	ProxyEndpointAnnotation = "observability.open-cluster-management.io/proxy-endpoint"
+
+	// ObsAPIEndpointAnnotation is the host of the observatorium api route which the server certificate
+	// is issued for, and which the manifestworks of the managed clusters are generated with
+	ObsAPIEndpointAnnotation = "observability.open-cluster-management.io/endpoint"
+	// ObsAPIPreviousEndpointsAnnotation are the hosts of the route before it changed, they are still served
+	// until every managed cluster moves to the new endpoint
+	ObsAPIPreviousEndpointsAnnotation = "observability.open-cluster-management.io/previous-endpoints"
+	ObsAPIEndpointChangedAnnotation   = "observability.open-cluster-management.io/endpoint-changed-at"
+	// ObsAPIProxyEndpointsAnnotation are the hosts of the cluster proxies which the server certificate is
+	// issued for, the managed clusters only write through a proxy once the certificate covers it
+	ObsAPIProxyEndpointsAnnotation = "observability.open-cluster-management.io/proxy-endpoints"
+	// AdditionalSANsAnnotation are the additional SANs which the server certificate is issued for,
+	// they are replaced in the certificate when the list in the spec changes
+	AdditionalSANsAnnotation = "observability.open-cluster-management.io/additional-sans"
+
+	TracingBackendTempo  = "tempo"
+	TracingBackendJaeger = "jaeger"
+	TracingCAMountPath   = "/etc/otlp/tracing-ca"
+	TracingCAKey         = "ca.crt"
+	ServiceCAFile        = "/var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt"
+
+	EventsFilterConfigMapName = "observability-events-filter"
+	EventsFilterFileKey       = "filter.yaml"
+
+	ExternalMetricsStoreThanos        = "Thanos"
+	ExternalMetricsStoreObservatorium = "Observatorium"
+	ExternalMetricsStoreCortex        = "Cortex"
+	ExternalMetricsStoreMimir         = "Mimir"
+	// ScopeOrgIDHeader is the header of the tenant ID of Cortex and Mimir
+	ScopeOrgIDHeader = "X-Scope-OrgID"
+	// DefaultExternalMetricsStoreTenantID is the tenant ID of Cortex and Mimir for the clusters
+	// which do not belong to a tenant
+	DefaultExternalMetricsStoreTenantID = "anonymous"
 )
 
 const (
@@ -108,6 +290,34 @@ const (
 	LeaseControllerKey            = "klusterlet_addon_lease_controller"
 
 	RbacQueryProxyKey = "rbac_query_proxy"
+
+	OTelCollectorImgRepo = "docker.io/otel"
+	OTelCollectorImgName = "opentelemetry-collector-contrib"
+	OTelCollectorImgTag  = "0.29.0"
+	OTelCollectorKey     = "opentelemetry_collector_contrib"
+
+	LokiImgRepo     = "docker.io/grafana"
+	LokiImgName     = "loki"
+	LokiImgTag      = "2.2.1"
+	LokiKey         = "loki"
+	PromtailImgName = "promtail"
+	PromtailKey     = "promtail"
+
+	EventExporterImgRepo = "ghcr.io/opsgenie"
+	EventExporterImgName = "kubernetes-event-exporter"
+	EventExporterImgTag  = "v0.10"
+	EventExporterKey     = "kubernetes_event_exporter"
+
+	// promtool creates the blocks of the backfill from the recording rules since v2.27.0
+	PrometheusImgRepo = "quay.io/prometheus"
+	PrometheusImgName = "prometheus"
+	PrometheusImgTag  = "v2.32.1"
+	PrometheusKey     = "prometheus"
+
+	GitSyncImgRepo = "k8s.gcr.io/git-sync"
+	GitSyncImgName = "git-sync"
+	GitSyncImgTag  = "v3.3.4"
+	GitSyncKey     = "git_sync"
 )
 
 const (
@@ -155,6 +365,8 @@ const (
 	Alertmanager            = "alertmanager"
 	ThanosReceiveController = "thanos-receive-controller"
 	ObservatoriumOperator   = "observatorium-operator"
+	OTLPReceiver            = "otlp-receiver"
+	Loki                    = "loki"
 )
 
 const (
@@ -179,7 +391,9 @@ var (
 	imageManifests              = map[string]string{}
 	hasCustomRuleConfigMap      = false
 	hasCustomAlertmanagerConfig = false
+	objStorageSecretName        = ""
 
+	Replicas0      int32 = 0
 	Replicas1      int32 = 1
 	Replicas2      int32 = 2
 	Replicas3      int32 = 3
@@ -189,6 +403,7 @@ var (
 		ThanosQueryFrontend: &Replicas2,
 		Grafana:             &Replicas2,
 		RbacQueryProxy:      &Replicas2,
+		OTLPReceiver:        &Replicas2,
 
 		ThanosRule:           &Replicas3,
 		ThanosReceive:        &Replicas3,
@@ -215,8 +430,10 @@ func GetClusterNameLabelKey() string {
 	return clusterNameLabelKey
 }
 
-// ReadImageManifestConfigMap reads configmap with the name is mch-image-manifest-xxx
-func ReadImageManifestConfigMap(c client.Client) (bool, error) {
+// ReadImageManifestConfigMap reads configmap with the name is mch-image-manifest-xxx.
+// The configmap is large and only read once, so an uncached reader should be passed in.
+// The image set which is applied before is used if the image manifest is rejected.
+func ReadImageManifestConfigMap(c client.Reader) (bool, error) {
 	//Only need to read if imageManifests is empty
 	if len(imageManifests) != 0 {
 		return false, nil
@@ -240,7 +457,14 @@ func ReadImageManifestConfigMap(c client.Client) (bool, error) {
 			},
 			imageCM)
 		if err == nil {
-			imageManifests = imageCM.Data
+			releaseImageManifests = imageCM.Data
+			// the image set of the release is not applied if it is rejected
+			state, err := GetImageManifestState(c)
+			if err != nil {
+				log.Error(err, "Failed to read the state of the image manifest")
+				return false, err
+			}
+			imageManifests = GetAppliedImageManifests(state, imageCM.Data)
 		} else {
 			if errors.IsNotFound(err) {
 				log.Info("Cannot get image manifest configmap", "configmap name", imageCMName)
@@ -301,6 +525,15 @@ func ReplaceImage(annotations map[string]string, imageRepo, componentName string
 	}
 }
 
+// GetComponentVersion returns the version of the operator
+func GetComponentVersion() string {
+	componentVersion, found := os.LookupEnv(ComponentVersion)
+	if !found || componentVersion == "" {
+		return defaultComponentVersion
+	}
+	return componentVersion
+}
+
 // GetDefaultTenantName returns the default tenant name
 func GetDefaultTenantName() string {
 	return defaultTenantName
@@ -317,10 +550,45 @@ func GetObsAPIUrl(client client.Client, namespace string) (string, error) {
 	return found.Spec.Host, nil
 }
 
+// GetObsAPIEndpoint returns the host of the observatorium api which the server certificate is issued
+// for. It follows the host of the route once the certificate covers the new host, so the managed
+// clusters never move to an endpoint which the certificate does not cover yet
+func GetObsAPIEndpoint(c client.Client, namespace string) (string, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: ServerCerts, Namespace: namespace}, secret)
+	if err != nil && !errors.IsNotFound(err) {
+		return "", err
+	}
+	if endpoint := secret.GetAnnotations()[ObsAPIEndpointAnnotation]; endpoint != "" {
+		return endpoint, nil
+	}
+	return GetObsAPIUrl(c, namespace)
+}
+
+// GetOTLPReceiverUrl is used to get the URL for otlp receiver
+func GetOTLPReceiverUrl(client client.Client, namespace string) (string, error) {
+	found := &routev1.Route{}
+
+	err := client.Get(context.TODO(), types.NamespacedName{Name: OTLPReceiver, Namespace: namespace}, found)
+	if err != nil {
+		return "", err
+	}
+	return found.Spec.Host, nil
+}
+
 func GetDefaultNamespace() string {
 	return defaultNamespace
 }
 
+// GetMCONamespace returns the namespace of the operator
+func GetMCONamespace() string {
+	podNamespace, found := os.LookupEnv("POD_NAMESPACE")
+	if !found {
+		podNamespace = defaultMCONamespace
+	}
+	return podNamespace
+}
+
 // GetMonitoringCRName returns monitoring cr name
 func GetMonitoringCRName() string {
 	return monitoringCRName
@@ -429,6 +697,162 @@ func GetObsAPISvc(instanceName string) string {
 	return instanceName + "-observatorium-api." + defaultNamespace + ".svc.cluster.local"
 }
 
+// GetThanosReceiveSvc returns thanos receive service
+func GetThanosReceiveSvc(instanceName string) string {
+	return instanceName + "-" + ThanosReceive + "." + defaultNamespace + ".svc.cluster.local"
+}
+
+// GetThanosQueryFrontendURL returns the query url of thanos query frontend
+func GetThanosQueryFrontendURL(instanceName string) string {
+	return "http://" + instanceName + "-" + ThanosQueryFrontend + "." + defaultNamespace + ".svc.cluster.local:9090"
+}
+
+// GetAlertingSelfTestURL returns the url of the receiver of the watchdog alerts, which is
+// served by the webhook server of the operator
+func GetAlertingSelfTestURL() string {
+	return "https://multicluster-observability-webhook-service." + GetMCONamespace() + ".svc" + AlertingSelfTestPath
+}
+
+// GetAlertmanagerSvcURL returns the url of the alertmanager service on the hub
+func GetAlertmanagerSvcURL() string {
+	return "http://" + Alertmanager + "." + defaultNamespace + ".svc.cluster.local:9093"
+}
+
+// GetThanosReceiveURL returns the remote write url of thanos receive
+func GetThanosReceiveURL(instanceName string) string {
+	return "http://" + GetThanosReceiveSvc(instanceName) + ":19291/api/v1/receive"
+}
+
+// GetOTLPReceiverSvc returns otlp receiver service
+func GetOTLPReceiverSvc() string {
+	return OTLPReceiver + "." + defaultNamespace + ".svc.cluster.local"
+}
+
+// GetLokiSvc returns loki service
+func GetLokiSvc() string {
+	return Loki + "." + defaultNamespace + ".svc.cluster.local"
+}
+
+// GetExternalMetricsStoreCredentials returns the secret of the credentials of the external metrics
+// store, or nil if no credentials are required
+func GetExternalMetricsStoreCredentials(c client.Client,
+	mco *mcov1beta2.MultiClusterObservability) (*corev1.Secret, error) {
+	if !IsExternalMetricsStoreEnabled(mco) || mco.Spec.ExternalMetricsStore.CredentialsSecret == "" {
+		return nil, nil
+	}
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      mco.Spec.ExternalMetricsStore.CredentialsSecret,
+		Namespace: GetDefaultNamespace(),
+	}, secret)
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// GetLokiUrl is used to get the URL for loki
+func GetLokiUrl(client client.Client, namespace string) (string, error) {
+	found := &routev1.Route{}
+
+	err := client.Get(context.TODO(), types.NamespacedName{Name: Loki, Namespace: namespace}, found)
+	if err != nil {
+		return "", err
+	}
+	return found.Spec.Host, nil
+}
+
+// IsOTLPReceiverEnabled returns true if the otlp receiver is deployed on the hub,
+// it is required by the traces collection
+func IsOTLPReceiverEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.EnableOTLPReceiver || IsTracingEnabled(mco)
+}
+
+// IsTracingEnabled returns true if the traces are collected from the managed clusters
+func IsTracingEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.Tracing != nil && mco.Spec.Tracing.Enabled
+}
+
+// GetTracingCAFile returns the path of the CA bundle which the server certificate of the tracing
+// backend is verified against in the otlp receiver
+func GetTracingCAFile(mco *mcov1beta2.MultiClusterObservability) string {
+	if mco.Spec.Tracing == nil || mco.Spec.Tracing.CASecretName == "" {
+		return ServiceCAFile
+	}
+	return TracingCAMountPath + "/" + TracingCAKey
+}
+
+// IsHubLokiEnabled returns true if the logs are collected into the loki on the hub
+func IsHubLokiEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	logs := mco.Spec.LogsCollection
+	return logs != nil && logs.Enabled && logs.ExternalLokiURL == ""
+}
+
+// IsGrafanaPersistenceEnabled returns true if the data of grafana is stored in a persistent volume claim
+func IsGrafanaPersistenceEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.Grafana != nil && mco.Spec.Grafana.Persistence != nil && mco.Spec.Grafana.Persistence.Enabled
+}
+
+// IsExternalMetricsStoreEnabled returns true if the metrics are stored in an external metrics store
+// instead of the observatorium on the hub
+func IsExternalMetricsStoreEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.ExternalMetricsStore != nil && mco.Spec.ExternalMetricsStore.RemoteWriteURL != ""
+}
+
+// IsScopeOrgIDRequired returns true if the external metrics store is Cortex or Mimir, which
+// require the X-Scope-OrgID header in the remote writes and the queries
+func IsScopeOrgIDRequired(mco *mcov1beta2.MultiClusterObservability) bool {
+	if !IsExternalMetricsStoreEnabled(mco) {
+		return false
+	}
+	storeType := mco.Spec.ExternalMetricsStore.Type
+	return storeType == ExternalMetricsStoreCortex || storeType == ExternalMetricsStoreMimir
+}
+
+// GetExternalMetricsStoreTenantID returns the X-Scope-OrgID of the clusters of the tenant, the
+// clusters which do not belong to a tenant use the default tenant ID of the external metrics store
+func GetExternalMetricsStoreTenantID(mco *mcov1beta2.MultiClusterObservability, tenant string) string {
+	if tenant != "" {
+		return tenant
+	}
+	if mco.Spec.ExternalMetricsStore.DefaultTenantID != "" {
+		return mco.Spec.ExternalMetricsStore.DefaultTenantID
+	}
+	return DefaultExternalMetricsStoreTenantID
+}
+
+// IsClusterDashboardsEnabled returns true if a drill-down dashboard is generated for each managed cluster
+func IsClusterDashboardsEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.Grafana != nil && mco.Spec.Grafana.ClusterDashboards
+}
+
+// IsGrafanaDashboardSyncEnabled returns true if the dashboards are synced from a git repository into grafana
+func IsGrafanaDashboardSyncEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.Grafana != nil && mco.Spec.Grafana.DashboardSync != nil &&
+		mco.Spec.Grafana.DashboardSync.Repository != ""
+}
+
+// IsCardinalityGuardEnabled returns true if the high-cardinality metrics are detected on the hub
+func IsCardinalityGuardEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.CardinalityGuard != nil && mco.Spec.CardinalityGuard.Enabled
+}
+
+// GetCardinalityExplosionExpr returns the expression of the metrics whose series are over
+// the threshold and more than doubled in the last hour in a cluster
+func GetCardinalityExplosionExpr(mco *mcov1beta2.MultiClusterObservability) string {
+	return fmt.Sprintf("%s > %d and %s > 2 * (%s offset 1h)", CardinalitySeriesRecord,
+		GetCardinalitySeriesThreshold(mco), CardinalitySeriesRecord, CardinalitySeriesRecord)
+}
+
+// GetCardinalitySeriesThreshold returns the number of the series of a metric in a cluster
+// over which the metric is alerted
+func GetCardinalitySeriesThreshold(mco *mcov1beta2.MultiClusterObservability) int32 {
+	if mco.Spec.CardinalityGuard == nil || mco.Spec.CardinalityGuard.SeriesThreshold <= 0 {
+		return defaultCardinalitySeriesThreshold
+	}
+	return mco.Spec.CardinalityGuard.SeriesThreshold
+}
+
 // SetCustomRuleConfigMap set true if there is custom rule configmap
 func SetCustomRuleConfigMap(hasConfigMap bool) {
 	hasCustomRuleConfigMap = hasConfigMap
@@ -438,3 +862,14 @@ func SetCustomRuleConfigMap(hasConfigMap bool) {
 func HasCustomRuleConfigMap() bool {
 	return hasCustomRuleConfigMap
 }
+
+// SetObjStorageSecretName sets the name of the secret of the object storage configuration
+func SetObjStorageSecretName(name string) {
+	objStorageSecretName = name
+}
+
+// GetObjStorageSecretName returns the name of the secret of the object storage configuration,
+// the thanos components are restarted when it is rotated
+func GetObjStorageSecretName() string {
+	return objStorageSecretName
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"errors"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	EncryptionSSES3  = "SSE-S3"
+	EncryptionSSEKMS = "SSE-KMS"
+)
+
+// IsObjStorageEncryptionEnabled returns true if the writes to the object storage are encrypted
+// with the server-side encryption of the storage config
+func IsObjStorageEncryptionEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.StorageConfig != nil && mco.Spec.StorageConfig.Encryption != nil
+}
+
+func validateEncryption(encryption *mcov1beta2.ObjStorageEncryptionSpec) error {
+	switch encryption.Type {
+	case EncryptionSSEKMS:
+		if encryption.KMSKeyID == "" {
+			return errors.New("no kmsKeyID for the SSE-KMS encryption")
+		}
+	case EncryptionSSES3:
+		if encryption.KMSKeyID != "" || len(encryption.KMSEncryptionContext) != 0 {
+			return errors.New("kmsKeyID and kmsEncryptionContext are only supported by the SSE-KMS encryption")
+		}
+	default:
+		return errors.New("invalid type of the encryption, only SSE-S3 and SSE-KMS are supported")
+	}
+	return nil
+}
+
+// GenerateEncryptionObjStorageConf returns the object storage configuration with the sse_config
+// of the server-side encryption. GCS and Azure do not take the keys in the requests of thanos,
+// their customer-managed keys are set as the default keys of the bucket or the storage account.
+func GenerateEncryptionObjStorageConf(data []byte,
+	encryption *mcov1beta2.ObjStorageEncryptionSpec) ([]byte, error) {
+	err := validateEncryption(encryption)
+	if err != nil {
+		return nil, err
+	}
+	var objectConfg ObjectStorgeConf
+	err = yaml.Unmarshal(data, &objectConfg)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ToLower(objectConfg.Type) != "s3" {
+		return nil, errors.New("the encryption is only supported by s3, set the customer-managed key " +
+			"as the default key of the gcs bucket or the azure storage account instead")
+	}
+
+	// keep the other fields of the configuration as they are
+	generated := map[string]interface{}{}
+	err = yaml.Unmarshal(data, &generated)
+	if err != nil {
+		return nil, err
+	}
+	config, ok := generated["config"].(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("no config in the object storage configuration")
+	}
+	sseConfig := map[string]interface{}{"type": encryption.Type}
+	if encryption.KMSKeyID != "" {
+		sseConfig["kms_key_id"] = encryption.KMSKeyID
+	}
+	if len(encryption.KMSEncryptionContext) != 0 {
+		sseConfig["kms_encryption_context"] = encryption.KMSEncryptionContext
+	}
+	config["sse_config"] = sseConfig
+	return yaml.Marshal(generated)
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+func TestGenerateEncryptionObjStorageConf(t *testing.T) {
+	s3Conf := []byte(`type: s3
+config:
+  bucket: bucket
+  endpoint: s3.us-east-1.amazonaws.com
+  access_key: access_key
+  secret_key: secret_key`)
+	caseList := []struct {
+		conf       []byte
+		encryption *mcov1beta2.ObjStorageEncryptionSpec
+		name       string
+		expected   map[interface{}]interface{}
+	}{
+		{
+			conf: s3Conf,
+			encryption: &mcov1beta2.ObjStorageEncryptionSpec{
+				Type:                 "SSE-KMS",
+				KMSKeyID:             "arn:aws:kms:us-east-1:123:key/abc",
+				KMSEncryptionContext: map[string]string{"team": "observability"},
+			},
+			name: "sse-kms",
+			expected: map[interface{}]interface{}{
+				"type":                   "SSE-KMS",
+				"kms_key_id":             "arn:aws:kms:us-east-1:123:key/abc",
+				"kms_encryption_context": map[interface{}]interface{}{"team": "observability"},
+			},
+		},
+		{
+			conf:       s3Conf,
+			encryption: &mcov1beta2.ObjStorageEncryptionSpec{Type: "SSE-S3"},
+			name:       "sse-s3",
+			expected:   map[interface{}]interface{}{"type": "SSE-S3"},
+		},
+		{
+			conf:       s3Conf,
+			encryption: &mcov1beta2.ObjStorageEncryptionSpec{Type: "SSE-KMS"},
+			name:       "sse-kms without key",
+		},
+		{
+			conf:       s3Conf,
+			encryption: &mcov1beta2.ObjStorageEncryptionSpec{Type: "SSE-S3", KMSKeyID: "key"},
+			name:       "sse-s3 with key",
+		},
+		{
+			conf: []byte(`type: gcs
+config:
+  bucket: bucket
+  service_account: service_account`),
+			encryption: &mcov1beta2.ObjStorageEncryptionSpec{Type: "SSE-KMS", KMSKeyID: "key"},
+			name:       "gcs",
+		},
+	}
+
+	for _, c := range caseList {
+		data, err := GenerateEncryptionObjStorageConf(c.conf, c.encryption)
+		if c.expected == nil {
+			if err == nil {
+				t.Errorf("case (%v) should be invalid", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case (%v) failed to generate the configuration: (%v)", c.name, err)
+			continue
+		}
+		generated := struct {
+			Config map[string]interface{} `yaml:"config"`
+		}{}
+		err = yaml.Unmarshal(data, &generated)
+		if err != nil {
+			t.Errorf("case (%v) failed to unmarshal the configuration: (%v)", c.name, err)
+			continue
+		}
+		if generated.Config["access_key"] != "access_key" {
+			t.Errorf("case (%v) should keep the other fields: %v", c.name, generated.Config)
+		}
+		sseConfig, ok := generated.Config["sse_config"].(map[interface{}]interface{})
+		if !ok || len(sseConfig) != len(c.expected) || sseConfig["type"] != c.expected["type"] ||
+			sseConfig["kms_key_id"] != c.expected["kms_key_id"] {
+			t.Errorf("case (%v) output: %v is not the expected: %v", c.name, sseConfig, c.expected)
+		}
+	}
+}
+
+func TestGenerateObjStorageConf(t *testing.T) {
+	conf := []byte(`type: s3
+config:
+  bucket: bucket
+  endpoint: s3.us-east-1.amazonaws.com`)
+	storageConfig := &mcov1beta2.StorageConfig{
+		Encryption: &mcov1beta2.ObjStorageEncryptionSpec{Type: "SSE-S3"},
+	}
+	_, err := GenerateObjStorageConf(conf, storageConfig)
+	if err == nil {
+		t.Errorf("The static keys are required without the workload identity")
+	}
+
+	storageConfig.WorkloadIdentity = &mcov1beta2.WorkloadIdentitySpec{
+		Provider: "AWS",
+		RoleARN:  "arn:aws:iam::123:role/thanos",
+	}
+	data, err := GenerateObjStorageConf(conf, storageConfig)
+	if err != nil {
+		t.Fatalf("Failed to generate the configuration: (%v)", err)
+	}
+	generated := &ObjectStorgeConf{}
+	err = yaml.Unmarshal(data, generated)
+	if err != nil || generated.Config.Bucket != "bucket" {
+		t.Errorf("Wrong configuration with the workload identity and the encryption: %s", string(data))
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+var (
+	labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// the labels which the observability sets itself, they cannot be overridden by the hub labels
+	reservedExternalLabels = map[string]bool{
+		clusterNameLabelKey: true,
+		ClusterIDLabelName:  true,
+		TenantLabelName:     true,
+		"receive":           true,
+		"replica":           true,
+		"tenant_id":         true,
+	}
+)
+
+// ValidateExternalLabels returns an error for the first invalid or reserved external label of the hub
+func ValidateExternalLabels(labels map[string]string) error {
+	names := []string{}
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !labelNameRegexp.MatchString(name) {
+			return fmt.Errorf("the external label %q is not a valid label name", name)
+		}
+		if reservedExternalLabels[name] {
+			return fmt.Errorf("the external label %q is reserved by the observability", name)
+		}
+		if labels[name] == "" {
+			return fmt.Errorf("the value of the external label %q is empty", name)
+		}
+	}
+	return nil
+}
+
+// GetHubExternalLabels returns the external labels which are applied to all the series stored on
+// the hub, the invalid and the reserved labels are skipped
+func GetHubExternalLabels(mco *mcov1beta2.MultiClusterObservability) map[string]string {
+	labels := map[string]string{}
+	for name, value := range mco.Spec.ExternalLabels {
+		if err := ValidateExternalLabels(map[string]string{name: value}); err != nil {
+			log.Info("Skipping the invalid external label of the hub", "reason", err.Error())
+			continue
+		}
+		labels[name] = value
+	}
+	return labels
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+func TestValidateExternalLabels(t *testing.T) {
+	caseList := []struct {
+		labels map[string]string
+		valid  bool
+	}{
+		{map[string]string{"hub": "hub-east", "business_unit": "retail"}, true},
+		{map[string]string{"business-unit": "retail"}, false},
+		{map[string]string{"0hub": "hub-east"}, false},
+		{map[string]string{"cluster": "hub-east"}, false},
+		{map[string]string{"replica": "0"}, false},
+		{map[string]string{"hub": ""}, false},
+	}
+	for _, c := range caseList {
+		if err := ValidateExternalLabels(c.labels); (err == nil) != c.valid {
+			t.Errorf("The external labels %v should be valid: %v (%v)", c.labels, c.valid, err)
+		}
+	}
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			ExternalLabels: map[string]string{"hub": "hub-east", "tenant": "team-a", "bu-name": "retail"},
+		},
+	}
+	if labels := GetHubExternalLabels(mco); !reflect.DeepEqual(labels, map[string]string{"hub": "hub-east"}) {
+		t.Errorf("The invalid and the reserved external labels should be skipped: %v", labels)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	ExternalSecretCrdName    = "externalsecrets.external-secrets.io"
+	ExternalSecretGroup      = "external-secrets.io"
+	ExternalSecretAPIVersion = "external-secrets.io/v1beta1"
+	ExternalSecretKind       = "ExternalSecret"
+	ExternalSecretListKind   = "ExternalSecretList"
+	// ExternalSecretOwnerLabel is the label of the ExternalSecrets which are created by the operator
+	ExternalSecretOwnerLabel = "observability.open-cluster-management.io/external-secret-owner"
+)
+
+// IsExternalSecretsEnabled returns true if some observability secrets are synced from an external
+// secret manager by the External Secrets Operator
+func IsExternalSecretsEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.ExternalSecrets != nil && len(mco.Spec.ExternalSecrets.Secrets) != 0
+}
+
+// GetExternalSecretNamespace returns the namespace of the secret which is synced from the secret store
+func GetExternalSecretNamespace(secret mcov1beta2.ExternalSecret) string {
+	if secret.Namespace != "" {
+		return secret.Namespace
+	}
+	return GetDefaultNamespace()
+}
+
+// IsExternalSecretOwned returns true if the secret is created and refreshed by an ExternalSecret
+func IsExternalSecretOwned(obj metav1.Object) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err == nil && gv.Group == ExternalSecretGroup && ref.Kind == ExternalSecretKind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+// FederatedHubDatasourcePrefix is the prefix of the names of the grafana datasources of the other hubs
+const FederatedHubDatasourcePrefix = "Observatorium-hub-"
+
+// GetFederatedHubCredentials returns the secret of the credentials of the other hub, or nil if the
+// hub is queried without the credentials
+func GetFederatedHubCredentials(c client.Client, hub mcov1beta2.FederatedHubSpec) (*corev1.Secret, error) {
+	if hub.CredentialsSecret == "" {
+		return nil, nil
+	}
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      hub.CredentialsSecret,
+		Namespace: GetDefaultNamespace(),
+	}, secret)
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// ValidateFederatedHubs returns an error if the names of the other hubs are duplicated or their
+// query URLs are invalid
+func ValidateFederatedHubs(mco *mcov1beta2.MultiClusterObservability) error {
+	names := map[string]bool{}
+	for _, hub := range mco.Spec.FederatedHubs {
+		if names[hub.Name] {
+			return fmt.Errorf("the name of the federated hub %q is duplicated", hub.Name)
+		}
+		names[hub.Name] = true
+		u, err := url.Parse(hub.QueryURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("the queryURL of the federated hub %q is not a valid http(s) URL: %q",
+				hub.Name, hub.QueryURL)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	// CollectorServiceAccountName is the service account on the hub whose tokens the managed clusters
+	// request to authenticate their remote writes
+	CollectorServiceAccountName = "observability-collector"
+	// CollectorTokenRoleName is the role which allows to request the tokens of the service account
+	CollectorTokenRoleName = "observability-collector-token"
+	// HubIssuerCAConfigMapName is the configmap which kubernetes publishes in every namespace with
+	// the CA of the API server, which serves the discovery of the service account issuer
+	HubIssuerCAConfigMapName = "kube-root-ca.crt"
+	HubIssuerCAKey           = "ca.crt"
+
+	// AuthModeAnnotation is the annotation of the ManagedClusterAddOn with the authentication which the
+	// managed cluster is switched to, it is not set for the client certificate
+	AuthModeAnnotation = "observability.open-cluster-management.io/auth-mode"
+	// AuthModeSinceAnnotation is the time when the managed cluster is switched to the authentication
+	AuthModeSinceAnnotation = "observability.open-cluster-management.io/auth-mode-since"
+	// TokenAuthMode is the authentication with the service account tokens
+	TokenAuthMode = "token"
+	// RevertingAuthMode is set while the managed cluster switches back from the service account tokens
+	// to the client certificate, it keeps the tokens until the client certificate is issued
+	RevertingAuthMode = "reverting"
+
+	// the validity of the client certificate is recorded on the ManagedClusterAddOn of the managed cluster
+	ClientCertNotBeforeAnnotation = "observability.open-cluster-management.io/client-cert-not-before"
+	ClientCertNotAfterAnnotation  = "observability.open-cluster-management.io/client-cert-not-after"
+	ClientCertSerialAnnotation    = "observability.open-cluster-management.io/client-cert-serial"
+	ClientCertIssuerAnnotation    = "observability.open-cluster-management.io/client-cert-issuer"
+
+	// ClientCARotationAnnotation is the annotation of the client CA secret with the rotationID which
+	// the client CA is rotated for, and ClientCARotatedAtAnnotation is the time of the rotation
+	ClientCARotationAnnotation  = "observability.open-cluster-management.io/client-ca-rotation"
+	ClientCARotatedAtAnnotation = "observability.open-cluster-management.io/client-ca-rotated-at"
+
+	defaultTokenAudience          = "observability-api"
+	defaultTokenExpirationSeconds = 3600
+	defaultHubIssuerURL           = "https://kubernetes.default.svc"
+)
+
+// getServiceAccountTokenSpec returns the spec of the token authentication, it is kept after the token
+// authentication is disabled so that the tokens are still validated until the clusters switch back
+func getServiceAccountTokenSpec(mco *mcov1beta2.MultiClusterObservability) *mcov1beta2.GatewayServiceAccountTokenSpec {
+	if mco.Spec.GatewayAuth == nil {
+		return nil
+	}
+	return mco.Spec.GatewayAuth.ServiceAccountToken
+}
+
+// IsTokenAuthEnabled returns true if the managed clusters may authenticate with the service account
+// tokens, which only applies to the observability API gateway on the hub and not to the external
+// metrics store
+func IsTokenAuthEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	spec := getServiceAccountTokenSpec(mco)
+	return spec != nil && spec.Enabled && !IsExternalMetricsStoreEnabled(mco)
+}
+
+// IsTokenAuthInUse returns true if the token authentication is enabled, or some managed clusters still
+// authenticate with the tokens while they switch back to the client certificates
+func IsTokenAuthInUse(c client.Client, mco *mcov1beta2.MultiClusterObservability) (bool, error) {
+	if IsTokenAuthEnabled(mco) {
+		return true, nil
+	}
+	addons := &addonv1alpha1.ManagedClusterAddOnList{}
+	if err := c.List(context.TODO(), addons); err != nil {
+		return false, err
+	}
+	for _, addon := range addons.Items {
+		if addon.Annotations[AuthModeAnnotation] != "" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetAuthMigrationRate returns the number of the managed clusters per minute which are switched between
+// the client certificates and the service account tokens, or 0 if they are switched at once
+func GetAuthMigrationRate(mco *mcov1beta2.MultiClusterObservability) int32 {
+	if mco.Spec.GatewayAuth == nil {
+		return 0
+	}
+	return mco.Spec.GatewayAuth.MigrationRate
+}
+
+// IsTokenAuthCluster returns true if the managed cluster with the labels authenticates with the service
+// account tokens instead of the client certificates
+func IsTokenAuthCluster(mco *mcov1beta2.MultiClusterObservability, clusterLabels map[string]string) (bool, error) {
+	if !IsTokenAuthEnabled(mco) {
+		return false, nil
+	}
+	spec := getServiceAccountTokenSpec(mco)
+	if spec.ClusterSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(spec.ClusterSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid clusterSelector of the serviceAccountToken: %v", err)
+	}
+	return selector.Matches(labels.Set(clusterLabels)), nil
+}
+
+// GetTokenAudience returns the audience of the service account tokens
+func GetTokenAudience(mco *mcov1beta2.MultiClusterObservability) string {
+	if spec := getServiceAccountTokenSpec(mco); spec != nil && spec.Audience != "" {
+		return spec.Audience
+	}
+	return defaultTokenAudience
+}
+
+// GetTokenExpirationSeconds returns the validity of the service account tokens
+func GetTokenExpirationSeconds(mco *mcov1beta2.MultiClusterObservability) int64 {
+	if spec := getServiceAccountTokenSpec(mco); spec != nil && spec.ExpirationSeconds != 0 {
+		return spec.ExpirationSeconds
+	}
+	return defaultTokenExpirationSeconds
+}
+
+// GetHubIssuerURL returns the URL of the service account issuer of the hub
+func GetHubIssuerURL(mco *mcov1beta2.MultiClusterObservability) string {
+	if spec := getServiceAccountTokenSpec(mco); spec != nil && spec.IssuerURL != "" {
+		return spec.IssuerURL
+	}
+	return defaultHubIssuerURL
+}
+
+// GetCollectorServiceAccountUser returns the user of the service account tokens, which is the
+// sub claim of the tokens
+func GetCollectorServiceAccountUser() string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", GetDefaultNamespace(), CollectorServiceAccountName)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	DefaultHAZoneLabel         = "topology.kubernetes.io/zone"
+	DefaultHAMinZones          = 3
+	DefaultHAReplicationFactor = 3
+)
+
+// hubZonesSatisfied is set once the nodes of the observability components span enough zones
+var hubZonesSatisfied = false
+
+// IsHighAvailabilityEnabled returns true if the components on the hub are deployed in the highly
+// available topology
+func IsHighAvailabilityEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.HighAvailability != nil
+}
+
+// GetHAZoneLabel returns the label of the nodes with their availability zone
+func GetHAZoneLabel(mco *mcov1beta2.MultiClusterObservability) string {
+	if mco.Spec.HighAvailability.ZoneLabel != "" {
+		return mco.Spec.HighAvailability.ZoneLabel
+	}
+	return DefaultHAZoneLabel
+}
+
+// GetHAMinZones returns the minimum number of the zones which the components must span
+func GetHAMinZones(mco *mcov1beta2.MultiClusterObservability) int {
+	if mco.Spec.HighAvailability.MinZones != 0 {
+		return int(mco.Spec.HighAvailability.MinZones)
+	}
+	return DefaultHAMinZones
+}
+
+// GetHAReplicationFactor returns the number of the copies of every series in thanos receive
+func GetHAReplicationFactor(mco *mcov1beta2.MultiClusterObservability) int32 {
+	if mco.Spec.HighAvailability.ReplicationFactor != 0 {
+		return mco.Spec.HighAvailability.ReplicationFactor
+	}
+	return DefaultHAReplicationFactor
+}
+
+// SetHubZonesSatisfied records whether the nodes of the components span enough zones
+func SetHubZonesSatisfied(satisfied bool) {
+	hubZonesSatisfied = satisfied
+}
+
+// GetHATopologySpreadConstraint returns the constraint which spreads the pods with the labels across
+// the zones. The pods are only held pending for the spread once the hub has enough zones, otherwise
+// they are spread on a best effort basis.
+func GetHATopologySpreadConstraint(mco *mcov1beta2.MultiClusterObservability,
+	labels map[string]string) corev1.TopologySpreadConstraint {
+	whenUnsatisfiable := corev1.ScheduleAnyway
+	if hubZonesSatisfied {
+		whenUnsatisfiable = corev1.DoNotSchedule
+	}
+	return corev1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       GetHAZoneLabel(mco),
+		WhenUnsatisfiable: whenUnsatisfiable,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ImageManifestStateConfigMapName is the configmap in the namespace of the operands which keeps
+	// the image sets applied from the image manifest of the release
+	ImageManifestStateConfigMapName = "observability-image-manifest-state"
+	ImageManifestStateKey           = "state.yaml"
+
+	// the reasons why the image set of the release is not applied
+	ImageManifestRejectedInvalid    = "Invalid"
+	ImageManifestRejectedRolledBack = "RolledBack"
+)
+
+var (
+	// the image manifest of the release, imageManifests is the image set which is applied
+	releaseImageManifests = map[string]string{}
+
+	// the reference of an image, [registry[:port]/]repository[:tag][@digest]
+	imageReferenceRegexp = regexp.MustCompile(`^` +
+		`(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?/)?` +
+		`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+		`(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?` +
+		`(?:@[a-zA-Z][a-zA-Z0-9]*(?:[-_+.][a-zA-Z][a-zA-Z0-9]*)*:[0-9a-fA-F]{32,})?$`)
+	imageDigestRegexp = regexp.MustCompile(`@sha256:[0-9a-f]{64}$`)
+)
+
+// ImageSet is a set of the images of the components identified by its hash
+type ImageSet struct {
+	Hash   string            `yaml:"hash,omitempty"`
+	Images map[string]string `yaml:"images,omitempty"`
+}
+
+// ImageManifestRejection is the image set of the release which is not applied
+type ImageManifestRejection struct {
+	Hash    string `yaml:"hash,omitempty"`
+	Reason  string `yaml:"reason,omitempty"`
+	Message string `yaml:"message,omitempty"`
+}
+
+// ImageManifestState is the image set which is applied, the image set which was applied before it,
+// and the image set of the release which is rejected. The rejected image set is not applied again
+// until the image manifest of the release changes.
+type ImageManifestState struct {
+	Current   ImageSet               `yaml:"current,omitempty"`
+	Previous  ImageSet               `yaml:"previous,omitempty"`
+	AppliedAt string                 `yaml:"appliedAt,omitempty"`
+	Rejected  ImageManifestRejection `yaml:"rejected,omitempty"`
+}
+
+// Apply makes the image set the current one, the current image set becomes the previous one
+func (s *ImageManifestState) Apply(images map[string]string) {
+	if s.Current.Hash != "" {
+		s.Previous = s.Current
+	}
+	s.Current = ImageSet{Hash: GetImageManifestsHash(images), Images: images}
+	s.AppliedAt = time.Now().UTC().Format(time.RFC3339)
+	s.Rejected = ImageManifestRejection{}
+}
+
+// Reject rejects the image set of the release, the previous image set becomes the current one
+// again if the rejected image set is applied
+func (s *ImageManifestState) Reject(hash, reason, message string) {
+	s.Rejected = ImageManifestRejection{Hash: hash, Reason: reason, Message: message}
+	if s.Current.Hash == hash {
+		s.Current, s.Previous = s.Previous, ImageSet{}
+		s.AppliedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+}
+
+// GetReleaseImageManifests returns the image manifest of the release, it can be different from the
+// images which are applied if the image manifest is rejected
+func GetReleaseImageManifests() map[string]string {
+	return releaseImageManifests
+}
+
+// SetReleaseImageManifests sets releaseImageManifests
+func SetReleaseImageManifests(images map[string]string) {
+	releaseImageManifests = images
+}
+
+// GetImageManifestsHash returns the hash of the image set, which does not depend on the order of the images
+func GetImageManifestsHash(images map[string]string) string {
+	keys := make([]string, 0, len(images))
+	for key := range images {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s=%s\n", key, images[key])
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil))
+}
+
+// ValidateImageReference checks the syntax of the image reference, and that the image is pinned by
+// its sha256 digest if the digest is required
+func ValidateImageReference(image string, requireDigest bool) error {
+	if !imageReferenceRegexp.MatchString(image) {
+		return fmt.Errorf("invalid image reference %q", image)
+	}
+	if requireDigest && !imageDigestRegexp.MatchString(image) {
+		return fmt.Errorf("image %q is not pinned by its sha256 digest", image)
+	}
+	return nil
+}
+
+// ValidateImageManifests checks all the images of the image manifest
+func ValidateImageManifests(images map[string]string, requireDigest bool) error {
+	keys := make([]string, 0, len(images))
+	for key := range images {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := ValidateImageReference(images[key], requireDigest); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// GetImageManifestState returns the state of the image sets, it is empty if no image set is applied
+func GetImageManifestState(c client.Reader) (*ImageManifestState, error) {
+	state := &ImageManifestState{}
+	cm := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      ImageManifestStateConfigMapName,
+		Namespace: GetDefaultNamespace(),
+	}, cm)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return state, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal([]byte(cm.Data[ImageManifestStateKey]), state); err != nil {
+		log.Error(err, "The state of the image manifest is corrupted, start a new one",
+			"configmap", ImageManifestStateConfigMapName)
+		return &ImageManifestState{}, nil
+	}
+	return state, nil
+}
+
+// SaveImageManifestState writes the state of the image sets into the configmap
+func SaveImageManifestState(c client.Client, state *ImageManifestState) error {
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{}
+	err = c.Get(context.TODO(), types.NamespacedName{
+		Name:      ImageManifestStateConfigMapName,
+		Namespace: GetDefaultNamespace(),
+	}, cm)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ImageManifestStateConfigMapName,
+				Namespace: GetDefaultNamespace(),
+			},
+			Data: map[string]string{ImageManifestStateKey: string(data)},
+		}
+		return c.Create(context.TODO(), cm)
+	}
+	if cm.Data[ImageManifestStateKey] == string(data) {
+		return nil
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[ImageManifestStateKey] = string(data)
+	return c.Update(context.TODO(), cm)
+}
+
+// GetAppliedImageManifests returns the images to apply for the image manifest of the release, it is
+// the current image set instead if the image manifest of the release is rejected
+func GetAppliedImageManifests(state *ImageManifestState, release map[string]string) map[string]string {
+	if state.Rejected.Hash == "" || state.Rejected.Hash != GetImageManifestsHash(release) {
+		return release
+	}
+	images := map[string]string{}
+	for key, image := range state.Current.Images {
+		images[key] = image
+	}
+	return images
+}
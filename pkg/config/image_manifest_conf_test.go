@@ -0,0 +1,102 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestValidateImageReference(t *testing.T) {
+	caseList := []struct {
+		image         string
+		requireDigest bool
+		valid         bool
+	}{
+		{image: "thanos", valid: true},
+		{image: "quay.io/open-cluster-management/thanos:2.3.0", valid: true},
+		{image: "registry.example.com:5000/ns/thanos_receive:v1.2-rc.1", valid: true},
+		{image: "quay.io/open-cluster-management/thanos@" + testDigest, valid: true},
+		{image: "quay.io/open-cluster-management/thanos:2.3.0@" + testDigest, requireDigest: true, valid: true},
+		{image: "quay.io/open-cluster-management/thanos:2.3.0", requireDigest: true, valid: false},
+		{image: "", valid: false},
+		{image: "quay.io/Open/Thanos:2.3.0", valid: false},
+		{image: "quay.io/thanos:2.3.0 ", valid: false},
+		{image: "quay.io/thanos::2.3.0", valid: false},
+		{image: "quay.io/thanos@sha256:abc", valid: false},
+	}
+	for _, c := range caseList {
+		err := ValidateImageReference(c.image, c.requireDigest)
+		if (err == nil) != c.valid {
+			t.Errorf("image %q (requireDigest %v): expected valid %v, got error %v", c.image, c.requireDigest, c.valid, err)
+		}
+	}
+
+	err := ValidateImageManifests(map[string]string{
+		"thanos":     "quay.io/thanos@" + testDigest,
+		"prometheus": "quay.io/prometheus:v2.26.0",
+	}, true)
+	if err == nil || !strings.HasPrefix(err.Error(), "prometheus:") {
+		t.Errorf("expected the unpinned prometheus image to be rejected, got %v", err)
+	}
+}
+
+func TestGetImageManifestsHash(t *testing.T) {
+	images := map[string]string{"a": "quay.io/a:1", "b": "quay.io/b:1"}
+	hash := GetImageManifestsHash(images)
+	if !strings.HasPrefix(hash, "sha256:") {
+		t.Errorf("unexpected hash %s", hash)
+	}
+	if hash != GetImageManifestsHash(map[string]string{"b": "quay.io/b:1", "a": "quay.io/a:1"}) {
+		t.Errorf("the hash depends on the order of the images")
+	}
+	if hash == GetImageManifestsHash(map[string]string{"a": "quay.io/a:1", "b": "quay.io/b:2"}) {
+		t.Errorf("the hash does not change with the images")
+	}
+}
+
+func TestImageManifestState(t *testing.T) {
+	scheme := runtime.NewScheme()
+	corev1.AddToScheme(scheme)
+	c := fake.NewFakeClientWithScheme(scheme)
+
+	oldImages := map[string]string{"thanos": "quay.io/thanos:1"}
+	newImages := map[string]string{"thanos": "quay.io/thanos:2"}
+	state, err := GetImageManifestState(c)
+	if err != nil {
+		t.Fatalf("Failed to get the state: (%v)", err)
+	}
+	state.Apply(oldImages)
+	state.Apply(newImages)
+	if state.Previous.Hash != GetImageManifestsHash(oldImages) || state.Current.Hash != GetImageManifestsHash(newImages) {
+		t.Errorf("unexpected state after the images are applied: %v", state)
+	}
+	if !reflect.DeepEqual(GetAppliedImageManifests(state, newImages), newImages) {
+		t.Errorf("the images of the release are not applied")
+	}
+
+	state.Reject(GetImageManifestsHash(newImages), ImageManifestRejectedRolledBack, "degraded")
+	err = SaveImageManifestState(c, state)
+	if err != nil {
+		t.Fatalf("Failed to save the state: (%v)", err)
+	}
+	state, err = GetImageManifestState(c)
+	if err != nil {
+		t.Fatalf("Failed to get the state: (%v)", err)
+	}
+	if state.Current.Hash != GetImageManifestsHash(oldImages) || state.Previous.Hash != "" ||
+		state.Rejected.Reason != ImageManifestRejectedRolledBack {
+		t.Errorf("unexpected state after the images are rolled back: %v", state)
+	}
+	if !reflect.DeepEqual(GetAppliedImageManifests(state, newImages), oldImages) {
+		t.Errorf("the rolled back images are applied")
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// IngestionErrorsConfigMapName is the configmap in the namespace of the operands which reports
+	// the managed clusters whose remote writes are rejected by the hub
+	IngestionErrorsConfigMapName = "observability-ingestion-errors"
+	IngestionErrorsFileKey       = "report.yaml"
+
+	// the number of the managed clusters with most rejected remote writes which are reported
+	IngestionErrorsTopClusters = 10
+	// the rejected remote writes of the metrics collectors in the last hour by cluster, the
+	// collectors count the remote writes which the hub rejects, e.g. with 409, 429 or 5xx
+	ingestionErrorsExpr = "topk(%d, sum by (%s) (increase(forward_errors[1h])) > 0)"
+)
+
+// IngestionErrorsReport attributes the rejected remote writes to the managed clusters
+type IngestionErrorsReport struct {
+	// Clusters are the managed clusters with most rejected remote writes in the last hour
+	Clusters []ClusterIngestionErrors `yaml:"clusters"`
+}
+
+// ClusterIngestionErrors is the number of the rejected remote writes of the managed cluster
+type ClusterIngestionErrors struct {
+	Cluster string `yaml:"cluster"`
+	Errors  int64  `yaml:"errors"`
+}
+
+// GetIngestionErrorsExpr returns the query of the managed clusters with most rejected remote writes
+func GetIngestionErrorsExpr() string {
+	return fmt.Sprintf(ingestionErrorsExpr, IngestionErrorsTopClusters, GetClusterNameLabelKey())
+}
+
+// GetIngestionErrorsReport returns the report of the rejected remote writes, or nil if it is not
+// generated yet
+func GetIngestionErrorsReport(c client.Reader) (*IngestionErrorsReport, error) {
+	cm := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{
+		Name:      IngestionErrorsConfigMapName,
+		Namespace: GetDefaultNamespace(),
+	}, cm)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	report := &IngestionErrorsReport{}
+	if err := yaml.Unmarshal([]byte(cm.Data[IngestionErrorsFileKey]), report); err != nil {
+		log.Error(err, "The report of the ingestion errors is invalid", "configmap", IngestionErrorsConfigMapName)
+		return nil, nil
+	}
+	return report, nil
+}
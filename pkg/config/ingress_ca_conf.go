@@ -0,0 +1,131 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"bytes"
+	"context"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// IngressCAConfigMapNamespace and IngressCAConfigMapName are the configmap which publishes the CA
+	// bundle of the certificate which the default ingress controller serves
+	IngressCAConfigMapNamespace = "openshift-config-managed"
+	IngressCAConfigMapName      = "default-ingress-cert"
+	ingressCAKey                = "ca-bundle.crt"
+	ingressNamespace            = "openshift-ingress"
+	ingressOperatorNamespace    = "openshift-ingress-operator"
+	ingressGeneratedCAName      = "router-ca"
+	defaultRouterName           = "default"
+)
+
+// IsObsAPIRoute returns true if the object is the route of the observatorium api
+func IsObsAPIRoute(obj v1.Object) bool {
+	return obj.GetName() == obsAPIGateway && obj.GetNamespace() == GetDefaultNamespace()
+}
+
+// GetObsAPIIngressCA returns the CA bundle of the certificate which the route of the observatorium
+// api is served by if the route terminates TLS itself, e.g. it is changed to the edge termination
+// and served by the wildcard certificate of the ingress controller. It returns nil if the route
+// passes TLS through, so that the observatorium api serves the certificate signed by the server CA.
+func GetObsAPIIngressCA(c client.Client, namespace string) ([]byte, error) {
+	route := &routev1.Route{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: obsAPIGateway, Namespace: namespace}, route)
+	if err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if route.Spec.TLS == nil || route.Spec.TLS.Termination == routev1.TLSTerminationPassthrough {
+		return nil, nil
+	}
+
+	var ca []byte
+	switch {
+	case route.Spec.TLS.CACertificate != "":
+		ca = []byte(route.Spec.TLS.CACertificate)
+	case route.Spec.TLS.Certificate != "":
+		// the certificate of the route is trusted directly if the route does not carry its CA
+		ca = []byte(route.Spec.TLS.Certificate)
+	default:
+		ca, err = getRouterCA(c, getAdmittedRouter(route))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(ca) > 0 && !bytes.HasSuffix(ca, []byte("\n")) {
+		ca = append(ca, '\n')
+	}
+	return ca, nil
+}
+
+// getAdmittedRouter returns the name of the ingress controller which admits the route
+func getAdmittedRouter(route *routev1.Route) string {
+	for _, ingress := range route.Status.Ingress {
+		for _, cond := range ingress.Conditions {
+			if cond.Type == routev1.RouteAdmitted && cond.Status == corev1.ConditionTrue &&
+				ingress.RouterName != "" {
+				return ingress.RouterName
+			}
+		}
+	}
+	return defaultRouterName
+}
+
+// getRouterCA returns the CA bundle of the default certificate of the ingress controller. The CA of
+// the default ingress controller is published in the default-ingress-cert configmap, the other
+// ingress controllers serve either the custom default certificate or the one signed by the router CA.
+func getRouterCA(c client.Client, routerName string) ([]byte, error) {
+	if routerName == defaultRouterName {
+		cm := &corev1.ConfigMap{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: IngressCAConfigMapName,
+			Namespace: IngressCAConfigMapNamespace}, cm)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				log.Info("The CA bundle of the default ingress controller is not found", "name", IngressCAConfigMapName)
+				return nil, nil
+			}
+			return nil, err
+		}
+		return []byte(cm.Data[ingressCAKey]), nil
+	}
+
+	ingressController := &unstructured.Unstructured{}
+	ingressController.SetAPIVersion("operator.openshift.io/v1")
+	ingressController.SetKind("IngressController")
+	err := c.Get(context.TODO(), types.NamespacedName{Name: routerName, Namespace: ingressOperatorNamespace},
+		ingressController)
+	if err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			log.Info("The ingress controller of the observatorium api route is not found", "name", routerName)
+			return nil, nil
+		}
+		return nil, err
+	}
+	secretName, secretNamespace := ingressGeneratedCAName, ingressOperatorNamespace
+	if name, _, _ := unstructured.NestedString(ingressController.Object, "spec", "defaultCertificate", "name"); name != "" {
+		// the chain of the custom default certificate is trusted as it is served
+		secretName, secretNamespace = name, ingressNamespace
+	}
+	secret := &corev1.Secret{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: secretNamespace}, secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("The certificate of the ingress controller is not found", "name", secretName)
+			return nil, nil
+		}
+		return nil, err
+	}
+	return secret.Data["tls.crt"], nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"testing"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetObsAPIIngressCA(t *testing.T) {
+	ingressCA := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: IngressCAConfigMapName, Namespace: IngressCAConfigMapNamespace},
+		Data:       map[string]string{ingressCAKey: "ingress-ca\n"},
+	}
+	caseList := []struct {
+		name     string
+		tls      *routev1.TLSConfig
+		expected string
+	}{
+		{
+			name:     "passthrough",
+			tls:      &routev1.TLSConfig{Termination: routev1.TLSTerminationPassthrough},
+			expected: "",
+		},
+		{
+			name:     "edge with the default certificate of the ingress controller",
+			tls:      &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge},
+			expected: "ingress-ca\n",
+		},
+		{
+			name: "edge with the certificate and the ca of the route",
+			tls: &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge,
+				Certificate: "route-cert", CACertificate: "route-ca"},
+			expected: "route-ca\n",
+		},
+		{
+			name:     "reencrypt with the certificate of the route",
+			tls:      &routev1.TLSConfig{Termination: routev1.TLSTerminationReencrypt, Certificate: "route-cert"},
+			expected: "route-cert\n",
+		},
+	}
+
+	for _, c := range caseList {
+		route := &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{Name: obsAPIGateway, Namespace: "test"},
+			Spec:       routev1.RouteSpec{Host: apiServerURL, TLS: c.tls},
+		}
+		scheme := runtime.NewScheme()
+		scheme.AddKnownTypes(routev1.GroupVersion, route)
+		scheme.AddKnownTypes(corev1.SchemeGroupVersion, ingressCA)
+		client := fake.NewFakeClientWithScheme(scheme, route, ingressCA)
+
+		ca, err := GetObsAPIIngressCA(client, "test")
+		if err != nil {
+			t.Fatalf("case: %s, failed to get the ca of the route: (%v)", c.name, err)
+		}
+		if string(ca) != c.expected {
+			t.Errorf("case: %s, the ca (%s) is not the expected (%s)", c.name, ca, c.expected)
+		}
+	}
+}
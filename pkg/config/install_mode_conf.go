@@ -0,0 +1,32 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"os"
+)
+
+const (
+	// WatchNamespaceEnv restricts the operator to the namespace, it is set by OLM to the target
+	// namespace of the OperatorGroup in the OwnNamespace install mode
+	WatchNamespaceEnv = "WATCH_NAMESPACE"
+)
+
+// GetWatchNamespace returns the namespace which the operator is restricted to, it is empty if the
+// operator watches all the namespaces
+func GetWatchNamespace() string {
+	watchNamespace, found := os.LookupEnv(WatchNamespaceEnv)
+	if !found {
+		return ""
+	}
+	return watchNamespace
+}
+
+// IsNamespaceScoped returns true if the operator and the hub components are installed into a
+// single namespace with the namespaced RBAC. The resources across the namespaces and the
+// cluster-scoped resources are not managed in this mode, e.g. the observability addon of the
+// managed clusters, the priority classes and the webhook configurations.
+func IsNamespaceScoped() bool {
+	return GetWatchNamespace() != ""
+}
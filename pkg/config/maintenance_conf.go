@@ -0,0 +1,219 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	// MaxMaintenanceWindowDuration is the longest duration of a recurring maintenance window
+	MaxMaintenanceWindowDuration = 7 * 24 * time.Hour
+	// the next start of a recurring maintenance window is searched in the horizon, it covers the
+	// weekly schedules
+	maintenanceWindowHorizon = 8 * 24 * time.Hour
+)
+
+// cronSchedule is a schedule in the cron format, each field is the set of the matched values
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays map[int]bool
+	// the day is matched by either the day of the month or the day of the week if both are restricted
+	anyDay, anyWeekday bool
+}
+
+// parseCronField parses a field of the cron format, e.g. *, 5, 1-5, */15 or 0,30
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	values := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+			part = part[:i]
+		}
+		from, to := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			from, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", part)
+			}
+			to = from
+			if len(bounds) == 2 {
+				to, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return nil, fmt.Errorf("invalid value %q", part)
+				}
+			} else if step > 1 {
+				to = max
+			}
+		}
+		if from < min || to > max || from > to {
+			return nil, fmt.Errorf("%q is out of the range %d-%d", part, min, max)
+		}
+		for v := from; v <= to; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+// parseCronSchedule parses the schedule in the cron format with 5 fields: minute, hour, day of
+// the month, month and day of the week
+func parseCronSchedule(schedule string) (*cronSchedule, error) {
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("the schedule %q does not have 5 fields", schedule)
+	}
+	s := &cronSchedule{anyDay: fields[2] == "*", anyWeekday: fields[4] == "*"}
+	var err error
+	for _, f := range []struct {
+		values   *map[int]bool
+		field    string
+		min, max int
+	}{
+		{&s.minutes, fields[0], 0, 59},
+		{&s.hours, fields[1], 0, 23},
+		{&s.days, fields[2], 1, 31},
+		{&s.months, fields[3], 1, 12},
+		{&s.weekdays, fields[4], 0, 7},
+	} {
+		*f.values, err = parseCronField(f.field, f.min, f.max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %v", schedule, err)
+		}
+	}
+	// both 0 and 7 are sunday
+	if s.weekdays[7] {
+		s.weekdays[0] = true
+	}
+	return s, nil
+}
+
+// matches returns true if the schedule starts at the minute of the time
+func (s *cronSchedule) matches(t time.Time) bool {
+	if !s.minutes[t.Minute()] || !s.hours[t.Hour()] || !s.months[int(t.Month())] {
+		return false
+	}
+	day, weekday := s.days[t.Day()], s.weekdays[int(t.Weekday())]
+	if !s.anyDay && !s.anyWeekday {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+// ValidateMaintenanceWindow validates the maintenance window, it is either recurring with the
+// schedule and the duration, or one-off with the start and the end
+func ValidateMaintenanceWindow(w mcov1beta2.MaintenanceWindow) error {
+	if len(w.Clusters) == 0 && len(w.ClusterSets) == 0 {
+		return fmt.Errorf("no clusters or clusterSets in the maintenance window %s", w.Name)
+	}
+	if w.Schedule != "" {
+		if w.Start != nil || w.End != nil {
+			return fmt.Errorf("only one of schedule and start can be set in the maintenance window %s", w.Name)
+		}
+		if w.Duration == nil || w.Duration.Duration <= 0 || w.Duration.Duration > MaxMaintenanceWindowDuration {
+			return fmt.Errorf("the duration of the maintenance window %s must be between 0 and %s",
+				w.Name, MaxMaintenanceWindowDuration)
+		}
+		_, err := parseCronSchedule(w.Schedule)
+		return err
+	}
+	if w.Start == nil || w.End == nil {
+		return fmt.Errorf("either schedule or start and end are required in the maintenance window %s", w.Name)
+	}
+	if !w.End.After(w.Start.Time) {
+		return fmt.Errorf("the end of the maintenance window %s is not after its start", w.Name)
+	}
+	return nil
+}
+
+// GetMaintenanceWindowEnd returns the end of the maintenance window and true if the window is
+// active at the time. The invalid window is never active.
+func GetMaintenanceWindowEnd(w mcov1beta2.MaintenanceWindow, now time.Time) (time.Time, bool) {
+	if ValidateMaintenanceWindow(w) != nil {
+		return time.Time{}, false
+	}
+	if w.Schedule == "" {
+		if !now.Before(w.Start.Time) && now.Before(w.End.Time) {
+			return w.End.Time, true
+		}
+		return time.Time{}, false
+	}
+	schedule, _ := parseCronSchedule(w.Schedule)
+	// the latest start in the duration ends last if the recurring windows overlap
+	for start := now.UTC().Truncate(time.Minute); now.Sub(start) < w.Duration.Duration; start = start.Add(-time.Minute) {
+		if schedule.matches(start) {
+			return start.Add(w.Duration.Duration), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// getMaintenanceWindowNextStart returns the next start of the maintenance window after the time,
+// or the zero time if the window does not start again in the horizon
+func getMaintenanceWindowNextStart(w mcov1beta2.MaintenanceWindow, now time.Time) time.Time {
+	if ValidateMaintenanceWindow(w) != nil {
+		return time.Time{}
+	}
+	if w.Schedule == "" {
+		if now.Before(w.Start.Time) {
+			return w.Start.Time
+		}
+		return time.Time{}
+	}
+	schedule, _ := parseCronSchedule(w.Schedule)
+	for start := now.UTC().Truncate(time.Minute).Add(time.Minute); start.Sub(now) <= maintenanceWindowHorizon; start = start.Add(time.Minute) {
+		if schedule.matches(start) {
+			return start
+		}
+	}
+	return time.Time{}
+}
+
+// NextMaintenanceWindowChange returns the duration until the next maintenance window starts or
+// the next active window ends, or 0 if there is no maintenance window to start or end
+func NextMaintenanceWindowChange(windows []mcov1beta2.MaintenanceWindow, now time.Time) time.Duration {
+	next := time.Duration(0)
+	for _, w := range windows {
+		end, _ := GetMaintenanceWindowEnd(w, now)
+		for _, t := range []time.Time{getMaintenanceWindowNextStart(w, now), end} {
+			if t.IsZero() || !t.After(now) {
+				continue
+			}
+			if d := t.Sub(now); next == 0 || d < next {
+				next = d
+			}
+		}
+	}
+	return next
+}
+
+// IsClusterInMaintenanceWindow returns true if the managed cluster, or the ManagedClusterSet which
+// it belongs to, is in the maintenance window
+func IsClusterInMaintenanceWindow(w mcov1beta2.MaintenanceWindow, cluster, clusterSet string) bool {
+	for _, name := range w.Clusters {
+		if name == cluster {
+			return true
+		}
+	}
+	if clusterSet == "" {
+		return false
+	}
+	for _, name := range w.ClusterSets {
+		if name == clusterSet {
+			return true
+		}
+	}
+	return false
+}
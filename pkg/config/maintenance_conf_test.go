@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	caseList := []struct {
+		schedule string
+		time     time.Time
+		matches  bool
+	}{
+		// 02:00 on every saturday, 2021-05-01 is a saturday
+		{"0 2 * * 6", time.Date(2021, 5, 1, 2, 0, 0, 0, time.UTC), true},
+		{"0 2 * * 6", time.Date(2021, 5, 2, 2, 0, 0, 0, time.UTC), false},
+		{"*/15 * * * *", time.Date(2021, 5, 2, 7, 45, 0, 0, time.UTC), true},
+		{"*/15 * * * *", time.Date(2021, 5, 2, 7, 50, 0, 0, time.UTC), false},
+		{"0 22 * * 1-5", time.Date(2021, 5, 3, 22, 0, 0, 0, time.UTC), true},
+		{"0 0 * * 7", time.Date(2021, 5, 2, 0, 0, 0, 0, time.UTC), true},
+		// either the day of the month or the day of the week is matched if both are restricted
+		{"0 0 15 * 6", time.Date(2021, 5, 15, 0, 0, 0, 0, time.UTC), true},
+		{"0 0 15 * 6", time.Date(2021, 5, 8, 0, 0, 0, 0, time.UTC), true},
+		{"0 0 15 * 6", time.Date(2021, 5, 9, 0, 0, 0, 0, time.UTC), false},
+	}
+	for _, c := range caseList {
+		s, err := parseCronSchedule(c.schedule)
+		if err != nil {
+			t.Fatalf("Failed to parse the schedule %q: (%v)", c.schedule, err)
+		}
+		if s.matches(c.time) != c.matches {
+			t.Errorf("The schedule %q should match %s: %v", c.schedule, c.time, c.matches)
+		}
+	}
+
+	for _, schedule := range []string{"0 2 * *", "60 * * * *", "* * * * 8", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		if _, err := parseCronSchedule(schedule); err == nil {
+			t.Errorf("The schedule %q should be invalid", schedule)
+		}
+	}
+}
+
+func TestMaintenanceWindow(t *testing.T) {
+	now := time.Date(2021, 5, 1, 3, 30, 0, 0, time.UTC)
+	recurring := mcov1beta2.MaintenanceWindow{
+		Name:     "weekly",
+		Clusters: []string{"cluster1"},
+		Schedule: "0 2 * * 6",
+		Duration: &metav1.Duration{Duration: 2 * time.Hour},
+	}
+	oneOff := mcov1beta2.MaintenanceWindow{
+		Name:        "upgrade",
+		ClusterSets: []string{"prod"},
+		Start:       &metav1.Time{Time: now.Add(time.Hour)},
+		End:         &metav1.Time{Time: now.Add(3 * time.Hour)},
+	}
+
+	end, active := GetMaintenanceWindowEnd(recurring, now)
+	if !active || !end.Equal(time.Date(2021, 5, 1, 4, 0, 0, 0, time.UTC)) {
+		t.Errorf("The recurring window should be active until 04:00: %s %v", end, active)
+	}
+	if _, active := GetMaintenanceWindowEnd(recurring, now.Add(time.Hour)); active {
+		t.Errorf("The recurring window should end after its duration")
+	}
+	if _, active := GetMaintenanceWindowEnd(oneOff, now); active {
+		t.Errorf("The one-off window should not be active before its start")
+	}
+	if end, active := GetMaintenanceWindowEnd(oneOff, now.Add(2*time.Hour)); !active || !end.Equal(oneOff.End.Time) {
+		t.Errorf("The one-off window should be active until its end: %s %v", end, active)
+	}
+
+	windows := []mcov1beta2.MaintenanceWindow{recurring, oneOff}
+	if next := NextMaintenanceWindowChange(windows, now); next != 30*time.Minute {
+		t.Errorf("The recurring window should end in 30 minutes: %s", next)
+	}
+	if next := NextMaintenanceWindowChange(windows, now.Add(time.Hour)); next != 2*time.Hour {
+		t.Errorf("The one-off window should end in 2 hours: %s", next)
+	}
+	if next := NextMaintenanceWindowChange(windows, now.Add(4*time.Hour)); next != 7*24*time.Hour-5*time.Hour-30*time.Minute {
+		t.Errorf("The recurring window should start again next saturday: %s", next)
+	}
+
+	if !IsClusterInMaintenanceWindow(oneOff, "cluster2", "prod") || IsClusterInMaintenanceWindow(oneOff, "cluster1", "") {
+		t.Errorf("The clusters of the cluster set should be in the window")
+	}
+
+	invalid := []mcov1beta2.MaintenanceWindow{
+		{Name: "no-clusters", Schedule: "0 2 * * 6", Duration: &metav1.Duration{Duration: time.Hour}},
+		{Name: "no-duration", Clusters: []string{"cluster1"}, Schedule: "0 2 * * 6"},
+		{Name: "too-long", Clusters: []string{"cluster1"}, Schedule: "0 2 * * 6",
+			Duration: &metav1.Duration{Duration: 8 * 24 * time.Hour}},
+		{Name: "both", Clusters: []string{"cluster1"}, Schedule: "0 2 * * 6",
+			Duration: &metav1.Duration{Duration: time.Hour}, Start: oneOff.Start},
+		{Name: "no-end", Clusters: []string{"cluster1"}, Start: oneOff.Start},
+		{Name: "reversed", Clusters: []string{"cluster1"}, Start: oneOff.End, End: oneOff.Start},
+	}
+	for _, w := range invalid {
+		if ValidateMaintenanceWindow(w) == nil {
+			t.Errorf("The maintenance window %s should be invalid", w.Name)
+		}
+	}
+}
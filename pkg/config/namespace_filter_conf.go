@@ -0,0 +1,24 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+)
+
+// ValidateNamespaceFilter returns an error if any namespace in the filter is not a valid regular expression
+func ValidateNamespaceFilter(filter *mcoshared.NamespaceFilterSpec) error {
+	if filter == nil {
+		return nil
+	}
+	for _, ns := range append(append([]string{}, filter.Include...), filter.Exclude...) {
+		if _, err := regexp.Compile("^(?:" + ns + ")$"); err != nil {
+			return fmt.Errorf("the namespace %q of the namespaceFilter is not a valid regular expression: %v", ns, err)
+		}
+	}
+	return nil
+}
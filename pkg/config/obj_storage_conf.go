@@ -8,6 +8,15 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v2"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	// GeneratedObjStorageSecretName is the name of the secret of the object storage configuration
+	// which the operator generates from the metricObjectStorage secret for the thanos components
+	GeneratedObjStorageSecretName = "thanos-object-storage-generated"
+	GeneratedObjStorageKey        = "thanos.yaml"
 )
 
 // Config is for s3/azure/gcs compatiable configuration
@@ -53,3 +62,33 @@ func CheckObjStorageConf(data []byte) (bool, error) {
 		return false, errors.New("invalid object storage type config")
 	}
 }
+
+// IsObjStorageConfGenerated returns true if the thanos components use the object storage
+// configuration which is generated from the metricObjectStorage secret
+func IsObjStorageConfGenerated(mco *mcov1beta2.MultiClusterObservability) bool {
+	return IsWorkloadIdentityEnabled(mco) || IsObjStorageEncryptionEnabled(mco)
+}
+
+// GenerateObjStorageConf validates the object storage configuration and returns the configuration
+// with the workload identity and the server-side encryption of the storage config
+func GenerateObjStorageConf(data []byte, storageConfig *mcov1beta2.StorageConfig) ([]byte, error) {
+	var err error
+	if storageConfig.WorkloadIdentity != nil {
+		data, err = GenerateWorkloadIdentityObjStorageConf(data, storageConfig.WorkloadIdentity)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ok, err := CheckObjStorageConf(data)
+		if !ok {
+			return nil, err
+		}
+	}
+	if storageConfig.Encryption != nil {
+		data, err = GenerateEncryptionObjStorageConf(data, storageConfig.Encryption)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	clusterv1alpha1 "github.com/open-cluster-management/api/cluster/v1alpha1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	PlacementKindPlacementRule = "PlacementRule"
+	PlacementKindPlacement     = "Placement"
+
+	PlacementDecisionKind     = "PlacementDecision"
+	PlacementDecisionListKind = "PlacementDecisionList"
+	PlacementDecisionCrdName  = "placementdecisions.cluster.open-cluster-management.io"
+	PlacementDecisionLabelKey = "cluster.open-cluster-management.io/placement"
+
+	// InstallStrategyPlacements is the type of the install strategy of the ClusterManagementAddOn
+	// which installs the addon on the managed clusters selected by the placements in the strategy
+	InstallStrategyPlacements = "Placements"
+)
+
+// PlacementAPIVersion is the version of the Placement APIs of OCM
+var PlacementAPIVersion = clusterv1alpha1.GroupVersion.String()
+
+// IsPlacementRefEnabled returns true if the managed clusters are selected by a user-managed
+// placement instead of the default PlacementRule
+func IsPlacementRefEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.PlacementRef != nil && mco.Spec.PlacementRef.Name != ""
+}
+
+// GetPlacementRefKind returns the kind of the placement which selects the managed clusters
+func GetPlacementRefKind(mco *mcov1beta2.MultiClusterObservability) string {
+	if !IsPlacementRefEnabled(mco) || mco.Spec.PlacementRef.Kind == "" {
+		return PlacementKindPlacementRule
+	}
+	return mco.Spec.PlacementRef.Kind
+}
+
+// GetPlacementRefName returns the name of the placement which selects the managed clusters,
+// it is the default PlacementRule which the operator creates if no placement is referenced
+func GetPlacementRefName(mco *mcov1beta2.MultiClusterObservability) string {
+	if !IsPlacementRefEnabled(mco) {
+		return GetPlacementRuleName()
+	}
+	return mco.Spec.PlacementRef.Name
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	HubPriorityClassName      = "observability-hub-critical"
+	AddonPriorityClassName    = "observability-addon-critical"
+	DefaultPriorityClassValue = 1000000
+)
+
+// getHubPriorityClassSpec returns the spec of the priority class of the components on the hub
+func getHubPriorityClassSpec(mco *mcov1beta2.MultiClusterObservability) *mcov1beta2.PriorityClassSpec {
+	if mco.Spec.PriorityClasses == nil {
+		return nil
+	}
+	return mco.Spec.PriorityClasses.Hub
+}
+
+// getAddonPriorityClassSpec returns the spec of the priority class of the addon on the managed clusters
+func getAddonPriorityClassSpec(mco *mcov1beta2.MultiClusterObservability) *mcov1beta2.PriorityClassSpec {
+	if mco.Spec.PriorityClasses == nil {
+		return nil
+	}
+	return mco.Spec.PriorityClasses.Addon
+}
+
+// GetHubPriorityClassName returns the name of the priority class of the deployments and
+// statefulsets on the hub, it is empty if no priority class is assigned. Only an existing
+// priority class is assigned in the namespace-scoped install mode.
+func GetHubPriorityClassName(mco *mcov1beta2.MultiClusterObservability) string {
+	spec := getHubPriorityClassSpec(mco)
+	if IsNamespaceScoped() && (spec == nil || spec.Name == "") {
+		// the priority class cannot be created with the namespaced RBAC
+		return ""
+	}
+	return getPriorityClassName(spec, HubPriorityClassName)
+}
+
+// GetAddonPriorityClassName returns the name of the priority class of the addon on the managed
+// clusters, it is empty if no priority class is assigned
+func GetAddonPriorityClassName(mco *mcov1beta2.MultiClusterObservability) string {
+	return getPriorityClassName(getAddonPriorityClassSpec(mco), AddonPriorityClassName)
+}
+
+func getPriorityClassName(spec *mcov1beta2.PriorityClassSpec, defaultName string) string {
+	if spec == nil {
+		return ""
+	}
+	if spec.Name != "" {
+		return spec.Name
+	}
+	return defaultName
+}
+
+// NewHubPriorityClass returns the priority class of the components on the hub which the operator
+// manages, it is nil if no priority class is assigned or an existing one is used
+func NewHubPriorityClass(mco *mcov1beta2.MultiClusterObservability) *schedulingv1.PriorityClass {
+	return newPriorityClass(getHubPriorityClassSpec(mco), HubPriorityClassName,
+		"The priority of the observability components on the hub.")
+}
+
+// NewAddonPriorityClass returns the priority class of the addon which the operator pushes to the
+// managed clusters, it is nil if no priority class is assigned or an existing one is used
+func NewAddonPriorityClass(mco *mcov1beta2.MultiClusterObservability) *schedulingv1.PriorityClass {
+	return newPriorityClass(getAddonPriorityClassSpec(mco), AddonPriorityClassName,
+		"The priority of the observability addon on the managed cluster.")
+}
+
+func newPriorityClass(spec *mcov1beta2.PriorityClassSpec, name, description string) *schedulingv1.PriorityClass {
+	if spec == nil || spec.Name != "" {
+		return nil
+	}
+	value := spec.Value
+	if value == 0 {
+		value = DefaultPriorityClassValue
+	}
+	return &schedulingv1.PriorityClass{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: schedulingv1.SchemeGroupVersion.String(),
+			Kind:       "PriorityClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Value:       value,
+		Description: description,
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetProxyHost returns the host of the proxy endpoint without the scheme, the port and the path,
+// which is the SAN the server certificate is issued for
+func GetProxyHost(endpoint string) string {
+	host := strings.TrimSpace(endpoint)
+	if idx := strings.Index(host, "://"); idx >= 0 {
+		host = host[idx+3:]
+	}
+	if idx := strings.Index(host, "/"); idx >= 0 {
+		host = host[:idx]
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.Trim(host, "[]")
+}
+
+// GetServerCertProxyHosts returns the hosts of the cluster proxies which the server certificate
+// in the secret is issued for
+func GetServerCertProxyHosts(secret *corev1.Secret) []string {
+	value := secret.GetAnnotations()[ObsAPIProxyEndpointsAnnotation]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// IsProxyEndpointCovered returns true if the server certificate of the observatorium api is issued
+// for the host of the proxy endpoint
+func IsProxyEndpointCovered(c client.Client, namespace string, endpoint string) (bool, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: ServerCerts, Namespace: namespace}, secret)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	host := GetProxyHost(endpoint)
+	for _, covered := range GetServerCertProxyHosts(secret) {
+		if covered == host {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"context"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+// QueryEndpointRouteName is the name of the route of the query endpoint for the external systems
+const QueryEndpointRouteName = "observability-query"
+
+// IsQueryEndpointEnabled returns true if the query endpoint of the fleet is exposed for the external systems
+func IsQueryEndpointEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.QueryEndpoint != nil && mco.Spec.QueryEndpoint.Enabled
+}
+
+// GetQueryEndpointHost returns the host of the route of the query endpoint
+func GetQueryEndpointHost(c client.Client, namespace string) (string, error) {
+	found := &routev1.Route{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: QueryEndpointRouteName, Namespace: namespace}, found)
+	if err != nil {
+		return "", err
+	}
+	return found.Spec.Host, nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	// DefaultTelemetryInterval is the default interval of the usage and health reports
+	DefaultTelemetryInterval = 24 * time.Hour
+	// the reports are not posted more often than the minimum interval
+	minTelemetryInterval = 10 * time.Minute
+)
+
+// IsTelemetryEnabled returns true if the usage and health reports are posted, they are opt-in
+func IsTelemetryEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.Telemetry != nil && mco.Spec.Telemetry.Enabled
+}
+
+// GetTelemetryInterval returns the interval of the usage and health reports
+func GetTelemetryInterval(mco *mcov1beta2.MultiClusterObservability) time.Duration {
+	if mco.Spec.Telemetry == nil || mco.Spec.Telemetry.Interval == "" {
+		return DefaultTelemetryInterval
+	}
+	interval, err := time.ParseDuration(mco.Spec.Telemetry.Interval)
+	if err != nil {
+		return DefaultTelemetryInterval
+	}
+	if interval < minTelemetryInterval {
+		return minTelemetryInterval
+	}
+	return interval
+}
+
+// ValidateTelemetry returns an error if the usage and health reports are enabled without a valid endpoint
+func ValidateTelemetry(mco *mcov1beta2.MultiClusterObservability) error {
+	if !IsTelemetryEnabled(mco) {
+		return nil
+	}
+	endpoint := mco.Spec.Telemetry.Endpoint
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("the endpoint of the telemetry %q is not a valid http(s) URL", endpoint)
+	}
+	if interval := mco.Spec.Telemetry.Interval; interval != "" {
+		if _, err := time.ParseDuration(interval); err != nil {
+			return fmt.Errorf("the interval of the telemetry %q is invalid: %v", interval, err)
+		}
+	}
+	return nil
+}
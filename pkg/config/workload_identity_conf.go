@@ -0,0 +1,128 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+const (
+	WorkloadIdentityAWS   = "AWS"
+	WorkloadIdentityGCP   = "GCP"
+	WorkloadIdentityAzure = "Azure"
+
+	awsRoleARNAnnotation           = "eks.amazonaws.com/role-arn"
+	gcpServiceAccountAnnotation    = "iam.gke.io/gcp-service-account"
+	azureClientIDAnnotation        = "azure.workload.identity/client-id"
+	azureUserAssignedIDConfigField = "user_assigned_id"
+)
+
+// the static keys which are replaced by the short-lived credentials of the workload identity
+var workloadIdentityStaticKeys = map[string][]string{
+	WorkloadIdentityAWS:   {"access_key", "secret_key"},
+	WorkloadIdentityGCP:   {"service_account"},
+	WorkloadIdentityAzure: {"storage_account_key"},
+}
+
+// the type of the object storage of each provider of the workload identity
+var workloadIdentityStorageTypes = map[string]string{
+	WorkloadIdentityAWS:   "s3",
+	WorkloadIdentityGCP:   "gcs",
+	WorkloadIdentityAzure: "azure",
+}
+
+// IsWorkloadIdentityEnabled returns true if the thanos components access the object storage with
+// the short-lived cloud credentials instead of the static keys
+func IsWorkloadIdentityEnabled(mco *mcov1beta2.MultiClusterObservability) bool {
+	return mco.Spec.StorageConfig != nil && mco.Spec.StorageConfig.WorkloadIdentity != nil
+}
+
+// GetWorkloadIdentityAnnotations returns the annotations of the service accounts of the thanos
+// components which bind them to the cloud identity
+func GetWorkloadIdentityAnnotations(identity *mcov1beta2.WorkloadIdentitySpec) map[string]string {
+	switch identity.Provider {
+	case WorkloadIdentityAWS:
+		return map[string]string{awsRoleARNAnnotation: identity.RoleARN}
+	case WorkloadIdentityGCP:
+		return map[string]string{gcpServiceAccountAnnotation: identity.GCPServiceAccount}
+	case WorkloadIdentityAzure:
+		return map[string]string{azureClientIDAnnotation: identity.ClientID}
+	}
+	return nil
+}
+
+func validateWorkloadIdentity(identity *mcov1beta2.WorkloadIdentitySpec) error {
+	switch identity.Provider {
+	case WorkloadIdentityAWS:
+		if identity.RoleARN == "" {
+			return errors.New("no roleARN in the workload identity of AWS")
+		}
+	case WorkloadIdentityGCP:
+		if identity.GCPServiceAccount == "" {
+			return errors.New("no gcpServiceAccount in the workload identity of GCP")
+		}
+	case WorkloadIdentityAzure:
+		if identity.ClientID == "" {
+			return errors.New("no clientID in the workload identity of Azure")
+		}
+	default:
+		return fmt.Errorf("invalid provider %s of the workload identity", identity.Provider)
+	}
+	return nil
+}
+
+// GenerateWorkloadIdentityObjStorageConf validates the object storage configuration without the
+// static keys and returns the configuration for the workload identity. The static keys are
+// removed so that thanos falls back to the credentials of the cloud identity of its service account.
+func GenerateWorkloadIdentityObjStorageConf(data []byte,
+	identity *mcov1beta2.WorkloadIdentitySpec) ([]byte, error) {
+	err := validateWorkloadIdentity(identity)
+	if err != nil {
+		return nil, err
+	}
+	var objectConfg ObjectStorgeConf
+	err = yaml.Unmarshal(data, &objectConfg)
+	if err != nil {
+		return nil, err
+	}
+	storageType := workloadIdentityStorageTypes[identity.Provider]
+	if strings.ToLower(objectConfg.Type) != storageType {
+		return nil, fmt.Errorf("invalid type config, only %s type is supported by the workload identity of %s",
+			storageType, identity.Provider)
+	}
+	conf := objectConfg.Config
+	if conf.Bucket == "" && storageType != "azure" {
+		return nil, fmt.Errorf("no %s bucket in config file", storageType)
+	}
+	if storageType == "s3" && conf.Endpoint == "" {
+		return nil, errors.New("no s3 endpoint in config file")
+	}
+	if storageType == "azure" && (conf.StorageAccount == "" || conf.Container == "") {
+		return nil, errors.New("no storage_account or container as azure storage in config file")
+	}
+
+	// keep the other fields of the configuration as they are
+	generated := map[string]interface{}{}
+	err = yaml.Unmarshal(data, &generated)
+	if err != nil {
+		return nil, err
+	}
+	config, ok := generated["config"].(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("no config in the object storage configuration")
+	}
+	for _, key := range workloadIdentityStaticKeys[identity.Provider] {
+		delete(config, key)
+	}
+	if identity.Provider == WorkloadIdentityAzure {
+		config[azureUserAssignedIDConfigField] = identity.ClientID
+	}
+	return yaml.Marshal(generated)
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+)
+
+func TestGenerateWorkloadIdentityObjStorageConf(t *testing.T) {
+	caseList := []struct {
+		conf     []byte
+		identity *mcov1beta2.WorkloadIdentitySpec
+		name     string
+		expected map[string]interface{}
+	}{
+		{
+			conf: []byte(`type: s3
+config:
+  bucket: bucket
+  endpoint: s3.us-east-1.amazonaws.com
+  region: us-east-1
+  access_key: access_key
+  secret_key: secret_key`),
+			identity: &mcov1beta2.WorkloadIdentitySpec{Provider: "AWS", RoleARN: "arn:aws:iam::123:role/thanos"},
+			name:     "s3 with irsa",
+			expected: map[string]interface{}{
+				"bucket":   "bucket",
+				"endpoint": "s3.us-east-1.amazonaws.com",
+				"region":   "us-east-1",
+			},
+		},
+		{
+			conf: []byte(`type: gcs
+config:
+  bucket: bucket`),
+			identity: &mcov1beta2.WorkloadIdentitySpec{Provider: "GCP", GCPServiceAccount: "thanos@p.iam.gserviceaccount.com"},
+			name:     "gcs with workload identity",
+			expected: map[string]interface{}{"bucket": "bucket"},
+		},
+		{
+			conf: []byte(`type: azure
+config:
+  storage_account: account
+  storage_account_key: key
+  container: container`),
+			identity: &mcov1beta2.WorkloadIdentitySpec{Provider: "Azure", ClientID: "client-id"},
+			name:     "azure with workload identity",
+			expected: map[string]interface{}{
+				"storage_account":  "account",
+				"container":        "container",
+				"user_assigned_id": "client-id",
+			},
+		},
+		{
+			conf: []byte(`type: gcs
+config:
+  bucket: bucket`),
+			identity: &mcov1beta2.WorkloadIdentitySpec{Provider: "AWS", RoleARN: "arn:aws:iam::123:role/thanos"},
+			name:     "mismatched provider",
+		},
+		{
+			conf: []byte(`type: s3
+config:
+  bucket: bucket
+  endpoint: s3.us-east-1.amazonaws.com`),
+			identity: &mcov1beta2.WorkloadIdentitySpec{Provider: "AWS"},
+			name:     "no role arn",
+		},
+		{
+			conf: []byte(`type: s3
+config:
+  endpoint: s3.us-east-1.amazonaws.com`),
+			identity: &mcov1beta2.WorkloadIdentitySpec{Provider: "AWS", RoleARN: "arn:aws:iam::123:role/thanos"},
+			name:     "no bucket",
+		},
+	}
+
+	for _, c := range caseList {
+		data, err := GenerateWorkloadIdentityObjStorageConf(c.conf, c.identity)
+		if c.expected == nil {
+			if err == nil {
+				t.Errorf("case (%v) should be invalid", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case (%v) failed to generate the configuration: (%v)", c.name, err)
+			continue
+		}
+		generated := struct {
+			Type   string                 `yaml:"type"`
+			Config map[string]interface{} `yaml:"config"`
+		}{}
+		err = yaml.Unmarshal(data, &generated)
+		if err != nil {
+			t.Errorf("case (%v) failed to unmarshal the configuration: (%v)", c.name, err)
+			continue
+		}
+		if len(generated.Config) != len(c.expected) {
+			t.Errorf("case (%v) output: %v is not the expected: %v", c.name, generated.Config, c.expected)
+			continue
+		}
+		for key, value := range c.expected {
+			if generated.Config[key] != value {
+				t.Errorf("case (%v) output: %v is not the expected: %v", c.name, generated.Config, c.expected)
+			}
+		}
+	}
+}
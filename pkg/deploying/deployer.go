@@ -43,12 +43,15 @@ func NewDeployer(client client.Client) *Deployer {
 		"Secret":             deployer.updateSecret,
 		"ClusterRole":        deployer.updateClusterRole,
 		"ClusterRoleBinding": deployer.updateClusterRoleBinding,
+		"Role":               deployer.updateRole,
+		"RoleBinding":        deployer.updateRoleBinding,
 	}
 	return deployer
 }
 
 // Deploy is used to create or update the resources
 func (d *Deployer) Deploy(obj *unstructured.Unstructured) error {
+	setDesiredHash(obj)
 	found := &unstructured.Unstructured{}
 	found.SetGroupVersionKind(obj.GroupVersionKind())
 	err := d.client.Get(context.TODO(), types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}, found)
@@ -94,8 +97,12 @@ func (d *Deployer) updateDeployment(desiredObj, runtimeObj *unstructured.Unstruc
 		log.Error(err, fmt.Sprintf("Failed to Unmarshal Deployment %s", runtimeObj.GetName()))
 	}
 
-	if !apiequality.Semantic.DeepDerivative(desiredDepoly.Spec, runtimeDepoly.Spec) {
+	// the unset priority class is ignored by the derivative comparison
+	if !apiequality.Semantic.DeepDerivative(desiredDepoly.Spec, runtimeDepoly.Spec) ||
+		desiredDepoly.Spec.Template.Spec.PriorityClassName != runtimeDepoly.Spec.Template.Spec.PriorityClassName ||
+		isHubPatchesChanged(desiredObj, runtimeObj) {
 		log.Info("Update", "Kind:", runtimeObj.GroupVersionKind(), "Name:", runtimeObj.GetName())
+		checkDrift(desiredObj, runtimeObj)
 		return d.client.Update(context.TODO(), desiredDepoly)
 	}
 
@@ -118,10 +125,22 @@ func (d *Deployer) updateStatefulSet(desiredObj, runtimeObj *unstructured.Unstru
 	}
 
 	if !apiequality.Semantic.DeepDerivative(desiredDepoly.Spec.Template, runtimeDepoly.Spec.Template) ||
-		!apiequality.Semantic.DeepDerivative(desiredDepoly.Spec.Replicas, runtimeDepoly.Spec.Replicas) {
+		!apiequality.Semantic.DeepDerivative(desiredDepoly.Spec.Replicas, runtimeDepoly.Spec.Replicas) ||
+		desiredDepoly.Spec.Template.Spec.PriorityClassName != runtimeDepoly.Spec.Template.Spec.PriorityClassName ||
+		isHubPatchesChanged(desiredObj, runtimeObj) {
 		log.Info("Update", "Kind:", runtimeObj.GroupVersionKind(), "Name:", runtimeObj.GetName())
+		checkDrift(desiredObj, runtimeObj)
 		runtimeDepoly.Spec.Replicas = desiredDepoly.Spec.Replicas
 		runtimeDepoly.Spec.Template = desiredDepoly.Spec.Template
+		if isHubPatchesChanged(desiredObj, runtimeObj) {
+			// the user-supplied patches can change the metadata
+			runtimeDepoly.Labels = desiredDepoly.Labels
+			runtimeDepoly.Annotations = desiredDepoly.Annotations
+		}
+		if runtimeDepoly.Annotations == nil {
+			runtimeDepoly.Annotations = map[string]string{}
+		}
+		runtimeDepoly.Annotations[config.DesiredHashAnnotation] = desiredObj.GetAnnotations()[config.DesiredHashAnnotation]
 		return d.client.Update(context.TODO(), runtimeDepoly)
 	}
 
@@ -145,6 +164,7 @@ func (d *Deployer) updateService(desiredObj, runtimeObj *unstructured.Unstructur
 
 	if !apiequality.Semantic.DeepDerivative(desiredService.Spec, runtimeService.Spec) {
 		log.Info("Update", "Kind:", runtimeObj.GroupVersionKind(), "Name:", runtimeObj.GetName())
+		checkDrift(desiredObj, runtimeObj)
 		return d.client.Update(context.TODO(), desiredService)
 	}
 
@@ -168,6 +188,7 @@ func (d *Deployer) updateConfigMap(desiredObj, runtimeObj *unstructured.Unstruct
 
 	if !apiequality.Semantic.DeepDerivative(desiredConfigMap.Data, runtimeConfigMap.Data) {
 		log.Info("Update", "Kind:", runtimeObj.GroupVersionKind(), "Name:", runtimeObj.GetName())
+		checkDrift(desiredObj, runtimeObj)
 		return d.client.Update(context.TODO(), desiredConfigMap)
 	}
 
@@ -209,3 +230,64 @@ func (d *Deployer) updateClusterRoleBinding(desiredObj, runtimeObj *unstructured
 	log.Info("Update", "Kind:", desiredObj.GroupVersionKind(), "Name:", desiredObj.GetName())
 	return d.client.Update(context.TODO(), desiredClusterRoleBinding)
 }
+
+func (d *Deployer) updateRole(desiredObj, runtimeObj *unstructured.Unstructured) error {
+	desiredJSON, _ := desiredObj.MarshalJSON()
+	desiredRole := &rbacv1.Role{}
+	err := json.Unmarshal(desiredJSON, desiredRole)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Failed to Unmarshal desired Role %s", desiredObj.GetName()))
+	}
+
+	log.Info("Update", "Kind:", desiredObj.GroupVersionKind(), "Name:", desiredObj.GetName())
+	return d.client.Update(context.TODO(), desiredRole)
+}
+
+func (d *Deployer) updateRoleBinding(desiredObj, runtimeObj *unstructured.Unstructured) error {
+	desiredJSON, _ := desiredObj.MarshalJSON()
+	desiredRoleBinding := &rbacv1.RoleBinding{}
+	err := json.Unmarshal(desiredJSON, desiredRoleBinding)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Failed to Unmarshal desired RoleBinding %s", desiredObj.GetName()))
+	}
+
+	log.Info("Update", "Kind:", desiredObj.GroupVersionKind(), "Name:", desiredObj.GetName())
+	return d.client.Update(context.TODO(), desiredRoleBinding)
+}
+
+// isHubPatchesChanged returns true if the user-supplied patches which apply to the resource are changed,
+// the patches can change the fields which are not compared to detect the update
+func isHubPatchesChanged(desiredObj, runtimeObj *unstructured.Unstructured) bool {
+	return desiredObj.GetAnnotations()[config.HubPatchesHashAnnotation] !=
+		runtimeObj.GetAnnotations()[config.HubPatchesHashAnnotation]
+}
+
+// setDesiredHash records the hash of the desired state in the resource
+func setDesiredHash(obj *unstructured.Unstructured) {
+	desired := obj.DeepCopy()
+	desired.SetResourceVersion("")
+	annotations := desired.GetAnnotations()
+	delete(annotations, config.DesiredHashAnnotation)
+	desired.SetAnnotations(annotations)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[config.DesiredHashAnnotation] = HashDesired(desired.Object)
+	obj.SetAnnotations(annotations)
+}
+
+// checkDrift records the config drift when the resource is reverted while its desired state is
+// unchanged since it is deployed last, which means that it is modified externally
+func checkDrift(desiredObj, runtimeObj *unstructured.Unstructured) {
+	hash := desiredObj.GetAnnotations()[config.DesiredHashAnnotation]
+	if hash == "" || runtimeObj.GetAnnotations()[config.DesiredHashAnnotation] != hash {
+		return
+	}
+	RecordDrift(&corev1.ObjectReference{
+		APIVersion: runtimeObj.GetAPIVersion(),
+		Kind:       runtimeObj.GetKind(),
+		Namespace:  runtimeObj.GetNamespace(),
+		Name:       runtimeObj.GetName(),
+		UID:        runtimeObj.GetUID(),
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package deploying
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// ReasonConfigDrift is the reason of the events of the reverted external modifications
+	ReasonConfigDrift = "ConfigDriftReverted"
+	// DriftRetention is how long the reverted external modifications are kept for the summary
+	DriftRetention = time.Hour
+)
+
+var (
+	configDrifts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "acm_observability_config_drift_total",
+		Help: "The number of the external modifications of the resources owned by the operator which are reverted, by resource.",
+	}, []string{"kind", "namespace", "name"})
+
+	driftMutex    sync.Mutex
+	driftRecorder record.EventRecorder
+	// the last time the external modifications of the resources are reverted
+	drifts = map[DriftedResource]time.Time{}
+)
+
+func init() {
+	metrics.Registry.MustRegister(configDrifts)
+}
+
+// DriftedResource is the resource owned by the operator which is modified externally
+type DriftedResource struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (r DriftedResource) String() string {
+	if r.Namespace == "" {
+		return r.Kind + "/" + r.Name
+	}
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// SetDriftRecorder sets the recorder of the events of the reverted external modifications
+func SetDriftRecorder(recorder record.EventRecorder) {
+	driftMutex.Lock()
+	defer driftMutex.Unlock()
+	driftRecorder = recorder
+}
+
+// HashDesired returns the hash of the desired state of the resource, which is recorded in the resource
+// to tell the external modifications from the changes of the desired state
+func HashDesired(desired interface{}) string {
+	data, _ := json.Marshal(desired)
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// RecordDrift counts the external modification of the resource which is reverted, and records the
+// warning event on the resource
+func RecordDrift(ref *corev1.ObjectReference) {
+	driftMutex.Lock()
+	defer driftMutex.Unlock()
+	resource := DriftedResource{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name}
+	drifts[resource] = time.Now()
+	configDrifts.WithLabelValues(ref.Kind, ref.Namespace, ref.Name).Inc()
+	message := fmt.Sprintf("The external modification of %s is reverted, "+
+		"another automation may manage the resource owned by the observability operator", resource)
+	log.Info("Config drift is reverted", "resource", resource.String())
+	if driftRecorder != nil {
+		driftRecorder.Event(ref, corev1.EventTypeWarning, ReasonConfigDrift, message)
+	}
+}
+
+// GetRecentDrifts returns the resources whose external modifications are reverted since the time,
+// the older ones are forgotten
+func GetRecentDrifts(since time.Time) []DriftedResource {
+	driftMutex.Lock()
+	defer driftMutex.Unlock()
+	resources := []DriftedResource{}
+	for resource, last := range drifts {
+		if last.Before(time.Now().Add(-DriftRetention)) {
+			delete(drifts, resource)
+			continue
+		}
+		if !last.Before(since) {
+			resources = append(resources, resource)
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].String() < resources[j].String()
+	})
+	return resources
+}
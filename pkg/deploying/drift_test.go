@@ -0,0 +1,78 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package deploying
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConfigDrift(t *testing.T) {
+	newCM := func(name, value string) *unstructured.Unstructured {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns1"},
+			Data:       map[string]string{"key": value},
+		})
+		if err != nil {
+			t.Fatalf("Failed to convert the configmap: (%v)", err)
+		}
+		return &unstructured.Unstructured{Object: obj}
+	}
+	isDrifted := func(name string) bool {
+		for _, r := range GetRecentDrifts(time.Now().Add(-time.Minute)) {
+			if r == (DriftedResource{Kind: "ConfigMap", Namespace: "ns1", Name: name}) {
+				return true
+			}
+		}
+		return false
+	}
+	deploy := func(c client.Client, name, value string) {
+		desired := newCM(name, value)
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "ns1"}, cm); err == nil {
+			desired.SetResourceVersion(cm.ResourceVersion)
+		}
+		if err := NewDeployer(c).Deploy(desired); err != nil {
+			t.Fatalf("Failed to deploy the configmap: (%v)", err)
+		}
+	}
+
+	c := fake.NewFakeClient()
+	deploy(c, "drifted", "v1")
+	deploy(c, "changed", "v1")
+
+	// the desired state is unchanged, the configmap is modified externally
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "drifted", Namespace: "ns1"}, cm); err != nil {
+		t.Fatalf("Failed to get the configmap: (%v)", err)
+	}
+	cm.Data["key"] = "modified"
+	if err := c.Update(context.TODO(), cm); err != nil {
+		t.Fatalf("Failed to update the configmap: (%v)", err)
+	}
+	deploy(c, "drifted", "v1")
+	if !isDrifted("drifted") {
+		t.Errorf("The external modification of the configmap should be recorded")
+	}
+
+	// the desired state is changed
+	deploy(c, "changed", "v2")
+	if isDrifted("changed") {
+		t.Errorf("The change of the desired state should not be recorded as drift")
+	}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "changed", Namespace: "ns1"}, cm); err != nil ||
+		cm.Data["key"] != "v2" {
+		t.Errorf("The configmap should be updated: %v (%v)", cm.Data, err)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package errorlog
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// FlushInterval is the interval in which the repeated errors are summarized in the log
+	FlushInterval = 5 * time.Minute
+)
+
+var (
+	log = logf.Log.WithName("errorlog")
+
+	clusterErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "acm_observability_managed_cluster_errors_total",
+		Help: "The number of the errors which the operator hits for the managed cluster, by reason.",
+	}, []string{"managed_cluster", "reason"})
+
+	mutex   sync.Mutex
+	pending = map[key]*aggregate{}
+	// the reasons which are exported in the metrics, they are removed once the cluster is detached
+	exported = map[key]bool{}
+	// the number of the errors per reason of all the clusters since they are taken last
+	reasonCounts = map[string]int{}
+)
+
+func init() {
+	metrics.Registry.MustRegister(clusterErrors)
+}
+
+// key is the reason of the error for the managed cluster, the reason is the message of the log
+type key struct {
+	cluster string
+	reason  string
+}
+
+// aggregate is the errors with the same reason for the managed cluster in the flush interval
+type aggregate struct {
+	// the number of the errors which are not logged yet
+	count int
+	// sample is the latest error
+	sample string
+	since  time.Time
+}
+
+// ClusterError logs the error of the managed cluster with the reason and the key/value pairs. The first
+// error of the reason for the cluster in the flush interval is logged at once, the later ones are only
+// counted and summarized by Flush, so that the same error does not flood the log on a big fleet.
+func ClusterError(logger logr.Logger, err error, cluster string, reason string, keysAndValues ...interface{}) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	k := key{cluster: cluster, reason: reason}
+	clusterErrors.WithLabelValues(cluster, reason).Inc()
+	exported[k] = true
+	reasonCounts[reason]++
+	if entry, ok := pending[k]; ok {
+		entry.count++
+		entry.sample = err.Error()
+		return
+	}
+	pending[k] = &aggregate{sample: err.Error(), since: time.Now()}
+	logger.Error(err, reason, append([]interface{}{"cluster", cluster}, keysAndValues...)...)
+}
+
+// Forget removes the errors of the managed cluster once it is detached
+func Forget(cluster string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	for k := range pending {
+		if k.cluster == cluster {
+			delete(pending, k)
+		}
+	}
+	for k := range exported {
+		if k.cluster == cluster {
+			clusterErrors.DeleteLabelValues(k.cluster, k.reason)
+			delete(exported, k)
+		}
+	}
+}
+
+// Flush logs the number and the latest sample of the repeated errors per reason per cluster, and starts
+// the next interval
+func Flush() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	keys := []key{}
+	for k, entry := range pending {
+		if entry.count > 0 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].cluster != keys[j].cluster {
+			return keys[i].cluster < keys[j].cluster
+		}
+		return keys[i].reason < keys[j].reason
+	})
+	for _, k := range keys {
+		entry := pending[k]
+		log.Info("Repeated errors of the managed cluster", "cluster", k.cluster, "reason", k.reason,
+			"count", entry.count, "since", entry.since.UTC().Format(time.RFC3339), "sample", entry.sample)
+	}
+	pending = map[key]*aggregate{}
+}
+
+// TakeReasonCounts returns the number of the errors per reason of all the managed clusters since
+// it is called last, the names of the clusters are not included
+func TakeReasonCounts() map[string]int {
+	mutex.Lock()
+	defer mutex.Unlock()
+	counts := reasonCounts
+	reasonCounts = map[string]int{}
+	return counts
+}
+
+// Reporter flushes the repeated errors periodically, it is added to the manager as a runnable
+type Reporter struct{}
+
+// Start flushes the repeated errors in the flush interval until the context is done
+func (r *Reporter) Start(ctx context.Context) error {
+	ticker := time.NewTicker(FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			Flush()
+		case <-ctx.Done():
+			Flush()
+			return nil
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package errorlog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// countingLogger counts the errors which are logged
+type countingLogger struct {
+	errors *int
+}
+
+func (l countingLogger) Enabled() bool                                 { return true }
+func (l countingLogger) Info(msg string, keysAndValues ...interface{}) {}
+func (l countingLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	*l.errors++
+}
+func (l countingLogger) V(level int) logr.Logger                             { return l }
+func (l countingLogger) WithValues(keysAndValues ...interface{}) logr.Logger { return l }
+func (l countingLogger) WithName(name string) logr.Logger                    { return l }
+
+func TestClusterError(t *testing.T) {
+	logged := 0
+	logger := countingLogger{errors: &logged}
+	reason := "Failed to create manifestwork"
+
+	for i := 0; i < 100; i++ {
+		ClusterError(logger, errors.New("conflict"), "cluster1", reason, "name", "cluster1-observability")
+	}
+	ClusterError(logger, errors.New("forbidden"), "cluster2", reason)
+	if logged != 2 {
+		t.Errorf("the repeated errors should be logged once per cluster: %d", logged)
+	}
+	if count := testutil.ToFloat64(clusterErrors.WithLabelValues("cluster1", reason)); count != 100 {
+		t.Errorf("all the errors should be counted in the metrics: %v", count)
+	}
+	entry := pending[key{cluster: "cluster1", reason: reason}]
+	if entry == nil || entry.count != 99 || entry.sample != "conflict" {
+		t.Errorf("the repeated errors should be aggregated: %v", entry)
+	}
+
+	// the errors are logged again in the next interval
+	Flush()
+	ClusterError(logger, errors.New("conflict"), "cluster1", reason)
+	if logged != 3 {
+		t.Errorf("the error should be logged again after the flush: %d", logged)
+	}
+
+	// the errors of the other clusters are kept
+	ClusterError(logger, errors.New("forbidden"), "cluster2", reason)
+	Forget("cluster1")
+	if len(pending) != 1 || pending[key{cluster: "cluster2", reason: reason}] == nil || exported[key{cluster: "cluster1", reason: reason}] {
+		t.Errorf("the errors of the detached cluster should be removed: %v", pending)
+	}
+	if count := testutil.CollectAndCount(clusterErrors); count != 1 {
+		t.Errorf("the metrics of the detached cluster should be removed: %d", count)
+	}
+}
@@ -0,0 +1,301 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+// Package linter validates a directory of the user configurations of the observability service offline,
+// with the same validations which the controllers run, so that the mistakes are caught in the CI of
+// the GitOps repository instead of being skipped by the operator at runtime.
+package linter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoctrl "github.com/open-cluster-management/multicluster-observability-operator/controllers/multiclusterobservability"
+	prctrl "github.com/open-cluster-management/multicluster-observability-operator/controllers/placementrule"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+)
+
+const (
+	customDashboardLabelKey = "grafana-custom-dashboard"
+)
+
+// Finding is a problem in a document of the configuration files
+type Finding struct {
+	File string
+	// Object is the kind and the name of the document
+	Object  string
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.File, f.Object, f.Message)
+}
+
+// document is a kubernetes object in the configuration files
+type document struct {
+	file string
+	meta metav1.PartialObjectMetadata
+	data []byte
+}
+
+func (d *document) object() string {
+	return d.meta.Kind + "/" + d.meta.Name
+}
+
+// Main runs the linter with the arguments of the linter command and returns the exit code
+func Main(args []string, out io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(out, "Usage: linter <directory>")
+		return 2
+	}
+	findings, err := Lint(args[0])
+	if err != nil {
+		fmt.Fprintf(out, "Failed to lint %s: %v\n", args[0], err)
+		return 2
+	}
+	for _, finding := range findings {
+		fmt.Fprintln(out, finding.String())
+	}
+	if len(findings) > 0 {
+		fmt.Fprintf(out, "%d problems found\n", len(findings))
+		return 1
+	}
+	return 0
+}
+
+// Lint validates all the yaml and json files in the directory and its subdirectories, the objects
+// which the operator does not read are ignored
+func Lint(dir string) ([]Finding, error) {
+	docs := []*document{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		fileDocs, err := readDocuments(path)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, fileDocs...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	findings := []Finding{}
+	for _, doc := range docs {
+		for _, err := range lintDocument(doc, docs) {
+			findings = append(findings, Finding{File: doc.file, Object: doc.object(), Message: err.Error()})
+		}
+	}
+	return findings, nil
+}
+
+// readDocuments splits the yaml documents in the file, a json file is a single document
+func readDocuments(path string) ([]*document, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	docs := []*document{}
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		raw, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+		doc := &document{file: path, data: raw}
+		err = yaml.Unmarshal(raw, &doc.meta)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if doc.meta.Kind == "" {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// lintDocument validates the document by its kind, the other documents are used to resolve the references
+func lintDocument(doc *document, docs []*document) []error {
+	switch doc.meta.Kind {
+	case "MultiClusterObservability":
+		mco := &mcov1beta2.MultiClusterObservability{}
+		if err := yaml.UnmarshalStrict(doc.data, mco); err != nil {
+			return []error{err}
+		}
+		return lintMCO(mco, docs)
+	case "ConfigMap":
+		cm := &corev1.ConfigMap{}
+		if err := yaml.UnmarshalStrict(doc.data, cm); err != nil {
+			return []error{err}
+		}
+		return lintConfigMap(cm)
+	case "Secret":
+		secret := &corev1.Secret{}
+		if err := yaml.UnmarshalStrict(doc.data, secret); err != nil {
+			return []error{err}
+		}
+		if secret.Name != config.AlertmanagerConfigName {
+			return nil
+		}
+		if err := mcoctrl.ValidateAlertmanagerConfig(getSecretData(secret, mcoctrl.AlertmanagerConfigKey)); err != nil {
+			return []error{fmt.Errorf("invalid %s: %v", mcoctrl.AlertmanagerConfigKey, err)}
+		}
+	case "ObservabilityMetricsAllowlist":
+		allowlist := &mcov1beta2.ObservabilityMetricsAllowlist{}
+		if err := yaml.UnmarshalStrict(doc.data, allowlist); err != nil {
+			return []error{err}
+		}
+		if err := prctrl.ValidateMetricsAllowlist(allowlist); err != nil {
+			return []error{err}
+		}
+	case "MetricsImport":
+		imp := &mcov1beta2.MetricsImport{}
+		if err := yaml.UnmarshalStrict(doc.data, imp); err != nil {
+			return []error{err}
+		}
+		if err := mcoctrl.ValidateMetricsImport(imp); err != nil {
+			return []error{err}
+		}
+	case "FleetSLO":
+		slo := &mcov1beta2.FleetSLO{}
+		if err := yaml.UnmarshalStrict(doc.data, slo); err != nil {
+			return []error{err}
+		}
+		if err := mcoctrl.ValidateFleetSLO(slo); err != nil {
+			return []error{err}
+		}
+	}
+	return nil
+}
+
+// lintMCO validates the maintenance windows of the MultiClusterObservability, and its object storage
+// configuration if its secret is in the directory, the secret is usually kept out of the GitOps repository
+func lintMCO(mco *mcov1beta2.MultiClusterObservability, docs []*document) []error {
+	errs := []error{}
+	for _, w := range mco.Spec.MaintenanceWindows {
+		if err := config.ValidateMaintenanceWindow(w); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := config.ValidateTelemetry(mco); err != nil {
+		errs = append(errs, err)
+	}
+	if err := config.ValidateExternalLabels(mco.Spec.ExternalLabels); err != nil {
+		errs = append(errs, err)
+	}
+	if err := config.ValidateFederatedHubs(mco); err != nil {
+		errs = append(errs, err)
+	}
+	if err := config.ValidateClusterAPIProbe(mco); err != nil {
+		errs = append(errs, err)
+	}
+	if err := config.ValidateBackfill(mco); err != nil {
+		errs = append(errs, err)
+	}
+	if err := config.ValidateClusterIdentity(mco); err != nil {
+		errs = append(errs, err)
+	}
+	if mco.Spec.ObservabilityAddonSpec != nil {
+		if err := config.ValidateNamespaceFilter(mco.Spec.ObservabilityAddonSpec.NamespaceFilter); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if mco.Spec.StorageConfig == nil || mco.Spec.StorageConfig.MetricObjectStorage == nil {
+		return append(errs, fmt.Errorf("the metricObjectStorage of the storageConfig is required"))
+	}
+	storage := mco.Spec.StorageConfig.MetricObjectStorage
+	for _, doc := range docs {
+		if doc.meta.Kind != "Secret" || doc.meta.Name != storage.Name {
+			continue
+		}
+		secret := &corev1.Secret{}
+		if err := yaml.Unmarshal(doc.data, secret); err != nil {
+			return errs
+		}
+		data := getSecretData(secret, storage.Key)
+		if data == nil {
+			return append(errs, fmt.Errorf("the key %s is not found in the secret %s", storage.Key, storage.Name))
+		}
+		if err := mcoctrl.ValidateObjStorageSecret(mco, data); err != nil {
+			return append(errs, fmt.Errorf("invalid object storage configuration in the secret %s: %v", storage.Name, err))
+		}
+	}
+	return errs
+}
+
+// lintConfigMap validates the configmap by its name and its labels
+func lintConfigMap(cm *corev1.ConfigMap) []error {
+	errs := []error{}
+	keys := []string{}
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	switch cm.Name {
+	case config.AllowlistConfigMapName, config.AllowlistCustomConfigMapName:
+		for _, key := range prctrl.AllowlistKeys {
+			if data, ok := cm.Data[key]; ok {
+				if err := prctrl.ValidateAllowlist(data); err != nil {
+					errs = append(errs, fmt.Errorf("invalid allowlist in %s: %v", key, err))
+				}
+			}
+		}
+	case config.AlertRuleCustomConfigMapName:
+		for _, key := range keys {
+			if err := mcoctrl.ValidateRules(cm.Data[key]); err != nil {
+				errs = append(errs, fmt.Errorf("invalid rules in %s: %v", key, err))
+			}
+		}
+	}
+	if _, ok := cm.Labels[customDashboardLabelKey]; ok {
+		for _, key := range keys {
+			if err := mcoctrl.ValidateDashboard(cm.Data[key]); err != nil {
+				errs = append(errs, fmt.Errorf("invalid dashboard in %s: %v", key, err))
+			}
+		}
+	}
+	if _, ok := cm.Labels[config.SpokeRulesLabelKey]; ok {
+		errs = append(errs, prctrl.ValidateSpokeRules(cm)...)
+	}
+	return errs
+}
+
+// getSecretData returns the data of the key in the secret, the stringData is accepted since the
+// secrets in the GitOps repository are usually written in plain text
+func getSecretData(secret *corev1.Secret, key string) []byte {
+	if data, ok := secret.StringData[key]; ok {
+		return []byte(data)
+	}
+	return secret.Data[key]
+}
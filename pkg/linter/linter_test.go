@@ -0,0 +1,149 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package linter
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validConfigs = `apiVersion: observability.open-cluster-management.io/v1beta2
+kind: MultiClusterObservability
+metadata:
+  name: observability
+spec:
+  storageConfig:
+    metricObjectStorage:
+      name: thanos-object-storage
+      key: thanos.yaml
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: thanos-object-storage
+stringData:
+  thanos.yaml: |
+    type: s3
+    config:
+      bucket: test
+      endpoint: s3.amazonaws.com
+      access_key: access
+      secret_key: secret
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: observability-metrics-custom-allowlist
+data:
+  metrics_list.yaml: |
+    names:
+      - node_memory_Active_bytes
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: alertmanager-config
+stringData:
+  alertmanager.yaml: |
+    route:
+      receiver: default
+    receivers:
+      - name: default
+`
+
+const invalidConfigs = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: observability-metrics-custom-allowlist
+data:
+  metrics_list.yaml: |
+    name:
+      - node_memory_Active_bytes
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: thanos-ruler-custom-rules
+data:
+  custom_rules.yaml: |
+    groups:
+      - name: custom
+        rules:
+          - alert: NodeDown
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-dashboard
+  labels:
+    grafana-custom-dashboard: "true"
+data:
+  my-dashboard.json: '{"title": "My Dashboard"'
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: alertmanager-config
+stringData:
+  alertmanager.yaml: |
+    route:
+      receiver: team
+    receivers:
+      - name: default
+---
+apiVersion: observability.open-cluster-management.io/v1beta2
+kind: FleetSLO
+metadata:
+  name: api-availability
+spec:
+  objective: "100"
+  sli:
+    errorQuery: sum(rate(apiserver_request_total{code=~"5.."}[{{window}}]))
+    totalQuery: sum(rate(apiserver_request_total[{{window}}]))
+`
+
+func writeConfigs(t *testing.T, data string) string {
+	dir, err := ioutil.TempDir("", "linter")
+	if err != nil {
+		t.Fatalf("Failed to create the directory: (%v)", err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "configs.yaml"), []byte(data), 0600)
+	if err != nil {
+		t.Fatalf("Failed to write the configs: (%v)", err)
+	}
+	return dir
+}
+
+func TestLint(t *testing.T) {
+	dir := writeConfigs(t, validConfigs)
+	defer os.RemoveAll(dir)
+	out := &bytes.Buffer{}
+	if code := Main([]string{dir}, out); code != 0 {
+		t.Errorf("the valid configs should pass: %d %s", code, out.String())
+	}
+
+	dir = writeConfigs(t, invalidConfigs)
+	defer os.RemoveAll(dir)
+	findings, err := Lint(dir)
+	if err != nil {
+		t.Fatalf("Failed to lint the configs: (%v)", err)
+	}
+	objects := []string{}
+	for _, finding := range findings {
+		objects = append(objects, finding.Object)
+	}
+	expected := []string{"ConfigMap/observability-metrics-custom-allowlist", "ConfigMap/thanos-ruler-custom-rules",
+		"ConfigMap/my-dashboard", "Secret/alertmanager-config", "FleetSLO/api-availability"}
+	if strings.Join(objects, ",") != strings.Join(expected, ",") {
+		t.Errorf("the invalid configs should be reported: %v", findings)
+	}
+	out.Reset()
+	if code := Main([]string{dir}, out); code != 1 {
+		t.Errorf("the linter should fail with the invalid configs: %d %s", code, out.String())
+	}
+}
@@ -15,6 +15,7 @@ import (
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/kustomize/v3/pkg/resource"
+	"sigs.k8s.io/yaml"
 
 	obv1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
@@ -40,6 +41,7 @@ type Renderer struct {
 	renderFns             map[string]renderFn
 	renderGrafanaFns      map[string]renderFn
 	renderAlertManagerFns map[string]renderFn
+	renderLokiFns         map[string]renderFn
 }
 
 func NewRenderer(multipleClusterMonitoring *obv1beta2.MultiClusterObservability) *Renderer {
@@ -58,6 +60,7 @@ func NewRenderer(multipleClusterMonitoring *obv1beta2.MultiClusterObservability)
 	}
 	renderer.newGranfanaRenderer()
 	renderer.newAlertManagerRenderer()
+	renderer.newLokiRenderer()
 	return renderer
 }
 
@@ -94,7 +97,27 @@ func (r *Renderer) Render(c runtimeclient.Client) ([]*unstructured.Unstructured,
 	}
 	resources = append(resources, alertResources...)
 
+	//render loki templates when the logs are collected into the hub
+	if mcoconfig.IsHubLokiEnabled(r.cr) {
+		lokiTemplates, err := templates.GetTemplateRenderer().GetLokiTemplates(r.cr)
+		if err != nil {
+			return nil, err
+		}
+		lokiResources, err := r.renderLokiTemplates(lokiTemplates)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, lokiResources...)
+	}
+
 	for idx, _ := range resources {
+		if resources[idx].GetKind() == "ConfigMap" &&
+			resources[idx].GetName() == mcoconfig.OTLPReceiver+"-config" {
+			err := updateOTLPReceiverConfig(resources[idx], r.cr)
+			if err != nil {
+				return nil, err
+			}
+		}
 		if resources[idx].GetKind() == "Deployment" {
 			obj := util.GetK8sObj(resources[idx].GetKind())
 			err := runtime.DefaultUnstructuredConverter.FromUnstructured(resources[idx].Object, obj)
@@ -127,6 +150,7 @@ func (r *Renderer) Render(c runtimeclient.Client) ([]*unstructured.Unstructured,
 				if found {
 					spec.Containers[1].Image = image
 				}
+				updateGrafanaSpec(dep, r.cr)
 
 			case "observatorium-operator":
 				found, image := mcoconfig.ReplaceImage(r.cr.Annotations, spec.Containers[0].Image,
@@ -138,6 +162,10 @@ func (r *Renderer) Render(c runtimeclient.Client) ([]*unstructured.Unstructured,
 			case "rbac-query-proxy":
 				dep.Spec.Replicas = config.GetObservabilityComponentReplicas(config.RbacQueryProxy)
 				updateProxySpec(spec, r.cr)
+
+			case "otlp-receiver":
+				dep.Spec.Replicas = config.GetObservabilityComponentReplicas(config.OTLPReceiver)
+				updateOTLPReceiverSpec(spec, r.cr)
 			}
 
 			unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
@@ -149,6 +177,38 @@ func (r *Renderer) Render(c runtimeclient.Client) ([]*unstructured.Unstructured,
 		}
 	}
 
+	// run the components with the priority class so that they are not the first pods evicted
+	if priorityClassName := mcoconfig.GetHubPriorityClassName(r.cr); priorityClassName != "" {
+		for idx := range resources {
+			if kind := resources[idx].GetKind(); kind != "Deployment" && kind != "StatefulSet" {
+				continue
+			}
+			err := unstructured.SetNestedField(resources[idx].Object, priorityClassName,
+				"spec", "template", "spec", "priorityClassName")
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// grant the permissions of the components in their namespace only when the operator is
+	// restricted to it
+	if mcoconfig.IsNamespaceScoped() {
+		if err := toNamespacedRBAC(resources); err != nil {
+			return nil, err
+		}
+	}
+
+	// label the resources with the version of the operator to detect the upgrades of the components
+	for idx := range resources {
+		labels := resources[idx].GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[mcoconfig.VersionLabelKey] = mcoconfig.GetComponentVersion()
+		resources[idx].SetLabels(labels)
+	}
+
 	return resources, nil
 }
 
@@ -174,6 +234,108 @@ func updateProxySpec(spec *corev1.PodSpec, mco *obv1beta2.MultiClusterObservabil
 	}
 }
 
+func updateOTLPReceiverSpec(spec *corev1.PodSpec, mco *obv1beta2.MultiClusterObservability) {
+	found, image := mcoconfig.ReplaceImage(mco.Annotations, spec.Containers[0].Image,
+		mcoconfig.OTelCollectorKey)
+	if found {
+		spec.Containers[0].Image = image
+	}
+
+	env := spec.Containers[0].Env
+	for idx := range env {
+		switch env[idx].Name {
+		case "THANOS_RECEIVE_URL":
+			// write into thanos receive directly as the tenant of the observatorium api
+			env[idx].Value = mcoconfig.GetThanosReceiveURL(mco.Name)
+		case "TENANT_ID":
+			env[idx].Value = mcoconfig.GetTenantUID()
+		}
+	}
+	if mcoconfig.IsTracingEnabled(mco) && !mco.Spec.Tracing.Insecure && mco.Spec.Tracing.CASecretName != "" {
+		// verify the server certificate of the tracing backend against its own CA
+		spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "tracing-ca",
+			MountPath: mcoconfig.TracingCAMountPath,
+			ReadOnly:  true,
+		})
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: "tracing-ca",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: mco.Spec.Tracing.CASecretName,
+					Items:      []corev1.KeyToPath{{Key: mcoconfig.TracingCAKey, Path: mcoconfig.TracingCAKey}},
+				},
+			},
+		})
+	}
+}
+
+// updateOTLPReceiverConfig adds the external labels of the hub to the metrics which the otlp
+// receiver writes, and the traces pipeline into the otlp receiver config, which exports the
+// traces to the tracing backend
+func updateOTLPReceiverConfig(u *unstructured.Unstructured, mco *obv1beta2.MultiClusterObservability) error {
+	externalLabels := mcoconfig.GetHubExternalLabels(mco)
+	if len(externalLabels) == 0 && !mcoconfig.IsTracingEnabled(mco) {
+		return nil
+	}
+	data, _, err := unstructured.NestedString(u.Object, "data", "config.yaml")
+	if err != nil {
+		return err
+	}
+	cfg := map[string]interface{}{}
+	err = yaml.Unmarshal([]byte(data), &cfg)
+	if err != nil {
+		return err
+	}
+
+	if len(externalLabels) > 0 {
+		labels := map[string]interface{}{}
+		for name, value := range externalLabels {
+			labels[name] = value
+		}
+		err = unstructured.SetNestedField(cfg, labels, "exporters", "prometheusremotewrite", "external_labels")
+		if err != nil {
+			return err
+		}
+	}
+	if mcoconfig.IsTracingEnabled(mco) {
+		if err := addTracesPipeline(cfg, mco); err != nil {
+			return err
+		}
+	}
+
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(u.Object, string(out), "data", "config.yaml")
+}
+
+// addTracesPipeline adds the traces pipeline which exports the traces to the tracing backend
+func addTracesPipeline(cfg map[string]interface{}, mco *obv1beta2.MultiClusterObservability) error {
+	exporter := "otlp"
+	if mco.Spec.Tracing.Backend == mcoconfig.TracingBackendJaeger {
+		exporter = "jaeger"
+	}
+	exporterCfg := map[string]interface{}{
+		"endpoint": mco.Spec.Tracing.Endpoint,
+	}
+	if mco.Spec.Tracing.Insecure {
+		exporterCfg["insecure"] = true
+	} else {
+		exporterCfg["ca_file"] = mcoconfig.GetTracingCAFile(mco)
+	}
+	err := unstructured.SetNestedField(cfg, exporterCfg, "exporters", exporter)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(cfg, map[string]interface{}{
+		"receivers":  []interface{}{"otlp"},
+		"processors": []interface{}{"memory_limiter", "batch"},
+		"exporters":  []interface{}{exporter},
+	}, "service", "pipelines", "traces")
+}
+
 func (r *Renderer) renderTemplates(templates []*resource.Resource) ([]*unstructured.Unstructured, error) {
 	uobjs := []*unstructured.Unstructured{}
 	for _, template := range templates {
@@ -236,6 +398,38 @@ func (r *Renderer) renderClusterRoleBinding(res *resource.Resource) (*unstructur
 	return u, nil
 }
 
+// toNamespacedRBAC converts the ClusterRoles and ClusterRoleBindings of the components into the
+// Roles and RoleBindings in the namespace of the operands, the permissions on the resources of the
+// other namespaces and the cluster-scoped resources are not granted by them
+func toNamespacedRBAC(resources []*unstructured.Unstructured) error {
+	clusterRoles := map[string]bool{}
+	for _, res := range resources {
+		if res.GetKind() == "ClusterRole" {
+			clusterRoles[res.GetName()] = true
+			res.SetKind("Role")
+			res.SetNamespace(mcoconfig.GetDefaultNamespace())
+		}
+	}
+	for _, res := range resources {
+		if res.GetKind() != "ClusterRoleBinding" {
+			continue
+		}
+		res.SetKind("RoleBinding")
+		res.SetNamespace(mcoconfig.GetDefaultNamespace())
+		// the binding keeps referring to the roles which are not rendered, e.g. the default ones
+		roleName, _, err := unstructured.NestedString(res.Object, "roleRef", "name")
+		if err != nil {
+			return err
+		}
+		if clusterRoles[roleName] {
+			if err := unstructured.SetNestedField(res.Object, "Role", "roleRef", "kind"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // UpdateNamespace checks for annotiation to update NS
 func UpdateNamespace(u *unstructured.Unstructured) bool {
 	metadata, ok := u.Object["metadata"].(map[string]interface{})
@@ -4,6 +4,7 @@
 package rendering
 
 import (
+	"fmt"
 	"strings"
 
 	v1 "k8s.io/api/apps/v1"
@@ -77,6 +78,11 @@ func (r *Renderer) renderAlertManagerStatefulSet(res *resource.Resource) (*unstr
 	spec.ImagePullSecrets = []corev1.LocalObjectReference{
 		{Name: r.cr.Spec.ImagePullSecret},
 	}
+	if mcoconfig.IsHighAvailabilityEnabled(r.cr) {
+		spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{
+			mcoconfig.GetHATopologySpreadConstraint(r.cr, dep.Spec.Selector.MatchLabels),
+		}
+	}
 
 	//replace the alertmanager and config-reloader images
 	found, image := mcoconfig.ReplaceImage(
@@ -93,9 +99,16 @@ func (r *Renderer) renderAlertManagerStatefulSet(res *resource.Resource) (*unstr
 		spec.Containers[1].Image = image
 	}
 	//replace the volumeClaimTemplate
+	storageSize := r.cr.Spec.StorageConfig.AlertmanagerStorageSize
+	if storageSize == "" {
+		storageSize = mcoconfig.DefaultAlertmanagerStorageSize
+	}
+	storage, err := apiresource.ParseQuantity(storageSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alertmanager storage size %s: %v", storageSize, err)
+	}
 	dep.Spec.VolumeClaimTemplates[0].Spec.StorageClassName = &r.cr.Spec.StorageConfig.StorageClass
-	dep.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage] =
-		apiresource.MustParse(r.cr.Spec.StorageConfig.AlertmanagerStorageSize)
+	dep.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage] = storage
 
 	unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
 	if err != nil {
@@ -4,12 +4,33 @@
 package rendering
 
 import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/kustomize/v3/pkg/resource"
+	"sigs.k8s.io/yaml"
 
+	obv1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
 )
 
+const (
+	// the root of the git-sync sidecar, the repository is checked out in the dashboards link under it
+	dashboardSyncRoot             = "/var/lib/grafana-dashboard-sync"
+	dashboardSyncLink             = "dashboards"
+	dashboardSyncProvisioningPath = "/etc/grafana/provisioning/dashboards"
+	dashboardSyncProviderKey      = "dashboard-sync.yaml"
+)
+
 func (r *Renderer) newGranfanaRenderer() {
 	r.renderGrafanaFns = map[string]renderFn{
 		"Deployment":            r.renderGrafanaDeployments,
@@ -57,5 +78,249 @@ func (r *Renderer) renderGrafanaTemplates(templates []*resource.Resource) ([]*un
 
 	}
 
+	// the claim is kept when the persistence is disabled, so that the data is not lost by mistake
+	if config.IsGrafanaPersistenceEnabled(r.cr) {
+		pvc, err := newGrafanaPVC(r.cr)
+		if err != nil {
+			return []*unstructured.Unstructured{}, err
+		}
+		uobjs = append(uobjs, pvc)
+	}
+
+	if config.IsGrafanaDashboardSyncEnabled(r.cr) {
+		cm, err := newGrafanaDashboardSyncConfigMap(r.cr)
+		if err != nil {
+			return []*unstructured.Unstructured{}, err
+		}
+		uobjs = append(uobjs, cm)
+	}
+
 	return uobjs, nil
 }
+
+// newGrafanaPVC returns the persistent volume claim of the data of grafana
+func newGrafanaPVC(mco *obv1beta2.MultiClusterObservability) (*unstructured.Unstructured, error) {
+	storageSize := mco.Spec.Grafana.Persistence.StorageSize
+	if storageSize == "" {
+		storageSize = config.DefaultGrafanaStorageSize
+	}
+	quantity, err := apiresource.ParseQuantity(storageSize)
+	if err != nil {
+		return nil, err
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.GrafanaStorageName,
+			Namespace: config.GetDefaultNamespace(),
+			Labels: map[string]string{
+				"app":      "multicluster-observability-grafana",
+				crLabelKey: mco.Name,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: quantity},
+			},
+		},
+	}
+	if mco.Spec.StorageConfig != nil && mco.Spec.StorageConfig.StorageClass != "" {
+		pvc.Spec.StorageClassName = &mco.Spec.StorageConfig.StorageClass
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pvc)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: obj}, nil
+}
+
+// updateGrafanaSpec applies the customizations of grafana in the MultiClusterObservability CR
+// to the grafana deployment
+func updateGrafanaSpec(dep *v1.Deployment, mco *obv1beta2.MultiClusterObservability) {
+	grafana := mco.Spec.Grafana
+	if grafana == nil {
+		return
+	}
+	container := &dep.Spec.Template.Spec.Containers[0]
+
+	if len(grafana.Plugins) > 0 {
+		plugins := []string{}
+		for _, plugin := range grafana.Plugins {
+			if plugin.Version != "" {
+				plugins = append(plugins, plugin.Name+" "+plugin.Version)
+			} else {
+				plugins = append(plugins, plugin.Name)
+			}
+		}
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  "GF_INSTALL_PLUGINS",
+			Value: strings.Join(plugins, ","),
+		})
+	}
+
+	if grafana.AdminPasswordSecret != nil {
+		container.Env = append(container.Env, newSecretEnvVar("GF_SECURITY_ADMIN_PASSWORD",
+			grafana.AdminPasswordSecret))
+	}
+
+	if smtp := grafana.SMTP; smtp != nil {
+		container.Env = append(container.Env,
+			corev1.EnvVar{Name: "GF_SMTP_ENABLED", Value: "true"},
+			corev1.EnvVar{Name: "GF_SMTP_HOST", Value: smtp.Host},
+			corev1.EnvVar{Name: "GF_SMTP_FROM_ADDRESS", Value: smtp.FromAddress},
+			corev1.EnvVar{Name: "GF_SMTP_SKIP_VERIFY", Value: strconv.FormatBool(smtp.SkipVerify)},
+		)
+		if smtp.User != "" {
+			container.Env = append(container.Env, corev1.EnvVar{Name: "GF_SMTP_USER", Value: smtp.User})
+		}
+		if smtp.PasswordSecret != nil {
+			container.Env = append(container.Env, newSecretEnvVar("GF_SMTP_PASSWORD", smtp.PasswordSecret))
+		}
+	}
+
+	if config.IsGrafanaDashboardSyncEnabled(mco) {
+		updateGrafanaDashboardSync(dep, mco)
+	}
+
+	if config.IsGrafanaPersistenceEnabled(mco) {
+		// the claim is ReadWriteOnce, so only one pod can mount it
+		replicas := int32(1)
+		dep.Spec.Replicas = &replicas
+		dep.Spec.Strategy = v1.DeploymentStrategy{Type: v1.RecreateDeploymentStrategyType}
+		for idx := range dep.Spec.Template.Spec.Volumes {
+			volume := &dep.Spec.Template.Spec.Volumes[idx]
+			if volume.Name == config.GrafanaStorageName {
+				volume.VolumeSource = corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: config.GrafanaStorageName,
+					},
+				}
+			}
+		}
+	}
+}
+
+// newGrafanaDashboardSyncConfigMap returns the configmap of the dashboard provider of grafana, which
+// loads the dashboards synced from the git repository into the folders of their sub directories
+func newGrafanaDashboardSyncConfigMap(mco *obv1beta2.MultiClusterObservability) (*unstructured.Unstructured, error) {
+	provider := map[string]interface{}{
+		"apiVersion": 1,
+		"providers": []interface{}{
+			map[string]interface{}{
+				"name":                  "dashboard-sync",
+				"type":                  "file",
+				"disableDeletion":       false,
+				"allowUiUpdates":        false,
+				"updateIntervalSeconds": 30,
+				"options": map[string]interface{}{
+					"path": path.Join(dashboardSyncRoot, dashboardSyncLink,
+						mco.Spec.Grafana.DashboardSync.Path),
+					"foldersFromFilesStructure": true,
+				},
+			},
+		},
+	}
+	data, err := yaml.Marshal(provider)
+	if err != nil {
+		return nil, err
+	}
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      config.GrafanaDashboardSyncName,
+			Namespace: config.GetDefaultNamespace(),
+			Labels: map[string]string{
+				"app":      "multicluster-observability-grafana",
+				crLabelKey: mco.Name,
+			},
+		},
+		Data: map[string]string{dashboardSyncProviderKey: string(data)},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: obj}, nil
+}
+
+// updateGrafanaDashboardSync adds the git-sync sidecar which pulls the git repository into the
+// volume shared with grafana, and mounts the dashboard provider into grafana
+func updateGrafanaDashboardSync(dep *v1.Deployment, mco *obv1beta2.MultiClusterObservability) {
+	sync := mco.Spec.Grafana.DashboardSync
+	ref := sync.Ref
+	if ref == "" {
+		ref = "main"
+	}
+	interval, err := time.ParseDuration(sync.Interval)
+	if err != nil || interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	image := config.GitSyncImgRepo + "/" + config.GitSyncImgName + ":" + config.GitSyncImgTag
+	if found, replacedImage := config.ReplaceImage(mco.Annotations, image, config.GitSyncKey); found {
+		image = replacedImage
+	}
+
+	container := corev1.Container{
+		Name:            config.GrafanaDashboardSyncName,
+		Image:           image,
+		ImagePullPolicy: mco.Spec.ImagePullPolicy,
+		Args: []string{
+			"--repo=" + sync.Repository,
+			"--branch=" + ref,
+			"--depth=1",
+			"--root=" + dashboardSyncRoot,
+			"--dest=" + dashboardSyncLink,
+			fmt.Sprintf("--wait=%d", int(interval.Seconds())),
+			// keep retrying instead of restarting the pod when the repository is unavailable
+			"--max-sync-failures=-1",
+		},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    apiresource.MustParse("4m"),
+				corev1.ResourceMemory: apiresource.MustParse("50Mi"),
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: config.GrafanaDashboardSyncName, MountPath: dashboardSyncRoot},
+		},
+	}
+	if sync.CredentialsSecret != "" {
+		for _, env := range [][2]string{{"GIT_SYNC_USERNAME", "username"}, {"GIT_SYNC_PASSWORD", "password"}} {
+			container.Env = append(container.Env, newSecretEnvVar(env[0], &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: sync.CredentialsSecret},
+				Key:                  env[1],
+			}))
+		}
+	}
+
+	spec := &dep.Spec.Template.Spec
+	spec.Containers = append(spec.Containers, container)
+	spec.Containers[0].VolumeMounts = append(spec.Containers[0].VolumeMounts,
+		corev1.VolumeMount{Name: config.GrafanaDashboardSyncName, MountPath: dashboardSyncRoot, ReadOnly: true},
+		corev1.VolumeMount{Name: config.GrafanaDashboardSyncName + "-provider", MountPath: dashboardSyncProvisioningPath},
+	)
+	spec.Volumes = append(spec.Volumes,
+		corev1.Volume{
+			Name:         config.GrafanaDashboardSyncName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+		corev1.Volume{
+			Name: config.GrafanaDashboardSyncName + "-provider",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: config.GrafanaDashboardSyncName},
+				},
+			},
+		},
+	)
+}
+
+func newSecretEnvVar(name string, selector *corev1.SecretKeySelector) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: selector.DeepCopy(),
+		},
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package rendering
+
+import (
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/kustomize/v3/pkg/resource"
+
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+const defaultLokiStorageSize = "10Gi"
+
+func (r *Renderer) newLokiRenderer() {
+	r.renderLokiFns = map[string]renderFn{
+		"StatefulSet":    r.renderLokiStatefulSet,
+		"Service":        r.renderNamespace,
+		"ServiceAccount": r.renderNamespace,
+		"ConfigMap":      r.renderNamespace,
+	}
+}
+
+func (r *Renderer) renderLokiStatefulSet(res *resource.Resource) (*unstructured.Unstructured, error) {
+	u, err := r.renderDeployments(res)
+	if err != nil {
+		return nil, err
+	}
+	obj := util.GetK8sObj(u.GetKind())
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj)
+	if err != nil {
+		return nil, err
+	}
+	sts := obj.(*v1.StatefulSet)
+	sts.ObjectMeta.Labels[crLabelKey] = r.cr.Name
+	sts.Spec.Selector.MatchLabels[crLabelKey] = r.cr.Name
+	sts.Spec.Template.ObjectMeta.Labels[crLabelKey] = r.cr.Name
+	sts.Name = r.cr.Name + "-" + sts.Name
+
+	spec := &sts.Spec.Template.Spec
+	spec.Containers[0].ImagePullPolicy = r.cr.Spec.ImagePullPolicy
+	spec.NodeSelector = r.cr.Spec.NodeSelector
+	spec.Tolerations = r.cr.Spec.Tolerations
+	spec.ImagePullSecrets = []corev1.LocalObjectReference{
+		{Name: r.cr.Spec.ImagePullSecret},
+	}
+
+	found, image := mcoconfig.ReplaceImage(r.cr.Annotations, spec.Containers[0].Image, mcoconfig.LokiKey)
+	if found {
+		spec.Containers[0].Image = image
+	}
+
+	//replace the volumeClaimTemplate
+	storageSize := r.cr.Spec.LogsCollection.StorageSize
+	if storageSize == "" {
+		storageSize = defaultLokiStorageSize
+	}
+	sts.Spec.VolumeClaimTemplates[0].Spec.StorageClassName = &r.cr.Spec.StorageConfig.StorageClass
+	sts.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage] =
+		apiresource.MustParse(storageSize)
+
+	unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return &unstructured.Unstructured{Object: unstructuredObj}, nil
+}
+
+func (r *Renderer) renderLokiTemplates(templates []*resource.Resource) ([]*unstructured.Unstructured, error) {
+	uobjs := []*unstructured.Unstructured{}
+	for _, template := range templates {
+		render, ok := r.renderLokiFns[template.GetKind()]
+		if !ok {
+			uobjs = append(uobjs, &unstructured.Unstructured{Object: template.Map()})
+			continue
+		}
+		uobj, err := render(template.DeepCopy())
+		if err != nil {
+			return []*unstructured.Unstructured{}, err
+		}
+		if uobj == nil {
+			continue
+		}
+		uobjs = append(uobjs, uobj)
+	}
+
+	return uobjs, nil
+}
@@ -6,13 +6,18 @@ package rendering
 import (
 	"os"
 	"path"
+	"strings"
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
 	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
 	"github.com/open-cluster-management/multicluster-observability-operator/pkg/rendering/templates"
 )
 
@@ -48,6 +53,7 @@ func TestRender(t *testing.T) {
 				RetentionResolution5m:  "1h",
 				RetentionResolution1h:  "1h",
 			},
+			PriorityClasses: &mcov1beta2.PriorityClassesSpec{Hub: &mcov1beta2.PriorityClassSpec{}},
 		},
 	}
 
@@ -56,10 +62,250 @@ func TestRender(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to render MultiClusterObservability: %v", err)
 	}
+	for _, obj := range objs {
+		if obj.GetLabels()[config.VersionLabelKey] != config.GetComponentVersion() {
+			t.Errorf("%s %s is not labelled with the version of the operator", obj.GetKind(), obj.GetName())
+		}
+		if obj.GetKind() == "Deployment" || obj.GetKind() == "StatefulSet" {
+			priorityClassName, _, _ := unstructured.NestedString(obj.Object,
+				"spec", "template", "spec", "priorityClassName")
+			if priorityClassName != config.HubPriorityClassName {
+				t.Errorf("%s %s does not run with the priority class", obj.GetKind(), obj.GetName())
+			}
+		}
+	}
 
 	printObjs(t, objs)
 }
 
+func TestRenderNamespaceScoped(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working dir %v", err)
+	}
+	templatesPath := path.Join(path.Dir(path.Dir(wd)), "manifests")
+	os.Setenv(templates.TemplatesPathEnvVar, templatesPath)
+	defer os.Unsetenv(templates.TemplatesPathEnvVar)
+	os.Setenv(config.WatchNamespaceEnv, config.GetDefaultNamespace())
+	defer os.Unsetenv(config.WatchNamespaceEnv)
+
+	mchcr := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "test"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			ImagePullPolicy: "Always",
+			ImagePullSecret: "test",
+			StorageConfig: &mcov1beta2.StorageConfig{
+				MetricObjectStorage: &mcoshared.PreConfiguredStorage{
+					Key:  "test",
+					Name: "test",
+				},
+			},
+			// the priority class cannot be created in the namespace-scoped install mode
+			PriorityClasses: &mcov1beta2.PriorityClassesSpec{Hub: &mcov1beta2.PriorityClassSpec{}},
+		},
+	}
+
+	renderer := NewRenderer(mchcr)
+	objs, err := renderer.Render(nil)
+	if err != nil {
+		t.Fatalf("failed to render MultiClusterObservability: %v", err)
+	}
+	roles := 0
+	for _, obj := range objs {
+		switch obj.GetKind() {
+		case "ClusterRole", "ClusterRoleBinding":
+			t.Errorf("%s %s should not be rendered in the namespace-scoped install mode", obj.GetKind(), obj.GetName())
+		case "Role", "RoleBinding":
+			roles++
+			if obj.GetNamespace() != config.GetDefaultNamespace() {
+				t.Errorf("%s %s is not in the namespace of the operands", obj.GetKind(), obj.GetName())
+			}
+		case "Deployment", "StatefulSet":
+			priorityClassName, _, _ := unstructured.NestedString(obj.Object,
+				"spec", "template", "spec", "priorityClassName")
+			if priorityClassName != "" {
+				t.Errorf("%s %s should not run with the priority class", obj.GetKind(), obj.GetName())
+			}
+		}
+		if obj.GetKind() == "RoleBinding" {
+			kind, _, _ := unstructured.NestedString(obj.Object, "roleRef", "kind")
+			if kind != "Role" {
+				t.Errorf("RoleBinding %s should refer to the Role: %s", obj.GetName(), kind)
+			}
+		}
+	}
+	if roles == 0 {
+		t.Errorf("The Roles and RoleBindings of the components are not rendered")
+	}
+}
+
+func TestRenderWithTracing(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working dir %v", err)
+	}
+	templatesPath := path.Join(path.Dir(path.Dir(wd)), "manifests")
+	os.Setenv(templates.TemplatesPathEnvVar, templatesPath)
+	defer os.Unsetenv(templates.TemplatesPathEnvVar)
+
+	mchcr := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "test"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			StorageConfig: &mcov1beta2.StorageConfig{
+				StorageClass:            "gp2",
+				AlertmanagerStorageSize: "1Gi",
+			},
+			Tracing: &mcov1beta2.TracingSpec{
+				Enabled:      true,
+				Backend:      "jaeger",
+				Endpoint:     "jaeger-collector.tracing.svc:14250",
+				QueryURL:     "http://jaeger-query.tracing.svc:16686",
+				CASecretName: "tracing-ca",
+			},
+		},
+	}
+
+	renderer := NewRenderer(mchcr)
+	objs, err := renderer.Render(nil)
+	if err != nil {
+		t.Fatalf("failed to render MultiClusterObservability: %v", err)
+	}
+	rendered := 0
+	for _, obj := range objs {
+		if obj.GetKind() == "ConfigMap" && obj.GetName() == "otlp-receiver-config" {
+			rendered++
+			data, _, _ := unstructured.NestedString(obj.Object, "data", "config.yaml")
+			if !strings.Contains(data, "traces:") || !strings.Contains(data, mchcr.Spec.Tracing.Endpoint) {
+				t.Errorf("the traces pipeline is not added into the otlp receiver config: %s", data)
+			}
+			if !strings.Contains(data, "ca_file: /etc/otlp/tracing-ca/ca.crt") || strings.Contains(data, "insecure") {
+				t.Errorf("the traces should be exported with TLS: %s", data)
+			}
+		}
+		if obj.GetKind() == "Deployment" && obj.GetName() == mchcr.Name+"-otlp-receiver" {
+			rendered++
+			dep := &appsv1.Deployment{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, dep); err != nil {
+				t.Fatalf("failed to convert the otlp receiver: %v", err)
+			}
+			found := false
+			for _, volume := range dep.Spec.Template.Spec.Volumes {
+				if volume.Name == "tracing-ca" && volume.Secret.SecretName == "tracing-ca" {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("the CA of the tracing backend is not mounted into the otlp receiver")
+			}
+		}
+	}
+	if rendered != 2 {
+		t.Fatalf("the otlp receiver is not rendered when the tracing is enabled")
+	}
+}
+
+func TestRenderWithGrafana(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working dir %v", err)
+	}
+	templatesPath := path.Join(path.Dir(path.Dir(wd)), "manifests")
+	os.Setenv(templates.TemplatesPathEnvVar, templatesPath)
+	defer os.Unsetenv(templates.TemplatesPathEnvVar)
+
+	mchcr := &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "test"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			StorageConfig: &mcov1beta2.StorageConfig{
+				StorageClass:            "gp2",
+				AlertmanagerStorageSize: "1Gi",
+			},
+			Grafana: &mcov1beta2.GrafanaSpec{
+				Plugins: []mcov1beta2.GrafanaPlugin{
+					{Name: "grafana-piechart-panel", Version: "1.6.1"},
+					{Name: "grafana-clock-panel"},
+				},
+				AdminPasswordSecret: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "grafana-admin"},
+					Key:                  "password",
+				},
+				Persistence: &mcov1beta2.GrafanaPersistenceSpec{Enabled: true},
+				SMTP: &mcov1beta2.GrafanaSMTPSpec{
+					Host:        "smtp.example.com:587",
+					FromAddress: "grafana@example.com",
+				},
+				DashboardSync: &mcov1beta2.DashboardSyncSpec{
+					Repository:        "https://github.com/example/dashboards.git",
+					Path:              "grafana",
+					Interval:          "1m",
+					CredentialsSecret: "dashboards-git",
+				},
+			},
+		},
+	}
+
+	renderer := NewRenderer(mchcr)
+	objs, err := renderer.Render(nil)
+	if err != nil {
+		t.Fatalf("failed to render MultiClusterObservability: %v", err)
+	}
+	foundPVC, foundProvider := false, false
+	for _, obj := range objs {
+		if obj.GetKind() == "PersistentVolumeClaim" && obj.GetName() == config.GrafanaStorageName {
+			foundPVC = true
+		}
+		if obj.GetKind() == "ConfigMap" && obj.GetName() == config.GrafanaDashboardSyncName {
+			data, _, _ := unstructured.NestedString(obj.Object, "data", dashboardSyncProviderKey)
+			if !strings.Contains(data, "/var/lib/grafana-dashboard-sync/dashboards/grafana") {
+				t.Fatalf("wrong path of the dashboard provider: %s", data)
+			}
+			foundProvider = true
+		}
+	}
+	if !foundPVC || !foundProvider {
+		t.Fatalf("the persistent volume claim or the dashboard provider of grafana is not rendered")
+	}
+	for _, obj := range objs {
+		if obj.GetKind() != "Deployment" || obj.GetName() != "test-grafana" {
+			continue
+		}
+		dep := &appsv1.Deployment{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, dep)
+		if err != nil {
+			t.Fatalf("failed to convert the grafana deployment: %v", err)
+		}
+		if *dep.Spec.Replicas != 1 {
+			t.Fatalf("grafana should run with one replica when the persistence is enabled")
+		}
+		env := map[string]corev1.EnvVar{}
+		for _, e := range dep.Spec.Template.Spec.Containers[0].Env {
+			env[e.Name] = e
+		}
+		if env["GF_INSTALL_PLUGINS"].Value != "grafana-piechart-panel 1.6.1,grafana-clock-panel" {
+			t.Fatalf("wrong plugins of grafana: %v", env["GF_INSTALL_PLUGINS"])
+		}
+		if env["GF_SECURITY_ADMIN_PASSWORD"].ValueFrom == nil || env["GF_SMTP_HOST"].Value != "smtp.example.com:587" {
+			t.Fatalf("the admin password or the smtp server is not configured: %v", env)
+		}
+		containers := dep.Spec.Template.Spec.Containers
+		sync := containers[len(containers)-1]
+		if sync.Name != config.GrafanaDashboardSyncName || len(sync.Env) != 2 ||
+			!strings.Contains(strings.Join(sync.Args, " "), "--wait=60") {
+			t.Fatalf("the dashboard sync sidecar is not rendered: %v", sync)
+		}
+		for _, volume := range dep.Spec.Template.Spec.Volumes {
+			if volume.Name == config.GrafanaStorageName && volume.PersistentVolumeClaim == nil {
+				t.Fatalf("the data of grafana is not stored in the persistent volume claim")
+			}
+		}
+		return
+	}
+	t.Fatalf("the grafana deployment is not rendered")
+}
+
 func printObjs(t *testing.T, objs []*unstructured.Unstructured) {
 	for _, obj := range objs {
 		t.Log(obj)
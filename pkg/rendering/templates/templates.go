@@ -4,9 +4,12 @@
 package templates
 
 import (
+	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"path"
+	"path/filepath"
 	"sync"
 
 	"sigs.k8s.io/kustomize/v3/k8sdeps/kunstruct"
@@ -20,6 +23,7 @@ import (
 	"sigs.k8s.io/kustomize/v3/pkg/target"
 
 	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	mcoconfig "github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
 )
 
 const TemplatesPathEnvVar = "TEMPLATES_PATH"
@@ -81,6 +85,20 @@ func (r *TemplateRenderer) GetAlertManagerTemplates(
 	return resourceList, nil
 }
 
+// GetLokiTemplates reads the loki manifests
+func (r *TemplateRenderer) GetLokiTemplates(
+	mco *mcov1beta2.MultiClusterObservability) ([]*resource.Resource, error) {
+	basePath := path.Join(r.templatesPath, "base")
+	// resourceList contains all kustomize resources
+	resourceList := []*resource.Resource{}
+
+	// add loki template
+	if err := r.AddTemplateFromPath(basePath+"/loki", &resourceList); err != nil {
+		return resourceList, err
+	}
+	return resourceList, nil
+}
+
 // GetTemplates reads base manifest
 func (r *TemplateRenderer) GetTemplates(
 	mco *mcov1beta2.MultiClusterObservability) ([]*resource.Resource, error) {
@@ -103,9 +121,63 @@ func (r *TemplateRenderer) GetTemplates(
 		return resourceList, err
 	}
 
+	// add otlp receiver template
+	if mcoconfig.IsOTLPReceiverEnabled(mco) {
+		if err := r.AddTemplateFromPath(basePath+"/otlp-receiver", &resourceList); err != nil {
+			return resourceList, err
+		}
+	}
+
 	return resourceList, nil
 }
 
+// GetOverlayTemplates renders the overlay under the overlays directory of the templates, the strategic
+// merge patches are applied on top of the overlay. The templates are copied into memory to render the
+// patches, so that the templates on disk are not changed.
+func (r *TemplateRenderer) GetOverlayTemplates(overlay string, patches []string) ([]*resource.Resource, error) {
+	overlayPath := path.Join(r.templatesPath, "overlays", overlay)
+	// resourceList contains all kustomize resources
+	resourceList := []*resource.Resource{}
+	if len(patches) == 0 {
+		if err := r.AddTemplateFromPath(overlayPath, &resourceList); err != nil {
+			return resourceList, err
+		}
+		return resourceList, nil
+	}
+
+	fSys := fs.MakeFsInMemory()
+	err := filepath.Walk(r.templatesPath, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		return fSys.WriteFile(file, data)
+	})
+	if err != nil {
+		return resourceList, err
+	}
+	patchedPath := path.Join(r.templatesPath, "patched")
+	kustomization := fmt.Sprintf("resources:\n- ../overlays/%s\npatchesStrategicMerge:\n", overlay)
+	for i, patch := range patches {
+		name := fmt.Sprintf("patch-%d.yaml", i)
+		if err := fSys.WriteFile(path.Join(patchedPath, name), []byte(patch)); err != nil {
+			return resourceList, err
+		}
+		kustomization += "- " + name + "\n"
+	}
+	if err := fSys.WriteFile(path.Join(patchedPath, "kustomization.yaml"), []byte(kustomization)); err != nil {
+		return resourceList, err
+	}
+	resMap, err := r.renderWithFs(patchedPath, fSys)
+	if err != nil {
+		return resourceList, err
+	}
+	return append(resourceList, resMap.Resources()...), nil
+}
+
 func (r *TemplateRenderer) AddTemplateFromPath(kustomizationPath string, resourceList *[]*resource.Resource) error {
 	var err error
 	resMap, ok := r.templates[kustomizationPath]
@@ -122,11 +194,15 @@ func (r *TemplateRenderer) AddTemplateFromPath(kustomizationPath string, resourc
 }
 
 func (r *TemplateRenderer) render(kustomizationPath string) (resmap.ResMap, error) {
+	return r.renderWithFs(kustomizationPath, fs.MakeFsOnDisk())
+}
+
+func (r *TemplateRenderer) renderWithFs(kustomizationPath string, fSys fs.FileSystem) (resmap.ResMap, error) {
 	ldr, err := loader.NewLoader(
 		loader.RestrictionRootOnly,
 		validator.NewKustValidator(),
 		kustomizationPath,
-		fs.MakeFsOnDisk(),
+		fSys,
 	)
 
 	if err != nil {
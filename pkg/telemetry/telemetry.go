@@ -0,0 +1,228 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+)
+
+const (
+	// the interval in which the reporter checks whether the next report is due
+	pollInterval = time.Minute
+	// the label of the components which the operator deploys for the MultiClusterObservability
+	crLabelKey = "observability.open-cluster-management.io/name"
+)
+
+var log = logf.Log.WithName("telemetry")
+
+// Report is the anonymous usage and health report which is posted to the telemetry endpoint. It
+// does not contain any metric data, nor the names of the managed clusters.
+type Report struct {
+	// HubID is the hash of the uid of the MultiClusterObservability, it tells the reports of the
+	// same hub apart without identifying it
+	HubID           string            `json:"hubID"`
+	OperatorVersion string            `json:"operatorVersion"`
+	Fleet           FleetSummary      `json:"fleet"`
+	Components      map[string]string `json:"components"`
+	AddonVersions   map[string]int    `json:"addonVersions"`
+	// Errors is the number of the errors per reason of all the managed clusters since the last report
+	Errors          map[string]int `json:"errors"`
+	IntervalSeconds int64          `json:"intervalSeconds"`
+	Timestamp       metav1.Time    `json:"timestamp"`
+}
+
+// FleetSummary is the size and the health of the fleet
+type FleetSummary struct {
+	ManagedClusters     int `json:"managedClusters"`
+	ObservabilityAddons int `json:"observabilityAddons"`
+	AvailableAddons     int `json:"availableAddons"`
+	DegradedAddons      int `json:"degradedAddons"`
+}
+
+// Reporter posts the usage and health reports periodically if they are enabled in the
+// MultiClusterObservability, it is added to the manager as a runnable
+type Reporter struct {
+	Client     client.Client
+	HTTPClient *http.Client
+	lastSent   time.Time
+}
+
+// Start posts the reports in their interval until the context is done
+func (r *Reporter) Start(ctx context.Context) error {
+	if r.HTTPClient == nil {
+		r.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.reportIfDue(ctx, time.Now()); err != nil {
+				log.Error(err, "Failed to post the telemetry report")
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// reportIfDue posts the report once the interval passes since the last report
+func (r *Reporter) reportIfDue(ctx context.Context, now time.Time) error {
+	mcoList := &mcov1beta2.MultiClusterObservabilityList{}
+	if err := r.Client.List(ctx, mcoList); err != nil {
+		return err
+	}
+	if len(mcoList.Items) == 0 || !config.IsTelemetryEnabled(&mcoList.Items[0]) {
+		// the errors are not carried over to the reports once they are enabled
+		errorlog.TakeReasonCounts()
+		r.lastSent = time.Time{}
+		return nil
+	}
+	mco := &mcoList.Items[0]
+	if err := config.ValidateTelemetry(mco); err != nil {
+		return err
+	}
+	interval := config.GetTelemetryInterval(mco)
+	if !r.lastSent.IsZero() && now.Sub(r.lastSent) < interval {
+		return nil
+	}
+	report, err := newReport(ctx, r.Client, mco, now)
+	if err != nil {
+		return err
+	}
+	report.IntervalSeconds = int64(interval.Seconds())
+	// the report is only sent once, the failed one is not retried until the next interval
+	r.lastSent = now
+	return r.send(ctx, mco.Spec.Telemetry.Endpoint, report)
+}
+
+// newReport summarizes the fleet, the components and the errors of the hub
+func newReport(ctx context.Context, c client.Client, mco *mcov1beta2.MultiClusterObservability,
+	now time.Time) (*Report, error) {
+	hash := sha256.Sum256([]byte(mco.GetUID()))
+	report := &Report{
+		HubID:           hex.EncodeToString(hash[:]),
+		OperatorVersion: config.GetComponentVersion(),
+		Components:      map[string]string{},
+		AddonVersions:   map[string]int{},
+		Errors:          errorlog.TakeReasonCounts(),
+		Timestamp:       metav1.NewTime(now),
+	}
+
+	clusters := &clusterv1.ManagedClusterList{}
+	if err := c.List(ctx, clusters); err != nil && !meta.IsNoMatchError(err) {
+		return nil, err
+	}
+	report.Fleet.ManagedClusters = len(clusters.Items)
+
+	addons := &mcov1beta1.ObservabilityAddonList{}
+	if err := c.List(ctx, addons); err != nil {
+		return nil, err
+	}
+	report.Fleet.ObservabilityAddons = len(addons.Items)
+	for _, addon := range addons.Items {
+		switch getAddonHealth(addon) {
+		case "Available":
+			report.Fleet.AvailableAddons++
+		case "Degraded":
+			report.Fleet.DegradedAddons++
+		}
+		if addon.Status.Version != "" {
+			report.AddonVersions[addon.Status.Version]++
+		}
+	}
+
+	selector := client.MatchingLabels{crLabelKey: mco.GetName()}
+	namespace := client.InNamespace(config.GetDefaultNamespace())
+	deployments := &appsv1.DeploymentList{}
+	if err := c.List(ctx, deployments, namespace, selector); err != nil {
+		return nil, err
+	}
+	for _, dep := range deployments.Items {
+		addComponents(report.Components, dep.Spec.Template.Spec.Containers)
+	}
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := c.List(ctx, statefulSets, namespace, selector); err != nil {
+		return nil, err
+	}
+	for _, sts := range statefulSets.Items {
+		addComponents(report.Components, sts.Spec.Template.Spec.Containers)
+	}
+	return report, nil
+}
+
+// addComponents records the versions of the containers by their names, only the tags or the digests
+// of the images are recorded so that the private registries are not exposed
+func addComponents(components map[string]string, containers []corev1.Container) {
+	for _, container := range containers {
+		image := container.Image
+		version := ""
+		if idx := strings.LastIndex(image, "@"); idx >= 0 {
+			version = image[idx+1:]
+		} else if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+			version = image[idx+1:]
+		}
+		components[container.Name] = version
+	}
+}
+
+// getAddonHealth returns Available, Degraded or Progressing by the conditions of the addon
+func getAddonHealth(addon mcov1beta1.ObservabilityAddon) string {
+	health := "Progressing"
+	for _, cond := range addon.Status.Conditions {
+		if cond.Status != metav1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case "Degraded", "Disabled", "NotSupported":
+			return "Degraded"
+		case "Available":
+			health = "Available"
+		}
+	}
+	return health
+}
+
+func (r *Reporter) send(ctx context.Context, endpoint string, report *Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the telemetry endpoint responded with %s", resp.Status)
+	}
+	log.Info("Posted the telemetry report", "managedClusters", report.Fleet.ManagedClusters)
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/errorlog"
+)
+
+func TestReporter(t *testing.T) {
+	s := scheme.Scheme
+	clusterv1.AddToScheme(s)
+	mcov1beta1.AddToScheme(s)
+	mcov1beta2.AddToScheme(s)
+
+	reports := []Report{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := Report{}
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			t.Errorf("Failed to decode the report: (%v)", err)
+		}
+		reports = append(reports, report)
+	}))
+	defer server.Close()
+
+	mco := &mcov1beta2.MultiClusterObservability{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability", UID: "test-uid"},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			Telemetry: &mcov1beta2.TelemetrySpec{Endpoint: server.URL, Interval: "12h"},
+		},
+	}
+	addon := func(namespace, condition, version string) *mcov1beta1.ObservabilityAddon {
+		return &mcov1beta1.ObservabilityAddon{
+			ObjectMeta: metav1.ObjectMeta{Name: "observability-addon", Namespace: namespace},
+			Status: mcov1beta1.ObservabilityAddonStatus{
+				Conditions: []mcov1beta1.StatusCondition{{Type: condition, Status: metav1.ConditionTrue}},
+				Version:    version,
+			},
+		}
+	}
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "observability-grafana",
+			Namespace: config.GetDefaultNamespace(),
+			Labels:    map[string]string{crLabelKey: "observability"},
+		},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "grafana", Image: "registry.example.com:5000/grafana:7.4.2"},
+				{Name: "grafana-dashboard-loader", Image: "registry.example.com/loader@sha256:abc"},
+			},
+		}}},
+	}
+	c := fake.NewFakeClient(mco, dep,
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}},
+		&clusterv1.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster2"}},
+		addon("cluster1", "Available", "2.3.0"), addon("cluster2", "Degraded", "2.3.0"))
+
+	r := &Reporter{Client: c, HTTPClient: server.Client()}
+	now := time.Now()
+	errorlog.ClusterError(log, errors.New("test"), "cluster1", "Failed to test")
+	if err := r.reportIfDue(context.TODO(), now); err != nil || len(reports) != 0 {
+		t.Fatalf("The report should not be posted before it is enabled: %v (%v)", reports, err)
+	}
+
+	mco.Spec.Telemetry.Enabled = true
+	if err := c.Update(context.TODO(), mco); err != nil {
+		t.Fatalf("Failed to enable the telemetry: (%v)", err)
+	}
+	errorlog.ClusterError(log, errors.New("test"), "cluster2", "Failed to get managedclusteraddon")
+	if err := r.reportIfDue(context.TODO(), now); err != nil || len(reports) != 1 {
+		t.Fatalf("The report should be posted once it is enabled: %v (%v)", reports, err)
+	}
+	report := reports[0]
+	if report.HubID == "" || report.HubID == "test-uid" || report.IntervalSeconds != 12*3600 {
+		t.Errorf("The hub should be identified by the hash of the uid in the report: %v", report)
+	}
+	if report.Fleet != (FleetSummary{ManagedClusters: 2, ObservabilityAddons: 2, AvailableAddons: 1, DegradedAddons: 1}) {
+		t.Errorf("The summary of the fleet is wrong: %v", report.Fleet)
+	}
+	if report.AddonVersions["2.3.0"] != 2 || report.Components["grafana"] != "7.4.2" ||
+		report.Components["grafana-dashboard-loader"] != "sha256:abc" {
+		t.Errorf("The versions of the components are wrong: %v %v", report.AddonVersions, report.Components)
+	}
+	if len(report.Errors) != 1 || report.Errors["Failed to get managedclusteraddon"] != 1 {
+		t.Errorf("Only the errors since the telemetry is enabled should be reported: %v", report.Errors)
+	}
+
+	if err := r.reportIfDue(context.TODO(), now.Add(time.Hour)); err != nil || len(reports) != 1 {
+		t.Errorf("The report should not be posted again in the interval: %v (%v)", len(reports), err)
+	}
+	if err := r.reportIfDue(context.TODO(), now.Add(12*time.Hour)); err != nil || len(reports) != 2 {
+		t.Errorf("The report should be posted again after the interval: %v (%v)", len(reports), err)
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+// Package testutil provides the fixtures of the resources which the operator reconciles, so that the unit
+// tests and the e2e suites can simulate the states of the fleet without building them by hand.
+package testutil
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	addonv1alpha1 "github.com/open-cluster-management/api/addon/v1alpha1"
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	mcoshared "github.com/open-cluster-management/multicluster-observability-operator/api/shared"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+	mcov1beta2 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta2"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/config"
+	"github.com/open-cluster-management/multicluster-observability-operator/pkg/util"
+)
+
+const (
+	// ObservabilityAddonName is the name of the ObservabilityAddon in the namespace of the managed cluster
+	ObservabilityAddonName = "observability-addon"
+	// ManifestWorkSuffix is the suffix of the ManifestWork which deploys the addon to the managed cluster
+	ManifestWorkSuffix = "-observability"
+	// ObjectStorageSecretName is the secret of the object storage which NewMCO refers to
+	ObjectStorageSecretName = "thanos-object-storage"
+	ObjectStorageSecretKey  = "thanos.yaml"
+
+	ownerLabelKey   = "owner"
+	ownerLabelValue = "multicluster-observability-operator"
+)
+
+// NewScheme returns the scheme with all the types which the fixtures use
+func NewScheme() (*runtime.Scheme, error) {
+	s := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		mcov1beta1.AddToScheme,
+		mcov1beta2.AddToScheme,
+		addonv1alpha1.AddToScheme,
+		clusterv1.AddToScheme,
+		workv1.AddToScheme,
+	} {
+		if err := add(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// NewMCO returns the MultiClusterObservability with the object storage and the metrics collection
+// enabled, the spec can be modified before the object is created
+func NewMCO(name string) *mcov1beta2.MultiClusterObservability {
+	return &mcov1beta2.MultiClusterObservability{
+		TypeMeta:   metav1.TypeMeta{Kind: "MultiClusterObservability", APIVersion: mcov1beta2.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: mcov1beta2.MultiClusterObservabilitySpec{
+			StorageConfig: &mcov1beta2.StorageConfig{
+				MetricObjectStorage: &mcoshared.PreConfiguredStorage{
+					Name: ObjectStorageSecretName,
+					Key:  ObjectStorageSecretKey,
+				},
+			},
+			ObservabilityAddonSpec: &mcoshared.ObservabilityAddonSpec{
+				EnableMetrics: true,
+				Interval:      300,
+			},
+		},
+	}
+}
+
+// NewObjectStorageSecret returns the secret of the object storage which NewMCO refers to
+func NewObjectStorageSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: ObjectStorageSecretName, Namespace: config.GetDefaultNamespace()},
+		Data: map[string][]byte{
+			ObjectStorageSecretKey: []byte("type: s3\nconfig:\n  bucket: test\n  endpoint: s3.amazonaws.com\n" +
+				"  access_key: access\n  secret_key: secret\n"),
+		},
+	}
+}
+
+// NewAddonCondition returns the condition which the endpoint operator reports on the ObservabilityAddon
+func NewAddonCondition(conditionType string, reason string, message string) mcov1beta1.StatusCondition {
+	return mcov1beta1.StatusCondition{
+		Type:               conditionType,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(time.Now()),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// AddonAvailable is reported once the metrics collector is deployed and functional
+func AddonAvailable() mcov1beta1.StatusCondition {
+	return NewAddonCondition("Available", "Deployed", "Metrics collector deployed and functional")
+}
+
+// AddonProgressing is reported while the metrics collector is deployed
+func AddonProgressing() mcov1beta1.StatusCondition {
+	return NewAddonCondition("Progressing", "Deployed", "Metrics collector deployed")
+}
+
+// AddonDegraded is reported once the metrics collector fails
+func AddonDegraded(message string) mcov1beta1.StatusCondition {
+	return NewAddonCondition("Degraded", "Degraded", message)
+}
+
+// AddonDisabled is reported once the metrics collection is disabled for the managed cluster
+func AddonDisabled() mcov1beta1.StatusCondition {
+	return NewAddonCondition("Disabled", "Disabled", "enableMetrics is set to False")
+}
+
+// NewObservabilityAddon returns the ObservabilityAddon of the managed cluster with the conditions
+func NewObservabilityAddon(cluster string, conditions ...mcov1beta1.StatusCondition) *mcov1beta1.ObservabilityAddon {
+	return &mcov1beta1.ObservabilityAddon{
+		TypeMeta: metav1.TypeMeta{Kind: "ObservabilityAddon", APIVersion: mcov1beta1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ObservabilityAddonName,
+			Namespace: cluster,
+			Labels:    map[string]string{ownerLabelKey: ownerLabelValue},
+		},
+		Status: mcov1beta1.ObservabilityAddonStatus{Conditions: conditions},
+	}
+}
+
+// NewManagedClusterAddOn returns the ManagedClusterAddOn of the managed cluster
+func NewManagedClusterAddOn(cluster string) *addonv1alpha1.ManagedClusterAddOn {
+	return &addonv1alpha1.ManagedClusterAddOn{
+		ObjectMeta: metav1.ObjectMeta{Name: util.ManagedClusterAddonName, Namespace: cluster},
+	}
+}
+
+// NewManagedCluster returns the available managed cluster with the labels
+func NewManagedCluster(name string, labels map[string]string) *clusterv1.ManagedCluster {
+	return &clusterv1.ManagedCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status: clusterv1.ManagedClusterStatus{
+			Conditions: []metav1.Condition{
+				{
+					Type:               clusterv1.ManagedClusterConditionAvailable,
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(time.Now()),
+					Reason:             "ManagedClusterAvailable",
+				},
+			},
+		},
+	}
+}
+
+func newWorkCondition(conditionType string, status metav1.ConditionStatus, reason string,
+	message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		LastTransitionTime: metav1.NewTime(time.Now()),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// WorkApplied is reported once the manifests are applied on the managed cluster
+func WorkApplied() metav1.Condition {
+	return newWorkCondition(workv1.WorkApplied, metav1.ConditionTrue, "AppliedManifestWorkComplete",
+		"Apply manifest work complete")
+}
+
+// WorkAvailable is reported once the applied resources exist on the managed cluster
+func WorkAvailable() metav1.Condition {
+	return newWorkCondition(workv1.WorkAvailable, metav1.ConditionTrue, "ResourcesAvailable",
+		"All resources are available")
+}
+
+// WorkFailed is reported once the manifests fail to be applied on the managed cluster
+func WorkFailed(message string) metav1.Condition {
+	return newWorkCondition(workv1.WorkApplied, metav1.ConditionFalse, "AppliedManifestWorkFailed", message)
+}
+
+// ManifestStatus returns the status which the work agent reports for the manifest with the kind and the name
+func ManifestStatus(ordinal int32, kind string, namespace string, name string,
+	conditions ...metav1.Condition) workv1.ManifestCondition {
+	return workv1.ManifestCondition{
+		ResourceMeta: workv1.ManifestResourceMeta{
+			Ordinal:   ordinal,
+			Kind:      kind,
+			Namespace: namespace,
+			Name:      name,
+		},
+		Conditions: conditions,
+	}
+}
+
+// NewManifestWork returns the ManifestWork of the managed cluster with the conditions and the status of
+// its manifests which the work agent reports
+func NewManifestWork(cluster string, conditions []metav1.Condition,
+	manifests ...workv1.ManifestCondition) *workv1.ManifestWork {
+	return &workv1.ManifestWork{
+		TypeMeta: metav1.TypeMeta{Kind: "ManifestWork", APIVersion: workv1.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cluster + ManifestWorkSuffix,
+			Namespace: cluster,
+			Labels:    map[string]string{ownerLabelKey: ownerLabelValue},
+		},
+		Status: workv1.ManifestWorkStatus{
+			Conditions:     conditions,
+			ResourceStatus: workv1.ManifestResourceStatus{Manifests: manifests},
+		},
+	}
+}
+
+// ClusterState is the state of the observability addon on the simulated managed cluster
+type ClusterState string
+
+const (
+	ClusterAvailable   ClusterState = "Available"
+	ClusterProgressing ClusterState = "Progressing"
+	ClusterDegraded    ClusterState = "Degraded"
+	ClusterDisabled    ClusterState = "Disabled"
+	// ClusterNotApplied is the cluster whose ManifestWork fails to be applied, the addon never reports
+	ClusterNotApplied ClusterState = "NotApplied"
+)
+
+// ClusterName returns the name of the i-th managed cluster of the simulated fleet
+func ClusterName(i int) string {
+	return fmt.Sprintf("cluster-%d", i)
+}
+
+// NewCluster returns the objects of the managed cluster in the state: the ManagedCluster, its namespace,
+// the ManagedClusterAddOn, the ManifestWork and the ObservabilityAddon
+func NewCluster(name string, state ClusterState) []runtime.Object {
+	objs := []runtime.Object{
+		NewManagedCluster(name, map[string]string{"name": name}),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}},
+		NewManagedClusterAddOn(name),
+	}
+	if state == ClusterNotApplied {
+		return append(objs, NewManifestWork(name, []metav1.Condition{WorkFailed("failed to apply the manifests")}))
+	}
+	objs = append(objs, NewManifestWork(name, []metav1.Condition{WorkApplied(), WorkAvailable()},
+		ManifestStatus(0, "ObservabilityAddon", name, ObservabilityAddonName, WorkApplied(), WorkAvailable())))
+	switch state {
+	case ClusterAvailable:
+		return append(objs, NewObservabilityAddon(name, AddonAvailable()))
+	case ClusterProgressing:
+		return append(objs, NewObservabilityAddon(name, AddonProgressing()))
+	case ClusterDegraded:
+		return append(objs, NewObservabilityAddon(name, AddonDegraded("Metrics collector failed to forward")))
+	case ClusterDisabled:
+		return append(objs, NewObservabilityAddon(name, AddonDisabled()))
+	}
+	return append(objs, NewObservabilityAddon(name))
+}
+
+// NewFleet returns the objects of the managed clusters in the states, the i-th cluster is named by
+// ClusterName(i). The states are repeated to the size of the fleet, e.g. NewFleet(100, ClusterAvailable,
+// ClusterDegraded) returns 50 available and 50 degraded clusters.
+func NewFleet(size int, states ...ClusterState) []runtime.Object {
+	if len(states) == 0 {
+		states = []ClusterState{ClusterAvailable}
+	}
+	objs := []runtime.Object{}
+	for i := 0; i < size; i++ {
+		objs = append(objs, NewCluster(ClusterName(i), states[i%len(states)])...)
+	}
+	return objs
+}
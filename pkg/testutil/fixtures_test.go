@@ -0,0 +1,60 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workv1 "github.com/open-cluster-management/api/work/v1"
+	mcov1beta1 "github.com/open-cluster-management/multicluster-observability-operator/api/v1beta1"
+)
+
+func TestNewFleet(t *testing.T) {
+	s, err := NewScheme()
+	if err != nil {
+		t.Fatalf("Failed to create the scheme: (%v)", err)
+	}
+	objs := append(NewFleet(4, ClusterAvailable, ClusterDegraded, ClusterNotApplied), NewMCO("observability"),
+		NewObjectStorageSecret())
+	c := fake.NewFakeClientWithScheme(s, objs...)
+
+	addons := &mcov1beta1.ObservabilityAddonList{}
+	if err := c.List(context.TODO(), addons); err != nil {
+		t.Fatalf("Failed to list the observabilityaddons: (%v)", err)
+	}
+	states := map[string]string{}
+	for _, addon := range addons.Items {
+		for _, condition := range addon.Status.Conditions {
+			states[addon.Namespace] = condition.Type
+		}
+	}
+	expected := map[string]string{ClusterName(0): "Available", ClusterName(1): "Degraded", ClusterName(3): "Available"}
+	if len(states) != len(expected) {
+		t.Fatalf("the addon of the cluster whose manifestwork is not applied should not exist: %v", states)
+	}
+	for cluster, state := range expected {
+		if states[cluster] != state {
+			t.Errorf("the addon of %s should be %s: %v", cluster, state, states)
+		}
+	}
+
+	work := &workv1.ManifestWork{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: ClusterName(2) + ManifestWorkSuffix, Namespace: ClusterName(2)}, work)
+	if err != nil {
+		t.Fatalf("Failed to get the manifestwork: (%v)", err)
+	}
+	if meta.IsStatusConditionTrue(work.Status.Conditions, workv1.WorkApplied) {
+		t.Errorf("the manifestwork should not be applied: %v", work.Status.Conditions)
+	}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: ClusterName(0) + ManifestWorkSuffix, Namespace: ClusterName(0)}, work)
+	if err != nil || len(work.Status.ResourceStatus.Manifests) != 1 ||
+		!meta.IsStatusConditionTrue(work.Status.ResourceStatus.Manifests[0].Conditions, workv1.WorkAvailable) {
+		t.Errorf("the status of the manifests should be reported: %v (%v)", work.Status, err)
+	}
+}
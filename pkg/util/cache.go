@@ -0,0 +1,290 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+var configMapGVK = corev1.SchemeGroupVersion.WithKind("ConfigMap")
+
+// NewScopedCacheFunc returns the function to create the manager cache which only caches
+// the objects of the kinds in scopedObjs (e.g. ConfigMaps and Secrets) in the namespace.
+// The reads of these kinds in the other namespaces bypass the cache and go to the API server.
+// The other kinds are cached cluster-wide as usual. The ConfigMaps in the namespace with any of the
+// excludedConfigMapLabels (e.g. the large grafana dashboards) are never cached, they are read from
+// the API server by name, and the lists of the ConfigMaps do not return them.
+func NewScopedCacheFunc(namespace string, excludedConfigMapLabels []string,
+	scopedObjs ...client.Object) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		clusterCache, err := cache.New(config, opts)
+		if err != nil {
+			return nil, err
+		}
+		nsOpts := opts
+		nsOpts.Namespace = namespace
+		nsCache, err := cache.New(config, nsOpts)
+		if err != nil {
+			return nil, err
+		}
+		reader, err := client.New(config, client.Options{Scheme: opts.Scheme, Mapper: opts.Mapper})
+		if err != nil {
+			return nil, err
+		}
+		var cmCache cache.Cache
+		if len(excludedConfigMapLabels) != 0 {
+			kubeClient, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return nil, err
+			}
+			selector := labels.NewSelector()
+			for _, key := range excludedConfigMapLabels {
+				requirement, err := labels.NewRequirement(key, selection.DoesNotExist, nil)
+				if err != nil {
+					return nil, err
+				}
+				selector = selector.Add(*requirement)
+			}
+			resync := 10 * time.Hour
+			if opts.Resync != nil {
+				resync = *opts.Resync
+			}
+			cmCache = newConfigMapCache(kubeClient, namespace, selector, resync)
+		}
+		return newScopedCache(namespace, opts.Scheme, clusterCache, nsCache, cmCache, reader, scopedObjs...)
+	}
+}
+
+func newScopedCache(namespace string, scheme *runtime.Scheme, clusterCache cache.Cache, nsCache cache.Cache,
+	cmCache cache.Cache, reader client.Reader, scopedObjs ...client.Object) (*scopedCache, error) {
+	c := &scopedCache{
+		namespace:    namespace,
+		scheme:       scheme,
+		clusterCache: clusterCache,
+		nsCache:      nsCache,
+		cmCache:      cmCache,
+		reader:       reader,
+		scopedKinds:  map[schema.GroupVersionKind]bool{},
+	}
+	for _, obj := range scopedObjs {
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil {
+			return nil, err
+		}
+		c.scopedKinds[gvk] = true
+	}
+	return c, nil
+}
+
+type scopedCache struct {
+	namespace    string
+	scheme       *runtime.Scheme
+	clusterCache cache.Cache
+	nsCache      cache.Cache
+	// cmCache caches the ConfigMaps in the namespace without the excluded ones, nil if none is excluded
+	cmCache     cache.Cache
+	reader      client.Reader
+	scopedKinds map[schema.GroupVersionKind]bool
+}
+
+var _ cache.Cache = &scopedCache{}
+
+func (c *scopedCache) isScoped(obj runtime.Object) bool {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return false
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	return c.scopedKinds[gvk]
+}
+
+// namespacedCache returns the cache of the scoped kind in the namespace
+func (c *scopedCache) namespacedCache(gvk schema.GroupVersionKind) cache.Cache {
+	if c.cmCache != nil && gvk == configMapGVK {
+		return c.cmCache
+	}
+	return c.nsCache
+}
+
+func (c *scopedCache) namespacedCacheFor(obj runtime.Object) cache.Cache {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return c.nsCache
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	return c.namespacedCache(gvk)
+}
+
+func (c *scopedCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if !c.isScoped(obj) {
+		return c.clusterCache.Get(ctx, key, obj)
+	}
+	if key.Namespace != c.namespace {
+		return c.reader.Get(ctx, key, obj)
+	}
+	nsCache := c.namespacedCacheFor(obj)
+	err := nsCache.Get(ctx, key, obj)
+	if errors.IsNotFound(err) && nsCache == c.cmCache {
+		// the ConfigMap may be excluded from the cache
+		return c.reader.Get(ctx, key, obj)
+	}
+	return err
+}
+
+func (c *scopedCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if !c.isScoped(list) {
+		return c.clusterCache.List(ctx, list, opts...)
+	}
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.Namespace == c.namespace {
+		return c.namespacedCacheFor(list).List(ctx, list, opts...)
+	}
+	return c.reader.List(ctx, list, opts...)
+}
+
+func (c *scopedCache) GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+	if c.isScoped(obj) {
+		return c.namespacedCacheFor(obj).GetInformer(ctx, obj)
+	}
+	return c.clusterCache.GetInformer(ctx, obj)
+}
+
+func (c *scopedCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+	if c.scopedKinds[gvk] {
+		return c.namespacedCache(gvk).GetInformerForKind(ctx, gvk)
+	}
+	return c.clusterCache.GetInformerForKind(ctx, gvk)
+}
+
+func (c *scopedCache) Start(ctx context.Context) error {
+	go func() {
+		err := c.nsCache.Start(ctx)
+		if err != nil {
+			log.Error(err, "Failed to start the namespaced cache", "namespace", c.namespace)
+		}
+	}()
+	if c.cmCache != nil {
+		go func() {
+			err := c.cmCache.Start(ctx)
+			if err != nil {
+				log.Error(err, "Failed to start the configmap cache", "namespace", c.namespace)
+			}
+		}()
+	}
+	return c.clusterCache.Start(ctx)
+}
+
+func (c *scopedCache) WaitForCacheSync(ctx context.Context) bool {
+	if c.cmCache != nil && !c.cmCache.WaitForCacheSync(ctx) {
+		return false
+	}
+	return c.nsCache.WaitForCacheSync(ctx) && c.clusterCache.WaitForCacheSync(ctx)
+}
+
+func (c *scopedCache) IndexField(ctx context.Context, obj client.Object, field string,
+	extractValue client.IndexerFunc) error {
+	if c.isScoped(obj) {
+		return c.namespacedCacheFor(obj).IndexField(ctx, obj, field, extractValue)
+	}
+	return c.clusterCache.IndexField(ctx, obj, field, extractValue)
+}
+
+// configMapCache caches the ConfigMaps in the namespace which match the label selector. The cache of
+// controller-runtime cannot select the objects by labels, so it is built on a filtered informer.
+type configMapCache struct {
+	informer toolscache.SharedIndexInformer
+}
+
+var _ cache.Cache = &configMapCache{}
+
+func newConfigMapCache(kubeClient kubernetes.Interface, namespace string, selector labels.Selector,
+	resync time.Duration) *configMapCache {
+	informer := coreinformers.NewFilteredConfigMapInformer(kubeClient, namespace, resync,
+		toolscache.Indexers{}, func(opts *metav1.ListOptions) {
+			opts.LabelSelector = selector.String()
+		})
+	return &configMapCache{informer: informer}
+}
+
+func (c *configMapCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return fmt.Errorf("the configmap cache cannot get %T", obj)
+	}
+	item, exists, err := c.informer.GetStore().GetByKey(key.Namespace + "/" + key.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.NewNotFound(corev1.Resource("configmaps"), key.Name)
+	}
+	item.(*corev1.ConfigMap).DeepCopyInto(cm)
+	cm.SetGroupVersionKind(configMapGVK)
+	return nil
+}
+
+func (c *configMapCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	cmList, ok := list.(*corev1.ConfigMapList)
+	if !ok {
+		return fmt.Errorf("the configmap cache cannot list %T", list)
+	}
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	if listOpts.FieldSelector != nil {
+		return fmt.Errorf("the configmap cache cannot list by fields")
+	}
+	selector := labels.Everything()
+	if listOpts.LabelSelector != nil {
+		selector = listOpts.LabelSelector
+	}
+	cmList.Items = []corev1.ConfigMap{}
+	for _, item := range c.informer.GetStore().List() {
+		cm := item.(*corev1.ConfigMap)
+		if selector.Matches(labels.Set(cm.GetLabels())) {
+			cmList.Items = append(cmList.Items, *cm.DeepCopy())
+		}
+	}
+	return nil
+}
+
+func (c *configMapCache) GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+	return c.informer, nil
+}
+
+func (c *configMapCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+	return c.informer, nil
+}
+
+func (c *configMapCache) Start(ctx context.Context) error {
+	c.informer.Run(ctx.Done())
+	return nil
+}
+
+func (c *configMapCache) WaitForCacheSync(ctx context.Context) bool {
+	return toolscache.WaitForCacheSync(ctx.Done(), c.informer.HasSynced)
+}
+
+func (c *configMapCache) IndexField(ctx context.Context, obj client.Object, field string,
+	extractValue client.IndexerFunc) error {
+	return fmt.Errorf("the configmap cache cannot index the fields")
+}
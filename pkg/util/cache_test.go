@@ -0,0 +1,118 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// readerCache is a cache backed by a client.Reader
+type readerCache struct {
+	cache.Cache
+	reader client.Reader
+}
+
+func (c *readerCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return c.reader.Get(ctx, key, obj)
+}
+
+func (c *readerCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.reader.List(ctx, list, opts...)
+}
+
+func newTestConfigMap(ns string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+	}
+}
+
+func TestScopedCache(t *testing.T) {
+	clusterCache := &readerCache{reader: fake.NewFakeClient(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	})}
+	nsCache := &readerCache{reader: fake.NewFakeClient(newTestConfigMap(namespace))}
+	reader := fake.NewFakeClient(newTestConfigMap("other"))
+
+	c, err := newScopedCache(namespace, scheme.Scheme, clusterCache, nsCache, nil, reader, &corev1.ConfigMap{})
+	if err != nil {
+		t.Fatalf("Failed to create scoped cache: (%v)", err)
+	}
+
+	// the configmaps in the namespace are read from the namespaced cache
+	err = c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, &corev1.ConfigMap{})
+	if err != nil {
+		t.Fatalf("Failed to get configmap in the scoped namespace: (%v)", err)
+	}
+	// the configmaps in other namespaces bypass the cache
+	err = c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "other"}, &corev1.ConfigMap{})
+	if err != nil {
+		t.Fatalf("Failed to get configmap out of the scoped namespace: (%v)", err)
+	}
+	cmList := &corev1.ConfigMapList{}
+	err = c.List(context.TODO(), cmList, client.InNamespace(namespace))
+	if err != nil || len(cmList.Items) != 1 || cmList.Items[0].Namespace != namespace {
+		t.Fatalf("Failed to list configmaps in the scoped namespace: (%v) %v", err, cmList.Items)
+	}
+	// the other kinds are read from the cluster cache
+	err = c.Get(context.TODO(), types.NamespacedName{Name: name}, &corev1.Namespace{})
+	if err != nil {
+		t.Fatalf("Failed to get namespace from the cluster cache: (%v)", err)
+	}
+}
+
+func TestScopedCacheExcludedConfigMaps(t *testing.T) {
+	dashboard := newTestConfigMap(namespace)
+	dashboard.Name = "dashboard"
+	dashboard.Labels = map[string]string{"general-folder": "true"}
+	requirement, err := labels.NewRequirement("general-folder", selection.DoesNotExist, nil)
+	if err != nil {
+		t.Fatalf("Failed to create the label requirement: (%v)", err)
+	}
+	cmCache := newConfigMapCache(kubefake.NewSimpleClientset(newTestConfigMap(namespace), dashboard),
+		namespace, labels.NewSelector().Add(*requirement), time.Hour)
+	reader := fake.NewFakeClient(dashboard)
+
+	c, err := newScopedCache(namespace, scheme.Scheme, &readerCache{}, &readerCache{}, cmCache, reader,
+		&corev1.ConfigMap{})
+	if err != nil {
+		t.Fatalf("Failed to create scoped cache: (%v)", err)
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	go func() {
+		_ = cmCache.Start(ctx)
+	}()
+	if !cmCache.WaitForCacheSync(ctx) {
+		t.Fatalf("Failed to sync the configmap cache")
+	}
+
+	// the dashboards are not listed from the cache
+	cmList := &corev1.ConfigMapList{}
+	err = c.List(context.TODO(), cmList, client.InNamespace(namespace))
+	if err != nil || len(cmList.Items) != 1 || cmList.Items[0].Name != name {
+		t.Fatalf("Failed to list the cached configmaps: (%v) %v", err, cmList.Items)
+	}
+	// the dashboards are read from the reader
+	found := &corev1.ConfigMap{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: dashboard.Name, Namespace: namespace}, found)
+	if err != nil || found.Labels["general-folder"] != "true" {
+		t.Fatalf("Failed to get the excluded configmap: (%v)", err)
+	}
+}
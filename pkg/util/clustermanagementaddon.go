@@ -6,6 +6,7 @@ package util
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -20,6 +21,8 @@ import (
 const (
 	ObservabilityController = "observability-controller"
 	grafanaLink             = "/grafana/d/2b679d600f3b9e7676a7c5ac3643d448/acm-clusters-overview"
+	addonDescription        = "Collects the metrics from the managed clusters and forwards them to the hub. " +
+		"It is configured by the ObservabilityAddon in the cluster namespace."
 )
 
 type clusterManagementAddOnSpec struct {
@@ -54,6 +57,25 @@ func CreateClusterManagementAddon(c client.Client) error {
 		}
 
 		log.Info(fmt.Sprintf("%s clustermanagementaddon is present ", ObservabilityController))
+		desired := newClusterManagementAddon()
+		if !reflect.DeepEqual(desired.Spec, clusterManagementAddon.Spec) ||
+			!hasAnnotations(clusterManagementAddon.Annotations, desired.Annotations) {
+			// the changes are patched, an update would drop the install strategy which is not in
+			// the vendored API of the ClusterManagementAddOn
+			original := clusterManagementAddon.DeepCopy()
+			clusterManagementAddon.Spec = desired.Spec
+			if clusterManagementAddon.Annotations == nil {
+				clusterManagementAddon.Annotations = map[string]string{}
+			}
+			for k, v := range desired.Annotations {
+				clusterManagementAddon.Annotations[k] = v
+			}
+			if err := c.Patch(context.TODO(), clusterManagementAddon, client.MergeFrom(original)); err != nil {
+				log.Error(err, "Failed to update observability-controller clustermanagementaddon")
+				return err
+			}
+			log.Info("Updated observability-controller clustermanagementaddon")
+		}
 		return nil
 	}
 	return nil
@@ -74,10 +96,19 @@ func DeleteClusterManagementAddon(client client.Client) error {
 	return nil
 }
 
+func hasAnnotations(annotations map[string]string, expected map[string]string) bool {
+	for k, v := range expected {
+		if annotations[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
 func newClusterManagementAddon() *addonv1alpha1.ClusterManagementAddOn {
 	clusterManagementAddOnSpec := clusterManagementAddOnSpec{
 		DisplayName: "Observability Controller",
-		Description: "Manages Observability components.",
+		Description: addonDescription,
 		CRDName:     "observabilityaddons.observability.open-cluster-management.io",
 	}
 	return &addonv1alpha1.ClusterManagementAddOn{
@@ -62,3 +62,24 @@ func TestClusterManagmentAddon(t *testing.T) {
 		t.Fatalf("Failed to delete clustermanagementaddon: (%v)", err)
 	}
 }
+
+func TestClusterManagementAddonUpdate(t *testing.T) {
+	s := scheme.Scheme
+	addonv1alpha1.AddToScheme(s)
+	stale := newClusterManagementAddon()
+	stale.Spec.AddOnMeta.Description = "Manages Observability components."
+	c := fake.NewFakeClient(stale)
+
+	err := CreateClusterManagementAddon(c)
+	if err != nil {
+		t.Fatalf("Failed to update clustermanagementaddon: (%v)", err)
+	}
+	addon := &addonv1alpha1.ClusterManagementAddOn{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: ObservabilityController}, addon)
+	if err != nil {
+		t.Fatalf("Failed to get clustermanagementaddon: (%v)", err)
+	}
+	if addon.Spec.AddOnMeta.Description != addonDescription {
+		t.Fatalf("Stale description is not updated: %s", addon.Spec.AddOnMeta.Description)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package util
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	ManifestPatchTypeStrategic = "strategic"
+	ManifestPatchTypeJSON      = "json"
+)
+
+// ManifestPatch is a user-supplied patch of the manifest of the kind and the name
+type ManifestPatch struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Type is strategic for a strategic merge patch, or json for a JSON6902 patch. The strategic merge
+	// patch of the kinds which are not known by the operator is applied as a JSON merge patch.
+	Type  string `json:"type,omitempty"`
+	Patch string `json:"patch"`
+}
+
+// GetManifestPatches returns the patches of the configmap in the order of its keys, every key is a
+// list of the patches. The hash of the patches is returned to track their change.
+func GetManifestPatches(c client.Client, name, namespace string) ([]ManifestPatch, string, error) {
+	cm := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, cm)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, "", nil
+		}
+		log.Error(err, "Failed to get the patches configmap", "name", name)
+		return nil, "", err
+	}
+
+	keys := make([]string, 0, len(cm.Data))
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	patches := []ManifestPatch{}
+	for _, key := range keys {
+		list := []ManifestPatch{}
+		if err := yaml.Unmarshal([]byte(cm.Data[key]), &list); err != nil {
+			log.Error(err, "The patches are invalid, skip them", "name", name, "key", key)
+			continue
+		}
+		for _, patch := range list {
+			if patch.Kind == "" || patch.Name == "" || patch.Patch == "" {
+				log.Info("The patch requires the kind, the name and the patch, skip it", "name", name, "key", key)
+				continue
+			}
+			patches = append(patches, patch)
+		}
+	}
+	if len(patches) == 0 {
+		return nil, "", nil
+	}
+	return patches, HashManifestPatches(patches), nil
+}
+
+// HashManifestPatches returns the hash of the patches
+func HashManifestPatches(patches []ManifestPatch) string {
+	data, _ := json.Marshal(patches)
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// ApplyManifestPatch applies the patch to the json document of the manifest
+func ApplyManifestPatch(doc []byte, patch ManifestPatch) ([]byte, error) {
+	patchJSON, err := yaml.YAMLToJSON([]byte(patch.Patch))
+	if err != nil {
+		return nil, err
+	}
+	switch patch.Type {
+	case ManifestPatchTypeJSON:
+		ops, err := jsonpatch.DecodePatch(patchJSON)
+		if err != nil {
+			return nil, err
+		}
+		return ops.Apply(doc)
+	case "", ManifestPatchTypeStrategic:
+		obj := GetK8sObj(patch.Kind)
+		if _, ok := obj.(*unstructured.Unstructured); obj == nil || ok {
+			return jsonpatch.MergePatch(doc, patchJSON)
+		}
+		return strategicpatch.StrategicMergePatch(doc, patchJSON, obj)
+	default:
+		return nil, fmt.Errorf("unknown type %q of the patch", patch.Type)
+	}
+}
@@ -4,10 +4,13 @@
 package util
 
 import (
+	"bytes"
+	"encoding/json"
 	"reflect"
 	"strings"
 
 	v1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	v1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
@@ -24,12 +27,15 @@ var compFns = map[string]compFn{
 	"Namespace":                compareNamespaces,
 	"Deployment":               compareDeployments,
 	"ServiceAccount":           compareServiceAccounts,
+	"Service":                  compareServices,
 	"ClusterRole":              compareClusterRoles,
 	"ClusterRoleBinding":       compareClusterRoleBindings,
 	"Secret":                   compareSecrets,
 	"ConfigMap":                compareConfigMap,
 	"CustomResourceDefinition": compareCRD,
 	"ObservabilityAddon":       compareObsAddon,
+	"PrometheusRule":           comparePrometheusRules,
+	"Job":                      compareJobs,
 }
 
 // GetK8sObj is used to get k8s struct based on the passed-in Kind name
@@ -41,11 +47,14 @@ func GetK8sObj(kind string) runtime.Object {
 		"ClusterRole":              &rbacv1.ClusterRole{},
 		"ClusterRoleBinding":       &rbacv1.ClusterRoleBinding{},
 		"ServiceAccount":           &corev1.ServiceAccount{},
+		"Service":                  &corev1.Service{},
 		"PersistentVolumeClaim":    &corev1.PersistentVolumeClaim{},
 		"Secret":                   &corev1.Secret{},
 		"ConfigMap":                &corev1.ConfigMap{},
 		"CustomResourceDefinition": &v1beta1.CustomResourceDefinition{},
 		"ObservabilityAddon":       &mcov1beta1.ObservabilityAddon{},
+		"PrometheusRule":           &unstructured.Unstructured{},
+		"Job":                      &batchv1.Job{},
 	}
 	return objs[kind]
 }
@@ -128,6 +137,21 @@ func compareServiceAccounts(obj1 runtime.Object, obj2 runtime.Object) bool {
 	return true
 }
 
+func compareServices(obj1 runtime.Object, obj2 runtime.Object) bool {
+	svc1 := obj1.(*corev1.Service)
+	svc2 := obj2.(*corev1.Service)
+	if svc1.Name != svc2.Name || svc1.Namespace != svc2.Namespace {
+		log.Info("Find updated name/namespace for service", "service", svc1.Name)
+		return false
+	}
+	if !reflect.DeepEqual(svc1.Spec.Ports, svc2.Spec.Ports) ||
+		!reflect.DeepEqual(svc1.Spec.Selector, svc2.Spec.Selector) {
+		log.Info("Find updated ports/selector for service", "service", svc1.Name)
+		return false
+	}
+	return true
+}
+
 func compareClusterRoles(obj1 runtime.Object, obj2 runtime.Object) bool {
 	cr1 := obj1.(*rbacv1.ClusterRole)
 	cr2 := obj2.(*rbacv1.ClusterRole)
@@ -201,3 +225,34 @@ func compareCRD(obj1 runtime.Object, obj2 runtime.Object) bool {
 func compareObsAddon(obj1 runtime.Object, obj2 runtime.Object) bool {
 	return reflect.DeepEqual(obj1, obj2)
 }
+
+func comparePrometheusRules(obj1 runtime.Object, obj2 runtime.Object) bool {
+	rule1 := obj1.(*unstructured.Unstructured)
+	rule2 := obj2.(*unstructured.Unstructured)
+	if rule1.GetName() != rule2.GetName() || rule1.GetNamespace() != rule2.GetNamespace() {
+		log.Info("Find updated name/namespace for prometheusrule", "prometheusrule", rule1.GetName())
+		return false
+	}
+	// compare the json of the specs since the numbers are decoded in different types
+	spec1, err1 := json.Marshal(rule1.Object["spec"])
+	spec2, err2 := json.Marshal(rule2.Object["spec"])
+	if err1 != nil || err2 != nil || !bytes.Equal(spec1, spec2) {
+		log.Info("Find updated spec for prometheusrule", "prometheusrule", rule1.GetName())
+		return false
+	}
+	return true
+}
+
+func compareJobs(obj1 runtime.Object, obj2 runtime.Object) bool {
+	job1 := obj1.(*batchv1.Job)
+	job2 := obj2.(*batchv1.Job)
+	if job1.Name != job2.Name || job1.Namespace != job2.Namespace {
+		log.Info("Find updated name/namespace for job", "job", job1.Name)
+		return false
+	}
+	if !reflect.DeepEqual(job1.Spec.Template, job2.Spec.Template) {
+		log.Info("Find updated job", "job", job1.Name)
+		return false
+	}
+	return true
+}
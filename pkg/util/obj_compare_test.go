@@ -100,6 +100,72 @@ func TestCompareObject(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Compare service",
+			rawObj1: runtime.RawExtension{
+				Object: &corev1.Service{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: "v1",
+						Kind:       "Service",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-svc-1",
+						Namespace: "ns1",
+					},
+					Spec: corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{
+							{
+								Name: "metrics",
+								Port: 8383,
+							},
+						},
+						Selector: map[string]string{"name": "test"},
+					},
+				},
+			},
+			rawObj2: runtime.RawExtension{
+				Object: &corev1.Service{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: "v1",
+						Kind:       "Service",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-svc-2",
+						Namespace: "ns1",
+					},
+					Spec: corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{
+							{
+								Name: "metrics",
+								Port: 8383,
+							},
+						},
+						Selector: map[string]string{"name": "test"},
+					},
+				},
+			},
+			rawObj3: runtime.RawExtension{
+				Object: &corev1.Service{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: "v1",
+						Kind:       "Service",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-svc-1",
+						Namespace: "ns1",
+					},
+					Spec: corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{
+							{
+								Name: "metrics",
+								Port: 8080,
+							},
+						},
+						Selector: map[string]string{"name": "test"},
+					},
+				},
+			},
+		},
 		{
 			name: "Compare ClusterRoleBinding",
 			rawObj1: runtime.RawExtension{
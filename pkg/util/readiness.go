@@ -0,0 +1,31 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package util
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// NewServingChecker returns the readiness check which passes once the local server accepts the
+// TLS connections on the port. The replica is only added to the endpoints of the webhook service
+// when it serves the requests, so that the admissions do not fail while the operator is upgraded.
+func NewServingChecker(port int) healthz.Checker {
+	addr := net.JoinHostPort("127.0.0.1", fmt.Sprint(port))
+	return func(_ *http.Request) error {
+		// only the handshake is checked, the serving certificate is verified by the API server
+		// #nosec G402
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", addr,
+			&tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return fmt.Errorf("the server on %s is not serving: %v", addr, err)
+		}
+		return conn.Close()
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package util
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestServingChecker(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	_, p, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to get the port of the server: (%v)", err)
+	}
+	port, _ := strconv.Atoi(p)
+
+	check := NewServingChecker(port)
+	if err := check(nil); err != nil {
+		t.Errorf("The check should pass when the server is serving: (%v)", err)
+	}
+	server.Close()
+	if err := check(nil); err == nil {
+		t.Errorf("The check should fail when the server is stopped")
+	}
+}
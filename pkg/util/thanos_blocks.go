@@ -0,0 +1,69 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ThanosMetaEnv is the env of the upload script with the thanos section of the meta.json of the blocks
+const ThanosMetaEnv = "THANOS_META"
+
+// the compaction levels of the uploaded blocks, Prometheus compacts its blocks up to 31 days
+var uploadedCompactionLevels = []int{1, 2, 3, 4, 5, 6}
+
+// NewThanosMeta returns the thanos section of the meta.json of the Prometheus blocks which are
+// uploaded, with the external labels of the blocks
+func NewThanosMeta(labels map[string]string) (string, error) {
+	meta := map[string]interface{}{
+		"labels":     labels,
+		"downsample": map[string]interface{}{"resolution": 0},
+		"source":     "import",
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// GetUploadBlocksScript returns the script which uploads the Prometheus blocks in the source directory
+// into the object storage. Thanos has no command to upload the Prometheus blocks, so the blocks are
+// linked into a FILESYSTEM bucket in the work directory with the thanos section from the ThanosMetaEnv
+// env appended to their meta.json, and copied into the object storage by thanos tools bucket replicate.
+func GetUploadBlocksScript(sourceDir, workDir, objStorageConfigFile string) string {
+	bucketDir := workDir + "/blocks"
+	lines := []string{
+		"set -e",
+		fmt.Sprintf("mkdir -p %s", bucketDir),
+		fmt.Sprintf("for block in %s/*/; do", sourceDir),
+		`  [ -f "${block}meta.json" ] || continue`,
+		fmt.Sprintf(`  dest=%s/$(basename "${block}")`, bucketDir),
+		`  mkdir -p "${dest}/chunks"`,
+		`  for chunk in "${block}"chunks/*; do`,
+		`    [ -e "${chunk}" ] || continue`,
+		`    ln -s "${chunk}" "${dest}/chunks/"`,
+		`  done`,
+		`  ln -s "${block}index" "${dest}/index"`,
+		// the meta.json of Prometheus ends with the closing brace on its own line
+		`  sed '$d' "${block}meta.json" > "${dest}/meta.json"`,
+		fmt.Sprintf(`  printf ',\n\t"thanos": %%s\n}\n' "${%s}" >> "${dest}/meta.json"`, ThanosMetaEnv),
+		"done",
+		fmt.Sprintf(`printf 'type: FILESYSTEM\nconfig:\n  directory: %s\n' > %s/objstore.yaml`, bucketDir, workDir),
+	}
+	args := []string{
+		"exec thanos tools bucket replicate",
+		"--objstore.config-file=" + workDir + "/objstore.yaml",
+		"--objstore-to.config-file=" + objStorageConfigFile,
+		"--single-run",
+	}
+	for _, level := range uploadedCompactionLevels {
+		args = append(args, fmt.Sprintf("--compaction=%d", level))
+	}
+	args = append(args, "--log.level=info")
+	lines = append(lines, strings.Join(args, " "))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewThanosMeta(t *testing.T) {
+	meta, err := NewThanosMeta(map[string]string{"cluster": "cluster1"})
+	if err != nil {
+		t.Fatalf("failed to generate the thanos meta: (%v)", err)
+	}
+	expected := `{"downsample":{"resolution":0},"labels":{"cluster":"cluster1"},"source":"import"}`
+	if meta != expected {
+		t.Errorf("the thanos meta (%s) is not the expected (%s)", meta, expected)
+	}
+}
+
+func TestGetUploadBlocksScript(t *testing.T) {
+	script := GetUploadBlocksScript("/var/source", "/var/upload", "/etc/objstore/thanos.yaml")
+	for _, expected := range []string{
+		"for block in /var/source/*/; do",
+		"directory: /var/upload/blocks",
+		"thanos tools bucket replicate --objstore.config-file=/var/upload/objstore.yaml " +
+			"--objstore-to.config-file=/etc/objstore/thanos.yaml --single-run",
+		`"${THANOS_META}"`,
+	} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("the script does not contain (%s): %s", expected, script)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+// Package perf contains the helpers to simulate a fleet of managed clusters on the hub
+// and to measure the API calls issued by the controllers when reconciling the fleet.
+package perf
+
+import (
+	"context"
+	"os"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// KubeconfigEnvVar points to the kubeconfig of the API server (e.g. envtest or kwok)
+// the simulated fleet is created in. The fake client is used when it is not set.
+const KubeconfigEnvVar = "PERF_KUBECONFIG"
+
+// NewClient returns the client with the objs created in it
+func NewClient(s *runtime.Scheme, objs ...runtime.Object) (client.Client, error) {
+	kubeconfig := os.Getenv(KubeconfigEnvVar)
+	if kubeconfig == "" {
+		return fake.NewFakeClientWithScheme(s, objs...), nil
+	}
+	cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	// the fleet is large, do not throttle the client
+	cfg.QPS = 1000
+	cfg.Burst = 2000
+	c, err := client.New(cfg, client.Options{Scheme: s})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		err = c.Create(context.TODO(), obj.(client.Object))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// CountingClient is a client.Client which counts the API calls by verb
+type CountingClient struct {
+	client.Client
+	mutex  sync.Mutex
+	counts map[string]int
+}
+
+// NewCountingClient wraps the client c
+func NewCountingClient(c client.Client) *CountingClient {
+	return &CountingClient{
+		Client: c,
+		counts: map[string]int{},
+	}
+}
+
+func (c *CountingClient) count(verb string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.counts[verb]++
+}
+
+// Total returns the number of the API calls since the last reset
+func (c *CountingClient) Total() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	total := 0
+	for _, n := range c.counts {
+		total += n
+	}
+	return total
+}
+
+// Counts returns the number of the API calls by verb since the last reset
+func (c *CountingClient) Counts() map[string]int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	counts := make(map[string]int, len(c.counts))
+	for verb, n := range c.counts {
+		counts[verb] = n
+	}
+	return counts
+}
+
+// Verbs returns the sorted verbs which have been called since the last reset
+func (c *CountingClient) Verbs() []string {
+	verbs := []string{}
+	for verb := range c.Counts() {
+		verbs = append(verbs, verb)
+	}
+	sort.Strings(verbs)
+	return verbs
+}
+
+// Reset clears the counts
+func (c *CountingClient) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.counts = map[string]int{}
+}
+
+func (c *CountingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	c.count("get")
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *CountingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.count("list")
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *CountingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.count("create")
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *CountingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.count("delete")
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *CountingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.count("update")
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *CountingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch,
+	opts ...client.PatchOption) error {
+	c.count("patch")
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *CountingClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	c.count("deleteallof")
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *CountingClient) Status() client.StatusWriter {
+	return &countingStatusWriter{StatusWriter: c.Client.Status(), parent: c}
+}
+
+type countingStatusWriter struct {
+	client.StatusWriter
+	parent *CountingClient
+}
+
+func (w *countingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	w.parent.count("status-update")
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w *countingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch,
+	opts ...client.PatchOption) error {
+	w.parent.count("status-patch")
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package perf
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	clusterv1 "github.com/open-cluster-management/api/cluster/v1"
+	placementv1 "github.com/open-cluster-management/multicloud-operators-placementrule/pkg/apis/apps/v1"
+)
+
+// Fleet is a simulated fleet of managed clusters, each cluster has the same name as
+// its cluster namespace on the hub
+type Fleet struct {
+	Clusters []string
+}
+
+// NewFleet returns the fleet with size managed clusters
+func NewFleet(size int) *Fleet {
+	fleet := &Fleet{Clusters: make([]string, size)}
+	for i := 0; i < size; i++ {
+		fleet.Clusters[i] = fmt.Sprintf("perf-cluster-%05d", i)
+	}
+	return fleet
+}
+
+// Objects returns the cluster namespaces and ManagedClusters of the fleet
+func (f *Fleet) Objects() []runtime.Object {
+	objs := make([]runtime.Object, 0, 2*len(f.Clusters))
+	for _, name := range f.Clusters {
+		objs = append(objs,
+			&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+			},
+			&clusterv1.ManagedCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name,
+					Labels: map[string]string{
+						"vendor": "OpenShift",
+					},
+				},
+			},
+		)
+	}
+	return objs
+}
+
+// Decisions returns the placement decisions which select all the clusters of the fleet
+func (f *Fleet) Decisions() []placementv1.PlacementDecision {
+	decisions := make([]placementv1.PlacementDecision, len(f.Clusters))
+	for i, name := range f.Clusters {
+		decisions[i] = placementv1.PlacementDecision{
+			ClusterName:      name,
+			ClusterNamespace: name,
+		}
+	}
+	return decisions
+}